@@ -0,0 +1,366 @@
+// Command mcp-client connects to an MCP server and lets you explore and call
+// its tools, prompts and resources from a prompt, for debugging servers
+// without a full host application.
+//
+// Usage:
+//
+//	mcp-client ws://localhost:8080/mcp
+//	mcp-client -- ./my-stdio-server --flag
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/chongliujia/mcp-go-template/pkg/client"
+	"github.com/chongliujia/mcp-go-template/pkg/mcp"
+)
+
+const (
+	AppName    = "mcp-client"
+	AppVersion = "1.0.0"
+)
+
+const helpText = `Commands:
+  tools                     List tools
+  describe <tool>           Show a tool's input and output schema
+  call <tool> [json]        Call a tool; prompts for JSON arguments when omitted
+  prompts                   List prompts
+  prompt <name> [json]      Render a prompt with JSON arguments
+  resources                 List resources
+  templates                 List resource templates
+  read <uri>                Read a resource
+  ping                      Check that the server responds
+  help                      Show this help
+  quit                      Disconnect and exit`
+
+func main() {
+	var (
+		token   = flag.String("token", "", "Bearer token sent with the WebSocket handshake")
+		timeout = flag.Duration("timeout", 30*time.Second, "Timeout for each request")
+		verbose = flag.Bool("verbose", false, "Log connection events and server notifications")
+		version = flag.Bool("version", false, "Show version information")
+	)
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [flags] ws://host:port/mcp\n       %s [flags] -- command [args...]\n\nFlags:\n", AppName, AppName)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if *version {
+		fmt.Printf("%s version %s\n", AppName, AppVersion)
+		return
+	}
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	logger := logrus.New()
+	logger.SetOutput(os.Stderr)
+	if !*verbose {
+		logger.SetLevel(logrus.WarnLevel)
+	}
+
+	opts := client.DefaultOptions()
+	opts.ClientInfo = mcp.ClientInfo{Name: AppName, Version: AppVersion}
+	opts.Logger = logger
+	opts.OnNotification = func(notification *mcp.Message) {
+		if *verbose {
+			fmt.Fprintf(os.Stderr, "<- %s %s\n", notification.Method, compactJSON(notification.Params))
+		}
+	}
+
+	c := client.New(dialer(flag.Args(), *token), opts)
+	defer c.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	err := c.Connect(ctx)
+	cancel()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to connect: %v\n", err)
+		os.Exit(1)
+	}
+
+	server := c.Server()
+	fmt.Printf("Connected to %s %s (protocol %s)\n", server.ServerInfo.Name, server.ServerInfo.Version, server.ProtocolVersion)
+	if server.Instructions != "" {
+		fmt.Println(server.Instructions)
+	}
+	fmt.Println(`Type "help" for commands.`)
+
+	repl(c, *timeout)
+}
+
+// dialer picks the transport from the command line: a ws:// or wss:// URL,
+// or a command to run as a stdio server
+func dialer(args []string, token string) client.Dialer {
+	target := args[0]
+	if len(args) == 1 && (strings.HasPrefix(target, "ws://") || strings.HasPrefix(target, "wss://")) {
+		header := http.Header{}
+		if token != "" {
+			header.Set("Authorization", "Bearer "+token)
+		}
+		return client.WebSocket(target, header)
+	}
+	return client.Stdio(target, args[1:]...)
+}
+
+// repl reads commands from stdin until quit or end of input
+func repl(c *client.Client, timeout time.Duration) {
+	input := bufio.NewReader(os.Stdin)
+	for {
+		fmt.Print("mcp> ")
+		line, err := input.ReadString('\n')
+		if err != nil && (line == "" || !errors.Is(err, io.EOF)) {
+			fmt.Println()
+			return
+		}
+
+		command, rest := splitCommand(strings.TrimSpace(line))
+		if command == "" {
+			continue
+		}
+		if command == "quit" || command == "exit" {
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		if err := run(ctx, c, input, command, rest); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		}
+		cancel()
+	}
+}
+
+// run executes one command
+func run(ctx context.Context, c *client.Client, input *bufio.Reader, command, rest string) error {
+	switch command {
+	case "help":
+		fmt.Println(helpText)
+	case "ping":
+		start := time.Now()
+		if err := c.Ping(ctx); err != nil {
+			return err
+		}
+		fmt.Printf("pong in %s\n", time.Since(start).Round(time.Microsecond))
+	case "tools":
+		tools, err := c.ListTools(ctx)
+		if err != nil {
+			return err
+		}
+		printNone(len(tools))
+		sort.Slice(tools, func(i, j int) bool { return tools[i].Name < tools[j].Name })
+		for _, tool := range tools {
+			fmt.Printf("  %-24s %s\n", tool.Name, firstLine(tool.Description))
+		}
+	case "describe":
+		tool, err := findTool(ctx, c, rest)
+		if err != nil {
+			return err
+		}
+		printJSON(tool)
+	case "call":
+		name, arguments := splitCommand(rest)
+		if name == "" {
+			return errors.New("usage: call <tool> [json]")
+		}
+		if arguments == "" {
+			if tool, err := findTool(ctx, c, name); err == nil {
+				fmt.Printf("Arguments for %s: %s\n", name, compactJSON(tool.InputSchema))
+			}
+			fmt.Print("json> ")
+			line, _ := input.ReadString('\n')
+			arguments = strings.TrimSpace(line)
+		}
+		params, err := parseArguments(arguments)
+		if err != nil {
+			return err
+		}
+		result, err := c.CallTool(ctx, name, params)
+		if err != nil {
+			return err
+		}
+		printToolResult(result)
+	case "prompts":
+		prompts, err := c.ListPrompts(ctx)
+		if err != nil {
+			return err
+		}
+		printNone(len(prompts))
+		for _, prompt := range prompts {
+			var args []string
+			for _, arg := range prompt.Arguments {
+				if arg.Required {
+					args = append(args, arg.Name)
+				} else {
+					args = append(args, "["+arg.Name+"]")
+				}
+			}
+			fmt.Printf("  %-24s %s %s\n", prompt.Name, strings.Join(args, " "), firstLine(prompt.Description))
+		}
+	case "prompt":
+		name, arguments := splitCommand(rest)
+		if name == "" {
+			return errors.New("usage: prompt <name> [json]")
+		}
+		params, err := parseArguments(arguments)
+		if err != nil {
+			return err
+		}
+		result, err := c.GetPrompt(ctx, name, params)
+		if err != nil {
+			return err
+		}
+		for _, message := range result.Messages {
+			fmt.Printf("[%s]\n", message.Role)
+			printContent(message.Content)
+		}
+	case "resources":
+		resources, err := c.ListResources(ctx)
+		if err != nil {
+			return err
+		}
+		printNone(len(resources))
+		for _, resource := range resources {
+			fmt.Printf("  %-40s %-20s %s\n", resource.URI, resource.MimeType, resource.Name)
+		}
+	case "templates":
+		templates, err := c.ListResourceTemplates(ctx)
+		if err != nil {
+			return err
+		}
+		printNone(len(templates))
+		for _, template := range templates {
+			fmt.Printf("  %-40s %-20s %s\n", template.URITemplate, template.MimeType, template.Name)
+		}
+	case "read":
+		if rest == "" {
+			return errors.New("usage: read <uri>")
+		}
+		result, err := c.ReadResource(ctx, rest)
+		if err != nil {
+			return err
+		}
+		for _, contents := range result.Contents {
+			fmt.Printf("--- %s (%s)\n", contents.URI, contents.MimeType)
+			if contents.IsBlob() {
+				data, _ := contents.Bytes()
+				fmt.Printf("<%d bytes of binary data>\n", len(data))
+			} else {
+				fmt.Println(contents.Text)
+			}
+		}
+	default:
+		return fmt.Errorf("unknown command %q, type \"help\" for commands", command)
+	}
+	return nil
+}
+
+// printNone notes an empty listing
+func printNone(count int) {
+	if count == 0 {
+		fmt.Println("  (none)")
+	}
+}
+
+// findTool returns the named tool's definition
+func findTool(ctx context.Context, c *client.Client, name string) (*mcp.Tool, error) {
+	if name == "" {
+		return nil, errors.New("usage: describe <tool>")
+	}
+	tools, err := c.ListTools(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, tool := range tools {
+		if tool.Name == name {
+			return tool, nil
+		}
+	}
+	return nil, fmt.Errorf("tool %s not found", name)
+}
+
+// parseArguments decodes a JSON object of arguments; empty input means none
+func parseArguments(text string) (map[string]interface{}, error) {
+	if text == "" {
+		return nil, nil
+	}
+	var arguments map[string]interface{}
+	if err := json.Unmarshal([]byte(text), &arguments); err != nil {
+		return nil, fmt.Errorf("arguments must be a JSON object: %w", err)
+	}
+	return arguments, nil
+}
+
+// printToolResult shows a tool's content blocks and structured content
+func printToolResult(result *mcp.CallToolResult) {
+	if result.IsError {
+		fmt.Println("Tool returned an error:")
+	}
+	printContent(result.Content)
+	if result.StructuredContent != nil {
+		fmt.Println("--- structuredContent")
+		printJSON(result.StructuredContent)
+	}
+}
+
+// printContent shows content blocks, summarizing binary data
+func printContent(blocks []mcp.Content) {
+	for _, content := range blocks {
+		switch content.Type {
+		case mcp.ContentTypeText:
+			fmt.Println(content.Text)
+		case mcp.ContentTypeResource:
+			if content.Resource != nil {
+				fmt.Printf("<resource %s (%s)>\n", content.Resource.URI, content.Resource.MimeType)
+			}
+		default:
+			data, _ := content.Bytes()
+			fmt.Printf("<%s %s, %d bytes>\n", content.Type, content.MimeType, len(data))
+		}
+	}
+}
+
+// printJSON writes v as indented JSON
+func printJSON(v interface{}) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return
+	}
+	fmt.Println(string(data))
+}
+
+// compactJSON renders v on one line
+func compactJSON(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+// splitCommand splits a line into its first word and the rest
+func splitCommand(line string) (string, string) {
+	command, rest, _ := strings.Cut(line, " ")
+	return command, strings.TrimSpace(rest)
+}
+
+// firstLine returns the first line of a description
+func firstLine(text string) string {
+	line, _, _ := strings.Cut(text, "\n")
+	return line
+}
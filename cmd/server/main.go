@@ -2,18 +2,36 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"flag"
+	"fmt"
+	"net/http"
 	"os"
 	"os/signal"
+	"slices"
+	"strings"
+	"sync"
 	"syscall"
+	"time"
 
 	"github.com/sirupsen/logrus"
 
+	"github.com/chongliujia/mcp-go-template/internal/audit"
+	"github.com/chongliujia/mcp-go-template/internal/auth"
 	"github.com/chongliujia/mcp-go-template/internal/config"
+	"github.com/chongliujia/mcp-go-template/internal/database"
+	"github.com/chongliujia/mcp-go-template/internal/prompts"
+	"github.com/chongliujia/mcp-go-template/internal/resources"
+	"github.com/chongliujia/mcp-go-template/internal/scratchpad"
 	"github.com/chongliujia/mcp-go-template/internal/server"
+	"github.com/chongliujia/mcp-go-template/internal/state"
+	"github.com/chongliujia/mcp-go-template/internal/telemetry"
+	"github.com/chongliujia/mcp-go-template/internal/tools"
 	"github.com/chongliujia/mcp-go-template/internal/tools/examples"
+	"github.com/chongliujia/mcp-go-template/pkg/client"
 	"github.com/chongliujia/mcp-go-template/pkg/mcp"
 	"github.com/chongliujia/mcp-go-template/pkg/utils"
+	"github.com/chongliujia/mcp-go-template/pkg/utils/httpclient"
 )
 
 const (
@@ -21,14 +39,51 @@ const (
 	AppVersion = "1.0.0"
 )
 
+// settingOverrides applies each -set key=value flag as a config override
+type settingOverrides struct{}
+
+// String returns no default for the flag's usage
+func (settingOverrides) String() string {
+	return ""
+}
+
+// Set overrides the setting named before the first "="
+func (settingOverrides) Set(value string) error {
+	key, setting, found := strings.Cut(value, "=")
+	if !found {
+		return fmt.Errorf("expected key=value")
+	}
+	return config.Override(key, setting)
+}
+
 func main() {
 	// Parse command line flags
 	var (
-		configPath = flag.String("config", "", "Path to configuration file")
-		logLevel   = flag.String("log-level", "", "Log level (debug, info, warn, error)")
-		version    = flag.Bool("version", false, "Show version information")
+		configPath   = flag.String("config", "", "Path to configuration file")
+		configFormat = flag.String("config-format", "", "Configuration file format (yaml, json, toml); defaults to the file extension")
+		profile      = flag.String("profile", "", "Configuration profile merged over the config file, e.g. prod; defaults to MCP_PROFILE")
+		logLevel     = flag.String("log-level", "", "Log level (debug, info, warn, error)")
+		version      = flag.Bool("version", false, "Show version information")
+		toolDocs     = flag.Bool("tool-docs", false, "Print Markdown documentation for the registered tools and exit")
+		initConfig   = flag.Bool("init-config", false, "Write a commented sample configuration to -config or config.yaml and exit")
 	)
-	flag.Parse()
+	flag.Var(settingOverrides{}, "set", "Override a setting, e.g. -set server.port=9000 (repeatable)")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [validate | print-config] [flags]\n\n", os.Args[0])
+		fmt.Fprintln(flag.CommandLine.Output(), "  validate      check the configuration and exit")
+		fmt.Fprintln(flag.CommandLine.Output(), "  print-config  print the effective configuration, secrets masked, and exit")
+		fmt.Fprintln(flag.CommandLine.Output())
+		flag.PrintDefaults()
+	}
+
+	// A subcommand comes before the flags, e.g. "validate -config prod.yaml"
+	command := ""
+	if len(os.Args) > 1 && (os.Args[1] == "validate" || os.Args[1] == "print-config") {
+		command = os.Args[1]
+		flag.CommandLine.Parse(os.Args[2:])
+	} else {
+		flag.Parse()
+	}
 
 	// Show version if requested
 	if *version {
@@ -36,9 +91,24 @@ func main() {
 		os.Exit(0)
 	}
 
+	// Write a sample configuration before loading one
+	if *initConfig {
+		os.Exit(writeSampleConfig(*configPath))
+	}
+
+	// Keep stdout clean for generated documentation and subcommand output
+	if *toolDocs || command != "" {
+		utils.GetLogger().SetOutput(os.Stderr)
+	}
+
 	// Load configuration
-	cfg, err := config.Load(*configPath)
+	config.SetProfile(*profile)
+	cfg, err := config.LoadFormat(*configPath, *configFormat)
 	if err != nil {
+		if command != "" {
+			fmt.Fprintf(os.Stderr, "Invalid configuration: %v\n", err)
+			os.Exit(1)
+		}
 		utils.Fatalf("Failed to load configuration: %v", err)
 	}
 
@@ -47,6 +117,13 @@ func main() {
 		cfg.Logging.Level = *logLevel
 	}
 
+	if command != "" {
+		os.Exit(runCommand(command, cfg))
+	}
+
+	// Keep API keys and resolved secrets out of the logs
+	utils.MaskSecrets(cfg.Secrets())
+
 	// Configure logging
 	utils.SetLogLevel(utils.LogLevel(cfg.Logging.Level))
 	if cfg.Logging.Format == "text" {
@@ -54,13 +131,57 @@ func main() {
 			FullTimestamp: true,
 		})
 	}
+	utils.SetSampling(logSampling(cfg))
+	if syslog := cfg.Logging.Syslog; syslog.Enabled {
+		if err := utils.AddSyslog(utils.SyslogOptions{
+			Network:  syslog.Network,
+			Address:  syslog.Address,
+			Tag:      syslog.Tag,
+			Facility: syslog.Facility,
+		}); err != nil {
+			utils.Fatalf("Failed to configure syslog logging: %v", err)
+		}
+	}
+	if cfg.Logging.Journald.Enabled {
+		if err := utils.AddJournald(cfg.Logging.Journald.Identifier); err != nil {
+			utils.Fatalf("Failed to configure journald logging: %v", err)
+		}
+	}
 
 	logger := utils.GetLogger()
 	logger.WithFields(logrus.Fields{
 		"name":    cfg.MCP.Name,
 		"version": cfg.MCP.Version,
+		"profile": cfg.Profile(),
 	}).Info("Starting MCP server")
 
+	// Configure the shared outbound HTTP client before tools are created
+	if err := httpclient.Configure(cfg.GetHTTPClientOptions()); err != nil {
+		logger.WithError(err).Fatal("Failed to configure HTTP client")
+	}
+
+	// Export traces before handling any requests
+	shutdownTracing, err := telemetry.Setup(context.Background(), cfg.GetTracingOptions())
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to configure tracing")
+	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			logger.WithError(err).Warn("Failed to flush traces")
+		}
+	}()
+	if cfg.Tracing.Enabled {
+		logger.WithField("endpoint", cfg.Tracing.Endpoint).Info("Tracing enabled")
+	}
+
+	// Connect the shared state backend so replicas behave consistently
+	store, err := state.New(context.Background(), cfg.GetStateOptions())
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to initialize state backend")
+	}
+	defer store.Close()
+	logger.WithField("backend", cfg.State.Backend).Info("State backend initialized")
+
 	// Create server capabilities based on configuration
 	capabilities := createServerCapabilities(cfg)
 
@@ -70,23 +191,292 @@ func main() {
 		Version: cfg.MCP.Version,
 	}
 
-	// Create MCP handler
-	handler := mcp.NewBaseHandler(serverInfo, capabilities)
+	// Create MCP handler serving the tool and resource registries, so
+	// entries registered anywhere show up in listings, counts and
+	// list_changed notifications
+	toolRegistry := tools.NewRegistry()
+	resourceRegistry := resources.NewRegistry()
+	handler := mcp.NewBaseHandlerWithRegistries(serverInfo, capabilities, mcp.Registries{
+		Tools:     toolRegistry,
+		Resources: resourceRegistry,
+	})
+	handler.SetPageSize(cfg.MCP.PageSize)
+	handler.SetMaxBlobBytes(cfg.MCP.MaxBlobBytes)
+	if len(cfg.Security.Access) > 0 {
+		rules := make(map[string]mcp.AccessRule, len(cfg.Security.Access))
+		for _, access := range cfg.Security.Access {
+			rules[access.Client] = mcp.AccessRule{Tools: access.Tools, Resources: access.Resources}
+		}
+		handler.SetAccessRules(rules)
+		logger.WithField("rules", len(rules)).Info("Per-client access rules enabled")
+	}
+
+	// Record every tool call, including those refused by the middleware
+	// added below
+	if cfg.Logging.Audit.Enabled {
+		sink, err := audit.NewFileSink(cfg.Logging.Audit.Directory, cfg.Logging.Audit.RetentionDays)
+		if err != nil {
+			logger.WithError(err).Fatal("Failed to open audit log")
+		}
+		defer sink.Close()
+		handler.Use(mcp.AuditTools(sink, cfg.Logging.Audit.Redact))
+		logger.WithField("directory", cfg.Logging.Audit.Directory).Info("Audit log enabled")
+	}
+
+	// Keep a panicking tool from taking the server down
+	handler.Use(mcp.RecoverPanics(func(tool string, recovered interface{}, stack []byte) {
+		logger.WithFields(logrus.Fields{
+			"tool":  tool,
+			"panic": recovered,
+			"stack": string(stack),
+		}).Error("Tool panicked")
+	}))
+
+	// Keep tools within the call rates configured for them; the limits are
+	// replaced when the configuration is reloaded
+	toolLimits := mcp.NewToolRateLimiter(toolRateLimits(cfg))
+	if state.Shared(store) {
+		toolLimits.SetShared(store)
+	}
+	handler.Use(toolLimits.Middleware())
+
+	// Bound how many calls of memory-heavy tools run at once
+	if len(cfg.Tools.Concurrency) > 0 {
+		limits := make(map[string]mcp.ToolConcurrencyLimit, len(cfg.Tools.Concurrency))
+		for name, limit := range cfg.Tools.Concurrency {
+			limits[name] = mcp.ToolConcurrencyLimit{
+				MaxConcurrent: limit.MaxConcurrent,
+				QueueTimeout:  time.Duration(limit.QueueTimeout) * time.Second,
+			}
+		}
+		handler.Use(mcp.LimitToolConcurrency(limits))
+	}
+
+	// Reuse results of repeated identical calls
+	if len(cfg.Tools.Cache.Tools) > 0 {
+		options := mcp.ResultCacheOptions{
+			Tools:      cfg.Tools.Cache.Tools,
+			Global:     cfg.Tools.Cache.Global,
+			TTL:        time.Duration(cfg.Tools.Cache.TTL) * time.Second,
+			MaxEntries: cfg.Tools.Cache.MaxEntries,
+		}
+		if state.Shared(store) {
+			options.Shared = store
+		}
+		handler.Use(mcp.CacheResults(options))
+	}
+
+	// Retry transient failures of flaky tools; as the innermost middleware,
+	// retries are not seen by the limits above
+	if len(cfg.Tools.Retries) > 0 {
+		policies := make(map[string]mcp.RetryPolicy, len(cfg.Tools.Retries))
+		for name, retry := range cfg.Tools.Retries {
+			policy := mcp.RetryPolicy{
+				MaxAttempts: retry.MaxAttempts,
+				Backoff:     time.Duration(retry.Backoff) * time.Millisecond,
+				MaxBackoff:  time.Duration(retry.MaxBackoff) * time.Millisecond,
+			}
+			if retry.RetryErrorResults {
+				policy.Retryable = mcp.RetryOnErrorResults
+			}
+			policies[name] = policy
+		}
+		handler.Use(mcp.RetryTools(policies))
+	}
 
 	// Register example tools if tools are enabled
 	if cfg.IsToolsEnabled() {
-		if err := registerTools(handler); err != nil {
+		if err := registerTools(toolRegistry, cfg); err != nil {
 			logger.WithError(err).Fatal("Failed to register tools")
 		}
 	}
 
+	// Load tools shipped as Go plugins
+	if cfg.IsToolsEnabled() && cfg.Tools.Plugins.Path != "" {
+		count, err := tools.LoadPlugins(cfg.Tools.Plugins.Path, handler)
+		if err != nil {
+			logger.WithError(err).Fatal("Failed to load tool plugins")
+		}
+		utils.Infof("Loaded %d tools from plugins in %s", count, cfg.Tools.Plugins.Path)
+	}
+
+	// Register tools backed by external commands declared in config
+	if cfg.IsToolsEnabled() {
+		if err := registerExternalTools(toolRegistry, cfg); err != nil {
+			logger.WithError(err).Fatal("Failed to register external tools")
+		}
+
+		// Serve the configured versions of tools registered in several
+		for name, version := range cfg.Tools.PinnedVersions {
+			if err := toolRegistry.PinVersion(name, version); err != nil {
+				logger.WithError(err).Fatal("Failed to pin tool version")
+			}
+		}
+	}
+
+	// Give agents a scratchpad whose entries are also kv:// resources
+	if cfg.IsToolsEnabled() && cfg.Tools.KVStore.Enabled {
+		pad := scratchpad.NewStore(scratchpad.Options{
+			MaxEntries:      cfg.Tools.KVStore.MaxEntries,
+			MaxValueBytes:   cfg.Tools.KVStore.MaxValueBytes,
+			MaxTotalEntries: cfg.Tools.KVStore.MaxTotalEntries,
+			MaxTotalBytes:   cfg.Tools.KVStore.MaxTotalBytes,
+		})
+		// Entries of a client without credentials go with its session
+		handler.OnSessionClose(pad.Release)
+		if err := toolRegistry.Register(scratchpad.NewTool(pad)); err != nil {
+			logger.WithError(err).Fatal("Failed to register kv_store tool")
+		}
+		if cfg.IsResourcesEnabled() {
+			if err := handler.RegisterResourceTemplate(pad); err != nil {
+				logger.WithError(err).Fatal("Failed to register kv:// resources")
+			}
+		}
+	}
+
+	// Cache the contents of the providers configured with a TTL
+	resourceCache := resources.NewCache(cfg.Resources.Cache.MaxEntries)
+
+	// Expose the configured SQL database through a query tool and schema
+	// resources
+	if cfg.Tools.Database.Enabled {
+		if err := registerDatabase(handler, toolRegistry, resourceCache, cfg); err != nil {
+			logger.WithError(err).Fatal("Failed to register database")
+		}
+	}
+
+	// Register example resources if resources are enabled
+	if cfg.IsResourcesEnabled() {
+		for _, resource := range resources.DefaultResources(serverInfo) {
+			if err := handler.RegisterResource(resource); err != nil {
+				logger.WithError(err).Fatal("Failed to register resources")
+			}
+		}
+		utils.Infof("Successfully registered %d default resources", resourceRegistry.Count())
+
+		// Let clients read HTML and PDF resources as Markdown or text
+		for _, conversion := range resources.DefaultConversions() {
+			handler.RegisterResourceConversion(conversion)
+		}
+
+		if cfg.Resources.Filesystem.Enabled {
+			if err := registerFilesystemResources(handler, resourceCache, cfg); err != nil {
+				logger.WithError(err).Fatal("Failed to register filesystem resources")
+			}
+		}
+
+		// Serve the configured URLs as resources
+		ttl := cfg.GetResourceCacheTTL("http")
+		for _, resource := range cfg.Resources.HTTP {
+			definition := &mcp.Resource{
+				URI:         resource.URI,
+				Name:        resource.Name,
+				Description: resource.Description,
+				MimeType:    resource.MimeType,
+			}
+			remote := resources.NewHTTPResource(definition, resource.URL, httpclient.New("resources"))
+			if err := handler.RegisterResource(resourceCache.Wrap(remote, ttl)); err != nil {
+				logger.WithError(err).Fatal("Failed to register HTTP resources")
+			}
+		}
+	}
+
+	// Register example prompts if prompts are enabled
+	if cfg.IsPromptsEnabled() {
+		if err := registerPrompts(handler, cfg); err != nil {
+			logger.WithError(err).Fatal("Failed to register prompts")
+		}
+	}
+
+	if *toolDocs {
+		list, _ := handler.ListTools()
+		fmt.Print(tools.GenerateMarkdown(list))
+		os.Exit(0)
+	}
+
 	// Create and configure server
 	srv := server.New(cfg, handler)
 
+	// Tell clients when tools are added or removed at runtime and when
+	// resources they subscribed to change
+	handler.SetNotifier(srv.Notify)
+
+	srv.SetStateStore(store)
+
+	// Validate bearer tokens against the identity provider's keys
+	if cfg.Security.JWT.JWKSURL != "" {
+		opts := cfg.GetJWTOptions()
+		opts.HTTPClient = httpclient.New("jwks")
+		validator, err := auth.NewJWTValidator(opts)
+		if err != nil {
+			logger.WithError(err).Fatal("Failed to configure JWT validation")
+		}
+		srv.SetJWTValidator(validator)
+		logger.WithField("jwks_url", cfg.Security.JWT.JWKSURL).Info("JWT authentication enabled")
+	}
+
 	// Create context for graceful shutdown
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	// Load script tools from the configured directory
+	if cfg.IsToolsEnabled() && cfg.Tools.Directory.Path != "" {
+		if err := loadToolDirectory(ctx, cfg, handler); err != nil {
+			logger.WithError(err).Fatal("Failed to load tools directory")
+		}
+	}
+
+	// Load prompt definitions from the configured directory, beside the
+	// built-in prompts
+	if cfg.IsPromptsEnabled() && cfg.Prompts.Dir != "" {
+		if err := loadPromptDirectory(ctx, cfg, handler); err != nil {
+			logger.WithError(err).Fatal("Failed to load prompts directory")
+		}
+	}
+
+	// Serve the tools of the configured upstream MCP servers
+	if cfg.IsToolsEnabled() {
+		for _, proxyConfig := range cfg.Tools.Proxies {
+			proxy := tools.NewProxy(proxyConfig.Name, proxyDialer(proxyConfig), handler)
+			proxy.SetNamespace(proxyConfig.Namespace)
+			if err := proxy.Connect(ctx); err != nil {
+				logger.WithError(err).Fatal("Failed to connect tool proxy")
+			}
+			defer proxy.Close()
+		}
+
+		// Aliases come last so they can point at any loaded tool
+		for alias, target := range cfg.Tools.Aliases {
+			if err := handler.AliasTool(alias, target); err != nil {
+				logger.WithError(err).Fatal("Failed to define tool alias")
+			}
+			utils.Infof("Tool %s is available as %s", target, alias)
+		}
+	}
+
+	// Apply configuration changes on SIGHUP and, when enabled, as the
+	// config file changes
+	reloader := &configReloader{
+		current:    cfg,
+		logLevel:   *logLevel,
+		server:     srv,
+		toolLimits: toolLimits,
+	}
+	hupCh := make(chan os.Signal, 1)
+	signal.Notify(hupCh, syscall.SIGHUP)
+	go func() {
+		for range hupCh {
+			logger.Info("Received SIGHUP, reloading configuration")
+			reloader.reload(config.Reload())
+		}
+	}()
+	if cfg.Server.WatchConfig {
+		if err := config.Watch(ctx, reloader.reload); err != nil {
+			logger.WithError(err).Fatal("Failed to watch config file")
+		}
+		utils.Infof("Watching config file for changes")
+	}
+
 	// Handle shutdown signals
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
@@ -115,9 +505,127 @@ func main() {
 		}
 	}
 
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), time.Duration(cfg.Server.ShutdownTimeout)*time.Second)
+	handler.Shutdown(shutdownCtx)
+	cancelShutdown()
+
 	logger.Info("Server stopped")
 }
 
+// runCommand runs a subcommand on the loaded configuration and returns the
+// exit code
+func runCommand(command string, cfg *config.Config) int {
+	switch command {
+	case "validate":
+		source := config.FileUsed()
+		if source == "" {
+			source = "defaults and environment"
+		}
+		if cfg.Profile() != "" {
+			source += ", profile " + cfg.Profile()
+		}
+		fmt.Printf("Configuration is valid (%s)\n", source)
+	case "print-config":
+		if err := cfg.Masked().WriteYAML(os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+	return 0
+}
+
+// writeSampleConfig writes the sample configuration to path, or to
+// config.yaml, without replacing an existing file
+func writeSampleConfig(path string) int {
+	if path == "" {
+		path = "config.yaml"
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to create sample configuration: %v\n", err)
+		return 1
+	}
+	if err := config.WriteSample(file); err != nil {
+		file.Close()
+		fmt.Fprintf(os.Stderr, "Failed to write sample configuration: %v\n", err)
+		return 1
+	}
+	if err := file.Close(); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to write sample configuration: %v\n", err)
+		return 1
+	}
+	fmt.Printf("Wrote sample configuration to %s\n", path)
+	return 0
+}
+
+// configReloader applies the settings that can change while the server
+// runs: the log level, rate limits and allowed IPs
+type configReloader struct {
+	mu         sync.Mutex
+	current    *config.Config
+	logLevel   string // set by -log-level, which overrides the config file
+	server     *server.Server
+	toolLimits *mcp.ToolRateLimiter
+}
+
+// reload applies a reloaded configuration, keeping the current one when
+// the new one is invalid or cannot be applied
+func (r *configReloader) reload(cfg *config.Config, err error) {
+	if err != nil {
+		utils.Errorf("Keeping current configuration: %v", err)
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.logLevel != "" {
+		cfg.Logging.Level = r.logLevel
+	}
+	if err := r.apply(cfg); err != nil {
+		utils.Errorf("Failed to apply reloaded configuration, rolling back: %v", err)
+		if err := r.apply(r.current); err != nil {
+			utils.Errorf("Failed to roll back configuration: %v", err)
+		}
+		return
+	}
+	r.current = cfg
+	utils.Infof("Configuration reloaded")
+}
+
+// apply puts the reloadable settings of cfg into effect
+func (r *configReloader) apply(cfg *config.Config) error {
+	if err := r.server.Reload(cfg); err != nil {
+		return err
+	}
+	r.toolLimits.SetLimits(toolRateLimits(cfg))
+	utils.SetLogLevel(utils.LogLevel(cfg.Logging.Level))
+	utils.SetSampling(logSampling(cfg))
+	utils.MaskSecrets(cfg.Secrets())
+	return nil
+}
+
+// logSampling returns the configured limit on repeated log messages
+func logSampling(cfg *config.Config) utils.SamplingOptions {
+	sampling := cfg.Logging.Sampling
+	if !sampling.Enabled {
+		return utils.SamplingOptions{}
+	}
+	return utils.SamplingOptions{
+		Burst:    sampling.Burst,
+		Interval: time.Duration(sampling.Interval) * time.Second,
+	}
+}
+
+// toolRateLimits returns the configured limits on calls to each tool
+func toolRateLimits(cfg *config.Config) map[string]mcp.ToolRateLimit {
+	limits := make(map[string]mcp.ToolRateLimit, len(cfg.Tools.RateLimits))
+	for name, limit := range cfg.Tools.RateLimits {
+		limits[name] = mcp.ToolRateLimit{RequestsPerSecond: limit.RequestsPerSecond, Burst: limit.Burst}
+	}
+	return limits
+}
+
 // createServerCapabilities creates server capabilities based on configuration
 func createServerCapabilities(cfg *config.Config) mcp.ServerCapabilities {
 	capabilities := mcp.ServerCapabilities{}
@@ -126,6 +634,10 @@ func createServerCapabilities(cfg *config.Config) mcp.ServerCapabilities {
 		capabilities.Logging = &mcp.LoggingCapability{}
 	}
 
+	if cfg.IsCompletionsEnabled() {
+		capabilities.Completions = &mcp.CompletionsCapability{}
+	}
+
 	if cfg.IsToolsEnabled() {
 		capabilities.Tools = &mcp.ToolsCapability{
 			ListChanged: cfg.MCP.Capabilities.Tools.ListChanged,
@@ -141,7 +653,8 @@ func createServerCapabilities(cfg *config.Config) mcp.ServerCapabilities {
 
 	if cfg.IsPromptsEnabled() {
 		capabilities.Prompts = &mcp.PromptsCapability{
-			ListChanged: cfg.MCP.Capabilities.Prompts.ListChanged,
+			// Reloading the prompts directory changes the list at runtime
+			ListChanged: cfg.MCP.Capabilities.Prompts.ListChanged || cfg.Prompts.Dir != "" && cfg.Prompts.Watch,
 		}
 	}
 
@@ -149,35 +662,251 @@ func createServerCapabilities(cfg *config.Config) mcp.ServerCapabilities {
 }
 
 // registerTools registers example tools for deep research
-func registerTools(handler *mcp.BaseHandler) error {
+func registerTools(registry *tools.Registry, cfg *config.Config) error {
 	// Register calculator tool
 	calculator := examples.NewCalculatorTool()
-	if err := handler.RegisterTool(calculator); err != nil {
+	if err := registry.Register(calculator); err != nil {
 		return err
 	}
-	utils.Info("Registered calculator tool")
 
 	// Register web search tool for research
 	webSearch := examples.NewWebSearchTool()
-	if err := handler.RegisterTool(webSearch); err != nil {
+	for name, engine := range cfg.GetSearchEngines() {
+		if err := webSearch.SetEngine(name, engine); err != nil {
+			return err
+		}
+	}
+	if err := registry.Register(webSearch); err != nil {
 		return err
 	}
-	utils.Info("Registered web search tool")
 
 	// Register document analyzer for research
 	docAnalyzer := examples.NewDocumentAnalyzerTool()
-	if err := handler.RegisterTool(docAnalyzer); err != nil {
+	if cfg.Tools.DocumentAnalyzer.Parallelism > 0 {
+		docAnalyzer.SetParallelism(cfg.Tools.DocumentAnalyzer.Parallelism)
+	}
+	if err := registry.Register(docAnalyzer); err != nil {
 		return err
 	}
-	utils.Info("Registered document analyzer tool")
 
 	// Register knowledge graph tool for deep research
 	knowledgeGraph := examples.NewKnowledgeGraphTool()
-	if err := handler.RegisterTool(knowledgeGraph); err != nil {
+	if err := registry.Register(knowledgeGraph); err != nil {
+		return err
+	}
+
+	// Register spreadsheet tool for office workflows
+	spreadsheet := examples.NewSpreadsheetTool()
+	if err := registry.Register(spreadsheet); err != nil {
+		return err
+	}
+
+	utils.Infof("Successfully registered %d research tools", registry.Count())
+	return nil
+}
+
+// registerExternalTools registers the command-backed tools declared under
+// tools.external. Their names must not clash with registered tools.
+func registerExternalTools(registry *tools.Registry, cfg *config.Config) error {
+	for _, external := range cfg.Tools.External {
+		// A new version of a versioned tool may join the existing ones
+		if _, err := registry.Get(external.Name); err == nil {
+			versions := registry.Versions(external.Name)
+			if external.Version == "" || len(versions) == 0 || slices.Contains(versions, external.Version) {
+				return fmt.Errorf("external tool '%s' is already registered", external.Name)
+			}
+		}
+
+		manifest := tools.ScriptManifest{
+			Name:        external.Name,
+			Version:     external.Version,
+			Description: external.Description,
+			Category:    external.Category,
+			Tags:        external.Tags,
+			Command:     external.Command,
+			Args:        external.Args,
+			Env:         external.Env,
+			Timeout:     external.Timeout,
+
+			MaxOutputBytes: external.MaxOutputBytes,
+		}
+		if manifest.MaxOutputBytes == 0 {
+			manifest.MaxOutputBytes = cfg.Server.MaxMessageBytes
+		}
+		if len(external.InputSchema) > 0 {
+			data, err := json.Marshal(external.InputSchema)
+			if err != nil {
+				return fmt.Errorf("external tool '%s': %w", external.Name, err)
+			}
+			if err := json.Unmarshal(data, &manifest.InputSchema); err != nil {
+				return fmt.Errorf("external tool '%s': invalid input schema: %w", external.Name, err)
+			}
+		}
+		if manifest.InputSchema.Type == "" {
+			manifest.InputSchema.Type = "object"
+		}
+
+		// Relative command paths resolve against the working directory
+		if err := registry.Register(tools.NewScriptTool(manifest, "")); err != nil {
+			return err
+		}
+		utils.Infof("Registered external tool %s", external.Name)
+	}
+	return nil
+}
+
+// proxyDialer connects to an upstream MCP server over WebSocket or stdio
+func proxyDialer(proxy config.ToolProxyConfig) client.Dialer {
+	if proxy.Command != "" {
+		return client.Stdio(proxy.Command, proxy.Args...)
+	}
+	header := http.Header{}
+	for key, value := range proxy.Headers {
+		header.Set(key, value)
+	}
+	return client.WebSocket(proxy.URL, header)
+}
+
+// registerFilesystemResources lists the files under the configured roots
+// as resources, and serves reads of files added later through a template.
+// When clients can subscribe or contents are cached, changed files are
+// watched until shutdown.
+func registerFilesystemResources(handler *mcp.BaseHandler, cache *resources.Cache, cfg *config.Config) error {
+	provider, err := resources.NewFilesystemProvider(cfg.GetFilesystemResourceOptions())
+	if err != nil {
+		return err
+	}
+	files, err := provider.Resources()
+	if err != nil {
+		return err
+	}
+
+	ttl := cfg.GetResourceCacheTTL("filesystem")
+	for _, file := range files {
+		if err := handler.RegisterResource(cache.Wrap(file, ttl)); err != nil {
+			return err
+		}
+	}
+	if err := handler.RegisterResourceTemplate(cache.WrapTemplate(provider, ttl)); err != nil {
 		return err
 	}
-	utils.Info("Registered knowledge graph tool")
+	utils.Infof("Serving %d files from %v as resources", len(files), cfg.Resources.Filesystem.Roots)
 
-	utils.Infof("Successfully registered %d research tools", 4)
+	if cfg.Resources.Filesystem.Watch && (cfg.MCP.Capabilities.Resources.Subscribe || ttl > 0) {
+		ctx, stop := context.WithCancel(context.Background())
+		handler.OnShutdown(func(context.Context) {
+			stop()
+		})
+		err := provider.Watch(ctx, func(uri string) {
+			cache.Invalidate(uri)
+			handler.NotifyResourceUpdated(uri)
+		})
+		if err != nil {
+			stop()
+			return err
+		}
+		utils.Infof("Watching filesystem resources for changes")
+	}
 	return nil
-}
\ No newline at end of file
+}
+
+// registerDatabase opens the configured database, registering the query
+// tool when tools are enabled and its table schemas when resources are.
+// The database is closed when the handler shuts down.
+func registerDatabase(handler *mcp.BaseHandler, toolRegistry *tools.Registry, cache *resources.Cache, cfg *config.Config) error {
+	db, err := database.Open(context.Background(), cfg.GetDatabaseOptions())
+	if err != nil {
+		return err
+	}
+	handler.OnShutdown(func(ctx context.Context) {
+		db.Close()
+	})
+
+	if cfg.IsToolsEnabled() {
+		if err := toolRegistry.Register(db.NewQueryTool()); err != nil {
+			return err
+		}
+	}
+	if cfg.IsResourcesEnabled() {
+		tables, err := db.TableResources(context.Background())
+		if err != nil {
+			return err
+		}
+		ttl := cfg.GetResourceCacheTTL("database")
+		for _, table := range tables {
+			if err := handler.RegisterResource(cache.Wrap(table, ttl)); err != nil {
+				return err
+			}
+		}
+		if err := handler.RegisterResourceTemplate(cache.WrapTemplate(db, ttl)); err != nil {
+			return err
+		}
+		utils.Infof("Serving the schemas of %d tables as resources", len(tables))
+	}
+	return nil
+}
+
+// registerPrompts registers the example prompts, exposing each prompt's
+// versions under its base name with the configured default version
+func registerPrompts(handler *mcp.BaseHandler, cfg *config.Config) error {
+	registry := prompts.NewRegistry()
+	if err := registry.RegisterDefaultPrompts(); err != nil {
+		return err
+	}
+
+	for name, version := range cfg.Prompts.DefaultVersions {
+		if err := registry.SetDefaultVersion(name, version); err != nil {
+			return err
+		}
+	}
+	for name, weights := range cfg.Prompts.VariantWeights {
+		if err := registry.SetVariantWeights(name, weights); err != nil {
+			return err
+		}
+	}
+
+	for _, prompt := range registry.Handlers() {
+		if err := handler.RegisterPrompt(prompt); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// loadPromptDirectory loads template prompts from the prompts directory
+// and, when enabled, reloads them as the files change
+func loadPromptDirectory(ctx context.Context, cfg *config.Config, handler *mcp.BaseHandler) error {
+	loader := prompts.NewDirectoryLoader(cfg.Prompts.Dir, handler)
+	if err := loader.Load(); err != nil {
+		return err
+	}
+
+	if cfg.Prompts.Watch {
+		if err := loader.Watch(ctx); err != nil {
+			return err
+		}
+		utils.Infof("Watching prompts directory: %s", cfg.Prompts.Dir)
+	}
+
+	return nil
+}
+
+// loadToolDirectory loads script tools from the tools directory and, when
+// enabled, keeps them in sync with the directory's contents
+func loadToolDirectory(ctx context.Context, cfg *config.Config, handler *mcp.BaseHandler) error {
+	// The handler notifies clients as the loader registers and removes tools
+	loader := tools.NewDirectoryLoader(cfg.Tools.Directory.Path, handler, nil)
+	if err := loader.Load(); err != nil {
+		return err
+	}
+
+	if cfg.Tools.Directory.Watch {
+		if err := loader.Watch(ctx); err != nil {
+			return err
+		}
+		utils.Infof("Watching tools directory: %s", cfg.Tools.Directory.Path)
+	}
+
+	return nil
+}
@@ -0,0 +1,133 @@
+// Package audit stores the records of the tool call audit log
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chongliujia/mcp-go-template/pkg/mcp"
+	"github.com/chongliujia/mcp-go-template/pkg/utils"
+)
+
+const (
+	filePrefix = "audit-"
+	fileSuffix = ".jsonl"
+	dateLayout = "2006-01-02"
+)
+
+// FileSink writes audit records as JSON lines to one file per day, named
+// audit-YYYY-MM-DD.jsonl, and deletes the files of days past retention
+type FileSink struct {
+	dir       string
+	retention time.Duration
+	logger    utils.Logger
+
+	mu   sync.Mutex
+	day  string
+	file *os.File
+}
+
+// NewFileSink creates a sink writing to dir, keeping retentionDays days of
+// records; 0 keeps them forever
+func NewFileSink(dir string, retentionDays int) (*FileSink, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create audit directory: %w", err)
+	}
+	return &FileSink{
+		dir:       dir,
+		retention: time.Duration(retentionDays) * 24 * time.Hour,
+		logger:    utils.DefaultLogger(),
+	}, nil
+}
+
+// SetLogger sets the logger the sink logs to. It must be called before
+// records are written.
+func (s *FileSink) SetLogger(logger utils.Logger) {
+	s.logger = logger
+}
+
+// WriteAudit appends a record to the file of the record's day
+func (s *FileSink) WriteAudit(record *mcp.AuditRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit record: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	day := record.Time.UTC().Format(dateLayout)
+	if s.file == nil || day != s.day {
+		if err := s.rotate(day); err != nil {
+			return err
+		}
+	}
+	if _, err := s.file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write audit record: %w", err)
+	}
+	return nil
+}
+
+// rotate switches to the file of day and prunes expired files; the caller
+// holds the lock
+func (s *FileSink) rotate(day string) error {
+	if s.file != nil {
+		s.file.Close()
+		s.file = nil
+	}
+
+	path := filepath.Join(s.dir, filePrefix+day+fileSuffix)
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o640)
+	if err != nil {
+		return fmt.Errorf("failed to open audit file: %w", err)
+	}
+	s.file = file
+	s.day = day
+
+	s.prune(time.Now())
+	return nil
+}
+
+// prune deletes the files of days older than the retention before now
+func (s *FileSink) prune(now time.Time) {
+	if s.retention <= 0 {
+		return
+	}
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		s.logger.Warnf("Failed to read audit directory: %v", err)
+		return
+	}
+
+	cutoff := now.UTC().Add(-s.retention).Format(dateLayout)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, filePrefix) || !strings.HasSuffix(name, fileSuffix) {
+			continue
+		}
+		day := strings.TrimSuffix(strings.TrimPrefix(name, filePrefix), fileSuffix)
+		if _, err := time.Parse(dateLayout, day); err != nil || day >= cutoff {
+			continue
+		}
+		if err := os.Remove(filepath.Join(s.dir, name)); err != nil {
+			s.logger.Warnf("Failed to delete expired audit file %s: %v", name, err)
+		}
+	}
+}
+
+// Close closes the current file
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
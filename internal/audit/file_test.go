@@ -0,0 +1,80 @@
+package audit
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chongliujia/mcp-go-template/pkg/mcp"
+)
+
+func TestFileSink_WritesDailyFiles(t *testing.T) {
+	dir := t.TempDir()
+	sink, err := NewFileSink(dir, 0)
+	if err != nil {
+		t.Fatalf("Failed to create sink: %v", err)
+	}
+	defer sink.Close()
+
+	first := time.Date(2026, 3, 1, 23, 59, 0, 0, time.UTC)
+	for _, record := range []*mcp.AuditRecord{
+		{Time: first, Tool: "calculator", Success: true},
+		{Time: first, Tool: "web_search", Error: "timeout"},
+		{Time: first.Add(2 * time.Minute), Tool: "calculator", Success: true},
+	} {
+		if err := sink.WriteAudit(record); err != nil {
+			t.Fatalf("Failed to write record: %v", err)
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "audit-2026-03-01.jsonl"))
+	if err != nil {
+		t.Fatalf("Failed to read the first day's file: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 records on the first day, got %d", len(lines))
+	}
+	var record mcp.AuditRecord
+	if err := json.Unmarshal([]byte(lines[1]), &record); err != nil || record.Tool != "web_search" || record.Error != "timeout" {
+		t.Errorf("Unexpected record %s: %v", lines[1], err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "audit-2026-03-02.jsonl")); err != nil {
+		t.Errorf("Expected a file for the second day: %v", err)
+	}
+}
+
+func TestFileSink_PrunesExpiredFiles(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now().UTC()
+	old := filepath.Join(dir, "audit-"+now.AddDate(0, 0, -10).Format(dateLayout)+".jsonl")
+	recent := filepath.Join(dir, "audit-"+now.AddDate(0, 0, -2).Format(dateLayout)+".jsonl")
+	other := filepath.Join(dir, "notes.txt")
+	for _, path := range []string{old, recent, other} {
+		if err := os.WriteFile(path, []byte("{}\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	sink, err := NewFileSink(dir, 7)
+	if err != nil {
+		t.Fatalf("Failed to create sink: %v", err)
+	}
+	defer sink.Close()
+	if err := sink.WriteAudit(&mcp.AuditRecord{Time: now, Tool: "calculator"}); err != nil {
+		t.Fatalf("Failed to write record: %v", err)
+	}
+
+	if _, err := os.Stat(old); !os.IsNotExist(err) {
+		t.Errorf("Expected the expired file to be deleted, got %v", err)
+	}
+	for _, path := range []string{recent, other} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("Expected %s to be kept: %v", filepath.Base(path), err)
+		}
+	}
+}
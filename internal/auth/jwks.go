@@ -0,0 +1,187 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// minRefreshInterval limits how often an unknown key ID or a stale cache
+// triggers a refetch, whether or not the last one succeeded
+const minRefreshInterval = 10 * time.Second
+
+// defaultFetchTimeout bounds a key set fetch when JWTOptions.HTTPClient is
+// nil, since the cache's lock is held meanwhile
+const defaultFetchTimeout = 10 * time.Second
+
+// jwk is a single JSON Web Key
+type jwk struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// jwkSet caches the signing keys published at a JWKS URL
+type jwkSet struct {
+	url      string
+	client   *http.Client
+	interval time.Duration
+
+	mu          sync.Mutex
+	keys        map[string]crypto.PublicKey
+	fetchedAt   time.Time
+	attemptedAt time.Time
+}
+
+// newJWKSet creates a key cache that refetches keys every interval
+func newJWKSet(url string, client *http.Client, interval time.Duration) *jwkSet {
+	return &jwkSet{
+		url:      url,
+		client:   client,
+		interval: interval,
+		keys:     make(map[string]crypto.PublicKey),
+	}
+}
+
+// key returns the public key with the given ID, fetching the key set when
+// the cache is stale or the ID is unknown
+func (s *jwkSet) key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	age := time.Since(s.fetchedAt)
+	key, exists := s.keys[kid]
+	if exists && age < s.interval {
+		return key, nil
+	}
+
+	// Refetch at most once per minRefreshInterval, even while the JWKS
+	// endpoint fails
+	if time.Since(s.attemptedAt) < minRefreshInterval {
+		if exists {
+			return key, nil
+		}
+		return nil, fmt.Errorf("unknown signing key: %s", kid)
+	}
+
+	s.attemptedAt = time.Now()
+	if err := s.refresh(ctx); err != nil {
+		if exists {
+			// Keep serving the cached key if the JWKS endpoint is unavailable
+			return key, nil
+		}
+		return nil, err
+	}
+
+	key, exists = s.keys[kid]
+	if !exists {
+		return nil, fmt.Errorf("unknown signing key: %s", kid)
+	}
+	return key, nil
+}
+
+// refresh fetches the key set; the caller holds the lock
+func (s *jwkSet) refresh(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create JWKS request: %w", err)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to fetch JWKS: status %d", resp.StatusCode)
+	}
+
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return fmt.Errorf("failed to decode JWKS: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			// Skip key types we cannot use rather than rejecting the set
+			continue
+		}
+		keys[k.Kid] = key
+	}
+
+	s.keys = keys
+	s.fetchedAt = time.Now()
+	return nil
+}
+
+// publicKey decodes the key material of an RSA or EC key
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve: %s", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported key type: %s", k.Kty)
+	}
+}
+
+// decodeBigInt decodes a base64url-encoded big-endian integer
+func decodeBigInt(value string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key encoding: %w", err)
+	}
+	return new(big.Int).SetBytes(data), nil
+}
@@ -0,0 +1,104 @@
+// Package auth validates bearer tokens presented by MCP clients.
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ErrInvalidToken is returned for tokens that fail validation
+var ErrInvalidToken = errors.New("invalid token")
+
+// JWTOptions configures bearer token validation
+type JWTOptions struct {
+	// JWKSURL publishes the issuer's signing keys
+	JWKSURL string
+	// Issuer is the required "iss" claim; empty skips the check
+	Issuer string
+	// Audience lists accepted "aud" values; empty skips the check
+	Audience []string
+	// RefreshInterval is how long fetched signing keys are cached
+	RefreshInterval time.Duration
+	// Leeway tolerates clock skew when checking exp and nbf
+	Leeway time.Duration
+	// HTTPClient fetches the key set; nil uses a client that gives up after
+	// defaultFetchTimeout
+	HTTPClient *http.Client
+}
+
+// JWTValidator validates JWT bearer tokens against a JWKS endpoint
+type JWTValidator struct {
+	opts   JWTOptions
+	keys   *jwkSet
+	parser *jwt.Parser
+}
+
+// NewJWTValidator creates a validator for tokens signed by the keys at
+// opts.JWKSURL
+func NewJWTValidator(opts JWTOptions) (*JWTValidator, error) {
+	if opts.JWKSURL == "" {
+		return nil, fmt.Errorf("JWKS URL is required")
+	}
+	if opts.RefreshInterval <= 0 {
+		opts.RefreshInterval = time.Hour
+	}
+	client := opts.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: defaultFetchTimeout}
+	}
+
+	parserOpts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512", "PS256", "PS384", "PS512"}),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(opts.Leeway),
+	}
+	if opts.Issuer != "" {
+		parserOpts = append(parserOpts, jwt.WithIssuer(opts.Issuer))
+	}
+
+	return &JWTValidator{
+		opts:   opts,
+		keys:   newJWKSet(opts.JWKSURL, client, opts.RefreshInterval),
+		parser: jwt.NewParser(parserOpts...),
+	}, nil
+}
+
+// Validate checks the token's signature, expiry, issuer, and audience and
+// returns its claims
+func (v *JWTValidator) Validate(ctx context.Context, token string) (map[string]interface{}, error) {
+	claims := jwt.MapClaims{}
+	_, err := v.parser.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return v.keys.key(ctx, kid)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	if len(v.opts.Audience) > 0 && !audienceAllowed(claims, v.opts.Audience) {
+		return nil, fmt.Errorf("%w: audience not accepted", ErrInvalidToken)
+	}
+
+	return claims, nil
+}
+
+// audienceAllowed reports whether any of the token's audiences is accepted
+func audienceAllowed(claims jwt.MapClaims, accepted []string) bool {
+	audiences, err := claims.GetAudience()
+	if err != nil {
+		return false
+	}
+	for _, aud := range audiences {
+		for _, want := range accepted {
+			if aud == want {
+				return true
+			}
+		}
+	}
+	return false
+}
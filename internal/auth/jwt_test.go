@@ -0,0 +1,116 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestJWTValidator_Validate(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+
+	jwks := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kid": "test-key",
+				"kty": "RSA",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	}))
+	defer jwks.Close()
+
+	validator, err := NewJWTValidator(JWTOptions{
+		JWKSURL:  jwks.URL,
+		Issuer:   "https://issuer.example.com",
+		Audience: []string{"mcp"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create validator: %v", err)
+	}
+
+	sign := func(kid string, claims jwt.MapClaims) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		token.Header["kid"] = kid
+		signed, err := token.SignedString(key)
+		if err != nil {
+			t.Fatalf("Failed to sign token: %v", err)
+		}
+		return signed
+	}
+
+	valid := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"sub": "user-1",
+			"iss": "https://issuer.example.com",
+			"aud": "mcp",
+			"exp": time.Now().Add(time.Hour).Unix(),
+		}
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr bool
+	}{
+		{"valid token", sign("test-key", valid()), false},
+		{"wrong issuer", sign("test-key", func() jwt.MapClaims { c := valid(); c["iss"] = "other"; return c }()), true},
+		{"wrong audience", sign("test-key", func() jwt.MapClaims { c := valid(); c["aud"] = "other"; return c }()), true},
+		{"expired", sign("test-key", func() jwt.MapClaims { c := valid(); c["exp"] = time.Now().Add(-time.Hour).Unix(); return c }()), true},
+		{"missing expiry", sign("test-key", func() jwt.MapClaims { c := valid(); delete(c, "exp"); return c }()), true},
+		{"unknown key", sign("other-key", valid()), true},
+		{"malformed", "not-a-token", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := validator.Validate(context.Background(), tt.token)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidToken) {
+					t.Errorf("Expected ErrInvalidToken, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if claims["sub"] != "user-1" {
+				t.Errorf("Expected sub user-1, got %v", claims["sub"])
+			}
+		})
+	}
+}
+
+func TestJWKSet_ThrottlesFailedFetches(t *testing.T) {
+	fetches := 0
+	jwks := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer jwks.Close()
+
+	set := newJWKSet(jwks.URL, jwks.Client(), time.Hour)
+	for i := 0; i < 3; i++ {
+		if _, err := set.key(context.Background(), "test-key"); err == nil {
+			t.Fatal("Expected an unavailable JWKS endpoint to fail")
+		}
+	}
+	if fetches != 1 {
+		t.Errorf("Expected failed fetches to be throttled, got %d fetches", fetches)
+	}
+}
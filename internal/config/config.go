@@ -2,30 +2,195 @@ package config
 
 import (
 	"fmt"
+	"net/netip"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/spf13/viper"
+
+	"github.com/chongliujia/mcp-go-template/internal/auth"
+	"github.com/chongliujia/mcp-go-template/internal/database"
+	"github.com/chongliujia/mcp-go-template/internal/resources"
+	"github.com/chongliujia/mcp-go-template/internal/state"
+	"github.com/chongliujia/mcp-go-template/internal/telemetry"
+	"github.com/chongliujia/mcp-go-template/internal/tools/examples"
+	"github.com/chongliujia/mcp-go-template/pkg/utils/httpclient"
 )
 
 // Config represents the application configuration
 type Config struct {
-	Server   ServerConfig   `mapstructure:"server"`
-	Logging  LoggingConfig  `mapstructure:"logging"`
-	MCP      MCPConfig      `mapstructure:"mcp"`
-	Security SecurityConfig `mapstructure:"security"`
+	Server     ServerConfig     `mapstructure:"server"`
+	Logging    LoggingConfig    `mapstructure:"logging"`
+	MCP        MCPConfig        `mapstructure:"mcp"`
+	Security   SecurityConfig   `mapstructure:"security"`
+	HTTPClient HTTPClientConfig `mapstructure:"http_client"`
+	Tools      ToolSettings     `mapstructure:"tools"`
+	State      StateConfig      `mapstructure:"state"`
+	Prompts    PromptSettings   `mapstructure:"prompts"`
+	Resources  ResourceSettings `mapstructure:"resources"`
+	Tracing    TracingConfig    `mapstructure:"tracing"`
+
+	// secrets holds the values resolved from secret references
+	secrets []string
+	// profile names the profile merged over the config file
+	profile string
 }
 
 // ServerConfig represents server configuration
 type ServerConfig struct {
-	Host    string `mapstructure:"host"`
-	Port    int    `mapstructure:"port"`
-	Timeout int    `mapstructure:"timeout"`
+	Host           string `mapstructure:"host"`
+	Port           int    `mapstructure:"port"`
+	Timeout        int    `mapstructure:"timeout"`
+	SessionTimeout int    `mapstructure:"session_timeout"`
+	// ShutdownTimeout bounds how long shutdown waits for in-flight requests
+	ShutdownTimeout int `mapstructure:"shutdown_timeout"`
+	// MaxConnections caps concurrent WebSocket clients; 0 means unlimited
+	MaxConnections int `mapstructure:"max_connections"`
+	// MaxInflightRequests caps concurrent requests per connection; 0 means unlimited
+	MaxInflightRequests int `mapstructure:"max_inflight_requests"`
+	// MaxMessageBytes caps the size of a single message in either direction; 0 means unlimited
+	MaxMessageBytes int64 `mapstructure:"max_message_bytes"`
+	// SocketPath makes the server listen on a Unix domain socket instead
+	// of host:port when set
+	SocketPath string          `mapstructure:"socket_path"`
+	WebSocket  WebSocketConfig `mapstructure:"websocket"`
+	CORS       CORSConfig      `mapstructure:"cors"`
+	// TrustedProxies lists the proxies, as addresses or CIDR ranges, whose
+	// X-Forwarded-For and X-Real-IP headers are believed
+	TrustedProxies []string `mapstructure:"trusted_proxies"`
+	// EnablePprof serves runtime profiles under /debug/pprof/
+	EnablePprof bool            `mapstructure:"enable_pprof"`
+	RateLimit   RateLimitConfig `mapstructure:"rate_limit"`
+	// WatchConfig reloads the config file when it changes; SIGHUP always
+	// reloads it
+	WatchConfig bool `mapstructure:"watch_config"`
+}
+
+// Rate limit client keys
+const (
+	RateLimitByConnection = "connection"
+	RateLimitByAPIKey     = "api_key"
+)
+
+// RateLimitConfig represents per-client token-bucket rate limiting
+type RateLimitConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// RequestsPerSecond is each client's sustained rate across all
+	// requests; 0 leaves requests unlimited unless a tool limit applies
+	RequestsPerSecond float64 `mapstructure:"requests_per_second"`
+	Burst             int     `mapstructure:"burst"`
+	// Key is "connection" to limit each connection or session separately,
+	// or "api_key" to share one budget between all connections using the
+	// same API key or token subject
+	Key string `mapstructure:"key"`
+	// Tools sets additional limits on calls to specific tools
+	Tools map[string]ToolRateLimitConfig `mapstructure:"tools"`
+}
+
+// ToolRateLimitConfig represents the rate limit for one tool
+type ToolRateLimitConfig struct {
+	RequestsPerSecond float64 `mapstructure:"requests_per_second"`
+	Burst             int     `mapstructure:"burst"`
+}
+
+// CORSConfig represents the cross-origin policy applied to HTTP endpoints
+type CORSConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// AllowedOrigins supports wildcards such as "https://*.example.com" and "*"
+	AllowedOrigins   []string `mapstructure:"allowed_origins"`
+	AllowedMethods   []string `mapstructure:"allowed_methods"`
+	AllowedHeaders   []string `mapstructure:"allowed_headers"`
+	ExposedHeaders   []string `mapstructure:"exposed_headers"`
+	AllowCredentials bool     `mapstructure:"allow_credentials"`
+	// MaxAge is how long browsers may cache a preflight response, in seconds
+	MaxAge int `mapstructure:"max_age"`
+}
+
+// WebSocketConfig represents WebSocket keepalive configuration; all values
+// are in seconds and 0 disables the corresponding check
+type WebSocketConfig struct {
+	// PingInterval is how often the server pings each client
+	PingInterval int `mapstructure:"ping_interval"`
+	// PongTimeout is how long a client may go without answering a ping
+	PongTimeout int `mapstructure:"pong_timeout"`
+	// IdleTimeout closes connections that send no MCP messages for this long
+	IdleTimeout int `mapstructure:"idle_timeout"`
+	// MCPPingInterval sends an MCP ping request to clients that sent no MCP
+	// messages for this long; clients that answer are no longer idle
+	MCPPingInterval int `mapstructure:"mcp_ping_interval"`
 }
 
 // LoggingConfig represents logging configuration
 type LoggingConfig struct {
-	Level  string `mapstructure:"level"`
-	Format string `mapstructure:"format"`
+	Level     string          `mapstructure:"level"`
+	Format    string          `mapstructure:"format"`
+	AccessLog AccessLogConfig `mapstructure:"access_log"`
+	Audit     AuditConfig     `mapstructure:"audit"`
+	Syslog    SyslogConfig    `mapstructure:"syslog"`
+	Journald  JournaldConfig  `mapstructure:"journald"`
+	Sampling  SamplingConfig  `mapstructure:"sampling"`
+}
+
+// AccessLogFields lists the fields an access log line can include
+var AccessLogFields = []string{"method", "id", "correlation_id", "tool", "session_id", "duration_ms", "result_bytes", "error_code"}
+
+// AccessLogConfig represents the per-request access log
+type AccessLogConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Fields selects which of AccessLogFields are logged
+	Fields []string `mapstructure:"fields"`
+	// SampleRate is the fraction of successful requests logged, from 0 to
+	// 1; failed requests are always logged
+	SampleRate float64 `mapstructure:"sample_rate"`
+}
+
+// AuditConfig represents the audit log of tool calls
+type AuditConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Directory holds one JSON lines file per day
+	Directory string `mapstructure:"directory"`
+	// Redact lists argument names whose values are not recorded
+	Redact []string `mapstructure:"redact"`
+	// RetentionDays is how many days of records are kept; 0 keeps them all
+	RetentionDays int `mapstructure:"retention_days"`
+}
+
+// SyslogFacilities lists the facilities syslog messages can be sent with
+var SyslogFacilities = []string{"kern", "user", "mail", "daemon", "auth", "syslog", "lpr", "news", "uucp", "cron", "authpriv", "ftp", "local0", "local1", "local2", "local3", "local4", "local5", "local6", "local7"}
+
+// SyslogConfig represents the copy of server logs sent to syslog
+type SyslogConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Network and Address reach a remote syslog server, e.g. "udp" and
+	// "logs.internal:514"; both empty use the local daemon
+	Network string `mapstructure:"network"`
+	Address string `mapstructure:"address"`
+	// Tag names the program in messages; empty uses the executable's name
+	Tag      string `mapstructure:"tag"`
+	Facility string `mapstructure:"facility"`
+}
+
+// JournaldConfig represents the copy of server logs sent to the systemd
+// journal, with log fields as journal fields
+type JournaldConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Identifier is the SYSLOG_IDENTIFIER of entries; empty uses the
+	// executable's name
+	Identifier string `mapstructure:"identifier"`
+}
+
+// SamplingConfig limits how often the same message is logged
+type SamplingConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Burst is how many entries with the same level and message are logged
+	// per interval
+	Burst int `mapstructure:"burst"`
+	// Interval is in seconds
+	Interval int `mapstructure:"interval"`
 }
 
 // MCPConfig represents MCP-specific configuration
@@ -36,6 +201,12 @@ type MCPConfig struct {
 	Instructions string            `mapstructure:"instructions"`
 	Capabilities CapabilityConfig  `mapstructure:"capabilities"`
 	Metadata     map[string]string `mapstructure:"metadata"`
+	// PageSize caps the items in each tools/list, resources/list and
+	// prompts/list page; 0 returns everything at once
+	PageSize int `mapstructure:"page_size"`
+	// MaxBlobBytes caps the decoded binary data in one tool result or
+	// resource read; 0 means unlimited
+	MaxBlobBytes int64 `mapstructure:"max_blob_bytes"`
 }
 
 // CapabilityConfig represents MCP capability configuration
@@ -44,6 +215,8 @@ type CapabilityConfig struct {
 	Resources ResourcesConfig `mapstructure:"resources"`
 	Prompts   PromptsConfig   `mapstructure:"prompts"`
 	Logging   bool            `mapstructure:"logging"`
+	// Completions enables completion/complete for tool and prompt arguments
+	Completions bool `mapstructure:"completions"`
 }
 
 // ToolsConfig represents tools capability configuration
@@ -67,23 +240,371 @@ type PromptsConfig struct {
 
 // SecurityConfig represents security configuration
 type SecurityConfig struct {
-	EnableTLS  bool     `mapstructure:"enable_tls"`
-	CertFile   string   `mapstructure:"cert_file"`
-	KeyFile    string   `mapstructure:"key_file"`
+	EnableTLS bool   `mapstructure:"enable_tls"`
+	CertFile  string `mapstructure:"cert_file"`
+	KeyFile   string `mapstructure:"key_file"`
+	// AllowedIPs lists addresses or CIDR ranges, e.g. "10.0.0.0/8" or "::1"
 	AllowedIPs []string `mapstructure:"allowed_ips"`
+	// AllowedOrigins restricts browser origins, e.g. "https://*.example.com"
+	AllowedOrigins []string `mapstructure:"allowed_origins"`
+	// APIKeys enables API key authentication when non-empty; keys can also
+	// be set with MCP_SECURITY_API_KEYS (comma separated)
+	APIKeys []string `mapstructure:"api_keys"`
+	// APIKeysFile names a file of additional API keys, one per line
+	APIKeysFile string    `mapstructure:"api_keys_file"`
+	JWT         JWTConfig `mapstructure:"jwt"`
+	// Access limits authenticated clients to some tools and resources
+	Access []AccessConfig `mapstructure:"access"`
+}
+
+// AccessConfig represents the tools and resources one client may use
+type AccessConfig struct {
+	// Client is the principal the rule applies to: "key:" and the first 16
+	// hex digits of the SHA-256 of an API key, "sub:" and a token subject,
+	// or "*" for clients without a rule of their own
+	Client string `mapstructure:"client"`
+	// Tools and Resources list tool names and resource URIs; * matches
+	// any run of characters
+	Tools     []string `mapstructure:"tools"`
+	Resources []string `mapstructure:"resources"`
+}
+
+// JWTConfig represents bearer token validation; setting JWKSURL enables it
+type JWTConfig struct {
+	JWKSURL  string   `mapstructure:"jwks_url"`
+	Issuer   string   `mapstructure:"issuer"`
+	Audience []string `mapstructure:"audience"`
+	// RefreshInterval is how long signing keys are cached, in seconds
+	RefreshInterval int `mapstructure:"refresh_interval"`
+	// Leeway tolerates clock skew when checking expiry, in seconds
+	Leeway int `mapstructure:"leeway"`
+}
+
+// HTTPClientConfig represents outbound HTTP client configuration shared by tools
+type HTTPClientConfig struct {
+	Timeout             int            `mapstructure:"timeout"`
+	ToolTimeouts        map[string]int `mapstructure:"tool_timeouts"`
+	MaxIdleConns        int            `mapstructure:"max_idle_conns"`
+	MaxIdleConnsPerHost int            `mapstructure:"max_idle_conns_per_host"`
+	IdleConnTimeout     int            `mapstructure:"idle_conn_timeout"`
+	ProxyURL            string         `mapstructure:"proxy_url"`
+	UserAgent           string         `mapstructure:"user_agent"`
+	InsecureSkipVerify  bool           `mapstructure:"insecure_skip_verify"`
+	CAFile              string         `mapstructure:"ca_file"`
+}
+
+// ToolSettings represents per-tool runtime settings
+type ToolSettings struct {
+	Directory        ToolDirectoryConfig    `mapstructure:"directory"`
+	Plugins          ToolPluginsConfig      `mapstructure:"plugins"`
+	External         []ExternalToolConfig   `mapstructure:"external"`
+	Proxies          []ToolProxyConfig      `mapstructure:"proxies"`
+	DocumentAnalyzer DocumentAnalyzerConfig `mapstructure:"document_analyzer"`
+	WebSearch        WebSearchConfig        `mapstructure:"web_search"`
+	Cache            ToolCacheConfig        `mapstructure:"cache"`
+	Database         DatabaseConfig         `mapstructure:"database"`
+	KVStore          KVStoreConfig          `mapstructure:"kv_store"`
+
+	// Aliases maps extra names to registered tools. Map keys are
+	// lower-cased when read from a config file.
+	Aliases map[string]string `mapstructure:"aliases"`
+	// PinnedVersions maps a tool name to the version served when several
+	// are registered; otherwise the newest is served
+	PinnedVersions map[string]string `mapstructure:"pinned_versions"`
+	// RateLimits caps calls to a tool from all clients together, unlike
+	// server.rate_limit.tools which applies per client
+	RateLimits map[string]ToolRateLimitConfig `mapstructure:"rate_limits"`
+	// Concurrency caps the calls of a tool running at once
+	Concurrency map[string]ToolConcurrencyConfig `mapstructure:"concurrency"`
+	// Retries repeats failed calls to a tool
+	Retries map[string]ToolRetryConfig `mapstructure:"retries"`
+}
+
+// ToolRetryConfig represents how failed calls to a tool are retried
+type ToolRetryConfig struct {
+	MaxAttempts int `mapstructure:"max_attempts"`
+	// Backoff is the wait before the first retry, in milliseconds; it
+	// doubles for each further retry up to MaxBackoff
+	Backoff    int `mapstructure:"backoff_ms"`
+	MaxBackoff int `mapstructure:"max_backoff_ms"`
+	// RetryErrorResults also retries error results, for tools that report
+	// upstream failures that way
+	RetryErrorResults bool `mapstructure:"retry_error_results"`
+}
+
+// ToolCacheConfig represents the cache of tool results
+type ToolCacheConfig struct {
+	// Tools lists the tools whose results are cached; empty disables the cache
+	Tools []string `mapstructure:"tools"`
+	// Global lists the cached tools whose results all clients share
+	Global []string `mapstructure:"global"`
+	// TTL is how long a result is reused, in seconds
+	TTL        int `mapstructure:"ttl"`
+	MaxEntries int `mapstructure:"max_entries"`
+}
+
+// ToolConcurrencyConfig represents how many calls to a tool may run at once
+type ToolConcurrencyConfig struct {
+	MaxConcurrent int `mapstructure:"max_concurrent"`
+	// QueueTimeout is how long excess calls wait, in seconds; 0 rejects them
+	QueueTimeout int `mapstructure:"queue_timeout"`
+}
+
+// ToolDirectoryConfig represents the directory of script tool manifests
+type ToolDirectoryConfig struct {
+	// Path is the directory to load manifests from; empty disables loading
+	Path  string `mapstructure:"path"`
+	Watch bool   `mapstructure:"watch"`
+}
+
+// ToolPluginsConfig represents the directory of Go plugins providing tools
+type ToolPluginsConfig struct {
+	// Path is the directory to load *.so plugins from; empty disables loading
+	Path string `mapstructure:"path"`
+}
+
+// ExternalToolConfig declares a tool that runs an external command, with
+// the same fields as a script tool manifest
+type ExternalToolConfig struct {
+	Name        string            `mapstructure:"name"`
+	Version     string            `mapstructure:"version"`
+	Description string            `mapstructure:"description"`
+	Category    string            `mapstructure:"category"`
+	Tags        []string          `mapstructure:"tags"`
+	Command     string            `mapstructure:"command"`
+	Args        []string          `mapstructure:"args"`
+	Env         map[string]string `mapstructure:"env"`
+	Timeout     int               `mapstructure:"timeout"` // Seconds
+	// MaxOutputBytes caps the command's stdout; 0 means server.max_message_bytes
+	MaxOutputBytes int64 `mapstructure:"max_output_bytes"`
+	// InputSchema is the tool's JSON Schema. Map keys are lower-cased when
+	// read from a config file, so property names must be lower case.
+	InputSchema map[string]interface{} `mapstructure:"input_schema"`
+}
+
+// ToolProxyConfig declares another MCP server whose tools are served as if
+// they were local. Exactly one of URL and Command is set.
+type ToolProxyConfig struct {
+	Name string `mapstructure:"name"`
+	// Namespace, when set, serves the tools as namespace/name
+	Namespace string `mapstructure:"namespace"`
+	// URL is the server's WebSocket endpoint
+	URL     string            `mapstructure:"url"`
+	Headers map[string]string `mapstructure:"headers"`
+	// Command starts the server as a subprocess speaking stdio
+	Command string   `mapstructure:"command"`
+	Args    []string `mapstructure:"args"`
+}
+
+// DatabaseConfig represents the SQL database behind the table schema
+// resources and the sql_query tool. The driver must be linked into the
+// server binary.
+type DatabaseConfig struct {
+	Enabled bool   `mapstructure:"enabled"`
+	Driver  string `mapstructure:"driver"`
+	// DSN is better set through MCP_TOOLS_DATABASE_DSN than in a file
+	DSN string `mapstructure:"dsn"`
+	// Dialect is sqlite, postgres or mysql; empty guesses it from Driver
+	Dialect string `mapstructure:"dialect"`
+	MaxRows int    `mapstructure:"max_rows"`
+	Timeout int    `mapstructure:"timeout"` // Seconds
+	// AllowedStatements lists the keywords a query may start with
+	AllowedStatements []string `mapstructure:"allowed_statements"`
+}
+
+// KVStoreConfig represents the kv_store scratchpad tool and its kv://
+// resources
+type KVStoreConfig struct {
+	Enabled       bool `mapstructure:"enabled"`
+	MaxEntries    int  `mapstructure:"max_entries"`
+	MaxValueBytes int  `mapstructure:"max_value_bytes"`
+	// MaxTotalEntries and MaxTotalBytes bound the entries of all clients
+	// together, since entries of credentials outlive their sessions
+	MaxTotalEntries int `mapstructure:"max_total_entries"`
+	MaxTotalBytes   int `mapstructure:"max_total_bytes"`
+}
+
+// DocumentAnalyzerConfig represents document analyzer tool settings
+type DocumentAnalyzerConfig struct {
+	// Parallelism bounds concurrent analysis stages; 0 uses the number of CPUs
+	Parallelism int `mapstructure:"parallelism"`
+}
+
+// WebSearchConfig represents web search tool settings
+type WebSearchConfig struct {
+	// Engines configures duckduckgo, searxng and brave by name
+	Engines map[string]SearchEngineSettings `mapstructure:"engines"`
+}
+
+// SearchEngineSettings represents one search engine's endpoint and
+// credentials
+type SearchEngineSettings struct {
+	Enabled bool   `mapstructure:"enabled"`
+	BaseURL string `mapstructure:"base_url"`
+	// APIKey authenticates requests; Brave requires one
+	APIKey string `mapstructure:"api_key"`
+	// RateLimit is the minimum number of seconds between requests
+	RateLimit float64 `mapstructure:"rate_limit"`
+	// Timeout bounds each request in seconds; 0 uses http_client.timeout
+	Timeout    int `mapstructure:"timeout"`
+	MaxRetries int `mapstructure:"max_retries"`
+}
+
+// SearchEngines are the engines the web search tool supports
+var SearchEngines = []string{"duckduckgo", "searxng", "brave"}
+
+// PromptSettings represents prompt runtime settings
+type PromptSettings struct {
+	// DefaultVersions maps a prompt name to the version served when a
+	// client does not request one, e.g. research_prompt: v2
+	DefaultVersions map[string]string `mapstructure:"default_versions"`
+	// VariantWeights splits requests made without a version between a
+	// prompt's variants, e.g. research_prompt: {v1: 50, v2: 50}
+	VariantWeights map[string]map[string]int `mapstructure:"variant_weights"`
+	// Dir is the directory to load JSON and YAML prompt definitions from;
+	// empty disables loading
+	Dir string `mapstructure:"dir"`
+	// Watch reloads the prompts directory as its files change
+	Watch bool `mapstructure:"watch"`
+}
+
+// ResourceSettings represents the resource providers
+type ResourceSettings struct {
+	Filesystem FilesystemResourcesConfig `mapstructure:"filesystem"`
+	HTTP       []HTTPResourceConfig      `mapstructure:"http"`
+	Cache      ResourceCacheConfig       `mapstructure:"cache"`
+}
+
+// HTTPResourceConfig declares a resource whose content is fetched from a URL
+type HTTPResourceConfig struct {
+	URI         string `mapstructure:"uri"`
+	Name        string `mapstructure:"name"`
+	Description string `mapstructure:"description"`
+	URL         string `mapstructure:"url"`
+	// MimeType overrides the Content-Type the server answers with
+	MimeType string `mapstructure:"mime_type"`
+}
+
+// ResourceCacheConfig represents the cache of resource contents
+type ResourceCacheConfig struct {
+	// TTL maps a provider (filesystem, http or database) to how long its
+	// contents are reused, in seconds; unlisted providers are not cached
+	TTL        map[string]int `mapstructure:"ttl"`
+	MaxEntries int            `mapstructure:"max_entries"`
+}
+
+// ResourceCacheProviders are the providers whose contents can be cached
+var ResourceCacheProviders = []string{"filesystem", "http", "database"}
+
+// FilesystemResourcesConfig represents the files served as file:// resources
+type FilesystemResourcesConfig struct {
+	Enabled bool     `mapstructure:"enabled"`
+	Roots   []string `mapstructure:"roots"`
+	// Include and Exclude are glob patterns for paths below a root, where
+	// ** matches any number of directories; empty Include serves all files
+	Include      []string `mapstructure:"include"`
+	Exclude      []string `mapstructure:"exclude"`
+	MaxFileBytes int64    `mapstructure:"max_file_bytes"`
+	MaxFiles     int      `mapstructure:"max_files"`
+	// Watch notifies subscribed clients when served files change; it needs
+	// mcp.capabilities.resources.subscribe
+	Watch bool `mapstructure:"watch"`
+}
+
+// TracingConfig represents OpenTelemetry trace export over OTLP/HTTP
+type TracingConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Endpoint is the collector's host:port, e.g. "localhost:4318"
+	Endpoint string `mapstructure:"endpoint"`
+	// URLPath overrides the collector's trace path; empty uses /v1/traces
+	URLPath  string            `mapstructure:"url_path"`
+	Insecure bool              `mapstructure:"insecure"`
+	Headers  map[string]string `mapstructure:"headers"`
+	// ServiceName defaults to mcp.name when empty
+	ServiceName string `mapstructure:"service_name"`
+	// SampleRatio is the fraction of traces recorded, from 0 to 1
+	SampleRatio float64 `mapstructure:"sample_ratio"`
+}
+
+// StateConfig represents the backend sharing sessions, cached results,
+// rate limits and notifications between replicas
+type StateConfig struct {
+	Backend string      `mapstructure:"backend"`
+	Redis   RedisConfig `mapstructure:"redis"`
+}
+
+// RedisConfig represents Redis connection configuration
+type RedisConfig struct {
+	Addr        string `mapstructure:"addr"`
+	Password    string `mapstructure:"password"`
+	DB          int    `mapstructure:"db"`
+	KeyPrefix   string `mapstructure:"key_prefix"`
+	DialTimeout int    `mapstructure:"dial_timeout"`
 }
 
 // DefaultConfig returns a configuration with default values
 func DefaultConfig() *Config {
 	return &Config{
 		Server: ServerConfig{
-			Host:    "localhost",
-			Port:    8030,
-			Timeout: 30,
+			Host:            "localhost",
+			Port:            8030,
+			Timeout:         30,
+			SessionTimeout:  1800,
+			ShutdownTimeout: 30,
+			TrustedProxies:  []string{},
+
+			MaxConnections:      0,
+			MaxInflightRequests: 32,
+			MaxMessageBytes:     4 << 20,
+			WebSocket: WebSocketConfig{
+				PingInterval:    30,
+				PongTimeout:     60,
+				IdleTimeout:     0,
+				MCPPingInterval: 0,
+			},
+			RateLimit: RateLimitConfig{
+				Enabled:           false,
+				RequestsPerSecond: 10,
+				Burst:             20,
+				Key:               RateLimitByConnection,
+				Tools:             make(map[string]ToolRateLimitConfig),
+			},
+			CORS: CORSConfig{
+				Enabled:          false,
+				AllowedOrigins:   []string{"*"},
+				AllowedMethods:   []string{"GET", "POST", "DELETE", "OPTIONS"},
+				AllowedHeaders:   []string{"Content-Type", "Authorization", "X-API-Key", "Mcp-Session-Id", "MCP-Protocol-Version", "Last-Event-ID"},
+				ExposedHeaders:   []string{"Mcp-Session-Id"},
+				AllowCredentials: false,
+				MaxAge:           600,
+			},
 		},
 		Logging: LoggingConfig{
 			Level:  "info",
 			Format: "json",
+			AccessLog: AccessLogConfig{
+				Enabled:    true,
+				Fields:     append([]string(nil), AccessLogFields...),
+				SampleRate: 1,
+			},
+			Audit: AuditConfig{
+				Enabled:       false,
+				Directory:     "logs/audit",
+				Redact:        []string{"password", "token", "secret", "api_key", "authorization"},
+				RetentionDays: 90,
+			},
+			Syslog: SyslogConfig{
+				Enabled:  false,
+				Facility: "daemon",
+			},
+			Journald: JournaldConfig{
+				Enabled: false,
+			},
+			Sampling: SamplingConfig{
+				Enabled:  false,
+				Burst:    10,
+				Interval: 60,
+			},
 		},
 		MCP: MCPConfig{
 			Name:        "mcp-go-template",
@@ -103,55 +624,275 @@ func DefaultConfig() *Config {
 					Enabled:     true,
 					ListChanged: false,
 				},
-				Logging: true,
+				Logging:     true,
+				Completions: true,
 			},
-			Metadata: make(map[string]string),
+			Metadata:     make(map[string]string),
+			PageSize:     100,
+			MaxBlobBytes: 2 << 20,
 		},
 		Security: SecurityConfig{
-			EnableTLS:  false,
-			AllowedIPs: []string{},
+			EnableTLS:      false,
+			AllowedIPs:     []string{},
+			AllowedOrigins: []string{},
+			APIKeys:        []string{},
+			Access:         []AccessConfig{},
+			JWT: JWTConfig{
+				Audience:        []string{},
+				RefreshInterval: 3600,
+				Leeway:          30,
+			},
+		},
+		HTTPClient: HTTPClientConfig{
+			Timeout:             30,
+			ToolTimeouts:        make(map[string]int),
+			MaxIdleConns:        100,
+			MaxIdleConnsPerHost: 10,
+			IdleConnTimeout:     90,
+			UserAgent:           "Mozilla/5.0 (compatible; MCP-Go-Template/1.0)",
+		},
+		Tools: ToolSettings{
+			Directory: ToolDirectoryConfig{
+				Watch: true,
+			},
+			Aliases:        make(map[string]string),
+			PinnedVersions: make(map[string]string),
+			RateLimits:     make(map[string]ToolRateLimitConfig),
+			Concurrency:    make(map[string]ToolConcurrencyConfig),
+			Retries:        make(map[string]ToolRetryConfig),
+			Cache: ToolCacheConfig{
+				Tools:      []string{},
+				Global:     []string{},
+				TTL:        300,
+				MaxEntries: 1000,
+			},
+			Database: DatabaseConfig{
+				Enabled:           false,
+				MaxRows:           100,
+				Timeout:           10,
+				AllowedStatements: []string{"select", "with"},
+			},
+			KVStore: KVStoreConfig{
+				Enabled:         true,
+				MaxEntries:      1000,
+				MaxValueBytes:   64 << 10,
+				MaxTotalEntries: 100000,
+				MaxTotalBytes:   64 << 20,
+			},
+			WebSearch: WebSearchConfig{
+				Engines: map[string]SearchEngineSettings{
+					"duckduckgo": {
+						Enabled:    true,
+						BaseURL:    "https://api.duckduckgo.com/",
+						RateLimit:  2,
+						MaxRetries: 2,
+					},
+					"searxng": {
+						Enabled:    true,
+						BaseURL:    "https://search.sapti.me/search",
+						RateLimit:  3,
+						MaxRetries: 2,
+					},
+					"brave": {
+						Enabled:    false,
+						BaseURL:    "https://api.search.brave.com/res/v1/web/search",
+						RateLimit:  1,
+						MaxRetries: 2,
+					},
+				},
+			},
+		},
+		Prompts: PromptSettings{
+			DefaultVersions: make(map[string]string),
+			VariantWeights:  make(map[string]map[string]int),
+			Watch:           true,
+		},
+		Resources: ResourceSettings{
+			Filesystem: FilesystemResourcesConfig{
+				Enabled:      false,
+				Roots:        []string{},
+				Include:      []string{},
+				Exclude:      []string{".*"},
+				MaxFileBytes: 1 << 20,
+				MaxFiles:     1000,
+				Watch:        true,
+			},
+			HTTP: []HTTPResourceConfig{},
+			Cache: ResourceCacheConfig{
+				TTL:        make(map[string]int),
+				MaxEntries: 1000,
+			},
+		},
+		Tracing: TracingConfig{
+			Endpoint:    "localhost:4318",
+			Headers:     make(map[string]string),
+			SampleRatio: 1,
+		},
+		State: StateConfig{
+			Backend: state.BackendMemory,
+			Redis: RedisConfig{
+				Addr:        "localhost:6379",
+				KeyPrefix:   "mcp:",
+				DialTimeout: 5,
+			},
 		},
 	}
 }
 
-// Load loads configuration from various sources
+// ConfigFormats are the config file formats Load reads
+var ConfigFormats = []string{"yaml", "json", "toml"}
+
+// configPaths are searched in order for a config file when none is given
+var configPaths = []string{".", "./config", "/etc/mcp-go-template", "$HOME/.mcp-go-template"}
+
+// Load loads configuration from various sources, reading the config file
+// in the format its extension names
 func Load(configPath string) (*Config, error) {
+	return LoadFormat(configPath, "")
+}
+
+// LoadFormat loads configuration like Load, reading the config file as
+// format, one of ConfigFormats. An empty format is taken from the file's
+// extension, with yaml for other extensions.
+func LoadFormat(configPath, format string) (*Config, error) {
+	return defaultLoader.LoadFormat(configPath, format)
+}
+
+// Load loads configuration from various sources like the package's Load
+func (l *Loader) Load(configPath string) (*Config, error) {
+	return l.LoadFormat(configPath, "")
+}
+
+// LoadFormat loads configuration like the package's LoadFormat
+func (l *Loader) LoadFormat(configPath, format string) (*Config, error) {
+	if format == "yml" {
+		format = "yaml"
+	}
+	if format != "" && !slices.Contains(ConfigFormats, format) {
+		return nil, fmt.Errorf("unsupported config format: %s (supported: %s)", format, strings.Join(ConfigFormats, ", "))
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
 	// Set default values
 	config := DefaultConfig()
-	
+
 	// Configure viper
-	viper.SetConfigName("config")
-	viper.SetConfigType("yaml")
-	
+	l.v.SetConfigName("config")
+
+	if configPath == "" {
+		configPath = findConfigFile(format)
+	}
 	if configPath != "" {
-		viper.SetConfigFile(configPath)
+		l.v.SetConfigFile(configPath)
+		if format == "" {
+			format = formatOf(configPath)
+		}
 	} else {
-		viper.AddConfigPath(".")
-		viper.AddConfigPath("./config")
-		viper.AddConfigPath("/etc/mcp-go-template")
-		viper.AddConfigPath("$HOME/.mcp-go-template")
+		for _, path := range configPaths {
+			l.v.AddConfigPath(path)
+		}
+	}
+	if format == "" {
+		format = "yaml"
 	}
+	l.v.SetConfigType(format)
 
 	// Read environment variables
-	viper.AutomaticEnv()
-	viper.SetEnvPrefix("MCP")
-	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	l.v.AutomaticEnv()
+	l.v.SetEnvPrefix("MCP")
+	l.v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
 
 	// Set default values in viper
-	setDefaults(config)
+	setDefaults(l.v, config)
+
+	return l.read(config)
+}
 
+// findConfigFile returns the first config file in configPaths, named
+// config.yaml, config.yml, config.json or config.toml, or only with the
+// extensions of format when it is set
+func findConfigFile(format string) string {
+	extensions := []string{"yaml", "yml", "json", "toml"}
+	if format != "" {
+		extensions = []string{format}
+		if format == "yaml" {
+			extensions = append(extensions, "yml")
+		}
+	}
+	for _, dir := range configPaths {
+		for _, extension := range extensions {
+			path := filepath.Join(os.ExpandEnv(dir), "config."+extension)
+			if info, err := os.Stat(path); err == nil && !info.IsDir() {
+				return path
+			}
+		}
+	}
+	return ""
+}
+
+// formatOf returns the config format a file's extension names, or "" for
+// other extensions
+func formatOf(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return "yaml"
+	case ".json":
+		return "json"
+	case ".toml":
+		return "toml"
+	}
+	return ""
+}
+
+// FileUsed returns the config file Load read, or "" when none was found
+func FileUsed() string {
+	return defaultLoader.FileUsed()
+}
+
+// FileUsed returns the config file the loader read, or "" when none was
+// found
+func (l *Loader) FileUsed() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.v.ConfigFileUsed()
+}
+
+// read fills config from the config file, environment variables and the
+// defaults set in the loader's viper, and validates it; l.mu must be held
+func (l *Loader) read(config *Config) (*Config, error) {
 	// Try to read config file
-	if err := viper.ReadInConfig(); err != nil {
+	if err := l.v.ReadInConfig(); err != nil {
 		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
 			return nil, fmt.Errorf("error reading config file: %w", err)
 		}
 		// Config file not found, use defaults and environment variables
 	}
 
+	// Merge the active profile over the config file
+	profile, err := l.mergeProfile()
+	if err != nil {
+		return nil, err
+	}
+
 	// Unmarshal config
-	if err := viper.Unmarshal(config); err != nil {
+	if err := l.v.Unmarshal(config); err != nil {
 		return nil, fmt.Errorf("error unmarshaling config: %w", err)
 	}
+	config.profile = profile
+
+	// Expand ${ENV_VAR} references and fetch secret:// and vault:// values
+	secrets, err := resolveSecrets(config)
+	if err != nil {
+		return nil, fmt.Errorf("error resolving secrets: %w", err)
+	}
+	config.secrets = secrets
+
+	// Merge API keys kept outside the config file
+	if err := loadAPIKeysFile(config); err != nil {
+		return nil, err
+	}
 
 	// Validate configuration
 	if err := validate(config); err != nil {
@@ -161,33 +902,190 @@ func Load(configPath string) (*Config, error) {
 	return config, nil
 }
 
+// loadAPIKeysFile appends the keys in security.api_keys_file to
+// security.api_keys, skipping blank lines and # comments
+func loadAPIKeysFile(config *Config) error {
+	if config.Security.APIKeysFile == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(config.Security.APIKeysFile)
+	if err != nil {
+		return fmt.Errorf("error reading API keys file: %w", err)
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		key := strings.TrimSpace(line)
+		if key == "" || strings.HasPrefix(key, "#") {
+			continue
+		}
+		config.Security.APIKeys = append(config.Security.APIKeys, key)
+	}
+	return nil
+}
+
 // setDefaults sets default values in viper
-func setDefaults(config *Config) {
-	viper.SetDefault("server.host", config.Server.Host)
-	viper.SetDefault("server.port", config.Server.Port)
-	viper.SetDefault("server.timeout", config.Server.Timeout)
-	
-	viper.SetDefault("logging.level", config.Logging.Level)
-	viper.SetDefault("logging.format", config.Logging.Format)
-	
-	viper.SetDefault("mcp.name", config.MCP.Name)
-	viper.SetDefault("mcp.version", config.MCP.Version)
-	viper.SetDefault("mcp.description", config.MCP.Description)
-	viper.SetDefault("mcp.instructions", config.MCP.Instructions)
-	
-	viper.SetDefault("mcp.capabilities.tools.enabled", config.MCP.Capabilities.Tools.Enabled)
-	viper.SetDefault("mcp.capabilities.tools.list_changed", config.MCP.Capabilities.Tools.ListChanged)
-	viper.SetDefault("mcp.capabilities.resources.enabled", config.MCP.Capabilities.Resources.Enabled)
-	viper.SetDefault("mcp.capabilities.resources.subscribe", config.MCP.Capabilities.Resources.Subscribe)
-	viper.SetDefault("mcp.capabilities.resources.list_changed", config.MCP.Capabilities.Resources.ListChanged)
-	viper.SetDefault("mcp.capabilities.prompts.enabled", config.MCP.Capabilities.Prompts.Enabled)
-	viper.SetDefault("mcp.capabilities.prompts.list_changed", config.MCP.Capabilities.Prompts.ListChanged)
-	viper.SetDefault("mcp.capabilities.logging", config.MCP.Capabilities.Logging)
-	
-	viper.SetDefault("security.enable_tls", config.Security.EnableTLS)
-	viper.SetDefault("security.cert_file", config.Security.CertFile)
-	viper.SetDefault("security.key_file", config.Security.KeyFile)
-	viper.SetDefault("security.allowed_ips", config.Security.AllowedIPs)
+func setDefaults(v *viper.Viper, config *Config) {
+	v.SetDefault("server.host", config.Server.Host)
+	v.SetDefault("server.port", config.Server.Port)
+	v.SetDefault("server.timeout", config.Server.Timeout)
+	v.SetDefault("server.session_timeout", config.Server.SessionTimeout)
+	v.SetDefault("server.shutdown_timeout", config.Server.ShutdownTimeout)
+	v.SetDefault("server.max_connections", config.Server.MaxConnections)
+	v.SetDefault("server.max_inflight_requests", config.Server.MaxInflightRequests)
+	v.SetDefault("server.max_message_bytes", config.Server.MaxMessageBytes)
+	v.SetDefault("server.socket_path", config.Server.SocketPath)
+	v.SetDefault("server.trusted_proxies", config.Server.TrustedProxies)
+	v.SetDefault("server.enable_pprof", config.Server.EnablePprof)
+	v.SetDefault("server.watch_config", config.Server.WatchConfig)
+	v.SetDefault("server.rate_limit.enabled", config.Server.RateLimit.Enabled)
+	v.SetDefault("server.rate_limit.requests_per_second", config.Server.RateLimit.RequestsPerSecond)
+	v.SetDefault("server.rate_limit.burst", config.Server.RateLimit.Burst)
+	v.SetDefault("server.rate_limit.key", config.Server.RateLimit.Key)
+	v.SetDefault("server.rate_limit.tools", config.Server.RateLimit.Tools)
+	v.SetDefault("server.websocket.ping_interval", config.Server.WebSocket.PingInterval)
+	v.SetDefault("server.websocket.pong_timeout", config.Server.WebSocket.PongTimeout)
+	v.SetDefault("server.websocket.idle_timeout", config.Server.WebSocket.IdleTimeout)
+	v.SetDefault("server.websocket.mcp_ping_interval", config.Server.WebSocket.MCPPingInterval)
+	v.SetDefault("server.cors.enabled", config.Server.CORS.Enabled)
+	v.SetDefault("server.cors.allowed_origins", config.Server.CORS.AllowedOrigins)
+	v.SetDefault("server.cors.allowed_methods", config.Server.CORS.AllowedMethods)
+	v.SetDefault("server.cors.allowed_headers", config.Server.CORS.AllowedHeaders)
+	v.SetDefault("server.cors.exposed_headers", config.Server.CORS.ExposedHeaders)
+	v.SetDefault("server.cors.allow_credentials", config.Server.CORS.AllowCredentials)
+	v.SetDefault("server.cors.max_age", config.Server.CORS.MaxAge)
+
+	v.SetDefault("logging.level", config.Logging.Level)
+	v.SetDefault("logging.format", config.Logging.Format)
+	v.SetDefault("logging.access_log.enabled", config.Logging.AccessLog.Enabled)
+	v.SetDefault("logging.access_log.fields", config.Logging.AccessLog.Fields)
+	v.SetDefault("logging.access_log.sample_rate", config.Logging.AccessLog.SampleRate)
+	v.SetDefault("logging.audit.enabled", config.Logging.Audit.Enabled)
+	v.SetDefault("logging.audit.directory", config.Logging.Audit.Directory)
+	v.SetDefault("logging.audit.redact", config.Logging.Audit.Redact)
+	v.SetDefault("logging.audit.retention_days", config.Logging.Audit.RetentionDays)
+	v.SetDefault("logging.syslog.enabled", config.Logging.Syslog.Enabled)
+	v.SetDefault("logging.syslog.network", config.Logging.Syslog.Network)
+	v.SetDefault("logging.syslog.address", config.Logging.Syslog.Address)
+	v.SetDefault("logging.syslog.tag", config.Logging.Syslog.Tag)
+	v.SetDefault("logging.syslog.facility", config.Logging.Syslog.Facility)
+	v.SetDefault("logging.journald.enabled", config.Logging.Journald.Enabled)
+	v.SetDefault("logging.journald.identifier", config.Logging.Journald.Identifier)
+	v.SetDefault("logging.sampling.enabled", config.Logging.Sampling.Enabled)
+	v.SetDefault("logging.sampling.burst", config.Logging.Sampling.Burst)
+	v.SetDefault("logging.sampling.interval", config.Logging.Sampling.Interval)
+
+	v.SetDefault("mcp.name", config.MCP.Name)
+	v.SetDefault("mcp.version", config.MCP.Version)
+	v.SetDefault("mcp.description", config.MCP.Description)
+	v.SetDefault("mcp.instructions", config.MCP.Instructions)
+	v.SetDefault("mcp.page_size", config.MCP.PageSize)
+	v.SetDefault("mcp.max_blob_bytes", config.MCP.MaxBlobBytes)
+
+	v.SetDefault("mcp.capabilities.tools.enabled", config.MCP.Capabilities.Tools.Enabled)
+	v.SetDefault("mcp.capabilities.tools.list_changed", config.MCP.Capabilities.Tools.ListChanged)
+	v.SetDefault("mcp.capabilities.resources.enabled", config.MCP.Capabilities.Resources.Enabled)
+	v.SetDefault("mcp.capabilities.resources.subscribe", config.MCP.Capabilities.Resources.Subscribe)
+	v.SetDefault("mcp.capabilities.resources.list_changed", config.MCP.Capabilities.Resources.ListChanged)
+	v.SetDefault("mcp.capabilities.prompts.enabled", config.MCP.Capabilities.Prompts.Enabled)
+	v.SetDefault("mcp.capabilities.prompts.list_changed", config.MCP.Capabilities.Prompts.ListChanged)
+	v.SetDefault("mcp.capabilities.logging", config.MCP.Capabilities.Logging)
+	v.SetDefault("mcp.capabilities.completions", config.MCP.Capabilities.Completions)
+
+	v.SetDefault("security.enable_tls", config.Security.EnableTLS)
+	v.SetDefault("security.cert_file", config.Security.CertFile)
+	v.SetDefault("security.key_file", config.Security.KeyFile)
+	v.SetDefault("security.allowed_ips", config.Security.AllowedIPs)
+	v.SetDefault("security.allowed_origins", config.Security.AllowedOrigins)
+	v.SetDefault("security.api_keys", config.Security.APIKeys)
+	v.SetDefault("security.api_keys_file", config.Security.APIKeysFile)
+	v.SetDefault("security.access", config.Security.Access)
+	v.SetDefault("security.jwt.jwks_url", config.Security.JWT.JWKSURL)
+	v.SetDefault("security.jwt.issuer", config.Security.JWT.Issuer)
+	v.SetDefault("security.jwt.audience", config.Security.JWT.Audience)
+	v.SetDefault("security.jwt.refresh_interval", config.Security.JWT.RefreshInterval)
+	v.SetDefault("security.jwt.leeway", config.Security.JWT.Leeway)
+
+	v.SetDefault("http_client.timeout", config.HTTPClient.Timeout)
+	v.SetDefault("http_client.tool_timeouts", config.HTTPClient.ToolTimeouts)
+	v.SetDefault("http_client.max_idle_conns", config.HTTPClient.MaxIdleConns)
+	v.SetDefault("http_client.max_idle_conns_per_host", config.HTTPClient.MaxIdleConnsPerHost)
+	v.SetDefault("http_client.idle_conn_timeout", config.HTTPClient.IdleConnTimeout)
+	v.SetDefault("http_client.proxy_url", config.HTTPClient.ProxyURL)
+	v.SetDefault("http_client.user_agent", config.HTTPClient.UserAgent)
+	v.SetDefault("http_client.insecure_skip_verify", config.HTTPClient.InsecureSkipVerify)
+	v.SetDefault("http_client.ca_file", config.HTTPClient.CAFile)
+
+	v.SetDefault("tools.directory.path", config.Tools.Directory.Path)
+	v.SetDefault("tools.directory.watch", config.Tools.Directory.Watch)
+	v.SetDefault("tools.plugins.path", config.Tools.Plugins.Path)
+	v.SetDefault("tools.external", config.Tools.External)
+	v.SetDefault("tools.proxies", config.Tools.Proxies)
+	v.SetDefault("tools.aliases", config.Tools.Aliases)
+	v.SetDefault("tools.pinned_versions", config.Tools.PinnedVersions)
+	v.SetDefault("tools.rate_limits", config.Tools.RateLimits)
+	v.SetDefault("tools.concurrency", config.Tools.Concurrency)
+	v.SetDefault("tools.retries", config.Tools.Retries)
+	v.SetDefault("tools.cache.tools", config.Tools.Cache.Tools)
+	v.SetDefault("tools.cache.global", config.Tools.Cache.Global)
+	v.SetDefault("tools.cache.ttl", config.Tools.Cache.TTL)
+	v.SetDefault("tools.cache.max_entries", config.Tools.Cache.MaxEntries)
+	v.SetDefault("tools.database.enabled", config.Tools.Database.Enabled)
+	v.SetDefault("tools.database.driver", config.Tools.Database.Driver)
+	v.SetDefault("tools.database.dsn", config.Tools.Database.DSN)
+	v.SetDefault("tools.database.dialect", config.Tools.Database.Dialect)
+	v.SetDefault("tools.database.max_rows", config.Tools.Database.MaxRows)
+	v.SetDefault("tools.database.timeout", config.Tools.Database.Timeout)
+	v.SetDefault("tools.database.allowed_statements", config.Tools.Database.AllowedStatements)
+	v.SetDefault("tools.kv_store.enabled", config.Tools.KVStore.Enabled)
+	v.SetDefault("tools.kv_store.max_entries", config.Tools.KVStore.MaxEntries)
+	v.SetDefault("tools.kv_store.max_value_bytes", config.Tools.KVStore.MaxValueBytes)
+	v.SetDefault("tools.kv_store.max_total_entries", config.Tools.KVStore.MaxTotalEntries)
+	v.SetDefault("tools.kv_store.max_total_bytes", config.Tools.KVStore.MaxTotalBytes)
+	v.SetDefault("tools.document_analyzer.parallelism", config.Tools.DocumentAnalyzer.Parallelism)
+	// Engine defaults are set field by field so a config file can override
+	// some settings of an engine and keep the rest
+	for name, engine := range config.Tools.WebSearch.Engines {
+		prefix := "tools.web_search.engines." + name + "."
+		v.SetDefault(prefix+"enabled", engine.Enabled)
+		v.SetDefault(prefix+"base_url", engine.BaseURL)
+		v.SetDefault(prefix+"api_key", engine.APIKey)
+		v.SetDefault(prefix+"rate_limit", engine.RateLimit)
+		v.SetDefault(prefix+"timeout", engine.Timeout)
+		v.SetDefault(prefix+"max_retries", engine.MaxRetries)
+	}
+
+	v.SetDefault("prompts.default_versions", config.Prompts.DefaultVersions)
+	v.SetDefault("prompts.variant_weights", config.Prompts.VariantWeights)
+	v.SetDefault("prompts.dir", config.Prompts.Dir)
+	v.SetDefault("prompts.watch", config.Prompts.Watch)
+
+	// Resources defaults
+	v.SetDefault("resources.filesystem.enabled", config.Resources.Filesystem.Enabled)
+	v.SetDefault("resources.filesystem.roots", config.Resources.Filesystem.Roots)
+	v.SetDefault("resources.filesystem.include", config.Resources.Filesystem.Include)
+	v.SetDefault("resources.filesystem.exclude", config.Resources.Filesystem.Exclude)
+	v.SetDefault("resources.filesystem.max_file_bytes", config.Resources.Filesystem.MaxFileBytes)
+	v.SetDefault("resources.filesystem.max_files", config.Resources.Filesystem.MaxFiles)
+	v.SetDefault("resources.filesystem.watch", config.Resources.Filesystem.Watch)
+	v.SetDefault("resources.http", config.Resources.HTTP)
+	v.SetDefault("resources.cache.ttl", config.Resources.Cache.TTL)
+	v.SetDefault("resources.cache.max_entries", config.Resources.Cache.MaxEntries)
+
+	v.SetDefault("tracing.enabled", config.Tracing.Enabled)
+	v.SetDefault("tracing.endpoint", config.Tracing.Endpoint)
+	v.SetDefault("tracing.url_path", config.Tracing.URLPath)
+	v.SetDefault("tracing.insecure", config.Tracing.Insecure)
+	v.SetDefault("tracing.headers", config.Tracing.Headers)
+	v.SetDefault("tracing.service_name", config.Tracing.ServiceName)
+	v.SetDefault("tracing.sample_ratio", config.Tracing.SampleRatio)
+
+	v.SetDefault("state.backend", config.State.Backend)
+	v.SetDefault("state.redis.addr", config.State.Redis.Addr)
+	v.SetDefault("state.redis.password", config.State.Redis.Password)
+	v.SetDefault("state.redis.db", config.State.Redis.DB)
+	v.SetDefault("state.redis.key_prefix", config.State.Redis.KeyPrefix)
+	v.SetDefault("state.redis.dial_timeout", config.State.Redis.DialTimeout)
 }
 
 // validate validates the configuration
@@ -200,6 +1098,46 @@ func validate(config *Config) error {
 		return fmt.Errorf("server timeout must be positive: %d", config.Server.Timeout)
 	}
 
+	if config.Server.SessionTimeout <= 0 {
+		return fmt.Errorf("server session timeout must be positive: %d", config.Server.SessionTimeout)
+	}
+
+	if config.Server.ShutdownTimeout <= 0 {
+		return fmt.Errorf("server shutdown timeout must be positive: %d", config.Server.ShutdownTimeout)
+	}
+
+	if config.Server.MaxConnections < 0 || config.Server.MaxInflightRequests < 0 {
+		return fmt.Errorf("server connection limits must not be negative")
+	}
+
+	if config.Server.MaxMessageBytes < 0 {
+		return fmt.Errorf("server max message bytes must not be negative: %d", config.Server.MaxMessageBytes)
+	}
+
+	if cors := config.Server.CORS; cors.AllowCredentials && slices.Contains(cors.AllowedOrigins, "*") {
+		return fmt.Errorf("server.cors.allow_credentials needs explicit allowed_origins, not \"*\"")
+	}
+
+	ws := config.Server.WebSocket
+	if ws.PingInterval < 0 || ws.PongTimeout < 0 || ws.IdleTimeout < 0 {
+		return fmt.Errorf("websocket keepalive settings must not be negative")
+	}
+	if ws.PingInterval > 0 && ws.PongTimeout <= ws.PingInterval {
+		return fmt.Errorf("websocket pong timeout (%d) must be greater than ping interval (%d)", ws.PongTimeout, ws.PingInterval)
+	}
+
+	if err := validateRateLimit(config.Server.RateLimit); err != nil {
+		return err
+	}
+
+	if _, err := config.GetAllowedIPPrefixes(); err != nil {
+		return fmt.Errorf("invalid security.allowed_ips: %w", err)
+	}
+
+	if _, err := ParseIPPrefixes(config.Server.TrustedProxies); err != nil {
+		return fmt.Errorf("invalid server.trusted_proxies: %w", err)
+	}
+
 	validLogLevels := map[string]bool{
 		"debug": true, "info": true, "warn": true, "error": true,
 	}
@@ -214,6 +1152,52 @@ func validate(config *Config) error {
 		return fmt.Errorf("invalid log format: %s", config.Logging.Format)
 	}
 
+	accessLog := config.Logging.AccessLog
+	if accessLog.SampleRate < 0 || accessLog.SampleRate > 1 {
+		return fmt.Errorf("access log sample rate must be between 0 and 1: %g", accessLog.SampleRate)
+	}
+	for _, field := range accessLog.Fields {
+		if !slices.Contains(AccessLogFields, field) {
+			return fmt.Errorf("invalid access log field: %s", field)
+		}
+	}
+
+	if audit := config.Logging.Audit; audit.Enabled {
+		if audit.Directory == "" {
+			return fmt.Errorf("logging.audit.directory cannot be empty when the audit log is enabled")
+		}
+		if audit.RetentionDays < 0 {
+			return fmt.Errorf("logging.audit.retention_days cannot be negative")
+		}
+	}
+
+	if syslog := config.Logging.Syslog; syslog.Enabled {
+		if !slices.Contains(SyslogFacilities, syslog.Facility) {
+			return fmt.Errorf("invalid syslog facility: %s", syslog.Facility)
+		}
+		switch syslog.Network {
+		case "":
+			if syslog.Address != "" {
+				return fmt.Errorf("logging.syslog.network cannot be empty when logging.syslog.address is set")
+			}
+		case "udp", "tcp", "unix", "unixgram":
+			if syslog.Address == "" {
+				return fmt.Errorf("logging.syslog.address cannot be empty when logging.syslog.network is set")
+			}
+		default:
+			return fmt.Errorf("invalid syslog network: %s (want udp, tcp, unix or unixgram)", syslog.Network)
+		}
+	}
+
+	if sampling := config.Logging.Sampling; sampling.Enabled {
+		if sampling.Burst <= 0 {
+			return fmt.Errorf("logging.sampling.burst must be positive when sampling is enabled")
+		}
+		if sampling.Interval <= 0 {
+			return fmt.Errorf("logging.sampling.interval must be positive when sampling is enabled")
+		}
+	}
+
 	if config.MCP.Name == "" {
 		return fmt.Errorf("MCP name cannot be empty")
 	}
@@ -222,6 +1206,14 @@ func validate(config *Config) error {
 		return fmt.Errorf("MCP version cannot be empty")
 	}
 
+	if config.MCP.PageSize < 0 {
+		return fmt.Errorf("mcp.page_size cannot be negative")
+	}
+
+	if config.MCP.MaxBlobBytes < 0 {
+		return fmt.Errorf("mcp.max_blob_bytes cannot be negative")
+	}
+
 	if config.Security.EnableTLS {
 		if config.Security.CertFile == "" {
 			return fmt.Errorf("cert file is required when TLS is enabled")
@@ -231,14 +1223,246 @@ func validate(config *Config) error {
 		}
 	}
 
+	if config.HTTPClient.Timeout <= 0 {
+		return fmt.Errorf("http client timeout must be positive: %d", config.HTTPClient.Timeout)
+	}
+
+	if config.Tools.DocumentAnalyzer.Parallelism < 0 {
+		return fmt.Errorf("document analyzer parallelism cannot be negative: %d", config.Tools.DocumentAnalyzer.Parallelism)
+	}
+
+	for name, engine := range config.Tools.WebSearch.Engines {
+		if !slices.Contains(SearchEngines, name) {
+			return fmt.Errorf("unknown search engine in tools.web_search.engines: %s (supported: %s)", name, strings.Join(SearchEngines, ", "))
+		}
+		if engine.RateLimit < 0 || engine.Timeout < 0 || engine.MaxRetries < 0 {
+			return fmt.Errorf("search engine %s: rate_limit, timeout and max_retries cannot be negative", name)
+		}
+		if !engine.Enabled {
+			continue
+		}
+		if u, err := url.Parse(engine.BaseURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("search engine %s: invalid base_url: %q", name, engine.BaseURL)
+		}
+		if name == "brave" && engine.APIKey == "" {
+			return fmt.Errorf("search engine brave needs an api_key when enabled")
+		}
+	}
+
+	if config.Resources.Filesystem.Enabled && len(config.Resources.Filesystem.Roots) == 0 {
+		return fmt.Errorf("resources.filesystem needs at least one root when enabled")
+	}
+	if config.Resources.Filesystem.MaxFileBytes < 0 || config.Resources.Filesystem.MaxFiles < 0 {
+		return fmt.Errorf("resources.filesystem max_file_bytes and max_files cannot be negative")
+	}
+	uris := make(map[string]bool)
+	for _, resource := range config.Resources.HTTP {
+		if resource.URI == "" || resource.URL == "" {
+			return fmt.Errorf("resources.http entries need a uri and a url")
+		}
+		if uris[resource.URI] {
+			return fmt.Errorf("duplicate resources.http uri: %s", resource.URI)
+		}
+		uris[resource.URI] = true
+	}
+	for provider, ttl := range config.Resources.Cache.TTL {
+		if !slices.Contains(ResourceCacheProviders, provider) {
+			return fmt.Errorf("invalid resources.cache.ttl provider: %s (must be one of %s)", provider, strings.Join(ResourceCacheProviders, ", "))
+		}
+		if ttl < 0 {
+			return fmt.Errorf("resources.cache.ttl for %s cannot be negative", provider)
+		}
+	}
+	if config.Resources.Cache.MaxEntries < 0 {
+		return fmt.Errorf("resources.cache.max_entries cannot be negative")
+	}
+
+	clients := make(map[string]bool)
+	for i, access := range config.Security.Access {
+		if access.Client == "" {
+			return fmt.Errorf("security.access rule %d needs a client", i)
+		}
+		if clients[access.Client] {
+			return fmt.Errorf("security.access has more than one rule for client '%s'", access.Client)
+		}
+		clients[access.Client] = true
+	}
+
+	for i, tool := range config.Tools.External {
+		if tool.Name == "" || tool.Command == "" {
+			return fmt.Errorf("external tool %d needs a name and a command", i)
+		}
+		if tool.MaxOutputBytes < 0 {
+			return fmt.Errorf("external tool '%s' max output bytes must not be negative: %d", tool.Name, tool.MaxOutputBytes)
+		}
+	}
+
+	namespaces := make(map[string]string)
+	for i, proxy := range config.Tools.Proxies {
+		if proxy.Name == "" {
+			return fmt.Errorf("tool proxy %d needs a name", i)
+		}
+		if (proxy.URL == "") == (proxy.Command == "") {
+			return fmt.Errorf("tool proxy '%s' needs exactly one of url and command", proxy.Name)
+		}
+		if proxy.Namespace == "" {
+			continue
+		}
+		if other, taken := namespaces[proxy.Namespace]; taken {
+			return fmt.Errorf("tool proxies '%s' and '%s' share namespace '%s'", other, proxy.Name, proxy.Namespace)
+		}
+		namespaces[proxy.Namespace] = proxy.Name
+	}
+
+	for tool, limit := range config.Tools.RateLimits {
+		if limit.RequestsPerSecond <= 0 || limit.Burst <= 0 {
+			return fmt.Errorf("tools.rate_limits for tool '%s' needs a positive requests_per_second and burst", tool)
+		}
+	}
+
+	for tool, limit := range config.Tools.Concurrency {
+		if limit.MaxConcurrent <= 0 || limit.QueueTimeout < 0 {
+			return fmt.Errorf("tools.concurrency for tool '%s' needs a positive max_concurrent and a queue_timeout of at least 0", tool)
+		}
+	}
+
+	for tool, retry := range config.Tools.Retries {
+		if retry.MaxAttempts < 1 || retry.Backoff < 0 || retry.MaxBackoff < 0 {
+			return fmt.Errorf("tools.retries for tool '%s' needs max_attempts of at least 1 and backoffs of at least 0", tool)
+		}
+	}
+
+	if config.Tools.Database.Enabled {
+		if config.Tools.Database.Driver == "" || config.Tools.Database.DSN == "" {
+			return fmt.Errorf("tools.database needs a driver and a dsn when enabled")
+		}
+		if !slices.Contains([]string{"", "sqlite", "postgres", "mysql"}, config.Tools.Database.Dialect) {
+			return fmt.Errorf("invalid tools.database.dialect: %s (must be sqlite, postgres or mysql)", config.Tools.Database.Dialect)
+		}
+		if config.Tools.Database.MaxRows <= 0 || config.Tools.Database.Timeout <= 0 {
+			return fmt.Errorf("tools.database max_rows and timeout must be positive")
+		}
+		if len(config.Tools.Database.AllowedStatements) == 0 {
+			return fmt.Errorf("tools.database.allowed_statements cannot be empty")
+		}
+	}
+	if kv := config.Tools.KVStore; kv.MaxEntries < 0 || kv.MaxValueBytes < 0 || kv.MaxTotalEntries < 0 || kv.MaxTotalBytes < 0 {
+		return fmt.Errorf("tools.kv_store limits cannot be negative")
+	}
+	if len(config.Tools.Cache.Tools) > 0 {
+		if config.Tools.Cache.TTL <= 0 {
+			return fmt.Errorf("tools.cache.ttl must be positive")
+		}
+		if config.Tools.Cache.MaxEntries < 0 {
+			return fmt.Errorf("tools.cache.max_entries cannot be negative")
+		}
+	}
+	for _, name := range config.Tools.Cache.Global {
+		if !slices.Contains(config.Tools.Cache.Tools, name) {
+			return fmt.Errorf("tools.cache.global lists '%s', which is not in tools.cache.tools", name)
+		}
+	}
+
+	for alias, target := range config.Tools.Aliases {
+		if target == "" || alias == target {
+			return fmt.Errorf("invalid target for tool alias '%s': %q", alias, target)
+		}
+	}
+
+	for tool, timeout := range config.HTTPClient.ToolTimeouts {
+		if timeout <= 0 {
+			return fmt.Errorf("http client timeout for tool '%s' must be positive: %d", tool, timeout)
+		}
+	}
+
+	if config.Tracing.Enabled && config.Tracing.Endpoint == "" {
+		return fmt.Errorf("tracing endpoint is required when tracing is enabled")
+	}
+
+	if config.Tracing.SampleRatio < 0 || config.Tracing.SampleRatio > 1 {
+		return fmt.Errorf("tracing sample ratio must be between 0 and 1: %g", config.Tracing.SampleRatio)
+	}
+
+	switch config.State.Backend {
+	case state.BackendMemory:
+	case state.BackendRedis:
+		if config.State.Redis.Addr == "" {
+			return fmt.Errorf("redis address is required when the redis state backend is enabled")
+		}
+	default:
+		return fmt.Errorf("invalid state backend: %s", config.State.Backend)
+	}
+
+	return nil
+}
+
+// validateRateLimit checks the rate limiter's buckets and client key
+func validateRateLimit(rl RateLimitConfig) error {
+	if !rl.Enabled {
+		return nil
+	}
+
+	if rl.Key != RateLimitByConnection && rl.Key != RateLimitByAPIKey {
+		return fmt.Errorf("invalid rate limit key: %s", rl.Key)
+	}
+	if rl.RequestsPerSecond < 0 {
+		return fmt.Errorf("rate limit requests per second must not be negative: %g", rl.RequestsPerSecond)
+	}
+	if rl.RequestsPerSecond > 0 && rl.Burst <= 0 {
+		return fmt.Errorf("rate limit burst must be positive: %d", rl.Burst)
+	}
+	for tool, limit := range rl.Tools {
+		if limit.RequestsPerSecond <= 0 || limit.Burst <= 0 {
+			return fmt.Errorf("rate limit for tool '%s' needs a positive requests_per_second and burst", tool)
+		}
+	}
 	return nil
 }
 
+// GetAllowedIPPrefixes parses security.allowed_ips into prefixes; a plain
+// address becomes a single-address prefix
+func (c *Config) GetAllowedIPPrefixes() ([]netip.Prefix, error) {
+	return ParseIPPrefixes(c.Security.AllowedIPs)
+}
+
+// ParseIPPrefixes parses addresses and CIDR ranges such as "10.0.0.0/8",
+// "192.168.1.10", and "2001:db8::/32"
+func ParseIPPrefixes(entries []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(entries))
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if strings.Contains(entry, "/") {
+			prefix, err := netip.ParsePrefix(entry)
+			if err != nil {
+				return nil, fmt.Errorf("invalid CIDR %q: %w", entry, err)
+			}
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+
+		addr, err := netip.ParseAddr(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid IP address %q: %w", entry, err)
+		}
+		addr = addr.Unmap()
+		prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return prefixes, nil
+}
+
 // GetAddress returns the server address
 func (c *Config) GetAddress() string {
 	return fmt.Sprintf("%s:%d", c.Server.Host, c.Server.Port)
 }
 
+// GetListenAddress returns the network and address the server listens on
+func (c *Config) GetListenAddress() (string, string) {
+	if c.Server.SocketPath != "" {
+		return "unix", c.Server.SocketPath
+	}
+	return "tcp", c.GetAddress()
+}
+
 // IsToolsEnabled returns whether tools capability is enabled
 func (c *Config) IsToolsEnabled() bool {
 	return c.MCP.Capabilities.Tools.Enabled
@@ -257,4 +1481,124 @@ func (c *Config) IsPromptsEnabled() bool {
 // IsLoggingEnabled returns whether logging capability is enabled
 func (c *Config) IsLoggingEnabled() bool {
 	return c.MCP.Capabilities.Logging
-}
\ No newline at end of file
+}
+
+// IsCompletionsEnabled returns whether argument completion is enabled
+func (c *Config) IsCompletionsEnabled() bool {
+	return c.MCP.Capabilities.Completions
+}
+
+// GetHTTPClientOptions converts the HTTP client configuration into factory options
+func (c *Config) GetHTTPClientOptions() httpclient.Options {
+	opts := httpclient.DefaultOptions()
+	opts.Timeout = time.Duration(c.HTTPClient.Timeout) * time.Second
+	for tool, timeout := range c.HTTPClient.ToolTimeouts {
+		opts.ToolTimeouts[tool] = time.Duration(timeout) * time.Second
+	}
+	if c.HTTPClient.MaxIdleConns > 0 {
+		opts.MaxIdleConns = c.HTTPClient.MaxIdleConns
+	}
+	if c.HTTPClient.MaxIdleConnsPerHost > 0 {
+		opts.MaxIdleConnsPerHost = c.HTTPClient.MaxIdleConnsPerHost
+	}
+	if c.HTTPClient.IdleConnTimeout > 0 {
+		opts.IdleConnTimeout = time.Duration(c.HTTPClient.IdleConnTimeout) * time.Second
+	}
+	if c.HTTPClient.UserAgent != "" {
+		opts.UserAgent = c.HTTPClient.UserAgent
+	}
+	opts.ProxyURL = c.HTTPClient.ProxyURL
+	opts.InsecureSkipVerify = c.HTTPClient.InsecureSkipVerify
+	opts.CAFile = c.HTTPClient.CAFile
+	return opts
+}
+
+// GetStateOptions converts the state configuration into store options
+func (c *Config) GetStateOptions() state.Options {
+	opts := state.DefaultOptions()
+	opts.Backend = c.State.Backend
+	opts.RedisAddr = c.State.Redis.Addr
+	opts.RedisPassword = c.State.Redis.Password
+	opts.RedisDB = c.State.Redis.DB
+	opts.KeyPrefix = c.State.Redis.KeyPrefix
+	if c.State.Redis.DialTimeout > 0 {
+		opts.DialTimeout = time.Duration(c.State.Redis.DialTimeout) * time.Second
+	}
+	return opts
+}
+
+// GetJWTOptions converts the JWT configuration into validator options
+func (c *Config) GetJWTOptions() auth.JWTOptions {
+	return auth.JWTOptions{
+		JWKSURL:         c.Security.JWT.JWKSURL,
+		Issuer:          c.Security.JWT.Issuer,
+		Audience:        c.Security.JWT.Audience,
+		RefreshInterval: time.Duration(c.Security.JWT.RefreshInterval) * time.Second,
+		Leeway:          time.Duration(c.Security.JWT.Leeway) * time.Second,
+	}
+}
+
+// GetFilesystemResourceOptions converts the filesystem resource
+// configuration into provider options
+func (c *Config) GetFilesystemResourceOptions() resources.FilesystemOptions {
+	return resources.FilesystemOptions{
+		Roots:        c.Resources.Filesystem.Roots,
+		Include:      c.Resources.Filesystem.Include,
+		Exclude:      c.Resources.Filesystem.Exclude,
+		MaxFileBytes: c.Resources.Filesystem.MaxFileBytes,
+		MaxFiles:     c.Resources.Filesystem.MaxFiles,
+	}
+}
+
+// GetDatabaseOptions converts the database configuration into database
+// options
+func (c *Config) GetDatabaseOptions() database.Options {
+	return database.Options{
+		Driver:            c.Tools.Database.Driver,
+		DSN:               c.Tools.Database.DSN,
+		Dialect:           c.Tools.Database.Dialect,
+		MaxRows:           c.Tools.Database.MaxRows,
+		Timeout:           time.Duration(c.Tools.Database.Timeout) * time.Second,
+		AllowedStatements: c.Tools.Database.AllowedStatements,
+	}
+}
+
+// GetResourceCacheTTL returns how long the contents of a provider are
+// cached; 0 means they are not
+func (c *Config) GetResourceCacheTTL(provider string) time.Duration {
+	return time.Duration(c.Resources.Cache.TTL[provider]) * time.Second
+}
+
+// GetTracingOptions converts the tracing configuration into telemetry options
+func (c *Config) GetTracingOptions() telemetry.Options {
+	opts := telemetry.DefaultOptions()
+	opts.Enabled = c.Tracing.Enabled
+	opts.Endpoint = c.Tracing.Endpoint
+	opts.URLPath = c.Tracing.URLPath
+	opts.Insecure = c.Tracing.Insecure
+	opts.Headers = c.Tracing.Headers
+	opts.ServiceName = c.MCP.Name
+	if c.Tracing.ServiceName != "" {
+		opts.ServiceName = c.Tracing.ServiceName
+	}
+	opts.ServiceVersion = c.MCP.Version
+	opts.SampleRatio = c.Tracing.SampleRatio
+	return opts
+}
+
+// GetSearchEngines converts the search engine settings into web search
+// tool engine configurations
+func (c *Config) GetSearchEngines() map[string]examples.SearchEngineConfig {
+	engines := make(map[string]examples.SearchEngineConfig, len(c.Tools.WebSearch.Engines))
+	for name, engine := range c.Tools.WebSearch.Engines {
+		engines[name] = examples.SearchEngineConfig{
+			BaseURL:    engine.BaseURL,
+			Enabled:    engine.Enabled,
+			RateLimit:  time.Duration(engine.RateLimit * float64(time.Second)),
+			MaxRetries: engine.MaxRetries,
+			APIKey:     engine.APIKey,
+			Timeout:    time.Duration(engine.Timeout) * time.Second,
+		}
+	}
+	return engines
+}
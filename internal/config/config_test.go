@@ -0,0 +1,179 @@
+package config
+
+import (
+	"net/netip"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseIPPrefixes(t *testing.T) {
+	prefixes, err := ParseIPPrefixes([]string{"10.0.0.0/8", "192.168.1.10", "2001:db8::/32", "::ffff:172.16.0.1"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		addr    string
+		allowed bool
+	}{
+		{"10.1.2.3", true},
+		{"11.0.0.1", false},
+		{"192.168.1.10", true},
+		{"192.168.1.11", false},
+		{"2001:db8::1", true},
+		{"2001:db9::1", false},
+		{"172.16.0.1", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			addr := netip.MustParseAddr(tt.addr)
+			allowed := false
+			for _, prefix := range prefixes {
+				if prefix.Contains(addr) {
+					allowed = true
+				}
+			}
+			if allowed != tt.allowed {
+				t.Errorf("Contains(%s) = %v, want %v", tt.addr, allowed, tt.allowed)
+			}
+		})
+	}
+
+	if _, err := ParseIPPrefixes([]string{"10.0.0.0/33"}); err == nil {
+		t.Error("Expected error for invalid CIDR")
+	}
+	if _, err := ParseIPPrefixes([]string{"not-an-ip"}); err == nil {
+		t.Error("Expected error for invalid address")
+	}
+}
+
+func TestConfig_GetSearchEngines(t *testing.T) {
+	cfg := DefaultConfig()
+	if err := validate(cfg); err != nil {
+		t.Fatalf("Expected the defaults to be valid: %v", err)
+	}
+
+	brave := cfg.Tools.WebSearch.Engines["brave"]
+	brave.Enabled = true
+	cfg.Tools.WebSearch.Engines["brave"] = brave
+	if err := validate(cfg); err == nil {
+		t.Error("Expected brave without an api_key to be rejected")
+	}
+
+	brave.APIKey = "brave-key"
+	brave.RateLimit = 0.5
+	brave.Timeout = 5
+	cfg.Tools.WebSearch.Engines["brave"] = brave
+	if err := validate(cfg); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	engine := cfg.GetSearchEngines()["brave"]
+	if engine.APIKey != "brave-key" || engine.RateLimit != 500*time.Millisecond || engine.Timeout != 5*time.Second {
+		t.Errorf("Unexpected engine config: %+v", engine)
+	}
+
+	cfg.Tools.WebSearch.Engines["bing"] = SearchEngineSettings{}
+	if err := validate(cfg); err == nil {
+		t.Error("Expected an unknown engine to be rejected")
+	}
+}
+
+func TestValidate_LogSinks(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Logging.Syslog.Enabled = true
+	cfg.Logging.Journald.Enabled = true
+	if err := validate(cfg); err != nil {
+		t.Fatalf("Expected the local syslog daemon and journald to be valid: %v", err)
+	}
+
+	cfg.Logging.Syslog.Network = "udp"
+	cfg.Logging.Syslog.Address = "logs.internal:514"
+	cfg.Logging.Syslog.Facility = "local3"
+	if err := validate(cfg); err != nil {
+		t.Fatalf("Expected a remote syslog server to be valid: %v", err)
+	}
+
+	for name, syslog := range map[string]SyslogConfig{
+		"unknown facility":        {Enabled: true, Facility: "local9"},
+		"unknown network":         {Enabled: true, Facility: "daemon", Network: "http", Address: "logs.internal:514"},
+		"network without address": {Enabled: true, Facility: "daemon", Network: "tcp"},
+		"address without network": {Enabled: true, Facility: "daemon", Address: "logs.internal:514"},
+	} {
+		cfg.Logging.Syslog = syslog
+		if err := validate(cfg); err == nil {
+			t.Errorf("Expected a syslog config with an %s to be rejected", name)
+		}
+	}
+}
+
+func TestValidate_CORSCredentials(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Server.CORS.AllowCredentials = true
+	cfg.Server.CORS.AllowedOrigins = []string{"*"}
+	if err := validate(cfg); err == nil {
+		t.Error("Expected credentials with a wildcard origin to be rejected")
+	}
+
+	cfg.Server.CORS.AllowedOrigins = []string{"https://app.example.com", "https://*.example.com"}
+	if err := validate(cfg); err != nil {
+		t.Errorf("Expected credentials with explicit origins to be valid: %v", err)
+	}
+}
+
+func TestValidate_LogSampling(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Logging.Sampling.Enabled = true
+	if err := validate(cfg); err != nil {
+		t.Fatalf("Expected the default sampling to be valid: %v", err)
+	}
+
+	cfg.Logging.Sampling.Burst = 0
+	if err := validate(cfg); err == nil {
+		t.Error("Expected a sampling burst of 0 to be rejected")
+	}
+	cfg.Logging.Sampling.Burst = 5
+	cfg.Logging.Sampling.Interval = -1
+	if err := validate(cfg); err == nil {
+		t.Error("Expected a negative sampling interval to be rejected")
+	}
+}
+
+func TestLoadFormat(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"config.json":  `{"server": {"port": 9001}, "tools": {"enabled": ["calculator"]}}`,
+		"config.toml":  "[server]\nport = 9002\n\n[tools]\nenabled = [\"calculator\"]\n",
+		"settings.cfg": `{"server": {"port": 9003}}`,
+	}
+	for name, contents := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		file   string
+		format string
+		port   int
+	}{
+		{"config.json", "", 9001},
+		{"config.toml", "", 9002},
+		{"settings.cfg", "json", 9003},
+	}
+	for _, tt := range tests {
+		cfg, err := LoadFormat(filepath.Join(dir, tt.file), tt.format)
+		if err != nil {
+			t.Fatalf("Load %s failed: %v", tt.file, err)
+		}
+		if cfg.Server.Port != tt.port {
+			t.Errorf("Expected port %d from %s, got %d", tt.port, tt.file, cfg.Server.Port)
+		}
+	}
+
+	if _, err := LoadFormat(filepath.Join(dir, "config.json"), "ini"); err == nil {
+		t.Error("Expected an unsupported format to be rejected")
+	}
+}
@@ -0,0 +1,27 @@
+package config
+
+import (
+	"sync"
+
+	"github.com/spf13/viper"
+)
+
+// Loader loads configuration with a viper instance of its own, so that
+// programs embedding the server and parallel tests can load several
+// configurations without sharing state. Its methods are safe for
+// concurrent use.
+type Loader struct {
+	// mu serializes loads, as viper is not safe for concurrent use
+	mu      sync.Mutex
+	v       *viper.Viper
+	profile string
+}
+
+// defaultLoader backs the package's functions with viper's global
+// instance, as they have always used it
+var defaultLoader = &Loader{v: viper.GetViper()}
+
+// NewLoader returns a loader with a new viper instance
+func NewLoader() *Loader {
+	return &Loader{v: viper.New()}
+}
@@ -0,0 +1,60 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestLoader_Independent(t *testing.T) {
+	dir := t.TempDir()
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for i := 0; i < 8; i++ {
+		port := 9100 + i
+		path := filepath.Join(dir, fmt.Sprintf("config-%d.yaml", i))
+		if err := os.WriteFile(path, []byte(fmt.Sprintf("server:\n  port: %d\n", port)), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			loader := NewLoader()
+			if err := loader.Override("server.cors.max_age", fmt.Sprint(port)); err != nil {
+				errs <- err
+				return
+			}
+			for j := 0; j < 3; j++ {
+				cfg, err := loader.Load(path)
+				if err == nil {
+					cfg, err = loader.Reload()
+				}
+				if err != nil {
+					errs <- err
+					return
+				}
+				if cfg.Server.Port != port || cfg.Server.CORS.MaxAge != port || loader.FileUsed() != path {
+					errs <- fmt.Errorf("loader for %s got port %d and max_age %d", path, cfg.Server.Port, cfg.Server.CORS.MaxAge)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+
+	// Overrides of a loader leave the package's Load alone
+	cfg, err := Load(filepath.Join(dir, "config-0.yaml"))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Server.CORS.MaxAge != DefaultConfig().Server.CORS.MaxAge {
+		t.Errorf("Expected the default max_age, got %d", cfg.Server.CORS.MaxAge)
+	}
+}
@@ -0,0 +1,81 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Override sets key, a setting's config file path such as "server.port" or
+// "tools.rate_limits.web_search.burst", to value for every later Load and
+// Reload. It takes precedence over the config file and environment
+// variables. Values are converted to the setting's type, and lists are
+// comma separated.
+func Override(key, value string) error {
+	return defaultLoader.Override(key, value)
+}
+
+// Override sets a setting for the loader's later loads, like the package's
+// Override
+func (l *Loader) Override(key, value string) error {
+	key = strings.ToLower(strings.TrimSpace(key))
+	if err := checkSetting(key); err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.v.Set(key, value)
+	return nil
+}
+
+// checkSetting returns an error unless key names a single setting of
+// Config; map entries may have any name
+func checkSetting(key string) error {
+	if key == "" {
+		return fmt.Errorf("empty setting name")
+	}
+
+	t := reflect.TypeOf(Config{})
+	for _, segment := range strings.Split(key, ".") {
+		switch t.Kind() {
+		case reflect.Struct:
+			field, found := settingField(t, segment)
+			if !found {
+				return fmt.Errorf("unknown setting: %s", key)
+			}
+			t = field.Type
+		case reflect.Map:
+			t = t.Elem()
+		case reflect.Interface:
+			return nil
+		default:
+			return fmt.Errorf("unknown setting: %s", key)
+		}
+	}
+
+	if t.Kind() == reflect.Struct || t.Kind() == reflect.Map {
+		return fmt.Errorf("%s is a section, set one of its settings instead", key)
+	}
+	return nil
+}
+
+// settingField returns the exported field of t named name in config files
+func settingField(t reflect.Type, name string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.IsExported() && settingName(field) == name {
+			return field, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// settingName returns the name of field in config files: its mapstructure
+// tag, or its lowercased name without one
+func settingName(field reflect.StructField) string {
+	if name := strings.Split(field.Tag.Get("mapstructure"), ",")[0]; name != "" {
+		return name
+	}
+	return strings.ToLower(field.Name)
+}
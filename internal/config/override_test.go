@@ -0,0 +1,43 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOverride(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("server:\n  cors:\n    max_age: 60\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	loader := NewLoader()
+	if err := loader.Override("server.cors.max_age", "120"); err != nil {
+		t.Fatalf("Override failed: %v", err)
+	}
+	if err := loader.Override("Server.CORS.Allowed_Origins", "https://a.example,https://b.example"); err != nil {
+		t.Fatalf("Override failed: %v", err)
+	}
+	cfg, err := loader.Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Server.CORS.MaxAge != 120 {
+		t.Errorf("Expected the override to win over the file, got %d", cfg.Server.CORS.MaxAge)
+	}
+	if len(cfg.Server.CORS.AllowedOrigins) != 2 || cfg.Server.CORS.AllowedOrigins[1] != "https://b.example" {
+		t.Errorf("Expected a comma separated list, got %v", cfg.Server.CORS.AllowedOrigins)
+	}
+
+	for _, key := range []string{"tools.rate_limits.web_search.burst", "tools.aliases.search", "tools.proxies"} {
+		if err := checkSetting(key); err != nil {
+			t.Errorf("Expected %s to be a setting: %v", key, err)
+		}
+	}
+	for _, key := range []string{"", "server.prot", "server", "server.port.value", "tools.rate_limits.web_search"} {
+		if err := checkSetting(key); err == nil {
+			t.Errorf("Expected %q to be rejected", key)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"reflect"
+	"strings"
+
+	"github.com/mitchellh/mapstructure"
+	"gopkg.in/yaml.v3"
+)
+
+// maskedValue replaces secrets in printed configuration
+const maskedValue = "********"
+
+// Masked returns a copy of the configuration with API keys, passwords,
+// DSNs, headers, environment values of external tools and every value
+// resolved from a secret reference masked, for printing or logging. Empty
+// values stay empty so it is clear they are unset.
+func (c *Config) Masked() *Config {
+	masked := *c
+	walkStrings(reflect.ValueOf(&masked).Elem(), "", func(path, value string) (string, error) {
+		for _, secret := range c.secrets {
+			value = strings.ReplaceAll(value, secret, maskedValue)
+		}
+		return value, nil
+	})
+
+	masked.Security.APIKeys = make([]string, len(c.Security.APIKeys))
+	for i, key := range c.Security.APIKeys {
+		masked.Security.APIKeys[i] = mask(key)
+	}
+	masked.State.Redis.Password = mask(c.State.Redis.Password)
+	masked.Tools.Database.DSN = mask(c.Tools.Database.DSN)
+	masked.Tracing.Headers = maskValues(c.Tracing.Headers)
+	if proxyURL, err := url.Parse(masked.HTTPClient.ProxyURL); err == nil {
+		masked.HTTPClient.ProxyURL = proxyURL.Redacted()
+	} else {
+		masked.HTTPClient.ProxyURL = mask(masked.HTTPClient.ProxyURL)
+	}
+
+	// The walk above gave masked its own copies of these slices and maps
+	for name, engine := range masked.Tools.WebSearch.Engines {
+		engine.APIKey = mask(engine.APIKey)
+		masked.Tools.WebSearch.Engines[name] = engine
+	}
+	for i := range masked.Tools.Proxies {
+		masked.Tools.Proxies[i].Headers = maskValues(masked.Tools.Proxies[i].Headers)
+	}
+	for i := range masked.Tools.External {
+		masked.Tools.External[i].Env = maskValues(masked.Tools.External[i].Env)
+	}
+
+	return &masked
+}
+
+// Secrets returns the secret values of the configuration, the ones Masked
+// hides, so that logs can leave them out too
+func (c *Config) Secrets() []string {
+	secrets := append([]string{}, c.secrets...)
+	secrets = append(secrets, c.Security.APIKeys...)
+	secrets = append(secrets, c.State.Redis.Password, c.Tools.Database.DSN)
+	if proxyURL, err := url.Parse(c.HTTPClient.ProxyURL); err == nil {
+		if password, set := proxyURL.User.Password(); set {
+			secrets = append(secrets, password)
+		}
+	}
+	for _, value := range c.Tracing.Headers {
+		secrets = append(secrets, value)
+	}
+	for _, engine := range c.Tools.WebSearch.Engines {
+		secrets = append(secrets, engine.APIKey)
+	}
+	for _, proxy := range c.Tools.Proxies {
+		for _, value := range proxy.Headers {
+			secrets = append(secrets, value)
+		}
+	}
+	for _, external := range c.Tools.External {
+		for _, value := range external.Env {
+			secrets = append(secrets, value)
+		}
+	}
+
+	nonEmpty := secrets[:0]
+	for _, secret := range secrets {
+		if secret != "" {
+			nonEmpty = append(nonEmpty, secret)
+		}
+	}
+	return nonEmpty
+}
+
+// WriteYAML writes the configuration as YAML with the keys used in config
+// files
+func (c *Config) WriteYAML(w io.Writer) error {
+	settings, err := settingsOf(reflect.ValueOf(*c))
+	if err != nil {
+		return fmt.Errorf("error encoding config: %w", err)
+	}
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(settings); err != nil {
+		return fmt.Errorf("error encoding config: %w", err)
+	}
+	return encoder.Close()
+}
+
+// settingsOf converts structs below v, including those in slices and maps,
+// to maps keyed by their mapstructure tags
+func settingsOf(v reflect.Value) (interface{}, error) {
+	switch v.Kind() {
+	case reflect.Struct:
+		fields := make(map[string]interface{})
+		if err := mapstructure.Decode(v.Interface(), &fields); err != nil {
+			return nil, err
+		}
+		for key, field := range fields {
+			value, err := settingsOf(reflect.ValueOf(field))
+			if err != nil {
+				return nil, err
+			}
+			fields[key] = value
+		}
+		return fields, nil
+	case reflect.Slice:
+		if v.IsNil() {
+			return v.Interface(), nil
+		}
+		items := make([]interface{}, v.Len())
+		for i := range items {
+			item, err := settingsOf(v.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			items[i] = item
+		}
+		return items, nil
+	case reflect.Map:
+		if v.IsNil() {
+			return v.Interface(), nil
+		}
+		entries := make(map[string]interface{}, v.Len())
+		for _, key := range v.MapKeys() {
+			entry, err := settingsOf(v.MapIndex(key))
+			if err != nil {
+				return nil, err
+			}
+			entries[fmt.Sprint(key.Interface())] = entry
+		}
+		return entries, nil
+	case reflect.Interface, reflect.Pointer:
+		if v.IsNil() {
+			return nil, nil
+		}
+		return settingsOf(v.Elem())
+	case reflect.Invalid:
+		return nil, nil
+	}
+	return v.Interface(), nil
+}
+
+// mask hides a non-empty secret
+func mask(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	return maskedValue
+}
+
+// maskValues returns a copy of values with every value masked
+func maskValues(values map[string]string) map[string]string {
+	masked := make(map[string]string, len(values))
+	for key, value := range values {
+		masked[key] = mask(value)
+	}
+	return masked
+}
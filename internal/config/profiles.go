@@ -0,0 +1,95 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// ProfileEnv names the environment variable that selects the active
+// profile when SetProfile has not
+const ProfileEnv = "MCP_PROFILE"
+
+// SetProfile makes Load and Reload merge the settings under
+// profiles.<name> in the config file over the rest of it, e.g. to keep
+// development and production settings in one file. An empty name leaves
+// the choice to MCP_PROFILE.
+func SetProfile(name string) {
+	defaultLoader.SetProfile(name)
+}
+
+// SetProfile selects the profile of the loader's later loads, like the
+// package's SetProfile
+func (l *Loader) SetProfile(name string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.profile = strings.ToLower(strings.TrimSpace(name))
+}
+
+// Profile returns the name of the profile merged into the configuration,
+// or "" when none is active
+func (c *Config) Profile() string {
+	return c.profile
+}
+
+// profileName returns the profile set with SetProfile or MCP_PROFILE;
+// l.mu must be held
+func (l *Loader) profileName() string {
+	if l.profile != "" {
+		return l.profile
+	}
+	return strings.ToLower(strings.TrimSpace(os.Getenv(ProfileEnv)))
+}
+
+// mergeProfile merges the active profile's settings over the config file
+// read by the loader and returns its name. Environment variables and
+// overrides still take precedence.
+func (l *Loader) mergeProfile() (string, error) {
+	name := l.profileName()
+	if name == "" {
+		return "", nil
+	}
+
+	profiles := l.v.GetStringMap("profiles")
+	settings, ok := profiles[name].(map[string]interface{})
+	if !ok {
+		defined := make([]string, 0, len(profiles))
+		for profile := range profiles {
+			defined = append(defined, profile)
+		}
+		sort.Strings(defined)
+		if len(defined) == 0 {
+			return "", fmt.Errorf("unknown profile %s: the config file defines no profiles", name)
+		}
+		return "", fmt.Errorf("unknown profile %s (defined: %s)", name, strings.Join(defined, ", "))
+	}
+	if err := checkProfileSettings(settings, ""); err != nil {
+		return "", fmt.Errorf("profile %s: %w", name, err)
+	}
+
+	if err := l.v.MergeConfigMap(settings); err != nil {
+		return "", fmt.Errorf("error merging profile %s: %w", name, err)
+	}
+	return name, nil
+}
+
+// checkProfileSettings returns an error for the first setting in settings,
+// nested below prefix, that Config does not have
+func checkProfileSettings(settings map[string]interface{}, prefix string) error {
+	for key, value := range settings {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		if nested, ok := value.(map[string]interface{}); ok {
+			if err := checkProfileSettings(nested, key); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := checkSetting(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
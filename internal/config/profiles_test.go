@@ -0,0 +1,67 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoad_Profiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(`server:
+  port: 8040
+logging:
+  level: info
+  format: text
+profiles:
+  dev:
+    logging:
+      level: debug
+  prod:
+    server:
+      port: 9040
+    logging:
+      level: warn
+  broken:
+    server:
+      prot: 1
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { SetProfile("") })
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Profile() != "" || cfg.Server.Port != 8040 {
+		t.Errorf("Expected the base config without a profile, got %q and port %d", cfg.Profile(), cfg.Server.Port)
+	}
+
+	SetProfile("prod")
+	cfg, err = Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Profile() != "prod" || cfg.Server.Port != 9040 || cfg.Logging.Level != "warn" || cfg.Logging.Format != "text" {
+		t.Errorf("Expected prod merged over the base config, got %q, %d and %+v", cfg.Profile(), cfg.Server.Port, cfg.Logging)
+	}
+
+	// MCP_PROFILE applies when no profile is set
+	SetProfile("")
+	t.Setenv(ProfileEnv, "dev")
+	cfg, err = Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Logging.Level != "debug" || cfg.Server.Port != 8040 {
+		t.Errorf("Expected the dev profile, got %s and port %d", cfg.Logging.Level, cfg.Server.Port)
+	}
+
+	for _, profile := range []string{"staging", "broken"} {
+		SetProfile(profile)
+		if _, err := Load(path); err == nil {
+			t.Errorf("Expected profile %s to fail", profile)
+		}
+	}
+}
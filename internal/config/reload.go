@@ -0,0 +1,84 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// reloadDebounce coalesces the events of one save into one reload
+const reloadDebounce = 250 * time.Millisecond
+
+// Reload reads the config file used by Load again, with the same
+// environment variables and defaults, and returns the new configuration if
+// it is valid. On error the caller keeps running with its current one.
+func Reload() (*Config, error) {
+	return defaultLoader.Reload()
+}
+
+// Reload reads the config file used by the loader's Load again, like the
+// package's Reload
+func (l *Loader) Reload() (*Config, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.read(DefaultConfig())
+}
+
+// Watch reloads the config file whenever it changes, until ctx is done,
+// and passes each result to onChange. The file's directory is watched so
+// that editors replacing the file are noticed.
+func Watch(ctx context.Context, onChange func(*Config, error)) error {
+	return defaultLoader.Watch(ctx, onChange)
+}
+
+// Watch reloads the config file used by the loader's Load whenever it
+// changes, like the package's Watch
+func (l *Loader) Watch(ctx context.Context, onChange func(*Config, error)) error {
+	path := l.FileUsed()
+	if path == "" {
+		return fmt.Errorf("no config file to watch")
+	}
+	path = filepath.Clean(path)
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create watcher: %w", err)
+	}
+	if err := watcher.Add(filepath.Dir(path)); err != nil {
+		watcher.Close()
+		return fmt.Errorf("failed to watch config file: %w", err)
+	}
+
+	go func() {
+		defer watcher.Close()
+
+		var reload <-chan time.Time
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if filepath.Clean(event.Name) == path && event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) != 0 {
+					reload = time.After(reloadDebounce)
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				onChange(nil, fmt.Errorf("config file watcher error: %w", err))
+			case <-reload:
+				reload = nil
+				onChange(l.Reload())
+			}
+		}
+	}()
+
+	return nil
+}
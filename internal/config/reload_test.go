@@ -0,0 +1,61 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	write := func(contents string) {
+		if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("logging:\n  level: info\n")
+	if _, err := Load(path); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	write("logging:\n  level: debug\nsecurity:\n  allowed_ips: [\"10.0.0.0/8\"]\n")
+	cfg, err := Reload()
+	if err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	if cfg.Logging.Level != "debug" || len(cfg.Security.AllowedIPs) != 1 {
+		t.Errorf("Expected the changed settings, got %+v, %v", cfg.Logging, cfg.Security.AllowedIPs)
+	}
+
+	for _, invalid := range []string{"logging:\n  level: loud\n", "security:\n  allowed_ips: [\"10.0.0.0/33\"]\n", "logging: [\n"} {
+		write(invalid)
+		if _, err := Reload(); err == nil {
+			t.Errorf("Expected %q to be rejected", invalid)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes := make(chan *Config, 1)
+	err = Watch(ctx, func(cfg *Config, err error) {
+		if err == nil {
+			changes <- cfg
+		}
+	})
+	if err != nil {
+		t.Fatalf("Watch failed: %v", err)
+	}
+
+	write("logging:\n  level: warn\n")
+	select {
+	case cfg := <-changes:
+		if cfg.Logging.Level != "warn" {
+			t.Errorf("Expected the watched change, got level %s", cfg.Logging.Level)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the config file change")
+	}
+}
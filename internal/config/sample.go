@@ -0,0 +1,285 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// sampleHeader starts the file WriteSample writes
+const sampleHeader = `# MCP Go Template Configuration
+# Every setting with its default value; delete the ones you keep as is
+`
+
+// settingComments describe the settings in the sample config, by config
+// file path; * stands for any key of a map
+var settingComments = map[string]string{
+	"http_client":                               "Outbound HTTP requests made by tools",
+	"http_client.ca_file":                       "Extra PEM CA certificates to trust",
+	"http_client.idle_conn_timeout":             "Seconds",
+	"http_client.insecure_skip_verify":          "Skip TLS certificate verification; for testing only",
+	"http_client.max_idle_conns":                "Idle connections kept across all hosts",
+	"http_client.max_idle_conns_per_host":       "Idle connections kept per host",
+	"http_client.proxy_url":                     "Empty uses HTTP_PROXY/HTTPS_PROXY from the environment",
+	"http_client.timeout":                       "Default outbound request timeout in seconds",
+	"http_client.tool_timeouts":                 "Per-tool overrides, e.g. { web_search: 15 }",
+	"http_client.user_agent":                    "User-Agent header of outbound requests",
+	"logging":                                   "Server, access and audit logs",
+	"logging.access_log.enabled":                "One line per MCP request",
+	"logging.access_log.fields":                 "Fields logged per request",
+	"logging.access_log.sample_rate":            "Fraction of successful requests logged; failures are always logged",
+	"logging.audit.directory":                   "One audit-YYYY-MM-DD.jsonl file per day",
+	"logging.audit.enabled":                     "One record per tool call: time, client, tool, arguments, duration, outcome",
+	"logging.audit.redact":                      "Argument names recorded as [REDACTED]",
+	"logging.audit.retention_days":              "Days of records kept; 0 keeps them all",
+	"logging.format":                            "json, text",
+	"logging.journald":                          "Copy of the logs sent to the systemd journal, with log fields as journal fields",
+	"logging.journald.enabled":                  "Send logs to the journal",
+	"logging.journald.identifier":               "SYSLOG_IDENTIFIER of entries; empty uses the executable's name",
+	"logging.level":                             "debug, info, warn, error",
+	"logging.sampling":                          "Limit on entries with the same level and message, e.g. those of retried requests",
+	"logging.sampling.burst":                    "Entries logged per message per interval; the next one logged reports how many were suppressed",
+	"logging.sampling.enabled":                  "Leave repeated messages out of the logs",
+	"logging.sampling.interval":                 "Seconds",
+	"logging.syslog":                            "Copy of the logs sent to syslog",
+	"logging.syslog.address":                    "Remote syslog server, e.g. logs.internal:514",
+	"logging.syslog.enabled":                    "Send logs to syslog",
+	"logging.syslog.facility":                   "daemon, user, local0-local7, ...",
+	"logging.syslog.network":                    "udp, tcp, unix or unixgram; empty uses the local daemon",
+	"logging.syslog.tag":                        "Program name in messages; empty uses the executable's name",
+	"mcp":                                       "Server identity and MCP capabilities",
+	"mcp.capabilities.completions":              "Suggest tool and prompt argument values via completion/complete",
+	"mcp.capabilities.logging":                  "Let clients set the log level and receive log messages",
+	"mcp.capabilities.prompts.enabled":          "Serve prompts/list and prompts/get",
+	"mcp.capabilities.prompts.list_changed":     "Notify clients when the prompt list changes",
+	"mcp.capabilities.resources.enabled":        "Serve resources/list and resources/read",
+	"mcp.capabilities.resources.list_changed":   "Notify clients when the resource list changes",
+	"mcp.capabilities.resources.subscribe":      "Let clients subscribe to resource updates",
+	"mcp.capabilities.tools.enabled":            "Serve tools/list and tools/call",
+	"mcp.capabilities.tools.list_changed":       "Notify clients when the tool list changes",
+	"mcp.description":                           "Shown to clients that display server details",
+	"mcp.instructions":                          "Guidance for the model on using this server",
+	"mcp.max_blob_bytes":                        "Max decoded binary data per tool result or resource read. 0 is unlimited",
+	"mcp.metadata":                              "Extra key/value pairs describing the server",
+	"mcp.name":                                  "Server name reported in initialize",
+	"mcp.page_size":                             "Max items per list page; clients follow nextCursor. 0 disables paging",
+	"mcp.version":                               "Server version reported in initialize",
+	"prompts":                                   "Prompt versions and definitions",
+	"prompts.default_versions":                  "Version served when a client does not request one",
+	"prompts.dir":                               "Directory of prompt definitions (*.json, *.yaml); empty disables",
+	"prompts.variant_weights":                   "Split requests between versions, e.g. research_prompt: {v1: 50, v2: 50}",
+	"prompts.watch":                             "Reload prompts when their files change",
+	"resources":                                 "Resources served to clients",
+	"resources.cache.max_entries":               "URIs kept; least recently read are dropped first",
+	"resources.cache.ttl":                       "Seconds contents are reused by provider (filesystem, http, database), e.g. {http: 300}",
+	"resources.filesystem.enabled":              "Serve files under roots as file:// resources",
+	"resources.filesystem.exclude":              "Globs to hide; \".*\" hides dotfiles and dot directories",
+	"resources.filesystem.include":              "Globs below a root, e.g. [\"**/*.md\"]; empty serves all files",
+	"resources.filesystem.max_file_bytes":       "Larger files are not served",
+	"resources.filesystem.max_files":            "Most files listed by resources/list",
+	"resources.filesystem.roots":                "Directories to serve, e.g. [\"./docs\"]",
+	"resources.filesystem.watch":                "Notify subscribers when served files change (needs mcp.capabilities.resources.subscribe)",
+	"resources.http":                            "URLs served as resources, e.g. [{uri: \"docs://changelog\", name: Changelog, url: \"https://example.com/CHANGELOG.md\"}]",
+	"security":                                  "TLS, client authentication and access rules",
+	"security.access":                           "Per-client tools and resources, e.g. [{client: \"sub:reporting\", tools: [\"web_search\"], resources: [\"file:///docs/*\"]}]",
+	"security.allowed_ips":                      "Addresses or CIDR ranges, e.g. [\"10.0.0.0/8\", \"::1\"]; empty allows all",
+	"security.allowed_origins":                  "Browser origins allowed on /mcp, e.g. \"https://*.example.com\"; empty allows all",
+	"security.api_keys":                         "Require one of these API keys; also MCP_SECURITY_API_KEYS=\"key1,key2\" or \"secret:///run/secrets/key\"",
+	"security.api_keys_file":                    "File with additional API keys, one per line",
+	"security.cert_file":                        "PEM certificate chain",
+	"security.enable_tls":                       "Serve HTTPS and WSS with cert_file and key_file",
+	"security.jwt.audience":                     "Accepted token audiences (aud); empty skips the check",
+	"security.jwt.issuer":                       "Required token issuer (iss); empty skips the check",
+	"security.jwt.jwks_url":                     "Validate \"Authorization: Bearer\" tokens against this JWKS; empty disables",
+	"security.jwt.leeway":                       "Seconds of clock skew tolerated for exp/nbf",
+	"security.jwt.refresh_interval":             "Seconds to cache signing keys",
+	"security.key_file":                         "PEM private key",
+	"server":                                    "HTTP, WebSocket and Streamable HTTP listener",
+	"server.cors.allow_credentials":             "Allow cookies and HTTP authentication; needs explicit origins, not \"*\"",
+	"server.cors.allowed_headers":               "Request headers browsers may send",
+	"server.cors.allowed_methods":               "Methods browsers may use",
+	"server.cors.allowed_origins":               "Wildcards like \"https://*.example.com\" are supported",
+	"server.cors.enabled":                       "Answer cross-origin browser requests",
+	"server.cors.exposed_headers":               "Response headers browser scripts may read",
+	"server.cors.max_age":                       "Seconds browsers may cache preflight responses",
+	"server.enable_pprof":                       "Serve CPU and memory profiles under /debug/pprof/ (requires API key if configured)",
+	"server.host":                               "Address to listen on; 0.0.0.0 listens on all interfaces",
+	"server.max_connections":                    "Maximum concurrent WebSocket clients; 0 means unlimited",
+	"server.max_inflight_requests":              "Maximum concurrent requests per connection; 0 means unlimited",
+	"server.max_message_bytes":                  "Maximum size of a single message in either direction; 0 means unlimited",
+	"server.port":                               "Port to listen on",
+	"server.rate_limit.burst":                   "Requests a client may send at once",
+	"server.rate_limit.enabled":                 "Limit the requests each client may send",
+	"server.rate_limit.key":                     "\"connection\" per connection/session, \"api_key\" per API key or token subject",
+	"server.rate_limit.requests_per_second":     "Sustained requests per client; 0 limits only the tools below",
+	"server.rate_limit.tools":                   "Per-tool limits, e.g. web_search: {requests_per_second: 0.5, burst: 2}",
+	"server.session_timeout":                    "Idle Streamable HTTP sessions expire after this many seconds",
+	"server.shutdown_timeout":                   "Seconds to wait for in-flight tool calls on shutdown",
+	"server.socket_path":                        "Listen on this Unix domain socket instead of host:port",
+	"server.timeout":                            "Seconds allowed to read a request and write its response",
+	"server.trusted_proxies":                    "Proxies (IPs or CIDRs) whose X-Forwarded-For is trusted, e.g. [\"10.0.0.0/8\"]",
+	"server.watch_config":                       "Reload log level, rate limits and allowed IPs when this file changes; SIGHUP always reloads",
+	"server.websocket.idle_timeout":             "Close connections with no MCP messages for this many seconds; 0 disables",
+	"server.websocket.mcp_ping_interval":        "Send an MCP ping to clients idle this many seconds; 0 disables",
+	"server.websocket.ping_interval":            "Seconds between server pings; 0 disables pings",
+	"server.websocket.pong_timeout":             "Close connections that miss pongs for this many seconds",
+	"state":                                     "Session state shared by tools",
+	"state.backend":                             "\"memory\" for a single replica, \"redis\" to share sessions, caches, rate limits and notifications between replicas",
+	"state.redis.addr":                          "host:port of the Redis server",
+	"state.redis.db":                            "Redis database number",
+	"state.redis.dial_timeout":                  "Seconds",
+	"state.redis.key_prefix":                    "Prepended to every key and channel, to share a Redis server",
+	"state.redis.password":                      "e.g. \"${REDIS_PASSWORD}\" or \"vault://secret/data/mcp#redis_password\"",
+	"tools":                                     "Tool sources and per-tool settings",
+	"tools.aliases":                             "Extra names for tools, e.g. search: web_search",
+	"tools.cache.global":                        "Cached tools whose results all clients share; list only tools whose results depend on their arguments alone",
+	"tools.cache.max_entries":                   "Results kept across all tools; least recently used are dropped first",
+	"tools.cache.tools":                         "Tools whose results are reused for identical arguments, e.g. [web_search, document_analyzer]",
+	"tools.cache.ttl":                           "Seconds a cached result is reused",
+	"tools.concurrency":                         "Calls running at once, e.g. document_analyzer: {max_concurrent: 2, queue_timeout: 30}",
+	"tools.database":                            "sql_query tool and db://tables/ schema resources",
+	"tools.database.allowed_statements":         "Keywords a query may start with",
+	"tools.database.dialect":                    "sqlite, postgres or mysql; empty guesses from the driver",
+	"tools.database.driver":                     "database/sql driver linked into the binary, e.g. pgx or sqlite3",
+	"tools.database.dsn":                        "Prefer MCP_TOOLS_DATABASE_DSN to keep credentials out of the file",
+	"tools.database.enabled":                    "Register the sql_query tool",
+	"tools.database.max_rows":                   "Rows returned per query",
+	"tools.database.timeout":                    "Seconds per query",
+	"tools.directory.path":                      "Directory of script tool manifests (*.json, *.yaml); empty disables",
+	"tools.directory.watch":                     "Reload tools when manifests change",
+	"tools.document_analyzer.parallelism":       "Concurrent analysis stages; 0 uses the number of CPUs",
+	"tools.external":                            "Tools running a command, e.g. {name, description, command, args, env, timeout, max_output_bytes, input_schema}",
+	"tools.kv_store":                            "Scratchpad tool whose entries are kv://<key> resources",
+	"tools.kv_store.enabled":                    "Register the kv_store tool",
+	"tools.kv_store.max_entries":                "Keys kept per client; 0 means unlimited",
+	"tools.kv_store.max_total_bytes":            "Size of all values of all clients together; 0 means unlimited",
+	"tools.kv_store.max_total_entries":          "Keys kept for all clients together; 0 means unlimited",
+	"tools.kv_store.max_value_bytes":            "Largest value in bytes",
+	"tools.pinned_versions":                     "Version served for tools registered in several, e.g. convert: 1.2.0; default is the newest",
+	"tools.plugins.path":                        "Directory of Go plugins (*.so) exporting NewTools; empty disables",
+	"tools.proxies":                             "MCP servers whose tools are served here, e.g. {name, url} or {name, command, args}; add namespace to serve them as namespace/tool",
+	"tools.rate_limits":                         "Limits shared by all clients, e.g. 10 calls a minute: web_search: {requests_per_second: 0.167, burst: 10}",
+	"tools.retries":                             "Retries of failed calls, e.g. web_search: {max_attempts: 3, backoff_ms: 500, max_backoff_ms: 5000, retry_error_results: true}",
+	"tools.web_search.engines":                  "duckduckgo, searxng and brave",
+	"tools.web_search.engines.*.api_key":        "Sent to engines that need one",
+	"tools.web_search.engines.*.base_url":       "Search API endpoint",
+	"tools.web_search.engines.*.enabled":        "Used by engine \"auto\" and when requested",
+	"tools.web_search.engines.*.max_retries":    "Retries after a failed request",
+	"tools.web_search.engines.*.rate_limit":     "Minimum seconds between requests",
+	"tools.web_search.engines.*.timeout":        "Seconds per request; 0 uses http_client.timeout",
+	"tools.web_search.engines.brave.api_key":    "e.g. \"${BRAVE_API_KEY}\" or \"secret:///run/secrets/brave_api_key\"",
+	"tools.web_search.engines.brave.enabled":    "Needs an API key from https://brave.com/search/api/",
+	"tools.web_search.engines.searxng.base_url": "A public instance; point this at your own",
+	"tracing":              "OpenTelemetry traces",
+	"tracing.enabled":      "Export a span per request and tool call",
+	"tracing.endpoint":     "OTLP/HTTP collector host:port",
+	"tracing.headers":      "Extra headers, e.g. collector auth",
+	"tracing.insecure":     "Plain HTTP to the collector",
+	"tracing.sample_ratio": "Fraction of traces recorded, from 0 to 1",
+	"tracing.service_name": "Empty uses mcp.name",
+	"tracing.url_path":     "Empty uses /v1/traces",
+}
+
+// WriteSample writes a config file with every setting of DefaultConfig,
+// including each tool's, at its default value and commented
+func WriteSample(w io.Writer) error {
+	config := reflect.ValueOf(*DefaultConfig())
+	if _, err := io.WriteString(w, sampleHeader); err != nil {
+		return err
+	}
+
+	// One document per section, to keep a blank line between sections
+	for i := 0; i < config.NumField(); i++ {
+		field := config.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := settingName(field)
+		value, err := sampleNode(config.Field(i), name, name)
+		if err != nil {
+			return fmt.Errorf("error encoding %s: %w", name, err)
+		}
+		section := &yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{
+			{Kind: yaml.ScalarNode, Value: name, HeadComment: settingComments[name]},
+			value,
+		}}
+
+		if _, err := io.WriteString(w, "\n"); err != nil {
+			return err
+		}
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(section); err != nil {
+			return fmt.Errorf("error encoding %s: %w", name, err)
+		}
+		if err := encoder.Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sampleNode returns the YAML for v, the setting at path, with comments
+// for the settings below it. pattern is path with map keys replaced by *.
+func sampleNode(v reflect.Value, path, pattern string) (*yaml.Node, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	switch {
+	case v.Kind() == reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name := settingName(field)
+			if err := addSampleSetting(node, name, v.Field(i), path+"."+name, pattern+"."+name); err != nil {
+				return nil, err
+			}
+		}
+	case v.Kind() == reflect.Map && v.Len() > 0 && v.Type().Elem().Kind() == reflect.Struct:
+		keys := make([]string, 0, v.Len())
+		for _, key := range v.MapKeys() {
+			keys = append(keys, key.String())
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			value := v.MapIndex(reflect.ValueOf(key).Convert(v.Type().Key()))
+			if err := addSampleSetting(node, key, value, path+"."+key, pattern+".*"); err != nil {
+				return nil, err
+			}
+		}
+	default:
+		if err := node.Encode(v.Interface()); err != nil {
+			return nil, err
+		}
+		// Lists and maps of values fit on one line
+		if node.Kind == yaml.SequenceNode || node.Kind == yaml.MappingNode {
+			node.Style = yaml.FlowStyle
+		}
+	}
+	return node, nil
+}
+
+// addSampleSetting adds the setting name with value v to mapping, with its
+// comment above a section or after a value
+func addSampleSetting(mapping *yaml.Node, name string, v reflect.Value, path, pattern string) error {
+	value, err := sampleNode(v, path, pattern)
+	if err != nil {
+		return err
+	}
+	comment, found := settingComments[path]
+	if !found {
+		comment = settingComments[pattern]
+	}
+
+	key := &yaml.Node{Kind: yaml.ScalarNode, Value: name}
+	if value.Kind == yaml.MappingNode && value.Style != yaml.FlowStyle {
+		key.HeadComment = comment
+	} else {
+		value.LineComment = comment
+	}
+	mapping.Content = append(mapping.Content, key, value)
+	return nil
+}
@@ -0,0 +1,45 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteSample(t *testing.T) {
+	var sample bytes.Buffer
+	if err := WriteSample(&sample); err != nil {
+		t.Fatalf("WriteSample failed: %v", err)
+	}
+	for _, expected := range []string{
+		"# HTTP, WebSocket and Streamable HTTP listener\nserver:\n",
+		"  port: 8030 # Port to listen on\n",
+		"      searxng:\n        enabled: true # Used by engine \"auto\" and when requested\n",
+	} {
+		if !strings.Contains(sample.String(), expected) {
+			t.Errorf("Expected %q in the sample:\n%s", expected, sample.String())
+		}
+	}
+
+	// The sample loads to the defaults
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, sample.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	var loaded, defaults bytes.Buffer
+	if err := cfg.WriteYAML(&loaded); err != nil {
+		t.Fatal(err)
+	}
+	if err := DefaultConfig().WriteYAML(&defaults); err != nil {
+		t.Fatal(err)
+	}
+	if loaded.String() != defaults.String() {
+		t.Errorf("Expected the sample to load to the defaults, got:\n%s", loaded.String())
+	}
+}
@@ -0,0 +1,218 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SecretResolver returns the secret a reference such as
+// "vault://secret/data/mcp#brave_api_key" points to; it receives the part
+// after the scheme
+type SecretResolver func(reference string) (string, error)
+
+var (
+	resolversMu sync.RWMutex
+	resolvers   = map[string]SecretResolver{
+		"secret": resolveFileSecret,
+		"vault":  resolveVaultSecret,
+	}
+)
+
+// envReference matches ${NAME} and ${NAME:-default}
+var envReference = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+
+// vaultTimeout bounds a request to Vault
+const vaultTimeout = 10 * time.Second
+
+// RegisterSecretResolver makes config values starting with scheme:// be
+// replaced by what resolver returns for them, replacing any resolver for
+// the scheme. secret:// (files) and vault:// are built in.
+func RegisterSecretResolver(scheme string, resolver SecretResolver) {
+	resolversMu.Lock()
+	defer resolversMu.Unlock()
+	resolvers[scheme] = resolver
+}
+
+// resolveSecrets expands ${NAME} environment references in every string
+// setting and replaces values with a registered scheme, such as
+// secret://path, by the secret they point to. It returns the secrets
+// resolved from schemes so they can be masked.
+func resolveSecrets(config *Config) ([]string, error) {
+	var secrets []string
+	resolve := func(path, value string) (string, error) {
+		expanded, err := expandEnv(value)
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", path, err)
+		}
+		scheme, reference, found := strings.Cut(expanded, "://")
+		if !found {
+			return expanded, nil
+		}
+		resolversMu.RLock()
+		resolver, exists := resolvers[scheme]
+		resolversMu.RUnlock()
+		if !exists {
+			return expanded, nil
+		}
+
+		secret, err := resolver(reference)
+		if err != nil {
+			return "", fmt.Errorf("%s: failed to resolve %s secret: %w", path, scheme, err)
+		}
+		if secret != "" {
+			secrets = append(secrets, secret)
+		}
+		return secret, nil
+	}
+
+	if err := walkStrings(reflect.ValueOf(config).Elem(), "", resolve); err != nil {
+		return nil, err
+	}
+	return secrets, nil
+}
+
+// expandEnv replaces ${NAME} with the environment variable NAME, or with
+// the default of ${NAME:-default} when it is unset
+func expandEnv(value string) (string, error) {
+	var missing string
+	expanded := envReference.ReplaceAllStringFunc(value, func(reference string) string {
+		match := envReference.FindStringSubmatch(reference)
+		if env, set := os.LookupEnv(match[1]); set {
+			return env
+		}
+		if match[2] != "" {
+			return match[3]
+		}
+		if missing == "" {
+			missing = match[1]
+		}
+		return ""
+	})
+	if missing != "" {
+		return "", fmt.Errorf("environment variable %s is not set", missing)
+	}
+	return expanded, nil
+}
+
+// walkStrings calls fn with every string setting below v, including those
+// in slices and maps, and stores what it returns. Slices and maps are
+// replaced by copies, so values sharing them with v are left unchanged.
+// path names the setting by its config file keys, e.g.
+// "security.api_keys[0]".
+func walkStrings(v reflect.Value, path string, fn func(path, value string) (string, error)) error {
+	switch v.Kind() {
+	case reflect.String:
+		value, err := fn(path, v.String())
+		if err != nil {
+			return err
+		}
+		v.SetString(value)
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name := settingName(field)
+			if path != "" {
+				name = path + "." + name
+			}
+			if err := walkStrings(v.Field(i), name, fn); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice:
+		if v.IsNil() {
+			return nil
+		}
+		copied := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		reflect.Copy(copied, v)
+		for i := 0; i < copied.Len(); i++ {
+			if err := walkStrings(copied.Index(i), fmt.Sprintf("%s[%d]", path, i), fn); err != nil {
+				return err
+			}
+		}
+		v.Set(copied)
+	case reflect.Map:
+		// Maps of arbitrary values such as schemas are left alone
+		if v.IsNil() || v.Type().Key().Kind() != reflect.String || v.Type().Elem().Kind() == reflect.Interface {
+			return nil
+		}
+		copied := reflect.MakeMapWithSize(v.Type(), v.Len())
+		for _, key := range v.MapKeys() {
+			value := reflect.New(v.Type().Elem()).Elem()
+			value.Set(v.MapIndex(key))
+			if err := walkStrings(value, path+"."+key.String(), fn); err != nil {
+				return err
+			}
+			copied.SetMapIndex(key, value)
+		}
+		v.Set(copied)
+	}
+	return nil
+}
+
+// resolveFileSecret reads the secret in a file, e.g. secret:///run/secrets/brave
+// or, relative to the working directory, secret://secrets/brave, without
+// its trailing newline
+func resolveFileSecret(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// resolveVaultSecret reads a field of a HashiCorp Vault secret, e.g.
+// vault://secret/data/mcp#brave_api_key, from the server at VAULT_ADDR with
+// the token in VAULT_TOKEN. Both KV version 1 and 2 paths work.
+func resolveVaultSecret(reference string) (string, error) {
+	path, field, found := strings.Cut(reference, "#")
+	if !found || field == "" {
+		return "", fmt.Errorf("reference must name a field, e.g. vault://secret/data/mcp#api_key")
+	}
+	addr := os.Getenv("VAULT_ADDR")
+	if addr == "" {
+		return "", fmt.Errorf("VAULT_ADDR is not set")
+	}
+
+	req, err := http.NewRequest(http.MethodGet, strings.TrimRight(addr, "/")+"/v1/"+strings.TrimLeft(path, "/"), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", os.Getenv("VAULT_TOKEN"))
+
+	client := &http.Client{Timeout: vaultTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("vault returned %s for %s", resp.Status, path)
+	}
+
+	var body struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("invalid vault response: %w", err)
+	}
+	data := body.Data
+	// KV version 2 nests the secret's fields in data.data
+	if nested, ok := data["data"].(map[string]interface{}); ok {
+		data = nested
+	}
+	value, ok := data[field].(string)
+	if !ok {
+		return "", fmt.Errorf("vault secret %s has no string field %s", path, field)
+	}
+	return value, nil
+}
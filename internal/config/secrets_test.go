@@ -0,0 +1,82 @@
+package config
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoad_ResolvesSecrets(t *testing.T) {
+	vault := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/secret/data/mcp" || r.Header.Get("X-Vault-Token") != "root" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"data": {"data": {"redis_password": "from-vault"}}}`))
+	}))
+	defer vault.Close()
+	t.Setenv("VAULT_ADDR", vault.URL)
+	t.Setenv("VAULT_TOKEN", "root")
+	t.Setenv("TEST_REDIS_HOST", "redis.internal")
+
+	dir := t.TempDir()
+	keyFile := filepath.Join(dir, "api_key")
+	if err := os.WriteFile(keyFile, []byte("from-file\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "config.yaml")
+	write := func(contents string) {
+		if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write(`security:
+  api_keys: ["secret://` + keyFile + `"]
+state:
+  redis:
+    addr: "${TEST_REDIS_HOST}:${TEST_REDIS_PORT:-6379}"
+    password: "vault://secret/data/mcp#redis_password"
+tools:
+  proxies:
+    - name: upstream
+      url: "ws://upstream/mcp?token=secret://` + keyFile + `"
+`)
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Security.APIKeys[0] != "from-file" || cfg.State.Redis.Password != "from-vault" {
+		t.Errorf("Expected the secrets to be resolved, got %v and %q", cfg.Security.APIKeys, cfg.State.Redis.Password)
+	}
+	if cfg.State.Redis.Addr != "redis.internal:6379" {
+		t.Errorf("Expected the environment to be expanded, got %s", cfg.State.Redis.Addr)
+	}
+	// Only whole values are references; other schemes are left alone
+	if !strings.HasPrefix(cfg.Tools.Proxies[0].URL, "ws://upstream/") {
+		t.Errorf("Expected the proxy URL to be kept, got %s", cfg.Tools.Proxies[0].URL)
+	}
+
+	masked := cfg.Masked()
+	if masked.State.Redis.Password != maskedValue || masked.State.Redis.Addr != "redis.internal:6379" {
+		t.Errorf("Expected only secrets to be masked, got %+v", masked.State.Redis)
+	}
+	secrets := strings.Join(cfg.Secrets(), ",")
+	if !strings.Contains(secrets, "from-file") || !strings.Contains(secrets, "from-vault") {
+		t.Errorf("Expected the resolved secrets to be reported, got %s", secrets)
+	}
+
+	for _, invalid := range []string{
+		"state:\n  redis:\n    addr: \"${TEST_UNSET_VARIABLE}\"\n",
+		"security:\n  api_keys: [\"secret://" + filepath.Join(dir, "missing") + "\"]\n",
+		"state:\n  redis:\n    password: \"vault://secret/data/mcp#missing\"\n",
+	} {
+		write(invalid)
+		if _, err := Load(path); err == nil {
+			t.Errorf("Expected %q to fail", invalid)
+		}
+	}
+}
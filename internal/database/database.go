@@ -0,0 +1,102 @@
+// Package database exposes a SQL database to MCP clients: table schemas as
+// resources and a read-only query tool. It uses database/sql, so the
+// driver for the configured database must be linked into the binary, as
+// in import _ "github.com/jackc/pgx/v5/stdlib".
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Dialects whose catalogs the schema resources know how to read
+const (
+	DialectSQLite   = "sqlite"
+	DialectPostgres = "postgres"
+	DialectMySQL    = "mysql"
+)
+
+// Options configures the database connection and what queries may do
+type Options struct {
+	// Driver is the database/sql driver name, such as pgx or sqlite3
+	Driver string
+	DSN    string
+	// Dialect selects the catalog queries; empty derives it from Driver
+	Dialect string
+	// MaxRows caps the rows a query returns
+	MaxRows int
+	// Timeout bounds each query
+	Timeout time.Duration
+	// AllowedStatements lists the statement keywords queries may start
+	// with, such as select and with
+	AllowedStatements []string
+}
+
+// DefaultOptions returns options for read-only SELECT queries
+func DefaultOptions() Options {
+	return Options{
+		MaxRows:           100,
+		Timeout:           10 * time.Second,
+		AllowedStatements: []string{"select", "with"},
+	}
+}
+
+// DB is an open database shared by the schema resources and the query tool
+type DB struct {
+	db      *sql.DB
+	dialect string
+	opts    Options
+}
+
+// Open connects to the database and checks that it answers
+func Open(ctx context.Context, opts Options) (*DB, error) {
+	if opts.Driver == "" || opts.DSN == "" {
+		return nil, fmt.Errorf("database needs a driver and a DSN")
+	}
+	dialect := opts.Dialect
+	if dialect == "" {
+		dialect = dialectOf(opts.Driver)
+	}
+
+	db, err := sql.Open(opts.Driver, opts.DSN)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	pingCtx, cancel := context.WithTimeout(ctx, opts.Timeout)
+	defer cancel()
+	if err := db.PingContext(pingCtx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	return &DB{db: db, dialect: dialect, opts: opts}, nil
+}
+
+// Close closes the database
+func (d *DB) Close() error {
+	return d.db.Close()
+}
+
+// dialectOf guesses the dialect of a driver from its name
+func dialectOf(driver string) string {
+	switch name := strings.ToLower(driver); {
+	case strings.Contains(name, "sqlite"):
+		return DialectSQLite
+	case strings.Contains(name, "postgres"), name == "pgx", name == "pq":
+		return DialectPostgres
+	case strings.Contains(name, "mysql"):
+		return DialectMySQL
+	}
+	return ""
+}
+
+// withTimeout bounds ctx by the query timeout
+func (d *DB) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if d.opts.Timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, d.opts.Timeout)
+}
@@ -0,0 +1,232 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/chongliujia/mcp-go-template/pkg/mcp"
+)
+
+// fakeDriver answers the SQLite catalog queries and SELECTs of the users
+// table, recording the transactions it is asked for
+type fakeDriver struct {
+	mu        sync.Mutex
+	readOnly  []bool
+	rolled    int
+	committed int
+}
+
+var testDriver = &fakeDriver{}
+
+func init() {
+	sql.Register("fakesqlite", testDriver)
+}
+
+func (d *fakeDriver) Open(name string) (driver.Conn, error) {
+	return &fakeConn{driver: d}, nil
+}
+
+type fakeConn struct {
+	driver *fakeDriver
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, fmt.Errorf("prepare is not supported")
+}
+
+func (c *fakeConn) Close() error { return nil }
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *fakeConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	c.driver.mu.Lock()
+	defer c.driver.mu.Unlock()
+	c.driver.readOnly = append(c.driver.readOnly, opts.ReadOnly)
+	return &fakeTx{driver: c.driver}, nil
+}
+
+func (c *fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	switch {
+	case strings.Contains(query, "sqlite_master"):
+		return &fakeRows{columns: []string{"name"}, values: [][]driver.Value{{"orders"}, {"users"}}}, nil
+	case strings.Contains(query, "pragma_table_info"):
+		if args[0].Value != "users" {
+			return &fakeRows{columns: []string{"name", "type", "nullable"}}, nil
+		}
+		return &fakeRows{columns: []string{"name", "type", "nullable"}, values: [][]driver.Value{
+			{"id", "INTEGER", false},
+			{"email", "TEXT", true},
+		}}, nil
+	case strings.HasPrefix(query, "SELECT * FROM users"):
+		rows := &fakeRows{columns: []string{"id", "email"}}
+		for i := int64(1); i <= 5; i++ {
+			rows.values = append(rows.values, []driver.Value{i, []byte(fmt.Sprintf("user%d@example.com", i))})
+		}
+		return rows, nil
+	}
+	return nil, fmt.Errorf("unexpected query: %s", query)
+}
+
+type fakeTx struct {
+	driver *fakeDriver
+}
+
+func (t *fakeTx) Commit() error {
+	t.driver.mu.Lock()
+	defer t.driver.mu.Unlock()
+	t.driver.committed++
+	return nil
+}
+
+func (t *fakeTx) Rollback() error {
+	t.driver.mu.Lock()
+	defer t.driver.mu.Unlock()
+	t.driver.rolled++
+	return nil
+}
+
+type fakeRows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+
+func (r *fakeRows) Close() error { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+func openTestDB(t *testing.T, maxRows int) *DB {
+	t.Helper()
+	opts := DefaultOptions()
+	opts.Driver = "fakesqlite"
+	opts.DSN = "test"
+	opts.MaxRows = maxRows
+	db, err := Open(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestQueryTool_Execute(t *testing.T) {
+	db := openTestDB(t, 3)
+	tool := db.NewQueryTool()
+
+	if tool.Definition().Category != mcp.ToolCategoryDatabase {
+		t.Errorf("Expected the database category, got %s", tool.Definition().Category)
+	}
+
+	result, err := tool.Execute(context.Background(), map[string]interface{}{
+		"query":  "SELECT * FROM users WHERE id > ?",
+		"params": []interface{}{0},
+	})
+	if err != nil || result.IsError {
+		t.Fatalf("Expected the query to succeed, got %v %+v", err, result)
+	}
+	rows := result.StructuredContent.(*QueryResult)
+	if rows.RowCount != 3 || !rows.Truncated {
+		t.Errorf("Expected 3 rows truncated, got %d truncated=%v", rows.RowCount, rows.Truncated)
+	}
+	if rows.Rows[0]["email"] != "user1@example.com" {
+		t.Errorf("Expected text columns as strings, got %#v", rows.Rows[0]["email"])
+	}
+
+	testDriver.mu.Lock()
+	readOnly, rolled, committed := testDriver.readOnly[len(testDriver.readOnly)-1], testDriver.rolled, testDriver.committed
+	testDriver.mu.Unlock()
+	if !readOnly || rolled == 0 || committed != 0 {
+		t.Errorf("Expected a read-only transaction that is rolled back, got readOnly=%v rolled=%d committed=%d", readOnly, rolled, committed)
+	}
+}
+
+func TestQueryTool_RejectsStatements(t *testing.T) {
+	tool := openTestDB(t, 10).NewQueryTool()
+
+	queries := []string{
+		"DELETE FROM users",
+		"  -- looks harmless\nDROP TABLE users",
+		"SELECT * FROM users; DROP TABLE users",
+		"/* comment */ UPDATE users SET email = ''",
+		"",
+	}
+	for _, query := range queries {
+		result, err := tool.Execute(context.Background(), map[string]interface{}{"query": query})
+		if err != nil || !result.IsError {
+			t.Errorf("Expected %q to be rejected, got %v %+v", query, err, result)
+		}
+	}
+}
+
+func TestCheckStatement(t *testing.T) {
+	db := openTestDB(t, 10)
+
+	tests := []struct {
+		query string
+		ok    bool
+	}{
+		{"SELECT 1", true},
+		{"select 1;", true},
+		{"WITH t AS (SELECT 1) SELECT * FROM t", true},
+		{"-- comment\nSELECT 1", true},
+		{"SELECT ';' AS semicolon", true},
+		{"SELECT 1; -- trailing comment", true},
+		{"SELECT 1; SELECT 2", false},
+		{"INSERT INTO users VALUES (1)", false},
+		{"PRAGMA writable_schema = 1", false},
+		{"-- only a comment", false},
+	}
+
+	for _, tt := range tests {
+		if err := db.checkStatement(tt.query); (err == nil) != tt.ok {
+			t.Errorf("checkStatement(%q) = %v, want ok=%v", tt.query, err, tt.ok)
+		}
+	}
+}
+
+func TestDB_TableResources(t *testing.T) {
+	db := openTestDB(t, 10)
+
+	tables, err := db.TableResources(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to list tables: %v", err)
+	}
+	if len(tables) != 2 || tables[1].Definition().URI != "db://tables/users" {
+		t.Fatalf("Expected the orders and users tables, got %v", tables)
+	}
+
+	result, err := tables[1].Read(context.Background(), "db://tables/users")
+	if err != nil {
+		t.Fatalf("Failed to read schema: %v", err)
+	}
+	var schema TableSchema
+	if err := json.Unmarshal([]byte(result.Contents[0].Text), &schema); err != nil {
+		t.Fatalf("Expected a JSON schema, got %v", err)
+	}
+	if len(schema.Columns) != 2 || schema.Columns[0] != (Column{Name: "id", Type: "integer"}) || !schema.Columns[1].Nullable {
+		t.Errorf("Unexpected schema: %+v", schema)
+	}
+
+	if _, err := db.Read(context.Background(), "db://tables/missing", map[string]string{"table": "missing"}); err == nil {
+		t.Error("Expected reading a missing table to fail")
+	} else if info, ok := mcp.AsErrorInfo(err); !ok || info.Code != mcp.ResourceNotFound {
+		t.Errorf("Expected a resource not found error, got %v", err)
+	}
+}
@@ -0,0 +1,267 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"strings"
+	"unicode"
+
+	"github.com/chongliujia/mcp-go-template/pkg/mcp"
+)
+
+// QueryResult is the structured result of the query tool
+type QueryResult struct {
+	Columns   []string                 `json:"columns"`
+	Rows      []map[string]interface{} `json:"rows"`
+	RowCount  int                      `json:"row_count"`
+	Truncated bool                     `json:"truncated"`
+}
+
+// queryArgs are the arguments of the query tool
+type queryArgs struct {
+	Query  string        `json:"query"`
+	Params []interface{} `json:"params,omitempty"`
+}
+
+// QueryTool runs parameterized read-only queries against a DB. Queries
+// must start with an allowed statement keyword, may hold only one
+// statement, and run in a read-only transaction that is always rolled
+// back, so they cannot change the database even when the driver ignores
+// the read-only flag.
+type QueryTool struct {
+	definition *mcp.Tool
+	db         *DB
+}
+
+// NewQueryTool creates the query tool for db
+func (d *DB) NewQueryTool() *QueryTool {
+	return &QueryTool{
+		definition: &mcp.Tool{
+			Name:        "sql_query",
+			Description: fmt.Sprintf("Runs a read-only SQL query against the server's database and returns up to %d rows. Pass values as params and reference them with placeholders rather than writing them into the query. Allowed statements: %s.", d.opts.MaxRows, strings.Join(d.opts.AllowedStatements, ", ")),
+			Category:    mcp.ToolCategoryDatabase,
+			Tags:        []string{"sql", "data"},
+			InputSchema: mcp.ToolSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"query": map[string]interface{}{
+						"type":        "string",
+						"description": "A single SQL statement, using the driver's placeholders (? or $1) for parameters",
+						"minLength":   1,
+					},
+					"params": map[string]interface{}{
+						"type":        "array",
+						"description": "Values for the query's placeholders, in order",
+					},
+				},
+				Required: []string{"query"},
+			},
+			OutputSchema: &mcp.ToolSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"columns": map[string]interface{}{
+						"type":  "array",
+						"items": map[string]interface{}{"type": "string"},
+					},
+					"rows": map[string]interface{}{
+						"type":  "array",
+						"items": map[string]interface{}{"type": "object"},
+					},
+					"row_count": map[string]interface{}{"type": "integer"},
+					"truncated": map[string]interface{}{"type": "boolean"},
+				},
+				Required: []string{"columns", "rows", "row_count", "truncated"},
+			},
+		},
+		db: d,
+	}
+}
+
+// Definition returns the tool definition
+func (q *QueryTool) Definition() *mcp.Tool {
+	return q.definition
+}
+
+// Execute checks and runs the query
+func (q *QueryTool) Execute(ctx context.Context, params map[string]interface{}) (*mcp.CallToolResult, error) {
+	var args queryArgs
+	if err := mcp.BindArguments(params, &args); err != nil {
+		return queryError(fmt.Sprintf("Error: %v", err)), nil
+	}
+	if err := q.db.checkStatement(args.Query); err != nil {
+		return queryError(fmt.Sprintf("Error: %v", err)), nil
+	}
+
+	result, err := q.db.query(ctx, args.Query, queryParams(args.Params))
+	if err != nil {
+		return queryError(fmt.Sprintf("Error running query: %v", err)), nil
+	}
+
+	text := fmt.Sprintf("Returned %d row(s) with columns: %s", result.RowCount, strings.Join(result.Columns, ", "))
+	if result.Truncated {
+		text += fmt.Sprintf(" (truncated to the first %d rows)", q.db.opts.MaxRows)
+	}
+	return mcp.NewStructuredResult(text, result), nil
+}
+
+// query runs a checked statement in a read-only transaction, fetching one
+// row more than MaxRows to tell whether the result was truncated
+func (d *DB) query(ctx context.Context, query string, params []interface{}) (*QueryResult, error) {
+	ctx, cancel := d.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := d.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	result := &QueryResult{Columns: columns, Rows: []map[string]interface{}{}}
+	for rows.Next() {
+		if d.opts.MaxRows > 0 && len(result.Rows) == d.opts.MaxRows {
+			result.Truncated = true
+			break
+		}
+		values := make([]interface{}, len(columns))
+		pointers := make([]interface{}, len(columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			return nil, err
+		}
+
+		row := make(map[string]interface{}, len(columns))
+		for i, column := range columns {
+			if data, ok := values[i].([]byte); ok {
+				row[column] = string(data)
+			} else {
+				row[column] = values[i]
+			}
+		}
+		result.Rows = append(result.Rows, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	result.RowCount = len(result.Rows)
+	return result, nil
+}
+
+// checkStatement rejects queries that hold more than one statement or do
+// not start with an allowed keyword
+func (d *DB) checkStatement(query string) error {
+	statement := strings.TrimSpace(skipComments(query))
+	if statement == "" {
+		return fmt.Errorf("query cannot be empty")
+	}
+	if multipleStatements(statement) {
+		return fmt.Errorf("query must hold a single statement")
+	}
+
+	words := strings.FieldsFunc(statement, func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	if len(words) == 0 {
+		return fmt.Errorf("query must start with a statement keyword")
+	}
+	keyword := strings.ToLower(words[0])
+	for _, allowed := range d.opts.AllowedStatements {
+		if strings.EqualFold(keyword, allowed) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s statements are not allowed (allowed: %s)", strings.ToUpper(keyword), strings.Join(d.opts.AllowedStatements, ", "))
+}
+
+// skipComments drops the whitespace and comments before a statement
+func skipComments(query string) string {
+	for {
+		query = strings.TrimLeftFunc(query, unicode.IsSpace)
+		switch {
+		case strings.HasPrefix(query, "--"):
+			end := strings.IndexByte(query, '\n')
+			if end < 0 {
+				return ""
+			}
+			query = query[end+1:]
+		case strings.HasPrefix(query, "/*"):
+			end := strings.Index(query[2:], "*/")
+			if end < 0 {
+				return ""
+			}
+			query = query[end+4:]
+		default:
+			return query
+		}
+	}
+}
+
+// multipleStatements reports whether a semicolon outside quotes and
+// comments is followed by anything but whitespace
+func multipleStatements(query string) bool {
+	var quote byte
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case strings.HasPrefix(query[i:], "--"):
+			end := strings.IndexByte(query[i:], '\n')
+			if end < 0 {
+				return false
+			}
+			i += end
+		case strings.HasPrefix(query[i:], "/*"):
+			end := strings.Index(query[i+2:], "*/")
+			if end < 0 {
+				return false
+			}
+			i += end + 3
+		case c == ';':
+			return strings.TrimSpace(skipComments(query[i+1:])) != ""
+		}
+	}
+	return false
+}
+
+// queryParams passes whole JSON numbers as integers, which drivers bind
+// to integer columns more reliably than floats
+func queryParams(params []interface{}) []interface{} {
+	converted := make([]interface{}, len(params))
+	for i, param := range params {
+		if f, ok := param.(float64); ok && f == math.Trunc(f) && math.Abs(f) < 1<<53 {
+			converted[i] = int64(f)
+		} else {
+			converted[i] = param
+		}
+	}
+	return converted
+}
+
+// queryError creates an error result for the query tool
+func queryError(message string) *mcp.CallToolResult {
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{{
+			Type: "text",
+			Text: message,
+		}},
+		IsError: true,
+	}
+}
@@ -0,0 +1,160 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/chongliujia/mcp-go-template/pkg/mcp"
+)
+
+// tableURIPrefix starts the URI of every table schema resource
+const tableURIPrefix = "db://tables/"
+
+// catalog holds a dialect's queries for table names and a table's columns;
+// the columns query takes the table name as its only parameter
+type catalog struct {
+	tables  string
+	columns string
+}
+
+var catalogs = map[string]catalog{
+	DialectSQLite: {
+		tables:  `SELECT name FROM sqlite_master WHERE type IN ('table', 'view') AND name NOT LIKE 'sqlite_%' ORDER BY name`,
+		columns: `SELECT name, type, "notnull" = 0 FROM pragma_table_info(?) ORDER BY cid`,
+	},
+	DialectPostgres: {
+		tables:  `SELECT table_name FROM information_schema.tables WHERE table_schema = current_schema() ORDER BY table_name`,
+		columns: `SELECT column_name, data_type, is_nullable = 'YES' FROM information_schema.columns WHERE table_schema = current_schema() AND table_name = $1 ORDER BY ordinal_position`,
+	},
+	DialectMySQL: {
+		tables:  `SELECT table_name FROM information_schema.tables WHERE table_schema = DATABASE() ORDER BY table_name`,
+		columns: `SELECT column_name, data_type, is_nullable = 'YES' FROM information_schema.columns WHERE table_schema = DATABASE() AND table_name = ? ORDER BY ordinal_position`,
+	},
+}
+
+// Column describes one column of a table
+type Column struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Nullable bool   `json:"nullable"`
+}
+
+// TableSchema is the document served for a table
+type TableSchema struct {
+	Table   string   `json:"table"`
+	Columns []Column `json:"columns"`
+}
+
+// TableResources returns a resource for each table in the database. The
+// DB itself is a resource template for db://tables/{table}, so tables
+// created later can still be read.
+func (d *DB) TableResources(ctx context.Context) ([]mcp.ResourceHandler, error) {
+	cat, ok := catalogs[d.dialect]
+	if !ok {
+		return nil, fmt.Errorf("schema resources do not support driver %q; set a dialect of sqlite, postgres or mysql", d.opts.Driver)
+	}
+
+	ctx, cancel := d.withTimeout(ctx)
+	defer cancel()
+	rows, err := d.db.QueryContext(ctx, cat.tables)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tables: %w", err)
+	}
+	defer rows.Close()
+
+	var resources []mcp.ResourceHandler
+	for rows.Next() {
+		var table string
+		if err := rows.Scan(&table); err != nil {
+			return nil, fmt.Errorf("failed to list tables: %w", err)
+		}
+		resources = append(resources, &tableResource{db: d, table: table})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list tables: %w", err)
+	}
+	return resources, nil
+}
+
+// Definition returns the template matching every table schema URI
+func (d *DB) Definition() *mcp.ResourceTemplate {
+	return &mcp.ResourceTemplate{
+		URITemplate: tableURIPrefix + "{table}",
+		Name:        "Table schema",
+		Description: "Columns of a table in the server's database",
+		MimeType:    "application/json",
+	}
+}
+
+// Read returns the schema of the table in uri
+func (d *DB) Read(ctx context.Context, uri string, vars map[string]string) (*mcp.ReadResourceResult, error) {
+	return d.readSchema(ctx, uri, vars["table"])
+}
+
+// readSchema returns the columns of table as JSON. A table without columns
+// does not exist.
+func (d *DB) readSchema(ctx context.Context, uri, table string) (*mcp.ReadResourceResult, error) {
+	cat, ok := catalogs[d.dialect]
+	if !ok {
+		return nil, mcp.ResourceNotFoundError(uri)
+	}
+
+	ctx, cancel := d.withTimeout(ctx)
+	defer cancel()
+	rows, err := d.db.QueryContext(ctx, cat.columns, table)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the columns of %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	schema := TableSchema{Table: table, Columns: []Column{}}
+	for rows.Next() {
+		var column Column
+		var nullable sql.NullBool
+		if err := rows.Scan(&column.Name, &column.Type, &nullable); err != nil {
+			return nil, fmt.Errorf("failed to read the columns of %s: %w", table, err)
+		}
+		column.Type = strings.ToLower(column.Type)
+		column.Nullable = nullable.Bool
+		schema.Columns = append(schema.Columns, column)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read the columns of %s: %w", table, err)
+	}
+	if len(schema.Columns) == 0 {
+		return nil, mcp.ResourceNotFoundError(uri)
+	}
+
+	data, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode schema: %w", err)
+	}
+	return &mcp.ReadResourceResult{
+		Contents: []mcp.ResourceContents{mcp.NewTextResourceContents(uri, "application/json", string(data))},
+	}, nil
+}
+
+// tableResource is the schema of one listed table
+type tableResource struct {
+	db    *DB
+	table string
+}
+
+// Definition returns the resource definition
+func (r *tableResource) Definition() *mcp.Resource {
+	return &mcp.Resource{
+		URI:         tableURIPrefix + url.PathEscape(r.table),
+		Name:        r.table,
+		Description: "Columns of the " + r.table + " table",
+		MimeType:    "application/json",
+	}
+}
+
+// Read returns the table's schema
+func (r *tableResource) Read(ctx context.Context, uri string) (*mcp.ReadResourceResult, error) {
+	return r.db.readSchema(ctx, uri, r.table)
+}
@@ -0,0 +1,131 @@
+package prompts
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/chongliujia/mcp-go-template/pkg/mcp"
+)
+
+// maxIncludeDepth bounds how deeply template prompts can include each other
+const maxIncludeDepth = 8
+
+// Composer serves the prompts and tools that template prompts include with
+// the prompt and tool functions; *mcp.BaseHandler is one
+type Composer interface {
+	GetPrompt(ctx context.Context, params *mcp.GetPromptParams) (*mcp.GetPromptResult, error)
+	CallTool(ctx context.Context, params *mcp.CallToolParams) (*mcp.CallToolResult, error)
+}
+
+// includeDepthKey holds how many prompt inclusions led to a Generate call
+type includeDepthKey struct{}
+
+// SetComposer sets where the prompt and tool functions of the templates
+// look up prompts and tools. Without one, templates using them fail.
+func (p *TemplatePrompt) SetComposer(composer Composer) {
+	p.composer = composer
+}
+
+// composeFuncs returns the prompt and tool functions for a Generate call
+// with ctx:
+//
+//	{{prompt "research_prompt" "topic" .topic}} renders another prompt's text
+//	{{tool "web_search" "query" .topic}} calls a tool listed in Tools
+//
+// Arguments are given as name and value pairs or as a single map.
+func (p *TemplatePrompt) composeFuncs(ctx context.Context) template.FuncMap {
+	depth, _ := ctx.Value(includeDepthKey{}).(int)
+
+	return template.FuncMap{
+		"prompt": func(name string, pairs ...interface{}) (string, error) {
+			if p.composer == nil {
+				return "", fmt.Errorf("prompt %s cannot be included: no prompts are available", name)
+			}
+			if depth >= maxIncludeDepth {
+				return "", fmt.Errorf("prompt %s cannot be included: prompts are nested more than %d deep", name, maxIncludeDepth)
+			}
+			arguments, err := argumentMap(pairs)
+			if err != nil {
+				return "", fmt.Errorf("prompt %s: %w", name, err)
+			}
+
+			included, err := p.composer.GetPrompt(context.WithValue(ctx, includeDepthKey{}, depth+1), &mcp.GetPromptParams{Name: name, Arguments: arguments})
+			if err != nil {
+				return "", fmt.Errorf("prompt %s: %w", name, err)
+			}
+			var texts []string
+			for _, message := range included.Messages {
+				if text := contentText(message.Content); text != "" {
+					texts = append(texts, text)
+				}
+			}
+			return strings.Join(texts, "\n\n"), nil
+		},
+		"tool": func(name string, pairs ...interface{}) (string, error) {
+			if p.composer == nil {
+				return "", fmt.Errorf("tool %s cannot be called: no tools are available", name)
+			}
+			if !p.allowsTool(name) {
+				return "", fmt.Errorf("tool %s cannot be called: it is not listed in the prompt's tools", name)
+			}
+			arguments, err := argumentMap(pairs)
+			if err != nil {
+				return "", fmt.Errorf("tool %s: %w", name, err)
+			}
+
+			result, err := p.composer.CallTool(ctx, &mcp.CallToolParams{Name: name, Arguments: arguments})
+			if err != nil {
+				return "", fmt.Errorf("tool %s: %w", name, err)
+			}
+			if result.IsError {
+				return "", fmt.Errorf("tool %s failed: %s", name, contentText(result.Content))
+			}
+			return contentText(result.Content), nil
+		},
+	}
+}
+
+// allowsTool reports whether the definition lists name in its tools
+func (p *TemplatePrompt) allowsTool(name string) bool {
+	for _, tool := range p.definition.Tools {
+		if tool == name {
+			return true
+		}
+	}
+	return false
+}
+
+// argumentMap turns name and value pairs, or a single map, into arguments
+func argumentMap(pairs []interface{}) (map[string]interface{}, error) {
+	if len(pairs) == 1 {
+		if arguments, ok := pairs[0].(map[string]interface{}); ok {
+			return arguments, nil
+		}
+	}
+	if len(pairs)%2 != 0 {
+		return nil, fmt.Errorf("arguments must be name and value pairs")
+	}
+
+	arguments := make(map[string]interface{}, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		name, ok := pairs[i].(string)
+		if !ok {
+			return nil, fmt.Errorf("argument name %v is not a string", pairs[i])
+		}
+		arguments[name] = pairs[i+1]
+	}
+	return arguments, nil
+}
+
+// contentText joins the text items of content
+func contentText(content []mcp.Content) string {
+	var texts []string
+	for _, item := range content {
+		if item.Type == mcp.ContentTypeText && item.Text != "" {
+			texts = append(texts, item.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
@@ -0,0 +1,88 @@
+package prompts
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/chongliujia/mcp-go-template/pkg/mcp"
+)
+
+func TestTemplatePrompt_ComposesPromptsAndTools(t *testing.T) {
+	handler := mcp.NewBaseHandler(mcp.ServerInfo{Name: "test", Version: "1.0.0"}, mcp.ServerCapabilities{
+		Tools:   &mcp.ToolsCapability{},
+		Prompts: &mcp.PromptsCapability{},
+	})
+	handler.RegisterToolFunc(&mcp.Tool{Name: "web_search", InputSchema: mcp.ToolSchema{Type: "object"}}, func(ctx context.Context, params map[string]interface{}) (*mcp.CallToolResult, error) {
+		return &mcp.CallToolResult{Content: []mcp.Content{mcp.NewTextContent("Results for " + params["query"].(string))}}, nil
+	})
+	handler.RegisterToolFunc(&mcp.Tool{Name: "delete_files", InputSchema: mcp.ToolSchema{Type: "object"}}, func(ctx context.Context, params map[string]interface{}) (*mcp.CallToolResult, error) {
+		t.Error("Expected an unlisted tool not to be called")
+		return nil, nil
+	})
+
+	definitions := []TemplateDefinition{
+		{
+			Name:      "summarize",
+			Arguments: []TemplateArgument{{Name: "style", Default: "brief"}},
+			Messages:  []MessageTemplate{{Role: "user", Text: "Summarize the findings in a {{.style}} style."}},
+		},
+		{
+			Name:      "research_workflow",
+			Arguments: []TemplateArgument{{Name: "topic", Required: true}},
+			Tools:     []string{"web_search"},
+			Messages: []MessageTemplate{{Role: "user", Text: `Sources:
+{{tool "web_search" "query" .topic}}
+
+{{prompt "summarize" "style" "detailed"}}`}},
+		},
+		{
+			Name:     "unlisted_tool",
+			Messages: []MessageTemplate{{Role: "user", Text: `{{tool "delete_files"}}`}},
+		},
+		{
+			Name:     "loop",
+			Messages: []MessageTemplate{{Role: "user", Text: `{{prompt "loop"}}`}},
+		},
+	}
+	for _, definition := range definitions {
+		prompt, err := NewTemplatePrompt(definition)
+		if err != nil {
+			t.Fatalf("Failed to parse %s: %v", definition.Name, err)
+		}
+		prompt.SetComposer(handler)
+		handler.RegisterPrompt(prompt)
+	}
+	ctx := context.Background()
+	handler.HandleMessage(ctx, mcp.NewNotification("initialized", nil))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, err := handler.GetPrompt(ctx, &mcp.GetPromptParams{Name: "research_workflow", Arguments: map[string]interface{}{"topic": "solar"}})
+			if err != nil {
+				t.Errorf("GetPrompt failed: %v", err)
+				return
+			}
+			expected := "Sources:\nResults for solar\n\nSummarize the findings in a detailed style."
+			if text := result.Messages[0].Content[0].Text; text != expected {
+				t.Errorf("Unexpected text: %q", text)
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, name := range []string{"unlisted_tool", "loop"} {
+		if _, err := handler.GetPrompt(ctx, &mcp.GetPromptParams{Name: name}); err == nil {
+			t.Errorf("Expected %s to fail", name)
+		}
+	}
+
+	orphan, _ := NewTemplatePrompt(definitions[1])
+	if _, err := orphan.Generate(ctx, map[string]interface{}{"topic": "solar"}); err == nil || !strings.Contains(err.Error(), "no tools are available") {
+		t.Errorf("Expected a prompt without a composer to fail, got %v", err)
+	}
+}
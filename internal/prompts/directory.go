@@ -0,0 +1,232 @@
+package prompts
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"gopkg.in/yaml.v3"
+
+	"github.com/chongliujia/mcp-go-template/pkg/mcp"
+	"github.com/chongliujia/mcp-go-template/pkg/utils"
+)
+
+// reloadDebounce coalesces bursts of filesystem events into one reload
+const reloadDebounce = 250 * time.Millisecond
+
+// PromptTarget is where a DirectoryLoader registers the prompts it loads
+type PromptTarget interface {
+	RegisterPrompt(handler mcp.PromptHandler) error
+	UnregisterPrompt(name string) error
+	ListPrompts() ([]*mcp.Prompt, error)
+}
+
+// loadedPrompt records a prompt loaded from a definition file
+type loadedPrompt struct {
+	path string
+	hash [sha256.Size]byte
+}
+
+// DirectoryLoader loads template prompt definitions from a directory into
+// a PromptTarget and keeps them in sync as files are added, changed, or
+// removed
+type DirectoryLoader struct {
+	dir    string
+	target PromptTarget
+	logger utils.Logger
+
+	mu     sync.Mutex
+	loaded map[string]loadedPrompt // prompt name -> source file
+}
+
+// NewDirectoryLoader creates a loader for dir
+func NewDirectoryLoader(dir string, target PromptTarget) *DirectoryLoader {
+	return &DirectoryLoader{
+		dir:    dir,
+		target: target,
+		logger: utils.DefaultLogger(),
+		loaded: make(map[string]loadedPrompt),
+	}
+}
+
+// SetLogger sets the logger the loader logs to. It must be called before
+// Load.
+func (l *DirectoryLoader) SetLogger(logger utils.Logger) {
+	l.logger = logger
+}
+
+// LoadPromptFile reads a JSON or YAML prompt definition and parses its
+// templates
+func LoadPromptFile(path string) (*TemplatePrompt, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read prompt file: %w", err)
+	}
+	return parsePromptFile(path, data)
+}
+
+// parsePromptFile decodes a definition according to its file extension
+func parsePromptFile(path string, data []byte) (*TemplatePrompt, error) {
+	var err error
+	var definition TemplateDefinition
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		err = json.Unmarshal(data, &definition)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &definition)
+	default:
+		return nil, fmt.Errorf("unsupported prompt file format: %s", filepath.Ext(path))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse prompt file %s: %w", path, err)
+	}
+
+	prompt, err := NewTemplatePrompt(definition)
+	if err != nil {
+		return nil, fmt.Errorf("prompt file %s: %w", path, err)
+	}
+	return prompt, nil
+}
+
+// Load scans the directory and brings the registered prompts in line with
+// the definitions it contains
+func (l *DirectoryLoader) Load() error {
+	entries, err := os.ReadDir(l.dir)
+	if err != nil {
+		return fmt.Errorf("failed to read prompts directory: %w", err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	existing, err := l.target.ListPrompts()
+	if err != nil {
+		return fmt.Errorf("failed to list prompts: %w", err)
+	}
+	builtin := make(map[string]bool, len(existing))
+	for _, prompt := range existing {
+		if _, ours := l.loaded[prompt.Name]; !ours {
+			builtin[prompt.Name] = true
+		}
+	}
+
+	found := make(map[string]bool)
+	for _, entry := range entries {
+		if entry.IsDir() || !isPromptFile(entry.Name()) {
+			continue
+		}
+
+		path := filepath.Join(l.dir, entry.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			l.logger.Warnf("Skipping prompt file %s: %v", path, err)
+			continue
+		}
+
+		prompt, err := parsePromptFile(path, data)
+		if err != nil {
+			l.logger.Warnf("Skipping prompt file: %v", err)
+			continue
+		}
+		if composer, ok := l.target.(Composer); ok {
+			prompt.SetComposer(composer)
+		}
+		name := prompt.Definition().Name
+
+		if builtin[name] {
+			l.logger.Warnf("Skipping prompt file %s: prompt '%s' is already registered", path, name)
+			continue
+		}
+		if found[name] {
+			l.logger.Warnf("Skipping prompt file %s: duplicate prompt name '%s'", path, name)
+			continue
+		}
+		found[name] = true
+
+		hash := sha256.Sum256(data)
+		if prev, exists := l.loaded[name]; exists && prev.path == path && prev.hash == hash {
+			continue
+		}
+
+		if err := l.target.RegisterPrompt(prompt); err != nil {
+			l.logger.Warnf("Failed to register prompt from %s: %v", path, err)
+			continue
+		}
+		l.loaded[name] = loadedPrompt{path: path, hash: hash}
+		l.logger.Infof("Loaded prompt %s from %s", name, path)
+	}
+
+	for name := range l.loaded {
+		if found[name] {
+			continue
+		}
+		if err := l.target.UnregisterPrompt(name); err != nil {
+			l.logger.Warnf("Failed to unregister prompt %s: %v", name, err)
+		}
+		delete(l.loaded, name)
+		l.logger.Infof("Unloaded prompt %s", name)
+	}
+
+	return nil
+}
+
+// Watch reloads the directory whenever its contents change, until ctx is
+// done. The target re-registers changed prompts, which notifies clients
+// with prompts/list_changed.
+func (l *DirectoryLoader) Watch(ctx context.Context) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create watcher: %w", err)
+	}
+	if err := watcher.Add(l.dir); err != nil {
+		watcher.Close()
+		return fmt.Errorf("failed to watch prompts directory: %w", err)
+	}
+
+	go func() {
+		defer watcher.Close()
+
+		var reload <-chan time.Time
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if isPromptFile(event.Name) {
+					reload = time.After(reloadDebounce)
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				l.logger.Warnf("Prompts directory watcher error: %v", err)
+			case <-reload:
+				reload = nil
+				if err := l.Load(); err != nil {
+					l.logger.Errorf("Failed to reload prompts directory: %v", err)
+				}
+			}
+		}
+	}()
+
+	return nil
+}
+
+// isPromptFile reports whether name has a prompt definition extension
+func isPromptFile(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".json", ".yaml", ".yml":
+		return true
+	}
+	return false
+}
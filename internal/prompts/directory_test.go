@@ -0,0 +1,130 @@
+package prompts
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/chongliujia/mcp-go-template/pkg/mcp"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+}
+
+func promptNames(t *testing.T, handler *mcp.BaseHandler) map[string]bool {
+	t.Helper()
+	list, err := handler.ListPrompts()
+	if err != nil {
+		t.Fatalf("ListPrompts failed: %v", err)
+	}
+	names := make(map[string]bool, len(list))
+	for _, prompt := range list {
+		names[prompt.Name] = true
+	}
+	return names
+}
+
+func TestDirectoryLoader_LoadsPromptFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "review.yaml"), `name: code_review
+description: Reviews a change
+arguments:
+  - name: language
+    required: true
+  - name: focus
+    default: correctness
+messages:
+  - role: user
+    text: |
+      Review this {{.language}} change for {{.focus}}.
+      {{template "checklist"}}
+partials:
+  checklist: Check the tests.
+`)
+	writeFile(t, filepath.Join(dir, "greet.json"), `{"name": "greet", "messages": [{"role": "user", "text": "Say hello to {{.name}}"}], "arguments": [{"name": "name", "required": true}]}`)
+	writeFile(t, filepath.Join(dir, "broken.yaml"), "name: broken\nmessages:\n  - role: user\n    text: \"{{if}}\"\n")
+	writeFile(t, filepath.Join(dir, "builtin.json"), `{"name": "research_prompt", "messages": [{"role": "user", "text": "Hi"}]}`)
+	writeFile(t, filepath.Join(dir, "notes.txt"), "not a prompt")
+
+	handler := mcp.NewBaseHandler(mcp.ServerInfo{Name: "test", Version: "1.0.0"}, mcp.ServerCapabilities{})
+	handler.RegisterPrompt(&TemplatePrompt{definition: TemplateDefinition{Name: "research_prompt"}})
+
+	loader := NewDirectoryLoader(dir, handler)
+	if err := loader.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if names := promptNames(t, handler); len(names) != 3 || !names["code_review"] || !names["greet"] {
+		t.Fatalf("Expected code_review and greet beside the builtin prompt, got %v", names)
+	}
+	if _, ours := loader.loaded["research_prompt"]; ours {
+		t.Error("Expected the builtin prompt to be kept")
+	}
+
+	ctx := context.Background()
+	handler.HandleMessage(ctx, mcp.NewNotification("initialized", nil))
+	prompt, err := handler.GetPrompt(ctx, &mcp.GetPromptParams{Name: "code_review", Arguments: map[string]interface{}{"language": "Go"}})
+	if err != nil {
+		t.Fatalf("GetPrompt failed: %v", err)
+	}
+	if prompt.Messages[0].Content[0].Text != "Review this Go change for correctness.\nCheck the tests." {
+		t.Errorf("Unexpected rendering: %+v", prompt)
+	}
+
+	// Removing a file unregisters its prompt
+	os.Remove(filepath.Join(dir, "greet.json"))
+	loader.Load()
+	if names := promptNames(t, handler); names["greet"] || !names["code_review"] {
+		t.Errorf("Expected greet to be unloaded, got %v", names)
+	}
+}
+
+func TestDirectoryLoader_WatchReloadsChangedFiles(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "greet.yaml")
+	writeFile(t, path, "name: greet\nmessages:\n  - role: user\n    text: Hello\n")
+
+	handler := mcp.NewBaseHandler(mcp.ServerInfo{Name: "test", Version: "1.0.0"}, mcp.ServerCapabilities{
+		Prompts: &mcp.PromptsCapability{ListChanged: true},
+	})
+	notified := make(chan string, 10)
+	handler.SetNotifier(func(notification *mcp.Message) {
+		notified <- notification.Method
+	})
+
+	loader := NewDirectoryLoader(dir, handler)
+	if err := loader.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	<-notified
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := loader.Watch(ctx); err != nil {
+		t.Fatalf("Watch failed: %v", err)
+	}
+
+	writeFile(t, path, "name: greet\nmessages:\n  - role: user\n    text: Hello again\n")
+	select {
+	case method := <-notified:
+		if method != "notifications/prompts/list_changed" {
+			t.Errorf("Expected a list_changed notification, got %s", method)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the prompt to be reloaded")
+	}
+
+	handler.HandleMessage(ctx, mcp.NewNotification("initialized", nil))
+	prompt, err := handler.GetPrompt(ctx, &mcp.GetPromptParams{Name: "greet"})
+	if err != nil {
+		t.Fatalf("GetPrompt failed: %v", err)
+	}
+	if text := prompt.Messages[0].Content[0].Text; text != "Hello again" {
+		t.Errorf("Expected the edited prompt, got %q", text)
+	}
+}
@@ -0,0 +1,78 @@
+package examples
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/chongliujia/mcp-go-template/pkg/mcp"
+)
+
+// researchTemplates holds the wording of each research prompt version
+var researchTemplates = map[string]string{
+	"v1": "Research the topic \"%s\" and write a %s summary of your findings.",
+	"v2": `You are a research assistant. Investigate the topic "%s" and produce a %s report.
+
+1. Use web_search to gather current sources.
+2. Use document_analyzer on the most relevant sources to extract key points.
+3. Use knowledge_graph to map the main entities and how they relate.
+4. Summarize the findings, citing the sources you used and noting open questions.`,
+}
+
+// researchDepths are the levels of detail the research prompt accepts
+var researchDepths = []string{"brief", "standard", "detailed"}
+
+// ResearchPrompt guides a model through researching a topic with the
+// research tool suite. Each version is registered as research_prompt:<version>.
+type ResearchPrompt struct {
+	version string
+}
+
+// NewResearchPrompt creates the research prompt variant for version
+func NewResearchPrompt(version string) *ResearchPrompt {
+	return &ResearchPrompt{version: version}
+}
+
+// Definition returns the prompt definition
+func (p *ResearchPrompt) Definition() *mcp.Prompt {
+	return &mcp.Prompt{
+		Name:        "research_prompt:" + p.version,
+		Description: "Guides a structured research session on a topic using the research tools",
+		Arguments: []mcp.PromptArgument{
+			{
+				Name:        "topic",
+				Description: "Topic to research",
+				Required:    true,
+			},
+			{
+				Name:        "depth",
+				Description: "Level of detail: brief, standard, or detailed (default: standard)",
+				Default:     "standard",
+				Values:      researchDepths,
+			},
+		},
+	}
+}
+
+// Generate renders the prompt for the given arguments
+func (p *ResearchPrompt) Generate(ctx context.Context, params map[string]interface{}) (*mcp.GetPromptResult, error) {
+	template, exists := researchTemplates[p.version]
+	if !exists {
+		return nil, fmt.Errorf("unknown research prompt version: %s", p.version)
+	}
+
+	topic, _ := params["topic"].(string)
+	if strings.TrimSpace(topic) == "" {
+		return nil, mcp.InvalidParamsError("topic", "is required")
+	}
+
+	depth, _ := params["depth"].(string)
+	if depth == "" {
+		depth = "standard"
+	}
+
+	return mcp.NewPromptResult(
+		fmt.Sprintf("Research prompt (%s) for %s", p.version, topic),
+		mcp.NewUserMessage(fmt.Sprintf(template, topic, depth)),
+	), nil
+}
@@ -4,23 +4,36 @@ import (
 	"fmt"
 	"sync"
 
+	"github.com/chongliujia/mcp-go-template/internal/prompts/examples"
 	"github.com/chongliujia/mcp-go-template/pkg/mcp"
 	"github.com/chongliujia/mcp-go-template/pkg/utils"
 )
 
 // Registry manages prompt registration and discovery
 type Registry struct {
-	prompts map[string]mcp.PromptHandler
-	mutex   sync.RWMutex
+	prompts  map[string]mcp.PromptHandler
+	defaults map[string]string         // base name -> default version
+	weights  map[string]map[string]int // base name -> version -> weight
+	mutex    sync.RWMutex
+	logger   utils.Logger
 }
 
 // NewRegistry creates a new prompt registry
 func NewRegistry() *Registry {
 	return &Registry{
-		prompts: make(map[string]mcp.PromptHandler),
+		prompts:  make(map[string]mcp.PromptHandler),
+		defaults: make(map[string]string),
+		weights:  make(map[string]map[string]int),
+		logger:   utils.DefaultLogger(),
 	}
 }
 
+// SetLogger sets the logger the registry logs to. It must be called before
+// prompts are registered.
+func (r *Registry) SetLogger(logger utils.Logger) {
+	r.logger = logger
+}
+
 // Register registers a prompt handler
 func (r *Registry) Register(handler mcp.PromptHandler) error {
 	r.mutex.Lock()
@@ -39,7 +52,7 @@ func (r *Registry) Register(handler mcp.PromptHandler) error {
 	}
 
 	r.prompts[prompt.Name] = handler
-	utils.Infof("Registered prompt: %s", prompt.Name)
+	r.logger.Infof("Registered prompt: %s", prompt.Name)
 	return nil
 }
 
@@ -53,7 +66,7 @@ func (r *Registry) Unregister(name string) error {
 	}
 
 	delete(r.prompts, name)
-	utils.Infof("Unregistered prompt: %s", name)
+	r.logger.Infof("Unregistered prompt: %s", name)
 	return nil
 }
 
@@ -90,15 +103,16 @@ func (r *Registry) Count() int {
 	return len(r.prompts)
 }
 
-// RegisterDefaultPrompts is currently disabled - implement example prompts as needed
+// RegisterDefaultPrompts registers all default example prompts
 func (r *Registry) RegisterDefaultPrompts() error {
-	// TODO: Implement example prompts
-	// Example code:
-	// if err := r.Register(examples.NewCodeAnalysisPrompt()); err != nil {
-	//     return fmt.Errorf("failed to register code analysis prompt: %w", err)
-	// }
-	
-	utils.Infof("No default prompts to register currently")
+	// Register both research prompt variants
+	for _, version := range []string{"v1", "v2"} {
+		if err := r.Register(examples.NewResearchPrompt(version)); err != nil {
+			return fmt.Errorf("failed to register research prompt %s: %w", version, err)
+		}
+	}
+
+	r.logger.Infof("Successfully registered %d default prompts", r.Count())
 	return nil
 }
 
@@ -130,5 +144,7 @@ func (r *Registry) Clear() {
 	defer r.mutex.Unlock()
 
 	r.prompts = make(map[string]mcp.PromptHandler)
-	utils.Info("Cleared all registered prompts")
+	r.defaults = make(map[string]string)
+	r.weights = make(map[string]map[string]int)
+	r.logger.Info("Cleared all registered prompts")
 }
\ No newline at end of file
@@ -0,0 +1,191 @@
+package prompts
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/chongliujia/mcp-go-template/pkg/mcp"
+)
+
+// TemplateArgument is an argument of a template prompt
+type TemplateArgument struct {
+	Name        string   `json:"name" yaml:"name"`
+	Description string   `json:"description" yaml:"description"`
+	Required    bool     `json:"required" yaml:"required"`
+	Default     string   `json:"default" yaml:"default"` // used when the argument is not given
+	Values      []string `json:"values" yaml:"values"`   // suggested by completion
+}
+
+// MessageTemplate is the text/template source of one prompt message
+type MessageTemplate struct {
+	Role string `json:"role" yaml:"role"` // user or assistant
+	Text string `json:"text" yaml:"text"`
+	// Resources are templates of the URIs of resources embedded after the
+	// text, such as file:///docs/{{.name}}.md; empty URIs are skipped
+	Resources []string `json:"resources" yaml:"resources"`
+}
+
+// TemplateDefinition describes a prompt rendered from Go templates.
+// Messages see the arguments as fields, as in {{.topic}}, and can include
+// a partial with {{template "name" .}}, another prompt with
+// {{prompt "name" ...}} and a tool's output with {{tool "name" ...}}.
+// Definitions can also be loaded from JSON or YAML files with a
+// DirectoryLoader.
+type TemplateDefinition struct {
+	Name        string             `json:"name" yaml:"name"`
+	Description string             `json:"description" yaml:"description"`
+	Arguments   []TemplateArgument `json:"arguments" yaml:"arguments"`
+	Messages    []MessageTemplate  `json:"messages" yaml:"messages"`
+	Partials    map[string]string  `json:"partials" yaml:"partials"`
+	// Tools lists the tools the templates may call with the tool function
+	Tools []string `json:"tools" yaml:"tools"`
+}
+
+// templateFuncs are the functions available to prompt templates in
+// addition to the text/template builtins
+var templateFuncs = template.FuncMap{
+	"default": func(fallback string, value interface{}) interface{} {
+		if value == nil || value == "" {
+			return fallback
+		}
+		return value
+	},
+	"upper": func(s string) string { return strings.ToUpper(s) },
+	"lower": func(s string) string { return strings.ToLower(s) },
+	"trim":  strings.TrimSpace,
+	"join": func(sep string, values []interface{}) string {
+		parts := make([]string, len(values))
+		for i, value := range values {
+			parts[i] = fmt.Sprint(value)
+		}
+		return strings.Join(parts, sep)
+	},
+	// prompt and tool are replaced by composeFuncs when rendering
+	"prompt": func(string, ...interface{}) (string, error) { return "", nil },
+	"tool":   func(string, ...interface{}) (string, error) { return "", nil },
+}
+
+// TemplatePrompt is a prompt whose messages are rendered from templates
+type TemplatePrompt struct {
+	definition TemplateDefinition
+	templates  *template.Template
+	composer   Composer
+}
+
+// NewTemplatePrompt parses the templates of definition
+func NewTemplatePrompt(definition TemplateDefinition) (*TemplatePrompt, error) {
+	if definition.Name == "" {
+		return nil, fmt.Errorf("prompt name cannot be empty")
+	}
+	if len(definition.Messages) == 0 {
+		return nil, fmt.Errorf("prompt '%s' has no messages", definition.Name)
+	}
+
+	templates := template.New(definition.Name).Funcs(templateFuncs).Option("missingkey=error")
+	for name, text := range definition.Partials {
+		if _, err := templates.New(name).Parse(text); err != nil {
+			return nil, fmt.Errorf("prompt '%s': invalid partial '%s': %w", definition.Name, name, err)
+		}
+	}
+	for i, message := range definition.Messages {
+		if message.Role != mcp.RoleUser && message.Role != mcp.RoleAssistant {
+			return nil, fmt.Errorf("prompt '%s': message %d has role '%s', want user or assistant", definition.Name, i+1, message.Role)
+		}
+		if _, err := templates.New(messageTemplateName(i)).Parse(message.Text); err != nil {
+			return nil, fmt.Errorf("prompt '%s': invalid message %d: %w", definition.Name, i+1, err)
+		}
+		for j, uri := range message.Resources {
+			if _, err := templates.New(resourceTemplateName(i, j)).Parse(uri); err != nil {
+				return nil, fmt.Errorf("prompt '%s': invalid resource %d of message %d: %w", definition.Name, j+1, i+1, err)
+			}
+		}
+	}
+
+	return &TemplatePrompt{definition: definition, templates: templates}, nil
+}
+
+// messageTemplateName names the template of the i-th message
+func messageTemplateName(i int) string {
+	return fmt.Sprintf("message %d", i+1)
+}
+
+// resourceTemplateName names the template of the j-th resource URI of the
+// i-th message
+func resourceTemplateName(i, j int) string {
+	return fmt.Sprintf("message %d resource %d", i+1, j+1)
+}
+
+// render executes the named template with data, trimming the result
+func (p *TemplatePrompt) render(templates *template.Template, name string, data map[string]interface{}) (string, error) {
+	var text bytes.Buffer
+	if err := templates.ExecuteTemplate(&text, name, data); err != nil {
+		return "", fmt.Errorf("failed to render prompt '%s': %w", p.definition.Name, err)
+	}
+	return strings.TrimSpace(text.String()), nil
+}
+
+// Definition returns the prompt definition
+func (p *TemplatePrompt) Definition() *mcp.Prompt {
+	arguments := make([]mcp.PromptArgument, len(p.definition.Arguments))
+	for i, arg := range p.definition.Arguments {
+		arguments[i] = mcp.PromptArgument{Name: arg.Name, Description: arg.Description, Required: arg.Required, Default: arg.Default, Values: arg.Values}
+	}
+	return &mcp.Prompt{
+		Name:        p.definition.Name,
+		Description: p.definition.Description,
+		Arguments:   arguments,
+	}
+}
+
+// Generate renders the messages for the given arguments. Messages that
+// render to nothing, for example because of a false condition, are left
+// out. Resources are returned as references that GetPrompt embeds.
+func (p *TemplatePrompt) Generate(ctx context.Context, params map[string]interface{}) (*mcp.GetPromptResult, error) {
+	data := make(map[string]interface{}, len(params)+len(p.definition.Arguments))
+	for key, value := range params {
+		data[key] = value
+	}
+	for _, arg := range p.definition.Arguments {
+		value, given := data[arg.Name]
+		if !given || value == nil || value == "" {
+			if arg.Required {
+				return nil, mcp.InvalidParamsError(arg.Name, "is required")
+			}
+			data[arg.Name] = arg.Default
+		}
+	}
+
+	templates, err := p.templates.Clone()
+	if err != nil {
+		return nil, fmt.Errorf("failed to render prompt '%s': %w", p.definition.Name, err)
+	}
+	templates.Funcs(p.composeFuncs(ctx))
+
+	result := mcp.NewPromptResult(p.definition.Description)
+	for i, message := range p.definition.Messages {
+		text, err := p.render(templates, messageTemplateName(i), data)
+		if err != nil {
+			return nil, err
+		}
+		var content []mcp.Content
+		if text != "" {
+			content = append(content, mcp.NewTextContent(text))
+		}
+		for j := range message.Resources {
+			uri, err := p.render(templates, resourceTemplateName(i, j), data)
+			if err != nil {
+				return nil, err
+			}
+			if uri != "" {
+				content = append(content, mcp.NewResourceReference(uri, ""))
+			}
+		}
+		if len(content) > 0 {
+			result.Messages = append(result.Messages, mcp.NewPromptMessage(message.Role, content...))
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,109 @@
+package prompts
+
+import (
+	"context"
+	"testing"
+
+	"github.com/chongliujia/mcp-go-template/pkg/mcp"
+)
+
+func TestTemplatePrompt_Generate(t *testing.T) {
+	prompt, err := NewTemplatePrompt(TemplateDefinition{
+		Name: "code_review",
+		Arguments: []TemplateArgument{
+			{Name: "language", Required: true},
+			{Name: "focus", Default: "correctness", Values: []string{"correctness", "performance", "security"}},
+			{Name: "strict"},
+			{Name: "extra"},
+		},
+		Messages: []MessageTemplate{
+			{Role: "user", Text: `Review this {{upper .language}} change for {{.focus}}.{{if .strict}} Block on any issue.{{end}}
+{{template "checklist" .}}`},
+			{Role: "assistant", Text: `{{if .strict}}I will be strict.{{end}}`},
+		},
+		Partials: map[string]string{
+			"checklist": `Check tests and docs{{with .extra}} and {{.}}{{end}}.`,
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+	ctx := context.Background()
+
+	result, err := prompt.Generate(ctx, map[string]interface{}{"language": "go", "extra": "naming"})
+	if err != nil {
+		t.Fatalf("Failed to generate: %v", err)
+	}
+	if len(result.Messages) != 1 {
+		t.Fatalf("Expected the empty assistant message to be dropped, got %+v", result.Messages)
+	}
+	if text := result.Messages[0].Content[0].Text; text != "Review this GO change for correctness.\nCheck tests and docs and naming." {
+		t.Errorf("Unexpected text: %q", text)
+	}
+
+	result, err = prompt.Generate(ctx, map[string]interface{}{"language": "go", "focus": "security", "strict": true})
+	if err != nil {
+		t.Fatalf("Failed to generate: %v", err)
+	}
+	if len(result.Messages) != 2 || result.Messages[0].Content[0].Text != "Review this GO change for security. Block on any issue.\nCheck tests and docs." {
+		t.Errorf("Unexpected messages: %+v", result.Messages)
+	}
+
+	if _, err := prompt.Generate(ctx, map[string]interface{}{}); err == nil {
+		t.Error("Expected a missing required argument to fail")
+	} else if info, ok := mcp.AsErrorInfo(err); !ok || info.Code != mcp.InvalidParams {
+		t.Errorf("Expected invalid params, got %v", err)
+	}
+
+	if values := mcp.PromptArgumentValues(prompt.Definition(), "focus"); len(values) != 3 || values[2] != "security" {
+		t.Errorf("Expected the focus values to be declared, got %v", values)
+	}
+}
+
+func TestTemplatePrompt_RejectsInvalidTemplates(t *testing.T) {
+	tests := []TemplateDefinition{
+		{Messages: []MessageTemplate{{Role: "user", Text: "Hi"}}},
+		{Name: "empty"},
+		{Name: "role", Messages: []MessageTemplate{{Role: "system", Text: "Hi"}}},
+		{Name: "syntax", Messages: []MessageTemplate{{Role: "user", Text: "{{if .x}}"}}},
+		{Name: "func", Messages: []MessageTemplate{{Role: "user", Text: "{{list .x}}"}}},
+		{Name: "partial", Messages: []MessageTemplate{{Role: "user", Text: "Hi"}}, Partials: map[string]string{"p": "{{end}}"}},
+	}
+	for _, definition := range tests {
+		if _, err := NewTemplatePrompt(definition); err == nil {
+			t.Errorf("Expected %+v to be rejected", definition)
+		}
+	}
+
+	prompt, err := NewTemplatePrompt(TemplateDefinition{Name: "typo", Messages: []MessageTemplate{{Role: "user", Text: "{{.topc}}"}}})
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+	if _, err := prompt.Generate(context.Background(), nil); err == nil {
+		t.Error("Expected an undeclared field to fail rendering")
+	}
+}
+
+func TestTemplatePrompt_ReferencesResources(t *testing.T) {
+	prompt, err := NewTemplatePrompt(TemplateDefinition{
+		Name:      "explain",
+		Arguments: []TemplateArgument{{Name: "file", Required: true}, {Name: "diff"}},
+		Messages: []MessageTemplate{{
+			Role:      "user",
+			Text:      "Explain {{.file}}",
+			Resources: []string{"file:///src/{{.file}}", "{{with .diff}}git://diff/{{.}}{{end}}"},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+
+	result, err := prompt.Generate(context.Background(), map[string]interface{}{"file": "main.go"})
+	if err != nil {
+		t.Fatalf("Failed to generate: %v", err)
+	}
+	content := result.Messages[0].Content
+	if len(content) != 2 || content[1].Type != mcp.ContentTypeResource || content[1].Resource.URI != "file:///src/main.go" {
+		t.Errorf("Expected the text and one resource reference, got %+v", content)
+	}
+}
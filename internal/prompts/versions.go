@@ -0,0 +1,307 @@
+package prompts
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/chongliujia/mcp-go-template/pkg/mcp"
+)
+
+// VersionSeparator separates a prompt's base name from its version,
+// as in "research_prompt:v2"
+const VersionSeparator = ":"
+
+// VersionArgument is the prompts/get argument that selects a variant
+const VersionArgument = "version"
+
+// SplitVersion splits a prompt name into its base name and version. Names
+// without a version return an empty version.
+func SplitVersion(name string) (string, string) {
+	if idx := strings.LastIndex(name, VersionSeparator); idx > 0 {
+		return name[:idx], name[idx+1:]
+	}
+	return name, ""
+}
+
+// SetDefaultVersion selects the variant served when a prompt is requested
+// without a version
+func (r *Registry) SetDefaultVersion(base, version string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.prompts[base+VersionSeparator+version]; !exists {
+		return fmt.Errorf("prompt '%s' has no version '%s'", base, version)
+	}
+	r.defaults[base] = version
+	return nil
+}
+
+// SetVariantWeights splits requests made without a version between the
+// variants of a prompt, in proportion to their weights. A session always
+// gets the same variant. Weights replace the default version; an empty map
+// removes them.
+func (r *Registry) SetVariantWeights(base string, weights map[string]int) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	total := 0
+	for version, weight := range weights {
+		if _, exists := r.prompts[base+VersionSeparator+version]; !exists {
+			return fmt.Errorf("prompt '%s' has no version '%s'", base, version)
+		}
+		if weight < 0 {
+			return fmt.Errorf("prompt '%s' version '%s' has a negative weight", base, version)
+		}
+		total += weight
+	}
+	if len(weights) == 0 {
+		delete(r.weights, base)
+		return nil
+	}
+	if total == 0 {
+		return fmt.Errorf("prompt '%s' variant weights add up to zero", base)
+	}
+
+	r.weights[base] = make(map[string]int, len(weights))
+	for version, weight := range weights {
+		r.weights[base][version] = weight
+	}
+	return nil
+}
+
+// pickVariant chooses a weighted variant of base for the session in ctx,
+// or a random one without a session. It returns "" when base has no weights.
+func (r *Registry) pickVariant(ctx context.Context, base string) string {
+	r.mutex.RLock()
+	weights := r.weights[base]
+	versions := make([]string, 0, len(weights))
+	total := 0
+	for version, weight := range weights {
+		if _, exists := r.prompts[base+VersionSeparator+version]; exists && weight > 0 {
+			versions = append(versions, version)
+			total += weight
+		}
+	}
+	r.mutex.RUnlock()
+	if total == 0 {
+		return ""
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return compareVersions(versions[i], versions[j]) < 0
+	})
+
+	var n int
+	if session, ok := mcp.SessionFromContext(ctx); ok {
+		hash := fnv.New32a()
+		hash.Write([]byte(base + "\x00" + session.ID()))
+		n = int(hash.Sum32() % uint32(total))
+	} else {
+		n = rand.Intn(total)
+	}
+	for _, version := range versions {
+		if n < weights[version] {
+			return version
+		}
+		n -= weights[version]
+	}
+	return versions[len(versions)-1]
+}
+
+// Versions returns the registered versions of a prompt, oldest first
+func (r *Registry) Versions(base string) []string {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.versionsLocked(base)
+}
+
+// versionsLocked returns the sorted versions of base; the caller holds the lock
+func (r *Registry) versionsLocked(base string) []string {
+	var versions []string
+	for name := range r.prompts {
+		if b, v := SplitVersion(name); b == base && v != "" {
+			versions = append(versions, v)
+		}
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return compareVersions(versions[i], versions[j]) < 0
+	})
+	return versions
+}
+
+// Resolve finds the handler for a prompt. An explicit version, either in
+// name ("research_prompt:v1") or in version, selects that variant;
+// otherwise the configured default is used, falling back to an unversioned
+// registration and then to the newest version.
+func (r *Registry) Resolve(name, version string) (mcp.PromptHandler, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	base, nameVersion := SplitVersion(name)
+	if nameVersion != "" {
+		version = nameVersion
+	}
+
+	if version != "" {
+		handler, exists := r.prompts[base+VersionSeparator+version]
+		if !exists {
+			return nil, mcp.InvalidParamsError(VersionArgument, fmt.Sprintf("prompt '%s' has no version '%s'", base, version))
+		}
+		return handler, nil
+	}
+
+	if def, ok := r.defaults[base]; ok {
+		if handler, exists := r.prompts[base+VersionSeparator+def]; exists {
+			return handler, nil
+		}
+	}
+
+	if handler, exists := r.prompts[base]; exists {
+		return handler, nil
+	}
+
+	versions := r.versionsLocked(base)
+	if len(versions) == 0 {
+		return nil, mcp.PromptNotFoundError(base)
+	}
+	return r.prompts[base+VersionSeparator+versions[len(versions)-1]], nil
+}
+
+// Handlers returns one handler per prompt base name. Each serves the
+// default variant and accepts a "version" argument to select another.
+func (r *Registry) Handlers() []mcp.PromptHandler {
+	r.mutex.RLock()
+	bases := make(map[string]bool)
+	for name := range r.prompts {
+		base, _ := SplitVersion(name)
+		bases[base] = true
+	}
+	r.mutex.RUnlock()
+
+	names := make([]string, 0, len(bases))
+	for base := range bases {
+		names = append(names, base)
+	}
+	sort.Strings(names)
+
+	handlers := make([]mcp.PromptHandler, 0, len(names))
+	for _, base := range names {
+		handlers = append(handlers, &versionedPrompt{registry: r, base: base})
+	}
+	return handlers
+}
+
+// versionedPrompt exposes all variants of a prompt under its base name
+type versionedPrompt struct {
+	registry *Registry
+	base     string
+}
+
+// Definition returns the default variant's definition under the base name,
+// with an optional argument for selecting a version
+func (p *versionedPrompt) Definition() *mcp.Prompt {
+	handler, err := p.registry.Resolve(p.base, "")
+	if err != nil {
+		return &mcp.Prompt{Name: p.base}
+	}
+
+	def := *handler.Definition()
+	def.Name = p.base
+
+	versions := p.registry.Versions(p.base)
+	if len(versions) > 0 {
+		def.Arguments = append(append([]mcp.PromptArgument{}, def.Arguments...), mcp.PromptArgument{
+			Name:        VersionArgument,
+			Description: fmt.Sprintf("Prompt version to use (%s)", strings.Join(versions, ", ")),
+		})
+	}
+	return &def
+}
+
+// Generate renders the variant selected by the version argument or, when
+// none is given, by the variant weights, logging which one was served
+func (p *versionedPrompt) Generate(ctx context.Context, params map[string]interface{}) (*mcp.GetPromptResult, error) {
+	version, _ := params[VersionArgument].(string)
+	selectedBy := "argument"
+	if version == "" {
+		version = p.registry.pickVariant(ctx, p.base)
+		selectedBy = "weight"
+	}
+	if version == "" {
+		selectedBy = "default"
+	}
+
+	handler, err := p.registry.Resolve(p.base, version)
+	if err != nil {
+		return nil, err
+	}
+
+	// Arguments are checked against the selected variant's declarations
+	args := make(map[string]interface{}, len(params))
+	for key, value := range params {
+		if key != VersionArgument {
+			args[key] = value
+		}
+	}
+	args, err = mcp.ValidatePromptArguments(args, handler.Definition().Arguments)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := logrus.Fields{
+		"prompt":      p.base,
+		"variant":     handler.Definition().Name,
+		"selected_by": selectedBy,
+	}
+	if session, ok := mcp.SessionFromContext(ctx); ok {
+		fields["session_id"] = session.ID()
+	}
+	p.registry.logger.WithFields(fields).Info("Served prompt variant")
+
+	return handler.Generate(ctx, args)
+}
+
+// ValidatesArguments reports that Generate checks the arguments against
+// the variant it selects rather than the default one
+func (p *versionedPrompt) ValidatesArguments() bool {
+	return true
+}
+
+// Complete suggests versions for the version argument and otherwise defers
+// to the variant selected by the version already filled in, using the
+// values it declares when it has no completion of its own
+func (p *versionedPrompt) Complete(ctx context.Context, params *mcp.CompleteParams) (*mcp.Completion, error) {
+	if params.Argument.Name == VersionArgument {
+		return mcp.CompleteValues(p.registry.Versions(p.base), params.Argument.Value), nil
+	}
+
+	version := ""
+	if params.Context != nil {
+		version = params.Context.Arguments[VersionArgument]
+	}
+	handler, err := p.registry.Resolve(p.base, version)
+	if err != nil {
+		return nil, err
+	}
+	if provider, ok := handler.(mcp.CompletionProvider); ok {
+		return provider.Complete(ctx, params)
+	}
+	return mcp.CompleteValues(mcp.PromptArgumentValues(handler.Definition(), params.Argument.Name), params.Argument.Value), nil
+}
+
+// compareVersions orders versions such as "v2" and "v10" numerically,
+// falling back to string comparison
+func compareVersions(a, b string) int {
+	na, errA := strconv.Atoi(strings.TrimPrefix(a, "v"))
+	nb, errB := strconv.Atoi(strings.TrimPrefix(b, "v"))
+	if errA == nil && errB == nil {
+		return na - nb
+	}
+	return strings.Compare(a, b)
+}
@@ -0,0 +1,176 @@
+package prompts
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/chongliujia/mcp-go-template/internal/prompts/examples"
+	"github.com/chongliujia/mcp-go-template/pkg/mcp"
+)
+
+func TestRegistry_ResolveVersions(t *testing.T) {
+	registry := NewRegistry()
+	for _, version := range []string{"v1", "v2", "v10"} {
+		if err := registry.Register(examples.NewResearchPrompt(version)); err != nil {
+			t.Fatalf("Register failed: %v", err)
+		}
+	}
+
+	if got := strings.Join(registry.Versions("research_prompt"), ","); got != "v1,v2,v10" {
+		t.Errorf("Versions() = %s, want v1,v2,v10", got)
+	}
+
+	tests := []struct {
+		name     string
+		version  string
+		expected string
+	}{
+		{"research_prompt", "", "research_prompt:v10"},
+		{"research_prompt", "v1", "research_prompt:v1"},
+		{"research_prompt:v2", "", "research_prompt:v2"},
+	}
+	for _, tt := range tests {
+		handler, err := registry.Resolve(tt.name, tt.version)
+		if err != nil {
+			t.Fatalf("Resolve(%s, %s) failed: %v", tt.name, tt.version, err)
+		}
+		if got := handler.Definition().Name; got != tt.expected {
+			t.Errorf("Resolve(%s, %s) = %s, want %s", tt.name, tt.version, got, tt.expected)
+		}
+	}
+
+	if err := registry.SetDefaultVersion("research_prompt", "v1"); err != nil {
+		t.Fatalf("SetDefaultVersion failed: %v", err)
+	}
+	if handler, _ := registry.Resolve("research_prompt", ""); handler.Definition().Name != "research_prompt:v1" {
+		t.Errorf("Expected configured default v1, got %s", handler.Definition().Name)
+	}
+
+	if err := registry.SetDefaultVersion("research_prompt", "v3"); err == nil {
+		t.Error("Expected error for unknown default version")
+	}
+	if _, err := registry.Resolve("research_prompt", "v3"); err == nil {
+		t.Error("Expected error for unknown version")
+	}
+}
+
+func TestRegistry_Handlers(t *testing.T) {
+	registry := NewRegistry()
+	if err := registry.RegisterDefaultPrompts(); err != nil {
+		t.Fatalf("RegisterDefaultPrompts failed: %v", err)
+	}
+	registry.SetDefaultVersion("research_prompt", "v1")
+
+	handlers := registry.Handlers()
+	if len(handlers) != 1 {
+		t.Fatalf("Expected 1 handler, got %d", len(handlers))
+	}
+
+	def := handlers[0].Definition()
+	if def.Name != "research_prompt" {
+		t.Errorf("Expected base name research_prompt, got %s", def.Name)
+	}
+	if last := def.Arguments[len(def.Arguments)-1]; last.Name != VersionArgument {
+		t.Errorf("Expected trailing version argument, got %s", last.Name)
+	}
+
+	result, err := handlers[0].Generate(context.Background(), map[string]interface{}{
+		"topic":   "solar power",
+		"version": "v2",
+	})
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if !strings.Contains(result.Messages[0].Content[0].Text, "web_search") {
+		t.Errorf("Expected v2 wording, got %s", result.Messages[0].Content[0].Text)
+	}
+}
+
+func TestRegistry_VariantWeights(t *testing.T) {
+	registry := NewRegistry()
+	if err := registry.RegisterDefaultPrompts(); err != nil {
+		t.Fatalf("RegisterDefaultPrompts failed: %v", err)
+	}
+
+	if err := registry.SetVariantWeights("research_prompt", map[string]int{"v3": 1}); err == nil {
+		t.Error("Expected an unknown version to be rejected")
+	}
+	if err := registry.SetVariantWeights("research_prompt", map[string]int{"v1": 0}); err == nil {
+		t.Error("Expected weights adding up to zero to be rejected")
+	}
+
+	if err := registry.SetVariantWeights("research_prompt", map[string]int{"v1": 1, "v2": 1}); err != nil {
+		t.Fatalf("SetVariantWeights failed: %v", err)
+	}
+	served := make(map[string]int)
+	for i := 0; i < 200; i++ {
+		served[registry.pickVariant(context.Background(), "research_prompt")]++
+	}
+	if served["v1"] == 0 || served["v2"] == 0 || len(served) != 2 {
+		t.Errorf("Expected both variants to be served, got %v", served)
+	}
+
+	// A session keeps its variant
+	session := mcp.NewSession("session-1")
+	ctx := mcp.WithSession(context.Background(), session)
+	first := registry.pickVariant(ctx, "research_prompt")
+	for i := 0; i < 20; i++ {
+		if variant := registry.pickVariant(ctx, "research_prompt"); variant != first {
+			t.Fatalf("Expected session to keep variant %s, got %s", first, variant)
+		}
+	}
+
+	// An explicit version overrides the weights
+	handler := registry.Handlers()[0]
+	result, err := handler.Generate(ctx, map[string]interface{}{"topic": "solar", "version": "v1"})
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if strings.Contains(result.Messages[0].Content[0].Text, "web_search") {
+		t.Errorf("Expected v1 wording, got %s", result.Messages[0].Content[0].Text)
+	}
+
+	registry.SetVariantWeights("research_prompt", nil)
+	if variant := registry.pickVariant(ctx, "research_prompt"); variant != "" {
+		t.Errorf("Expected removed weights to pick no variant, got %s", variant)
+	}
+}
+
+func TestRegistry_ValidatesSelectedVariant(t *testing.T) {
+	registry := NewRegistry()
+	for version, argument := range map[string]string{"v1": "topic", "v2": "audience"} {
+		prompt, err := NewTemplatePrompt(TemplateDefinition{
+			Name:      "p:" + version,
+			Arguments: []TemplateArgument{{Name: argument, Required: true}},
+			Messages:  []MessageTemplate{{Role: "user", Text: "Explain it to {{." + argument + "}}"}},
+		})
+		if err != nil {
+			t.Fatalf("Failed to parse: %v", err)
+		}
+		registry.Register(prompt)
+	}
+	registry.SetDefaultVersion("p", "v1")
+
+	h := mcp.NewBaseHandler(mcp.ServerInfo{Name: "test", Version: "1.0.0"}, mcp.ServerCapabilities{})
+	h.RegisterPrompt(registry.Handlers()[0])
+	ctx := context.Background()
+	h.HandleMessage(ctx, mcp.NewNotification("initialized", nil))
+
+	result, err := h.GetPrompt(ctx, &mcp.GetPromptParams{Name: "p", Arguments: map[string]interface{}{"version": "v2", "audience": "kids"}})
+	if err != nil {
+		t.Fatalf("Expected v2's own arguments to be accepted, got %v", err)
+	}
+	if text := result.Messages[0].Content[0].Text; text != "Explain it to kids" {
+		t.Errorf("Unexpected message: %q", text)
+	}
+	if _, err := h.GetPrompt(ctx, &mcp.GetPromptParams{Name: "p", Arguments: map[string]interface{}{"version": "v2", "topic": "go"}}); err == nil {
+		t.Error("Expected v2 to require its own arguments")
+	}
+
+	// Variants picked by weight are checked the same way
+	registry.SetVariantWeights("p", map[string]int{"v2": 1})
+	if _, err := h.GetPrompt(ctx, &mcp.GetPromptParams{Name: "p", Arguments: map[string]interface{}{"audience": "kids"}}); err != nil {
+		t.Errorf("Expected the weighted variant's arguments to be accepted, got %v", err)
+	}
+}
@@ -0,0 +1,216 @@
+package resources
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/chongliujia/mcp-go-template/pkg/mcp"
+)
+
+// Revalidator is implemented by resources that can tell whether their
+// content changed since a version they returned, as HTTP resources do with
+// ETags. ReadChanged returns a nil result when the content still has
+// version etag; otherwise it reads the content and returns its version,
+// which may be empty.
+type Revalidator interface {
+	ReadChanged(ctx context.Context, uri, etag string) (*mcp.ReadResourceResult, string, error)
+}
+
+// Cache keeps the contents read through the resources it wraps, so
+// repeated reads of a URI are served without reaching the provider. Each
+// wrapped provider has its own TTL. When an entry expires, a Revalidator
+// is asked whether its content changed before it is read in full.
+type Cache struct {
+	maxEntries int
+
+	mu      sync.Mutex
+	order   *list.List // most recently used first
+	entries map[string]*list.Element
+}
+
+// resourceCacheEntry is the cached content of one URI
+type resourceCacheEntry struct {
+	uri     string
+	result  *mcp.ReadResourceResult
+	etag    string
+	expires time.Time
+}
+
+// NewCache creates a cache holding at most maxEntries URIs, evicting the
+// least recently read first; 0 or less means no bound
+func NewCache(maxEntries int) *Cache {
+	return &Cache{
+		maxEntries: maxEntries,
+		order:      list.New(),
+		entries:    make(map[string]*list.Element),
+	}
+}
+
+// Wrap returns handler with its contents cached for ttl. A ttl of 0 or
+// less returns handler unchanged.
+func (c *Cache) Wrap(handler mcp.ResourceHandler, ttl time.Duration) mcp.ResourceHandler {
+	if ttl <= 0 {
+		return handler
+	}
+	return &cachedResource{handler: handler, cache: c, ttl: ttl}
+}
+
+// WrapTemplate returns handler with the contents of the URIs it matches
+// cached for ttl. A ttl of 0 or less returns handler unchanged.
+func (c *Cache) WrapTemplate(handler mcp.ResourceTemplateHandler, ttl time.Duration) mcp.ResourceTemplateHandler {
+	if ttl <= 0 {
+		return handler
+	}
+	return &cachedTemplate{handler: handler, cache: c, ttl: ttl}
+}
+
+// Invalidate drops the cached content of uri, so the next read reaches the
+// provider
+func (c *Cache) Invalidate(uri string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, exists := c.entries[uri]; exists {
+		c.order.Remove(element)
+		delete(c.entries, uri)
+	}
+}
+
+// Len returns the number of cached URIs
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// read serves uri from the cache while it is fresh. Otherwise it asks
+// revalidator, when there is one, or calls read, caching successful
+// results for ttl.
+func (c *Cache) read(ctx context.Context, uri string, ttl time.Duration, revalidator Revalidator, read func() (*mcp.ReadResourceResult, error)) (*mcp.ReadResourceResult, error) {
+	cached, etag, fresh := c.lookup(uri)
+	if fresh {
+		return cached, nil
+	}
+
+	var result *mcp.ReadResourceResult
+	var err error
+	if revalidator != nil {
+		if cached == nil {
+			etag = ""
+		}
+		result, etag, err = revalidator.ReadChanged(ctx, uri, etag)
+		if err == nil && result == nil {
+			if cached == nil {
+				return nil, fmt.Errorf("resource %s returned no content", uri)
+			}
+			result = cached
+		}
+	} else {
+		result, err = read()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	c.store(uri, result, etag, ttl)
+	return copyReadResult(result), nil
+}
+
+// lookup returns the cached content of uri and whether it is still fresh.
+// Expired content is returned with its ETag for revalidation.
+func (c *Cache) lookup(uri string) (*mcp.ReadResourceResult, string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, exists := c.entries[uri]
+	if !exists {
+		return nil, "", false
+	}
+	c.order.MoveToFront(element)
+	entry := element.Value.(*resourceCacheEntry)
+	if time.Now().After(entry.expires) {
+		return entry.result, entry.etag, false
+	}
+	return copyReadResult(entry.result), entry.etag, true
+}
+
+// store caches result for uri, evicting the least recently read URI when
+// the cache is full
+func (c *Cache) store(uri string, result *mcp.ReadResourceResult, etag string, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := &resourceCacheEntry{uri: uri, result: copyReadResult(result), etag: etag, expires: time.Now().Add(ttl)}
+	if element, exists := c.entries[uri]; exists {
+		element.Value = entry
+		c.order.MoveToFront(element)
+		return
+	}
+	c.entries[uri] = c.order.PushFront(entry)
+
+	if c.maxEntries > 0 && c.order.Len() > c.maxEntries {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*resourceCacheEntry).uri)
+	}
+}
+
+// copyReadResult copies result, so callers cannot change cached contents
+func copyReadResult(result *mcp.ReadResourceResult) *mcp.ReadResourceResult {
+	copied := *result
+	copied.Contents = append([]mcp.ResourceContents(nil), result.Contents...)
+	return &copied
+}
+
+// cachedResource is a resource read through the cache
+type cachedResource struct {
+	handler mcp.ResourceHandler
+	cache   *Cache
+	ttl     time.Duration
+}
+
+// Definition returns the wrapped resource's definition
+func (r *cachedResource) Definition() *mcp.Resource {
+	return r.handler.Definition()
+}
+
+// Read serves the resource from the cache
+func (r *cachedResource) Read(ctx context.Context, uri string) (*mcp.ReadResourceResult, error) {
+	revalidator, _ := r.handler.(Revalidator)
+	return r.cache.read(ctx, uri, r.ttl, revalidator, func() (*mcp.ReadResourceResult, error) {
+		return r.handler.Read(ctx, uri)
+	})
+}
+
+// OnUpdate passes updates of the wrapped resource on, dropping its cached
+// content first
+func (r *cachedResource) OnUpdate(update func(uri string)) {
+	if updater, ok := r.handler.(mcp.ResourceUpdater); ok {
+		updater.OnUpdate(func(uri string) {
+			r.cache.Invalidate(uri)
+			update(uri)
+		})
+	}
+}
+
+// cachedTemplate is a resource template read through the cache
+type cachedTemplate struct {
+	handler mcp.ResourceTemplateHandler
+	cache   *Cache
+	ttl     time.Duration
+}
+
+// Definition returns the wrapped template's definition
+func (t *cachedTemplate) Definition() *mcp.ResourceTemplate {
+	return t.handler.Definition()
+}
+
+// Read serves the URI from the cache
+func (t *cachedTemplate) Read(ctx context.Context, uri string, vars map[string]string) (*mcp.ReadResourceResult, error) {
+	return t.cache.read(ctx, uri, t.ttl, nil, func() (*mcp.ReadResourceResult, error) {
+		return t.handler.Read(ctx, uri, vars)
+	})
+}
@@ -0,0 +1,155 @@
+package resources
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/chongliujia/mcp-go-template/internal/resources/examples"
+	"github.com/chongliujia/mcp-go-template/pkg/mcp"
+)
+
+// countingResource counts its reads and returns their number as its text
+type countingResource struct {
+	uri   string
+	reads int32
+}
+
+func (r *countingResource) Definition() *mcp.Resource {
+	return &mcp.Resource{URI: r.uri, Name: r.uri}
+}
+
+func (r *countingResource) Read(ctx context.Context, uri string) (*mcp.ReadResourceResult, error) {
+	reads := atomic.AddInt32(&r.reads, 1)
+	return &mcp.ReadResourceResult{
+		Contents: []mcp.ResourceContents{mcp.NewTextResourceContents(uri, "text/plain", string(rune('0'+reads)))},
+	}, nil
+}
+
+func readText(t *testing.T, handler mcp.ResourceHandler) string {
+	t.Helper()
+	result, err := handler.Read(context.Background(), handler.Definition().URI)
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", handler.Definition().URI, err)
+	}
+	return result.Contents[0].Text
+}
+
+func TestCache_ServesWithinTTL(t *testing.T) {
+	cache := NewCache(0)
+	resource := &countingResource{uri: "test://counter"}
+	cached := cache.Wrap(resource, 50*time.Millisecond)
+
+	if first, second := readText(t, cached), readText(t, cached); first != "1" || second != "1" {
+		t.Errorf("Expected repeated reads to be cached, got %s then %s", first, second)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if text := readText(t, cached); text != "2" {
+		t.Errorf("Expected an expired entry to be read again, got %s", text)
+	}
+
+	cache.Invalidate("test://counter")
+	if text := readText(t, cached); text != "3" {
+		t.Errorf("Expected an invalidated entry to be read again, got %s", text)
+	}
+
+	if unwrapped := cache.Wrap(resource, 0); unwrapped != mcp.ResourceHandler(resource) {
+		t.Error("Expected a zero TTL to leave the resource uncached")
+	}
+}
+
+func TestCache_EvictsLeastRecentlyRead(t *testing.T) {
+	cache := NewCache(2)
+	a := cache.Wrap(&countingResource{uri: "test://a"}, time.Minute)
+	b := cache.Wrap(&countingResource{uri: "test://b"}, time.Minute)
+	c := cache.Wrap(&countingResource{uri: "test://c"}, time.Minute)
+
+	readText(t, a)
+	readText(t, b)
+	readText(t, a)
+	readText(t, c)
+
+	if cache.Len() != 2 {
+		t.Fatalf("Expected 2 cached entries, got %d", cache.Len())
+	}
+	if text := readText(t, b); text != "2" {
+		t.Errorf("Expected the least recently read entry to be evicted, got %s", text)
+	}
+	if text := readText(t, c); text != "1" {
+		t.Errorf("Expected the newest entry to stay cached, got %s", text)
+	}
+}
+
+func TestCache_InvalidatesOnUpdate(t *testing.T) {
+	cache := NewCache(0)
+	notes := examples.NewMemoryResource("memory://notes", "Notes", "")
+	notes.Set("draft")
+
+	h := mcp.NewBaseHandler(mcp.ServerInfo{Name: "test", Version: "1.0.0"}, mcp.ServerCapabilities{
+		Resources: &mcp.ResourcesCapability{Subscribe: true},
+	})
+	var notified []string
+	h.SetNotifier(func(notification *mcp.Message) {
+		notified = append(notified, notification.Method)
+	})
+	cached := cache.Wrap(notes, time.Hour)
+	if err := h.RegisterResource(cached); err != nil {
+		t.Fatalf("Failed to register: %v", err)
+	}
+
+	if text := readText(t, cached); text != "draft" {
+		t.Fatalf("Expected the draft, got %s", text)
+	}
+	notes.Set("final")
+	if text := readText(t, cached); text != "final" {
+		t.Errorf("Expected an update to invalidate the cache, got %s", text)
+	}
+	if len(notified) != 1 || notified[0] != "notifications/resources/updated" {
+		t.Errorf("Expected the update to still notify subscribers, got %v", notified)
+	}
+}
+
+func TestCache_RevalidatesHTTPResourcesWithETag(t *testing.T) {
+	var fetches, revalidations int32
+	etag := `"v1"`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == etag {
+			atomic.AddInt32(&revalidations, 1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		atomic.AddInt32(&fetches, 1)
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+		w.Write([]byte("# Changelog"))
+	}))
+	defer server.Close()
+
+	cache := NewCache(0)
+	remote := NewHTTPResource(&mcp.Resource{URI: "docs://changelog", Name: "Changelog"}, server.URL, server.Client())
+	cached := cache.Wrap(remote, 20*time.Millisecond)
+
+	result, err := cached.Read(context.Background(), "docs://changelog")
+	if err != nil {
+		t.Fatalf("Failed to read: %v", err)
+	}
+	if result.Contents[0].Text != "# Changelog" || result.Contents[0].MimeType != "text/markdown" {
+		t.Errorf("Unexpected contents: %+v", result.Contents[0])
+	}
+	readText(t, cached)
+	if fetches != 1 || revalidations != 0 {
+		t.Errorf("Expected one fetch within the TTL, got %d fetches and %d revalidations", fetches, revalidations)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	if text := readText(t, cached); text != "# Changelog" {
+		t.Errorf("Expected the cached content after revalidation, got %s", text)
+	}
+	if fetches != 1 || revalidations != 1 {
+		t.Errorf("Expected an expired entry to be revalidated, got %d fetches and %d revalidations", fetches, revalidations)
+	}
+}
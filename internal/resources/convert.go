@@ -0,0 +1,242 @@
+package resources
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+
+	"github.com/chongliujia/mcp-go-template/internal/tools/examples"
+	"github.com/chongliujia/mcp-go-template/pkg/mcp"
+)
+
+var (
+	// spaces matches the whitespace HTML renders as a single space
+	spaces = regexp.MustCompile(`\s+`)
+	// blankLines matches the runs of empty lines left by nested blocks
+	blankLines = regexp.MustCompile(`[ \t]*\n(?:[ \t]*\n)+`)
+)
+
+// DefaultConversions returns the conversions offered to clients through
+// the accept parameter of resources/read: HTML as plain text or Markdown
+// and PDF as plain text, using the document analyzer's extraction
+func DefaultConversions() []mcp.ResourceConversion {
+	htmlText := func(ctx context.Context, contents mcp.ResourceContents) (mcp.ResourceContents, error) {
+		data, err := contents.Bytes()
+		if err != nil {
+			return mcp.ResourceContents{}, err
+		}
+		return mcp.ResourceContents{Text: examples.ExtractHTMLText(string(data))}, nil
+	}
+
+	return []mcp.ResourceConversion{
+		{From: "text/html", To: "text/plain", Convert: htmlText},
+		{From: "application/xhtml+xml", To: "text/plain", Convert: htmlText},
+		{From: "text/html", To: "text/markdown", Convert: func(ctx context.Context, contents mcp.ResourceContents) (mcp.ResourceContents, error) {
+			data, err := contents.Bytes()
+			if err != nil {
+				return mcp.ResourceContents{}, err
+			}
+			markdown, err := HTMLToMarkdown(string(data))
+			if err != nil {
+				return mcp.ResourceContents{}, err
+			}
+			return mcp.ResourceContents{Text: markdown}, nil
+		}},
+		{From: "application/pdf", To: "text/plain", Convert: func(ctx context.Context, contents mcp.ResourceContents) (mcp.ResourceContents, error) {
+			data, err := contents.Bytes()
+			if err != nil {
+				return mcp.ResourceContents{}, err
+			}
+			text, err := examples.ExtractPDFText(data)
+			if err != nil {
+				return mcp.ResourceContents{}, err
+			}
+			return mcp.ResourceContents{Text: text}, nil
+		}},
+	}
+}
+
+// HTMLToMarkdown renders the body of an HTML document as Markdown,
+// keeping headings, paragraphs, lists, links, emphasis, code and quotes
+func HTMLToMarkdown(document string) (string, error) {
+	root, err := html.Parse(strings.NewReader(document))
+	if err != nil {
+		return "", fmt.Errorf("failed to parse HTML: %w", err)
+	}
+
+	var md markdownWriter
+	md.node(root)
+	text := blankLines.ReplaceAllString(md.String(), "\n\n")
+	return strings.TrimSpace(text) + "\n", nil
+}
+
+// markdownWriter accumulates Markdown while walking an HTML tree
+type markdownWriter struct {
+	strings.Builder
+	lists []listState // enclosing lists, innermost last
+	pre   bool
+}
+
+// listState tracks an ordered or unordered list being written
+type listState struct {
+	ordered bool
+	items   int
+}
+
+// block starts a new paragraph
+func (w *markdownWriter) block() {
+	w.WriteString("\n\n")
+}
+
+// lineStart reports whether nothing or only whitespace precedes the
+// next character on its line
+func (w *markdownWriter) lineStart() bool {
+	current := w.String()
+	return current == "" || strings.HasSuffix(current, " ") || strings.HasSuffix(current, "\n")
+}
+
+// children writes the children of n
+func (w *markdownWriter) children(n *html.Node) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		w.node(c)
+	}
+}
+
+// inline writes the children of n wrapped in marker, dropping the marker
+// when they render to nothing
+func (w *markdownWriter) inline(n *html.Node, marker string) {
+	var inner markdownWriter
+	inner.lists = w.lists
+	inner.children(n)
+	text := strings.TrimSpace(inner.String())
+	if text == "" {
+		return
+	}
+	w.WriteString(marker + text + marker)
+}
+
+// node writes n and its descendants
+func (w *markdownWriter) node(n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		if w.pre {
+			w.WriteString(n.Data)
+			return
+		}
+		text := spaces.ReplaceAllString(n.Data, " ")
+		if strings.HasPrefix(text, " ") && w.lineStart() {
+			text = text[1:]
+		}
+		w.WriteString(text)
+		return
+	case html.DocumentNode:
+		w.children(n)
+		return
+	case html.ElementNode:
+	default:
+		return
+	}
+
+	switch n.Data {
+	case "script", "style", "noscript", "head", "template":
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		w.block()
+		w.WriteString(strings.Repeat("#", int(n.Data[1]-'0')) + " ")
+		w.inline(n, "")
+		w.block()
+	case "p", "div", "section", "article", "header", "footer", "main", "nav", "aside", "figure", "table", "tr":
+		w.block()
+		w.children(n)
+		w.block()
+	case "br":
+		w.WriteString("  \n")
+	case "hr":
+		w.block()
+		w.WriteString("---")
+		w.block()
+	case "strong", "b":
+		w.inline(n, "**")
+	case "em", "i":
+		w.inline(n, "*")
+	case "code":
+		if w.pre {
+			w.children(n)
+		} else {
+			w.inline(n, "`")
+		}
+	case "pre":
+		w.block()
+		w.WriteString("```\n")
+		w.pre = true
+		w.children(n)
+		w.pre = false
+		if !strings.HasSuffix(w.String(), "\n") {
+			w.WriteString("\n")
+		}
+		w.WriteString("```")
+		w.block()
+	case "a":
+		var inner markdownWriter
+		inner.children(n)
+		text := strings.TrimSpace(inner.String())
+		href := attr(n, "href")
+		if href == "" || text == "" {
+			w.WriteString(text)
+			return
+		}
+		w.WriteString("[" + text + "](" + href + ")")
+	case "img":
+		if src := attr(n, "src"); src != "" {
+			w.WriteString("![" + attr(n, "alt") + "](" + src + ")")
+		}
+	case "ul", "ol":
+		if len(w.lists) == 0 {
+			w.block()
+		}
+		w.lists = append(w.lists, listState{ordered: n.Data == "ol"})
+		w.children(n)
+		w.lists = w.lists[:len(w.lists)-1]
+		if len(w.lists) == 0 {
+			w.block()
+		}
+	case "li":
+		marker := "- "
+		if len(w.lists) > 0 {
+			list := &w.lists[len(w.lists)-1]
+			list.items++
+			if list.ordered {
+				marker = fmt.Sprintf("%d. ", list.items)
+			}
+		}
+		w.WriteString("\n" + strings.Repeat("  ", max(len(w.lists)-1, 0)) + marker)
+		var inner markdownWriter
+		inner.lists = w.lists
+		inner.children(n)
+		w.WriteString(strings.TrimSpace(inner.String()))
+	case "blockquote":
+		var inner markdownWriter
+		inner.children(n)
+		text := strings.TrimSpace(blankLines.ReplaceAllString(inner.String(), "\n\n"))
+		w.block()
+		w.WriteString("> " + strings.ReplaceAll(text, "\n", "\n> "))
+		w.block()
+	case "td", "th":
+		w.children(n)
+		w.WriteString(" ")
+	default:
+		w.children(n)
+	}
+}
+
+// attr returns the value of an attribute of n
+func attr(n *html.Node, name string) string {
+	for _, a := range n.Attr {
+		if a.Key == name {
+			return a.Val
+		}
+	}
+	return ""
+}
@@ -0,0 +1,98 @@
+package resources
+
+import (
+	"bytes"
+	"compress/zlib"
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/chongliujia/mcp-go-template/pkg/mcp"
+)
+
+// staticResource serves fixed contents
+type staticResource struct {
+	contents mcp.ResourceContents
+}
+
+func (r staticResource) Definition() *mcp.Resource {
+	return &mcp.Resource{URI: r.contents.URI, Name: r.contents.URI, MimeType: r.contents.MimeType}
+}
+
+func (r staticResource) Read(ctx context.Context, uri string) (*mcp.ReadResourceResult, error) {
+	return &mcp.ReadResourceResult{Contents: []mcp.ResourceContents{r.contents}}, nil
+}
+
+// samplePDF builds a PDF with one plain and one Flate content stream
+func samplePDF() []byte {
+	var compressed bytes.Buffer
+	writer := zlib.NewWriter(&compressed)
+	writer.Write([]byte("BT /F1 12 Tf 72 700 Td [(Second) -250 (page)] TJ ET"))
+	writer.Close()
+
+	var pdf bytes.Buffer
+	pdf.WriteString("%PDF-1.4\n")
+	pdf.WriteString("1 0 obj << /Type /Catalog /Pages 2 0 R >> endobj\n")
+	plain := "BT /F1 12 Tf 72 720 Td (Quarterly \\(Q3\\) report) Tj 0 -14 Td <FEFF00520065007600690065007700650064> Tj ET"
+	fmt.Fprintf(&pdf, "4 0 obj << /Length %d >>\nstream\n%s\nendstream\nendobj\n", len(plain), plain)
+	fmt.Fprintf(&pdf, "5 0 obj << /Length %d /Filter /FlateDecode >>\nstream\n", compressed.Len())
+	pdf.Write(compressed.Bytes())
+	pdf.WriteString("\nendstream\nendobj\n")
+	pdf.WriteString("6 0 obj << /Type /XObject /Subtype /Image /Length 4 >>\nstream\n(no)\nendstream\nendobj\n%%EOF\n")
+	return pdf.Bytes()
+}
+
+func TestHTMLToMarkdown(t *testing.T) {
+	page := `<html><head><title>Ignored</title><style>p{}</style></head><body>
+<h1>Release  notes</h1>
+<p>Version <strong>2.0</strong> adds <a href="https://example.com/docs">new docs</a> and <code>--watch</code>.</p>
+<ul><li>Faster</li><li>Smaller<ol><li>Binary</li></ol></li></ul>
+<blockquote><p>Worth it.</p></blockquote>
+<pre>go build ./...</pre>
+<script>alert(1)</script>
+</body></html>`
+
+	markdown, err := HTMLToMarkdown(page)
+	if err != nil {
+		t.Fatalf("Failed to convert: %v", err)
+	}
+	expected := "# Release notes\n\n" +
+		"Version **2.0** adds [new docs](https://example.com/docs) and `--watch`.\n\n" +
+		"- Faster\n- Smaller\n  1. Binary\n\n" +
+		"> Worth it.\n\n" +
+		"```\ngo build ./...\n```\n"
+	if markdown != expected {
+		t.Errorf("Unexpected Markdown:\n%s\nwant:\n%s", markdown, expected)
+	}
+}
+
+func TestDefaultConversions(t *testing.T) {
+	h := mcp.NewBaseHandler(mcp.ServerInfo{Name: "test", Version: "1.0.0"}, mcp.ServerCapabilities{Resources: &mcp.ResourcesCapability{}})
+	h.RegisterResource(staticResource{mcp.NewTextResourceContents("https://example.com/", "text/html", "<p>Hello <em>there</em></p>")})
+	h.RegisterResource(staticResource{mcp.NewBlobResourceContents("file:///report.pdf", "application/pdf", samplePDF())})
+	for _, conversion := range DefaultConversions() {
+		h.RegisterResourceConversion(conversion)
+	}
+	ctx := context.Background()
+	h.HandleMessage(ctx, mcp.NewNotification("initialized", nil))
+
+	tests := []struct {
+		uri    string
+		accept string
+		text   string
+	}{
+		{"https://example.com/", "text/markdown", "Hello *there*\n"},
+		{"https://example.com/", "text/plain", "Hello there"},
+		{"file:///report.pdf", "text/plain", "Quarterly (Q3) report\nReviewed\nSecond page"},
+	}
+	for _, tt := range tests {
+		result, err := h.ReadResource(ctx, &mcp.ReadResourceParams{URI: tt.uri, Accept: tt.accept})
+		if err != nil {
+			t.Errorf("%s as %s: unexpected error %v", tt.uri, tt.accept, err)
+			continue
+		}
+		if contents := result.Contents[0]; contents.Text != tt.text || contents.MimeType != tt.accept || contents.Blob != "" {
+			t.Errorf("%s as %s: got %+v", tt.uri, tt.accept, contents)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package examples
+
+import (
+	"context"
+	"sync"
+
+	"github.com/chongliujia/mcp-go-template/pkg/mcp"
+)
+
+// MemoryResource is a text resource kept in memory. Set replaces its text
+// and notifies the clients subscribed to it.
+type MemoryResource struct {
+	definition *mcp.Resource
+
+	mu     sync.RWMutex
+	text   string
+	update func(uri string)
+}
+
+// NewMemoryResource creates an empty in-memory text resource
+func NewMemoryResource(uri, name, description string) *MemoryResource {
+	return &MemoryResource{
+		definition: &mcp.Resource{
+			URI:         uri,
+			Name:        name,
+			Description: description,
+			MimeType:    "text/plain",
+		},
+	}
+}
+
+// Definition returns the resource definition
+func (r *MemoryResource) Definition() *mcp.Resource {
+	return r.definition
+}
+
+// Read returns the current text
+func (r *MemoryResource) Read(ctx context.Context, uri string) (*mcp.ReadResourceResult, error) {
+	return &mcp.ReadResourceResult{
+		Contents: []mcp.ResourceContents{mcp.NewTextResourceContents(uri, r.definition.MimeType, r.Text())},
+	}, nil
+}
+
+// Text returns the current text
+func (r *MemoryResource) Text() string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.text
+}
+
+// Set replaces the text
+func (r *MemoryResource) Set(text string) {
+	r.mu.Lock()
+	r.text = text
+	update := r.update
+	r.mu.Unlock()
+
+	if update != nil {
+		update(r.definition.URI)
+	}
+}
+
+// OnUpdate sets the function told about changes to the text
+func (r *MemoryResource) OnUpdate(update func(uri string)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.update = update
+}
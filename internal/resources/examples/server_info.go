@@ -0,0 +1,66 @@
+package examples
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"runtime"
+	"time"
+
+	"github.com/chongliujia/mcp-go-template/pkg/mcp"
+)
+
+// ServerInfoURI is the URI of the server info resource
+const ServerInfoURI = "server://info"
+
+// ServerInfoResource describes the running server: its name and version,
+// the protocol versions it speaks and how long it has been up
+type ServerInfoResource struct {
+	info    mcp.ServerInfo
+	started time.Time
+}
+
+// serverInfo is the JSON document served by ServerInfoResource
+type serverInfo struct {
+	Name             string    `json:"name"`
+	Version          string    `json:"version"`
+	ProtocolVersions []string  `json:"protocolVersions"`
+	GoVersion        string    `json:"goVersion"`
+	Started          time.Time `json:"started"`
+	UptimeSeconds    int64     `json:"uptimeSeconds"`
+}
+
+// NewServerInfoResource creates the server info resource, counting uptime
+// from now
+func NewServerInfoResource(info mcp.ServerInfo) *ServerInfoResource {
+	return &ServerInfoResource{info: info, started: time.Now()}
+}
+
+// Definition returns the resource definition
+func (r *ServerInfoResource) Definition() *mcp.Resource {
+	return &mcp.Resource{
+		URI:         ServerInfoURI,
+		Name:        "Server info",
+		Description: "Name, version, supported protocol versions and uptime of this server",
+		MimeType:    "application/json",
+	}
+}
+
+// Read returns the current server info as JSON
+func (r *ServerInfoResource) Read(ctx context.Context, uri string) (*mcp.ReadResourceResult, error) {
+	data, err := json.MarshalIndent(serverInfo{
+		Name:             r.info.Name,
+		Version:          r.info.Version,
+		ProtocolVersions: mcp.SupportedProtocolVersions,
+		GoVersion:        runtime.Version(),
+		Started:          r.started.UTC(),
+		UptimeSeconds:    int64(time.Since(r.started).Seconds()),
+	}, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode server info: %w", err)
+	}
+
+	return &mcp.ReadResourceResult{
+		Contents: []mcp.ResourceContents{mcp.NewTextResourceContents(uri, "application/json", string(data))},
+	}, nil
+}
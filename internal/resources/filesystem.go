@@ -0,0 +1,319 @@
+package resources
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/chongliujia/mcp-go-template/pkg/mcp"
+	"github.com/chongliujia/mcp-go-template/pkg/utils"
+)
+
+// FilesystemOptions selects the files a FilesystemProvider serves.
+// Include and Exclude hold glob patterns matched against the slash
+// separated path below a root: * and ? match within a directory, ** any
+// number of directories, and a pattern without a slash matches a name in
+// any directory. An excluded directory hides everything below it.
+type FilesystemOptions struct {
+	Roots []string
+	// Include limits the files served; empty serves all of them
+	Include []string
+	Exclude []string
+	// MaxFileBytes skips larger files; 0 or less means unlimited
+	MaxFileBytes int64
+	// MaxFiles caps how many files are listed; 0 or less means unlimited
+	MaxFiles int
+}
+
+// FilesystemProvider serves the files under a set of root directories as
+// file:// resources. Resources lists the files found when it is called,
+// while the provider itself is a resource template, so files added later
+// can still be read by URI.
+type FilesystemProvider struct {
+	roots  []string // absolute, with symlinks resolved
+	opts   FilesystemOptions
+	logger utils.Logger
+}
+
+// NewFilesystemProvider creates a provider for opts; every root must be an
+// existing directory
+func NewFilesystemProvider(opts FilesystemOptions) (*FilesystemProvider, error) {
+	if len(opts.Roots) == 0 {
+		return nil, fmt.Errorf("filesystem resources need at least one root")
+	}
+	for _, pattern := range append(append([]string{}, opts.Include...), opts.Exclude...) {
+		if _, err := path.Match(strings.ReplaceAll(pattern, "**", "*"), ""); err != nil {
+			return nil, fmt.Errorf("invalid glob pattern %q: %w", pattern, err)
+		}
+	}
+
+	p := &FilesystemProvider{opts: opts, logger: utils.DefaultLogger()}
+	for _, root := range opts.Roots {
+		abs, err := filepath.Abs(root)
+		if err != nil {
+			return nil, fmt.Errorf("invalid root %s: %w", root, err)
+		}
+		resolved, err := filepath.EvalSymlinks(abs)
+		if err != nil {
+			return nil, fmt.Errorf("invalid root %s: %w", root, err)
+		}
+		info, err := os.Stat(resolved)
+		if err != nil {
+			return nil, fmt.Errorf("invalid root %s: %w", root, err)
+		}
+		if !info.IsDir() {
+			return nil, fmt.Errorf("root %s is not a directory", root)
+		}
+		p.roots = append(p.roots, resolved)
+	}
+	return p, nil
+}
+
+// SetLogger sets the logger the provider logs to. It must be called before
+// Resources or Watch.
+func (p *FilesystemProvider) SetLogger(logger utils.Logger) {
+	p.logger = logger
+}
+
+// Resources walks the roots and returns a resource for every file served,
+// up to MaxFiles
+func (p *FilesystemProvider) Resources() ([]mcp.ResourceHandler, error) {
+	var resources []mcp.ResourceHandler
+	full := errors.New("enough files")
+
+	for _, root := range p.roots {
+		err := filepath.WalkDir(root, func(name string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				p.logger.Warnf("Skipping %s: %v", name, err)
+				return nil
+			}
+			if name == root {
+				return nil
+			}
+			rel := filepath.ToSlash(strings.TrimPrefix(name, root+string(filepath.Separator)))
+			if entry.IsDir() {
+				if matchesAnyGlob(p.opts.Exclude, rel) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !entry.Type().IsRegular() || !p.serves(rel) {
+				return nil
+			}
+			if info, err := entry.Info(); err != nil || p.tooLarge(info.Size()) {
+				return nil
+			}
+
+			if p.opts.MaxFiles > 0 && len(resources) >= p.opts.MaxFiles {
+				p.logger.Warnf("Listing only the first %d files of the filesystem resources", p.opts.MaxFiles)
+				return full
+			}
+			resources = append(resources, &fileResource{definition: &mcp.Resource{
+				URI:      fileURI(name),
+				Name:     rel,
+				MimeType: extensionMimeType(name),
+			}, provider: p, path: name})
+			return nil
+		})
+		if errors.Is(err, full) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s: %w", root, err)
+		}
+	}
+	return resources, nil
+}
+
+// Definition returns the template matching every file:// URI
+func (p *FilesystemProvider) Definition() *mcp.ResourceTemplate {
+	return &mcp.ResourceTemplate{
+		URITemplate: "file:///{+path}",
+		Name:        "Files",
+		Description: "Files under the server's configured filesystem roots",
+	}
+}
+
+// Read reads a file by URI, if it is one the provider serves
+func (p *FilesystemProvider) Read(ctx context.Context, uri string, vars map[string]string) (*mcp.ReadResourceResult, error) {
+	return p.readFile(uri, "/"+vars["path"])
+}
+
+// readFile reads the file at name for uri. Files outside the roots, or
+// not served, are reported as not found.
+func (p *FilesystemProvider) readFile(uri, name string) (*mcp.ReadResourceResult, error) {
+	resolved, err := filepath.EvalSymlinks(filepath.Clean(filepath.FromSlash(name)))
+	if err != nil {
+		return nil, mcp.ResourceNotFoundError(uri)
+	}
+	rel, ok := p.relative(resolved)
+	if !ok || !p.serves(rel) {
+		return nil, mcp.ResourceNotFoundError(uri)
+	}
+
+	info, err := os.Stat(resolved)
+	if err != nil || !info.Mode().IsRegular() {
+		return nil, mcp.ResourceNotFoundError(uri)
+	}
+	if p.tooLarge(info.Size()) {
+		return nil, fmt.Errorf("file %s is larger than %d bytes", rel, p.opts.MaxFileBytes)
+	}
+
+	data, err := os.ReadFile(resolved)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", rel, err)
+	}
+
+	mimeType := extensionMimeType(resolved)
+	if mimeType == "" {
+		mimeType = baseMimeType(http.DetectContentType(data))
+	}
+	if utf8.Valid(data) && !bytes.ContainsRune(data, 0) {
+		if mimeType == "application/octet-stream" {
+			mimeType = "text/plain"
+		}
+		return &mcp.ReadResourceResult{
+			Contents: []mcp.ResourceContents{mcp.NewTextResourceContents(uri, mimeType, string(data))},
+		}, nil
+	}
+	return &mcp.ReadResourceResult{
+		Contents: []mcp.ResourceContents{mcp.NewBlobResourceContents(uri, mimeType, data)},
+	}, nil
+}
+
+// relative returns the slash separated path of name below the root that
+// holds it
+func (p *FilesystemProvider) relative(name string) (string, bool) {
+	for _, root := range p.roots {
+		rel, err := filepath.Rel(root, name)
+		if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		return filepath.ToSlash(rel), true
+	}
+	return "", false
+}
+
+// serves reports whether the file at rel is included and neither it nor a
+// directory above it is excluded
+func (p *FilesystemProvider) serves(rel string) bool {
+	for dir := path.Dir(rel); dir != "."; dir = path.Dir(dir) {
+		if matchesAnyGlob(p.opts.Exclude, dir) {
+			return false
+		}
+	}
+	if matchesAnyGlob(p.opts.Exclude, rel) {
+		return false
+	}
+	return len(p.opts.Include) == 0 || matchesAnyGlob(p.opts.Include, rel)
+}
+
+// tooLarge reports whether a file of size bytes exceeds MaxFileBytes
+func (p *FilesystemProvider) tooLarge(size int64) bool {
+	return p.opts.MaxFileBytes > 0 && size > p.opts.MaxFileBytes
+}
+
+// fileResource is one listed file
+type fileResource struct {
+	definition *mcp.Resource
+	provider   *FilesystemProvider
+	path       string
+}
+
+// Definition returns the resource definition
+func (r *fileResource) Definition() *mcp.Resource {
+	return r.definition
+}
+
+// Read reads the file
+func (r *fileResource) Read(ctx context.Context, uri string) (*mcp.ReadResourceResult, error) {
+	return r.provider.readFile(uri, r.path)
+}
+
+// fileURI returns the file:// URI of an absolute path
+func fileURI(name string) string {
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(name)}).String()
+}
+
+// sourceMimeTypes covers project files that the system MIME table may not
+// know about
+var sourceMimeTypes = map[string]string{
+	".md":   "text/markdown",
+	".txt":  "text/plain",
+	".go":   "text/x-go",
+	".py":   "text/x-python",
+	".sh":   "text/x-shellscript",
+	".yaml": "application/yaml",
+	".yml":  "application/yaml",
+	".toml": "application/toml",
+}
+
+// extensionMimeType guesses a file's MIME type from its extension
+func extensionMimeType(name string) string {
+	ext := strings.ToLower(filepath.Ext(name))
+	if mimeType, ok := sourceMimeTypes[ext]; ok {
+		return mimeType
+	}
+	return baseMimeType(mime.TypeByExtension(ext))
+}
+
+// baseMimeType drops the parameters of a MIME type, such as its charset
+func baseMimeType(mimeType string) string {
+	if i := strings.IndexByte(mimeType, ';'); i >= 0 {
+		mimeType = mimeType[:i]
+	}
+	return strings.TrimSpace(mimeType)
+}
+
+// matchesAnyGlob reports whether rel matches one of the patterns
+func matchesAnyGlob(patterns []string, rel string) bool {
+	for _, pattern := range patterns {
+		if matchGlob(pattern, rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchGlob matches a slash separated path against a pattern in which **
+// stands for any number of directories; a pattern without a slash is
+// matched against the last element
+func matchGlob(pattern, rel string) bool {
+	if !strings.Contains(pattern, "/") {
+		matched, _ := path.Match(pattern, path.Base(rel))
+		return matched
+	}
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(rel, "/"))
+}
+
+// matchSegments matches path elements against pattern elements
+func matchSegments(patterns, names []string) bool {
+	for len(patterns) > 0 {
+		if patterns[0] == "**" {
+			for i := 0; i <= len(names); i++ {
+				if matchSegments(patterns[1:], names[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(names) == 0 {
+			return false
+		}
+		if matched, _ := path.Match(patterns[0], names[0]); !matched {
+			return false
+		}
+		patterns, names = patterns[1:], names[1:]
+	}
+	return len(names) == 0
+}
@@ -0,0 +1,145 @@
+package resources
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/chongliujia/mcp-go-template/pkg/mcp"
+)
+
+// writeFiles creates files below dir from a map of relative path to content
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestFilesystemProvider_ListsAndReads(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"README.md":             "# Project",
+		"docs/guide.md":         "guide",
+		"docs/api/endpoints.md": "endpoints",
+		"docs/draft.md":         "draft",
+		"main.go":               "package main",
+		"logo.png":              "\x89PNG\r\n\x1a\n\x00\x00",
+		"big.md":                string(make([]byte, 64)),
+		".env":                  "SECRET=1",
+		".git/config":           "[core]",
+	})
+
+	provider, err := NewFilesystemProvider(FilesystemOptions{
+		Roots:        []string{root},
+		Include:      []string{"**/*.md", "*.png"},
+		Exclude:      []string{".*", "docs/draft.md"},
+		MaxFileBytes: 32,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	files, err := provider.Resources()
+	if err != nil {
+		t.Fatalf("Failed to list files: %v", err)
+	}
+	var names []string
+	for _, file := range files {
+		names = append(names, file.Definition().Name)
+	}
+	sort.Strings(names)
+	want := []string{"README.md", "docs/api/endpoints.md", "docs/guide.md", "logo.png"}
+	if len(names) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, names)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Fatalf("Expected %v, got %v", want, names)
+		}
+	}
+
+	handler := mcp.NewBaseHandler(mcp.ServerInfo{Name: "test", Version: "1.0.0"}, mcp.ServerCapabilities{})
+	for _, file := range files {
+		handler.RegisterResource(file)
+	}
+	handler.RegisterResourceTemplate(provider)
+	handler.HandleMessage(context.Background(), mcp.NewNotification("initialized", nil))
+	read := func(name string) (*mcp.ReadResourceResult, error) {
+		return handler.ReadResource(context.Background(), &mcp.ReadResourceParams{URI: fileURI(filepath.Join(root, name))})
+	}
+
+	result, err := read("docs/guide.md")
+	if err != nil || result.Contents[0].Text != "guide" || result.Contents[0].MimeType != "text/markdown" {
+		t.Errorf("Unexpected guide contents %+v, %v", result, err)
+	}
+	result, err = read("logo.png")
+	if err != nil || !result.Contents[0].IsBlob() || result.Contents[0].MimeType != "image/png" {
+		t.Errorf("Expected the logo as a PNG blob, got %+v, %v", result, err)
+	}
+
+	// Files added after listing are read through the template
+	writeFiles(t, root, map[string]string{"docs/new.md": "new"})
+	if result, err := read("docs/new.md"); err != nil || result.Contents[0].Text != "new" {
+		t.Errorf("Expected a new file to be readable, got %+v, %v", result, err)
+	}
+
+	for _, name := range []string{".env", ".git/config", "main.go", "docs/draft.md", "missing.md", "../outside.md"} {
+		_, err := read(name)
+		if info, ok := mcp.AsErrorInfo(err); !ok || info.Code != mcp.ResourceNotFound {
+			t.Errorf("Expected %s to be not found, got %v", name, err)
+		}
+	}
+	if _, err := read("big.md"); err == nil {
+		t.Error("Expected a file over max_file_bytes to be refused")
+	}
+}
+
+func TestFilesystemProvider_RejectsEscapingSymlinks(t *testing.T) {
+	root, outside := t.TempDir(), t.TempDir()
+	writeFiles(t, outside, map[string]string{"secret.txt": "secret"})
+	if err := os.Symlink(filepath.Join(outside, "secret.txt"), filepath.Join(root, "link.txt")); err != nil {
+		t.Skipf("Symlinks unavailable: %v", err)
+	}
+
+	provider, err := NewFilesystemProvider(FilesystemOptions{Roots: []string{root}})
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+	uri := fileURI(filepath.Join(root, "link.txt"))
+	if _, err := provider.readFile(uri, filepath.Join(root, "link.txt")); err == nil {
+		t.Error("Expected a symlink leaving the root to be refused")
+	}
+}
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern, path string
+		want          bool
+	}{
+		{"*.md", "README.md", true},
+		{"*.md", "docs/guide.md", true},
+		{"docs/*.md", "docs/guide.md", true},
+		{"docs/*.md", "docs/api/endpoints.md", false},
+		{"docs/**", "docs/api/endpoints.md", true},
+		{"**/*.md", "README.md", true},
+		{"**/api/*.md", "docs/api/endpoints.md", true},
+		{".*", ".git", true},
+		{".*", "docs/.hidden", true},
+		{"node_modules", "web/node_modules", true},
+	}
+
+	for _, tt := range tests {
+		if got := matchGlob(tt.pattern, tt.path); got != tt.want {
+			t.Errorf("matchGlob(%q, %q) = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
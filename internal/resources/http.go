@@ -0,0 +1,87 @@
+package resources
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"unicode/utf8"
+
+	"github.com/chongliujia/mcp-go-template/pkg/mcp"
+)
+
+// maxHTTPResourceBytes bounds the body read for an HTTP resource
+const maxHTTPResourceBytes = 10 << 20
+
+// HTTPResource serves the content of a URL as a resource. It revalidates
+// with If-None-Match, so a Cache only downloads it again when it changed.
+type HTTPResource struct {
+	definition *mcp.Resource
+	url        string
+	client     *http.Client
+}
+
+// NewHTTPResource creates a resource with the given definition whose
+// content is fetched from url
+func NewHTTPResource(definition *mcp.Resource, url string, client *http.Client) *HTTPResource {
+	return &HTTPResource{definition: definition, url: url, client: client}
+}
+
+// Definition returns the resource definition
+func (r *HTTPResource) Definition() *mcp.Resource {
+	return r.definition
+}
+
+// Read fetches the resource
+func (r *HTTPResource) Read(ctx context.Context, uri string) (*mcp.ReadResourceResult, error) {
+	result, _, err := r.ReadChanged(ctx, uri, "")
+	return result, err
+}
+
+// ReadChanged fetches the resource unless the server answers that its
+// ETag is still etag
+func (r *HTTPResource) ReadChanged(ctx context.Context, uri, etag string) (*mcp.ReadResourceResult, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.url, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create request: %w", err)
+	}
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to fetch %s: %w", r.url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified && etag != "" {
+		return nil, etag, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("fetching %s returned status %d", r.url, resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxHTTPResourceBytes+1))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read %s: %w", r.url, err)
+	}
+	if len(data) > maxHTTPResourceBytes {
+		return nil, "", fmt.Errorf("%s is larger than %d bytes", r.url, maxHTTPResourceBytes)
+	}
+
+	mimeType := r.definition.MimeType
+	if mimeType == "" {
+		mimeType = baseMimeType(resp.Header.Get("Content-Type"))
+	}
+	if mimeType == "" {
+		mimeType = baseMimeType(http.DetectContentType(data))
+	}
+	var contents mcp.ResourceContents
+	if utf8.Valid(data) && !bytes.ContainsRune(data, 0) {
+		contents = mcp.NewTextResourceContents(uri, mimeType, string(data))
+	} else {
+		contents = mcp.NewBlobResourceContents(uri, mimeType, data)
+	}
+	return &mcp.ReadResourceResult{Contents: []mcp.ResourceContents{contents}}, resp.Header.Get("ETag"), nil
+}
@@ -0,0 +1,173 @@
+package resources
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/chongliujia/mcp-go-template/internal/resources/examples"
+	"github.com/chongliujia/mcp-go-template/pkg/mcp"
+	"github.com/chongliujia/mcp-go-template/pkg/utils"
+)
+
+// Registry manages resource registration and discovery. It implements
+// mcp.ResourceRegistry, so a BaseHandler can serve its resources directly.
+type Registry struct {
+	resources map[string]mcp.ResourceHandler // uri -> handler
+	mutex     sync.RWMutex
+	onChange  func()
+	logger    utils.Logger
+}
+
+// NewRegistry creates a new resource registry
+func NewRegistry() *Registry {
+	return &Registry{
+		resources: make(map[string]mcp.ResourceHandler),
+		logger:    utils.DefaultLogger(),
+	}
+}
+
+// SetLogger sets the logger the registry logs to. It must be called before
+// resources are registered.
+func (r *Registry) SetLogger(logger utils.Logger) {
+	r.logger = logger
+}
+
+// Register registers a resource handler, replacing any resource with the
+// same URI
+func (r *Registry) Register(handler mcp.ResourceHandler) error {
+	resource := handler.Definition()
+	if resource == nil {
+		return fmt.Errorf("resource definition cannot be nil")
+	}
+	if resource.URI == "" {
+		return fmt.Errorf("resource URI cannot be empty")
+	}
+
+	r.mutex.Lock()
+	_, replaced := r.resources[resource.URI]
+	r.resources[resource.URI] = handler
+	r.mutex.Unlock()
+
+	if replaced {
+		r.logger.Infof("Replaced resource: %s", resource.URI)
+	} else {
+		r.logger.Infof("Registered resource: %s", resource.URI)
+	}
+	r.changed()
+	return nil
+}
+
+// Unregister removes a resource from the registry
+func (r *Registry) Unregister(uri string) error {
+	r.mutex.Lock()
+	if _, exists := r.resources[uri]; !exists {
+		r.mutex.Unlock()
+		return fmt.Errorf("resource '%s' is not registered", uri)
+	}
+	delete(r.resources, uri)
+	r.mutex.Unlock()
+
+	r.logger.Infof("Unregistered resource: %s", uri)
+	r.changed()
+	return nil
+}
+
+// OnChange sets a function called after a resource is registered or
+// unregistered, for example to send notifications/resources/list_changed.
+// A BaseHandler serving the registry sets it itself.
+func (r *Registry) OnChange(fn func()) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.onChange = fn
+}
+
+// changed runs the change callback; the caller must not hold the lock
+func (r *Registry) changed() {
+	r.mutex.RLock()
+	onChange := r.onChange
+	r.mutex.RUnlock()
+
+	if onChange != nil {
+		onChange()
+	}
+}
+
+// Get retrieves a resource handler by URI
+func (r *Registry) Get(uri string) (mcp.ResourceHandler, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	handler, exists := r.resources[uri]
+	if !exists {
+		return nil, fmt.Errorf("resource '%s' not found", uri)
+	}
+
+	return handler, nil
+}
+
+// List returns all registered resources
+func (r *Registry) List() []*mcp.Resource {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	resources := make([]*mcp.Resource, 0, len(r.resources))
+	for _, handler := range r.resources {
+		resources = append(resources, handler.Definition())
+	}
+
+	return resources
+}
+
+// Count returns the number of registered resources
+func (r *Registry) Count() int {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return len(r.resources)
+}
+
+// DefaultResources returns the example resources for a server. Register
+// them through the handler, so resources that change can notify their
+// subscribers.
+func DefaultResources(info mcp.ServerInfo) []mcp.ResourceHandler {
+	return []mcp.ResourceHandler{
+		examples.NewServerInfoResource(info),
+		examples.NewMemoryResource("memory://notes", "Notes", "Scratch notes shared by the clients of this server"),
+	}
+}
+
+// GetResourceURIs returns the sorted URIs of all registered resources
+func (r *Registry) GetResourceURIs() []string {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	uris := make([]string, 0, len(r.resources))
+	for uri := range r.resources {
+		uris = append(uris, uri)
+	}
+
+	sort.Strings(uris)
+	return uris
+}
+
+// HasResource checks if a resource is registered
+func (r *Registry) HasResource(uri string) bool {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	_, exists := r.resources[uri]
+	return exists
+}
+
+// Clear removes all registered resources
+func (r *Registry) Clear() {
+	r.mutex.Lock()
+	hadResources := len(r.resources) > 0
+	r.resources = make(map[string]mcp.ResourceHandler)
+	r.mutex.Unlock()
+
+	r.logger.Info("Cleared all registered resources")
+	if hadResources {
+		r.changed()
+	}
+}
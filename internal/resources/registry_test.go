@@ -0,0 +1,84 @@
+package resources
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/chongliujia/mcp-go-template/internal/resources/examples"
+	"github.com/chongliujia/mcp-go-template/pkg/mcp"
+)
+
+func TestRegistry_ServedByBaseHandler(t *testing.T) {
+	registry := NewRegistry()
+	handler := mcp.NewBaseHandlerWithRegistries(mcp.ServerInfo{Name: "test", Version: "1.0.0"}, mcp.ServerCapabilities{
+		Resources: &mcp.ResourcesCapability{Subscribe: true, ListChanged: true},
+	}, mcp.Registries{Resources: registry})
+	handler.HandleMessage(context.Background(), mcp.NewNotification("initialized", nil))
+
+	var methods []string
+	handler.SetNotifier(func(notification *mcp.Message) {
+		methods = append(methods, notification.Method)
+	})
+
+	for _, resource := range DefaultResources(handler.GetServerInfo()) {
+		if err := handler.RegisterResource(resource); err != nil {
+			t.Fatalf("Failed to register resource: %v", err)
+		}
+	}
+	if uris := registry.GetResourceURIs(); len(uris) != 2 || uris[0] != "memory://notes" || uris[1] != examples.ServerInfoURI {
+		t.Errorf("Expected the default resources in the registry, got %v", uris)
+	}
+
+	result, err := handler.ReadResource(context.Background(), &mcp.ReadResourceParams{URI: examples.ServerInfoURI})
+	if err != nil {
+		t.Fatalf("Failed to read server info: %v", err)
+	}
+	var info map[string]interface{}
+	if err := json.Unmarshal([]byte(result.Contents[0].Text), &info); err != nil || info["name"] != "test" || info["version"] != "1.0.0" {
+		t.Errorf("Unexpected server info %s: %v", result.Contents[0].Text, err)
+	}
+
+	notes, _ := registry.Get("memory://notes")
+	notes.(*examples.MemoryResource).Set("remember the milk")
+	result, err = handler.ReadResource(context.Background(), &mcp.ReadResourceParams{URI: "memory://notes"})
+	if err != nil || result.Contents[0].Text != "remember the milk" {
+		t.Errorf("Expected the notes to be updated, got %+v, %v", result, err)
+	}
+
+	if err := handler.UnregisterResource("memory://notes"); err != nil {
+		t.Errorf("UnregisterResource failed: %v", err)
+	}
+	if registry.HasResource("memory://notes") || registry.Count() != 1 {
+		t.Errorf("Expected only the server info to remain, got %v", registry.GetResourceURIs())
+	}
+
+	want := []string{
+		"notifications/resources/list_changed",
+		"notifications/resources/list_changed",
+		"notifications/resources/updated",
+		"notifications/resources/list_changed",
+	}
+	if len(methods) != len(want) {
+		t.Fatalf("Expected notifications %v, got %v", want, methods)
+	}
+	for i := range want {
+		if methods[i] != want[i] {
+			t.Errorf("Expected notifications %v, got %v", want, methods)
+			break
+		}
+	}
+}
+
+func TestRegistry_RejectsInvalidResources(t *testing.T) {
+	registry := NewRegistry()
+	if err := registry.Register(examples.NewMemoryResource("", "Empty", "")); err == nil {
+		t.Error("Expected a resource without a URI to be rejected")
+	}
+	if err := registry.Unregister("memory://missing"); err == nil {
+		t.Error("Expected unregistering a missing resource to fail")
+	}
+	if _, err := registry.Get("memory://missing"); err == nil {
+		t.Error("Expected getting a missing resource to fail")
+	}
+}
@@ -0,0 +1,111 @@
+package resources
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// watchDebounce groups the bursts of events an editor produces while
+// saving a file into one update
+const watchDebounce = 100 * time.Millisecond
+
+// Watch calls update with the URI of every served file that is written,
+// created, removed or renamed below the roots, until ctx is done. New
+// directories are watched as they appear.
+func (p *FilesystemProvider) Watch(ctx context.Context, update func(uri string)) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create watcher: %w", err)
+	}
+	for _, root := range p.roots {
+		if err := p.watchTree(watcher, root); err != nil {
+			watcher.Close()
+			return err
+		}
+	}
+
+	go func() {
+		defer watcher.Close()
+
+		changed := make(map[string]bool)
+		var flush <-chan time.Time
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if p.handleEvent(watcher, event) {
+					changed[event.Name] = true
+					if flush == nil {
+						flush = time.After(watchDebounce)
+					}
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				p.logger.Warnf("Filesystem resource watcher error: %v", err)
+			case <-flush:
+				flush = nil
+				names := make([]string, 0, len(changed))
+				for name := range changed {
+					names = append(names, name)
+				}
+				sort.Strings(names)
+				for _, name := range names {
+					update(fileURI(name))
+				}
+				changed = make(map[string]bool)
+			}
+		}
+	}()
+
+	return nil
+}
+
+// handleEvent watches directories created below a root and reports whether
+// the event changed a served file
+func (p *FilesystemProvider) handleEvent(watcher *fsnotify.Watcher, event fsnotify.Event) bool {
+	rel, ok := p.relative(event.Name)
+	if !ok || event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Remove|fsnotify.Rename) == 0 {
+		return false
+	}
+
+	if event.Has(fsnotify.Create) {
+		if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+			if !matchesAnyGlob(p.opts.Exclude, rel) {
+				if err := p.watchTree(watcher, event.Name); err != nil {
+					p.logger.Warnf("Failed to watch %s: %v", event.Name, err)
+				}
+			}
+			return false
+		}
+	}
+	return p.serves(rel)
+}
+
+// watchTree adds dir and the directories below it that are not excluded
+func (p *FilesystemProvider) watchTree(watcher *fsnotify.Watcher, dir string) error {
+	return filepath.WalkDir(dir, func(name string, entry fs.DirEntry, err error) error {
+		if err != nil || !entry.IsDir() {
+			return nil
+		}
+		if rel, ok := p.relative(name); ok && matchesAnyGlob(p.opts.Exclude, rel) {
+			return filepath.SkipDir
+		}
+		if err := watcher.Add(name); err != nil {
+			return fmt.Errorf("failed to watch %s: %w", name, err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,79 @@
+package resources
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFilesystemProvider_Watch(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"guide.md":     "v1",
+		".git/HEAD":    "ref",
+		"notes/old.md": "old",
+	})
+
+	provider, err := NewFilesystemProvider(FilesystemOptions{
+		Roots:   []string{root},
+		Include: []string{"**/*.md"},
+		Exclude: []string{".*"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	updates := make(chan string, 16)
+	if err := provider.Watch(ctx, func(uri string) { updates <- uri }); err != nil {
+		t.Fatalf("Failed to watch: %v", err)
+	}
+
+	var seen []string
+	expectUpdate := func(name string) {
+		t.Helper()
+		want := fileURI(filepath.Join(provider.roots[0], filepath.FromSlash(name)))
+		deadline := time.After(5 * time.Second)
+		for {
+			select {
+			case uri := <-updates:
+				seen = append(seen, uri)
+				if uri == want {
+					return
+				}
+			case <-deadline:
+				t.Fatalf("Expected an update for %s", want)
+			}
+		}
+	}
+
+	writeFiles(t, root, map[string]string{".git/HEAD": "changed", "guide.txt": "ignored", "guide.md": "v2"})
+	expectUpdate("guide.md")
+
+	if err := os.Remove(filepath.Join(root, "notes", "old.md")); err != nil {
+		t.Fatal(err)
+	}
+	expectUpdate("notes/old.md")
+
+	// New directories are watched too
+	if err := os.Mkdir(filepath.Join(root, "drafts"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(2 * watchDebounce)
+	writeFiles(t, root, map[string]string{"drafts/new.md": "new"})
+	expectUpdate("drafts/new.md")
+
+	time.Sleep(3 * watchDebounce)
+	for len(updates) > 0 {
+		seen = append(seen, <-updates)
+	}
+	for _, uri := range seen {
+		if filepath.Ext(uri) != ".md" || strings.Contains(uri, "/.git/") {
+			t.Errorf("Expected no updates for files that are not served, got %s", uri)
+		}
+	}
+}
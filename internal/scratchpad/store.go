@@ -0,0 +1,234 @@
+// Package scratchpad keeps key-value entries in memory for agents to note
+// intermediate findings between tool calls. Entries are written through
+// the kv_store tool and served as kv://<key> resources. They belong to the
+// client that wrote them, identified by its credentials or, without any,
+// by its session. Entries of a session are dropped when it closes; those
+// of credentials last until the server restarts.
+package scratchpad
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/chongliujia/mcp-go-template/pkg/mcp"
+)
+
+// URIPrefix starts the URI of every entry
+const URIPrefix = "kv://"
+
+// validKey restricts keys to characters that need no escaping in a URI
+var validKey = regexp.MustCompile(`^[A-Za-z0-9._~/-]{1,128}$`)
+
+// Options bounds what the store holds
+type Options struct {
+	// MaxEntries caps the number of keys of each client; 0 or less means
+	// unlimited
+	MaxEntries int
+	// MaxValueBytes caps the size of a value; 0 or less means unlimited
+	MaxValueBytes int
+	// MaxTotalEntries caps the keys of all clients together; 0 or less
+	// means unlimited
+	MaxTotalEntries int
+	// MaxTotalBytes caps the size of all values together; 0 or less means
+	// unlimited
+	MaxTotalBytes int
+}
+
+// Entry is one stored value
+type Entry struct {
+	Key      string    `json:"key"`
+	Value    string    `json:"value"`
+	MimeType string    `json:"mime_type"`
+	Updated  time.Time `json:"updated"`
+}
+
+// Store holds the entries of each client. It is also the resource template
+// kv://{+key}: clients list, read and subscribe to their own entries.
+type Store struct {
+	opts Options
+
+	mu      sync.RWMutex
+	entries map[string]map[string]Entry // owner -> key -> entry
+	count   int                         // entries of all owners
+	bytes   int                         // value bytes of all owners
+}
+
+// NewStore creates an empty store
+func NewStore(opts Options) *Store {
+	return &Store{
+		opts:    opts,
+		entries: make(map[string]map[string]Entry),
+	}
+}
+
+// owner identifies the client in ctx: the principal of its credentials, so
+// its entries outlive a reconnect, or else its session. Calls without a
+// session share the "" owner.
+func owner(ctx context.Context) string {
+	session, ok := mcp.SessionFromContext(ctx)
+	if !ok {
+		return ""
+	}
+	if principal := session.Principal(); principal != "" {
+		return "principal:" + principal
+	}
+	return sessionOwner(session)
+}
+
+// sessionOwner identifies a client without credentials by its session
+func sessionOwner(session *mcp.Session) string {
+	return "session:" + session.ID()
+}
+
+// Set stores value under key for the client in ctx, replacing any
+// previous value, and tells the client if it subscribed to the entry
+func (s *Store) Set(ctx context.Context, key, value, mimeType string) error {
+	if !validKey.MatchString(key) {
+		return fmt.Errorf("invalid key %q: use up to 128 letters, digits and ._~/-", key)
+	}
+	if s.opts.MaxValueBytes > 0 && len(value) > s.opts.MaxValueBytes {
+		return fmt.Errorf("value is larger than %d bytes", s.opts.MaxValueBytes)
+	}
+	entry := Entry{Key: key, Value: value, MimeType: mimeType, Updated: time.Now()}
+
+	s.mu.Lock()
+	id := owner(ctx)
+	entries := s.entries[id]
+	if entries == nil {
+		entries = make(map[string]Entry)
+		s.entries[id] = entries
+	}
+	previous, exists := entries[key]
+	if !exists && s.opts.MaxEntries > 0 && len(entries) >= s.opts.MaxEntries {
+		s.mu.Unlock()
+		return fmt.Errorf("the store is full (%d entries); delete entries first", s.opts.MaxEntries)
+	}
+	if !exists && s.opts.MaxTotalEntries > 0 && s.count >= s.opts.MaxTotalEntries {
+		s.mu.Unlock()
+		return fmt.Errorf("the store is full for all clients (%d entries); delete entries first", s.opts.MaxTotalEntries)
+	}
+	bytes := s.bytes - len(previous.Value) + len(value)
+	if s.opts.MaxTotalBytes > 0 && bytes > s.opts.MaxTotalBytes {
+		s.mu.Unlock()
+		return fmt.Errorf("the store is full for all clients (%d bytes); delete entries first", s.opts.MaxTotalBytes)
+	}
+	entries[key] = entry
+	s.bytes = bytes
+	if !exists {
+		s.count++
+	}
+	s.mu.Unlock()
+
+	if exists {
+		notifyUpdated(ctx, URIPrefix+key)
+	}
+	return nil
+}
+
+// Get returns the entry the client in ctx stored under key
+func (s *Store) Get(ctx context.Context, key string) (Entry, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	entry, exists := s.entries[owner(ctx)][key]
+	return entry, exists
+}
+
+// Delete removes the entry the client in ctx stored under key, reporting
+// whether there was one
+func (s *Store) Delete(ctx context.Context, key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := owner(ctx)
+	entry, exists := s.entries[id][key]
+	if exists {
+		s.count--
+		s.bytes -= len(entry.Value)
+	}
+	delete(s.entries[id], key)
+	if len(s.entries[id]) == 0 {
+		delete(s.entries, id)
+	}
+	return exists
+}
+
+// Release drops the entries owned by session, once it closed. Entries of
+// its credentials are kept for the client's next session.
+func (s *Store) Release(session *mcp.Session) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := sessionOwner(session)
+	for _, entry := range s.entries[id] {
+		s.count--
+		s.bytes -= len(entry.Value)
+	}
+	delete(s.entries, id)
+}
+
+// List returns the entries of the client in ctx sorted by key
+func (s *Store) List(ctx context.Context) []Entry {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	owned := s.entries[owner(ctx)]
+	entries := make([]Entry, 0, len(owned))
+	for _, entry := range owned {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Key < entries[j].Key })
+	return entries
+}
+
+// Definition returns the resource template of the entries
+func (s *Store) Definition() *mcp.ResourceTemplate {
+	return &mcp.ResourceTemplate{
+		URITemplate: URIPrefix + "{+key}",
+		Name:        "scratchpad",
+		Description: "Scratchpad entries written with kv_store",
+	}
+}
+
+// ListResources returns the entries of the client in ctx as resources
+func (s *Store) ListResources(ctx context.Context) []*mcp.Resource {
+	entries := s.List(ctx)
+	resources := make([]*mcp.Resource, len(entries))
+	for i, entry := range entries {
+		resources[i] = &mcp.Resource{
+			URI:         URIPrefix + entry.Key,
+			Name:        entry.Key,
+			Description: "Scratchpad entry written with kv_store",
+			MimeType:    entry.MimeType,
+		}
+	}
+	return resources
+}
+
+// Read returns the current value of one of the entries of the client in
+// ctx
+func (s *Store) Read(ctx context.Context, uri string, vars map[string]string) (*mcp.ReadResourceResult, error) {
+	entry, exists := s.Get(ctx, vars["key"])
+	if !exists {
+		return nil, mcp.ResourceNotFoundError(uri)
+	}
+	return &mcp.ReadResourceResult{
+		Contents: []mcp.ResourceContents{mcp.NewTextResourceContents(uri, entry.MimeType, entry.Value)},
+	}, nil
+}
+
+// notifyUpdated tells the session in ctx that uri changed, if it
+// subscribed to it. Only the writer's session is told, since entries are
+// not shared.
+func notifyUpdated(ctx context.Context, uri string) {
+	session, ok := mcp.SessionFromContext(ctx)
+	if !ok || !session.IsSubscribed(uri) {
+		return
+	}
+	session.Notify(mcp.NewNotification("notifications/resources/updated", map[string]interface{}{
+		"uri": uri,
+	}))
+}
@@ -0,0 +1,190 @@
+package scratchpad
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/chongliujia/mcp-go-template/pkg/mcp"
+)
+
+func newTestHandler(t *testing.T, store *Store) *mcp.BaseHandler {
+	h := mcp.NewBaseHandler(mcp.ServerInfo{Name: "test", Version: "1.0.0"}, mcp.ServerCapabilities{
+		Resources: &mcp.ResourcesCapability{Subscribe: true},
+	})
+	if err := h.RegisterResourceTemplate(store); err != nil {
+		t.Fatalf("Failed to register the store: %v", err)
+	}
+	return h
+}
+
+// connect starts an initialized session with h
+func connect(h *mcp.BaseHandler, id string) (context.Context, *mcp.Session) {
+	session := mcp.NewSession(id)
+	ctx := mcp.WithSession(context.Background(), session)
+	h.HandleMessage(ctx, mcp.NewRequest(1, "initialize", mcp.InitializeParams{ProtocolVersion: mcp.MCPVersion}))
+	h.HandleMessage(ctx, mcp.NewNotification("initialized", nil))
+	return ctx, session
+}
+
+// listedURIs returns the URIs resources/list returns in ctx
+func listedURIs(t *testing.T, h *mcp.BaseHandler, ctx context.Context) []string {
+	response, _ := h.HandleMessage(ctx, mcp.NewRequest(2, "resources/list", nil))
+	if response.Error != nil {
+		t.Fatalf("Failed to list resources: %v", response.Error)
+	}
+	data, _ := json.Marshal(response.Result)
+	var result struct {
+		Resources []mcp.Resource `json:"resources"`
+	}
+	json.Unmarshal(data, &result)
+
+	uris := make([]string, len(result.Resources))
+	for i, resource := range result.Resources {
+		uris[i] = resource.URI
+	}
+	return uris
+}
+
+func TestStore_EntriesAreResources(t *testing.T) {
+	store := NewStore(Options{})
+	h := newTestHandler(t, store)
+	ctx, session := connect(h, "writer")
+	var notified []string
+	session.SetNotifier(func(notification *mcp.Message) {
+		notified = append(notified, notification.Method)
+	})
+
+	if err := store.Set(ctx, "findings/pricing", "Tier B is cheapest", "text/plain"); err != nil {
+		t.Fatalf("Failed to set: %v", err)
+	}
+	result, err := h.ReadResource(ctx, &mcp.ReadResourceParams{URI: "kv://findings/pricing"})
+	if err != nil {
+		t.Fatalf("Expected the entry as a resource, got %v", err)
+	}
+	if result.Contents[0].Text != "Tier B is cheapest" {
+		t.Errorf("Unexpected contents: %+v", result.Contents[0])
+	}
+	if uris := listedURIs(t, h, ctx); len(uris) != 1 || uris[0] != "kv://findings/pricing" {
+		t.Errorf("Expected the entry to be listed, got %v", uris)
+	}
+
+	subscribe := mcp.NewRequest(3, "resources/subscribe", mcp.SubscribeParams{URI: "kv://findings/pricing"})
+	if response, _ := h.HandleMessage(ctx, subscribe); response.Error != nil {
+		t.Fatalf("Failed to subscribe: %v", response.Error)
+	}
+	if err := store.Set(ctx, "findings/pricing", "Tier C is cheapest", "text/plain"); err != nil {
+		t.Fatalf("Failed to set: %v", err)
+	}
+	if len(notified) != 1 || notified[0] != "notifications/resources/updated" {
+		t.Errorf("Expected replacing a value to notify the subscriber, got %v", notified)
+	}
+
+	if !store.Delete(ctx, "findings/pricing") {
+		t.Fatal("Expected the entry to be deleted")
+	}
+	_, err = h.ReadResource(ctx, &mcp.ReadResourceParams{URI: "kv://findings/pricing"})
+	if info, ok := mcp.AsErrorInfo(err); !ok || info.Code != mcp.ResourceNotFound {
+		t.Errorf("Expected a deleted entry to be gone, got %v", err)
+	}
+}
+
+func TestStore_ScopedToClient(t *testing.T) {
+	store := NewStore(Options{MaxEntries: 1})
+	h := newTestHandler(t, store)
+	alice, _ := connect(h, "alice")
+	bob, _ := connect(h, "bob")
+
+	if err := store.Set(alice, "notes", "alice's", "text/plain"); err != nil {
+		t.Fatalf("Failed to set: %v", err)
+	}
+	if err := store.Set(bob, "other", "bob's", "text/plain"); err != nil {
+		t.Errorf("Expected MaxEntries to apply to each client, got %v", err)
+	}
+
+	if _, exists := store.Get(bob, "notes"); exists {
+		t.Error("Expected another session not to see the entry")
+	}
+	if store.Delete(bob, "notes") {
+		t.Error("Expected another session not to delete the entry")
+	}
+	if uris := listedURIs(t, h, bob); len(uris) != 1 || uris[0] != "kv://other" {
+		t.Errorf("Expected only the session's own entries listed, got %v", uris)
+	}
+	_, err := h.ReadResource(bob, &mcp.ReadResourceParams{URI: "kv://notes"})
+	if info, ok := mcp.AsErrorInfo(err); !ok || info.Code != mcp.ResourceNotFound {
+		t.Errorf("Expected another session's entry not to be readable, got %v", err)
+	}
+
+	// Sessions of the same principal share entries across reconnects
+	first, firstSession := connect(h, "first")
+	firstSession.SetPrincipal("agent")
+	store.Set(first, "plan", "step 1", "text/plain")
+	second, secondSession := connect(h, "second")
+	secondSession.SetPrincipal("agent")
+	if entry, exists := store.Get(second, "plan"); !exists || entry.Value != "step 1" {
+		t.Errorf("Expected the principal's entry in a new session, got %+v", entry)
+	}
+}
+
+func TestStore_Limits(t *testing.T) {
+	store := NewStore(Options{MaxEntries: 2, MaxValueBytes: 8})
+	ctx := context.Background()
+
+	if err := store.Set(ctx, "a", "123456789", "text/plain"); err == nil {
+		t.Error("Expected a value over MaxValueBytes to be rejected")
+	}
+	for _, key := range []string{"", "has space", "../x?y"} {
+		if err := store.Set(ctx, key, "v", "text/plain"); err == nil {
+			t.Errorf("Expected key %q to be rejected", key)
+		}
+	}
+
+	store.Set(ctx, "a", "1", "text/plain")
+	store.Set(ctx, "b", "2", "text/plain")
+	if err := store.Set(ctx, "c", "3", "text/plain"); err == nil {
+		t.Error("Expected a full store to reject new keys")
+	}
+	if err := store.Set(ctx, "a", "replaced", "text/plain"); err != nil {
+		t.Errorf("Expected a full store to still replace values, got %v", err)
+	}
+	if entries := store.List(ctx); len(entries) != 2 || entries[0].Value != "replaced" {
+		t.Errorf("Unexpected entries: %+v", entries)
+	}
+}
+
+func TestStore_ReleasedWithSession(t *testing.T) {
+	store := NewStore(Options{MaxTotalEntries: 2})
+	h := newTestHandler(t, store)
+	h.OnSessionClose(store.Release)
+	anonymous, anonymousSession := connect(h, "anonymous")
+	agent, agentSession := connect(h, "agent")
+	agentSession.SetPrincipal("agent")
+
+	store.Set(anonymous, "notes", "draft", "text/plain")
+	store.Set(agent, "plan", "step 1", "text/plain")
+	if err := store.Set(agent, "more", "step 2", "text/plain"); err == nil {
+		t.Error("Expected MaxTotalEntries to apply to all clients together")
+	}
+
+	h.CloseSession(anonymousSession)
+	h.CloseSession(agentSession)
+	if _, exists := store.Get(anonymous, "notes"); exists {
+		t.Error("Expected the entries of a closed session to be dropped")
+	}
+	if _, exists := store.Get(agent, "plan"); !exists {
+		t.Error("Expected the entries of credentials to outlive the session")
+	}
+	if err := store.Set(agent, "more", "step 2", "text/plain"); err != nil {
+		t.Errorf("Expected released entries to free room, got %v", err)
+	}
+
+	sized := NewStore(Options{MaxTotalBytes: 8})
+	sized.Set(anonymous, "a", "1234", "text/plain")
+	if err := sized.Set(agent, "b", "12345", "text/plain"); err == nil {
+		t.Error("Expected MaxTotalBytes to apply to all clients together")
+	}
+	if err := sized.Set(anonymous, "a", "12345678", "text/plain"); err != nil {
+		t.Errorf("Expected replacing a value to count only the new size, got %v", err)
+	}
+}
@@ -0,0 +1,131 @@
+package scratchpad
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/chongliujia/mcp-go-template/pkg/mcp"
+)
+
+// kvStoreArgs are the arguments of the kv_store tool. A string value is
+// stored as text, anything else as JSON.
+type kvStoreArgs struct {
+	Operation string      `json:"operation" enum:"set,get,delete,list"`
+	Key       string      `json:"key,omitempty"`
+	Value     interface{} `json:"value,omitempty"`
+}
+
+// Tool is the kv_store tool writing to and reading from a Store
+type Tool struct {
+	definition *mcp.Tool
+	store      *Store
+}
+
+// NewTool creates the kv_store tool for store
+func NewTool(store *Store) *Tool {
+	return &Tool{
+		definition: &mcp.Tool{
+			Name:        "kv_store",
+			Description: "Keeps notes between tool calls in a key-value scratchpad of your own: set, get, delete or list entries. Each entry is also readable as the resource kv://<key>, and subscribers are told when it changes.",
+			Category:    mcp.ToolCategoryResearch,
+			Tags:        []string{"memory", "notes"},
+			InputSchema: mcp.ToolSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"operation": map[string]interface{}{
+						"type":        "string",
+						"description": "Operation to perform",
+						"enum":        []string{"set", "get", "delete", "list"},
+					},
+					"key": map[string]interface{}{
+						"type":        "string",
+						"description": "Entry key, such as findings/pricing; letters, digits and ._~/- (not needed for list)",
+						"pattern":     validKey.String(),
+					},
+					"value": map[string]interface{}{
+						"description": "Value to store (set only); strings are kept as text, other values as JSON",
+					},
+				},
+				Required: []string{"operation"},
+			},
+		},
+		store: store,
+	}
+}
+
+// Definition returns the tool definition
+func (t *Tool) Definition() *mcp.Tool {
+	return t.definition
+}
+
+// Execute performs the requested operation
+func (t *Tool) Execute(ctx context.Context, params map[string]interface{}) (*mcp.CallToolResult, error) {
+	var args kvStoreArgs
+	if err := mcp.BindArguments(params, &args); err != nil {
+		return kvError(fmt.Sprintf("Error: %v", err)), nil
+	}
+	if args.Operation != "list" && args.Key == "" {
+		return kvError(fmt.Sprintf("Error: key is required for %s", args.Operation)), nil
+	}
+
+	switch args.Operation {
+	case "set":
+		if args.Value == nil {
+			return kvError("Error: value is required for set"), nil
+		}
+		value, mimeType, err := encodeValue(args.Value)
+		if err != nil {
+			return kvError(fmt.Sprintf("Error: %v", err)), nil
+		}
+		if err := t.store.Set(ctx, args.Key, value, mimeType); err != nil {
+			return kvError(fmt.Sprintf("Error: %v", err)), nil
+		}
+		entry, _ := t.store.Get(ctx, args.Key)
+		return mcp.NewStructuredResult(fmt.Sprintf("Stored %s (%d bytes) as %s%s", args.Key, len(value), URIPrefix, args.Key), entry), nil
+
+	case "get":
+		entry, exists := t.store.Get(ctx, args.Key)
+		if !exists {
+			return kvError(fmt.Sprintf("Error: no entry for key '%s'", args.Key)), nil
+		}
+		return mcp.NewStructuredResult(entry.Value, entry), nil
+
+	case "delete":
+		if !t.store.Delete(ctx, args.Key) {
+			return kvError(fmt.Sprintf("Error: no entry for key '%s'", args.Key)), nil
+		}
+		return mcp.NewStructuredResult(fmt.Sprintf("Deleted %s", args.Key), map[string]interface{}{"key": args.Key, "deleted": true}), nil
+
+	default:
+		entries := t.store.List(ctx)
+		keys := make([]string, len(entries))
+		for i, entry := range entries {
+			keys[i] = entry.Key
+		}
+		return mcp.NewStructuredResult(fmt.Sprintf("%d entries: %v", len(keys), keys), map[string]interface{}{"keys": keys, "count": len(keys)}), nil
+	}
+}
+
+// encodeValue stores strings as text and other values as JSON
+func encodeValue(value interface{}) (string, string, error) {
+	if text, ok := value.(string); ok {
+		return text, "text/plain", nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", "", fmt.Errorf("value cannot be stored: %w", err)
+	}
+	return string(data), "application/json", nil
+}
+
+// kvError creates an error result for the kv_store tool
+func kvError(message string) *mcp.CallToolResult {
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{{
+			Type: "text",
+			Text: message,
+		}},
+		IsError: true,
+	}
+}
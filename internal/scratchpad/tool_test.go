@@ -0,0 +1,47 @@
+package scratchpad
+
+import (
+	"context"
+	"testing"
+)
+
+func TestTool_Execute(t *testing.T) {
+	tool := NewTool(NewStore(Options{}))
+	ctx := context.Background()
+
+	result, err := tool.Execute(ctx, map[string]interface{}{
+		"operation": "set",
+		"key":       "sources",
+		"value":     []interface{}{"https://example.com", 3},
+	})
+	if err != nil || result.IsError {
+		t.Fatalf("Expected set to succeed, got %v %+v", err, result)
+	}
+
+	result, _ = tool.Execute(ctx, map[string]interface{}{"operation": "get", "key": "sources"})
+	entry := result.StructuredContent.(Entry)
+	if entry.Value != `["https://example.com",3]` || entry.MimeType != "application/json" {
+		t.Errorf("Expected non-string values stored as JSON, got %+v", entry)
+	}
+
+	result, _ = tool.Execute(ctx, map[string]interface{}{"operation": "list"})
+	if keys := result.StructuredContent.(map[string]interface{})["keys"].([]string); len(keys) != 1 || keys[0] != "sources" {
+		t.Errorf("Unexpected keys: %v", keys)
+	}
+
+	for _, params := range []map[string]interface{}{
+		{"operation": "get"},
+		{"operation": "get", "key": "missing"},
+		{"operation": "set", "key": "empty"},
+		{"operation": "rename", "key": "sources"},
+	} {
+		if result, _ := tool.Execute(ctx, params); !result.IsError {
+			t.Errorf("Expected %v to fail", params)
+		}
+	}
+
+	result, _ = tool.Execute(ctx, map[string]interface{}{"operation": "delete", "key": "sources"})
+	if result.IsError {
+		t.Errorf("Expected delete to succeed, got %+v", result)
+	}
+}
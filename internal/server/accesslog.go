@@ -0,0 +1,106 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"math/rand"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/chongliujia/mcp-go-template/internal/config"
+	"github.com/chongliujia/mcp-go-template/pkg/mcp"
+	"github.com/chongliujia/mcp-go-template/pkg/utils"
+)
+
+// accessLog writes one structured line per MCP request
+type accessLog struct {
+	enabled    bool
+	fields     map[string]bool
+	sampleRate float64
+}
+
+// newAccessLog builds the access log from logging.access_log
+func newAccessLog(cfg config.AccessLogConfig) *accessLog {
+	fields := make(map[string]bool, len(cfg.Fields))
+	for _, field := range cfg.Fields {
+		fields[field] = true
+	}
+	return &accessLog{
+		enabled:    cfg.Enabled,
+		fields:     fields,
+		sampleRate: cfg.SampleRate,
+	}
+}
+
+// sampled reports whether a request's line is written. Failed requests are
+// always logged so sampling never hides errors.
+func (a *accessLog) sampled(errorCode int) bool {
+	if errorCode != 0 || a.sampleRate >= 1 {
+		return true
+	}
+	return rand.Float64() < a.sampleRate
+}
+
+// logRequest records a handled request and its response
+func (s *Server) logRequest(ctx context.Context, message, response *mcp.Message, start time.Time) {
+	a := s.accessLog
+	if !a.enabled {
+		return
+	}
+
+	errorCode := 0
+	if response != nil && response.Error != nil {
+		errorCode = response.Error.Code
+	}
+	if !a.sampled(errorCode) {
+		return
+	}
+
+	entry := logrus.Fields{}
+	if a.fields["method"] {
+		entry["method"] = message.Method
+	}
+	if a.fields["id"] {
+		entry["id"] = message.ID
+	}
+	if a.fields["correlation_id"] {
+		if id := utils.CorrelationID(ctx); id != "" {
+			entry["correlation_id"] = id
+		}
+	}
+	if a.fields["tool"] && message.Method == "tools/call" {
+		entry["tool"] = mcp.MessageTarget(message)
+	}
+	if a.fields["session_id"] {
+		if session, ok := mcp.SessionFromContext(ctx); ok {
+			entry["session_id"] = session.ID()
+		}
+	}
+	if a.fields["duration_ms"] {
+		entry["duration_ms"] = float64(time.Since(start).Microseconds()) / 1000
+	}
+	if a.fields["result_bytes"] {
+		entry["result_bytes"] = resultBytes(response)
+	}
+	if a.fields["error_code"] {
+		entry["error_code"] = errorCode
+	}
+
+	s.logger.WithFields(entry).Info("MCP request")
+}
+
+// resultBytes returns the encoded size of a response's result
+func resultBytes(response *mcp.Message) int {
+	if response == nil || response.Result == nil {
+		return 0
+	}
+	if raw, ok := response.Result.(json.RawMessage); ok {
+		return len(raw)
+	}
+	data, err := json.Marshal(response.Result)
+	if err != nil {
+		return 0
+	}
+	return len(data)
+}
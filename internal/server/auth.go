@@ -0,0 +1,174 @@
+package server
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"github.com/chongliujia/mcp-go-template/pkg/mcp"
+)
+
+// APIKeyHeader carries the client's API key
+const APIKeyHeader = "X-API-Key"
+
+// apiKeyQueryParam carries the API key for clients that cannot set headers,
+// such as browser WebSocket connections
+const apiKeyQueryParam = "api_key"
+
+// bearerPrefix introduces a bearer token in the Authorization header
+const bearerPrefix = "Bearer "
+
+// authEnabled reports whether API keys or bearer tokens are required
+func (s *Server) authEnabled() bool {
+	return len(s.config.Security.APIKeys) > 0 || s.jwt != nil
+}
+
+// validAPIKey reports whether key is one of the configured API keys
+func (s *Server) validAPIKey(key string) bool {
+	if key == "" {
+		return false
+	}
+
+	valid := false
+	for _, candidate := range s.config.Security.APIKeys {
+		// Compare every key so timing does not reveal which one matched
+		if subtle.ConstantTimeCompare([]byte(key), []byte(candidate)) == 1 {
+			valid = true
+		}
+	}
+	return valid
+}
+
+// requestAPIKey returns the API key sent with an HTTP request
+func requestAPIKey(r *http.Request) string {
+	if key := r.Header.Get(APIKeyHeader); key != "" {
+		return key
+	}
+	return r.URL.Query().Get(apiKeyQueryParam)
+}
+
+// messageAPIKey returns the API key sent in a message's params._meta.apiKey,
+// which lets clients authenticate with their first MCP message
+func messageAPIKey(message *mcp.Message) string {
+	var params struct {
+		Meta struct {
+			APIKey string `json:"apiKey"`
+		} `json:"_meta"`
+	}
+	if err := message.UnmarshalParams(&params); err != nil {
+		return ""
+	}
+	return params.Meta.APIKey
+}
+
+// messagesAuthenticated reports whether any message carries a valid API key
+// and returns the principal of the first one that does
+func (s *Server) messagesAuthenticated(messages []*mcp.Message) (string, bool) {
+	for _, message := range messages {
+		if key := messageAPIKey(message); s.validAPIKey(key) {
+			return apiKeyPrincipal(key), true
+		}
+	}
+	return "", false
+}
+
+// apiKeyPrincipal identifies the client holding an API key without keeping
+// the key itself
+func apiKeyPrincipal(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return "key:" + hex.EncodeToString(sum[:8])
+}
+
+// tokenPrincipal identifies the client a bearer token was issued to
+func tokenPrincipal(claims mcp.Claims) string {
+	if sub, ok := claims["sub"].(string); ok && sub != "" {
+		return "sub:" + sub
+	}
+	return ""
+}
+
+// authResult is the outcome of authenticating an HTTP request
+type authResult struct {
+	authenticated bool
+	// principal identifies the authenticated client across connections
+	principal string
+	// claims are set when the client presented a valid bearer token
+	claims mcp.Claims
+}
+
+// authenticateRequest checks the credentials on an HTTP request: a bearer
+// token when JWT validation is configured, or an API key. It reports
+// whether the request is authenticated and, when credentials were sent but
+// are wrong, rejects the request with 401 and returns ok=false.
+func (s *Server) authenticateRequest(w http.ResponseWriter, r *http.Request) (result authResult, ok bool) {
+	if !s.authEnabled() {
+		return authResult{authenticated: true}, true
+	}
+
+	if token := bearerToken(r); token != "" && s.jwt != nil {
+		claims, err := s.jwt.Validate(r.Context(), token)
+		if err != nil {
+			s.logger.WithError(err).Debug("Bearer token rejected")
+			s.rejectUnauthenticated(w, r)
+			return authResult{}, false
+		}
+		return authResult{
+			authenticated: true,
+			principal:     tokenPrincipal(claims),
+			claims:        mcp.Claims(claims),
+		}, true
+	}
+
+	key := requestAPIKey(r)
+	if key == "" {
+		return authResult{}, true
+	}
+	if !s.validAPIKey(key) {
+		s.rejectUnauthenticated(w, r)
+		return authResult{}, false
+	}
+	return authResult{authenticated: true, principal: apiKeyPrincipal(key)}, true
+}
+
+// bearerToken returns the token from an "Authorization: Bearer" header
+func bearerToken(r *http.Request) string {
+	header := r.Header.Get("Authorization")
+	if len(header) > len(bearerPrefix) && strings.EqualFold(header[:len(bearerPrefix)], bearerPrefix) {
+		return strings.TrimSpace(header[len(bearerPrefix):])
+	}
+	return ""
+}
+
+// requireAPIKey wraps an HTTP endpoint so it needs valid credentials
+func (s *Server) requireAPIKey(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		result, ok := s.authenticateRequest(w, r)
+		if !ok {
+			return
+		}
+		if !result.authenticated {
+			s.rejectUnauthenticated(w, r)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// rejectUnauthenticated answers an HTTP request with 401
+func (s *Server) rejectUnauthenticated(w http.ResponseWriter, r *http.Request) {
+	s.logger.WithField("client_ip", s.getClientIP(r)).Warn("Connection rejected: missing or invalid credentials")
+	if s.jwt != nil {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="mcp"`)
+	} else {
+		w.Header().Set("WWW-Authenticate", `ApiKey header="`+APIKeyHeader+`"`)
+	}
+	http.Error(w, "Unauthorized", http.StatusUnauthorized)
+}
+
+// unauthorizedResponse rejects a message from an unauthenticated client
+func unauthorizedResponse(message *mcp.Message) *mcp.Message {
+	info := mcp.UnauthorizedError("missing or invalid credentials")
+	return mcp.NewErrorResponse(message.ID, info.Code, info.Message, info.Data)
+}
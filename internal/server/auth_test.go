@@ -0,0 +1,62 @@
+package server
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/chongliujia/mcp-go-template/internal/config"
+)
+
+// send makes a request without a body and returns its status
+func send(t *testing.T, method, url string, header http.Header) int {
+	req, _ := http.NewRequest(method, url, nil)
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s failed: %v", method, url, err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+func TestAdminTools_RequireCredentials(t *testing.T) {
+	open := newTestServer(t, config.DefaultConfig())
+	if status := send(t, http.MethodPost, open.URL+"/admin/tools/echo/disable", nil); status != http.StatusForbidden {
+		t.Errorf("Expected switching tools without configured credentials to fail with 403, got %d", status)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Security.APIKeys = []string{"admin-key"}
+	ts := newTestServer(t, cfg)
+	if status := send(t, http.MethodGet, ts.URL+"/admin/tools", nil); status != http.StatusUnauthorized {
+		t.Errorf("Expected listing tools without a key to fail with 401, got %d", status)
+	}
+	if status := send(t, http.MethodGet, ts.URL+"/admin/tools", http.Header{APIKeyHeader: {"wrong"}}); status != http.StatusUnauthorized {
+		t.Errorf("Expected a wrong key to fail with 401, got %d", status)
+	}
+	if status := send(t, http.MethodGet, ts.URL+"/admin/tools", http.Header{APIKeyHeader: {"admin-key"}}); status != http.StatusOK {
+		t.Errorf("Expected a valid key to list tools, got %d", status)
+	}
+}
+
+func TestAllowedIPs_RejectOtherClients(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Security.APIKeys = []string{"admin-key"}
+	cfg.Security.AllowedIPs = []string{"10.0.0.0/8"}
+	ts := newTestServer(t, cfg)
+
+	key := http.Header{APIKeyHeader: {"admin-key"}}
+	for _, path := range []string{"/mcp", "/admin/tools", "/admin/tools/echo/disable"} {
+		if status := send(t, http.MethodPost, ts.URL+path, key); status != http.StatusForbidden {
+			t.Errorf("Expected %s to fail with 403 outside the allowed IPs, got %d", path, status)
+		}
+	}
+
+	cfg.Security.AllowedIPs = []string{"127.0.0.1", "::1"}
+	allowed := newTestServer(t, cfg)
+	if status := send(t, http.MethodGet, allowed.URL+"/admin/tools", key); status != http.StatusOK {
+		t.Errorf("Expected an allowed IP to reach the admin API, got %d", status)
+	}
+}
@@ -0,0 +1,461 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/sirupsen/logrus"
+
+	"github.com/chongliujia/mcp-go-template/internal/auth"
+	"github.com/chongliujia/mcp-go-template/internal/state"
+	"github.com/chongliujia/mcp-go-template/pkg/mcp"
+	"github.com/chongliujia/mcp-go-template/pkg/utils"
+)
+
+// writeQueueSize is the number of outbound messages buffered per connection
+const writeQueueSize = 256
+
+// errConnectionClosed is returned when writing to a closed connection
+var errConnectionClosed = errors.New("connection closed")
+
+// errTooManyConnections is returned when server.max_connections is reached
+var errTooManyConnections = errors.New("too many connections")
+
+// ConnectionInfo describes a connected client for broadcast filtering
+type ConnectionInfo struct {
+	RemoteAddr   string
+	ClientIP     string
+	ClientInfo   mcp.ClientInfo
+	Capabilities mcp.ClientCapabilities
+	Initialized  bool
+	// Session is the client's MCP session, which holds its subscriptions
+	Session *mcp.Session
+}
+
+// ConnectionFilter selects which connections receive a broadcast
+type ConnectionFilter func(info ConnectionInfo) bool
+
+// outbound is a queued write: a message, or a close frame that is sent once
+// the messages queued before it have been written
+type outbound struct {
+	message     *mcp.Message
+	closeCode   int
+	closeReason string
+}
+
+// connection is a live client connection. Every write goes through the
+// connection's queue and is performed by its writer goroutine, so the
+// WebSocket only ever has one writer and a slow client never blocks writes
+// to other clients. The writer also sends keepalive pings and reaps the
+// connection once it has been idle too long.
+type connection struct {
+	conn      *websocket.Conn
+	queue     chan outbound
+	done      chan struct{}
+	closeOnce sync.Once
+	keepalive keepalive
+	session   *mcp.Session
+
+	// inflight bounds concurrent requests; nil means unlimited
+	inflight chan struct{}
+
+	// authenticated and principal are only accessed by the connection's reader
+	authenticated bool
+	principal     string
+
+	// lastActivity is the UnixNano time of the last message from the client
+	lastActivity atomic.Int64
+
+	infoMu sync.RWMutex
+	info   ConnectionInfo
+}
+
+// addConnection tracks a new client connection and starts its writer. It
+// fails with errTooManyConnections once server.max_connections is reached.
+func (s *Server) addConnection(conn *websocket.Conn, clientIP string) (*connection, error) {
+	id, err := newSessionID()
+	if err != nil {
+		return nil, err
+	}
+
+	c := &connection{
+		conn:      conn,
+		session:   mcp.NewSession(id),
+		queue:     make(chan outbound, writeQueueSize),
+		done:      make(chan struct{}),
+		keepalive: s.keepalive(),
+	}
+	c.info = ConnectionInfo{
+		RemoteAddr: conn.RemoteAddr().String(),
+		ClientIP:   clientIP,
+		Session:    c.session,
+	}
+	c.session.SetNotifier(func(notification *mcp.Message) {
+		if !c.trySend(notification) {
+			s.logger.WithFields(logrus.Fields{
+				"client": c.conn.RemoteAddr(),
+				"method": notification.Method,
+			}).Debug("Dropped notification for slow or closed client")
+		}
+	})
+	c.touch()
+	if limit := s.config.Server.MaxInflightRequests; limit > 0 {
+		c.inflight = make(chan struct{}, limit)
+	}
+
+	s.connMu.Lock()
+	if limit := s.config.Server.MaxConnections; limit > 0 && len(s.conns) >= limit {
+		s.connMu.Unlock()
+		return nil, errTooManyConnections
+	}
+	s.conns[c] = struct{}{}
+	s.connMu.Unlock()
+
+	go s.writeLoop(c)
+	return c, nil
+}
+
+// removeConnection stops tracking a client connection and stops its writer
+func (s *Server) removeConnection(c *connection) {
+	s.connMu.Lock()
+	delete(s.conns, c)
+	s.connMu.Unlock()
+
+	c.close()
+	s.closeSession(c.session)
+}
+
+// closeSession tells the handler that a client's session ended
+func (s *Server) closeSession(session *mcp.Session) {
+	if closer, ok := s.handler.(mcp.SessionCloser); ok {
+		closer.CloseSession(session)
+	}
+}
+
+// close stops the connection's writer and closes the socket
+func (c *connection) close() {
+	c.closeOnce.Do(func() {
+		close(c.done)
+		c.conn.Close()
+	})
+}
+
+// writeLoop writes queued messages and keepalive pings until the
+// connection closes
+func (s *Server) writeLoop(c *connection) {
+	var tick <-chan time.Time
+	if interval := c.keepalive.tickInterval(); interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	// MCP pings go out at most once per interval while the client is idle
+	var lastMCPPing time.Time
+	mcpPings := 0
+
+	for {
+		select {
+		case out := <-c.queue:
+			if out.message == nil {
+				c.writeClose(out.closeCode, out.closeReason)
+				c.close()
+				return
+			}
+			c.conn.SetWriteDeadline(time.Now().Add(c.keepalive.writeDeadline()))
+			if err := s.sendMessage(c.conn, out.message); err != nil {
+				s.logger.WithError(err).WithField("client", c.conn.RemoteAddr()).Error("Failed to send message")
+				c.close()
+				return
+			}
+		case <-tick:
+			if c.keepalive.idleTimeout > 0 && c.idleFor() >= c.keepalive.idleTimeout {
+				s.logger.WithField("client", c.conn.RemoteAddr()).Info("Closing idle WebSocket connection")
+				c.writeClose(websocket.CloseGoingAway, "idle timeout")
+				c.close()
+				return
+			}
+			if c.keepalive.pingInterval > 0 {
+				if err := c.keepalive.ping(c.conn); err != nil {
+					s.logger.WithError(err).WithField("client", c.conn.RemoteAddr()).Warn("Failed to ping client")
+					c.close()
+					return
+				}
+			}
+			if interval := c.keepalive.mcpPingInterval; interval > 0 && c.idleFor() >= interval && time.Since(lastMCPPing) >= interval {
+				mcpPings++
+				lastMCPPing = time.Now()
+				c.conn.SetWriteDeadline(time.Now().Add(c.keepalive.writeDeadline()))
+				if err := s.sendMessage(c.conn, mcpPing(mcpPings)); err != nil {
+					s.logger.WithError(err).WithField("client", c.conn.RemoteAddr()).Warn("Failed to ping client")
+					c.close()
+					return
+				}
+			}
+		case <-c.done:
+			return
+		}
+	}
+}
+
+// acquire reserves an in-flight request slot, reporting false if the
+// connection is already at its limit
+func (c *connection) acquire() bool {
+	if c.inflight == nil {
+		return true
+	}
+	select {
+	case c.inflight <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+// release frees a slot reserved by acquire
+func (c *connection) release() {
+	if c.inflight != nil {
+		<-c.inflight
+	}
+}
+
+// writeClose sends a close frame telling the client why it is being disconnected
+func (c *connection) writeClose(code int, reason string) {
+	message := websocket.FormatCloseMessage(code, reason)
+	c.conn.WriteControl(websocket.CloseMessage, message, time.Now().Add(c.keepalive.writeDeadline()))
+}
+
+// send queues a message for the connection, waiting for queue space
+func (c *connection) send(message *mcp.Message) error {
+	return c.enqueue(outbound{message: message})
+}
+
+// closeAfterPending queues a close frame behind any pending messages so the
+// client receives its outstanding responses before the connection closes
+func (c *connection) closeAfterPending(code int, reason string) {
+	c.enqueue(outbound{closeCode: code, closeReason: reason})
+}
+
+// waitClosed waits, up to the write deadline, for the writer to flush a
+// queued close frame
+func (c *connection) waitClosed() {
+	select {
+	case <-c.done:
+	case <-time.After(c.keepalive.writeDeadline()):
+	}
+}
+
+// enqueue adds a write to the connection's queue, waiting for space
+func (c *connection) enqueue(out outbound) error {
+	select {
+	case c.queue <- out:
+		return nil
+	case <-c.done:
+		return errConnectionClosed
+	}
+}
+
+// trySend queues a message without waiting; it reports false if the
+// connection's queue is full or the connection has closed
+func (c *connection) trySend(message *mcp.Message) bool {
+	select {
+	case <-c.done:
+		return false
+	default:
+	}
+
+	select {
+	case c.queue <- outbound{message: message}:
+		return true
+	default:
+		return false
+	}
+}
+
+// snapshot returns a copy of what is known about the client
+func (c *connection) snapshot() ConnectionInfo {
+	c.infoMu.RLock()
+	defer c.infoMu.RUnlock()
+	return c.info
+}
+
+// recordInitialize captures the client's identity and capabilities from its
+// initialize request
+func (c *connection) recordInitialize(message *mcp.Message) {
+	var params mcp.InitializeParams
+	if err := message.UnmarshalParams(&params); err != nil {
+		return
+	}
+
+	c.infoMu.Lock()
+	defer c.infoMu.Unlock()
+	c.info.ClientInfo = params.ClientInfo
+	c.info.Capabilities = params.Capabilities
+	c.info.Initialized = true
+}
+
+// Broadcast sends a notification to every WebSocket and Streamable HTTP
+// client connected to this replica and returns the number it was queued for
+func (s *Server) Broadcast(notification *mcp.Message) int {
+	return s.BroadcastFiltered(notification, nil)
+}
+
+// BroadcastFiltered sends a notification to the connected clients accepted
+// by filter; a nil filter accepts every client. Clients whose write queue
+// is full miss the notification rather than delaying the others.
+func (s *Server) BroadcastFiltered(notification *mcp.Message, filter ConnectionFilter) int {
+	s.connMu.RLock()
+	conns := make([]*connection, 0, len(s.conns))
+	for c := range s.conns {
+		conns = append(conns, c)
+	}
+	s.connMu.RUnlock()
+
+	delivered := 0
+	for _, c := range conns {
+		info := c.snapshot()
+		if filter != nil && !filter(info) {
+			continue
+		}
+		if c.trySend(notification) {
+			delivered++
+		} else {
+			s.logger.WithFields(logrus.Fields{
+				"client": info.RemoteAddr,
+				"method": notification.Method,
+			}).Warn("Dropped notification for slow or closed client")
+		}
+	}
+
+	s.sessionMu.RLock()
+	sessions := make([]*streamSession, 0, len(s.sessions))
+	for _, session := range s.sessions {
+		sessions = append(sessions, session)
+	}
+	s.sessionMu.RUnlock()
+
+	for _, session := range sessions {
+		if filter != nil && !filter(session.snapshot()) {
+			continue
+		}
+		if _, err := session.record(notification); err != nil {
+			s.logger.WithError(err).WithFields(logrus.Fields{
+				"session": session.id,
+				"method":  notification.Method,
+			}).Warn("Failed to queue notification")
+			continue
+		}
+		delivered++
+	}
+	return delivered
+}
+
+// InitializedClients accepts only clients that have completed initialize
+func InitializedClients(info ConnectionInfo) bool {
+	return info.Initialized
+}
+
+// ClientNamed accepts clients that identified themselves with name
+func ClientNamed(name string) ConnectionFilter {
+	return func(info ConnectionInfo) bool {
+		return info.ClientInfo.Name == name
+	}
+}
+
+// SubscribedTo accepts clients subscribed to updates of the resource at uri
+func SubscribedTo(uri string) ConnectionFilter {
+	return func(info ConnectionInfo) bool {
+		return info.Session != nil && info.Session.IsSubscribed(uri)
+	}
+}
+
+// ClientSupportsSampling accepts clients that advertise the sampling capability
+func ClientSupportsSampling(info ConnectionInfo) bool {
+	return info.Capabilities.Sampling != nil
+}
+
+// SetJWTValidator enables bearer token authentication. It must be called
+// before Start.
+func (s *Server) SetJWTValidator(validator *auth.JWTValidator) {
+	s.jwt = validator
+}
+
+// stateTimeout bounds each call to the state backend, so a slow backend
+// delays requests and notifications instead of blocking them
+const stateTimeout = 2 * time.Second
+
+// SetStateStore replaces the server's state backend. With a shared backend
+// such as Redis, Streamable HTTP sessions and rate limits are shared
+// between replicas. It must be called before Start.
+func (s *Server) SetStateStore(store state.Store) {
+	s.state = store
+}
+
+// SetLogger replaces the global logger the server logs through. It must be
+// called before Start.
+func (s *Server) SetLogger(logger utils.Logger) {
+	s.logger = logger
+}
+
+// Notify delivers a notification raised by the handler. Resource updates
+// are published to every replica and reach the clients subscribed to the
+// resource; other notifications go to this replica's initialized clients.
+func (s *Server) Notify(notification *mcp.Message) {
+	if notification.Method != "notifications/resources/updated" {
+		s.BroadcastFiltered(notification, InitializedClients)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), stateTimeout)
+	defer cancel()
+	if err := s.publishResourceUpdate(ctx, notification); err != nil {
+		s.logger.WithError(err).Warn("Failed to publish resource update")
+	}
+}
+
+// PublishResourceUpdate notifies the clients subscribed to a resource on
+// every replica that it changed
+func (s *Server) PublishResourceUpdate(ctx context.Context, uri string) error {
+	return s.publishResourceUpdate(ctx, mcp.NewNotification("notifications/resources/updated", map[string]interface{}{
+		"uri": uri,
+	}))
+}
+
+// publishResourceUpdate sends a resource update notification to every replica
+func (s *Server) publishResourceUpdate(ctx context.Context, notification *mcp.Message) error {
+	payload, err := json.Marshal(notification)
+	if err != nil {
+		return fmt.Errorf("failed to marshal resource update: %w", err)
+	}
+
+	return s.state.Publish(ctx, state.ResourceUpdatesChannel, payload)
+}
+
+// relayResourceUpdates forwards resource updates published by any replica to
+// the clients connected to this one until ctx is done
+func (s *Server) relayResourceUpdates(ctx context.Context) error {
+	updates, err := s.state.Subscribe(ctx, state.ResourceUpdatesChannel)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to resource updates: %w", err)
+	}
+
+	go func() {
+		for payload := range updates {
+			var notification mcp.Message
+			var params mcp.SubscribeParams
+			if err := json.Unmarshal(payload, &notification); err != nil || notification.UnmarshalParams(&params) != nil {
+				s.logger.WithError(err).Warn("Ignoring malformed resource update")
+				continue
+			}
+			s.BroadcastFiltered(&notification, SubscribedTo(params.URI))
+		}
+	}()
+
+	return nil
+}
@@ -0,0 +1,68 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// correlationMetaKey names the correlation ID in a response's _meta. The
+// field is experimental and lets client-side issues be matched to server
+// logs.
+const correlationMetaKey = "correlationId"
+
+// withCorrelationMeta adds the correlation ID to the _meta object of an
+// encoded result, leaving results that are not JSON objects unchanged
+func withCorrelationMeta(data []byte, correlationID string) []byte {
+	if !bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		return data
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return data
+	}
+
+	meta := map[string]json.RawMessage{}
+	if existing, ok := fields["_meta"]; ok {
+		if err := json.Unmarshal(existing, &meta); err != nil {
+			return data
+		}
+	}
+	id, _ := json.Marshal(correlationID)
+	meta[correlationMetaKey] = id
+
+	encodedMeta, err := json.Marshal(meta)
+	if err != nil {
+		return data
+	}
+	fields["_meta"] = encodedMeta
+
+	encoded, err := json.Marshal(fields)
+	if err != nil {
+		return data
+	}
+	return encoded
+}
+
+// withCorrelationData adds the correlation ID to an error's data under
+// _meta. Data that is neither empty nor an object is returned unchanged.
+func withCorrelationData(data interface{}, correlationID string) interface{} {
+	if correlationID == "" {
+		return data
+	}
+
+	meta := map[string]interface{}{correlationMetaKey: correlationID}
+	switch d := data.(type) {
+	case nil:
+		return map[string]interface{}{"_meta": meta}
+	case map[string]interface{}:
+		merged := make(map[string]interface{}, len(d)+1)
+		for key, value := range d {
+			merged[key] = value
+		}
+		merged["_meta"] = meta
+		return merged
+	default:
+		return data
+	}
+}
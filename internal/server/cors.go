@@ -0,0 +1,63 @@
+package server
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// withCORS applies the server.cors policy to every HTTP endpoint and
+// answers preflight requests
+func (s *Server) withCORS(next http.Handler) http.Handler {
+	cors := s.config.Server.CORS
+	if !cors.Enabled {
+		return next
+	}
+
+	methods := strings.Join(cors.AllowedMethods, ", ")
+	headers := strings.Join(cors.AllowedHeaders, ", ")
+	exposed := strings.Join(cors.ExposedHeaders, ", ")
+	wildcard := slices.Contains(cors.AllowedOrigins, "*")
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" || !originAllowed(origin, cors.AllowedOrigins) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		h := w.Header()
+		h.Add("Vary", "Origin")
+		// Credentials are never combined with a wildcard origin, so any
+		// site cannot make credentialed requests
+		if wildcard {
+			h.Set("Access-Control-Allow-Origin", "*")
+		} else if cors.AllowCredentials {
+			h.Set("Access-Control-Allow-Origin", origin)
+			h.Set("Access-Control-Allow-Credentials", "true")
+		} else {
+			h.Set("Access-Control-Allow-Origin", origin)
+		}
+		if exposed != "" {
+			h.Set("Access-Control-Expose-Headers", exposed)
+		}
+
+		// Preflight requests are answered here without reaching the endpoint
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			h.Add("Vary", "Access-Control-Request-Method")
+			h.Add("Vary", "Access-Control-Request-Headers")
+			h.Set("Access-Control-Allow-Methods", methods)
+			if headers != "" {
+				h.Set("Access-Control-Allow-Headers", headers)
+			}
+			if cors.MaxAge > 0 {
+				h.Set("Access-Control-Max-Age", strconv.Itoa(cors.MaxAge))
+			}
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,93 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/chongliujia/mcp-go-template/pkg/mcp"
+)
+
+// cancelGrace is how long cancelled requests get to return once the drain
+// deadline has passed
+const cancelGrace = 5 * time.Second
+
+// beginRequest registers an in-flight request. It reports false once the
+// server has started shutting down.
+func (s *Server) beginRequest() bool {
+	s.drainMu.Lock()
+	defer s.drainMu.Unlock()
+
+	if s.draining {
+		return false
+	}
+	s.inflight.Add(1)
+	return true
+}
+
+// requestContext derives the context for one request, which is cancelled
+// when the server abandons in-flight requests during shutdown
+func (s *Server) requestContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+	stop := context.AfterFunc(s.requests, cancel)
+	return ctx, func() {
+		stop()
+		cancel()
+	}
+}
+
+// drain stops accepting requests and waits for in-flight ones until ctx is
+// done, then cancels whatever is still running. WebSocket clients are sent a
+// close frame once their pending responses have been written.
+func (s *Server) drain(ctx context.Context) {
+	s.drainMu.Lock()
+	if s.draining {
+		s.drainMu.Unlock()
+		return
+	}
+	s.draining = true
+	close(s.shutdown)
+	s.drainMu.Unlock()
+
+	finished := make(chan struct{})
+	go func() {
+		s.inflight.Wait()
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+		s.logger.Info("All in-flight requests completed")
+	case <-ctx.Done():
+		s.logger.Warn("Drain deadline exceeded, cancelling in-flight requests")
+		s.cancelRequests()
+
+		select {
+		case <-finished:
+		case <-time.After(cancelGrace):
+			s.logger.Warn("In-flight requests did not stop after cancellation")
+		}
+	}
+	s.cancelRequests()
+
+	s.connMu.RLock()
+	conns := make([]*connection, 0, len(s.conns))
+	for c := range s.conns {
+		conns = append(conns, c)
+	}
+	s.connMu.RUnlock()
+
+	for _, c := range conns {
+		c.closeAfterPending(websocket.CloseGoingAway, "server shutting down")
+	}
+	for _, c := range conns {
+		c.waitClosed()
+		c.close()
+	}
+}
+
+// shutdownResponse rejects a request received after shutdown began
+func shutdownResponse(message *mcp.Message) *mcp.Message {
+	return mcp.NewErrorResponse(message.ID, mcp.InternalError, "server is shutting down", nil)
+}
@@ -0,0 +1,95 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/chongliujia/mcp-go-template/pkg/mcp"
+)
+
+// keepalive holds the WebSocket liveness settings; zero durations disable
+// the corresponding check
+type keepalive struct {
+	pingInterval    time.Duration
+	pongTimeout     time.Duration
+	idleTimeout     time.Duration
+	writeTimeout    time.Duration
+	mcpPingInterval time.Duration
+}
+
+// keepalive returns the server's configured WebSocket liveness settings
+func (s *Server) keepalive() keepalive {
+	ws := s.config.Server.WebSocket
+	return keepalive{
+		pingInterval:    time.Duration(ws.PingInterval) * time.Second,
+		pongTimeout:     time.Duration(ws.PongTimeout) * time.Second,
+		idleTimeout:     time.Duration(ws.IdleTimeout) * time.Second,
+		writeTimeout:    time.Duration(s.config.Server.Timeout) * time.Second,
+		mcpPingInterval: time.Duration(ws.MCPPingInterval) * time.Second,
+	}
+}
+
+// tickInterval returns how often the writer wakes up to ping and check for
+// idleness, or 0 if none of these is enabled
+func (k keepalive) tickInterval() time.Duration {
+	var interval time.Duration
+	for _, candidate := range []time.Duration{k.pingInterval, k.idleTimeout / 2, k.mcpPingInterval / 2} {
+		if candidate > 0 && (interval == 0 || candidate < interval) {
+			interval = candidate
+		}
+	}
+	return interval
+}
+
+// prepareReads sets the connection's initial read deadline and extends it
+// whenever the client answers a ping. Without pings, reads never time out,
+// which also clears any deadline inherited from the HTTP server.
+func (k keepalive) prepareReads(conn *websocket.Conn) {
+	if k.pingInterval <= 0 || k.pongTimeout <= 0 {
+		conn.SetReadDeadline(time.Time{})
+		return
+	}
+
+	conn.SetReadDeadline(time.Now().Add(k.pongTimeout))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(k.pongTimeout))
+	})
+}
+
+// ping sends a ping control frame
+func (k keepalive) ping(conn *websocket.Conn) error {
+	return conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(k.writeDeadline()))
+}
+
+// mcpPing returns the MCP ping request sent to idle clients
+func mcpPing(n int) *mcp.Message {
+	return mcp.NewRequest(fmt.Sprintf("server-ping-%d", n), "ping", nil)
+}
+
+// writeDeadline bounds how long a single write may take
+func (k keepalive) writeDeadline() time.Duration {
+	if k.writeTimeout > 0 {
+		return k.writeTimeout
+	}
+	return 10 * time.Second
+}
+
+// touch records that the client sent an MCP message
+func (c *connection) touch() {
+	c.lastActivity.Store(time.Now().UnixNano())
+}
+
+// idleFor returns how long ago the client last sent an MCP message
+func (c *connection) idleFor() time.Duration {
+	return time.Since(time.Unix(0, c.lastActivity.Load()))
+}
+
+// isTimeout reports whether err is a read deadline expiring
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
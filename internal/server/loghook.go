@@ -0,0 +1,104 @@
+package server
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/chongliujia/mcp-go-template/pkg/mcp"
+	"github.com/chongliujia/mcp-go-template/pkg/utils"
+)
+
+// logNotificationQueueSize bounds the log messages waiting to be sent to
+// clients; messages logged while it is full are not forwarded
+const logNotificationQueueSize = 256
+
+// logNotificationMethod is the notification carrying log messages
+const logNotificationMethod = "notifications/message"
+
+// clientLogHook forwards server log entries to clients that asked for them
+// with logging/setLevel
+type clientLogHook struct {
+	messages chan mcp.LoggingMessage
+}
+
+// Levels implements logrus.Hook
+func (h *clientLogHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire implements logrus.Hook. It never blocks the caller.
+func (h *clientLogHook) Fire(entry *logrus.Entry) error {
+	// Warnings about undelivered log notifications would otherwise feed
+	// back into the queue they were dropped from. Entries left out by
+	// sampling are not forwarded either.
+	if entry.Data["method"] == logNotificationMethod || utils.Suppressed(entry) {
+		return nil
+	}
+
+	data := make(map[string]interface{}, len(entry.Data)+1)
+	for key, value := range entry.Data {
+		if err, ok := value.(error); ok {
+			value = err.Error()
+		}
+		data[key] = value
+	}
+	data["message"] = entry.Message
+
+	select {
+	case h.messages <- mcp.LoggingMessage{
+		Level:  loggingLevel(entry.Level),
+		Data:   data,
+		Logger: "server",
+	}:
+	default:
+	}
+	return nil
+}
+
+// loggingLevel maps a logrus level to the protocol's logging levels
+func loggingLevel(level logrus.Level) mcp.LoggingLevel {
+	switch level {
+	case logrus.PanicLevel:
+		return mcp.LoggingLevelEmergency
+	case logrus.FatalLevel:
+		return mcp.LoggingLevelCritical
+	case logrus.ErrorLevel:
+		return mcp.LoggingLevelError
+	case logrus.WarnLevel:
+		return mcp.LoggingLevelWarning
+	case logrus.InfoLevel:
+		return mcp.LoggingLevelInfo
+	default:
+		return mcp.LoggingLevelDebug
+	}
+}
+
+// WantsLogLevel accepts clients whose logging/setLevel includes level
+func WantsLogLevel(level mcp.LoggingLevel) ConnectionFilter {
+	return func(info ConnectionInfo) bool {
+		return info.Session != nil && info.Session.LogLevel().Includes(level)
+	}
+}
+
+// forwardLogs installs a hook on the server's logger and sends its entries
+// to clients as notifications/message until ctx is done. Only loggers made
+// with utils.NewLogger take hooks.
+func (s *Server) forwardLogs(ctx context.Context) {
+	hook := &clientLogHook{messages: make(chan mcp.LoggingMessage, logNotificationQueueSize)}
+	if !utils.AddHook(s.logger, hook) {
+		s.logger.Warn("Server logs are not sent to clients: the logger is not based on logrus")
+		return
+	}
+
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case message := <-hook.messages:
+				s.BroadcastFiltered(mcp.NewNotification(logNotificationMethod, message), WantsLogLevel(message.Level))
+			}
+		}
+	}()
+}
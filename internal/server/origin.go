@@ -0,0 +1,50 @@
+package server
+
+import (
+	"net/http"
+	"path"
+	"strings"
+)
+
+// checkOrigin reports whether the request's Origin is allowed by
+// security.allowed_origins. An empty list allows every origin, and requests
+// without an Origin header (non-browser clients) are always allowed.
+func (s *Server) checkOrigin(r *http.Request) bool {
+	patterns := s.config.Security.AllowedOrigins
+	if len(patterns) == 0 {
+		return true
+	}
+
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	return originAllowed(origin, patterns)
+}
+
+// originAllowed matches origin against patterns such as
+// "https://app.example.com", "https://*.example.com", or "*"
+func originAllowed(origin string, patterns []string) bool {
+	origin = strings.ToLower(origin)
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		if pattern == "*" || pattern == origin {
+			return true
+		}
+		if matched, err := path.Match(pattern, origin); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+// checkAllowedOrigin rejects the request if its origin is not allowed
+func (s *Server) checkAllowedOrigin(w http.ResponseWriter, r *http.Request) bool {
+	if s.checkOrigin(r) {
+		return true
+	}
+
+	s.logger.WithField("origin", r.Header.Get("Origin")).Warn("Connection rejected: origin not allowed")
+	http.Error(w, "Forbidden", http.StatusForbidden)
+	return false
+}
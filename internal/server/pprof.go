@@ -0,0 +1,38 @@
+package server
+
+import (
+	"net/http"
+	"net/http/pprof"
+	"time"
+)
+
+// pprofPrefix is where the profiling endpoints are mounted
+const pprofPrefix = "/debug/pprof/"
+
+// registerPprof mounts the net/http/pprof handlers behind the same
+// credentials as the admin endpoints
+func (s *Server) registerPprof(mux *http.ServeMux) {
+	mux.HandleFunc(pprofPrefix, s.requireAPIKey(s.profiling(pprof.Index)))
+	mux.HandleFunc(pprofPrefix+"cmdline", s.requireAPIKey(s.profiling(pprof.Cmdline)))
+	mux.HandleFunc(pprofPrefix+"profile", s.requireAPIKey(s.profiling(pprof.Profile)))
+	mux.HandleFunc(pprofPrefix+"symbol", s.requireAPIKey(s.profiling(pprof.Symbol)))
+	mux.HandleFunc(pprofPrefix+"trace", s.requireAPIKey(s.profiling(pprof.Trace)))
+
+	if !s.authEnabled() {
+		s.logger.Warn("pprof endpoints are enabled without authentication")
+	}
+}
+
+// profiling lifts the server's write timeout for a profiling request, since
+// CPU profiles and traces stream for as long as the ?seconds= parameter asks
+func (s *Server) profiling(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !s.checkAllowedIP(w, r) {
+			return
+		}
+		if err := http.NewResponseController(w).SetWriteDeadline(time.Time{}); err != nil {
+			s.logger.WithError(err).Debug("Failed to clear write deadline for profiling request")
+		}
+		next(w, r)
+	}
+}
@@ -0,0 +1,244 @@
+package server
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/chongliujia/mcp-go-template/internal/config"
+	"github.com/chongliujia/mcp-go-template/internal/state"
+	"github.com/chongliujia/mcp-go-template/pkg/mcp"
+)
+
+// rateLimitIdle is how long a client's buckets are kept after its last
+// request; an idle client's buckets are full again long before this
+const rateLimitIdle = 10 * time.Minute
+
+// clientKey is the context key for the identity requests are rate limited by
+type clientKey struct{}
+
+// withClient returns a context carrying the rate limit identity of the
+// client sending requests in it
+func withClient(ctx context.Context, client string) context.Context {
+	return context.WithValue(ctx, clientKey{}, client)
+}
+
+// clientFromContext returns the rate limit identity set by withClient
+func clientFromContext(ctx context.Context) string {
+	client, _ := ctx.Value(clientKey{}).(string)
+	return client
+}
+
+// rateLimitKey returns the identity a client is rate limited by: its
+// session, or with key "api_key" the credentials it authenticated with
+func (s *Server) rateLimitKey(sessionID, principal string) string {
+	if limiter := s.rateLimiter(); limiter != nil && limiter.cfg.Key == config.RateLimitByAPIKey && principal != "" {
+		return principal
+	}
+	return "session:" + sessionID
+}
+
+// rateLimiter keeps token buckets for each client, one for all requests
+// and one per rate limited tool
+type rateLimiter struct {
+	cfg config.RateLimitConfig
+
+	mu      sync.Mutex
+	clients map[string]*clientBuckets
+}
+
+// clientBuckets are the token buckets of a single client
+type clientBuckets struct {
+	requests *rate.Limiter
+	tools    map[string]*rate.Limiter
+	lastSeen time.Time
+}
+
+// rateLimiter returns the current limiter, or nil when rate limiting is
+// disabled
+func (s *Server) rateLimiter() *rateLimiter {
+	s.liveMu.RLock()
+	defer s.liveMu.RUnlock()
+	return s.rateLimits
+}
+
+// newRateLimiter returns a limiter for cfg, or nil when rate limiting is
+// disabled
+func newRateLimiter(cfg config.RateLimitConfig) *rateLimiter {
+	if !cfg.Enabled {
+		return nil
+	}
+	return &rateLimiter{
+		cfg:     cfg,
+		clients: make(map[string]*clientBuckets),
+	}
+}
+
+// reserve takes a token from each bucket that applies to a request and
+// returns zero, or, when any bucket is empty, takes nothing and returns how
+// long the client should wait before retrying
+func (l *rateLimiter) reserve(client, tool string, now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	buckets := l.buckets(client, now)
+	limiters := make([]*rate.Limiter, 0, 2)
+	if buckets.requests != nil {
+		limiters = append(limiters, buckets.requests)
+	}
+	if tool != "" {
+		if limiter := buckets.tool(tool, l.cfg.Tools); limiter != nil {
+			limiters = append(limiters, limiter)
+		}
+	}
+
+	var wait time.Duration
+	reservations := make([]*rate.Reservation, 0, len(limiters))
+	for _, limiter := range limiters {
+		r := limiter.ReserveN(now, 1)
+		reservations = append(reservations, r)
+		if delay := r.DelayFrom(now); delay > wait {
+			wait = delay
+		}
+	}
+
+	if wait > 0 {
+		// Give the tokens back so rejected requests do not count
+		for _, r := range reservations {
+			r.CancelAt(now)
+		}
+	}
+	return wait
+}
+
+// buckets returns the client's buckets, creating them on first use; the
+// caller holds the lock
+func (l *rateLimiter) buckets(client string, now time.Time) *clientBuckets {
+	buckets, exists := l.clients[client]
+	if !exists {
+		buckets = &clientBuckets{tools: make(map[string]*rate.Limiter)}
+		if l.cfg.RequestsPerSecond > 0 {
+			buckets.requests = rate.NewLimiter(rate.Limit(l.cfg.RequestsPerSecond), l.cfg.Burst)
+		}
+		l.clients[client] = buckets
+	}
+	buckets.lastSeen = now
+	return buckets
+}
+
+// tool returns the client's bucket for a tool, or nil if the tool has no
+// limit of its own
+func (b *clientBuckets) tool(name string, limits map[string]config.ToolRateLimitConfig) *rate.Limiter {
+	limit, exists := limits[name]
+	if !exists {
+		return nil
+	}
+	limiter, exists := b.tools[name]
+	if !exists {
+		limiter = rate.NewLimiter(rate.Limit(limit.RequestsPerSecond), limit.Burst)
+		b.tools[name] = limiter
+	}
+	return limiter
+}
+
+// prune forgets clients that have not sent a request since before cutoff
+func (l *rateLimiter) prune(cutoff time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for client, buckets := range l.clients {
+		if buckets.lastSeen.Before(cutoff) {
+			delete(l.clients, client)
+		}
+	}
+}
+
+// pruneRateLimits periodically drops the buckets of idle clients until ctx
+// is done
+func (s *Server) pruneRateLimits(ctx context.Context) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if limiter := s.rateLimiter(); limiter != nil {
+				limiter.prune(now.Add(-rateLimitIdle))
+			}
+		}
+	}
+}
+
+// checkRateLimit returns an error response when the client sending a
+// request in ctx is over its rate limit
+func (s *Server) checkRateLimit(ctx context.Context, message *mcp.Message) *mcp.Message {
+	limiter := s.rateLimiter()
+	if limiter == nil {
+		return nil
+	}
+
+	tool := ""
+	if message.Method == "tools/call" {
+		tool = mcp.MessageTarget(message)
+	}
+
+	client := clientFromContext(ctx)
+	now := time.Now()
+	wait, shared := s.reserveShared(ctx, limiter.cfg, client, tool, now)
+	if !shared {
+		wait = limiter.reserve(client, tool, now)
+	}
+	if wait <= 0 {
+		return nil
+	}
+
+	info := mcp.RateLimitedError(wait)
+	return mcp.NewErrorResponse(message.ID, info.Code, info.Message, info.Data)
+}
+
+// reserveShared counts a request in a shared state store, so a client's
+// limits apply across all replicas, and returns how long the client should
+// wait when it is over any of them. Each limit allows its burst in every
+// window of burst/requests_per_second, and rejected requests count too. It
+// reports false when the store is local or fails, leaving the request to
+// the replica's own buckets.
+func (s *Server) reserveShared(ctx context.Context, cfg config.RateLimitConfig, client, tool string, now time.Time) (time.Duration, bool) {
+	if !state.Shared(s.state) {
+		return 0, false
+	}
+
+	var windows []mcp.SharedWindow
+	if cfg.RequestsPerSecond > 0 {
+		windows = append(windows, mcp.SharedWindow{
+			Store: s.state,
+			Key:   "client:" + client,
+			Rate:  cfg.RequestsPerSecond,
+			Burst: cfg.Burst,
+		})
+	}
+	if limit, exists := cfg.Tools[tool]; exists && tool != "" {
+		windows = append(windows, mcp.SharedWindow{
+			Store: s.state,
+			Key:   "client:" + client + ":tool:" + tool,
+			Rate:  limit.RequestsPerSecond,
+			Burst: limit.Burst,
+		})
+	}
+
+	var wait time.Duration
+	for _, window := range windows {
+		delay, err := window.Reserve(ctx, now)
+		if err != nil {
+			s.logger.WithError(err).Warn("Shared rate limit unavailable, limiting on this replica")
+			return 0, false
+		}
+		if delay > wait {
+			wait = delay
+		}
+	}
+	return wait, true
+}
@@ -3,14 +3,24 @@ package server
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
+	"net"
 	"net/http"
+	"net/netip"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/gorilla/websocket"
 	"github.com/sirupsen/logrus"
 
+	"github.com/chongliujia/mcp-go-template/internal/auth"
 	"github.com/chongliujia/mcp-go-template/internal/config"
+	"github.com/chongliujia/mcp-go-template/internal/state"
 	"github.com/chongliujia/mcp-go-template/pkg/mcp"
 	"github.com/chongliujia/mcp-go-template/pkg/utils"
 )
@@ -20,52 +30,150 @@ type Server struct {
 	config   *config.Config
 	handler  mcp.Handler
 	upgrader websocket.Upgrader
-	logger   *logrus.Logger
+	logger   utils.Logger
+	state    state.Store
+	jwt      *auth.JWTValidator
+
+	accessLog      *accessLog
+	trustedProxies []netip.Prefix
+
+	// Settings replaced by Reload. restrictIPs is set when allowed IPs are
+	// configured, so a list that failed to parse rejects every client.
+	liveMu      sync.RWMutex
+	restrictIPs bool
+	allowedIPs  []netip.Prefix
+	rateLimits  *rateLimiter
+
+	connMu sync.RWMutex
+	conns  map[*connection]struct{}
+
+	sessionMu sync.RWMutex
+	sessions  map[string]*streamSession
+
+	// In-flight request tracking for graceful shutdown
+	drainMu        sync.Mutex
+	draining       bool
+	shutdown       chan struct{}
+	inflight       sync.WaitGroup
+	requests       context.Context
+	cancelRequests context.CancelFunc
 }
 
 // New creates a new MCP server
 func New(cfg *config.Config, handler mcp.Handler) *Server {
-	return &Server{
+	requests, cancelRequests := context.WithCancel(context.Background())
+	s := &Server{
 		config:  cfg,
 		handler: handler,
-		upgrader: websocket.Upgrader{
-			CheckOrigin: func(r *http.Request) bool {
-				// Allow connections from any origin in development
-				// In production, implement proper origin checking
-				return true
-			},
-		},
-		logger: utils.GetLogger(),
+		logger:  utils.DefaultLogger(),
+		state:  state.NewMemoryStore(),
+		conns:  make(map[*connection]struct{}),
+
+		accessLog:   newAccessLog(cfg.Logging.AccessLog),
+		restrictIPs: len(cfg.Security.AllowedIPs) > 0,
+		rateLimits:  newRateLimiter(cfg.Server.RateLimit),
+
+		sessions: make(map[string]*streamSession),
+
+		shutdown:       make(chan struct{}),
+		requests:       requests,
+		cancelRequests: cancelRequests,
+	}
+
+	// Both lists are validated with the configuration
+	var err error
+	if s.allowedIPs, err = cfg.GetAllowedIPPrefixes(); err != nil {
+		s.logger.WithError(err).Error("Invalid security.allowed_ips, rejecting all connections")
 	}
+	if s.trustedProxies, err = config.ParseIPPrefixes(cfg.Server.TrustedProxies); err != nil {
+		s.logger.WithError(err).Error("Invalid server.trusted_proxies, ignoring forwarding headers")
+	}
+
+	// Origins are restricted by security.allowed_origins; an empty list
+	// allows any origin, which suits development
+	s.upgrader = websocket.Upgrader{
+		CheckOrigin: s.checkOrigin,
+	}
+	return s
 }
 
-// Start starts the MCP server
-func (s *Server) Start(ctx context.Context) error {
+// Reload applies the allowed IPs and rate limits of cfg to new requests.
+// Rate limits that are unchanged keep the state of each client's buckets.
+func (s *Server) Reload(cfg *config.Config) error {
+	allowedIPs, err := cfg.GetAllowedIPPrefixes()
+	if err != nil {
+		return fmt.Errorf("invalid security.allowed_ips: %w", err)
+	}
+
+	s.liveMu.Lock()
+	defer s.liveMu.Unlock()
+
+	s.restrictIPs = len(allowedIPs) > 0
+	s.allowedIPs = allowedIPs
+	if s.rateLimits == nil || !reflect.DeepEqual(s.rateLimits.cfg, cfg.Server.RateLimit) {
+		s.rateLimits = newRateLimiter(cfg.Server.RateLimit)
+	}
+	return nil
+}
+
+// routes returns the HTTP endpoints of the server
+func (s *Server) routes() http.Handler {
 	mux := http.NewServeMux()
-	mux.HandleFunc("/mcp", s.handleWebSocket)
+	mux.HandleFunc("/mcp", s.handleMCP)
 	mux.HandleFunc("/health", s.handleHealth)
+	mux.HandleFunc("/admin/tools", s.requireAllowedIP(s.requireAPIKey(s.handleAdminTools)))
+	mux.HandleFunc("/admin/tools/", s.requireAllowedIP(s.requireAPIKey(s.handleAdminTool)))
 	mux.HandleFunc("/", s.handleRoot)
+	if s.config.Server.EnablePprof {
+		s.registerPprof(mux)
+	}
+	return s.withCORS(mux)
+}
 
+// Start starts the MCP server
+func (s *Server) Start(ctx context.Context) error {
 	server := &http.Server{
 		Addr:         s.config.GetAddress(),
-		Handler:      mux,
+		Handler:      s.routes(),
 		ReadTimeout:  time.Duration(s.config.Server.Timeout) * time.Second,
 		WriteTimeout: time.Duration(s.config.Server.Timeout) * time.Second,
 	}
 
+	network, address := s.config.GetListenAddress()
+	listener, err := listen(network, address)
+	if err != nil {
+		return err
+	}
+
 	s.logger.WithFields(logrus.Fields{
-		"address": s.config.GetAddress(),
+		"network": network,
+		"address": address,
 		"name":    s.config.MCP.Name,
 		"version": s.config.MCP.Version,
 	}).Info("Starting MCP server")
 
+	// Relay resource updates published by other replicas
+	if err := s.relayResourceUpdates(ctx); err != nil {
+		return err
+	}
+
+	// Expire idle Streamable HTTP sessions
+	go s.expireSessions(ctx, time.Duration(s.config.Server.SessionTimeout)*time.Second)
+
+	go s.pruneRateLimits(ctx)
+
+	// Send log entries to clients that call logging/setLevel
+	if s.config.MCP.Capabilities.Logging {
+		s.forwardLogs(ctx)
+	}
+
 	// Start server in a goroutine
 	errCh := make(chan error, 1)
 	go func() {
 		if s.config.Security.EnableTLS {
-			errCh <- server.ListenAndServeTLS(s.config.Security.CertFile, s.config.Security.KeyFile)
+			errCh <- server.ServeTLS(listener, s.config.Security.CertFile, s.config.Security.KeyFile)
 		} else {
-			errCh <- server.ListenAndServe()
+			errCh <- server.Serve(listener)
 		}
 	}()
 
@@ -75,8 +183,11 @@ func (s *Server) Start(ctx context.Context) error {
 		s.logger.Info("Shutting down server...")
 		
 		// Create a context with timeout for graceful shutdown
-		shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Duration(s.config.Server.ShutdownTimeout)*time.Second)
 		defer cancel()
+
+		// Let in-flight requests finish and close WebSocket clients cleanly
+		s.drain(shutdownCtx)
 		
 		return server.Shutdown(shutdownCtx)
 	case err := <-errCh:
@@ -84,49 +195,133 @@ func (s *Server) Start(ctx context.Context) error {
 	}
 }
 
-// handleWebSocket handles WebSocket connections for MCP communication
-func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
-	// Check allowed IPs if configured
-	if len(s.config.Security.AllowedIPs) > 0 {
-		clientIP := s.getClientIP(r)
-		allowed := false
-		for _, ip := range s.config.Security.AllowedIPs {
-			if ip == clientIP {
-				allowed = true
-				break
+// listen opens the server listener. A stale Unix socket left behind by a
+// previous run is removed first; the socket file is removed again when the
+// listener closes.
+func listen(network, address string) (net.Listener, error) {
+	if network == "unix" {
+		if info, err := os.Stat(address); err == nil {
+			if info.Mode()&fs.ModeSocket == 0 {
+				return nil, fmt.Errorf("socket path %s exists and is not a socket", address)
+			}
+			if err := os.Remove(address); err != nil {
+				return nil, fmt.Errorf("failed to remove stale socket %s: %w", address, err)
 			}
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("failed to stat socket path %s: %w", address, err)
 		}
-		if !allowed {
-			s.logger.WithField("client_ip", clientIP).Warn("Connection rejected: IP not allowed")
-			http.Error(w, "Forbidden", http.StatusForbidden)
-			return
+	}
+
+	listener, err := net.Listen(network, address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s %s: %w", network, address, err)
+	}
+	return listener, nil
+}
+
+// checkAllowedIP rejects the request if allowed IPs are configured and the
+// client is not within any of the listed addresses or CIDR ranges
+func (s *Server) checkAllowedIP(w http.ResponseWriter, r *http.Request) bool {
+	s.liveMu.RLock()
+	restrictIPs, allowedIPs := s.restrictIPs, s.allowedIPs
+	s.liveMu.RUnlock()
+	if !restrictIPs {
+		return true
+	}
+
+	clientIP := s.getClientIP(r)
+	if addrInPrefixes(clientIP, allowedIPs) {
+		return true
+	}
+
+	s.logger.WithField("client_ip", clientIP).Warn("Connection rejected: IP not allowed")
+	http.Error(w, "Forbidden", http.StatusForbidden)
+	return false
+}
+
+// requireAllowedIP wraps an HTTP endpoint so it is only served to the
+// clients checkAllowedIP accepts
+func (s *Server) requireAllowedIP(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.checkAllowedIP(w, r) {
+			next(w, r)
 		}
 	}
+}
 
+// handleWebSocket handles WebSocket connections for MCP communication.
+// Unauthenticated connections must send an API key with their first message.
+func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request, creds authResult) {
 	conn, err := s.upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		s.logger.WithError(err).Error("WebSocket upgrade failed")
 		return
 	}
-	defer conn.Close()
 
 	s.logger.WithField("client", conn.RemoteAddr()).Info("New WebSocket connection")
 
 	// Handle the WebSocket connection
-	s.handleConnection(conn)
+	c, err := s.addConnection(conn, s.getClientIP(r))
+	if errors.Is(err, errTooManyConnections) {
+		s.logger.WithField("client", conn.RemoteAddr()).Warn("Connection rejected: connection limit reached")
+		s.rejectConnection(conn)
+		return
+	}
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to create session")
+		conn.Close()
+		return
+	}
+	defer s.removeConnection(c)
+	c.authenticated = creds.authenticated
+	c.principal = creds.principal
+	c.session.SetPrincipal(creds.principal)
+	if creds.claims != nil {
+		c.session.SetClaims(creds.claims)
+	}
+	s.handleConnection(c)
+}
+
+// rejectConnection tells a client over the connection limit why it was
+// refused and closes the socket
+func (s *Server) rejectConnection(conn *websocket.Conn) {
+	defer conn.Close()
+
+	info := mcp.LimitExceededError("max_connections", s.config.Server.MaxConnections)
+	conn.SetWriteDeadline(time.Now().Add(s.keepalive().writeDeadline()))
+	s.sendMessage(conn, mcp.NewErrorResponse(nil, info.Code, info.Message, info.Data))
+
+	message := websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "connection limit reached")
+	conn.WriteControl(websocket.CloseMessage, message, time.Now().Add(s.keepalive().writeDeadline()))
 }
 
 // handleConnection handles a single WebSocket connection
-func (s *Server) handleConnection(conn *websocket.Conn) {
+func (s *Server) handleConnection(c *connection) {
+	conn := c.conn
+	// Requests still running when the connection closes are cancelled,
+	// since nobody is left to read their responses
+	ctx, cancel := context.WithCancel(mcp.WithSession(context.Background(), c.session))
+	defer cancel()
+	ctx = withClient(ctx, s.rateLimitKey(c.session.ID(), c.principal))
+	c.keepalive.prepareReads(conn)
+	if limit := s.config.Server.MaxMessageBytes; limit > 0 {
+		// Oversized frames fail the read and close the connection with 1009
+		conn.SetReadLimit(limit)
+	}
 	for {
 		// Read message
 		messageType, data, err := conn.ReadMessage()
 		if err != nil {
-			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
+			if isTimeout(err) {
+				s.logger.WithField("client", conn.RemoteAddr()).Warn("WebSocket client stopped answering pings")
+			} else if errors.Is(err, websocket.ErrReadLimit) {
+				s.logger.WithField("client", conn.RemoteAddr()).Warn("WebSocket message exceeded max_message_bytes")
+			} else if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
 				s.logger.WithError(err).Error("WebSocket read error")
 			}
 			break
 		}
+		c.touch()
 
 		if messageType != websocket.TextMessage {
 			s.logger.Warn("Received non-text message, ignoring")
@@ -140,7 +335,7 @@ func (s *Server) handleConnection(conn *websocket.Conn) {
 			
 			// Send error response
 			errorResponse := mcp.NewErrorResponse(nil, mcp.ParseError, "Invalid JSON", err.Error())
-			s.sendMessage(conn, errorResponse)
+			c.send(errorResponse)
 			continue
 		}
 
@@ -149,37 +344,156 @@ func (s *Server) handleConnection(conn *websocket.Conn) {
 			"id":     message.ID,
 		}).Debug("Received MCP message")
 
-		// Handle the message
-		response, err := s.handler.HandleMessage(context.Background(), &message)
-		if err != nil {
-			s.logger.WithError(err).Error("Message handling failed")
-			
-			// Send internal error response
-			errorResponse := mcp.NewErrorResponse(message.ID, mcp.InternalError, "Internal server error", err.Error())
-			s.sendMessage(conn, errorResponse)
+		// Answers to the server's own requests go to the tool waiting for
+		// them; answers to pings only show that the client is alive
+		if message.IsResponse() {
+			c.session.HandleResponse(&message)
 			continue
 		}
 
-		// Send response if there is one
-		if response != nil {
-			if err := s.sendMessage(conn, response); err != nil {
-				s.logger.WithError(err).Error("Failed to send response")
+		if !c.authenticated {
+			key := messageAPIKey(&message)
+			if !s.validAPIKey(key) {
+				s.logger.WithField("client", conn.RemoteAddr()).Warn("Connection rejected: missing or invalid credentials")
+				if message.IsRequest() {
+					c.send(unauthorizedResponse(&message))
+				}
+				c.closeAfterPending(websocket.ClosePolicyViolation, "unauthorized")
+				c.waitClosed()
 				break
 			}
+			c.authenticated = true
+			c.principal = apiKeyPrincipal(key)
+			c.session.SetPrincipal(c.principal)
+			ctx = withClient(ctx, s.rateLimitKey(c.session.ID(), c.principal))
+		}
+
+		if message.Method == "initialize" {
+			c.recordInitialize(&message)
+		}
+
+		// Notifications are handled in order; requests run concurrently up
+		// to the connection's in-flight limit
+		if !message.IsRequest() {
+			if response := s.processMessage(ctx, &message); response != nil {
+				c.send(response)
+			}
+			continue
+		}
+
+		if !c.acquire() {
+			info := mcp.LimitExceededError("max_inflight_requests", s.config.Server.MaxInflightRequests)
+			c.send(mcp.NewErrorResponse(message.ID, info.Code, info.Message, info.Data))
+			continue
 		}
+
+		go func(message *mcp.Message) {
+			defer c.release()
+
+			// Send response if there is one
+			if response := s.processMessage(ctx, message); response != nil {
+				if err := c.send(response); err != nil {
+					s.logger.WithError(err).Debug("Dropped response for closed connection")
+				}
+			}
+		}(&message)
 	}
 
 	s.logger.WithField("client", conn.RemoteAddr()).Info("WebSocket connection closed")
 }
 
+// processMessage passes a message to the handler, converting handler
+// failures into an internal error response. Requests are tracked so
+// shutdown can wait for them, and are refused once shutdown has begun.
+func (s *Server) processMessage(ctx context.Context, message *mcp.Message) (response *mcp.Message) {
+	if message.IsRequest() {
+		if !s.beginRequest() {
+			return shutdownResponse(message)
+		}
+		defer s.inflight.Done()
+
+		var cancel context.CancelFunc
+		ctx, cancel = s.requestContext(ctx)
+		defer cancel()
+		ctx = utils.WithCorrelationID(ctx, utils.NewCorrelationID())
+
+		start := time.Now()
+		defer func() {
+			s.logRequest(ctx, message, response, start)
+		}()
+
+		if limited := s.checkRateLimit(ctx, message); limited != nil {
+			return s.encodeResponse(ctx, limited)
+		}
+	}
+
+	response, err := s.handler.HandleMessage(ctx, message)
+	if err != nil {
+		utils.CorrelatedLogger(ctx, s.logger).WithError(err).Error("Message handling failed")
+		response = mcp.NewErrorResponse(message.ID, mcp.InternalError, "Internal server error", err.Error())
+	}
+	return s.encodeResponse(ctx, response)
+}
+
+// encodeResponse encodes a response's result once, keeping it as raw JSON
+// so transports do not marshal it again. The request's correlation ID is
+// added under _meta, and a result exceeding server.max_message_bytes is
+// replaced with an error.
+func (s *Server) encodeResponse(ctx context.Context, response *mcp.Message) *mcp.Message {
+	if response == nil {
+		return nil
+	}
+	correlationID := utils.CorrelationID(ctx)
+
+	if response.Error != nil {
+		response.Error.Data = withCorrelationData(response.Error.Data, correlationID)
+		return response
+	}
+	if response.Result == nil {
+		return response
+	}
+
+	data, err := json.Marshal(response.Result)
+	if err != nil {
+		return s.encodeResponse(ctx, mcp.NewErrorResponse(response.ID, mcp.InternalError, "failed to encode result", err.Error()))
+	}
+	if correlationID != "" {
+		data = withCorrelationMeta(data, correlationID)
+	}
+
+	if limit := s.config.Server.MaxMessageBytes; limit > 0 && int64(len(data)) > limit {
+		utils.CorrelatedLogger(ctx, s.logger).WithFields(logrus.Fields{
+			"id":    response.ID,
+			"bytes": len(data),
+		}).Warn("Response exceeded max_message_bytes")
+		info := mcp.LimitExceededError("max_message_bytes", int(limit))
+		return s.encodeResponse(ctx, mcp.NewErrorResponse(response.ID, info.Code, info.Message, info.Data))
+	}
+
+	response.Result = json.RawMessage(data)
+	return response
+}
+
 // sendMessage sends a message over the WebSocket connection
 func (s *Server) sendMessage(conn *websocket.Conn, message *mcp.Message) error {
-	data, err := json.Marshal(message)
-	if err != nil {
+	// Encode into a pooled buffer first so a marshaling failure never leaves
+	// a partially written frame on the connection
+	buf := utils.GetBuffer()
+	defer utils.PutBuffer(buf)
+
+	if err := json.NewEncoder(buf).Encode(message); err != nil {
 		return fmt.Errorf("failed to marshal message: %w", err)
 	}
 
-	if err := conn.WriteMessage(websocket.TextMessage, data); err != nil {
+	w, err := conn.NextWriter(websocket.TextMessage)
+	if err != nil {
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+	if _, err := buf.WriteTo(w); err != nil {
+		w.Close()
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+	if err := w.Close(); err != nil {
 		return fmt.Errorf("failed to write message: %w", err)
 	}
 
@@ -199,12 +513,95 @@ func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 		"name":    s.config.MCP.Name,
 		"version": s.config.MCP.Version,
 		"time":    time.Now().UTC(),
+		"state":   s.config.State.Backend,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(health)
 }
 
+// handleAdminTools lists registered tools, optionally filtered by the
+// category and tag query parameters
+func (s *Server) handleAdminTools(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	tools, err := s.handler.ListTools()
+	if err != nil {
+		http.Error(w, "Failed to list tools", http.StatusInternalServerError)
+		return
+	}
+
+	query := r.URL.Query()
+	tools = mcp.FilterTools(tools, query.Get("category"), query["tag"])
+
+	response := map[string]interface{}{
+		"tools": tools,
+		"count": len(tools),
+	}
+	if toolSwitch, ok := s.handler.(mcp.ToolSwitch); ok {
+		response["disabled"] = toolSwitch.DisabledTools()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// handleAdminTool disables or re-enables a tool at runtime, answering
+// POST /admin/tools/{name}/disable and POST /admin/tools/{name}/enable.
+// Without configured credentials anyone could call it, so it is refused.
+func (s *Server) handleAdminTool(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !s.authEnabled() {
+		http.Error(w, "Forbidden: configure security.api_keys or security.jwt to change tools", http.StatusForbidden)
+		return
+	}
+
+	name, action, ok := strings.Cut(strings.TrimPrefix(r.URL.Path, "/admin/tools/"), "/")
+	if !ok || name == "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	toolSwitch, supported := s.handler.(mcp.ToolSwitch)
+	if !supported {
+		http.Error(w, "Disabling tools is not supported", http.StatusNotImplemented)
+		return
+	}
+
+	var err error
+	switch action {
+	case "disable":
+		err = toolSwitch.DisableTool(name)
+	case "enable":
+		err = toolSwitch.EnableTool(name)
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"tool":      name,
+		"action":    action,
+		"client_ip": s.getClientIP(r),
+	}).Info("Tool state changed")
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"tool":    name,
+		"enabled": action == "enable",
+	})
+}
+
 // handleRoot handles root path requests
 func (s *Server) handleRoot(w http.ResponseWriter, r *http.Request) {
 	info := map[string]interface{}{
@@ -213,43 +610,83 @@ func (s *Server) handleRoot(w http.ResponseWriter, r *http.Request) {
 		"description": s.config.MCP.Description,
 		"endpoints": map[string]string{
 			"websocket": "/mcp",
+			"streamable_http": "/mcp",
 			"health":    "/health",
+			"tools":     "/admin/tools",
 		},
-		"protocol_version": mcp.MCPVersion,
+		"protocol_version":  mcp.MCPVersion,
+		"protocol_versions": mcp.SupportedProtocolVersions,
+	}
+
+	if s.config.Server.EnablePprof {
+		info["endpoints"].(map[string]string)["pprof"] = pprofPrefix
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(info)
 }
 
-// getClientIP extracts the client IP from the request
+// parseClientAddr parses a client address with or without a port, treating
+// IPv4-mapped IPv6 addresses as IPv4
+func parseClientAddr(value string) (netip.Addr, bool) {
+	value = strings.TrimSpace(value)
+	if addrPort, err := netip.ParseAddrPort(value); err == nil {
+		return addrPort.Addr().Unmap(), true
+	}
+	addr, err := netip.ParseAddr(strings.Trim(value, "[]"))
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return addr.Unmap(), true
+}
+
+// getClientIP extracts the client IP from the request. Forwarding headers
+// are only honored when the direct peer is one of server.trusted_proxies;
+// otherwise any client could spoof its address.
 func (s *Server) getClientIP(r *http.Request) string {
-	// Check X-Forwarded-For header
-	xForwardedFor := r.Header.Get("X-Forwarded-For")
-	if xForwardedFor != "" {
-		// Take the first IP if there are multiple
-		if idx := len(xForwardedFor); idx > 0 {
-			if commaIdx := 0; commaIdx < idx {
-				for i, c := range xForwardedFor {
-					if c == ',' {
-						commaIdx = i
-						break
-					}
-				}
-				if commaIdx > 0 {
-					return xForwardedFor[:commaIdx]
-				}
+	peer := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(peer); err == nil {
+		peer = host
+	}
+
+	trusted := s.trustedProxies
+	if !addrInPrefixes(peer, trusted) {
+		return peer
+	}
+
+	// Walk X-Forwarded-For from the nearest hop, skipping our own proxies;
+	// the first untrusted address is the client
+	if xForwardedFor := r.Header.Get("X-Forwarded-For"); xForwardedFor != "" {
+		hops := strings.Split(xForwardedFor, ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop := strings.TrimSpace(hops[i])
+			if hop == "" {
+				continue
+			}
+			if i == 0 || !addrInPrefixes(hop, trusted) {
+				return hop
 			}
 		}
-		return xForwardedFor
 	}
 
 	// Check X-Real-IP header
-	xRealIP := r.Header.Get("X-Real-IP")
-	if xRealIP != "" {
+	if xRealIP := strings.TrimSpace(r.Header.Get("X-Real-IP")); xRealIP != "" {
 		return xRealIP
 	}
 
-	// Fall back to RemoteAddr
-	return r.RemoteAddr
-}
\ No newline at end of file
+	return peer
+}
+
+// addrInPrefixes reports whether value parses as an address within prefixes
+func addrInPrefixes(value string, prefixes []netip.Prefix) bool {
+	addr, ok := parseClientAddr(value)
+	if !ok {
+		return false
+	}
+	for _, prefix := range prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,721 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+
+	"github.com/chongliujia/mcp-go-template/internal/state"
+	"github.com/chongliujia/mcp-go-template/pkg/mcp"
+)
+
+// SessionHeader carries the Streamable HTTP session ID
+const SessionHeader = "Mcp-Session-Id"
+
+// ProtocolVersionHeader carries the negotiated protocol version on
+// Streamable HTTP requests after initialize
+const ProtocolVersionHeader = "MCP-Protocol-Version"
+
+// sessionHistorySize is the number of events kept per session for resumption
+const sessionHistorySize = 1000
+
+// sseEvent is a message delivered on a session's event stream
+type sseEvent struct {
+	id   uint64
+	data []byte
+}
+
+// streamSession is the server-side state of a Streamable HTTP client. Every
+// server-initiated message is recorded with an increasing event ID so a
+// client that reconnects with Last-Event-ID receives what it missed.
+// Responses to a POST go to that POST's stream alone.
+type streamSession struct {
+	id      string
+	session *mcp.Session
+	// principal identifies the credentials the session was opened with
+	principal string
+
+	mu       sync.Mutex
+	info     ConnectionInfo
+	lastSeen time.Time
+	nextID   uint64
+	history  []sseEvent
+	notify   chan struct{}
+	closed   bool
+}
+
+// newStreamSession creates a session with a random ID
+func newStreamSession(clientIP, remoteAddr string) (*streamSession, error) {
+	id, err := newSessionID()
+	if err != nil {
+		return nil, err
+	}
+
+	session := mcp.NewSession(id)
+	return &streamSession{
+		id:       id,
+		session:  session,
+		lastSeen: time.Now(),
+		notify:   make(chan struct{}),
+		info: ConnectionInfo{
+			RemoteAddr: remoteAddr,
+			ClientIP:   clientIP,
+			Session:    session,
+		},
+	}, nil
+}
+
+// restoreStreamSession recreates a session another replica saved
+func restoreStreamSession(id string, saved mcp.SessionState, clientIP, remoteAddr string) *streamSession {
+	session := mcp.RestoreSession(id, saved)
+	return &streamSession{
+		id:        id,
+		session:   session,
+		principal: saved.Principal,
+		lastSeen:  time.Now(),
+		notify:    make(chan struct{}),
+		info: ConnectionInfo{
+			RemoteAddr:   remoteAddr,
+			ClientIP:     clientIP,
+			ClientInfo:   saved.ClientInfo,
+			Capabilities: saved.Capabilities,
+			Initialized:  true,
+			Session:      session,
+		},
+	}
+}
+
+// newSessionID generates a random session identifier
+func newSessionID() (string, error) {
+	raw := make([]byte, 16)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("failed to generate session id: %w", err)
+	}
+	return hex.EncodeToString(raw), nil
+}
+
+// record assigns the next event ID to message and adds it to the history
+func (ss *streamSession) record(message *mcp.Message) (sseEvent, error) {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return sseEvent{}, fmt.Errorf("failed to marshal message: %w", err)
+	}
+
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	ss.nextID++
+	event := sseEvent{id: ss.nextID, data: data}
+	ss.history = append(ss.history, event)
+	if len(ss.history) > sessionHistorySize {
+		ss.history = ss.history[len(ss.history)-sessionHistorySize:]
+	}
+
+	// Wake any open GET stream
+	close(ss.notify)
+	ss.notify = make(chan struct{})
+
+	return event, nil
+}
+
+// eventsAfter returns recorded events newer than id and a channel closed
+// when another event is recorded
+func (ss *streamSession) eventsAfter(id uint64) ([]sseEvent, <-chan struct{}, bool) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	var events []sseEvent
+	for _, event := range ss.history {
+		if event.id > id {
+			events = append(events, event)
+		}
+	}
+	return events, ss.notify, ss.closed
+}
+
+// touch marks the session as active
+func (ss *streamSession) touch() {
+	ss.mu.Lock()
+	ss.lastSeen = time.Now()
+	ss.mu.Unlock()
+}
+
+// close ends the session and any open stream
+func (ss *streamSession) close() {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	if !ss.closed {
+		ss.closed = true
+		close(ss.notify)
+	}
+}
+
+// snapshot returns a copy of what is known about the client
+func (ss *streamSession) snapshot() ConnectionInfo {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	return ss.info
+}
+
+// recordInitialize captures the client's identity and capabilities
+func (ss *streamSession) recordInitialize(message *mcp.Message) {
+	var params mcp.InitializeParams
+	if err := message.UnmarshalParams(&params); err != nil {
+		return
+	}
+
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	ss.info.ClientInfo = params.ClientInfo
+	ss.info.Capabilities = params.Capabilities
+	ss.info.Initialized = true
+}
+
+// handleMCP routes /mcp requests to the WebSocket or Streamable HTTP transport
+func (s *Server) handleMCP(w http.ResponseWriter, r *http.Request) {
+	if !s.checkAllowedIP(w, r) || !s.checkAllowedOrigin(w, r) {
+		return
+	}
+
+	// Clients without a key on the request may still authenticate with
+	// their first MCP message
+	creds, ok := s.authenticateRequest(w, r)
+	if !ok {
+		return
+	}
+
+	if isWebSocketUpgrade(r) {
+		s.handleWebSocket(w, r, creds)
+		return
+	}
+
+	// Clients that omit the header are assumed to speak 2025-03-26
+	if version := r.Header.Get(ProtocolVersionHeader); version != "" && !mcp.IsSupportedProtocolVersion(version) {
+		http.Error(w, "Unsupported "+ProtocolVersionHeader, http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPost:
+		s.handleStreamablePost(w, r, creds)
+	case http.MethodGet:
+		s.handleStreamableGet(w, r, creds)
+	case http.MethodDelete:
+		s.handleStreamableDelete(w, r, creds)
+	default:
+		w.Header().Set("Allow", "GET, POST, DELETE")
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// isWebSocketUpgrade reports whether r asks to upgrade to a WebSocket
+func isWebSocketUpgrade(r *http.Request) bool {
+	return strings.EqualFold(r.Header.Get("Upgrade"), "websocket")
+}
+
+// handleStreamablePost handles client messages sent over Streamable HTTP.
+// Requests are answered either as a single JSON body or, when the client
+// accepts it, as an SSE stream with one event per response.
+func (s *Server) handleStreamablePost(w http.ResponseWriter, r *http.Request, creds authResult) {
+	if limit := s.config.Server.MaxMessageBytes; limit > 0 {
+		r.Body = http.MaxBytesReader(w, r.Body, limit)
+	}
+
+	body, err := io.ReadAll(r.Body)
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to read request body", http.StatusBadRequest)
+		return
+	}
+
+	messages, batch, err := decodeMessages(body)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, mcp.NewErrorResponse(nil, mcp.ParseError, "Invalid JSON", err.Error()))
+		return
+	}
+
+	var initialize *mcp.Message
+	for _, message := range messages {
+		if message.Method == "initialize" {
+			initialize = message
+		}
+	}
+
+	var session *streamSession
+	if initialize != nil {
+		if response := streamableVersionError(initialize); response != nil {
+			writeJSON(w, http.StatusOK, response)
+			return
+		}
+
+		// New sessions need an API key on the request or in initialize
+		if !creds.authenticated {
+			principal, ok := s.messagesAuthenticated(messages)
+			if !ok {
+				s.rejectUnauthenticated(w, r)
+				return
+			}
+			creds.principal = principal
+		}
+
+		session, err = newStreamSession(s.getClientIP(r), r.RemoteAddr)
+		if err != nil {
+			http.Error(w, "Failed to create session", http.StatusInternalServerError)
+			return
+		}
+		if creds.claims != nil {
+			session.session.SetClaims(creds.claims)
+		}
+		session.principal = creds.principal
+		session.session.SetPrincipal(creds.principal)
+		session = s.addSession(session)
+	} else {
+		// Clients that authenticated in initialize may keep sending their
+		// API key in _meta
+		if !creds.authenticated && s.authEnabled() {
+			if principal, ok := s.messagesAuthenticated(messages); ok {
+				creds = authResult{authenticated: true, principal: principal}
+			}
+		}
+		var status int
+		session, status = s.lookupSession(r, creds)
+		if session == nil {
+			s.sessionError(w, r, status)
+			return
+		}
+	}
+	session.touch()
+	w.Header().Set(SessionHeader, session.id)
+	// Continue the caller's trace when it sends a traceparent header
+	ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+	ctx = mcp.WithSession(ctx, session.session)
+	ctx = withClient(ctx, s.rateLimitKey(session.id, session.principal))
+
+	// Responses answer the server's own requests, such as sampling
+	pending := messages[:0]
+	for _, message := range messages {
+		if message.IsResponse() {
+			session.session.HandleResponse(message)
+		} else {
+			pending = append(pending, message)
+		}
+	}
+	messages = pending
+
+	// Notifications and responses only: acknowledge without a body
+	hasRequests := false
+	for _, message := range messages {
+		if message.IsRequest() {
+			hasRequests = true
+			break
+		}
+	}
+	if !hasRequests {
+		for _, message := range messages {
+			s.handler.HandleMessage(ctx, message)
+		}
+		s.saveSession(r.Context(), session)
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	if acceptsEventStream(r) {
+		s.streamResponses(ctx, w, session, messages)
+		s.saveSession(r.Context(), session)
+		return
+	}
+
+	var responses []*mcp.Message
+	for _, message := range messages {
+		if message.Method == "initialize" {
+			session.recordInitialize(message)
+		}
+		if response := s.processMessage(ctx, message); response != nil {
+			responses = append(responses, response)
+		}
+	}
+	s.saveSession(r.Context(), session)
+
+	if batch {
+		writeJSON(w, http.StatusOK, responses)
+	} else {
+		writeJSON(w, http.StatusOK, responses[0])
+	}
+}
+
+// streamableVersionError rejects an initialize request whose protocol
+// version predates the Streamable HTTP transport, returning nil otherwise
+func streamableVersionError(message *mcp.Message) *mcp.Message {
+	var params mcp.InitializeParams
+	if err := message.UnmarshalParams(&params); err != nil {
+		return nil
+	}
+
+	// Versions the handler cannot negotiate are rejected by it
+	version, ok := mcp.NegotiateProtocolVersion(params.ProtocolVersion)
+	if !ok || mcp.ProtocolAtLeast(version, mcp.ProtocolVersionStreamableHTTP) {
+		return nil
+	}
+
+	info := mcp.NewError(mcp.InvalidMCPVersion, fmt.Sprintf("Streamable HTTP requires protocol version %s or later", mcp.ProtocolVersionStreamableHTTP), map[string]interface{}{
+		"requested": params.ProtocolVersion,
+		"supported": mcp.SupportedProtocolVersions,
+	})
+	return mcp.NewErrorResponse(message.ID, info.Code, info.Message, info.Data)
+}
+
+// streamResponses answers a POST with an SSE stream, writing each response
+// as soon as it is ready
+func (s *Server) streamResponses(ctx context.Context, w http.ResponseWriter, session *streamSession, messages []*mcp.Message) {
+	// Slow tools may outlast the server's write timeout; each request is
+	// still bounded by its own timeout
+	http.NewResponseController(w).SetWriteDeadline(time.Time{})
+
+	flusher, ok := startEventStream(w)
+	if !ok {
+		http.Error(w, "Streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	for _, message := range messages {
+		if message.Method == "initialize" {
+			session.recordInitialize(message)
+		}
+
+		response := s.processMessage(ctx, message)
+		if response == nil {
+			continue
+		}
+
+		// Responses are not recorded, so a GET stream never replays them
+		data, err := json.Marshal(response)
+		if err != nil {
+			s.logger.WithError(err).Error("Failed to marshal response")
+			continue
+		}
+		if err := writeEvent(w, sseEvent{data: data}); err != nil {
+			s.logger.WithError(err).Debug("Event stream closed by client")
+			return
+		}
+		flusher.Flush()
+	}
+}
+
+// handleStreamableGet opens a long-lived SSE stream for server-initiated
+// messages. A Last-Event-ID header replays events the client missed.
+func (s *Server) handleStreamableGet(w http.ResponseWriter, r *http.Request, creds authResult) {
+	if !acceptsEventStream(r) {
+		http.Error(w, "Not acceptable", http.StatusNotAcceptable)
+		return
+	}
+
+	session, status := s.lookupSession(r, creds)
+	if session == nil {
+		s.sessionError(w, r, status)
+		return
+	}
+
+	var lastID uint64
+	if header := r.Header.Get("Last-Event-ID"); header != "" {
+		parsed, err := strconv.ParseUint(header, 10, 64)
+		if err != nil {
+			http.Error(w, "Invalid Last-Event-ID", http.StatusBadRequest)
+			return
+		}
+		lastID = parsed
+	}
+
+	// The stream outlives the server's write timeout
+	http.NewResponseController(w).SetWriteDeadline(time.Time{})
+	// Keep the shared copy alive for as long as the stream is open
+	s.saveSession(r.Context(), session)
+
+	w.Header().Set(SessionHeader, session.id)
+	flusher, ok := startEventStream(w)
+	if !ok {
+		http.Error(w, "Streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	flusher.Flush()
+
+	s.logger.WithField("session", session.id).Debug("Opened event stream")
+
+	for {
+		events, notify, closed := session.eventsAfter(lastID)
+		for _, event := range events {
+			if err := writeEvent(w, event); err != nil {
+				return
+			}
+			lastID = event.id
+		}
+		if len(events) > 0 {
+			flusher.Flush()
+			session.touch()
+		}
+		if closed {
+			return
+		}
+
+		select {
+		case <-notify:
+		case <-r.Context().Done():
+			return
+		case <-s.shutdown:
+			return
+		}
+	}
+}
+
+// handleStreamableDelete terminates a session at the client's request
+func (s *Server) handleStreamableDelete(w http.ResponseWriter, r *http.Request, creds authResult) {
+	session, status := s.lookupSession(r, creds)
+	if session == nil {
+		s.sessionError(w, r, status)
+		return
+	}
+
+	s.removeSession(session)
+	s.deleteSavedSession(r.Context(), session.id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// addSession tracks a new Streamable HTTP session and returns it, or the
+// session already tracked under its ID when another request restored it
+// first
+func (s *Server) addSession(session *streamSession) *streamSession {
+	s.sessionMu.Lock()
+	defer s.sessionMu.Unlock()
+	if existing, exists := s.sessions[session.id]; exists {
+		return existing
+	}
+
+	// Notifications for this client alone reach it on its GET stream
+	session.session.SetNotifier(func(notification *mcp.Message) {
+		if _, err := session.record(notification); err != nil {
+			s.logger.WithError(err).WithFields(logrus.Fields{
+				"session": session.id,
+				"method":  notification.Method,
+			}).Warn("Failed to queue notification")
+		}
+	})
+
+	s.sessions[session.id] = session
+
+	s.logger.WithFields(logrus.Fields{
+		"session": session.id,
+		"client":  session.info.RemoteAddr,
+	}).Info("New Streamable HTTP session")
+	return session
+}
+
+// removeSession ends and forgets a session
+func (s *Server) removeSession(session *streamSession) {
+	s.sessionMu.Lock()
+	delete(s.sessions, session.id)
+	s.sessionMu.Unlock()
+
+	session.close()
+	s.closeSession(session.session)
+	s.logger.WithField("session", session.id).Info("Streamable HTTP session closed")
+}
+
+// lookupSession finds the session named by the request header for a
+// caller with creds, returning the HTTP status to report when there is
+// none. Sessions opened on another replica are restored from a shared
+// state store. With authentication enabled every request needs credentials, and
+// sessions opened with other credentials are not found, so a leaked
+// session ID is of no use on its own.
+func (s *Server) lookupSession(r *http.Request, creds authResult) (*streamSession, int) {
+	id := r.Header.Get(SessionHeader)
+	if id == "" {
+		return nil, http.StatusBadRequest
+	}
+	if !creds.authenticated {
+		return nil, http.StatusUnauthorized
+	}
+
+	s.sessionMu.RLock()
+	session, exists := s.sessions[id]
+	s.sessionMu.RUnlock()
+	if !exists {
+		session = s.restoreSession(r, id)
+		exists = session != nil
+	}
+	if !exists || (s.authEnabled() && creds.principal != session.principal) {
+		return nil, http.StatusNotFound
+	}
+	return session, http.StatusOK
+}
+
+// saveSession stores what another replica needs to continue the session
+// in a shared state store, expiring after the session timeout
+func (s *Server) saveSession(ctx context.Context, session *streamSession) {
+	if !state.Shared(s.state) {
+		return
+	}
+	data, err := json.Marshal(session.session.State())
+	if err != nil {
+		s.logger.WithError(err).WithField("session", session.id).Warn("Failed to encode session")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, stateTimeout)
+	defer cancel()
+	ttl := time.Duration(s.config.Server.SessionTimeout) * time.Second
+	if err := s.state.Set(ctx, state.SessionPrefix+session.id, data, ttl); err != nil {
+		s.logger.WithError(err).WithField("session", session.id).Warn("Failed to save session")
+	}
+}
+
+// restoreSession continues a session another replica saved, returning nil
+// when there is none
+func (s *Server) restoreSession(r *http.Request, id string) *streamSession {
+	if !state.Shared(s.state) {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), stateTimeout)
+	defer cancel()
+	data, err := s.state.Get(ctx, state.SessionPrefix+id)
+	if err != nil {
+		if !errors.Is(err, state.ErrNotFound) {
+			s.logger.WithError(err).WithField("session", id).Warn("Failed to load session")
+		}
+		return nil
+	}
+	var saved mcp.SessionState
+	if err := json.Unmarshal(data, &saved); err != nil {
+		s.logger.WithError(err).WithField("session", id).Warn("Ignoring malformed saved session")
+		return nil
+	}
+
+	return s.addSession(restoreStreamSession(id, saved, s.getClientIP(r), r.RemoteAddr))
+}
+
+// deleteSavedSession removes a session from a shared state store. Other
+// replicas that restored it keep their copy until it expires there.
+func (s *Server) deleteSavedSession(ctx context.Context, id string) {
+	if !state.Shared(s.state) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, stateTimeout)
+	defer cancel()
+	if err := s.state.Delete(ctx, state.SessionPrefix+id); err != nil {
+		s.logger.WithError(err).WithField("session", id).Warn("Failed to delete saved session")
+	}
+}
+
+// sessionError answers a request whose session lookup failed with status
+func (s *Server) sessionError(w http.ResponseWriter, r *http.Request, status int) {
+	if status == http.StatusUnauthorized {
+		s.rejectUnauthenticated(w, r)
+		return
+	}
+	http.Error(w, http.StatusText(status), status)
+}
+
+// expireSessions closes sessions idle for longer than timeout until ctx is done
+func (s *Server) expireSessions(ctx context.Context, timeout time.Duration) {
+	ticker := time.NewTicker(timeout / 4)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.sessionMu.RLock()
+			var expired []*streamSession
+			for _, session := range s.sessions {
+				session.mu.Lock()
+				if now.Sub(session.lastSeen) > timeout {
+					expired = append(expired, session)
+				}
+				session.mu.Unlock()
+			}
+			s.sessionMu.RUnlock()
+
+			for _, session := range expired {
+				s.removeSession(session)
+			}
+		}
+	}
+}
+
+// decodeMessages parses a single JSON-RPC message or a batch
+func decodeMessages(body []byte) ([]*mcp.Message, bool, error) {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		var messages []*mcp.Message
+		if err := json.Unmarshal(trimmed, &messages); err != nil {
+			return nil, true, err
+		}
+		if len(messages) == 0 {
+			return nil, true, fmt.Errorf("empty batch")
+		}
+		return messages, true, nil
+	}
+
+	var message mcp.Message
+	if err := json.Unmarshal(trimmed, &message); err != nil {
+		return nil, false, err
+	}
+	return []*mcp.Message{&message}, false, nil
+}
+
+// acceptsEventStream reports whether the client accepts an SSE response
+func acceptsEventStream(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), "text/event-stream")
+}
+
+// startEventStream writes the SSE response headers
+func startEventStream(w http.ResponseWriter) (http.Flusher, bool) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return nil, false
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	return flusher, true
+}
+
+// writeEvent writes a single SSE message event, without an ID when the
+// event was not recorded for resumption
+func writeEvent(w io.Writer, event sseEvent) error {
+	if event.id == 0 {
+		_, err := fmt.Fprintf(w, "event: message\ndata: %s\n\n", event.data)
+		return err
+	}
+	_, err := fmt.Fprintf(w, "id: %d\nevent: message\ndata: %s\n\n", event.id, event.data)
+	return err
+}
+
+// writeJSON writes v as a JSON response body
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,276 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chongliujia/mcp-go-template/internal/config"
+	"github.com/chongliujia/mcp-go-template/internal/state"
+	"github.com/chongliujia/mcp-go-template/pkg/mcp"
+)
+
+// newTestServer serves the endpoints of a server created from cfg
+func newTestServer(t *testing.T, cfg *config.Config) *httptest.Server {
+	return newReplica(t, cfg, state.NewMemoryStore())
+}
+
+// newReplica serves the endpoints of a server created from cfg that keeps
+// its shared state in store
+func newReplica(t *testing.T, cfg *config.Config, store state.Store) *httptest.Server {
+	handler := mcp.NewBaseHandler(mcp.ServerInfo{Name: "test", Version: "1.0.0"}, mcp.ServerCapabilities{
+		Tools: &mcp.ToolsCapability{},
+	})
+	srv := New(cfg, handler)
+	srv.SetStateStore(store)
+	ts := httptest.NewServer(srv.routes())
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+// sharedStore is a MemoryStore standing in for a backend shared by replicas
+type sharedStore struct {
+	*state.MemoryStore
+}
+
+// post sends message to /mcp, in the session named by session unless it
+// is empty
+func post(t *testing.T, ts *httptest.Server, session string, message *mcp.Message, header http.Header) *http.Response {
+	body, _ := json.Marshal(message)
+	req, _ := http.NewRequest(http.MethodPost, ts.URL+"/mcp", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	if session != "" {
+		req.Header.Set(SessionHeader, session)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("POST failed: %v", err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+// initialize opens a session and returns its ID
+func initialize(t *testing.T, ts *httptest.Server, header http.Header) string {
+	resp := post(t, ts, "", mcp.NewRequest(1, "initialize", mcp.InitializeParams{ProtocolVersion: mcp.MCPVersion}), header)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected initialize to succeed, got %d", resp.StatusCode)
+	}
+	session := resp.Header.Get(SessionHeader)
+	if session == "" {
+		t.Fatal("Expected initialize to return a session ID")
+	}
+	post(t, ts, session, mcp.NewNotification("initialized", nil), header)
+	return session
+}
+
+func TestStreamable_SessionLifecycle(t *testing.T) {
+	ts := newTestServer(t, config.DefaultConfig())
+	session := initialize(t, ts, nil)
+
+	resp := post(t, ts, session, mcp.NewRequest(2, "ping", nil), nil)
+	var response mcp.Message
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil || response.Error != nil {
+		t.Fatalf("Expected ping to succeed in the session, got %v %+v", err, response.Error)
+	}
+
+	if resp := post(t, ts, "", mcp.NewRequest(3, "ping", nil), nil); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected a request without a session to fail with 400, got %d", resp.StatusCode)
+	}
+	if resp := post(t, ts, "unknown", mcp.NewRequest(3, "ping", nil), nil); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected an unknown session to fail with 404, got %d", resp.StatusCode)
+	}
+
+	req, _ := http.NewRequest(http.MethodDelete, ts.URL+"/mcp", nil)
+	req.Header.Set(SessionHeader, session)
+	deleted, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("DELETE failed: %v", err)
+	}
+	deleted.Body.Close()
+	if deleted.StatusCode != http.StatusNoContent {
+		t.Errorf("Expected DELETE to end the session with 204, got %d", deleted.StatusCode)
+	}
+	if resp := post(t, ts, session, mcp.NewRequest(4, "ping", nil), nil); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected a deleted session to fail with 404, got %d", resp.StatusCode)
+	}
+}
+
+func TestStreamable_ClosesSessionOnDelete(t *testing.T) {
+	handler := mcp.NewBaseHandler(mcp.ServerInfo{Name: "test", Version: "1.0.0"}, mcp.ServerCapabilities{})
+	var closed []string
+	handler.OnSessionClose(func(session *mcp.Session) {
+		closed = append(closed, session.ID())
+	})
+	ts := httptest.NewServer(New(config.DefaultConfig(), handler).routes())
+	defer ts.Close()
+	session := initialize(t, ts, nil)
+
+	header := http.Header{SessionHeader: {session}}
+	if status := send(t, http.MethodDelete, ts.URL+"/mcp", header); status != http.StatusNoContent {
+		t.Fatalf("Expected DELETE to end the session, got %d", status)
+	}
+	if len(closed) != 1 || closed[0] != session {
+		t.Errorf("Expected the handler to be told the session closed, got %v", closed)
+	}
+}
+
+func TestStreamable_ResumesFromLastEventID(t *testing.T) {
+	handler := mcp.NewBaseHandler(mcp.ServerInfo{Name: "test", Version: "1.0.0"}, mcp.ServerCapabilities{})
+	srv := New(config.DefaultConfig(), handler)
+	ts := httptest.NewServer(srv.routes())
+	defer ts.Close()
+	session := initialize(t, ts, nil)
+
+	// Responses streamed over SSE belong to their POST alone
+	stream := http.Header{"Accept": {"application/json, text/event-stream"}}
+	resp := post(t, ts, session, mcp.NewRequest(2, "ping", nil), stream)
+	if got := resp.Header.Get("Content-Type"); got != "text/event-stream" {
+		t.Fatalf("Expected an event stream, got %q", got)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	if !strings.Contains(string(body), `"id":2`) || strings.Contains(string(body), "id: ") {
+		t.Errorf("Expected the response as an event without an ID, got %q", body)
+	}
+
+	// Server-initiated messages are kept in the session's history
+	srv.sessionMu.RLock()
+	ss := srv.sessions[session]
+	srv.sessionMu.RUnlock()
+	for i := 1; i <= 2; i++ {
+		ss.session.Notify(mcp.NewNotification("notifications/message", map[string]interface{}{"data": i}))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL+"/mcp", nil)
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set(SessionHeader, session)
+	req.Header.Set("Last-Event-ID", "1")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	// Only the event after Last-Event-ID is replayed, and no response
+	scanner := bufio.NewScanner(resp.Body)
+	var lines []string
+	for scanner.Scan() && len(lines) < 3 {
+		lines = append(lines, scanner.Text())
+	}
+	if len(lines) < 3 || lines[0] != "id: 2" || !strings.Contains(lines[2], `"data":2`) {
+		t.Errorf("Expected the second notification to be replayed as event 2, got %q", lines)
+	}
+}
+
+func TestStreamable_ResponsesOutlastWriteTimeout(t *testing.T) {
+	handler := mcp.NewBaseHandler(mcp.ServerInfo{Name: "test", Version: "1.0.0"}, mcp.ServerCapabilities{
+		Tools: &mcp.ToolsCapability{},
+	})
+	handler.RegisterToolFunc(&mcp.Tool{Name: "slow", InputSchema: mcp.ToolSchema{Type: "object"}}, func(ctx context.Context, params map[string]interface{}) (*mcp.CallToolResult, error) {
+		time.Sleep(200 * time.Millisecond)
+		return &mcp.CallToolResult{Content: []mcp.Content{mcp.NewTextContent("done")}}, nil
+	})
+	ts := httptest.NewUnstartedServer(New(config.DefaultConfig(), handler).routes())
+	ts.Config.WriteTimeout = 100 * time.Millisecond
+	ts.Start()
+	defer ts.Close()
+	session := initialize(t, ts, nil)
+
+	stream := http.Header{"Accept": {"application/json, text/event-stream"}}
+	resp := post(t, ts, session, mcp.NewRequest(2, "tools/call", mcp.CallToolParams{Name: "slow"}), stream)
+	body, err := io.ReadAll(resp.Body)
+	if err != nil || !strings.Contains(string(body), "done") {
+		t.Errorf("Expected the slow result on the stream, got %q, %v", body, err)
+	}
+}
+
+func TestStreamable_AuthenticatesEveryRequest(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Security.APIKeys = []string{"alice-key", "bob-key"}
+	ts := newTestServer(t, cfg)
+
+	resp := post(t, ts, "", mcp.NewRequest(1, "initialize", mcp.InitializeParams{ProtocolVersion: mcp.MCPVersion}), nil)
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected initialize without credentials to fail with 401, got %d", resp.StatusCode)
+	}
+	if resp := post(t, ts, "", mcp.NewRequest(1, "ping", nil), http.Header{APIKeyHeader: {"wrong"}}); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected a wrong API key to fail with 401, got %d", resp.StatusCode)
+	}
+
+	alice := http.Header{APIKeyHeader: {"alice-key"}}
+	session := initialize(t, ts, alice)
+	if resp := post(t, ts, session, mcp.NewRequest(2, "ping", nil), alice); resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected the session's own credentials to be accepted, got %d", resp.StatusCode)
+	}
+	if resp := post(t, ts, session, mcp.NewRequest(3, "ping", nil), nil); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected a session request without credentials to fail with 401, got %d", resp.StatusCode)
+	}
+	if resp := post(t, ts, session, mcp.NewRequest(4, "ping", nil), http.Header{APIKeyHeader: {"bob-key"}}); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected another client's credentials to fail with 404, got %d", resp.StatusCode)
+	}
+}
+
+func TestStreamable_SessionsSharedBetweenReplicas(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Security.APIKeys = []string{"alice-key", "bob-key"}
+	store := sharedStore{state.NewMemoryStore()}
+	first, second := newReplica(t, cfg, store), newReplica(t, cfg, store)
+
+	alice := http.Header{APIKeyHeader: {"alice-key"}}
+	session := initialize(t, first, alice)
+	resp := post(t, second, session, mcp.NewRequest(2, "ping", nil), alice)
+	var response mcp.Message
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil || response.Error != nil {
+		t.Fatalf("Expected another replica to continue the session, got %d %v %+v", resp.StatusCode, err, response.Error)
+	}
+	if resp := post(t, second, session, mcp.NewRequest(3, "ping", nil), http.Header{APIKeyHeader: {"bob-key"}}); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected another client's credentials to fail with 404 on the other replica, got %d", resp.StatusCode)
+	}
+
+	req, _ := http.NewRequest(http.MethodDelete, first.URL+"/mcp", nil)
+	req.Header = alice.Clone()
+	req.Header.Set(SessionHeader, session)
+	deleted, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("DELETE failed: %v", err)
+	}
+	deleted.Body.Close()
+	third := newReplica(t, cfg, store)
+	if resp := post(t, third, session, mcp.NewRequest(4, "ping", nil), alice); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected a deleted session not to be restored, got %d", resp.StatusCode)
+	}
+}
+
+func TestRateLimit_SharedBetweenReplicas(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Server.RateLimit = config.RateLimitConfig{Enabled: true, RequestsPerSecond: 0.1, Burst: 3, Key: config.RateLimitByConnection}
+	store := sharedStore{state.NewMemoryStore()}
+	replicas := []*httptest.Server{newReplica(t, cfg, store), newReplica(t, cfg, store)}
+
+	// initialize takes the first of the session's 3 requests
+	session := initialize(t, replicas[0], nil)
+	var limited []int
+	for id := 2; id <= 4; id++ {
+		resp := post(t, replicas[id%2], session, mcp.NewRequest(id, "ping", nil), nil)
+		var response mcp.Message
+		json.NewDecoder(resp.Body).Decode(&response)
+		if response.Error != nil && response.Error.Code == mcp.RateLimited {
+			limited = append(limited, id)
+		}
+	}
+	if len(limited) != 1 || limited[0] != 4 {
+		t.Errorf("Expected only the fourth request across both replicas to be limited, got %v", limited)
+	}
+}
@@ -0,0 +1,180 @@
+package state
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// subscriberBuffer is the number of undelivered payloads kept per subscriber
+const subscriberBuffer = 64
+
+// pruneInterval is how often writes drop the entries that have expired
+const pruneInterval = time.Minute
+
+// memoryEntry is a stored value with an optional expiry
+type memoryEntry struct {
+	value   []byte
+	counter int64
+	expires time.Time
+}
+
+// expired reports whether the entry has passed its expiry
+func (e *memoryEntry) expired(now time.Time) bool {
+	return !e.expires.IsZero() && now.After(e.expires)
+}
+
+// MemoryStore is an in-process Store for single-replica deployments
+type MemoryStore struct {
+	mu          sync.Mutex
+	entries     map[string]*memoryEntry
+	lastPrune   time.Time
+	subscribers map[string]map[chan []byte]struct{}
+	closed      bool
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		entries:     make(map[string]*memoryEntry),
+		subscribers: make(map[string]map[chan []byte]struct{}),
+	}
+}
+
+// Get returns the value stored at key
+func (m *MemoryStore) Get(ctx context.Context, key string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, exists := m.entries[key]
+	if !exists || entry.expired(time.Now()) {
+		delete(m.entries, key)
+		return nil, ErrNotFound
+	}
+
+	value := make([]byte, len(entry.value))
+	copy(value, entry.value)
+	return value, nil
+}
+
+// Set stores value at key
+func (m *MemoryStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	entry := &memoryEntry{value: make([]byte, len(value))}
+	copy(entry.value, value)
+	if ttl > 0 {
+		entry.expires = time.Now().Add(ttl)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.prune(time.Now())
+	m.entries[key] = entry
+	return nil
+}
+
+// Delete removes key
+func (m *MemoryStore) Delete(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.entries, key)
+	return nil
+}
+
+// Incr increments the counter at key
+func (m *MemoryStore) Incr(ctx context.Context, key string, window time.Duration) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	m.prune(now)
+	entry, exists := m.entries[key]
+	if !exists || entry.expired(now) {
+		entry = &memoryEntry{}
+		if window > 0 {
+			entry.expires = now.Add(window)
+		}
+		m.entries[key] = entry
+	}
+
+	entry.counter++
+	return entry.counter, nil
+}
+
+// prune drops expired entries at most once per pruneInterval, so keys
+// that are never read again do not accumulate; the caller holds the lock
+func (m *MemoryStore) prune(now time.Time) {
+	if now.Sub(m.lastPrune) < pruneInterval {
+		return
+	}
+	m.lastPrune = now
+	for key, entry := range m.entries {
+		if entry.expired(now) {
+			delete(m.entries, key)
+		}
+	}
+}
+
+// Publish delivers payload to local subscribers of channel. Slow subscribers
+// whose buffers are full miss the payload rather than blocking the publisher.
+func (m *MemoryStore) Publish(ctx context.Context, channel string, payload []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for ch := range m.subscribers[channel] {
+		select {
+		case ch <- payload:
+		default:
+		}
+	}
+	return nil
+}
+
+// Subscribe registers a subscriber on channel until ctx is done
+func (m *MemoryStore) Subscribe(ctx context.Context, channel string) (<-chan []byte, error) {
+	ch := make(chan []byte, subscriberBuffer)
+
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		close(ch)
+		return ch, nil
+	}
+	if m.subscribers[channel] == nil {
+		m.subscribers[channel] = make(map[chan []byte]struct{})
+	}
+	m.subscribers[channel][ch] = struct{}{}
+	m.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		m.unsubscribe(channel, ch)
+	}()
+
+	return ch, nil
+}
+
+// unsubscribe removes and closes a subscriber channel
+func (m *MemoryStore) unsubscribe(channel string, ch chan []byte) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.subscribers[channel][ch]; exists {
+		delete(m.subscribers[channel], ch)
+		close(ch)
+	}
+}
+
+// Close closes all subscriber channels
+func (m *MemoryStore) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for channel, subs := range m.subscribers {
+		for ch := range subs {
+			close(ch)
+		}
+		delete(m.subscribers, channel)
+	}
+	m.closed = true
+	return nil
+}
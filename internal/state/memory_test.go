@@ -0,0 +1,88 @@
+package state
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMemoryStore_GetSetDelete(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+
+	if _, err := store.Get(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+
+	if err := store.Set(ctx, SessionPrefix+"abc", []byte("data"), 0); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	value, err := store.Get(ctx, SessionPrefix+"abc")
+	if err != nil || string(value) != "data" {
+		t.Errorf("Expected 'data', got %q (%v)", value, err)
+	}
+
+	store.Delete(ctx, SessionPrefix+"abc")
+	if _, err := store.Get(ctx, SessionPrefix+"abc"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound after delete, got %v", err)
+	}
+
+	store.Set(ctx, CachePrefix+"short", []byte("x"), time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	if _, err := store.Get(ctx, CachePrefix+"short"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected expired key to be missing, got %v", err)
+	}
+}
+
+func TestMemoryStore_Incr(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+
+	for want := int64(1); want <= 3; want++ {
+		got, err := store.Incr(ctx, RateLimitPrefix+"client", time.Hour)
+		if err != nil {
+			t.Fatalf("Incr failed: %v", err)
+		}
+		if got != want {
+			t.Errorf("Incr = %d, want %d", got, want)
+		}
+	}
+
+	store.Incr(ctx, RateLimitPrefix+"window", time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	if got, _ := store.Incr(ctx, RateLimitPrefix+"window", time.Millisecond); got != 1 {
+		t.Errorf("Expected counter to reset after window, got %d", got)
+	}
+}
+
+func TestMemoryStore_PubSub(t *testing.T) {
+	store := NewMemoryStore()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	ch, err := store.Subscribe(ctx, ResourceUpdatesChannel)
+	if err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+
+	store.Publish(context.Background(), ResourceUpdatesChannel, []byte("file:///a"))
+
+	select {
+	case payload := <-ch:
+		if string(payload) != "file:///a" {
+			t.Errorf("Unexpected payload %q", payload)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for published payload")
+	}
+
+	cancel()
+	select {
+	case _, ok := <-ch:
+		if ok {
+			t.Error("Expected subscription channel to be closed")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for subscription to close")
+	}
+}
@@ -0,0 +1,116 @@
+package state
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// incrScript increments a counter and sets its expiry on creation so the
+// window is applied atomically
+var incrScript = redis.NewScript(`
+local count = redis.call("INCR", KEYS[1])
+if count == 1 and tonumber(ARGV[1]) > 0 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+return count
+`)
+
+// RedisStore is a Store shared between replicas through Redis
+type RedisStore struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedisStore connects to Redis and verifies the connection
+func NewRedisStore(ctx context.Context, opts Options) (*RedisStore, error) {
+	client := redis.NewClient(&redis.Options{
+		Addr:        opts.RedisAddr,
+		Password:    opts.RedisPassword,
+		DB:          opts.RedisDB,
+		DialTimeout: opts.DialTimeout,
+	})
+
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to connect to redis at %s: %w", opts.RedisAddr, err)
+	}
+
+	return &RedisStore{
+		client: client,
+		prefix: opts.KeyPrefix,
+	}, nil
+}
+
+// Get returns the value stored at key
+func (r *RedisStore) Get(ctx context.Context, key string) ([]byte, error) {
+	value, err := r.client.Get(ctx, r.prefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrNotFound
+	}
+	return value, err
+}
+
+// Set stores value at key
+func (r *RedisStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return r.client.Set(ctx, r.prefix+key, value, ttl).Err()
+}
+
+// Delete removes key
+func (r *RedisStore) Delete(ctx context.Context, key string) error {
+	return r.client.Del(ctx, r.prefix+key).Err()
+}
+
+// Incr increments the counter at key
+func (r *RedisStore) Incr(ctx context.Context, key string, window time.Duration) (int64, error) {
+	return incrScript.Run(ctx, r.client, []string{r.prefix + key}, window.Milliseconds()).Int64()
+}
+
+// Publish sends payload to subscribers of channel on every replica
+func (r *RedisStore) Publish(ctx context.Context, channel string, payload []byte) error {
+	return r.client.Publish(ctx, r.prefix+channel, payload).Err()
+}
+
+// Subscribe delivers payloads published to channel until ctx is done
+func (r *RedisStore) Subscribe(ctx context.Context, channel string) (<-chan []byte, error) {
+	pubsub := r.client.Subscribe(ctx, r.prefix+channel)
+
+	// Wait for the subscription to be confirmed so no publish is missed
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return nil, fmt.Errorf("failed to subscribe to %s: %w", channel, err)
+	}
+
+	out := make(chan []byte, subscriberBuffer)
+	go func() {
+		defer close(out)
+		defer pubsub.Close()
+
+		messages := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-messages:
+				if !ok {
+					return
+				}
+				select {
+				case out <- []byte(msg.Payload):
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+// Close closes the Redis connection pool
+func (r *RedisStore) Close() error {
+	return r.client.Close()
+}
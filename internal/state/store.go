@@ -0,0 +1,102 @@
+package state
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrNotFound is returned by Get when a key does not exist or has expired
+var ErrNotFound = errors.New("state: key not found")
+
+// Supported backends
+const (
+	BackendMemory = "memory"
+	BackendRedis  = "redis"
+)
+
+// Key prefixes for the state shared between replicas
+const (
+	// SessionPrefix starts the keys of Streamable HTTP sessions
+	SessionPrefix = "session:"
+	// CachePrefix starts the keys of cached tool results
+	CachePrefix = "cache:"
+	// RateLimitPrefix starts the keys of rate limit counters
+	RateLimitPrefix = "ratelimit:"
+)
+
+// Pub/sub channels
+const (
+	// ResourceUpdatesChannel carries resource update notifications so every
+	// replica can forward them to its own subscribed clients
+	ResourceUpdatesChannel = "notifications/resources/updated"
+)
+
+// Store is a backend for state that must be consistent across server
+// replicas: sessions, cached tool results, rate limiter counters, and
+// cross-replica notifications. Implementations are safe for concurrent use.
+type Store interface {
+	// Get returns the value stored at key, or ErrNotFound
+	Get(ctx context.Context, key string) ([]byte, error)
+
+	// Set stores value at key. A ttl of zero means the key never expires.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+
+	// Delete removes key. Deleting a missing key is not an error.
+	Delete(ctx context.Context, key string) error
+
+	// Incr atomically increments the counter at key and returns the new
+	// value. The window is applied as the key's expiry when the counter is
+	// created, which gives fixed-window rate limiting.
+	Incr(ctx context.Context, key string, window time.Duration) (int64, error)
+
+	// Publish sends payload to every subscriber of channel on any replica
+	Publish(ctx context.Context, channel string, payload []byte) error
+
+	// Subscribe delivers payloads published to channel until ctx is done,
+	// at which point the returned channel is closed
+	Subscribe(ctx context.Context, channel string) (<-chan []byte, error)
+
+	// Close releases the backend's resources
+	Close() error
+}
+
+// Shared reports whether store is seen by other replicas, as opposed to
+// the in-process MemoryStore
+func Shared(store Store) bool {
+	_, local := store.(*MemoryStore)
+	return !local
+}
+
+// Options configures the state backend
+type Options struct {
+	Backend       string
+	RedisAddr     string
+	RedisPassword string
+	RedisDB       int
+	KeyPrefix     string
+	DialTimeout   time.Duration
+}
+
+// DefaultOptions returns options for a single-replica in-memory store
+func DefaultOptions() Options {
+	return Options{
+		Backend:     BackendMemory,
+		RedisAddr:   "localhost:6379",
+		KeyPrefix:   "mcp:",
+		DialTimeout: 5 * time.Second,
+	}
+}
+
+// New creates the store selected by opts.Backend
+func New(ctx context.Context, opts Options) (Store, error) {
+	switch opts.Backend {
+	case "", BackendMemory:
+		return NewMemoryStore(), nil
+	case BackendRedis:
+		return NewRedisStore(ctx, opts)
+	default:
+		return nil, fmt.Errorf("unknown state backend: %s", opts.Backend)
+	}
+}
@@ -0,0 +1,91 @@
+// Package telemetry configures OpenTelemetry tracing for the server.
+package telemetry
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+)
+
+// Options configures trace export
+type Options struct {
+	Enabled bool
+	// Endpoint is the OTLP/HTTP collector address, e.g. "localhost:4318"
+	Endpoint string
+	// URLPath overrides the collector's trace path; empty uses /v1/traces
+	URLPath string
+	// Insecure sends spans over plain HTTP
+	Insecure bool
+	Headers  map[string]string
+	// ServiceName is reported as the service.name resource attribute
+	ServiceName    string
+	ServiceVersion string
+	// SampleRatio is the fraction of new traces recorded, from 0 to 1
+	SampleRatio float64
+}
+
+// DefaultOptions returns options with tracing disabled
+func DefaultOptions() Options {
+	return Options{
+		Endpoint:    "localhost:4318",
+		Headers:     make(map[string]string),
+		ServiceName: "mcp-go-template",
+		SampleRatio: 1,
+	}
+}
+
+// Setup installs a global tracer provider that exports spans to the OTLP
+// endpoint. The returned function flushes pending spans and must be called
+// on shutdown. When tracing is disabled the global no-op provider is kept.
+func Setup(ctx context.Context, opts Options) (func(context.Context) error, error) {
+	if !opts.Enabled {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	clientOpts := []otlptracehttp.Option{
+		otlptracehttp.WithEndpoint(opts.Endpoint),
+	}
+	if opts.URLPath != "" {
+		clientOpts = append(clientOpts, otlptracehttp.WithURLPath(opts.URLPath))
+	}
+	if opts.Insecure {
+		clientOpts = append(clientOpts, otlptracehttp.WithInsecure())
+	}
+	if len(opts.Headers) > 0 {
+		clientOpts = append(clientOpts, otlptracehttp.WithHeaders(opts.Headers))
+	}
+
+	exporter, err := otlptracehttp.New(ctx, clientOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(opts.ServiceName),
+		semconv.ServiceVersion(opts.ServiceVersion),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(opts.SampleRatio))),
+	)
+
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	return provider.Shutdown, nil
+}
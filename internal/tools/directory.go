@@ -0,0 +1,200 @@
+package tools
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/chongliujia/mcp-go-template/pkg/mcp"
+	"github.com/chongliujia/mcp-go-template/pkg/utils"
+)
+
+// reloadDebounce coalesces bursts of filesystem events into one reload
+const reloadDebounce = 250 * time.Millisecond
+
+// ToolTarget is where a DirectoryLoader registers the tools it loads
+type ToolTarget interface {
+	RegisterTool(handler mcp.ToolHandler) error
+	UnregisterTool(name string) error
+	ListTools() ([]*mcp.Tool, error)
+}
+
+// loadedTool records a tool loaded from a manifest file
+type loadedTool struct {
+	path string
+	hash [sha256.Size]byte
+}
+
+// DirectoryLoader loads script tool manifests from a directory into a
+// ToolTarget and keeps them in sync as files are added, changed, or removed
+type DirectoryLoader struct {
+	dir      string
+	target   ToolTarget
+	onChange func()
+	logger   utils.Logger
+
+	mu     sync.Mutex
+	loaded map[string]loadedTool // tool name -> source manifest
+}
+
+// NewDirectoryLoader creates a loader for dir. onChange, if non-nil, is
+// called after a reload that added, changed, or removed tools.
+func NewDirectoryLoader(dir string, target ToolTarget, onChange func()) *DirectoryLoader {
+	return &DirectoryLoader{
+		dir:      dir,
+		target:   target,
+		onChange: onChange,
+		logger:   utils.DefaultLogger(),
+		loaded:   make(map[string]loadedTool),
+	}
+}
+
+// SetLogger sets the logger the loader logs to. It must be called before
+// Load.
+func (l *DirectoryLoader) SetLogger(logger utils.Logger) {
+	l.logger = logger
+}
+
+// Load scans the directory and brings the registered tools in line with the
+// manifests it contains
+func (l *DirectoryLoader) Load() error {
+	entries, err := os.ReadDir(l.dir)
+	if err != nil {
+		return fmt.Errorf("failed to read tools directory: %w", err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	existing, err := l.target.ListTools()
+	if err != nil {
+		return fmt.Errorf("failed to list tools: %w", err)
+	}
+	builtin := make(map[string]bool, len(existing))
+	for _, tool := range existing {
+		if _, ours := l.loaded[tool.Name]; !ours {
+			builtin[tool.Name] = true
+		}
+	}
+
+	found := make(map[string]bool)
+	changed := false
+
+	for _, entry := range entries {
+		if entry.IsDir() || !isManifestFile(entry.Name()) {
+			continue
+		}
+
+		path := filepath.Join(l.dir, entry.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			l.logger.Warnf("Skipping tool manifest %s: %v", path, err)
+			continue
+		}
+
+		manifest, err := parseScriptManifest(path, data)
+		if err != nil {
+			l.logger.Warnf("Skipping tool manifest: %v", err)
+			continue
+		}
+
+		if builtin[manifest.Name] {
+			l.logger.Warnf("Skipping tool manifest %s: tool '%s' is already registered", path, manifest.Name)
+			continue
+		}
+		if found[manifest.Name] {
+			l.logger.Warnf("Skipping tool manifest %s: duplicate tool name '%s'", path, manifest.Name)
+			continue
+		}
+		found[manifest.Name] = true
+
+		hash := sha256.Sum256(data)
+		if prev, exists := l.loaded[manifest.Name]; exists && prev.path == path && prev.hash == hash {
+			continue
+		}
+
+		if err := l.target.RegisterTool(NewScriptTool(*manifest, l.dir)); err != nil {
+			l.logger.Warnf("Failed to register tool from %s: %v", path, err)
+			continue
+		}
+		l.loaded[manifest.Name] = loadedTool{path: path, hash: hash}
+		changed = true
+		l.logger.Infof("Loaded tool %s from %s", manifest.Name, path)
+	}
+
+	for name := range l.loaded {
+		if found[name] {
+			continue
+		}
+		if err := l.target.UnregisterTool(name); err != nil {
+			l.logger.Warnf("Failed to unregister tool %s: %v", name, err)
+		}
+		delete(l.loaded, name)
+		changed = true
+		l.logger.Infof("Unloaded tool %s", name)
+	}
+
+	if changed && l.onChange != nil {
+		l.onChange()
+	}
+	return nil
+}
+
+// Watch reloads the directory whenever its contents change, until ctx is done
+func (l *DirectoryLoader) Watch(ctx context.Context) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create watcher: %w", err)
+	}
+	if err := watcher.Add(l.dir); err != nil {
+		watcher.Close()
+		return fmt.Errorf("failed to watch tools directory: %w", err)
+	}
+
+	go func() {
+		defer watcher.Close()
+
+		var reload <-chan time.Time
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if isManifestFile(event.Name) {
+					reload = time.After(reloadDebounce)
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				l.logger.Warnf("Tools directory watcher error: %v", err)
+			case <-reload:
+				reload = nil
+				if err := l.Load(); err != nil {
+					l.logger.Errorf("Failed to reload tools directory: %v", err)
+				}
+			}
+		}
+	}()
+
+	return nil
+}
+
+// isManifestFile reports whether name has a tool manifest extension
+func isManifestFile(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".json", ".yaml", ".yml":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,155 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chongliujia/mcp-go-template/pkg/mcp"
+)
+
+func TestDirectoryLoader_LoadAndReload(t *testing.T) {
+	if _, err := exec.LookPath("cat"); err != nil {
+		t.Skip("cat not available")
+	}
+
+	dir := t.TempDir()
+	manifest := filepath.Join(dir, "echo.json")
+	writeFile(t, manifest, `{"name": "echo", "description": "Echo arguments", "command": "cat"}`)
+
+	handler := mcp.NewBaseHandler(mcp.ServerInfo{Name: "test", Version: "1.0.0"}, mcp.ServerCapabilities{})
+	changes := 0
+	loader := NewDirectoryLoader(dir, handler, func() { changes++ })
+
+	if err := loader.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if names := toolNames(t, handler); len(names) != 1 || !names["echo"] {
+		t.Fatalf("Expected echo tool, got %v", names)
+	}
+	if changes != 1 {
+		t.Errorf("Expected 1 change notification, got %d", changes)
+	}
+
+	// Reloading unchanged manifests is a no-op
+	loader.Load()
+	if changes != 1 {
+		t.Errorf("Expected no change notification for unchanged directory, got %d", changes)
+	}
+
+	// Replace the manifest with a differently named tool
+	os.Remove(manifest)
+	writeFile(t, filepath.Join(dir, "shout.yaml"), "name: shout\ncommand: cat\n")
+	loader.Load()
+
+	names := toolNames(t, handler)
+	if len(names) != 1 || !names["shout"] {
+		t.Errorf("Expected only shout tool after reload, got %v", names)
+	}
+	if changes != 2 {
+		t.Errorf("Expected 2 change notifications, got %d", changes)
+	}
+}
+
+func TestDirectoryLoader_SkipsBuiltinNames(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "calc.json"), `{"name": "builtin", "command": "cat"}`)
+
+	handler := mcp.NewBaseHandler(mcp.ServerInfo{Name: "test", Version: "1.0.0"}, mcp.ServerCapabilities{})
+	builtin := NewScriptTool(ScriptManifest{Name: "builtin", Description: "original", Command: "true"}, dir)
+	handler.RegisterTool(builtin)
+
+	loader := NewDirectoryLoader(dir, handler, nil)
+	if err := loader.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	tools, _ := handler.ListTools()
+	if len(tools) != 1 || tools[0].Description != "original" {
+		t.Errorf("Expected built-in tool to be kept, got %+v", tools)
+	}
+}
+
+func TestScriptTool_Execute(t *testing.T) {
+	if _, err := exec.LookPath("cat"); err != nil {
+		t.Skip("cat not available")
+	}
+
+	tool := NewScriptTool(ScriptManifest{Name: "echo", Command: "cat"}, t.TempDir())
+	result, err := tool.Execute(context.Background(), map[string]interface{}{"text": "hi"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("Expected success, got error: %s", result.Content[0].Text)
+	}
+	if result.Content[0].Text != `{"text":"hi"}` {
+		t.Errorf("Unexpected output: %s", result.Content[0].Text)
+	}
+}
+
+func TestScriptTool_Bounds(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	t.Setenv("MCP_TEST_SECRET", "hunter2")
+
+	env := NewScriptTool(ScriptManifest{
+		Name: "env", Command: "sh", Args: []string{"-c", "env"},
+		Env: map[string]string{"GREETING": "hi"},
+	}, t.TempDir())
+	result, _ := env.Execute(context.Background(), nil)
+	if result.IsError {
+		t.Fatalf("Expected success, got error: %s", result.Content[0].Text)
+	}
+	output := result.Content[0].Text
+	if strings.Contains(output, "MCP_TEST_SECRET") {
+		t.Error("Expected the server's environment not to reach the script")
+	}
+	if !strings.Contains(output, "GREETING=hi") || !strings.Contains(output, "MCP_TOOL_NAME=env") {
+		t.Errorf("Expected the manifest's env and the tool's name, got %q", output)
+	}
+
+	large := NewScriptTool(ScriptManifest{
+		Name: "large", Command: "sh", Args: []string{"-c", "printf 0123456789"}, MaxOutputBytes: 4,
+	}, t.TempDir())
+	if result, _ := large.Execute(context.Background(), nil); !result.IsError {
+		t.Errorf("Expected output over MaxOutputBytes to fail, got %q", result.Content[0].Text)
+	}
+
+	// The background sleep keeps stdout open after the shell is killed
+	slow := NewScriptTool(ScriptManifest{
+		Name: "slow", Command: "sh", Args: []string{"-c", "sleep 30 & sleep 30"}, Timeout: 1,
+	}, t.TempDir())
+	start := time.Now()
+	if result, _ := slow.Execute(context.Background(), nil); !result.IsError {
+		t.Error("Expected a timed out script to fail")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected the timeout to stop the script's processes, took %v", elapsed)
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+}
+
+func toolNames(t *testing.T, handler *mcp.BaseHandler) map[string]bool {
+	t.Helper()
+	tools, err := handler.ListTools()
+	if err != nil {
+		t.Fatalf("ListTools failed: %v", err)
+	}
+	names := make(map[string]bool, len(tools))
+	for _, tool := range tools {
+		names[tool.Name] = true
+	}
+	return names
+}
@@ -0,0 +1,95 @@
+package tools
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/chongliujia/mcp-go-template/pkg/mcp"
+)
+
+// uncategorized is the heading for tools without a category
+const uncategorized = "other"
+
+// GenerateMarkdown renders reference documentation for tools, grouped by
+// category and sorted by name within each group
+func GenerateMarkdown(tools []*mcp.Tool) string {
+	groups := make(map[string][]*mcp.Tool)
+	for _, tool := range tools {
+		category := tool.Category
+		if category == "" {
+			category = uncategorized
+		}
+		groups[category] = append(groups[category], tool)
+	}
+
+	categories := make([]string, 0, len(groups))
+	for category := range groups {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+
+	var b strings.Builder
+	b.WriteString("# Tools\n")
+
+	for _, category := range categories {
+		group := groups[category]
+		sort.Slice(group, func(i, j int) bool {
+			return group[i].Name < group[j].Name
+		})
+
+		b.WriteString(fmt.Sprintf("\n## %s\n", strings.ToUpper(category[:1])+category[1:]))
+		for _, tool := range group {
+			b.WriteString(fmt.Sprintf("\n### %s\n\n", tool.Name))
+			if tool.Description != "" {
+				b.WriteString(tool.Description + "\n\n")
+			}
+			if tool.Deprecated != nil {
+				b.WriteString(fmt.Sprintf("**%s**\n\n", tool.Deprecated))
+			}
+			if tool.Version != "" {
+				b.WriteString(fmt.Sprintf("Version: %s\n\n", tool.Version))
+			}
+			if len(tool.Tags) > 0 {
+				b.WriteString(fmt.Sprintf("Tags: %s\n\n", strings.Join(tool.Tags, ", ")))
+			}
+			writeParameters(&b, tool.InputSchema)
+		}
+	}
+
+	return b.String()
+}
+
+// writeParameters renders a tool's input schema as a parameter table
+func writeParameters(b *strings.Builder, schema mcp.ToolSchema) {
+	if len(schema.Properties) == 0 {
+		return
+	}
+
+	required := make(map[string]bool, len(schema.Required))
+	for _, name := range schema.Required {
+		required[name] = true
+	}
+
+	names := make([]string, 0, len(schema.Properties))
+	for name := range schema.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	b.WriteString("| Parameter | Type | Required | Description |\n")
+	b.WriteString("|-----------|------|----------|-------------|\n")
+	for _, name := range names {
+		var paramType, description string
+		if prop, ok := schema.Properties[name].(map[string]interface{}); ok {
+			paramType, _ = prop["type"].(string)
+			description, _ = prop["description"].(string)
+		}
+		req := "no"
+		if required[name] {
+			req = "yes"
+		}
+		b.WriteString(fmt.Sprintf("| %s | %s | %s | %s |\n", name, paramType, req, description))
+	}
+	b.WriteString("\n")
+}
@@ -14,12 +14,21 @@ type CalculatorTool struct {
 	definition *mcp.Tool
 }
 
+// calculatorArgs are the arguments of the calculator tool
+type calculatorArgs struct {
+	Operation string  `json:"operation" enum:"add,subtract,multiply,divide,power"`
+	A         float64 `json:"a"`
+	B         float64 `json:"b"`
+}
+
 // NewCalculatorTool creates a new calculator tool
 func NewCalculatorTool() *CalculatorTool {
 	return &CalculatorTool{
 		definition: &mcp.Tool{
 			Name:        "calculator",
 			Description: "Performs basic mathematical operations including addition, subtraction, multiplication, division, and power calculations",
+			Category:    mcp.ToolCategoryMath,
+			Tags:        []string{"arithmetic"},
 			InputSchema: mcp.ToolSchema{
 				Type: "object",
 				Properties: map[string]interface{}{
@@ -50,40 +59,18 @@ func (c *CalculatorTool) Definition() *mcp.Tool {
 
 // Execute performs the mathematical calculation
 func (c *CalculatorTool) Execute(ctx context.Context, params map[string]interface{}) (*mcp.CallToolResult, error) {
-	// Extract parameters
-	operation, ok := params["operation"].(string)
-	if !ok {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{{
-				Type: "text",
-				Text: "Error: operation must be a string",
-			}},
-			IsError: true,
-		}, nil
-	}
-
-	// Convert numbers from interface{}
-	aVal, err := parseNumber(params["a"])
-	if err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{{
-				Type: "text",
-				Text: fmt.Sprintf("Error: invalid first number: %v", err),
-			}},
-			IsError: true,
-		}, nil
-	}
-
-	bVal, err := parseNumber(params["b"])
-	if err != nil {
+	// Bind parameters; numbers sent as strings are accepted
+	var args calculatorArgs
+	if err := mcp.BindArguments(params, &args); err != nil {
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{{
 				Type: "text",
-				Text: fmt.Sprintf("Error: invalid second number: %v", err),
+				Text: fmt.Sprintf("Error: %v", err),
 			}},
 			IsError: true,
 		}, nil
 	}
+	operation, aVal, bVal := args.Operation, args.A, args.B
 
 	// Perform calculation with enhanced error checking
 	var result float64
@@ -1,8 +1,8 @@
 package examples
 
 import (
+	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
 	"io"
 	"math"
@@ -10,18 +10,52 @@ import (
 	"net/url"
 	"os"
 	"regexp"
+	"runtime"
 	"sort"
 	"strings"
 	"time"
 
 	"golang.org/x/net/html"
+	"golang.org/x/sync/errgroup"
 	"github.com/chongliujia/mcp-go-template/pkg/mcp"
+	"github.com/chongliujia/mcp-go-template/pkg/utils/httpclient"
 )
 
+// Precompiled regular expressions shared across analyzer invocations
+var (
+	sentenceEndRegex   = regexp.MustCompile(`[.!?]+`)
+	sentenceSplitRegex = regexp.MustCompile(`[.!?]+\s+`)
+	whitespaceRegex    = regexp.MustCompile(`\s+`)
+
+	headerRegex = regexp.MustCompile(`(?m)^#+\s+`)
+	listRegex   = regexp.MustCompile(`(?m)^[\s]*[*\-+]\s+|^[\s]*\d+\.\s+`)
+	linkRegex   = regexp.MustCompile(`https?://[^\s]+|\[([^\]]+)\]\([^)]+\)`)
+	imageRegex  = regexp.MustCompile(`!\[([^\]]*)\]\([^)]+\)|<img[^>]+>`)
+
+	scriptTagRegex = regexp.MustCompile(`(?i)<script[^>]*>.*?</script>`)
+	styleTagRegex  = regexp.MustCompile(`(?i)<style[^>]*>.*?</style>`)
+	commentRegex   = regexp.MustCompile(`<!--.*?-->`)
+	htmlTagRegex   = regexp.MustCompile(`<[^>]*>`)
+
+	// documentEntityPatterns holds simple regex patterns for entity recognition
+	documentEntityPatterns = map[string]*regexp.Regexp{
+		"PERSON":       regexp.MustCompile(`\b[A-Z][a-z]+ [A-Z][a-z]+\b`), // Simple name pattern
+		"LOCATION":     regexp.MustCompile(`\b(?:New York|London|Paris|Tokyo|Beijing|Los Angeles|Chicago|San Francisco)\b`),
+		"ORGANIZATION": regexp.MustCompile(`\b(?:Google|Microsoft|Apple|Amazon|Facebook|IBM|Oracle|Intel)\b`),
+		"DATE":         regexp.MustCompile(`\b\d{1,2}/\d{1,2}/\d{4}\b|\b\d{4}-\d{2}-\d{2}\b`),
+		"MONEY":        regexp.MustCompile(`\$\d+(?:,\d{3})*(?:\.\d{2})?\b`),
+	}
+)
+
+// maxSampledChars bounds the document text sent to the client's model for
+// an llm_summary
+const maxSampledChars = 20000
+
 // DocumentAnalyzerTool implements document analysis functionality
 type DocumentAnalyzerTool struct {
-	definition *mcp.Tool
-	client     *http.Client
+	definition  *mcp.Tool
+	client      *http.Client
+	parallelism int
 }
 
 // DocumentAnalysis represents the analysis result of a document
@@ -78,12 +112,26 @@ type DocumentStructure struct {
 	ImageCount    int      `json:"image_count"`
 }
 
+// documentAnalyzerArgs are the arguments of the document analyzer tool
+type documentAnalyzerArgs struct {
+	InputType       string `json:"input_type" enum:"text,file,url"`
+	Content         string `json:"content"`
+	AnalysisDepth   string `json:"analysis_depth" enum:"basic,standard,comprehensive" default:"standard"`
+	ExtractKeywords bool   `json:"extract_keywords" default:"true"`
+	ExtractEntities bool   `json:"extract_entities" default:"true"`
+	GenerateSummary bool   `json:"generate_summary" default:"true"`
+	LLMSummary      bool   `json:"llm_summary,omitempty"`
+	MaxKeywords     int    `json:"max_keywords" default:"20" minimum:"5" maximum:"100"`
+}
+
 // NewDocumentAnalyzerTool creates a new document analyzer tool
 func NewDocumentAnalyzerTool() *DocumentAnalyzerTool {
 	return &DocumentAnalyzerTool{
 		definition: &mcp.Tool{
 			Name:        "document_analyzer",
 			Description: "Analyzes documents from files, URLs, or direct text input. Provides comprehensive analysis including keyword extraction, entity recognition, readability metrics, sentiment analysis, and document structure analysis",
+			Category:    mcp.ToolCategoryResearch,
+			Tags:        []string{"nlp", "network", "filesystem"},
 			InputSchema: mcp.ToolSchema{
 				Type: "object",
 				Properties: map[string]interface{}{
@@ -117,6 +165,11 @@ func NewDocumentAnalyzerTool() *DocumentAnalyzerTool {
 						"description": "Whether to generate a document summary",
 						"default":     true,
 					},
+					"llm_summary": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Ask the client's LLM for the summary via sampling when the client supports it",
+						"default":     false,
+					},
 					"max_keywords": map[string]interface{}{
 						"type":        "integer",
 						"description": "Maximum number of keywords to extract",
@@ -127,13 +180,40 @@ func NewDocumentAnalyzerTool() *DocumentAnalyzerTool {
 				},
 				Required: []string{"input_type", "content"},
 			},
+			OutputSchema: &mcp.ToolSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"source":          map[string]interface{}{"type": "string"},
+					"type":            map[string]interface{}{"type": "string"},
+					"word_count":      map[string]interface{}{"type": "integer"},
+					"char_count":      map[string]interface{}{"type": "integer"},
+					"sentence_count":  map[string]interface{}{"type": "integer"},
+					"paragraph_count": map[string]interface{}{"type": "integer"},
+					"reading_time":    map[string]interface{}{"type": "string"},
+					"language":        map[string]interface{}{"type": "string"},
+					"keywords":        map[string]interface{}{"type": []string{"array", "null"}},
+					"summary":         map[string]interface{}{"type": "string"},
+					"entities":        map[string]interface{}{"type": []string{"array", "null"}},
+					"statistics":      map[string]interface{}{"type": "object"},
+					"metadata":        map[string]interface{}{"type": "object"},
+				},
+				Required: []string{"source", "type", "word_count", "char_count"},
+			},
 		},
-		client: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		client:      httpclient.New("document_analyzer"),
+		parallelism: runtime.NumCPU(),
 	}
 }
 
+// SetParallelism sets how many analysis stages may run concurrently.
+// Values below one run the stages sequentially.
+func (d *DocumentAnalyzerTool) SetParallelism(n int) {
+	if n < 1 {
+		n = 1
+	}
+	d.parallelism = n
+}
+
 // Definition returns the tool definition
 func (d *DocumentAnalyzerTool) Definition() *mcp.Tool {
 	return d.definition
@@ -144,19 +224,17 @@ func (d *DocumentAnalyzerTool) Execute(ctx context.Context, params map[string]in
 	startTime := time.Now()
 
 	// Extract parameters
-	inputType, ok := params["input_type"].(string)
-	if !ok {
+	var args documentAnalyzerArgs
+	if err := mcp.BindArguments(params, &args); err != nil {
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{{
 				Type: "text",
-				Text: "Error: input_type is required and must be a string",
+				Text: fmt.Sprintf("Error: %v", err),
 			}},
 			IsError: true,
 		}, nil
 	}
-
-	content, ok := params["content"].(string)
-	if !ok || content == "" {
+	if args.Content == "" {
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{{
 				Type: "text",
@@ -165,44 +243,17 @@ func (d *DocumentAnalyzerTool) Execute(ctx context.Context, params map[string]in
 			IsError: true,
 		}, nil
 	}
+	inputType, content := args.InputType, args.Content
+	analysisDepth, maxKeywords := args.AnalysisDepth, args.MaxKeywords
+	extractKeywords, extractEntities := args.ExtractKeywords, args.ExtractEntities
+	generateSummary, llmSummary := args.GenerateSummary, args.LLMSummary
 
-	analysisDepth := "standard"
-	if val, exists := params["analysis_depth"]; exists {
-		if depth, ok := val.(string); ok {
-			analysisDepth = depth
-		}
-	}
-
-	extractKeywords := true
-	if val, exists := params["extract_keywords"]; exists {
-		if extract, ok := val.(bool); ok {
-			extractKeywords = extract
-		}
-	}
-
-	extractEntities := true
-	if val, exists := params["extract_entities"]; exists {
-		if extract, ok := val.(bool); ok {
-			extractEntities = extract
-		}
-	}
-
-	generateSummary := true
-	if val, exists := params["generate_summary"]; exists {
-		if generate, ok := val.(bool); ok {
-			generateSummary = generate
-		}
-	}
-
-	maxKeywords := 20
-	if val, exists := params["max_keywords"]; exists {
-		if max, ok := val.(float64); ok {
-			maxKeywords = int(max)
-		}
-	}
+	// Fetching a URL and a comprehensive analysis can each take a while
+	progress := mcp.ProgressFromContext(ctx)
 
 	// Get document text
-	text, source, err := d.getDocumentText(inputType, content)
+	progress(0, 3, "Retrieving document")
+	text, source, err := d.getDocumentText(ctx, inputType, content)
 	if err != nil {
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{{
@@ -214,39 +265,43 @@ func (d *DocumentAnalyzerTool) Execute(ctx context.Context, params map[string]in
 	}
 
 	// Perform analysis
-	analysis := d.analyzeDocument(text, source, inputType, analysisDepth, extractKeywords, extractEntities, generateSummary, maxKeywords)
+	progress(1, 3, "Analyzing document")
+	analysis, err := d.analyzeDocument(ctx, text, source, inputType, analysisDepth, extractKeywords, extractEntities, generateSummary, maxKeywords)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{{
+				Type: "text",
+				Text: fmt.Sprintf("Error analyzing document: %v", err),
+			}},
+			IsError: true,
+		}, nil
+	}
 	
+	// Replace the extractive summary with one from the client's model,
+	// keeping it if sampling is unavailable or declined
+	if generateSummary && llmSummary {
+		analysis.Metadata["summary_source"] = "extractive"
+		if summary, err := d.sampleSummary(ctx, text); err != nil {
+			analysis.Metadata["summary_error"] = err.Error()
+		} else {
+			analysis.Summary = summary
+			analysis.Metadata["summary_source"] = "sampling"
+		}
+	}
+
 	duration := time.Since(startTime)
 	analysis.Metadata["analysis_duration"] = duration.String()
 	analysis.Metadata["analysis_time"] = time.Now().Format(time.RFC3339)
 
 	// Format results
+	progress(2, 3, "Formatting results")
 	resultText := d.formatAnalysisResults(analysis)
 
-	// Convert to JSON
-	jsonData, err := json.MarshalIndent(analysis, "", "  ")
-	if err != nil {
-		jsonData = []byte(fmt.Sprintf(`{"error": "failed to marshal analysis: %v"}`, err))
-	}
-
-	return &mcp.CallToolResult{
-		Content: []mcp.Content{
-			{
-				Type: "text",
-				Text: resultText,
-			},
-			{
-				Type:     "text",
-				Text:     string(jsonData),
-				MimeType: "application/json",
-			},
-		},
-		IsError: false,
-	}, nil
+	return mcp.NewStructuredResult(resultText, analysis), nil
 }
 
 // getDocumentText retrieves text content based on input type with improved error handling
-func (d *DocumentAnalyzerTool) getDocumentText(inputType, content string) (string, string, error) {
+func (d *DocumentAnalyzerTool) getDocumentText(ctx context.Context, inputType, content string) (string, string, error) {
 	switch inputType {
 	case "text":
 		// Validate text content
@@ -279,6 +334,13 @@ func (d *DocumentAnalyzerTool) getDocumentText(inputType, content string) (strin
 		if err != nil {
 			return "", "", fmt.Errorf("failed to read file %s: %w", content, err)
 		}
+		if bytes.HasPrefix(data, []byte("%PDF")) {
+			text, err := ExtractPDFText(data)
+			if err != nil {
+				return "", "", fmt.Errorf("failed to extract text from %s: %w", content, err)
+			}
+			return text, content, nil
+		}
 		return string(data), content, nil
 		
 	case "url":
@@ -296,12 +358,11 @@ func (d *DocumentAnalyzerTool) getDocumentText(inputType, content string) (strin
 			return "", "", fmt.Errorf("unsupported URL scheme: %s (only http/https supported)", parsedURL.Scheme)
 		}
 		
-		req, err := http.NewRequest("GET", content, nil)
+		req, err := http.NewRequestWithContext(ctx, "GET", content, nil)
 		if err != nil {
 			return "", "", fmt.Errorf("failed to create request for URL %s: %w", content, err)
 		}
 		
-		req.Header.Set("User-Agent", "Mozilla/5.0 (compatible; MCP-Document-Analyzer/1.0)")
 		req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
 		req.Header.Set("Accept-Language", "en-US,en;q=0.5")
 		req.Header.Set("Accept-Encoding", "identity") // Disable compression for simplicity
@@ -333,6 +394,10 @@ func (d *DocumentAnalyzerTool) getDocumentText(inputType, content string) (strin
 		var text string
 		if strings.Contains(contentType, "text/html") || strings.Contains(contentType, "application/xhtml") {
 			text = d.stripHTML(string(body))
+		} else if strings.Contains(contentType, "application/pdf") {
+			if text, err = ExtractPDFText(body); err != nil {
+				return "", "", fmt.Errorf("failed to extract text from URL %s: %w", content, err)
+			}
 		} else {
 			// For plain text or other formats, use as-is
 			text = string(body)
@@ -350,8 +415,10 @@ func (d *DocumentAnalyzerTool) getDocumentText(inputType, content string) (strin
 	}
 }
 
-// analyzeDocument performs comprehensive document analysis
-func (d *DocumentAnalyzerTool) analyzeDocument(text, source, inputType, analysisDepth string, extractKeywords, extractEntities, generateSummary bool, maxKeywords int) *DocumentAnalysis {
+// analyzeDocument performs comprehensive document analysis. Independent
+// stages run concurrently, bounded by the tool's parallelism setting; each
+// stage writes to its own fields of the analysis.
+func (d *DocumentAnalyzerTool) analyzeDocument(ctx context.Context, text, source, inputType, analysisDepth string, extractKeywords, extractEntities, generateSummary bool, maxKeywords int) (*DocumentAnalysis, error) {
 	analysis := &DocumentAnalysis{
 		Source:   source,
 		Type:     inputType,
@@ -366,48 +433,93 @@ func (d *DocumentAnalyzerTool) analyzeDocument(text, source, inputType, analysis
 	analysis.ReadingTime = d.calculateReadingTime(analysis.WordCount)
 	analysis.Language = d.detectLanguage(text)
 
-	// Document structure analysis
-	analysis.Statistics.DocumentStructure = d.analyzeDocumentStructure(text)
-
 	// Basic statistics calculations
 	if analysis.WordCount > 0 {
 		analysis.Statistics.AvgWordsPerSentence = float64(analysis.WordCount) / float64(analysis.SentenceCount)
 		analysis.Statistics.AvgCharsPerWord = float64(analysis.CharCount) / float64(analysis.WordCount)
 	}
 
+	// Tokenize once; keyword, diversity, summary, sentiment, topic, and
+	// complexity stages all read from the same index
+	idx := newTokenIndex(text)
+
+	g, gctx := errgroup.WithContext(ctx)
+	parallelism := d.parallelism
+	if parallelism < 1 {
+		parallelism = 1
+	}
+	g.SetLimit(parallelism)
+
+	stage := func(fn func()) {
+		g.Go(func() error {
+			if err := gctx.Err(); err != nil {
+				return err
+			}
+			fn()
+			return nil
+		})
+	}
+
+	// Document structure analysis
+	stage(func() {
+		analysis.Statistics.DocumentStructure = d.analyzeDocumentStructure(text)
+	})
+
 	// Keyword extraction
 	if extractKeywords {
-		analysis.Keywords = d.extractKeywords(text, maxKeywords)
-		analysis.Statistics.LexicalDiversity = d.calculateLexicalDiversity(text)
+		stage(func() {
+			analysis.Keywords = d.extractKeywords(idx, maxKeywords)
+		})
+		stage(func() {
+			analysis.Statistics.LexicalDiversity = d.calculateLexicalDiversity(idx)
+		})
 	}
 
 	// Entity extraction
 	if extractEntities {
-		analysis.Entities = d.extractEntities(text)
+		stage(func() {
+			analysis.Entities = d.extractEntities(text)
+		})
 	}
 
 	// Summary generation
 	if generateSummary {
-		analysis.Summary = d.generateSummary(text)
+		stage(func() {
+			analysis.Summary = d.generateSummary(text, idx)
+		})
 	}
 
 	// Advanced analysis based on depth
 	switch analysisDepth {
 	case "comprehensive":
-		analysis.Statistics.ComplexityScore = d.calculateComplexityScore(text)
-		analysis.Statistics.SentimentScore = d.calculateSentimentScore(text)
-		analysis.Statistics.TopicDistribution = d.analyzeTopicDistribution(text)
-		fallthrough
+		// Falls back to the standard score, within the same stage so the
+		// two never race
+		stage(func() {
+			analysis.Statistics.ComplexityScore = d.calculateComplexityScore(text, idx)
+			if analysis.Statistics.ComplexityScore == 0 {
+				analysis.Statistics.ComplexityScore = d.calculateBasicComplexity(text, idx)
+			}
+		})
+		stage(func() {
+			analysis.Statistics.SentimentScore = d.calculateSentimentScore(idx)
+		})
+		stage(func() {
+			analysis.Statistics.TopicDistribution = d.analyzeTopicDistribution(idx)
+		})
 	case "standard":
 		// Standard analysis includes all basic metrics
-		if analysis.Statistics.ComplexityScore == 0 {
-			analysis.Statistics.ComplexityScore = d.calculateBasicComplexity(text)
-		}
+		stage(func() {
+			analysis.Statistics.ComplexityScore = d.calculateBasicComplexity(text, idx)
+		})
 	case "basic":
 		// Basic analysis only includes fundamental metrics (already calculated above)
 	}
 
-	return analysis
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	return analysis, nil
 }
 
 // countWords counts words in the text
@@ -419,8 +531,7 @@ func (d *DocumentAnalyzerTool) countWords(text string) int {
 // countSentences counts sentences in the text
 func (d *DocumentAnalyzerTool) countSentences(text string) int {
 	// Simple sentence detection based on punctuation
-	re := regexp.MustCompile(`[.!?]+`)
-	sentences := re.Split(text, -1)
+	sentences := sentenceEndRegex.Split(text, -1)
 	count := 0
 	for _, sentence := range sentences {
 		if strings.TrimSpace(sentence) != "" {
@@ -483,24 +594,16 @@ func (d *DocumentAnalyzerTool) detectLanguage(text string) string {
 	return "Unknown"
 }
 
-// extractKeywords extracts keywords and their frequencies
-func (d *DocumentAnalyzerTool) extractKeywords(text string, maxKeywords int) []KeywordInfo {
-	// Clean and tokenize text
-	words := d.tokenizeText(text)
-	
-	// Count word frequencies
-	wordFreq := make(map[string]int)
-	for _, word := range words {
-		if len(word) >= 3 && !d.isStopWord(word) { // Filter short words and stop words
-			wordFreq[word]++
-		}
-	}
-	
+// extractKeywords extracts keywords and their frequencies from the token index
+func (d *DocumentAnalyzerTool) extractKeywords(idx *tokenIndex, maxKeywords int) []KeywordInfo {
 	// Convert to KeywordInfo and sort by frequency
 	var keywords []KeywordInfo
-	totalWords := len(words)
+	totalWords := idx.Len()
 	
-	for word, freq := range wordFreq {
+	for word, freq := range idx.freq {
+		if len(word) < 3 || d.isStopWord(word) { // Filter short words and stop words
+			continue
+		}
 		score := float64(freq) / float64(totalWords) // Simple TF score
 		keywords = append(keywords, KeywordInfo{
 			Word:      word,
@@ -509,8 +612,11 @@ func (d *DocumentAnalyzerTool) extractKeywords(text string, maxKeywords int) []K
 		})
 	}
 	
-	// Sort by frequency (descending)
+	// Sort by frequency (descending), alphabetically for ties so results are stable
 	sort.Slice(keywords, func(i, j int) bool {
+		if keywords[i].Frequency == keywords[j].Frequency {
+			return keywords[i].Word < keywords[j].Word
+		}
 		return keywords[i].Frequency > keywords[j].Frequency
 	})
 	
@@ -525,9 +631,7 @@ func (d *DocumentAnalyzerTool) extractKeywords(text string, maxKeywords int) []K
 // tokenizeText tokenizes text into words
 func (d *DocumentAnalyzerTool) tokenizeText(text string) []string {
 	// Convert to lowercase and remove punctuation
-	re := regexp.MustCompile(`[^\p{L}\s]+`)
-	cleaned := re.ReplaceAllString(strings.ToLower(text), " ")
-	return strings.Fields(cleaned)
+	return tokenize(text)
 }
 
 // isStopWord checks if a word is a stop word
@@ -559,16 +663,7 @@ func (d *DocumentAnalyzerTool) extractEntities(text string) []EntityInfo {
 	entityCounts["DATE"] = make(map[string]int)
 	entityCounts["MONEY"] = make(map[string]int)
 	
-	// Simple regex patterns for entity recognition
-	patterns := map[string]*regexp.Regexp{
-		"PERSON":       regexp.MustCompile(`\b[A-Z][a-z]+ [A-Z][a-z]+\b`), // Simple name pattern
-		"LOCATION":     regexp.MustCompile(`\b(?:New York|London|Paris|Tokyo|Beijing|Los Angeles|Chicago|San Francisco)\b`),
-		"ORGANIZATION": regexp.MustCompile(`\b(?:Google|Microsoft|Apple|Amazon|Facebook|IBM|Oracle|Intel)\b`),
-		"DATE":         regexp.MustCompile(`\b\d{1,2}/\d{1,2}/\d{4}\b|\b\d{4}-\d{2}-\d{2}\b`),
-		"MONEY":        regexp.MustCompile(`\$\d+(?:,\d{3})*(?:\.\d{2})?\b`),
-	}
-	
-	for entityType, pattern := range patterns {
+	for entityType, pattern := range documentEntityPatterns {
 		matches := pattern.FindAllString(text, -1)
 		for _, match := range matches {
 			entityCounts[entityType][match]++
@@ -610,15 +705,46 @@ func (d *DocumentAnalyzerTool) getCategoryForEntityType(entityType string) strin
 	return "Other"
 }
 
+// sampleSummary asks the client's LLM to summarize text through
+// sampling/createMessage
+func (d *DocumentAnalyzerTool) sampleSummary(ctx context.Context, text string) (string, error) {
+	if !mcp.ClientSupportsSampling(ctx) {
+		return "", mcp.ErrSamplingUnsupported
+	}
+
+	if runes := []rune(text); len(runes) > maxSampledChars {
+		text = string(runes[:maxSampledChars])
+	}
+
+	result, err := mcp.CreateMessage(ctx, &mcp.CreateMessageParams{
+		Messages: []mcp.SamplingMessage{{
+			Role: "user",
+			Content: mcp.Content{
+				Type: "text",
+				Text: "Summarize the following document in three to five sentences:\n\n" + text,
+			},
+		}},
+		SystemPrompt: "You are a precise assistant that writes faithful, concise document summaries.",
+		MaxTokens:    400,
+	})
+	if err != nil {
+		return "", err
+	}
+	if result.Content.Type != "text" || strings.TrimSpace(result.Content.Text) == "" {
+		return "", fmt.Errorf("client returned no text summary")
+	}
+	return strings.TrimSpace(result.Content.Text), nil
+}
+
 // generateSummary generates a simple extractive summary
-func (d *DocumentAnalyzerTool) generateSummary(text string) string {
+func (d *DocumentAnalyzerTool) generateSummary(text string, idx *tokenIndex) string {
 	sentences := d.splitIntoSentences(text)
 	if len(sentences) <= 2 {
 		return strings.Join(sentences, " ")
 	}
 	
 	// Simple extractive summarization: take first and most keyword-rich sentences
-	keywords := d.extractKeywords(text, 10)
+	keywords := d.extractKeywords(idx, 10)
 	keywordSet := make(map[string]bool)
 	for _, kw := range keywords {
 		keywordSet[kw.Word] = true
@@ -670,8 +796,7 @@ func (d *DocumentAnalyzerTool) generateSummary(text string) string {
 
 // splitIntoSentences splits text into sentences
 func (d *DocumentAnalyzerTool) splitIntoSentences(text string) []string {
-	re := regexp.MustCompile(`[.!?]+\s+`)
-	sentences := re.Split(text, -1)
+	sentences := sentenceSplitRegex.Split(text, -1)
 	var result []string
 	for _, sentence := range sentences {
 		if trimmed := strings.TrimSpace(sentence); trimmed != "" {
@@ -682,32 +807,30 @@ func (d *DocumentAnalyzerTool) splitIntoSentences(text string) []string {
 }
 
 // calculateLexicalDiversity calculates the lexical diversity of the text
-func (d *DocumentAnalyzerTool) calculateLexicalDiversity(text string) float64 {
-	words := d.tokenizeText(text)
-	if len(words) == 0 {
+func (d *DocumentAnalyzerTool) calculateLexicalDiversity(idx *tokenIndex) float64 {
+	if idx.Len() == 0 {
 		return 0
 	}
 	
-	uniqueWords := make(map[string]bool)
-	for _, word := range words {
+	uniqueWords := 0
+	for word := range idx.freq {
 		if len(word) >= 3 && !d.isStopWord(word) {
-			uniqueWords[word] = true
+			uniqueWords++
 		}
 	}
 	
-	return float64(len(uniqueWords)) / float64(len(words))
+	return float64(uniqueWords) / float64(idx.Len())
 }
 
 // calculateBasicComplexity calculates a basic complexity score
-func (d *DocumentAnalyzerTool) calculateBasicComplexity(text string) float64 {
-	words := d.tokenizeText(text)
+func (d *DocumentAnalyzerTool) calculateBasicComplexity(text string, idx *tokenIndex) float64 {
 	sentences := d.splitIntoSentences(text)
 	
 	if len(sentences) == 0 {
 		return 0
 	}
 	
-	avgWordsPerSentence := float64(len(words)) / float64(len(sentences))
+	avgWordsPerSentence := float64(idx.Len()) / float64(len(sentences))
 	
 	// Simple complexity based on sentence length
 	if avgWordsPerSentence <= 10 {
@@ -723,29 +846,20 @@ func (d *DocumentAnalyzerTool) calculateBasicComplexity(text string) float64 {
 }
 
 // calculateComplexityScore calculates a comprehensive complexity score
-func (d *DocumentAnalyzerTool) calculateComplexityScore(text string) float64 {
+func (d *DocumentAnalyzerTool) calculateComplexityScore(text string, idx *tokenIndex) float64 {
 	// This is a simplified complexity calculation
 	// In a real implementation, you might use more sophisticated metrics like Flesch-Kincaid
-	return d.calculateBasicComplexity(text)
+	return d.calculateBasicComplexity(text, idx)
 }
 
 // calculateSentimentScore performs basic sentiment analysis
-func (d *DocumentAnalyzerTool) calculateSentimentScore(text string) float64 {
+func (d *DocumentAnalyzerTool) calculateSentimentScore(idx *tokenIndex) float64 {
 	// Simple sentiment analysis based on positive/negative word counts
 	positiveWords := []string{"good", "great", "excellent", "amazing", "wonderful", "fantastic", "positive", "happy", "love", "best"}
 	negativeWords := []string{"bad", "terrible", "awful", "horrible", "negative", "sad", "hate", "worst", "difficult", "problem"}
 	
-	text = strings.ToLower(text)
-	positiveCount := 0
-	negativeCount := 0
-	
-	for _, word := range positiveWords {
-		positiveCount += strings.Count(text, word)
-	}
-	
-	for _, word := range negativeWords {
-		negativeCount += strings.Count(text, word)
-	}
+	positiveCount := idx.CountAll(positiveWords)
+	negativeCount := idx.CountAll(negativeWords)
 	
 	total := positiveCount + negativeCount
 	if total == 0 {
@@ -757,7 +871,7 @@ func (d *DocumentAnalyzerTool) calculateSentimentScore(text string) float64 {
 }
 
 // analyzeTopicDistribution performs simple topic analysis
-func (d *DocumentAnalyzerTool) analyzeTopicDistribution(text string) map[string]float64 {
+func (d *DocumentAnalyzerTool) analyzeTopicDistribution(idx *tokenIndex) map[string]float64 {
 	topics := map[string][]string{
 		"Technology": {"computer", "software", "technology", "digital", "internet", "data", "system", "application"},
 		"Business":   {"business", "company", "market", "financial", "economy", "revenue", "profit", "customer"},
@@ -766,20 +880,15 @@ func (d *DocumentAnalyzerTool) analyzeTopicDistribution(text string) map[string]
 		"Education":  {"education", "learning", "student", "teacher", "school", "university", "knowledge", "study"},
 	}
 	
-	text = strings.ToLower(text)
 	topicScores := make(map[string]float64)
 	
-	totalWords := len(d.tokenizeText(text))
+	totalWords := idx.Len()
 	if totalWords == 0 {
 		return topicScores
 	}
 	
 	for topic, keywords := range topics {
-		count := 0
-		for _, keyword := range keywords {
-			count += strings.Count(text, keyword)
-		}
-		topicScores[topic] = float64(count) / float64(totalWords)
+		topicScores[topic] = float64(idx.CountAll(keywords)) / float64(totalWords)
 	}
 	
 	return topicScores
@@ -793,7 +902,6 @@ func (d *DocumentAnalyzerTool) analyzeDocumentStructure(text string) DocumentStr
 	}
 	
 	// Check for headers (simple markdown-style detection)
-	headerRegex := regexp.MustCompile(`(?m)^#+\s+`)
 	if headerRegex.MatchString(text) {
 		structure.HasHeaders = true
 		headers := headerRegex.FindAllString(text, -1)
@@ -804,20 +912,17 @@ func (d *DocumentAnalyzerTool) analyzeDocumentStructure(text string) DocumentStr
 	}
 	
 	// Check for lists
-	listRegex := regexp.MustCompile(`(?m)^[\s]*[*\-+]\s+|^[\s]*\d+\.\s+`)
 	if listRegex.MatchString(text) {
 		structure.HasLists = true
 		structure.ListTypes = append(structure.ListTypes, "unordered", "ordered")
 	}
 	
 	// Check for links
-	linkRegex := regexp.MustCompile(`https?://[^\s]+|\[([^\]]+)\]\([^)]+\)`)
 	links := linkRegex.FindAllString(text, -1)
 	structure.LinkCount = len(links)
 	structure.HasLinks = structure.LinkCount > 0
 	
 	// Check for images (simple detection)
-	imageRegex := regexp.MustCompile(`!\[([^\]]*)\]\([^)]+\)|<img[^>]+>`)
 	images := imageRegex.FindAllString(text, -1)
 	structure.ImageCount = len(images)
 	
@@ -835,6 +940,12 @@ func (d *DocumentAnalyzerTool) stripHTML(htmlContent string) string {
 	return d.stripHTMLRegex(htmlContent)
 }
 
+// ExtractHTMLText returns the readable text of an HTML document, as the
+// analyzer extracts it from fetched pages
+func ExtractHTMLText(htmlContent string) string {
+	return (&DocumentAnalyzerTool{}).stripHTML(htmlContent)
+}
+
 // parseHTMLWithParser uses golang.org/x/net/html to properly parse HTML
 func (d *DocumentAnalyzerTool) parseHTMLWithParser(htmlContent string) string {
 	doc, err := html.Parse(strings.NewReader(htmlContent))
@@ -848,7 +959,7 @@ func (d *DocumentAnalyzerTool) parseHTMLWithParser(htmlContent string) string {
 	
 	// Clean up whitespace
 	text := textBuilder.String()
-	text = regexp.MustCompile(`\s+`).ReplaceAllString(text, " ")
+	text = whitespaceRegex.ReplaceAllString(text, " ")
 	return strings.TrimSpace(text)
 }
 
@@ -900,21 +1011,16 @@ func (d *DocumentAnalyzerTool) isBlockElement(tagName string) bool {
 // stripHTMLRegex removes HTML tags using regex (fallback method)
 func (d *DocumentAnalyzerTool) stripHTMLRegex(html string) string {
 	// Remove script and style tags with their content
-	scriptRegex := regexp.MustCompile(`(?i)<script[^>]*>.*?</script>`)
-	styleRegex := regexp.MustCompile(`(?i)<style[^>]*>.*?</style>`)
-	html = scriptRegex.ReplaceAllString(html, "")
-	html = styleRegex.ReplaceAllString(html, "")
+	html = scriptTagRegex.ReplaceAllString(html, "")
+	html = styleTagRegex.ReplaceAllString(html, "")
 	
 	// Remove HTML comments
-	commentRegex := regexp.MustCompile(`<!--.*?-->`)
 	html = commentRegex.ReplaceAllString(html, "")
 	
 	// Remove all HTML tags
-	tagRegex := regexp.MustCompile(`<[^>]*>`)
-	html = tagRegex.ReplaceAllString(html, " ")
+	html = htmlTagRegex.ReplaceAllString(html, " ")
 	
 	// Clean up whitespace
-	whitespaceRegex := regexp.MustCompile(`\s+`)
 	html = whitespaceRegex.ReplaceAllString(html, " ")
 	
 	return strings.TrimSpace(html)
@@ -43,8 +43,8 @@ func TestDocumentAnalyzerTool_Execute_Text(t *testing.T) {
 		t.Errorf("Expected successful analysis, got error: %v", result.Content[0].Text)
 	}
 	
-	if len(result.Content) < 2 {
-		t.Fatal("Expected at least 2 content items (text and JSON)")
+	if _, ok := result.StructuredContent.(*DocumentAnalysis); !ok {
+		t.Fatalf("Expected the analysis as structured content, got %T", result.StructuredContent)
 	}
 }
 
@@ -304,7 +304,7 @@ func TestDocumentAnalyzerTool_ExtractKeywords(t *testing.T) {
 	analyzer := NewDocumentAnalyzerTool()
 	
 	text := "Document analysis is important. Document processing and analysis help understand content. Analysis provides insights."
-	keywords := analyzer.extractKeywords(text, 5)
+	keywords := analyzer.extractKeywords(newTokenIndex(text), 5)
 	
 	if len(keywords) == 0 {
 		t.Error("Expected at least some keywords")
@@ -340,7 +340,7 @@ func TestDocumentAnalyzerTool_CalculateLexicalDiversity(t *testing.T) {
 	}
 	
 	for _, test := range tests {
-		score := analyzer.calculateLexicalDiversity(test.input)
+		score := analyzer.calculateLexicalDiversity(newTokenIndex(test.input))
 		if score < test.minScore || score > test.maxScore {
 			t.Errorf("calculateLexicalDiversity(%q): expected score between %f and %f, got %f", test.input, test.minScore, test.maxScore, score)
 		}
@@ -360,7 +360,7 @@ func TestDocumentAnalyzerTool_CalculateBasicComplexity(t *testing.T) {
 	}
 	
 	for _, test := range tests {
-		score := analyzer.calculateBasicComplexity(test.input)
+		score := analyzer.calculateBasicComplexity(test.input, newTokenIndex(test.input))
 		if score < 0 || score > 1 {
 			t.Errorf("calculateBasicComplexity: %s - score %f should be between 0 and 1", test.description, score)
 		}
@@ -382,7 +382,7 @@ func TestDocumentAnalyzerTool_CalculateSentimentScore(t *testing.T) {
 	}
 	
 	for _, test := range tests {
-		score := analyzer.calculateSentimentScore(test.input)
+		score := analyzer.calculateSentimentScore(newTokenIndex(test.input))
 		if score < test.minScore || score > test.maxScore {
 			t.Errorf("calculateSentimentScore: %s - expected score between %f and %f, got %f", test.description, test.minScore, test.maxScore, score)
 		}
@@ -459,6 +459,48 @@ func TestDocumentAnalyzerTool_AnalyzeDocumentStructure(t *testing.T) {
 	}
 }
 
+func TestDocumentAnalyzerTool_AnalyzeDocumentParallel(t *testing.T) {
+	testText := strings.Repeat("Google announced great research results in London on 2024-01-15. The development team was happy with the software analysis. ", 20)
+
+	sequential := NewDocumentAnalyzerTool()
+	sequential.SetParallelism(1)
+	parallel := NewDocumentAnalyzerTool()
+	parallel.SetParallelism(8)
+
+	ctx := context.Background()
+	seqResult, err := sequential.analyzeDocument(ctx, testText, "test", "text", "comprehensive", true, true, true, 10)
+	if err != nil {
+		t.Fatalf("Sequential analysis failed: %v", err)
+	}
+	parResult, err := parallel.analyzeDocument(ctx, testText, "test", "text", "comprehensive", true, true, true, 10)
+	if err != nil {
+		t.Fatalf("Parallel analysis failed: %v", err)
+	}
+
+	if len(seqResult.Keywords) != len(parResult.Keywords) {
+		t.Errorf("Keyword count mismatch: %d vs %d", len(seqResult.Keywords), len(parResult.Keywords))
+	}
+	if len(seqResult.Entities) != len(parResult.Entities) {
+		t.Errorf("Entity count mismatch: %d vs %d", len(seqResult.Entities), len(parResult.Entities))
+	}
+	if (seqResult.Summary == "") != (parResult.Summary == "") {
+		t.Errorf("Summary presence mismatch: %q vs %q", seqResult.Summary, parResult.Summary)
+	}
+	if seqResult.Statistics.SentimentScore != parResult.Statistics.SentimentScore {
+		t.Errorf("Sentiment mismatch: %f vs %f", seqResult.Statistics.SentimentScore, parResult.Statistics.SentimentScore)
+	}
+}
+
+func TestDocumentAnalyzerTool_AnalyzeDocumentCancelled(t *testing.T) {
+	analyzer := NewDocumentAnalyzerTool()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := analyzer.analyzeDocument(ctx, "Some text to analyze.", "test", "text", "comprehensive", true, true, true, 10); err == nil {
+		t.Error("Expected error for cancelled context")
+	}
+}
+
 // Benchmark tests
 func BenchmarkDocumentAnalyzerTool_Execute(b *testing.B) {
 	analyzer := NewDocumentAnalyzerTool()
@@ -487,6 +529,6 @@ func BenchmarkDocumentAnalyzerTool_ExtractKeywords(b *testing.B) {
 	
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		analyzer.extractKeywords(testText, 20)
+		analyzer.extractKeywords(newTokenIndex(testText), 20)
 	}
-}
\ No newline at end of file
+}
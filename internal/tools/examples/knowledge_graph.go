@@ -2,7 +2,6 @@ package examples
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"regexp"
 	"sort"
@@ -11,6 +10,26 @@ import (
 	"github.com/chongliujia/mcp-go-template/pkg/mcp"
 )
 
+// Precompiled regular expressions used for entity extraction
+var (
+	personNameRegex = regexp.MustCompile(`\b[A-Z][a-z]+\s+[A-Z][a-z]+\b`)
+
+	organizationRegexes = []*regexp.Regexp{
+		regexp.MustCompile(`\b[A-Z][a-zA-Z\s&]+(?:Inc|Corp|LLC|Ltd|Company|Corporation|Organization|Institute|University|College|School)\b`),
+		regexp.MustCompile(`\b(?:Apple|Google|Microsoft|Amazon|Meta|Tesla|Netflix|IBM|Oracle|Adobe|Intel|AMD|Nvidia|OpenAI|Anthropic)\b`),
+		regexp.MustCompile(`\b[A-Z][A-Z]+\b`), // Acronyms
+	}
+
+	dateRegexes = []*regexp.Regexp{
+		regexp.MustCompile(`\b\d{4}-\d{2}-\d{2}\b`),     // YYYY-MM-DD
+		regexp.MustCompile(`\b\d{1,2}/\d{1,2}/\d{4}\b`), // MM/DD/YYYY
+		regexp.MustCompile(`\b\d{1,2}/\d{1,2}/\d{2}\b`), // MM/DD/YY
+		regexp.MustCompile(`\b(?:Jan|Feb|Mar|Apr|May|Jun|Jul|Aug|Sep|Oct|Nov|Dec)[a-z]*\s+\d{1,2},?\s+\d{4}\b`), // Month DD, YYYY
+	}
+
+	numberRegex = regexp.MustCompile(`\b\d+(?:\.\d+)?(?:[KMB]|million|billion|thousand)?\b`)
+)
+
 // KnowledgeGraphTool builds and analyzes knowledge graphs from text
 type KnowledgeGraphTool struct{}
 
@@ -64,6 +83,8 @@ func (k *KnowledgeGraphTool) Definition() *mcp.Tool {
 	return &mcp.Tool{
 		Name:        "knowledge_graph",
 		Description: "Build and analyze knowledge graphs from text - extract entities, relationships, and semantic connections for deep research analysis.",
+		Category:    mcp.ToolCategoryResearch,
+		Tags:        []string{"nlp", "graph"},
 		InputSchema: mcp.ToolSchema{
 			Type: "object",
 			Properties: map[string]interface{}{
@@ -200,28 +221,15 @@ func (k *KnowledgeGraphTool) buildKnowledgeGraph(text string, entityTypes []stri
 	// Format response
 	responseText := k.formatKnowledgeGraph(graph)
 	
-	// JSON format
-	jsonGraph, _ := json.MarshalIndent(graph, "", "  ")
-
-	return &mcp.CallToolResult{
-		Content: []mcp.Content{
-			{
-				Type: "text",
-				Text: responseText,
-			},
-			{
-				Type:     "text",
-				Text:     string(jsonGraph),
-				MimeType: "application/json",
-			},
-		},
-		IsError: false,
-	}, nil
+	return mcp.NewStructuredResult(responseText, graph), nil
 }
 
 // extractEntities extracts entities from text based on specified types
 func (k *KnowledgeGraphTool) extractEntities(text string, entityTypes []string, maxEntities int) []Entity {
 	entities := make(map[string]*Entity)
+
+	// Tokenize once for the vocabulary-based extractors
+	idx := newTokenIndex(text)
 	
 	// Extract different types of entities
 	for _, entityType := range entityTypes {
@@ -231,9 +239,9 @@ func (k *KnowledgeGraphTool) extractEntities(text string, entityTypes []string,
 		case "organization":
 			k.extractOrganizations(text, entities)
 		case "location":
-			k.extractLocations(text, entities)
+			k.extractLocations(idx, entities)
 		case "concept":
-			k.extractConcepts(text, entities)
+			k.extractConcepts(idx, entities)
 		case "date":
 			k.extractDates(text, entities)
 		case "number":
@@ -262,8 +270,7 @@ func (k *KnowledgeGraphTool) extractEntities(text string, entityTypes []string,
 // extractPersons extracts person entities
 func (k *KnowledgeGraphTool) extractPersons(text string, entities map[string]*Entity) {
 	// Pattern for names (simplified)
-	pattern := regexp.MustCompile(`\b[A-Z][a-z]+\s+[A-Z][a-z]+\b`)
-	matches := pattern.FindAllString(text, -1)
+	matches := personNameRegex.FindAllString(text, -1)
 
 	for _, match := range matches {
 		if k.isLikelyPersonName(match) {
@@ -286,14 +293,7 @@ func (k *KnowledgeGraphTool) extractPersons(text string, entities map[string]*En
 // extractOrganizations extracts organization entities
 func (k *KnowledgeGraphTool) extractOrganizations(text string, entities map[string]*Entity) {
 	// Common organization suffixes and names
-	patterns := []string{
-		`\b[A-Z][a-zA-Z\s&]+(?:Inc|Corp|LLC|Ltd|Company|Corporation|Organization|Institute|University|College|School)\b`,
-		`\b(?:Apple|Google|Microsoft|Amazon|Meta|Tesla|Netflix|IBM|Oracle|Adobe|Intel|AMD|Nvidia|OpenAI|Anthropic)\b`,
-		`\b[A-Z][A-Z]+\b`, // Acronyms
-	}
-
-	for _, patternStr := range patterns {
-		pattern := regexp.MustCompile(patternStr)
+	for _, pattern := range organizationRegexes {
 		matches := pattern.FindAllString(text, -1)
 
 		for _, match := range matches {
@@ -316,7 +316,7 @@ func (k *KnowledgeGraphTool) extractOrganizations(text string, entities map[stri
 }
 
 // extractLocations extracts location entities
-func (k *KnowledgeGraphTool) extractLocations(text string, entities map[string]*Entity) {
+func (k *KnowledgeGraphTool) extractLocations(idx *tokenIndex, entities map[string]*Entity) {
 	// Common location patterns
 	locations := []string{
 		"New York", "Los Angeles", "Chicago", "Houston", "Phoenix", "Philadelphia", "San Antonio", "San Diego", "Dallas", "San Jose",
@@ -325,9 +325,8 @@ func (k *KnowledgeGraphTool) extractLocations(text string, entities map[string]*
 		"United States", "China", "India", "Japan", "Germany", "United Kingdom", "France", "Brazil", "Italy", "Canada",
 	}
 
-	lowerText := strings.ToLower(text)
 	for _, location := range locations {
-		count := strings.Count(lowerText, strings.ToLower(location))
+		count := idx.Count(location)
 		if count > 0 {
 			id := strings.ToLower(strings.ReplaceAll(location, " ", "_"))
 			entities[id] = &Entity{
@@ -342,7 +341,7 @@ func (k *KnowledgeGraphTool) extractLocations(text string, entities map[string]*
 }
 
 // extractConcepts extracts conceptual entities
-func (k *KnowledgeGraphTool) extractConcepts(text string, entities map[string]*Entity) {
+func (k *KnowledgeGraphTool) extractConcepts(idx *tokenIndex, entities map[string]*Entity) {
 	// Common technical and conceptual terms
 	concepts := []string{
 		"artificial intelligence", "machine learning", "deep learning", "neural network", "algorithm",
@@ -352,9 +351,8 @@ func (k *KnowledgeGraphTool) extractConcepts(text string, entities map[string]*E
 		"sustainability", "climate change", "renewable energy", "environment", "economics", "finance",
 	}
 
-	lowerText := strings.ToLower(text)
 	for _, concept := range concepts {
-		count := strings.Count(lowerText, concept)
+		count := idx.Count(concept)
 		if count > 0 {
 			id := strings.ToLower(strings.ReplaceAll(concept, " ", "_"))
 			entities[id] = &Entity{
@@ -370,15 +368,7 @@ func (k *KnowledgeGraphTool) extractConcepts(text string, entities map[string]*E
 
 // extractDates extracts date entities
 func (k *KnowledgeGraphTool) extractDates(text string, entities map[string]*Entity) {
-	patterns := []string{
-		`\b\d{4}-\d{2}-\d{2}\b`,                    // YYYY-MM-DD
-		`\b\d{1,2}/\d{1,2}/\d{4}\b`,               // MM/DD/YYYY
-		`\b\d{1,2}/\d{1,2}/\d{2}\b`,               // MM/DD/YY
-		`\b(?:Jan|Feb|Mar|Apr|May|Jun|Jul|Aug|Sep|Oct|Nov|Dec)[a-z]*\s+\d{1,2},?\s+\d{4}\b`, // Month DD, YYYY
-	}
-
-	for _, patternStr := range patterns {
-		pattern := regexp.MustCompile(patternStr)
+	for _, pattern := range dateRegexes {
 		matches := pattern.FindAllString(text, -1)
 
 		for _, match := range matches {
@@ -400,8 +390,7 @@ func (k *KnowledgeGraphTool) extractDates(text string, entities map[string]*Enti
 
 // extractNumbers extracts numeric entities
 func (k *KnowledgeGraphTool) extractNumbers(text string, entities map[string]*Entity) {
-	pattern := regexp.MustCompile(`\b\d+(?:\.\d+)?(?:[KMB]|million|billion|thousand)?\b`)
-	matches := pattern.FindAllString(text, -1)
+	matches := numberRegex.FindAllString(text, -1)
 
 	for _, match := range matches {
 		if len(match) > 2 { // Skip small numbers
@@ -597,8 +586,7 @@ func (k *KnowledgeGraphTool) isCommonWord(word string) bool {
 }
 
 func (k *KnowledgeGraphTool) splitIntoSentences(text string) []string {
-	pattern := regexp.MustCompile(`[.!?]+\s+`)
-	sentences := pattern.Split(text, -1)
+	sentences := sentenceSplitRegex.Split(text, -1)
 	
 	var result []string
 	for _, sentence := range sentences {
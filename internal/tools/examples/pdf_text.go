@@ -0,0 +1,285 @@
+package examples
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// maxPDFStreamBytes bounds each decompressed PDF content stream
+const maxPDFStreamBytes = 16 << 20
+
+// ExtractPDFText returns the text drawn by the content streams of a PDF.
+// It reads uncompressed and Flate streams and the string operands of the
+// text operators, so it suits PDFs produced from text documents; scanned
+// pages and fonts with custom encodings yield little or nothing.
+func ExtractPDFText(data []byte) (string, error) {
+	if !bytes.HasPrefix(bytes.TrimLeft(data, " \t\r\n"), []byte("%PDF")) {
+		return "", fmt.Errorf("not a PDF document")
+	}
+
+	var text strings.Builder
+	for rest := data; ; {
+		start := bytes.Index(rest, []byte("stream"))
+		if start < 0 {
+			break
+		}
+		dict := rest[:start]
+		if obj := bytes.LastIndex(dict, []byte(" obj")); obj >= 0 {
+			dict = dict[obj:]
+		}
+		body := rest[start+len("stream"):]
+		body = bytes.TrimPrefix(body, []byte("\r"))
+		body = bytes.TrimPrefix(body, []byte("\n"))
+		end := bytes.Index(body, []byte("endstream"))
+		if end < 0 {
+			break
+		}
+		rest = body[end+len("endstream"):]
+
+		content, ok := pdfStreamContent(dict, body[:end])
+		if ok {
+			pdfContentText(content, &text)
+		}
+	}
+
+	extracted := strings.TrimSpace(text.String())
+	if extracted == "" {
+		return "", fmt.Errorf("no text found in the PDF")
+	}
+	return extracted, nil
+}
+
+// pdfStreamContent decodes a stream that may be a page's content stream.
+// Streams with a Type or Subtype, such as images, fonts and object
+// streams, and streams in other encodings are skipped.
+func pdfStreamContent(dict, body []byte) ([]byte, bool) {
+	if bytes.Contains(dict, []byte("/Type")) || bytes.Contains(dict, []byte("/Subtype")) || bytes.Contains(dict, []byte("/Length1")) {
+		return nil, false
+	}
+	if !bytes.Contains(dict, []byte("/Filter")) {
+		return body, true
+	}
+	if !bytes.Contains(dict, []byte("/FlateDecode")) || pdfHasOtherFilter(dict) {
+		return nil, false
+	}
+
+	reader, err := zlib.NewReader(bytes.NewReader(body))
+	if err != nil {
+		return nil, false
+	}
+	defer reader.Close()
+	content, err := io.ReadAll(io.LimitReader(reader, maxPDFStreamBytes))
+	if err != nil && len(content) == 0 {
+		return nil, false
+	}
+	return content, true
+}
+
+// pdfHasOtherFilter reports whether a stream applies a filter besides Flate
+func pdfHasOtherFilter(dict []byte) bool {
+	for _, filter := range []string{"/DCTDecode", "/JPXDecode", "/LZWDecode", "/ASCII85Decode", "/ASCIIHexDecode", "/RunLengthDecode", "/CCITTFaxDecode", "/JBIG2Decode"} {
+		if bytes.Contains(dict, []byte(filter)) {
+			return true
+		}
+	}
+	return false
+}
+
+// pdfContentText writes the strings shown by the text operators of a
+// content stream, starting a new line where the text moves to one
+func pdfContentText(content []byte, text *strings.Builder) {
+	var operands []interface{} // strings and numbers since the last operator
+	inText, onLine := false, false
+	newLine := func() {
+		if onLine {
+			text.WriteString("\n")
+			onLine = false
+		}
+	}
+	show := func(s string) {
+		text.WriteString(s)
+		onLine = onLine || s != ""
+	}
+
+	for i := 0; i < len(content); {
+		c := content[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n' || c == '\f' || c == 0:
+			i++
+		case c == '%':
+			for i < len(content) && content[i] != '\n' && content[i] != '\r' {
+				i++
+			}
+		case c == '(':
+			s, n := pdfLiteralString(content[i:])
+			operands = append(operands, s)
+			i += n
+		case c == '<' && i+1 < len(content) && content[i+1] == '<', c == '>' && i+1 < len(content) && content[i+1] == '>':
+			i += 2
+		case c == '<':
+			end := bytes.IndexByte(content[i:], '>')
+			if end < 0 {
+				return
+			}
+			operands = append(operands, pdfHexString(content[i+1:i+end]))
+			i += end + 1
+		case c == '[' || c == ']' || c == '{' || c == '}' || c == '>' || c == ')':
+			i++
+		default:
+			start := i
+			if c == '/' {
+				i++
+			}
+			for i < len(content) && !bytes.ContainsRune([]byte(" \t\r\n\f\x00()<>[]{}/%"), rune(content[i])) {
+				i++
+			}
+			token := string(content[start:i])
+			if c == '/' {
+				continue
+			}
+			if number, err := strconv.ParseFloat(token, 64); err == nil {
+				operands = append(operands, number)
+				continue
+			}
+
+			switch token {
+			case "BT":
+				inText = true
+			case "ET":
+				inText = false
+				newLine()
+			case "Td", "TD", "T*", "Tm":
+				if inText {
+					newLine()
+				}
+			case "Tj":
+				if inText && len(operands) > 0 {
+					if s, ok := operands[len(operands)-1].(string); ok {
+						show(s)
+					}
+				}
+			case "'", "\"":
+				if inText && len(operands) > 0 {
+					newLine()
+					if s, ok := operands[len(operands)-1].(string); ok {
+						show(s)
+					}
+				}
+			case "TJ":
+				if inText {
+					for _, operand := range operands {
+						switch value := operand.(type) {
+						case string:
+							show(value)
+						case float64:
+							// Large negative adjustments separate words
+							if value < -200 && onLine {
+								text.WriteString(" ")
+							}
+						}
+					}
+				}
+			}
+			operands = operands[:0]
+			if i == start {
+				i++
+			}
+		}
+	}
+}
+
+// pdfLiteralString decodes the (...) string at the start of data,
+// returning it and the number of bytes it took
+func pdfLiteralString(data []byte) (string, int) {
+	var s []byte
+	depth := 0
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		switch {
+		case c == '(':
+			if depth > 0 {
+				s = append(s, c)
+			}
+			depth++
+		case c == ')':
+			depth--
+			if depth == 0 {
+				return pdfDecodeText(s), i + 1
+			}
+			s = append(s, c)
+		case c == '\\' && i+1 < len(data):
+			i++
+			switch e := data[i]; e {
+			case 'n':
+				s = append(s, '\n')
+			case 'r':
+				s = append(s, '\r')
+			case 't':
+				s = append(s, '\t')
+			case 'b':
+				s = append(s, '\b')
+			case 'f':
+				s = append(s, '\f')
+			case '\r', '\n':
+				// A backslash at the end of a line continues the string
+				if e == '\r' && i+1 < len(data) && data[i+1] == '\n' {
+					i++
+				}
+			default:
+				if e >= '0' && e <= '7' {
+					value, n := 0, 0
+					for n < 3 && i+n < len(data) && data[i+n] >= '0' && data[i+n] <= '7' {
+						value = value*8 + int(data[i+n]-'0')
+						n++
+					}
+					s = append(s, byte(value))
+					i += n - 1
+				} else {
+					s = append(s, e)
+				}
+			}
+		default:
+			s = append(s, c)
+		}
+	}
+	return pdfDecodeText(s), len(data)
+}
+
+// pdfHexString decodes the digits of a <...> string
+func pdfHexString(digits []byte) string {
+	digits = bytes.Map(func(r rune) rune {
+		if strings.ContainsRune("0123456789abcdefABCDEF", r) {
+			return r
+		}
+		return -1
+	}, digits)
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+	decoded := make([]byte, hex.DecodedLen(len(digits)))
+	hex.Decode(decoded, digits)
+	return pdfDecodeText(decoded)
+}
+
+// pdfDecodeText converts a PDF string to UTF-8, reading UTF-16 when it
+// starts with a byte order mark and Latin-1 otherwise
+func pdfDecodeText(s []byte) string {
+	if len(s) >= 2 && s[0] == 0xfe && s[1] == 0xff {
+		units := make([]uint16, 0, len(s)/2)
+		for i := 2; i+1 < len(s); i += 2 {
+			units = append(units, uint16(s[i])<<8|uint16(s[i+1]))
+		}
+		return string(utf16.Decode(units))
+	}
+	runes := make([]rune, len(s))
+	for i, b := range s {
+		runes[i] = rune(b)
+	}
+	return string(runes)
+}
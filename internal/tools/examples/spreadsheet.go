@@ -0,0 +1,707 @@
+package examples
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/chongliujia/mcp-go-template/pkg/mcp"
+)
+
+// defaultSandboxDir is the directory spreadsheets are read from and written to
+const defaultSandboxDir = "sandbox"
+
+// maxColumns is the number of columns a worksheet may have, A through XFD
+const maxColumns = 16384
+
+// maxCells bounds the cells a read returns, rows times columns, so a sheet
+// with a few far-apart cells cannot expand into an enormous table
+const maxCells = 1 << 20
+
+// errColumnRange reports a cell reference beyond column XFD
+var errColumnRange = errors.New("column is beyond XFD")
+
+// SpreadsheetTool reads and writes XLSX workbooks inside a sandbox directory
+type SpreadsheetTool struct {
+	definition *mcp.Tool
+	sandboxDir string
+}
+
+// SheetData represents a single worksheet converted to structured JSON
+type SheetData struct {
+	Sheet    string                   `json:"sheet"`
+	Columns  []ColumnInfo             `json:"columns"`
+	Rows     []map[string]interface{} `json:"rows"`
+	RowCount int                      `json:"row_count"`
+}
+
+// ColumnInfo describes a column and its inferred type
+type ColumnInfo struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// NewSpreadsheetTool creates a new spreadsheet tool
+func NewSpreadsheetTool() *SpreadsheetTool {
+	return &SpreadsheetTool{
+		definition: &mcp.Tool{
+			Name:        "xlsx",
+			Description: "Reads XLSX spreadsheets into structured JSON with per-column type inference, and writes result tables to new workbooks in the sandbox directory",
+			Category:    mcp.ToolCategoryFilesystem,
+			Tags:        []string{"xlsx", "data"},
+			InputSchema: mcp.ToolSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"operation": map[string]interface{}{
+						"type":        "string",
+						"description": "Operation to perform",
+						"enum":        []string{"read", "write", "list_sheets"},
+					},
+					"path": map[string]interface{}{
+						"type":        "string",
+						"description": "Workbook path relative to the sandbox directory",
+					},
+					"sheet": map[string]interface{}{
+						"type":        "string",
+						"description": "Sheet name to read or create (default: first sheet / Sheet1)",
+					},
+					"header": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Whether the first row contains column names (read only)",
+						"default":     true,
+					},
+					"columns": map[string]interface{}{
+						"type":        "array",
+						"items":       map[string]interface{}{"type": "string"},
+						"description": "Column names for the header row (write only)",
+					},
+					"rows": map[string]interface{}{
+						"type":        "array",
+						"items":       map[string]interface{}{"type": "array"},
+						"description": "Table rows to write, each an array of cell values (write only)",
+					},
+				},
+				Required: []string{"operation", "path"},
+			},
+		},
+		sandboxDir: defaultSandboxDir,
+	}
+}
+
+// Definition returns the tool definition
+func (s *SpreadsheetTool) Definition() *mcp.Tool {
+	return s.definition
+}
+
+// Execute performs the requested spreadsheet operation
+func (s *SpreadsheetTool) Execute(ctx context.Context, params map[string]interface{}) (*mcp.CallToolResult, error) {
+	operation, ok := params["operation"].(string)
+	if !ok {
+		return spreadsheetError("Error: operation is required and must be a string"), nil
+	}
+
+	relPath, ok := params["path"].(string)
+	if !ok || relPath == "" {
+		return spreadsheetError("Error: path is required and must be a non-empty string"), nil
+	}
+
+	fullPath, err := s.resolvePath(relPath)
+	if err != nil {
+		return spreadsheetError(fmt.Sprintf("Error: %v", err)), nil
+	}
+
+	sheet, _ := params["sheet"].(string)
+
+	switch operation {
+	case "list_sheets":
+		sheets, err := listWorkbookSheets(fullPath)
+		if err != nil {
+			return spreadsheetError(fmt.Sprintf("Error reading workbook: %v", err)), nil
+		}
+		return spreadsheetJSONResult(fmt.Sprintf("Workbook %s contains %d sheet(s): %s", relPath, len(sheets), strings.Join(sheets, ", ")), map[string]interface{}{"sheets": sheets}), nil
+
+	case "read":
+		header := true
+		if val, exists := params["header"]; exists {
+			if h, ok := val.(bool); ok {
+				header = h
+			}
+		}
+
+		data, err := readWorkbookSheet(fullPath, sheet, header)
+		if err != nil {
+			return spreadsheetError(fmt.Sprintf("Error reading workbook: %v", err)), nil
+		}
+		return spreadsheetJSONResult(fmt.Sprintf("Read %d row(s) and %d column(s) from sheet '%s' of %s", data.RowCount, len(data.Columns), data.Sheet, relPath), data), nil
+
+	case "write":
+		if sheet == "" {
+			sheet = "Sheet1"
+		}
+
+		var columns []string
+		if cols, ok := params["columns"].([]interface{}); ok {
+			for _, c := range cols {
+				columns = append(columns, fmt.Sprintf("%v", c))
+			}
+		}
+
+		var rows [][]interface{}
+		if rawRows, ok := params["rows"].([]interface{}); ok {
+			for i, r := range rawRows {
+				row, ok := r.([]interface{})
+				if !ok {
+					return spreadsheetError(fmt.Sprintf("Error: row %d must be an array", i)), nil
+				}
+				rows = append(rows, row)
+			}
+		}
+
+		if len(columns) == 0 && len(rows) == 0 {
+			return spreadsheetError("Error: write requires columns and/or rows"), nil
+		}
+
+		if err := writeWorkbook(fullPath, sheet, columns, rows); err != nil {
+			return spreadsheetError(fmt.Sprintf("Error writing workbook: %v", err)), nil
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{{
+				Type: "text",
+				Text: fmt.Sprintf("Wrote %d row(s) to sheet '%s' in %s", len(rows), sheet, relPath),
+			}},
+			IsError: false,
+		}, nil
+
+	default:
+		return spreadsheetError(fmt.Sprintf("Error: unsupported operation '%s' (supported: read, write, list_sheets)", operation)), nil
+	}
+}
+
+// resolvePath maps a user supplied path into the sandbox directory
+func (s *SpreadsheetTool) resolvePath(relPath string) (string, error) {
+	if filepath.IsAbs(relPath) {
+		return "", fmt.Errorf("path must be relative to the sandbox directory")
+	}
+	if !strings.EqualFold(filepath.Ext(relPath), ".xlsx") {
+		return "", fmt.Errorf("path must have an .xlsx extension")
+	}
+
+	cleaned := filepath.Clean(relPath)
+	if cleaned == ".." || strings.HasPrefix(cleaned, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("path escapes the sandbox directory")
+	}
+
+	return filepath.Join(s.sandboxDir, cleaned), nil
+}
+
+// spreadsheetError builds an error result
+func spreadsheetError(message string) *mcp.CallToolResult {
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{{
+			Type: "text",
+			Text: message,
+		}},
+		IsError: true,
+	}
+}
+
+// spreadsheetJSONResult builds a result with a summary line and the payload
+// as structured content
+func spreadsheetJSONResult(summary string, payload interface{}) *mcp.CallToolResult {
+	return mcp.NewStructuredResult(summary, payload)
+}
+
+// XML structures used for reading workbooks
+
+type xlsxWorkbook struct {
+	Sheets []struct {
+		Name string `xml:"name,attr"`
+		RID  string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type xlsxRelationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+type xlsxSharedStrings struct {
+	Items []xlsxStringItem `xml:"si"`
+}
+
+type xlsxStringItem struct {
+	Text string `xml:"t"`
+	Runs []struct {
+		Text string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (si xlsxStringItem) String() string {
+	if len(si.Runs) == 0 {
+		return si.Text
+	}
+	var b strings.Builder
+	for _, r := range si.Runs {
+		b.WriteString(r.Text)
+	}
+	return b.String()
+}
+
+type xlsxWorksheet struct {
+	Rows []struct {
+		Cells []struct {
+			Ref       string          `xml:"r,attr"`
+			Type      string          `xml:"t,attr"`
+			Value     string          `xml:"v"`
+			InlineStr *xlsxStringItem `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// openWorkbookFiles indexes the files of an XLSX archive by name
+func openWorkbookFiles(r *zip.ReadCloser) map[string]*zip.File {
+	files := make(map[string]*zip.File, len(r.File))
+	for _, f := range r.File {
+		files[f.Name] = f
+	}
+	return files
+}
+
+// decodeZipXML decodes an XML file from an XLSX archive
+func decodeZipXML(files map[string]*zip.File, name string, v interface{}) error {
+	f, exists := files[name]
+	if !exists {
+		return fmt.Errorf("missing %s", name)
+	}
+
+	rc, err := f.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", name, err)
+	}
+	defer rc.Close()
+
+	// Guard against decompression bombs (limit each part to 50MB)
+	if err := xml.NewDecoder(io.LimitReader(rc, 50*1024*1024)).Decode(v); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", name, err)
+	}
+	return nil
+}
+
+// sheetPaths returns the sheet names in workbook order with their archive paths
+func sheetPaths(files map[string]*zip.File) ([]string, map[string]string, error) {
+	var wb xlsxWorkbook
+	if err := decodeZipXML(files, "xl/workbook.xml", &wb); err != nil {
+		return nil, nil, err
+	}
+
+	var rels xlsxRelationships
+	if err := decodeZipXML(files, "xl/_rels/workbook.xml.rels", &rels); err != nil {
+		return nil, nil, err
+	}
+
+	targets := make(map[string]string, len(rels.Relationships))
+	for _, rel := range rels.Relationships {
+		target := rel.Target
+		if strings.HasPrefix(target, "/") {
+			target = strings.TrimPrefix(target, "/")
+		} else {
+			target = path.Join("xl", target)
+		}
+		targets[rel.ID] = target
+	}
+
+	names := make([]string, 0, len(wb.Sheets))
+	paths := make(map[string]string, len(wb.Sheets))
+	for _, sheet := range wb.Sheets {
+		names = append(names, sheet.Name)
+		paths[sheet.Name] = targets[sheet.RID]
+	}
+	return names, paths, nil
+}
+
+// listWorkbookSheets returns the sheet names of a workbook
+func listWorkbookSheets(fullPath string) ([]string, error) {
+	r, err := zip.OpenReader(fullPath)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	names, _, err := sheetPaths(openWorkbookFiles(r))
+	return names, err
+}
+
+// readWorkbookSheet reads a sheet into structured rows with inferred column types
+func readWorkbookSheet(fullPath, sheet string, header bool) (*SheetData, error) {
+	r, err := zip.OpenReader(fullPath)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	files := openWorkbookFiles(r)
+	names, paths, err := sheetPaths(files)
+	if err != nil {
+		return nil, err
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("workbook has no sheets")
+	}
+	if sheet == "" {
+		sheet = names[0]
+	}
+	sheetPath, exists := paths[sheet]
+	if !exists || sheetPath == "" {
+		return nil, fmt.Errorf("sheet '%s' not found (available: %s)", sheet, strings.Join(names, ", "))
+	}
+
+	var shared xlsxSharedStrings
+	if _, exists := files["xl/sharedStrings.xml"]; exists {
+		if err := decodeZipXML(files, "xl/sharedStrings.xml", &shared); err != nil {
+			return nil, err
+		}
+	}
+
+	var ws xlsxWorksheet
+	if err := decodeZipXML(files, sheetPath, &ws); err != nil {
+		return nil, err
+	}
+
+	// Collect the cells of each row sparsely, by column
+	grid := make([]map[int]interface{}, 0, len(ws.Rows))
+	width, cells := 0, 0
+	for _, row := range ws.Rows {
+		values := make(map[int]interface{}, len(row.Cells))
+		for i, cell := range row.Cells {
+			col := i
+			if cell.Ref != "" {
+				idx, err := columnIndex(cell.Ref)
+				if errors.Is(err, errColumnRange) {
+					return nil, err
+				}
+				if err == nil {
+					col = idx
+				}
+			}
+			if col >= maxColumns {
+				return nil, fmt.Errorf("row has more than %d cells", maxColumns)
+			}
+			if cells++; cells > maxCells {
+				return nil, fmt.Errorf("sheet has more than %d cells", maxCells)
+			}
+			values[col] = cellValue(cell.Type, cell.Value, cell.InlineStr, shared)
+			if col+1 > width {
+				width = col + 1
+			}
+		}
+		grid = append(grid, values)
+	}
+
+	dataStart := 0
+	if header && len(grid) > 0 {
+		dataStart = 1
+	}
+	if rows := len(grid) - dataStart; rows > 0 && rows*width > maxCells {
+		return nil, fmt.Errorf("sheet spans %d rows of %d columns, more than %d cells", rows, width, maxCells)
+	}
+
+	columns := make([]ColumnInfo, width)
+	taken := make(map[string]bool, width)
+	for i := range columns {
+		name := columnName(i)
+		if dataStart == 1 {
+			if v := grid[0][i]; v != nil && fmt.Sprintf("%v", v) != "" {
+				name = fmt.Sprintf("%v", v)
+			}
+		}
+		columns[i].Name = uniqueColumnName(name, taken)
+	}
+
+	data := &SheetData{
+		Sheet:   sheet,
+		Columns: columns,
+		Rows:    make([]map[string]interface{}, 0, len(grid)),
+	}
+
+	for _, values := range grid[dataStart:] {
+		record := make(map[string]interface{}, width)
+		for i := 0; i < width; i++ {
+			v := values[i]
+			record[columns[i].Name] = v
+			columns[i].Type = mergeColumnType(columns[i].Type, valueType(v))
+		}
+		data.Rows = append(data.Rows, record)
+	}
+
+	for i := range columns {
+		if columns[i].Type == "" {
+			columns[i].Type = "empty"
+		}
+	}
+	data.RowCount = len(data.Rows)
+
+	return data, nil
+}
+
+// uniqueColumnName returns name, or when another column already has it
+// name_2, name_3 and so on, and records the result in taken
+func uniqueColumnName(name string, taken map[string]bool) string {
+	unique := name
+	for n := 2; taken[unique]; n++ {
+		unique = fmt.Sprintf("%s_%d", name, n)
+	}
+	taken[unique] = true
+	return unique
+}
+
+// cellValue converts a raw cell into a typed Go value
+func cellValue(cellType, raw string, inline *xlsxStringItem, shared xlsxSharedStrings) interface{} {
+	switch cellType {
+	case "s":
+		idx, err := strconv.Atoi(raw)
+		if err != nil || idx < 0 || idx >= len(shared.Items) {
+			return raw
+		}
+		return inferStringValue(shared.Items[idx].String())
+	case "inlineStr":
+		if inline == nil {
+			return ""
+		}
+		return inferStringValue(inline.String())
+	case "str", "e":
+		return raw
+	case "b":
+		return raw == "1"
+	default:
+		if raw == "" {
+			return nil
+		}
+		return parseCellNumber(raw)
+	}
+}
+
+// inferStringValue converts numeric and boolean looking strings into typed values
+func inferStringValue(s string) interface{} {
+	trimmed := strings.TrimSpace(s)
+	switch strings.ToLower(trimmed) {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+	if trimmed != "" {
+		if _, err := strconv.ParseFloat(trimmed, 64); err == nil {
+			return parseCellNumber(trimmed)
+		}
+	}
+	return s
+}
+
+// parseCellNumber parses a numeric cell, preferring integers where exact
+func parseCellNumber(raw string) interface{} {
+	if i, err := strconv.ParseInt(raw, 10, 64); err == nil {
+		return i
+	}
+	if f, err := strconv.ParseFloat(raw, 64); err == nil {
+		if f == float64(int64(f)) && !strings.ContainsAny(raw, "eE") {
+			return int64(f)
+		}
+		return f
+	}
+	return raw
+}
+
+// valueType returns the inferred type name of a cell value
+func valueType(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return ""
+	case bool:
+		return "boolean"
+	case int64:
+		return "integer"
+	case float64:
+		return "number"
+	default:
+		return "string"
+	}
+}
+
+// mergeColumnType widens a column type to accommodate a new value type
+func mergeColumnType(current, next string) string {
+	switch {
+	case next == "" || current == next:
+		return current
+	case current == "":
+		return next
+	case (current == "integer" && next == "number") || (current == "number" && next == "integer"):
+		return "number"
+	default:
+		return "string"
+	}
+}
+
+// columnIndex converts a cell reference such as "C7" into a zero-based column
+// index, rejecting columns beyond XFD
+func columnIndex(ref string) (int, error) {
+	idx := 0
+	n := 0
+	for _, r := range ref {
+		if r >= 'A' && r <= 'Z' {
+			idx = idx*26 + int(r-'A'+1)
+			n++
+		} else if r >= 'a' && r <= 'z' {
+			idx = idx*26 + int(r-'a'+1)
+			n++
+		} else {
+			break
+		}
+		if idx > maxColumns {
+			return 0, fmt.Errorf("invalid cell reference %s: %w", ref, errColumnRange)
+		}
+	}
+	if n == 0 {
+		return 0, fmt.Errorf("invalid cell reference: %s", ref)
+	}
+	return idx - 1, nil
+}
+
+// columnName converts a zero-based column index into spreadsheet letters
+func columnName(idx int) string {
+	name := ""
+	for idx >= 0 {
+		name = string(rune('A'+idx%26)) + name
+		idx = idx/26 - 1
+	}
+	return name
+}
+
+// writeWorkbook writes a new single-sheet workbook, refusing to overwrite existing files
+func writeWorkbook(fullPath, sheet string, columns []string, rows [][]interface{}) error {
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	f, err := os.OpenFile(fullPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		if os.IsExist(err) {
+			return fmt.Errorf("file already exists: %s", filepath.Base(fullPath))
+		}
+		return err
+	}
+
+	zw := zip.NewWriter(f)
+	parts := []struct {
+		name    string
+		content string
+	}{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRootRels},
+		{"xl/workbook.xml", fmt.Sprintf(xlsxWorkbookTemplate, xmlEscape(sheet))},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+		{"xl/worksheets/sheet1.xml", buildSheetXML(columns, rows)},
+	}
+
+	for _, part := range parts {
+		w, err := zw.Create(part.name)
+		if err != nil {
+			zw.Close()
+			f.Close()
+			return err
+		}
+		if _, err := io.WriteString(w, part.content); err != nil {
+			zw.Close()
+			f.Close()
+			return err
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// buildSheetXML renders worksheet XML using inline strings
+func buildSheetXML(columns []string, rows [][]interface{}) string {
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	b.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+
+	rowNum := 0
+	writeRow := func(values []interface{}) {
+		rowNum++
+		b.WriteString(fmt.Sprintf(`<row r="%d">`, rowNum))
+		for i, v := range values {
+			ref := fmt.Sprintf("%s%d", columnName(i), rowNum)
+			switch val := v.(type) {
+			case nil:
+				continue
+			case bool:
+				flag := "0"
+				if val {
+					flag = "1"
+				}
+				b.WriteString(fmt.Sprintf(`<c r="%s" t="b"><v>%s</v></c>`, ref, flag))
+			case float64:
+				b.WriteString(fmt.Sprintf(`<c r="%s"><v>%s</v></c>`, ref, strconv.FormatFloat(val, 'f', -1, 64)))
+			case int, int64, int32:
+				b.WriteString(fmt.Sprintf(`<c r="%s"><v>%d</v></c>`, ref, val))
+			default:
+				b.WriteString(fmt.Sprintf(`<c r="%s" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, ref, xmlEscape(fmt.Sprintf("%v", val))))
+			}
+		}
+		b.WriteString(`</row>`)
+	}
+
+	if len(columns) > 0 {
+		header := make([]interface{}, len(columns))
+		for i, c := range columns {
+			header[i] = c
+		}
+		writeRow(header)
+	}
+	for _, row := range rows {
+		writeRow(row)
+	}
+
+	b.WriteString(`</sheetData></worksheet>`)
+	return b.String()
+}
+
+// xmlEscape escapes text for inclusion in XML content or attributes
+func xmlEscape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+const xlsxContentTypes = xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+	`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+	`<Default Extension="xml" ContentType="application/xml"/>` +
+	`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+	`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+	`</Types>`
+
+const xlsxRootRels = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+	`</Relationships>`
+
+const xlsxWorkbookTemplate = xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+	`<sheets><sheet name="%s" sheetId="1" r:id="rId1"/></sheets>` +
+	`</workbook>`
+
+const xlsxWorkbookRels = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+	`</Relationships>`
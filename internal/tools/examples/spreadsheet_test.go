@@ -0,0 +1,173 @@
+package examples
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestSpreadsheetTool_Definition(t *testing.T) {
+	tool := NewSpreadsheetTool()
+	def := tool.Definition()
+
+	if def == nil {
+		t.Fatal("Definition should not be nil")
+	}
+
+	if def.Name != "xlsx" {
+		t.Errorf("Expected name 'xlsx', got '%s'", def.Name)
+	}
+}
+
+func TestSpreadsheetTool_WriteAndRead(t *testing.T) {
+	tool := NewSpreadsheetTool()
+	tool.sandboxDir = t.TempDir()
+	ctx := context.Background()
+
+	writeParams := map[string]interface{}{
+		"operation": "write",
+		"path":      "results/table.xlsx",
+		"sheet":     "Results",
+		"columns":   []interface{}{"name", "count", "score", "active"},
+		"rows": []interface{}{
+			[]interface{}{"alpha", 3.0, 1.5, true},
+			[]interface{}{"beta", 7.0, 2.0, false},
+		},
+	}
+
+	result, err := tool.Execute(ctx, writeParams)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("Expected successful write, got error: %v", result.Content[0].Text)
+	}
+
+	// Writing to the same path again must not overwrite the workbook
+	result, _ = tool.Execute(ctx, writeParams)
+	if !result.IsError {
+		t.Error("Expected error when overwriting an existing workbook")
+	}
+
+	data, err := readWorkbookSheet(tool.sandboxDir+"/results/table.xlsx", "", true)
+	if err != nil {
+		t.Fatalf("Failed to read workbook: %v", err)
+	}
+
+	if data.Sheet != "Results" {
+		t.Errorf("Expected sheet 'Results', got '%s'", data.Sheet)
+	}
+	if data.RowCount != 2 {
+		t.Fatalf("Expected 2 rows, got %d", data.RowCount)
+	}
+
+	expectedTypes := map[string]string{
+		"name":   "string",
+		"count":  "integer",
+		"score":  "number",
+		"active": "boolean",
+	}
+	for _, col := range data.Columns {
+		if expectedTypes[col.Name] != col.Type {
+			t.Errorf("Column %s: expected type %s, got %s", col.Name, expectedTypes[col.Name], col.Type)
+		}
+	}
+
+	if data.Rows[1]["name"] != "beta" {
+		t.Errorf("Expected name 'beta', got %v", data.Rows[1]["name"])
+	}
+	if data.Rows[1]["count"] != int64(7) {
+		t.Errorf("Expected count 7, got %v", data.Rows[1]["count"])
+	}
+	if data.Rows[0]["active"] != true {
+		t.Errorf("Expected active true, got %v", data.Rows[0]["active"])
+	}
+}
+
+func TestSpreadsheetTool_ReadLimits(t *testing.T) {
+	dir := t.TempDir()
+
+	// Repeated and missing header names are made unique
+	if err := writeWorkbook(dir+"/dup.xlsx", "Sheet1", []string{"name", "name", "", "name_2"}, [][]interface{}{{"a", "b", "c", "d"}}); err != nil {
+		t.Fatalf("Failed to write workbook: %v", err)
+	}
+	data, err := readWorkbookSheet(dir+"/dup.xlsx", "", true)
+	if err != nil {
+		t.Fatalf("Failed to read workbook: %v", err)
+	}
+	var names []string
+	for _, col := range data.Columns {
+		names = append(names, col.Name)
+	}
+	if strings.Join(names, ",") != "name,name_2,C,name_2_2" || data.Rows[0]["name_2"] != "b" {
+		t.Errorf("Expected unique column names, got %v and %v", names, data.Rows[0])
+	}
+
+	// A cell in column XFD on every row would expand past the budget
+	rows := make([][]interface{}, maxCells/maxColumns+2)
+	for i := range rows {
+		rows[i] = make([]interface{}, maxColumns)
+		rows[i][maxColumns-1] = "far"
+	}
+	if err := writeWorkbook(dir+"/wide.xlsx", "Sheet1", nil, rows); err != nil {
+		t.Fatalf("Failed to write workbook: %v", err)
+	}
+	if _, err := readWorkbookSheet(dir+"/wide.xlsx", "", false); err == nil {
+		t.Error("Expected a sheet over the cell budget to be rejected")
+	}
+}
+
+func TestSpreadsheetTool_PathOutsideSandbox(t *testing.T) {
+	tool := NewSpreadsheetTool()
+	tool.sandboxDir = t.TempDir()
+	ctx := context.Background()
+
+	paths := []string{"../escape.xlsx", "/etc/passwd.xlsx", "notes.txt"}
+	for _, p := range paths {
+		result, err := tool.Execute(ctx, map[string]interface{}{
+			"operation": "read",
+			"path":      p,
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !result.IsError {
+			t.Errorf("Expected error for path %q", p)
+		}
+	}
+}
+
+func TestColumnNameAndIndex(t *testing.T) {
+	tests := []struct {
+		index int
+		name  string
+	}{
+		{0, "A"},
+		{25, "Z"},
+		{26, "AA"},
+		{701, "ZZ"},
+		{702, "AAA"},
+	}
+
+	for _, tt := range tests {
+		if got := columnName(tt.index); got != tt.name {
+			t.Errorf("columnName(%d) = %s, want %s", tt.index, got, tt.name)
+		}
+		idx, err := columnIndex(tt.name + "12")
+		if err != nil {
+			t.Fatalf("columnIndex(%s) failed: %v", tt.name, err)
+		}
+		if idx != tt.index {
+			t.Errorf("columnIndex(%s) = %d, want %d", tt.name, idx, tt.index)
+		}
+	}
+
+	if idx, err := columnIndex("XFD1"); err != nil || idx != 16383 {
+		t.Errorf("columnIndex(XFD1) = %d, %v, want 16383", idx, err)
+	}
+	for _, ref := range []string{"XFE1", "ZZZZZZZZZZZZZZZZ1"} {
+		if _, err := columnIndex(ref); err == nil {
+			t.Errorf("Expected columnIndex(%s) to reject a column beyond XFD", ref)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package examples
+
+import (
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// tokenIndex is a single-pass tokenization of a text. It records the token
+// sequence and per-token frequencies so keyword, topic, sentiment, and entity
+// stages can share one pass over the text instead of rescanning it for every
+// vocabulary term. It is safe for concurrent readers.
+type tokenIndex struct {
+	tokens []string
+	freq   map[string]int
+
+	positionsOnce sync.Once
+	positions     map[string][]int
+}
+
+// newTokenIndex tokenizes text once and builds its frequency index
+func newTokenIndex(text string) *tokenIndex {
+	tokens := tokenize(text)
+	freq := make(map[string]int, len(tokens)/2+1)
+	for _, token := range tokens {
+		freq[token]++
+	}
+	return &tokenIndex{
+		tokens: tokens,
+		freq:   freq,
+	}
+}
+
+// tokenize splits text into lowercase runs of letters. Any non-letter rune
+// acts as a separator, matching the analyzer's historical tokenization.
+func tokenize(text string) []string {
+	var tokens []string
+	var current strings.Builder
+
+	flush := func() {
+		if current.Len() > 0 {
+			tokens = append(tokens, current.String())
+			current.Reset()
+		}
+	}
+
+	for _, r := range text {
+		if unicode.IsLetter(r) {
+			current.WriteRune(unicode.ToLower(r))
+		} else {
+			flush()
+		}
+	}
+	flush()
+
+	return tokens
+}
+
+// Len returns the number of tokens in the text
+func (ti *tokenIndex) Len() int {
+	return len(ti.tokens)
+}
+
+// Count returns how many times a term occurs as whole tokens. Multi-word
+// terms such as "machine learning" are matched as consecutive tokens.
+func (ti *tokenIndex) Count(term string) int {
+	parts := tokenize(term)
+	switch len(parts) {
+	case 0:
+		return 0
+	case 1:
+		return ti.freq[parts[0]]
+	}
+
+	// Cheap rejection before building the position index
+	for _, part := range parts {
+		if ti.freq[part] == 0 {
+			return 0
+		}
+	}
+
+	ti.positionsOnce.Do(ti.buildPositions)
+
+	count := 0
+	for _, pos := range ti.positions[parts[0]] {
+		if pos+len(parts) > len(ti.tokens) {
+			break
+		}
+		matched := true
+		for i := 1; i < len(parts); i++ {
+			if ti.tokens[pos+i] != parts[i] {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			count++
+		}
+	}
+	return count
+}
+
+// CountAll returns the total occurrences of all terms
+func (ti *tokenIndex) CountAll(terms []string) int {
+	total := 0
+	for _, term := range terms {
+		total += ti.Count(term)
+	}
+	return total
+}
+
+// buildPositions records the token offsets of every distinct token
+func (ti *tokenIndex) buildPositions() {
+	ti.positions = make(map[string][]int, len(ti.freq))
+	for i, token := range ti.tokens {
+		ti.positions[token] = append(ti.positions[token], i)
+	}
+}
@@ -0,0 +1,29 @@
+package examples
+
+import "testing"
+
+func TestTokenIndex_Count(t *testing.T) {
+	idx := newTokenIndex("Machine learning is great. Deep learning, machine-learning, and MACHINE learning!")
+
+	tests := []struct {
+		term     string
+		expected int
+	}{
+		{"learning", 4},
+		{"machine learning", 3},
+		{"deep learning", 1},
+		{"learning machine", 1},
+		{"neural network", 0},
+		{"", 0},
+	}
+
+	for _, test := range tests {
+		if got := idx.Count(test.term); got != test.expected {
+			t.Errorf("Count(%q) = %d, want %d", test.term, got, test.expected)
+		}
+	}
+
+	if idx.Len() != 11 {
+		t.Errorf("Expected 11 tokens, got %d", idx.Len())
+	}
+}
@@ -7,10 +7,12 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/chongliujia/mcp-go-template/pkg/mcp"
+	"github.com/chongliujia/mcp-go-template/pkg/utils/httpclient"
 )
 
 // SearchEngineConfig holds configuration for search engines
@@ -20,6 +22,10 @@ type SearchEngineConfig struct {
 	Enabled     bool
 	RateLimit   time.Duration
 	MaxRetries  int
+	// APIKey authenticates requests; Brave Search requires one
+	APIKey string
+	// Timeout bounds each request; zero leaves it to the HTTP client
+	Timeout time.Duration
 }
 
 // WebSearchTool implements web search functionality using multiple search engines
@@ -47,12 +53,24 @@ type SearchResponse struct {
 	Duration string        `json:"duration"`
 }
 
+// webSearchArgs are the arguments of the web search tool. max_results is
+// read separately, as malformed values fall back to the default.
+type webSearchArgs struct {
+	Query      string `json:"query"`
+	Engine     string `json:"engine" enum:"duckduckgo,searxng,brave,auto" default:"auto"`
+	SafeSearch bool   `json:"safe_search" default:"true"`
+	Language   string `json:"language" default:"en"`
+	Region     string `json:"region" default:"us-en"`
+}
+
 // NewWebSearchTool creates a new web search tool with enhanced configuration
 func NewWebSearchTool() *WebSearchTool {
 	return &WebSearchTool{
 		definition: &mcp.Tool{
 			Name:        "web_search",
 			Description: "Searches the web using multiple search engines (DuckDuckGo, SearXNG, Brave Search) and returns structured results with titles, URLs, descriptions, and sources. Includes rate limiting and fallback mechanisms.",
+			Category:    mcp.ToolCategoryNetwork,
+			Tags:        []string{"research", "search"},
 			InputSchema: mcp.ToolSchema{
 				Type: "object",
 				Properties: map[string]interface{}{
@@ -94,15 +112,30 @@ func NewWebSearchTool() *WebSearchTool {
 				},
 				Required: []string{"query"},
 			},
-		},
-		client: &http.Client{
-			Timeout: 30 * time.Second,
-			Transport: &http.Transport{
-				MaxIdleConns:        10,
-				IdleConnTimeout:     30 * time.Second,
-				DisableCompression:  false,
+			OutputSchema: &mcp.ToolSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"query": map[string]interface{}{"type": "string"},
+					"results": map[string]interface{}{
+						"type": "array",
+						"items": map[string]interface{}{
+							"type": "object",
+							"properties": map[string]interface{}{
+								"title":       map[string]interface{}{"type": "string"},
+								"url":         map[string]interface{}{"type": "string"},
+								"description": map[string]interface{}{"type": "string"},
+								"source":      map[string]interface{}{"type": "string"},
+							},
+						},
+					},
+					"total":    map[string]interface{}{"type": "integer"},
+					"engine":   map[string]interface{}{"type": "string"},
+					"duration": map[string]interface{}{"type": "string"},
+				},
+				Required: []string{"query", "results", "total", "engine"},
 			},
 		},
+		client: httpclient.New("web_search"),
 		engines: map[string]SearchEngineConfig{
 			"duckduckgo": {
 				Name:        "DuckDuckGo",
@@ -120,7 +153,7 @@ func NewWebSearchTool() *WebSearchTool {
 			},
 			"brave": {
 				Name:        "Brave Search",
-				BaseURL:     "https://search.brave.com/api/search",
+				BaseURL:     "https://api.search.brave.com/res/v1/web/search",
 				Enabled:     false, // Requires API key
 				RateLimit:   time.Second * 1,
 				MaxRetries:  2,
@@ -130,6 +163,20 @@ func NewWebSearchTool() *WebSearchTool {
 	}
 }
 
+// SetEngine replaces the configuration of a search engine, keeping its
+// display name when config has none
+func (w *WebSearchTool) SetEngine(name string, config SearchEngineConfig) error {
+	current, exists := w.engines[name]
+	if !exists {
+		return fmt.Errorf("unknown search engine: %s", name)
+	}
+	if config.Name == "" {
+		config.Name = current.Name
+	}
+	w.engines[name] = config
+	return nil
+}
+
 // Definition returns the tool definition
 func (w *WebSearchTool) Definition() *mcp.Tool {
 	return w.definition
@@ -140,19 +187,19 @@ func (w *WebSearchTool) Execute(ctx context.Context, params map[string]interface
 	startTime := time.Now()
 	
 	// Enhanced parameter extraction and validation
-	query, ok := params["query"].(string)
-	if !ok || query == "" {
+	var args webSearchArgs
+	if err := mcp.BindArguments(params, &args); err != nil {
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{{
 				Type: "text",
-				Text: "Error: query parameter is required and must be a non-empty string",
+				Text: fmt.Sprintf("Error: %v", err),
 			}},
 			IsError: true,
 		}, nil
 	}
 	
 	// Validate query length
-	query = strings.TrimSpace(query)
+	query := strings.TrimSpace(args.Query)
 	if len(query) == 0 {
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{{
@@ -182,62 +229,51 @@ func (w *WebSearchTool) Execute(ctx context.Context, params map[string]interface
 		maxResults = 10
 	}
 
-	engine := "auto"
-	if val, exists := params["engine"]; exists {
-		if eng, ok := val.(string); ok {
-			engine = eng
-		}
+	engine, safeSearch, region := args.Engine, args.SafeSearch, args.Region
+	language := args.Language
+	if len(language) != 2 {
+		language = "en"
 	}
 
-	safeSearch := true
-	if val, exists := params["safe_search"]; exists {
-		if safe, ok := val.(bool); ok {
-			safeSearch = safe
-		}
-	}
-	
-	language := "en"
-	if val, exists := params["language"]; exists {
-		if lang, ok := val.(string); ok && len(lang) == 2 {
-			language = lang
-		}
-	}
-	
-	region := "us-en"
-	if val, exists := params["region"]; exists {
-		if reg, ok := val.(string); ok {
-			region = reg
+	// A disabled engine cannot answer; let the user pick another one
+	if config, exists := w.engines[engine]; exists && !config.Enabled && mcp.ClientSupportsElicitation(ctx) {
+		if chosen := w.elicitEngine(ctx, engine); chosen != "" {
+			engine = chosen
 		}
 	}
 
 	// Perform search with fallback mechanisms
+	progress := mcp.ProgressFromContext(ctx)
 	var results []SearchResult
 	var searchEngine string
 	var searchErrors []error
 
 	switch engine {
 	case "duckduckgo":
-		results, searchEngine, searchErrors = w.searchWithRetry("duckduckgo", query, maxResults, safeSearch, language, region)
+		results, searchEngine, searchErrors = w.searchWithRetry(ctx, progress, "duckduckgo", query, maxResults, safeSearch, language, region)
 	case "searxng":
-		results, searchEngine, searchErrors = w.searchWithRetry("searxng", query, maxResults, safeSearch, language, region)
+		results, searchEngine, searchErrors = w.searchWithRetry(ctx, progress, "searxng", query, maxResults, safeSearch, language, region)
 	case "brave":
-		results, searchEngine, searchErrors = w.searchWithRetry("brave", query, maxResults, safeSearch, language, region)
+		results, searchEngine, searchErrors = w.searchWithRetry(ctx, progress, "brave", query, maxResults, safeSearch, language, region)
 	case "auto":
-		// Try engines in order of preference
-		engineOrder := []string{"duckduckgo", "searxng"}
-		for _, eng := range engineOrder {
+		// Try engines in order of preference; Brave only runs once it is
+		// configured with an API key
+		engineOrder := []string{"brave", "duckduckgo", "searxng"}
+		for i, eng := range engineOrder {
 			if w.engines[eng].Enabled {
+				progress(float64(i), float64(len(engineOrder)), fmt.Sprintf("Searching %s", w.engines[eng].Name))
 				var errs []error
-				results, searchEngine, errs = w.searchWithRetry(eng, query, maxResults, safeSearch, language, region)
+				results, searchEngine, errs = w.searchWithRetry(ctx, nil, eng, query, maxResults, safeSearch, language, region)
 				searchErrors = append(searchErrors, errs...)
-				if len(results) > 0 {
+				if len(results) > 0 || ctx.Err() != nil {
 					break
 				}
 			}
 		}
 		
 		// If no results from APIs, use simulated results
-		if len(results) == 0 {
+		if len(results) == 0 && ctx.Err() == nil {
+			mcp.CallContextFromContext(ctx).Logger.WithField("errors", len(searchErrors)).Warn("All search engines failed, returning simulated results")
 			var err error
 			results, err = w.simulateSearch(query, maxResults)
 			if err != nil {
@@ -256,6 +292,11 @@ func (w *WebSearchTool) Execute(ctx context.Context, params map[string]interface
 		}, nil
 	}
 
+	// A cancelled call has no one waiting for its results
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	// If still no results, return error with details
 	if len(results) == 0 {
 		errorMsg := fmt.Sprintf("Search failed for query '%s'. Errors encountered:", query)
@@ -312,30 +353,12 @@ func (w *WebSearchTool) Execute(ctx context.Context, params map[string]interface
 		}
 	}
 
-	// Convert to JSON for structured output
-	jsonData, err := json.MarshalIndent(response, "", "  ")
-	if err != nil {
-		jsonData = []byte(fmt.Sprintf(`{"error": "failed to marshal response: %v"}`, err))
-	}
-
-	return &mcp.CallToolResult{
-		Content: []mcp.Content{
-			{
-				Type: "text",
-				Text: resultText.String(),
-			},
-			{
-				Type:     "text",
-				Text:     string(jsonData),
-				MimeType: "application/json",
-			},
-		},
-		IsError: false,
-	}, nil
+	return mcp.NewStructuredResult(resultText.String(), response), nil
 }
 
-// searchWithRetry attempts to search using the specified engine with retry logic
-func (w *WebSearchTool) searchWithRetry(engineName, query string, maxResults int, safeSearch bool, language, region string) ([]SearchResult, string, []error) {
+// searchWithRetry attempts to search using the specified engine with retry
+// logic, reporting each retry to progress unless it is nil
+func (w *WebSearchTool) searchWithRetry(ctx context.Context, progress mcp.ProgressReporter, engineName, query string, maxResults int, safeSearch bool, language, region string) ([]SearchResult, string, []error) {
 	engineConfig, exists := w.engines[engineName]
 	if !exists || !engineConfig.Enabled {
 		return nil, "", []error{fmt.Errorf("engine %s not available", engineName)}
@@ -344,7 +367,9 @@ func (w *WebSearchTool) searchWithRetry(engineName, query string, maxResults int
 	// Rate limiting
 	if lastReq, exists := w.lastRequest[engineName]; exists {
 		if time.Since(lastReq) < engineConfig.RateLimit {
-			time.Sleep(engineConfig.RateLimit - time.Since(lastReq))
+			if err := sleepContext(ctx, engineConfig.RateLimit-time.Since(lastReq)); err != nil {
+				return nil, "", []error{err}
+			}
 		}
 	}
 	
@@ -353,18 +378,13 @@ func (w *WebSearchTool) searchWithRetry(engineName, query string, maxResults int
 	
 	for attempt := 0; attempt <= engineConfig.MaxRetries; attempt++ {
 		var err error
-		
-		switch engineName {
-		case "duckduckgo":
-			results, err = w.searchDuckDuckGo(query, maxResults, safeSearch, language, region)
-		case "searxng":
-			results, err = w.searchSearXNG(query, maxResults, safeSearch, language, region)
-		case "brave":
-			results, err = w.searchBrave(query, maxResults, safeSearch, language, region)
-		default:
-			return nil, "", []error{fmt.Errorf("unsupported engine: %s", engineName)}
+
+		if progress != nil && attempt > 0 {
+			progress(float64(attempt), float64(engineConfig.MaxRetries+1), fmt.Sprintf("Retrying %s (attempt %d of %d)", engineConfig.Name, attempt+1, engineConfig.MaxRetries+1))
 		}
 		
+		results, err = w.searchEngine(ctx, engineName, query, maxResults, safeSearch, language, region)
+		
 		w.lastRequest[engineName] = time.Now()
 		
 		if err == nil && len(results) > 0 {
@@ -375,18 +395,96 @@ func (w *WebSearchTool) searchWithRetry(engineName, query string, maxResults int
 			errors = append(errors, fmt.Errorf("attempt %d with %s: %w", attempt+1, engineConfig.Name, err))
 		}
 		
-		// Wait before retry (exponential backoff)
+		// Wait before retry (exponential backoff), giving up if the call
+		// is cancelled
 		if attempt < engineConfig.MaxRetries {
 			waitTime := time.Duration(attempt+1) * time.Second
-			time.Sleep(waitTime)
+			if err := sleepContext(ctx, waitTime); err != nil {
+				return results, engineConfig.Name, append(errors, err)
+			}
 		}
 	}
 	
 	return results, engineConfig.Name, errors
 }
 
+// searchEngine runs one search request with the engine, within the
+// engine's timeout
+func (w *WebSearchTool) searchEngine(ctx context.Context, engineName, query string, maxResults int, safeSearch bool, language, region string) ([]SearchResult, error) {
+	if timeout := w.engines[engineName].Timeout; timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	switch engineName {
+	case "duckduckgo":
+		return w.searchDuckDuckGo(ctx, query, maxResults, safeSearch, language, region)
+	case "searxng":
+		return w.searchSearXNG(ctx, query, maxResults, safeSearch, language, region)
+	case "brave":
+		return w.searchBrave(ctx, query, maxResults, safeSearch, language, region)
+	}
+	return nil, fmt.Errorf("unsupported engine: %s", engineName)
+}
+
+// elicitEngine asks the user which engine to use instead of a disabled
+// one, returning "" if they decline or the client cannot ask
+func (w *WebSearchTool) elicitEngine(ctx context.Context, disabled string) string {
+	var options []string
+	for name, config := range w.engines {
+		if config.Enabled {
+			options = append(options, name)
+		}
+	}
+	sort.Strings(options)
+	options = append(options, "auto")
+
+	result, err := mcp.Elicit(ctx, &mcp.ElicitParams{
+		Message: fmt.Sprintf("%s is not configured on this server. Which search engine should be used instead?", w.engines[disabled].Name),
+		RequestedSchema: mcp.ToolSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"engine": map[string]interface{}{
+					"type":        "string",
+					"title":       "Search engine",
+					"description": "Engine to run the search with",
+					"enum":        options,
+					"default":     "auto",
+				},
+			},
+			Required: []string{"engine"},
+		},
+	})
+	if err != nil || !result.Accepted() {
+		return ""
+	}
+
+	chosen, _ := result.Content["engine"].(string)
+	for _, option := range options {
+		if chosen == option {
+			return chosen
+		}
+	}
+	return ""
+}
+
+// sleepContext waits for d or until ctx is done, returning ctx's error in
+// the latter case
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 // searchSearXNG performs search using SearXNG API
-func (w *WebSearchTool) searchSearXNG(query string, maxResults int, safeSearch bool, language, region string) ([]SearchResult, error) {
+func (w *WebSearchTool) searchSearXNG(ctx context.Context, query string, maxResults int, safeSearch bool, language, region string) ([]SearchResult, error) {
 	baseURL := w.engines["searxng"].BaseURL
 	
 	params := url.Values{}
@@ -403,12 +501,11 @@ func (w *WebSearchTool) searchSearXNG(query string, maxResults int, safeSearch b
 	
 	reqURL := baseURL + "?" + params.Encode()
 	
-	req, err := http.NewRequest("GET", reqURL, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", reqURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create SearXNG request: %w", err)
 	}
 	
-	req.Header.Set("User-Agent", "Mozilla/5.0 (compatible; MCP-Go-Template/1.0)")
 	req.Header.Set("Accept", "application/json")
 	
 	resp, err := w.client.Do(req)
@@ -457,17 +554,78 @@ func (w *WebSearchTool) searchSearXNG(query string, maxResults int, safeSearch b
 	return results, nil
 }
 
-// searchBrave performs search using Brave Search API (placeholder)
-func (w *WebSearchTool) searchBrave(query string, maxResults int, safeSearch bool, language, region string) ([]SearchResult, error) {
-	// Brave Search API requires an API key and subscription
-	// This is a placeholder implementation
-	return nil, fmt.Errorf("Brave Search API not implemented - requires API key")
+// searchBrave performs search using the Brave Search API, which requires
+// an API key
+func (w *WebSearchTool) searchBrave(ctx context.Context, query string, maxResults int, safeSearch bool, language, region string) ([]SearchResult, error) {
+	engine := w.engines["brave"]
+	if engine.APIKey == "" {
+		return nil, fmt.Errorf("Brave Search requires an API key")
+	}
+
+	params := url.Values{}
+	params.Set("q", query)
+	// The API returns at most 20 results per request
+	params.Set("count", fmt.Sprint(min(maxResults, 20)))
+	params.Set("search_lang", language)
+	if country, _, _ := strings.Cut(region, "-"); len(country) == 2 {
+		params.Set("country", strings.ToUpper(country))
+	}
+	if safeSearch {
+		params.Set("safesearch", "strict")
+	} else {
+		params.Set("safesearch", "off")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", engine.BaseURL+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Brave Search request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("X-Subscription-Token", engine.APIKey)
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to perform Brave Search: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Brave Search HTTP error: %d %s", resp.StatusCode, resp.Status)
+	}
+
+	var braveResp struct {
+		Web struct {
+			Results []struct {
+				Title       string `json:"title"`
+				URL         string `json:"url"`
+				Description string `json:"description"`
+			} `json:"results"`
+		} `json:"web"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&braveResp); err != nil {
+		return nil, fmt.Errorf("failed to parse Brave Search response: %w", err)
+	}
+
+	var results []SearchResult
+	for i, result := range braveResp.Web.Results {
+		if i >= maxResults {
+			break
+		}
+		results = append(results, SearchResult{
+			Title:       result.Title,
+			URL:         result.URL,
+			Description: result.Description,
+			Source:      "Brave Search",
+		})
+	}
+
+	return results, nil
 }
 
 // searchDuckDuckGo performs search using DuckDuckGo with enhanced parameters
-func (w *WebSearchTool) searchDuckDuckGo(query string, maxResults int, safeSearch bool, language, region string) ([]SearchResult, error) {
+func (w *WebSearchTool) searchDuckDuckGo(ctx context.Context, query string, maxResults int, safeSearch bool, language, region string) ([]SearchResult, error) {
 	// DuckDuckGo Instant Answer API (limited functionality)
-	baseURL := "https://api.duckduckgo.com/"
+	baseURL := w.engines["duckduckgo"].BaseURL
 	
 	params := url.Values{}
 	params.Set("q", query)
@@ -483,12 +641,11 @@ func (w *WebSearchTool) searchDuckDuckGo(query string, maxResults int, safeSearc
 	// DuckDuckGo doesn't support language/region parameters in the free API
 	reqURL := baseURL + "?" + params.Encode()
 	
-	req, err := http.NewRequest("GET", reqURL, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", reqURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create DuckDuckGo request: %w", err)
 	}
 	
-	req.Header.Set("User-Agent", "Mozilla/5.0 (compatible; MCP-Go-Template/1.0)")
 	
 	resp, err := w.client.Do(req)
 	if err != nil {
@@ -2,6 +2,8 @@ package examples
 
 import (
 	"context"
+	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 	"time"
@@ -43,8 +45,8 @@ func TestWebSearchTool_Execute_ValidQuery(t *testing.T) {
 		t.Errorf("Expected successful search, got error: %v", result.Content[0].Text)
 	}
 	
-	if len(result.Content) < 2 {
-		t.Fatal("Expected at least 2 content items (text and JSON)")
+	if result.StructuredContent == nil {
+		t.Fatal("Expected structured content")
 	}
 }
 
@@ -360,11 +362,11 @@ func TestWebSearchTool_RateLimiting(t *testing.T) {
 	}
 	
 	// First request should set the timestamp
-	_, _, _ = search.searchWithRetry("duckduckgo", "test", 5, true, "en", "us-en")
+	_, _, _ = search.searchWithRetry(context.Background(), nil, "duckduckgo", "test", 5, true, "en", "us-en")
 	
 	// Second immediate request should trigger rate limiting
 	start := time.Now()
-	_, _, _ = search.searchWithRetry("duckduckgo", "test2", 5, true, "en", "us-en")
+	_, _, _ = search.searchWithRetry(context.Background(), nil, "duckduckgo", "test2", 5, true, "en", "us-en")
 	duration := time.Since(start)
 	
 	// Should have waited at least part of the rate limit duration
@@ -373,6 +375,45 @@ func TestWebSearchTool_RateLimiting(t *testing.T) {
 	}
 }
 
+func TestWebSearchTool_SetEngine_Brave(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Subscription-Token") != "brave-key" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Query().Get("q") != "golang" || r.URL.Query().Get("country") != "US" {
+			t.Errorf("Unexpected query: %s", r.URL.RawQuery)
+		}
+		w.Write([]byte(`{"web": {"results": [{"title": "Go", "url": "https://go.dev", "description": "The Go language"}]}}`))
+	}))
+	defer server.Close()
+
+	search := NewWebSearchTool()
+	if err := search.SetEngine("bing", SearchEngineConfig{}); err == nil {
+		t.Error("Expected an unknown engine to be rejected")
+	}
+	if err := search.SetEngine("brave", SearchEngineConfig{
+		BaseURL:    server.URL,
+		APIKey:     "brave-key",
+		Enabled:    true,
+		Timeout:    time.Second,
+		MaxRetries: 0,
+	}); err != nil {
+		t.Fatalf("SetEngine failed: %v", err)
+	}
+	if search.engines["brave"].Name != "Brave Search" {
+		t.Errorf("Expected the name to be kept, got %s", search.engines["brave"].Name)
+	}
+
+	results, engine, err := search.searchWithRetry(context.Background(), nil, "brave", "golang", 5, true, "en", "us-en")
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if engine != "Brave Search" || len(results) != 1 || results[0].URL != "https://go.dev" {
+		t.Errorf("Unexpected results from %s: %+v", engine, results)
+	}
+}
+
 func TestWebSearchTool_MissingParameters(t *testing.T) {
 	search := NewWebSearchTool()
 	ctx := context.Background()
@@ -439,18 +480,14 @@ func TestWebSearchTool_JSONOutput(t *testing.T) {
 		t.Fatalf("Unexpected error: %v", err)
 	}
 	
-	if len(result.Content) < 2 {
-		t.Fatal("Expected at least 2 content items")
-	}
-	
-	// Check that second content item is JSON
-	jsonContent := result.Content[1]
-	if jsonContent.MimeType != "application/json" {
-		t.Errorf("Expected JSON mime type, got %s", jsonContent.MimeType)
+	// The response is returned as structured content
+	response, ok := result.StructuredContent.(SearchResponse)
+	if !ok {
+		t.Fatalf("Expected a SearchResponse as structured content, got %T", result.StructuredContent)
 	}
 	
-	if jsonContent.Text == "" {
-		t.Error("JSON content should not be empty")
+	if response.Query != "test query" || response.Total != len(response.Results) {
+		t.Errorf("Unexpected structured content %+v", response)
 	}
 }
 
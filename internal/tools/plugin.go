@@ -0,0 +1,131 @@
+package tools
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"plugin"
+	"strings"
+
+	"github.com/chongliujia/mcp-go-template/pkg/mcp"
+	"github.com/chongliujia/mcp-go-template/pkg/utils"
+)
+
+// PluginSymbol is the function a tool plugin must export
+const PluginSymbol = "NewTools"
+
+// PluginLoader registers the tools of the Go plugins in a directory with a
+// ToolTarget.
+//
+// Plugins must be built with -buildmode=plugin against the same Go version
+// and module versions as the server, and cannot be unloaded, so the
+// directory is read once at startup.
+type PluginLoader struct {
+	dir    string
+	target ToolTarget
+	logger utils.Logger
+}
+
+// NewPluginLoader creates a loader for the plugins in dir
+func NewPluginLoader(dir string, target ToolTarget) *PluginLoader {
+	return &PluginLoader{
+		dir:    dir,
+		target: target,
+		logger: utils.DefaultLogger(),
+	}
+}
+
+// SetLogger sets the logger the loader logs to. It must be called before
+// Load.
+func (l *PluginLoader) SetLogger(logger utils.Logger) {
+	l.logger = logger
+}
+
+// LoadPlugins loads the plugins in dir into target with a PluginLoader
+func LoadPlugins(dir string, target ToolTarget) (int, error) {
+	return NewPluginLoader(dir, target).Load()
+}
+
+// Load opens every Go plugin (*.so) in the directory and registers the
+// tools returned by its NewTools function, which must have the signature
+// func() []mcp.ToolHandler. Plugins that fail to load and tools whose name is
+// already registered are skipped with a warning. It returns the number of
+// tools registered.
+func (l *PluginLoader) Load() (int, error) {
+	entries, err := os.ReadDir(l.dir)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read plugins directory: %w", err)
+	}
+
+	existing, err := l.target.ListTools()
+	if err != nil {
+		return 0, fmt.Errorf("failed to list tools: %w", err)
+	}
+	registered := make(map[string]bool, len(existing))
+	for _, tool := range existing {
+		registered[tool.Name] = true
+	}
+
+	count := 0
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.EqualFold(filepath.Ext(entry.Name()), ".so") {
+			continue
+		}
+
+		path := filepath.Join(l.dir, entry.Name())
+		handlers, err := openPlugin(path)
+		if err != nil {
+			l.logger.Warnf("Skipping plugin %s: %v", path, err)
+			continue
+		}
+
+		for _, handler := range handlers {
+			if handler == nil || handler.Definition() == nil {
+				l.logger.Warnf("Skipping tool from plugin %s: missing definition", path)
+				continue
+			}
+			name := handler.Definition().Name
+			if registered[name] {
+				l.logger.Warnf("Skipping tool from plugin %s: tool '%s' is already registered", path, name)
+				continue
+			}
+			if err := l.target.RegisterTool(handler); err != nil {
+				l.logger.Warnf("Failed to register tool from plugin %s: %v", path, err)
+				continue
+			}
+			registered[name] = true
+			count++
+			l.logger.Infof("Loaded tool %s from plugin %s", name, path)
+		}
+	}
+	return count, nil
+}
+
+// openPlugin opens a plugin and calls its NewTools function
+func openPlugin(path string) ([]mcp.ToolHandler, error) {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	symbol, err := p.Lookup(PluginSymbol)
+	if err != nil {
+		return nil, err
+	}
+	newTools, err := pluginConstructor(symbol)
+	if err != nil {
+		return nil, err
+	}
+	return newTools(), nil
+}
+
+// pluginConstructor checks the type of a plugin's NewTools symbol
+func pluginConstructor(symbol plugin.Symbol) (func() []mcp.ToolHandler, error) {
+	switch fn := symbol.(type) {
+	case func() []mcp.ToolHandler:
+		return fn, nil
+	case *func() []mcp.ToolHandler:
+		// A variable holding the function rather than the function itself
+		return *fn, nil
+	}
+	return nil, fmt.Errorf("%s has type %T, want func() []mcp.ToolHandler", PluginSymbol, symbol)
+}
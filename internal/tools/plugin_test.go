@@ -0,0 +1,59 @@
+package tools
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus/hooks/test"
+
+	"github.com/chongliujia/mcp-go-template/pkg/mcp"
+	"github.com/chongliujia/mcp-go-template/pkg/utils"
+)
+
+func TestLoadPlugins_SkipsInvalidPlugins(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "broken.so"), "not a plugin")
+	writeFile(t, filepath.Join(dir, "notes.txt"), "ignored")
+
+	handler := mcp.NewBaseHandler(mcp.ServerInfo{Name: "test", Version: "1.0.0"}, mcp.ServerCapabilities{})
+	count, err := LoadPlugins(dir, handler)
+	if err != nil {
+		t.Fatalf("LoadPlugins failed: %v", err)
+	}
+	if count != 0 || len(toolNames(t, handler)) != 0 {
+		t.Errorf("Expected no tools from invalid plugins, got %d", count)
+	}
+
+	if _, err := LoadPlugins(filepath.Join(dir, "missing"), handler); err == nil {
+		t.Error("Expected an error for a missing directory")
+	}
+}
+
+func TestPluginLoader_SetLogger(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "broken.so"), "not a plugin")
+
+	logger, logs := test.NewNullLogger()
+	loader := NewPluginLoader(dir, mcp.NewBaseHandler(mcp.ServerInfo{Name: "test", Version: "1.0.0"}, mcp.ServerCapabilities{}))
+	loader.SetLogger(utils.NewLogger(logger))
+	if _, err := loader.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if entry := logs.LastEntry(); entry == nil || !strings.HasPrefix(entry.Message, "Skipping plugin") {
+		t.Errorf("Expected the skipped plugin logged to the given logger, got %+v", entry)
+	}
+}
+
+func TestPluginConstructor(t *testing.T) {
+	newTools := func() []mcp.ToolHandler { return nil }
+	if _, err := pluginConstructor(newTools); err != nil {
+		t.Errorf("Expected the function to be accepted, got %v", err)
+	}
+	if _, err := pluginConstructor(&newTools); err != nil {
+		t.Errorf("Expected a pointer to the function to be accepted, got %v", err)
+	}
+	if _, err := pluginConstructor(func() []string { return nil }); err == nil {
+		t.Error("Expected a function with the wrong signature to be rejected")
+	}
+}
@@ -0,0 +1,161 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/chongliujia/mcp-go-template/pkg/client"
+	"github.com/chongliujia/mcp-go-template/pkg/mcp"
+	"github.com/chongliujia/mcp-go-template/pkg/utils"
+)
+
+// proxySyncTimeout bounds a tools/list refresh triggered by the remote server
+const proxySyncTimeout = 30 * time.Second
+
+// Proxy registers the tools of another MCP server into a ToolTarget and
+// forwards calls to them, keeping the list in sync when the remote server
+// announces changes
+type Proxy struct {
+	name      string
+	namespace string
+	target    ToolTarget
+	client    *client.Client
+	logger    utils.Logger
+
+	mu     sync.Mutex
+	loaded map[string]string // tool name -> encoded remote definition
+}
+
+// NewProxy creates a proxy for the server reached through dial. name
+// identifies the server in logs.
+func NewProxy(name string, dial client.Dialer, target ToolTarget) *Proxy {
+	p := &Proxy{
+		name:   name,
+		target: target,
+		logger: utils.DefaultLogger(),
+		loaded: make(map[string]string),
+	}
+
+	opts := client.DefaultOptions()
+	opts.OnNotification = func(notification *mcp.Message) {
+		if notification.Method == "notifications/tools/list_changed" {
+			// Sync sends requests, whose responses arrive on the goroutine
+			// delivering this notification
+			go p.resync()
+		}
+	}
+	p.client = client.New(dial, opts)
+	return p
+}
+
+// SetNamespace registers the remote tools as namespace/name, so servers
+// with overlapping tool names can be proxied side by side. It must be
+// called before Connect.
+func (p *Proxy) SetNamespace(namespace string) {
+	p.namespace = namespace
+}
+
+// SetLogger sets the logger the proxy logs to. It must be called before
+// Connect.
+func (p *Proxy) SetLogger(logger utils.Logger) {
+	p.logger = logger
+}
+
+// Connect connects to the remote server and registers its tools
+func (p *Proxy) Connect(ctx context.Context) error {
+	if err := p.client.Connect(ctx); err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", p.name, err)
+	}
+	return p.Sync(ctx)
+}
+
+// Sync brings the registered tools in line with the remote server's list.
+// Remote tools named like a tool registered elsewhere are skipped.
+func (p *Proxy) Sync(ctx context.Context) error {
+	remote, err := p.client.ListTools(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list tools of %s: %w", p.name, err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	existing, err := p.target.ListTools()
+	if err != nil {
+		return fmt.Errorf("failed to list tools: %w", err)
+	}
+	builtin := make(map[string]bool, len(existing))
+	for _, tool := range existing {
+		if _, ours := p.loaded[tool.Name]; !ours {
+			builtin[tool.Name] = true
+		}
+	}
+
+	found := make(map[string]bool, len(remote))
+	for _, tool := range remote {
+		name := mcp.NamespacedName(p.namespace, tool.Name)
+		if builtin[name] {
+			p.logger.Warnf("Skipping tool %s from %s: tool is already registered", name, p.name)
+			continue
+		}
+		found[name] = true
+
+		encoded, _ := json.Marshal(tool)
+		if p.loaded[name] == string(encoded) {
+			continue
+		}
+		proxyTool := &ProxyTool{definition: tool, client: p.client}
+		if err := p.target.RegisterTool(mcp.Namespaced(p.namespace, proxyTool)); err != nil {
+			p.logger.Warnf("Failed to register tool %s from %s: %v", name, p.name, err)
+			continue
+		}
+		p.loaded[name] = string(encoded)
+		p.logger.Infof("Loaded tool %s from %s", name, p.name)
+	}
+
+	for name := range p.loaded {
+		if found[name] {
+			continue
+		}
+		if err := p.target.UnregisterTool(name); err != nil {
+			p.logger.Warnf("Failed to unregister tool %s: %v", name, err)
+		}
+		delete(p.loaded, name)
+		p.logger.Infof("Unloaded tool %s from %s", name, p.name)
+	}
+	return nil
+}
+
+// resync refreshes the tools after the remote server announced a change
+func (p *Proxy) resync() {
+	ctx, cancel := context.WithTimeout(context.Background(), proxySyncTimeout)
+	defer cancel()
+	if err := p.Sync(ctx); err != nil {
+		p.logger.Warnf("Failed to refresh proxied tools: %v", err)
+	}
+}
+
+// Close disconnects from the remote server
+func (p *Proxy) Close() error {
+	return p.client.Close()
+}
+
+// ProxyTool forwards calls to a tool of another MCP server
+type ProxyTool struct {
+	definition *mcp.Tool
+	client     *client.Client
+}
+
+// Definition returns the remote tool's definition
+func (t *ProxyTool) Definition() *mcp.Tool {
+	return t.definition
+}
+
+// Execute calls the remote tool. Its result, including IsError results, is
+// returned as is.
+func (t *ProxyTool) Execute(ctx context.Context, params map[string]interface{}) (*mcp.CallToolResult, error) {
+	return t.client.CallTool(ctx, t.definition.Name, params)
+}
@@ -0,0 +1,116 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/chongliujia/mcp-go-template/internal/tools/examples"
+	"github.com/chongliujia/mcp-go-template/pkg/client"
+	"github.com/chongliujia/mcp-go-template/pkg/mcp"
+)
+
+// handlerTransport connects a client to a handler in the same process
+type handlerTransport struct {
+	handler  *mcp.BaseHandler
+	incoming chan *mcp.Message
+	done     chan struct{}
+	close    sync.Once
+}
+
+func (t *handlerTransport) Send(ctx context.Context, message *mcp.Message) error {
+	response, err := t.handler.HandleMessage(ctx, message)
+	if err != nil {
+		return err
+	}
+	if response != nil {
+		t.push(response)
+	}
+	return nil
+}
+
+func (t *handlerTransport) push(message *mcp.Message) {
+	select {
+	case t.incoming <- message:
+	case <-t.done:
+	}
+}
+
+func (t *handlerTransport) Receive() (*mcp.Message, error) {
+	select {
+	case message := <-t.incoming:
+		return message, nil
+	case <-t.done:
+		return nil, errors.New("transport closed")
+	}
+}
+
+func (t *handlerTransport) Close() error {
+	t.close.Do(func() { close(t.done) })
+	return nil
+}
+
+// dialHandler returns a dialer connecting to handler, which sends its
+// notifications to the connection
+func dialHandler(handler *mcp.BaseHandler) client.Dialer {
+	return func(ctx context.Context) (client.Transport, error) {
+		transport := &handlerTransport{
+			handler:  handler,
+			incoming: make(chan *mcp.Message, 16),
+			done:     make(chan struct{}),
+		}
+		handler.SetNotifier(func(notification *mcp.Message) { go transport.push(notification) })
+		return transport, nil
+	}
+}
+
+func TestProxy_ForwardsRemoteTools(t *testing.T) {
+	remote := mcp.NewBaseHandler(mcp.ServerInfo{Name: "remote", Version: "1.0.0"}, mcp.ServerCapabilities{
+		Tools: &mcp.ToolsCapability{ListChanged: true},
+	})
+	remote.RegisterTool(examples.NewCalculatorTool())
+	remote.RegisterTool(NewScriptTool(ScriptManifest{Name: "echo", Command: "cat"}, ""))
+
+	local := mcp.NewBaseHandler(mcp.ServerInfo{Name: "local", Version: "1.0.0"}, mcp.ServerCapabilities{})
+	local.RegisterTool(NewScriptTool(ScriptManifest{Name: "echo", Description: "local", Command: "cat"}, ""))
+	local.HandleMessage(context.Background(), mcp.NewNotification("initialized", nil))
+
+	proxy := NewProxy("remote", dialHandler(remote), local)
+	if err := proxy.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	defer proxy.Close()
+
+	// The remote echo clashes with the local tool and is skipped
+	names := toolNames(t, local)
+	if len(names) != 2 || !names["calculator"] {
+		t.Fatalf("Expected the local echo and the remote calculator, got %v", names)
+	}
+	tools, _ := local.ListTools()
+	for _, tool := range tools {
+		if tool.Name == "echo" && tool.Description != "local" {
+			t.Error("Expected the local echo tool to be kept")
+		}
+	}
+
+	result, err := local.CallTool(context.Background(), &mcp.CallToolParams{
+		Name:      "calculator",
+		Arguments: map[string]interface{}{"operation": "add", "a": 2.0, "b": 3.0},
+	})
+	if err != nil || result.IsError || !strings.Contains(result.Content[0].Text, "5") {
+		t.Fatalf("Expected the remote calculator's result, got %+v, %v", result, err)
+	}
+
+	// Tools removed remotely are unregistered once the server says so
+	remote.UnregisterTool("calculator")
+	deadline := time.Now().Add(2 * time.Second)
+	for toolNames(t, local)["calculator"] {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected calculator to be unregistered after list_changed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -2,6 +2,7 @@ package tools
 
 import (
 	"fmt"
+	"sort"
 	"sync"
 
 	"github.com/chongliujia/mcp-go-template/internal/tools/examples"
@@ -9,24 +10,38 @@ import (
 	"github.com/chongliujia/mcp-go-template/pkg/utils"
 )
 
-// Registry manages tool registration and discovery
+// Registry manages tool registration and discovery. It implements
+// mcp.ToolRegistry, so a BaseHandler can serve its tools directly.
 type Registry struct {
-	tools map[string]mcp.ToolHandler
-	mutex sync.RWMutex
+	tools    map[string]mcp.ToolHandler            // name -> served handler
+	versions map[string]map[string]mcp.ToolHandler // name -> version -> handler
+	pins     map[string]string                     // name -> pinned version
+	mutex    sync.RWMutex
+	onChange func()
+	logger   utils.Logger
 }
 
 // NewRegistry creates a new tool registry
 func NewRegistry() *Registry {
 	return &Registry{
-		tools: make(map[string]mcp.ToolHandler),
+		tools:    make(map[string]mcp.ToolHandler),
+		versions: make(map[string]map[string]mcp.ToolHandler),
+		pins:     make(map[string]string),
+		logger:   utils.DefaultLogger(),
 	}
 }
 
-// Register registers a tool handler
-func (r *Registry) Register(handler mcp.ToolHandler) error {
-	r.mutex.Lock()
-	defer r.mutex.Unlock()
+// SetLogger sets the logger the registry logs to. It must be called before
+// tools are registered.
+func (r *Registry) SetLogger(logger utils.Logger) {
+	r.logger = logger
+}
 
+// Register registers a tool handler, replacing any tool with the same name
+// so reloaded tools can take the place of their previous version. Tools
+// whose definition carries a semantic version are kept side by side with
+// the other versions of that name; see PinVersion.
+func (r *Registry) Register(handler mcp.ToolHandler) error {
 	tool := handler.Definition()
 	if tool == nil {
 		return fmt.Errorf("tool definition cannot be nil")
@@ -34,30 +49,71 @@ func (r *Registry) Register(handler mcp.ToolHandler) error {
 	if tool.Name == "" {
 		return fmt.Errorf("tool name cannot be empty")
 	}
-
-	if _, exists := r.tools[tool.Name]; exists {
-		return fmt.Errorf("tool '%s' is already registered", tool.Name)
+	if tool.Version != "" {
+		if _, ok := parseSemver(tool.Version); !ok {
+			return fmt.Errorf("tool '%s' has an invalid version: %s", tool.Name, tool.Version)
+		}
 	}
 
+	r.mutex.Lock()
+	_, replaced := r.tools[tool.Name]
+	if tool.Version == "" {
+		delete(r.versions, tool.Name)
+	} else {
+		if r.versions[tool.Name] == nil {
+			r.versions[tool.Name] = make(map[string]mcp.ToolHandler)
+		}
+		r.versions[tool.Name][tool.Version] = handler
+		handler = r.servedVersionLocked(tool.Name)
+	}
 	r.tools[tool.Name] = handler
-	utils.Infof("Registered tool: %s", tool.Name)
+	r.mutex.Unlock()
+
+	if replaced {
+		r.logger.Infof("Replaced tool: %s", tool.Name)
+	} else {
+		r.logger.Infof("Registered tool: %s", tool.Name)
+	}
+	r.changed()
 	return nil
 }
 
 // Unregister removes a tool from the registry
 func (r *Registry) Unregister(name string) error {
 	r.mutex.Lock()
-	defer r.mutex.Unlock()
-
 	if _, exists := r.tools[name]; !exists {
+		r.mutex.Unlock()
 		return fmt.Errorf("tool '%s' is not registered", name)
 	}
-
 	delete(r.tools, name)
-	utils.Infof("Unregistered tool: %s", name)
+	delete(r.versions, name)
+	r.mutex.Unlock()
+
+	r.logger.Infof("Unregistered tool: %s", name)
+	r.changed()
 	return nil
 }
 
+// OnChange sets a function called after a tool is registered or
+// unregistered, for example to send notifications/tools/list_changed. A
+// BaseHandler serving the registry sets it itself.
+func (r *Registry) OnChange(fn func()) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.onChange = fn
+}
+
+// changed runs the change callback; the caller must not hold the lock
+func (r *Registry) changed() {
+	r.mutex.RLock()
+	onChange := r.onChange
+	r.mutex.RUnlock()
+
+	if onChange != nil {
+		onChange()
+	}
+}
+
 // Get retrieves a tool handler by name
 func (r *Registry) Get(name string) (mcp.ToolHandler, error) {
 	r.mutex.RLock()
@@ -113,7 +169,12 @@ func (r *Registry) RegisterDefaultTools() error {
 		return fmt.Errorf("failed to register knowledge graph tool: %w", err)
 	}
 
-	utils.Infof("Successfully registered %d default tools", r.Count())
+	// Register spreadsheet tool
+	if err := r.Register(examples.NewSpreadsheetTool()); err != nil {
+		return fmt.Errorf("failed to register spreadsheet tool: %w", err)
+	}
+
+	r.logger.Infof("Successfully registered %d default tools", r.Count())
 	return nil
 }
 
@@ -142,8 +203,37 @@ func (r *Registry) HasTool(name string) bool {
 // Clear removes all registered tools
 func (r *Registry) Clear() {
 	r.mutex.Lock()
-	defer r.mutex.Unlock()
-
+	hadTools := len(r.tools) > 0
 	r.tools = make(map[string]mcp.ToolHandler)
-	utils.Info("Cleared all registered tools")
-}
\ No newline at end of file
+	r.versions = make(map[string]map[string]mcp.ToolHandler)
+	r.mutex.Unlock()
+
+	r.logger.Info("Cleared all registered tools")
+	if hadTools {
+		r.changed()
+	}
+}
+
+// ListFiltered returns the registered tools in category that carry every tag
+func (r *Registry) ListFiltered(category string, tags []string) []*mcp.Tool {
+	return mcp.FilterTools(r.List(), category, tags)
+}
+
+// Categories returns the sorted, distinct categories of the registered tools
+func (r *Registry) Categories() []string {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	seen := make(map[string]bool)
+	categories := make([]string, 0)
+	for _, handler := range r.tools {
+		category := handler.Definition().Category
+		if category != "" && !seen[category] {
+			seen[category] = true
+			categories = append(categories, category)
+		}
+	}
+
+	sort.Strings(categories)
+	return categories
+}
@@ -0,0 +1,44 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/chongliujia/mcp-go-template/internal/tools/examples"
+	"github.com/chongliujia/mcp-go-template/pkg/mcp"
+)
+
+func TestRegistry_ServedByBaseHandler(t *testing.T) {
+	registry := NewRegistry()
+	handler := mcp.NewBaseHandlerWithRegistries(mcp.ServerInfo{Name: "test", Version: "1.0.0"}, mcp.ServerCapabilities{
+		Tools: &mcp.ToolsCapability{ListChanged: true},
+	}, mcp.Registries{Tools: registry})
+
+	notifications := 0
+	handler.SetNotifier(func(notification *mcp.Message) { notifications++ })
+
+	if err := handler.RegisterTool(examples.NewCalculatorTool()); err != nil {
+		t.Fatalf("Failed to register tool: %v", err)
+	}
+	if !registry.HasTool("calculator") || registry.Count() != 1 {
+		t.Errorf("Expected the handler to register into the registry, got %v", registry.GetToolNames())
+	}
+
+	// Re-registering replaces the tool, as reloads from a tools directory do
+	if err := registry.Register(examples.NewCalculatorTool()); err != nil {
+		t.Errorf("Expected the tool to be replaced, got %v", err)
+	}
+	if err := handler.UnregisterTool("calculator"); err != nil {
+		t.Errorf("UnregisterTool failed: %v", err)
+	}
+	if registry.Count() != 0 {
+		t.Errorf("Expected the registry to be empty, got %d tools", registry.Count())
+	}
+	if notifications != 3 {
+		t.Errorf("Expected 3 list_changed notifications, got %d", notifications)
+	}
+
+	err := handler.UnregisterTool("calculator")
+	if info, ok := mcp.AsErrorInfo(err); !ok || info.Code != mcp.ToolNotFound {
+		t.Errorf("Expected a tool not found error, got %v", err)
+	}
+}
@@ -0,0 +1,234 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/chongliujia/mcp-go-template/pkg/mcp"
+)
+
+// defaultScriptTimeout bounds a script tool run when its manifest sets no timeout
+const defaultScriptTimeout = 30 * time.Second
+
+// defaultScriptOutputBytes bounds what is kept of a script's stdout and
+// stderr when its manifest sets no limit, matching the default
+// server.max_message_bytes
+const defaultScriptOutputBytes = 4 << 20
+
+// scriptWaitDelay is how long a cancelled script's output pipes may stay
+// open, for example held by a process it started, before they are closed
+const scriptWaitDelay = 5 * time.Second
+
+// scriptEnv lists the variables of the server's environment passed on to
+// scripts; everything else, such as credentials, stays with the server
+var scriptEnv = []string{"PATH", "HOME", "TMPDIR", "LANG", "LC_ALL", "TZ", "SYSTEMROOT"}
+
+// ScriptManifest describes a tool backed by an external command. Manifests
+// are JSON or YAML files placed in the tools directory.
+type ScriptManifest struct {
+	Name        string            `json:"name" yaml:"name"`
+	Version     string            `json:"version" yaml:"version"`
+	Description string            `json:"description" yaml:"description"`
+	Category    string            `json:"category" yaml:"category"`
+	Tags        []string          `json:"tags" yaml:"tags"`
+	InputSchema mcp.ToolSchema    `json:"inputSchema" yaml:"inputSchema"`
+	Command     string            `json:"command" yaml:"command"`
+	Args        []string          `json:"args" yaml:"args"`
+	Env         map[string]string `json:"env" yaml:"env"`
+	Timeout     int               `json:"timeout" yaml:"timeout"` // Seconds
+	// MaxOutputBytes caps the script's stdout; larger output fails the call
+	MaxOutputBytes int64 `json:"max_output_bytes" yaml:"max_output_bytes"`
+	// Deprecated marks the tool deprecated, with its message and replacement
+	Deprecated *mcp.ToolDeprecation `json:"deprecated" yaml:"deprecated"`
+}
+
+// ScriptTool runs an external command for each call. The call arguments are
+// written to the command's stdin as JSON. Its stdout is returned as text,
+// or used as the result directly when it is a JSON CallToolResult. The
+// command sees only a minimal environment plus the manifest's env, and a
+// timeout kills it along with the processes it started.
+type ScriptTool struct {
+	manifest ScriptManifest
+	dir      string
+}
+
+// LoadScriptManifest reads and validates a manifest file
+func LoadScriptManifest(path string) (*ScriptManifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+	return parseScriptManifest(path, data)
+}
+
+// parseScriptManifest decodes a manifest according to its file extension
+func parseScriptManifest(path string, data []byte) (*ScriptManifest, error) {
+	var err error
+	var manifest ScriptManifest
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		err = json.Unmarshal(data, &manifest)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &manifest)
+	default:
+		return nil, fmt.Errorf("unsupported manifest format: %s", filepath.Ext(path))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse manifest %s: %w", path, err)
+	}
+
+	if manifest.Name == "" {
+		return nil, fmt.Errorf("manifest %s: tool name cannot be empty", path)
+	}
+	if manifest.Command == "" {
+		return nil, fmt.Errorf("manifest %s: command cannot be empty", path)
+	}
+	if manifest.InputSchema.Type == "" {
+		manifest.InputSchema.Type = "object"
+	}
+
+	return &manifest, nil
+}
+
+// NewScriptTool creates a tool from a manifest. Relative command paths are
+// resolved against dir, the directory holding the manifest.
+func NewScriptTool(manifest ScriptManifest, dir string) *ScriptTool {
+	return &ScriptTool{
+		manifest: manifest,
+		dir:      dir,
+	}
+}
+
+// Definition returns the tool definition
+func (s *ScriptTool) Definition() *mcp.Tool {
+	return &mcp.Tool{
+		Name:        s.manifest.Name,
+		Description: s.manifest.Description,
+		InputSchema: s.manifest.InputSchema,
+		Category:    s.manifest.Category,
+		Tags:        s.manifest.Tags,
+		Version:     s.manifest.Version,
+		Deprecated:  s.manifest.Deprecated,
+	}
+}
+
+// Execute runs the command with the call arguments on stdin
+func (s *ScriptTool) Execute(ctx context.Context, params map[string]interface{}) (*mcp.CallToolResult, error) {
+	input, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode arguments: %w", err)
+	}
+
+	timeout := defaultScriptTimeout
+	if s.manifest.Timeout > 0 {
+		timeout = time.Duration(s.manifest.Timeout) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	command := s.manifest.Command
+	if strings.ContainsRune(command, filepath.Separator) && !filepath.IsAbs(command) {
+		command = filepath.Join(s.dir, command)
+	}
+
+	cmd := exec.CommandContext(ctx, command, s.manifest.Args...)
+	cmd.Dir = s.dir
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Env = scriptEnvironment(s.manifest)
+	cmd.WaitDelay = scriptWaitDelay
+	setProcessGroup(cmd)
+
+	limit := s.manifest.MaxOutputBytes
+	if limit <= 0 {
+		limit = defaultScriptOutputBytes
+	}
+	stdout := &cappedBuffer{limit: limit}
+	stderr := &cappedBuffer{limit: limit}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	err = cmd.Run()
+	if err == nil && stdout.truncated {
+		err = fmt.Errorf("output is larger than %d bytes", limit)
+		stderr.buf.Reset()
+	}
+	if err != nil {
+		message := strings.TrimSpace(stderr.String())
+		if message == "" {
+			message = err.Error()
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{{
+				Type: "text",
+				Text: fmt.Sprintf("Error: %s", message),
+			}},
+			IsError: true,
+		}, nil
+	}
+
+	var result mcp.CallToolResult
+	if err := json.Unmarshal(stdout.Bytes(), &result); err == nil && len(result.Content) > 0 {
+		return &result, nil
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{{
+			Type: "text",
+			Text: strings.TrimRight(stdout.String(), "\n"),
+		}},
+	}, nil
+}
+
+// scriptEnvironment returns the environment a script runs with: the
+// variables in scriptEnv that are set, the tool's name and the manifest's env
+func scriptEnvironment(manifest ScriptManifest) []string {
+	env := make([]string, 0, len(scriptEnv)+len(manifest.Env)+1)
+	for _, name := range scriptEnv {
+		if value, exists := os.LookupEnv(name); exists {
+			env = append(env, name+"="+value)
+		}
+	}
+	env = append(env, "MCP_TOOL_NAME="+manifest.Name)
+	for key, value := range manifest.Env {
+		env = append(env, key+"="+value)
+	}
+	return env
+}
+
+// cappedBuffer keeps the first limit bytes written to it and discards the
+// rest, so a script cannot fill the server's memory. The buffer is not
+// embedded, so io.Copy cannot bypass Write through its ReadFrom.
+type cappedBuffer struct {
+	buf       bytes.Buffer
+	limit     int64
+	truncated bool
+}
+
+// Write keeps what fits and reports the whole of p as written, so the
+// script is not stopped by a write error
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	room := b.limit - int64(b.buf.Len())
+	if int64(len(p)) <= room {
+		return b.buf.Write(p)
+	}
+	b.truncated = true
+	if room > 0 {
+		b.buf.Write(p[:room])
+	}
+	return len(p), nil
+}
+
+// Bytes returns the kept output
+func (b *cappedBuffer) Bytes() []byte { return b.buf.Bytes() }
+
+// String returns the kept output as a string
+func (b *cappedBuffer) String() string { return b.buf.String() }
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package tools
+
+import "os/exec"
+
+// setProcessGroup leaves cmd as it is, since process groups are not
+// available on this platform; cancelling it kills the command alone
+func setProcessGroup(cmd *exec.Cmd) {}
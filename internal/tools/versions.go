@@ -0,0 +1,109 @@
+package tools
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/chongliujia/mcp-go-template/pkg/mcp"
+)
+
+// PinVersion selects the version of a tool that is served. Without a pin
+// the newest version is served. Clients are told the tool list changed.
+func (r *Registry) PinVersion(name, version string) error {
+	r.mutex.Lock()
+	handler, exists := r.versions[name][version]
+	if !exists {
+		r.mutex.Unlock()
+		return fmt.Errorf("tool '%s' has no version '%s'", name, version)
+	}
+	r.pins[name] = version
+	r.tools[name] = handler
+	r.mutex.Unlock()
+
+	r.changed()
+	return nil
+}
+
+// Versions returns the registered versions of a tool, oldest first
+func (r *Registry) Versions(name string) []string {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	versions := make([]string, 0, len(r.versions[name]))
+	for version := range r.versions[name] {
+		versions = append(versions, version)
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return compareSemver(versions[i], versions[j]) < 0
+	})
+	return versions
+}
+
+// servedVersionLocked returns the pinned version of a tool, or its newest
+// one; the caller holds the lock
+func (r *Registry) servedVersionLocked(name string) mcp.ToolHandler {
+	versions := r.versions[name]
+	if handler, pinned := versions[r.pins[name]]; pinned {
+		return handler
+	}
+
+	newest := ""
+	for version := range versions {
+		if newest == "" || compareSemver(version, newest) > 0 {
+			newest = version
+		}
+	}
+	return versions[newest]
+}
+
+// semver is a parsed semantic version
+type semver struct {
+	numbers    [3]int
+	prerelease string
+}
+
+// parseSemver parses MAJOR.MINOR.PATCH with an optional "v" prefix,
+// pre-release and build metadata, as in "v1.2.0-beta+42"
+func parseSemver(version string) (semver, bool) {
+	version = strings.TrimPrefix(version, "v")
+	version, _, _ = strings.Cut(version, "+")
+	version, prerelease, _ := strings.Cut(version, "-")
+
+	parts := strings.Split(version, ".")
+	if len(parts) != 3 {
+		return semver{}, false
+	}
+	parsed := semver{prerelease: prerelease}
+	for i, part := range parts {
+		number, err := strconv.Atoi(part)
+		if err != nil || number < 0 {
+			return semver{}, false
+		}
+		parsed.numbers[i] = number
+	}
+	return parsed, true
+}
+
+// compareSemver orders semantic versions; pre-releases come before the
+// release they precede
+func compareSemver(a, b string) int {
+	va, _ := parseSemver(a)
+	vb, _ := parseSemver(b)
+	for i := range va.numbers {
+		if va.numbers[i] != vb.numbers[i] {
+			return va.numbers[i] - vb.numbers[i]
+		}
+	}
+
+	switch {
+	case va.prerelease == vb.prerelease:
+		return 0
+	case va.prerelease == "":
+		return 1
+	case vb.prerelease == "":
+		return -1
+	}
+	return strings.Compare(va.prerelease, vb.prerelease)
+}
@@ -0,0 +1,78 @@
+package tools
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/chongliujia/mcp-go-template/pkg/mcp"
+)
+
+func versionedTool(version string) *ScriptTool {
+	return NewScriptTool(ScriptManifest{Name: "convert", Version: version, Command: "cat"}, "")
+}
+
+func TestRegistry_ToolVersions(t *testing.T) {
+	registry := NewRegistry()
+	handler := mcp.NewBaseHandlerWithRegistries(mcp.ServerInfo{Name: "test", Version: "1.0.0"}, mcp.ServerCapabilities{
+		Tools: &mcp.ToolsCapability{ListChanged: true},
+	}, mcp.Registries{Tools: registry})
+
+	notifications := 0
+	handler.SetNotifier(func(notification *mcp.Message) { notifications++ })
+
+	for _, version := range []string{"1.10.0", "1.2.0", "2.0.0-beta"} {
+		if err := registry.Register(versionedTool(version)); err != nil {
+			t.Fatalf("Register(%s) failed: %v", version, err)
+		}
+	}
+	if got := strings.Join(registry.Versions("convert"), ","); got != "1.2.0,1.10.0,2.0.0-beta" {
+		t.Errorf("Versions() = %s, want 1.2.0,1.10.0,2.0.0-beta", got)
+	}
+
+	// Versions share one name and the newest is served
+	listed, _ := handler.ListTools()
+	if len(listed) != 1 || listed[0].Version != "2.0.0-beta" {
+		t.Fatalf("Expected the newest version to be served, got %+v", listed)
+	}
+
+	if err := registry.PinVersion("convert", "1.10.0"); err != nil {
+		t.Fatalf("PinVersion failed: %v", err)
+	}
+	if listed, _ := handler.ListTools(); listed[0].Version != "1.10.0" {
+		t.Errorf("Expected the pinned version, got %s", listed[0].Version)
+	}
+
+	// Newer versions registered later do not override the pin
+	registry.Register(versionedTool("3.0.0"))
+	if listed, _ := handler.ListTools(); listed[0].Version != "1.10.0" {
+		t.Errorf("Expected the pin to hold, got %s", listed[0].Version)
+	}
+	if notifications != 5 {
+		t.Errorf("Expected 5 list_changed notifications, got %d", notifications)
+	}
+
+	if err := registry.PinVersion("convert", "9.9.9"); err == nil {
+		t.Error("Expected pinning an unknown version to fail")
+	}
+	if err := registry.Register(versionedTool("latest")); err == nil {
+		t.Error("Expected an invalid version to be rejected")
+	}
+}
+
+func TestCompareSemver(t *testing.T) {
+	tests := []struct {
+		a, b string
+		less bool
+	}{
+		{"1.2.0", "1.10.0", true},
+		{"v1.0.0", "1.0.1", true},
+		{"2.0.0-alpha", "2.0.0", true},
+		{"2.0.0-alpha", "2.0.0-beta", true},
+		{"1.0.0+build", "1.0.0", false},
+	}
+	for _, tt := range tests {
+		if got := compareSemver(tt.a, tt.b) < 0; got != tt.less {
+			t.Errorf("compareSemver(%s, %s) < 0 = %v, want %v", tt.a, tt.b, got, tt.less)
+		}
+	}
+}
@@ -0,0 +1,420 @@
+// Package client is an MCP client for servers built from this template and
+// any other server speaking the protocol. It reconnects and re-initializes
+// after the connection drops, resending idempotent requests that were cut
+// off, so long-lived agents survive server restarts.
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/chongliujia/mcp-go-template/pkg/mcp"
+)
+
+var (
+	// ErrClosed is returned by requests on a closed client
+	ErrClosed = errors.New("client is closed")
+	// ErrDisconnected is returned by requests whose connection dropped
+	// before the response arrived
+	ErrDisconnected = errors.New("connection to server lost")
+	// ErrReconnectFailed is returned once reconnection gave up
+	ErrReconnectFailed = errors.New("reconnection to server failed")
+)
+
+// RequestHandler answers a request the server sends to the client, such as
+// sampling/createMessage, returning its result
+type RequestHandler func(ctx context.Context, request *mcp.Message) (interface{}, error)
+
+// Options configures a client
+type Options struct {
+	ClientInfo   mcp.ClientInfo
+	Capabilities mcp.ClientCapabilities
+	// ProtocolVersion is requested in initialize
+	ProtocolVersion string
+	Reconnect       ReconnectPolicy
+	Retry           RetryPolicy
+	// OnNotification receives the server's notifications
+	OnNotification func(notification *mcp.Message)
+	// OnRequest answers server requests other than ping; without it they
+	// are refused
+	OnRequest RequestHandler
+	Logger    logrus.FieldLogger
+}
+
+// DefaultOptions returns options that reconnect with exponential backoff
+// and resend interrupted idempotent requests once
+func DefaultOptions() Options {
+	return Options{
+		ClientInfo:      mcp.ClientInfo{Name: "mcp-go-template-client", Version: "1.0.0"},
+		ProtocolVersion: mcp.MCPVersion,
+		Reconnect: ReconnectPolicy{
+			Enabled:      true,
+			InitialDelay: 500 * time.Millisecond,
+			MaxDelay:     30 * time.Second,
+			Multiplier:   2,
+			Jitter:       0.2,
+		},
+		Retry:  RetryPolicy{MaxAttempts: 1},
+		Logger: logrus.StandardLogger(),
+	}
+}
+
+// Client is a connection to an MCP server that survives reconnections
+type Client struct {
+	dial Dialer
+	opts Options
+
+	mu sync.Mutex
+	// transport is the current connection, nil while disconnected
+	transport Transport
+	// ready is set once the current connection is initialized
+	ready bool
+	// connected is closed when the client becomes ready or gives up, and
+	// replaced when it disconnects
+	connected chan struct{}
+	// err is why the client cannot send requests, once it gave up
+	err     error
+	server  *mcp.InitializeResult
+	pending map[string]chan *mcp.Message
+	nextID  uint64
+	closed  bool
+	done    chan struct{}
+}
+
+// New returns a client that connects through dial; call Connect to open
+// the first connection
+func New(dial Dialer, opts Options) *Client {
+	if opts.Logger == nil {
+		opts.Logger = logrus.StandardLogger()
+	}
+	if opts.ProtocolVersion == "" {
+		opts.ProtocolVersion = mcp.MCPVersion
+	}
+	return &Client{
+		dial:      dial,
+		opts:      opts,
+		connected: make(chan struct{}),
+		pending:   make(map[string]chan *mcp.Message),
+		done:      make(chan struct{}),
+	}
+}
+
+// Connect opens the first connection and initializes it. Later
+// disconnections are handled according to the reconnect policy.
+func (c *Client) Connect(ctx context.Context) error {
+	return c.connect(ctx)
+}
+
+// Server returns the server's answer to the latest initialize, or nil
+// before the client connected
+func (c *Client) Server() *mcp.InitializeResult {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.server
+}
+
+// Close disconnects from the server and stops reconnecting
+func (c *Client) Close() error {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil
+	}
+	c.closed = true
+	close(c.done)
+	transport := c.transport
+	c.mu.Unlock()
+
+	c.fail(ErrClosed)
+	if transport != nil {
+		return transport.Close()
+	}
+	return nil
+}
+
+// connect dials, starts reading, and initializes the new connection
+func (c *Client) connect(ctx context.Context) error {
+	transport, err := c.dial(ctx)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	if c.closed || c.transport != nil {
+		closed := c.closed
+		c.mu.Unlock()
+		transport.Close()
+		if closed {
+			return ErrClosed
+		}
+		return fmt.Errorf("client is already connected")
+	}
+	c.transport = transport
+	c.mu.Unlock()
+
+	go c.readLoop(transport)
+
+	response, err := c.request(ctx, transport, "initialize", mcp.InitializeParams{
+		ProtocolVersion: c.opts.ProtocolVersion,
+		Capabilities:    c.opts.Capabilities,
+		ClientInfo:      c.opts.ClientInfo,
+	})
+	if err == nil {
+		var result mcp.InitializeResult
+		if err = response.UnmarshalResult(&result); err == nil {
+			c.mu.Lock()
+			c.server = &result
+			c.mu.Unlock()
+			err = transport.Send(ctx, mcp.NewNotification("initialized", nil))
+		}
+	}
+	if err != nil {
+		c.drop(transport)
+		return fmt.Errorf("failed to initialize: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.transport != transport {
+		return ErrDisconnected
+	}
+	// connected is already closed if the client had given up
+	if c.err == nil {
+		close(c.connected)
+	}
+	c.ready = true
+	c.err = nil
+	return nil
+}
+
+// readLoop routes the messages of one connection until it fails
+func (c *Client) readLoop(transport Transport) {
+	for {
+		message, err := transport.Receive()
+		if err != nil {
+			c.lost(transport, err)
+			return
+		}
+
+		switch {
+		case message.IsResponse():
+			c.mu.Lock()
+			reply, exists := c.pending[requestKey(message.ID)]
+			delete(c.pending, requestKey(message.ID))
+			c.mu.Unlock()
+			if exists {
+				reply <- message
+			}
+		case message.IsRequest():
+			go c.answer(transport, message)
+		case message.IsNotification():
+			if c.opts.OnNotification != nil {
+				c.opts.OnNotification(message)
+			}
+		}
+	}
+}
+
+// answer replies to a request from the server
+func (c *Client) answer(transport Transport, request *mcp.Message) {
+	ctx := context.Background()
+
+	var response *mcp.Message
+	switch {
+	case request.Method == "ping":
+		response = mcp.NewSuccessResponse(request.ID, map[string]interface{}{})
+	case c.opts.OnRequest != nil:
+		result, err := c.opts.OnRequest(ctx, request)
+		if err != nil {
+			response = mcp.NewErrorResponseFromError(request.ID, err, mcp.InternalError, "request failed")
+		} else {
+			response = mcp.NewSuccessResponse(request.ID, result)
+		}
+	default:
+		info := mcp.MethodNotFoundError(request.Method)
+		response = mcp.NewErrorResponse(request.ID, info.Code, info.Message, info.Data)
+	}
+
+	if err := transport.Send(ctx, response); err != nil {
+		c.opts.Logger.WithError(err).Debug("Failed to answer server request")
+	}
+}
+
+// lost handles a failed connection, reconnecting when the policy allows.
+// Connections that fail during initialize are left to whoever is
+// connecting.
+func (c *Client) lost(transport Transport, err error) {
+	if established := c.drop(transport); !established {
+		return
+	}
+
+	c.mu.Lock()
+	closed := c.closed
+	c.mu.Unlock()
+	if closed {
+		return
+	}
+
+	c.opts.Logger.WithError(err).Warn("Lost connection to MCP server")
+	if c.opts.Reconnect.Enabled {
+		go c.reconnect()
+	} else {
+		c.fail(ErrDisconnected)
+	}
+}
+
+// drop forgets a failed connection and fails its pending requests,
+// reporting whether it was the initialized current connection
+func (c *Client) drop(transport Transport) bool {
+	c.mu.Lock()
+	if c.transport != transport {
+		c.mu.Unlock()
+		return false
+	}
+	c.transport = nil
+	established := c.ready
+	if c.ready {
+		c.ready = false
+		c.connected = make(chan struct{})
+	}
+	pending := c.pending
+	c.pending = make(map[string]chan *mcp.Message)
+	c.mu.Unlock()
+
+	transport.Close()
+	for _, reply := range pending {
+		reply <- nil
+	}
+	return established
+}
+
+// fail makes requests waiting for a connection return err
+func (c *Client) fail(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.ready || c.err != nil {
+		return
+	}
+	c.err = err
+	close(c.connected)
+}
+
+// waitConnected returns the current connection once it is initialized
+func (c *Client) waitConnected(ctx context.Context) (Transport, error) {
+	for {
+		c.mu.Lock()
+		if c.closed {
+			c.mu.Unlock()
+			return nil, ErrClosed
+		}
+		if c.ready {
+			transport := c.transport
+			c.mu.Unlock()
+			return transport, nil
+		}
+		if c.err != nil {
+			err := c.err
+			c.mu.Unlock()
+			return nil, err
+		}
+		connected := c.connected
+		c.mu.Unlock()
+
+		select {
+		case <-connected:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// Call sends a request and waits for its response. Requests cut off by a
+// disconnection are resent after reconnecting when the retry policy
+// allows. An error response is returned as an *mcp.ErrorInfo.
+func (c *Client) Call(ctx context.Context, method string, params interface{}) (*mcp.Message, error) {
+	for attempt := 0; ; attempt++ {
+		transport, err := c.waitConnected(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		response, err := c.request(ctx, transport, method, params)
+		if !errors.Is(err, ErrDisconnected) || attempt >= c.opts.Retry.MaxAttempts || !c.opts.Retry.allows(method, params) {
+			return response, err
+		}
+		c.opts.Logger.WithField("method", method).Debug("Resending request after reconnection")
+	}
+}
+
+// request sends a request over transport and waits for its response
+func (c *Client) request(ctx context.Context, transport Transport, method string, params interface{}) (*mcp.Message, error) {
+	reply := make(chan *mcp.Message, 1)
+
+	c.mu.Lock()
+	if c.transport != transport {
+		c.mu.Unlock()
+		return nil, ErrDisconnected
+	}
+	c.nextID++
+	id := c.nextID
+	key := requestKey(id)
+	c.pending[key] = reply
+	c.mu.Unlock()
+
+	forget := func() {
+		c.mu.Lock()
+		delete(c.pending, key)
+		c.mu.Unlock()
+	}
+
+	if err := transport.Send(ctx, mcp.NewRequest(id, method, params)); err != nil {
+		forget()
+		c.lost(transport, err)
+		return nil, fmt.Errorf("%w: %v", ErrDisconnected, err)
+	}
+
+	select {
+	case response := <-reply:
+		if response == nil {
+			return nil, ErrDisconnected
+		}
+		if response.Error != nil {
+			return nil, response.Error
+		}
+		return response, nil
+	case <-ctx.Done():
+		forget()
+		transport.Send(context.Background(), mcp.NewNotification("notifications/cancelled", mcp.CancelledParams{
+			RequestID: id,
+			Reason:    ctx.Err().Error(),
+		}))
+		return nil, ctx.Err()
+	}
+}
+
+// requestKey normalizes request IDs, which come back from JSON as float64,
+// by their JSON encoding, so 1000000 matches whatever type carries it
+func requestKey(id mcp.RequestID) string {
+	key, err := json.Marshal(id)
+	if err != nil {
+		return fmt.Sprint(id)
+	}
+	return string(key)
+}
+
+// toolCallName returns the tool a tools/call request targets
+func toolCallName(params interface{}) string {
+	switch p := params.(type) {
+	case mcp.CallToolParams:
+		return p.Name
+	case *mcp.CallToolParams:
+		return p.Name
+	}
+	return ""
+}
@@ -0,0 +1,273 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/chongliujia/mcp-go-template/pkg/mcp"
+)
+
+// echoTool returns its message argument
+type echoTool struct{}
+
+func (echoTool) Definition() *mcp.Tool {
+	return &mcp.Tool{Name: "echo", InputSchema: mcp.ToolSchema{Type: "object"}}
+}
+
+func (echoTool) Execute(ctx context.Context, params map[string]interface{}) (*mcp.CallToolResult, error) {
+	return &mcp.CallToolResult{Content: []mcp.Content{mcp.NewTextContent(fmt.Sprint(params["message"]))}}, nil
+}
+
+// fakeServer hands out in-memory connections to a handler
+type fakeServer struct {
+	handler *mcp.BaseHandler
+
+	mu sync.Mutex
+	// dials counts connections, including failed ones
+	dials int
+	// failDials makes the next dials fail
+	failDials int
+	// dropOn closes the connection instead of answering this method once
+	dropOn  string
+	current *memoryTransport
+}
+
+func newFakeServer(t *testing.T) *fakeServer {
+	h := mcp.NewBaseHandler(mcp.ServerInfo{Name: "fake", Version: "1.0.0"}, mcp.ServerCapabilities{Tools: &mcp.ToolsCapability{}})
+	if err := h.RegisterTool(echoTool{}); err != nil {
+		t.Fatalf("Failed to register tool: %v", err)
+	}
+	return &fakeServer{handler: h}
+}
+
+func (s *fakeServer) dial(ctx context.Context) (Transport, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.dials++
+	if s.failDials > 0 {
+		s.failDials--
+		return nil, errors.New("connection refused")
+	}
+
+	session := mcp.NewSession(fmt.Sprintf("session-%d", s.dials))
+	s.current = &memoryTransport{
+		server:   s,
+		ctx:      mcp.WithSession(context.Background(), session),
+		incoming: make(chan *mcp.Message, 16),
+		closed:   make(chan struct{}),
+	}
+	return s.current, nil
+}
+
+// restart drops the current connection as a server restart would
+func (s *fakeServer) restart() {
+	s.mu.Lock()
+	current := s.current
+	s.mu.Unlock()
+	current.Close()
+}
+
+// memoryTransport passes messages to the fake server's handler, encoding
+// them as JSON like a real connection
+type memoryTransport struct {
+	server    *fakeServer
+	ctx       context.Context
+	incoming  chan *mcp.Message
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+func (t *memoryTransport) Send(ctx context.Context, message *mcp.Message) error {
+	select {
+	case <-t.closed:
+		return io.ErrClosedPipe
+	default:
+	}
+
+	t.server.mu.Lock()
+	drop := message.Method != "" && message.Method == t.server.dropOn
+	if drop {
+		t.server.dropOn = ""
+	}
+	t.server.mu.Unlock()
+	if drop {
+		t.Close()
+		return nil
+	}
+
+	response, _ := t.server.handler.HandleMessage(t.ctx, roundTrip(message))
+	if response != nil {
+		select {
+		case t.incoming <- roundTrip(response):
+		case <-t.closed:
+		}
+	}
+	return nil
+}
+
+func (t *memoryTransport) Receive() (*mcp.Message, error) {
+	select {
+	case message := <-t.incoming:
+		return message, nil
+	case <-t.closed:
+		return nil, io.EOF
+	}
+}
+
+func (t *memoryTransport) Close() error {
+	t.closeOnce.Do(func() { close(t.closed) })
+	return nil
+}
+
+// roundTrip encodes and decodes a message as it would cross the wire
+func roundTrip(message *mcp.Message) *mcp.Message {
+	data, _ := json.Marshal(message)
+	var decoded mcp.Message
+	json.Unmarshal(data, &decoded)
+	return &decoded
+}
+
+// testOptions reconnects quickly and keeps test output quiet
+func testOptions() Options {
+	opts := DefaultOptions()
+	opts.Reconnect.InitialDelay = time.Millisecond
+	opts.Reconnect.MaxDelay = 5 * time.Millisecond
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	opts.Logger = logger
+	return opts
+}
+
+func TestClient_ListAndCallTools(t *testing.T) {
+	server := newFakeServer(t)
+	c := New(server.dial, testOptions())
+	defer c.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := c.Connect(ctx); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	if c.Server() == nil || c.Server().ServerInfo.Name != "fake" {
+		t.Fatalf("Expected the server's initialize result, got %+v", c.Server())
+	}
+
+	tools, err := c.ListTools(ctx)
+	if err != nil || len(tools) != 1 || tools[0].Name != "echo" {
+		t.Fatalf("Expected the echo tool, got %v, %v", tools, err)
+	}
+
+	result, err := c.CallTool(ctx, "echo", map[string]interface{}{"message": "hello"})
+	if err != nil || result.Content[0].Text != "hello" {
+		t.Fatalf("Expected hello, got %+v, %v", result, err)
+	}
+
+	_, err = c.CallTool(ctx, "missing", nil)
+	var info *mcp.ErrorInfo
+	if !errors.As(err, &info) || info.Code != mcp.ToolNotFound {
+		t.Errorf("Expected a tool not found error, got %v", err)
+	}
+}
+
+func TestClient_ReconnectsAndRetriesIdempotentRequests(t *testing.T) {
+	server := newFakeServer(t)
+	c := New(server.dial, testOptions())
+	defer c.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := c.Connect(ctx); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+
+	// After a restart with two refused dials, requests wait for the new,
+	// re-initialized connection
+	server.mu.Lock()
+	server.failDials = 2
+	server.mu.Unlock()
+	server.restart()
+
+	if err := c.Ping(ctx); err != nil {
+		t.Fatalf("Expected ping to succeed after reconnecting, got %v", err)
+	}
+	server.mu.Lock()
+	dials := server.dials
+	server.mu.Unlock()
+	if dials != 4 {
+		t.Errorf("Expected 4 dials, got %d", dials)
+	}
+
+	// An idempotent request cut off by a disconnection is resent
+	server.mu.Lock()
+	server.dropOn = "tools/list"
+	server.mu.Unlock()
+	if tools, err := c.ListTools(ctx); err != nil || len(tools) != 1 {
+		t.Errorf("Expected tools/list to be retried, got %v, %v", tools, err)
+	}
+
+	// A tool call is not, unless the retry policy names the tool
+	server.mu.Lock()
+	server.dropOn = "tools/call"
+	server.mu.Unlock()
+	if _, err := c.CallTool(ctx, "echo", nil); !errors.Is(err, ErrDisconnected) {
+		t.Errorf("Expected tools/call to fail with ErrDisconnected, got %v", err)
+	}
+}
+
+func TestClient_GivesUpAfterMaxAttempts(t *testing.T) {
+	server := newFakeServer(t)
+	opts := testOptions()
+	opts.Reconnect.MaxAttempts = 2
+	c := New(server.dial, opts)
+	defer c.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := c.Connect(ctx); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+
+	server.mu.Lock()
+	server.failDials = 10
+	server.mu.Unlock()
+	server.restart()
+
+	if err := c.Ping(ctx); !errors.Is(err, ErrReconnectFailed) {
+		t.Errorf("Expected ErrReconnectFailed, got %v", err)
+	}
+}
+
+func TestReconnectPolicy_Delay(t *testing.T) {
+	policy := ReconnectPolicy{InitialDelay: 100 * time.Millisecond, MaxDelay: time.Second, Multiplier: 2}
+
+	expected := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second, time.Second}
+	for attempt, want := range expected {
+		if got := policy.delay(attempt); got != want {
+			t.Errorf("Attempt %d: expected %s, got %s", attempt, want, got)
+		}
+	}
+
+	policy.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		if got := policy.delay(0); got < 50*time.Millisecond || got > 150*time.Millisecond {
+			t.Fatalf("Expected jittered delay within 50%%, got %s", got)
+		}
+	}
+}
+
+func TestRequestKey_MatchesDecodedIDs(t *testing.T) {
+	if requestKey(uint64(1000000)) != requestKey(float64(1e6)) {
+		t.Errorf("Expected a decoded float64 ID to match the uint64 sent, got %s and %s", requestKey(uint64(1000000)), requestKey(float64(1e6)))
+	}
+	if requestKey("1") == requestKey(float64(1)) {
+		t.Error("Expected the string and number IDs to stay apart")
+	}
+}
@@ -0,0 +1,116 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/chongliujia/mcp-go-template/pkg/mcp"
+)
+
+// Ping checks that the server is responsive
+func (c *Client) Ping(ctx context.Context) error {
+	_, err := c.Call(ctx, "ping", nil)
+	return err
+}
+
+// ListTools returns every tool, following nextCursor across pages
+func (c *Client) ListTools(ctx context.Context) ([]*mcp.Tool, error) {
+	return listAll[*mcp.Tool](ctx, c, "tools/list", "tools")
+}
+
+// CallTool calls a tool with the given arguments
+func (c *Client) CallTool(ctx context.Context, name string, arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	response, err := c.Call(ctx, "tools/call", mcp.CallToolParams{Name: name, Arguments: arguments})
+	if err != nil {
+		return nil, err
+	}
+
+	var result mcp.CallToolResult
+	if err := response.UnmarshalResult(&result); err != nil {
+		return nil, fmt.Errorf("invalid tools/call result: %w", err)
+	}
+	return &result, nil
+}
+
+// ListResources returns every resource, following nextCursor across pages
+func (c *Client) ListResources(ctx context.Context) ([]*mcp.Resource, error) {
+	return listAll[*mcp.Resource](ctx, c, "resources/list", "resources")
+}
+
+// ListResourceTemplates returns every resource template
+func (c *Client) ListResourceTemplates(ctx context.Context) ([]*mcp.ResourceTemplate, error) {
+	return listAll[*mcp.ResourceTemplate](ctx, c, "resources/templates/list", "resourceTemplates")
+}
+
+// ReadResource reads the resource at uri
+func (c *Client) ReadResource(ctx context.Context, uri string) (*mcp.ReadResourceResult, error) {
+	response, err := c.Call(ctx, "resources/read", mcp.ReadResourceParams{URI: uri})
+	if err != nil {
+		return nil, err
+	}
+
+	var result mcp.ReadResourceResult
+	if err := response.UnmarshalResult(&result); err != nil {
+		return nil, fmt.Errorf("invalid resources/read result: %w", err)
+	}
+	return &result, nil
+}
+
+// ListPrompts returns every prompt, following nextCursor across pages
+func (c *Client) ListPrompts(ctx context.Context) ([]*mcp.Prompt, error) {
+	return listAll[*mcp.Prompt](ctx, c, "prompts/list", "prompts")
+}
+
+// GetPrompt renders a prompt with the given arguments
+func (c *Client) GetPrompt(ctx context.Context, name string, arguments map[string]interface{}) (*mcp.GetPromptResult, error) {
+	response, err := c.Call(ctx, "prompts/get", mcp.GetPromptParams{Name: name, Arguments: arguments})
+	if err != nil {
+		return nil, err
+	}
+
+	var result mcp.GetPromptResult
+	if err := response.UnmarshalResult(&result); err != nil {
+		return nil, fmt.Errorf("invalid prompts/get result: %w", err)
+	}
+	return &result, nil
+}
+
+// listAll requests every page of a list method and returns the items held
+// under key
+func listAll[T any](ctx context.Context, c *Client, method, key string) ([]T, error) {
+	var items []T
+	cursor := ""
+	for {
+		var params interface{}
+		if cursor != "" {
+			params = mcp.PaginatedParams{Cursor: cursor}
+		}
+
+		response, err := c.Call(ctx, method, params)
+		if err != nil {
+			return nil, err
+		}
+
+		var page map[string]json.RawMessage
+		if err := response.UnmarshalResult(&page); err != nil {
+			return nil, fmt.Errorf("invalid %s result: %w", method, err)
+		}
+		var pageItems []T
+		if raw, exists := page[key]; exists {
+			if err := json.Unmarshal(raw, &pageItems); err != nil {
+				return nil, fmt.Errorf("invalid %s result: %w", method, err)
+			}
+		}
+		items = append(items, pageItems...)
+
+		var next string
+		if raw, exists := page["nextCursor"]; exists {
+			json.Unmarshal(raw, &next)
+		}
+		if next == "" {
+			return items, nil
+		}
+		cursor = next
+	}
+}
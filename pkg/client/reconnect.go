@@ -0,0 +1,134 @@
+package client
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// connectTimeout bounds each reconnection attempt, including initialize
+const connectTimeout = 30 * time.Second
+
+// ReconnectPolicy controls how the client reconnects after losing its
+// connection
+type ReconnectPolicy struct {
+	// Enabled turns on automatic reconnection
+	Enabled bool
+	// InitialDelay is the wait before the first reconnection attempt
+	InitialDelay time.Duration
+	// MaxDelay caps the wait between attempts
+	MaxDelay time.Duration
+	// Multiplier grows the wait after each failed attempt
+	Multiplier float64
+	// Jitter randomizes each wait by up to this fraction, so clients of a
+	// restarted server do not reconnect in lockstep
+	Jitter float64
+	// MaxAttempts gives up after this many failed attempts; 0 retries forever
+	MaxAttempts int
+}
+
+// delay returns the wait before the given reconnection attempt, starting
+// at 0
+func (p ReconnectPolicy) delay(attempt int) time.Duration {
+	delay := float64(p.InitialDelay) * math.Pow(p.Multiplier, float64(attempt))
+	if p.MaxDelay > 0 && delay > float64(p.MaxDelay) {
+		delay = float64(p.MaxDelay)
+	}
+	if p.Jitter > 0 {
+		delay *= 1 + p.Jitter*(2*rand.Float64()-1)
+	}
+	return time.Duration(delay)
+}
+
+// IdempotentMethods are the requests resent after a reconnection by
+// default: reading them twice has no side effects
+var IdempotentMethods = []string{
+	"ping",
+	"tools/list",
+	"resources/list",
+	"resources/templates/list",
+	"resources/read",
+	"prompts/list",
+	"prompts/get",
+	"completion/complete",
+}
+
+// RetryPolicy controls which requests are resent when the connection drops
+// before their response arrives
+type RetryPolicy struct {
+	// MaxAttempts is how many times a request may be resent; 0 disables
+	// retries
+	MaxAttempts int
+	// Methods are the methods safe to resend; nil means IdempotentMethods
+	Methods []string
+	// Tools are the tools whose tools/call is safe to resend
+	Tools []string
+}
+
+// allows reports whether a request may be resent
+func (p RetryPolicy) allows(method string, params interface{}) bool {
+	if p.MaxAttempts <= 0 {
+		return false
+	}
+
+	if method == "tools/call" {
+		name := toolCallName(params)
+		for _, tool := range p.Tools {
+			if tool == name {
+				return true
+			}
+		}
+		return false
+	}
+
+	methods := p.Methods
+	if methods == nil {
+		methods = IdempotentMethods
+	}
+	for _, candidate := range methods {
+		if candidate == method {
+			return true
+		}
+	}
+	return false
+}
+
+// reconnect dials until a connection is initialized, the policy gives up,
+// or the client is closed
+func (c *Client) reconnect() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-c.done:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	policy := c.opts.Reconnect
+	for attempt := 0; policy.MaxAttempts == 0 || attempt < policy.MaxAttempts; attempt++ {
+		delay := policy.delay(attempt)
+		c.opts.Logger.WithField("attempt", attempt+1).Infof("Reconnecting to MCP server in %s", delay)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		attemptCtx, cancelAttempt := context.WithTimeout(ctx, connectTimeout)
+		err := c.connect(attemptCtx)
+		cancelAttempt()
+		if err == nil {
+			c.opts.Logger.Info("Reconnected to MCP server")
+			return
+		}
+		c.opts.Logger.WithError(err).Warn("Reconnection attempt failed")
+	}
+
+	c.fail(ErrReconnectFailed)
+}
@@ -0,0 +1,100 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+
+	"github.com/chongliujia/mcp-go-template/pkg/mcp"
+)
+
+// maxStdioMessage bounds a single newline-delimited message read from a
+// subprocess
+const maxStdioMessage = 16 << 20
+
+// Stdio returns a dialer that starts the server as a subprocess and
+// exchanges newline-delimited JSON-RPC messages over its stdin and stdout.
+// The server's stderr is passed through. Every reconnection starts a new
+// process.
+func Stdio(name string, args ...string) Dialer {
+	return func(ctx context.Context) (Transport, error) {
+		cmd := exec.Command(name, args...)
+		cmd.Stderr = os.Stderr
+
+		stdin, err := cmd.StdinPipe()
+		if err != nil {
+			return nil, err
+		}
+		stdout, err := cmd.StdoutPipe()
+		if err != nil {
+			return nil, err
+		}
+		if err := cmd.Start(); err != nil {
+			return nil, fmt.Errorf("failed to start %s: %w", name, err)
+		}
+
+		scanner := bufio.NewScanner(stdout)
+		scanner.Buffer(make([]byte, 64*1024), maxStdioMessage)
+		return &stdioTransport{cmd: cmd, stdin: stdin, scanner: scanner}, nil
+	}
+}
+
+// stdioTransport is a Transport over a subprocess's standard streams
+type stdioTransport struct {
+	cmd     *exec.Cmd
+	stdin   io.WriteCloser
+	scanner *bufio.Scanner
+	// writeMu keeps concurrently sent messages on separate lines
+	writeMu   sync.Mutex
+	closeOnce sync.Once
+}
+
+// Send implements Transport
+func (t *stdioTransport) Send(ctx context.Context, message *mcp.Message) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to encode message: %w", err)
+	}
+
+	t.writeMu.Lock()
+	defer t.writeMu.Unlock()
+	_, err = t.stdin.Write(append(data, '\n'))
+	return err
+}
+
+// Receive implements Transport
+func (t *stdioTransport) Receive() (*mcp.Message, error) {
+	for t.scanner.Scan() {
+		line := t.scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		var message mcp.Message
+		if err := json.Unmarshal(line, &message); err != nil {
+			return nil, fmt.Errorf("invalid message from server: %w", err)
+		}
+		return &message, nil
+	}
+	if err := t.scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+// Close implements Transport, stopping the subprocess
+func (t *stdioTransport) Close() error {
+	t.closeOnce.Do(func() {
+		t.stdin.Close()
+		if t.cmd.Process != nil {
+			t.cmd.Process.Kill()
+		}
+		t.cmd.Wait()
+	})
+	return nil
+}
@@ -0,0 +1,58 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/chongliujia/mcp-go-template/pkg/mcp"
+)
+
+// TestHelperProcess is not a real test: it runs as the stdio server
+// subprocess, answering requests with handler
+func TestHelperProcess(t *testing.T) {
+	if os.Getenv("MCP_CLIENT_HELPER_PROCESS") != "1" {
+		return
+	}
+
+	handler := mcp.NewBaseHandler(mcp.ServerInfo{Name: "stdio", Version: "1.0.0"}, mcp.ServerCapabilities{Tools: &mcp.ToolsCapability{}})
+	handler.RegisterTool(echoTool{})
+	ctx := mcp.WithSession(context.Background(), mcp.NewSession("stdio"))
+
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		var message mcp.Message
+		if err := json.Unmarshal(scanner.Bytes(), &message); err != nil {
+			continue
+		}
+		if response, _ := handler.HandleMessage(ctx, &message); response != nil {
+			data, _ := json.Marshal(response)
+			fmt.Println(string(data))
+		}
+	}
+	os.Exit(0)
+}
+
+func TestStdio(t *testing.T) {
+	t.Setenv("MCP_CLIENT_HELPER_PROCESS", "1")
+	c := New(Stdio(os.Args[0], "-test.run=TestHelperProcess"), testOptions())
+	defer c.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := c.Connect(ctx); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	if c.Server().ServerInfo.Name != "stdio" {
+		t.Errorf("Expected the stdio server, got %+v", c.Server().ServerInfo)
+	}
+
+	result, err := c.CallTool(ctx, "echo", map[string]interface{}{"message": "over stdio"})
+	if err != nil || result.Content[0].Text != "over stdio" {
+		t.Errorf("Expected the echoed message, got %+v, %v", result, err)
+	}
+}
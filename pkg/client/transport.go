@@ -0,0 +1,90 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/chongliujia/mcp-go-template/pkg/mcp"
+)
+
+// Transport carries JSON-RPC messages between the client and one server
+// connection
+type Transport interface {
+	// Send writes a message to the server
+	Send(ctx context.Context, message *mcp.Message) error
+	// Receive blocks until the server sends a message or the connection
+	// fails
+	Receive() (*mcp.Message, error)
+	// Close ends the connection, unblocking Receive
+	Close() error
+}
+
+// Dialer opens a new connection to the server. The client calls it again
+// for every reconnection.
+type Dialer func(ctx context.Context) (Transport, error)
+
+// WebSocket returns a dialer for a server's WebSocket endpoint, such as
+// ws://localhost:8080/mcp, sending header with the handshake
+func WebSocket(url string, header http.Header) Dialer {
+	return func(ctx context.Context) (Transport, error) {
+		conn, _, err := websocket.DefaultDialer.DialContext(ctx, url, header)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to %s: %w", url, err)
+		}
+		return &wsTransport{conn: conn}, nil
+	}
+}
+
+// wsTransport is a Transport over a WebSocket connection
+type wsTransport struct {
+	conn *websocket.Conn
+	// writeMu serializes writes, which the connection does not allow
+	// concurrently
+	writeMu sync.Mutex
+}
+
+// Send implements Transport
+func (t *wsTransport) Send(ctx context.Context, message *mcp.Message) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to encode message: %w", err)
+	}
+
+	t.writeMu.Lock()
+	defer t.writeMu.Unlock()
+	if deadline, ok := ctx.Deadline(); ok {
+		t.conn.SetWriteDeadline(deadline)
+		defer t.conn.SetWriteDeadline(time.Time{})
+	}
+	return t.conn.WriteMessage(websocket.TextMessage, data)
+}
+
+// Receive implements Transport
+func (t *wsTransport) Receive() (*mcp.Message, error) {
+	for {
+		messageType, data, err := t.conn.ReadMessage()
+		if err != nil {
+			return nil, err
+		}
+		if messageType != websocket.TextMessage {
+			continue
+		}
+
+		var message mcp.Message
+		if err := json.Unmarshal(data, &message); err != nil {
+			return nil, fmt.Errorf("invalid message from server: %w", err)
+		}
+		return &message, nil
+	}
+}
+
+// Close implements Transport
+func (t *wsTransport) Close() error {
+	return t.conn.Close()
+}
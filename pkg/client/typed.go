@@ -0,0 +1,110 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/chongliujia/mcp-go-template/pkg/mcp"
+)
+
+// ErrNoStructuredContent is returned by CallToolTyped when the tool result
+// carries no JSON payload to decode
+var ErrNoStructuredContent = errors.New("tool result has no structured content")
+
+// ToolError is returned by CallToolTyped when the tool reports an error
+// result
+type ToolError struct {
+	Tool   string
+	Result *mcp.CallToolResult
+}
+
+// Error returns the text the tool reported
+func (e *ToolError) Error() string {
+	var texts []string
+	for _, content := range e.Result.Content {
+		if content.Type == mcp.ContentTypeText && content.Text != "" {
+			texts = append(texts, content.Text)
+		}
+	}
+	if len(texts) == 0 {
+		return fmt.Sprintf("tool %s failed", e.Tool)
+	}
+	return fmt.Sprintf("tool %s failed: %s", e.Tool, strings.Join(texts, "; "))
+}
+
+// CallToolTyped calls a tool with in encoded as its arguments and decodes
+// the structured content of the result into TOut. in must encode to a JSON
+// object, such as a struct with json tags or a map.
+func CallToolTyped[TIn, TOut any](ctx context.Context, c *Client, name string, in TIn) (TOut, error) {
+	var out TOut
+
+	arguments, err := toArguments(in)
+	if err != nil {
+		return out, fmt.Errorf("invalid arguments for tool %s: %w", name, err)
+	}
+
+	result, err := c.CallTool(ctx, name, arguments)
+	if err != nil {
+		return out, err
+	}
+	if result.IsError {
+		return out, &ToolError{Tool: name, Result: result}
+	}
+
+	if err := DecodeStructured(result, &out); err != nil {
+		return out, fmt.Errorf("invalid result from tool %s: %w", name, err)
+	}
+	return out, nil
+}
+
+// DecodeStructured decodes the structured content of a tool result into
+// out. Results from servers that downgraded the payload to a JSON text
+// block for older protocol revisions are decoded from that block.
+func DecodeStructured(result *mcp.CallToolResult, out interface{}) error {
+	var data []byte
+	if result.StructuredContent != nil {
+		encoded, err := json.Marshal(result.StructuredContent)
+		if err != nil {
+			return err
+		}
+		data = encoded
+	} else {
+		for i := len(result.Content) - 1; i >= 0; i-- {
+			content := result.Content[i]
+			if content.Type == mcp.ContentTypeText && content.MimeType == "application/json" {
+				data = []byte(content.Text)
+				break
+			}
+		}
+	}
+	if data == nil {
+		return ErrNoStructuredContent
+	}
+	return json.Unmarshal(data, out)
+}
+
+// toArguments encodes in as a tools/call arguments object
+func toArguments(in interface{}) (map[string]interface{}, error) {
+	if in == nil {
+		return nil, nil
+	}
+	if arguments, ok := in.(map[string]interface{}); ok {
+		return arguments, nil
+	}
+
+	data, err := json.Marshal(in)
+	if err != nil {
+		return nil, err
+	}
+	if string(data) == "null" {
+		return nil, nil
+	}
+	var arguments map[string]interface{}
+	if err := json.Unmarshal(data, &arguments); err != nil {
+		return nil, fmt.Errorf("arguments must encode to a JSON object: %w", err)
+	}
+	return arguments, nil
+}
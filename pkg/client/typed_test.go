@@ -0,0 +1,90 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/chongliujia/mcp-go-template/pkg/mcp"
+)
+
+type sumArgs struct {
+	Values []float64 `json:"values"`
+}
+
+type sumResult struct {
+	Sum   float64 `json:"sum"`
+	Count int     `json:"count"`
+}
+
+// sumTool adds its values, returning the result as structured content
+type sumTool struct{}
+
+func (sumTool) Definition() *mcp.Tool {
+	return &mcp.Tool{Name: "sum", InputSchema: mcp.ToolSchema{Type: "object"}}
+}
+
+func (sumTool) Execute(ctx context.Context, params map[string]interface{}) (*mcp.CallToolResult, error) {
+	values, ok := params["values"].([]interface{})
+	if !ok {
+		return &mcp.CallToolResult{Content: []mcp.Content{mcp.NewTextContent("values is required")}, IsError: true}, nil
+	}
+	result := sumResult{Count: len(values)}
+	for _, value := range values {
+		result.Sum += value.(float64)
+	}
+	return mcp.NewStructuredResult("done", result), nil
+}
+
+func TestCallToolTyped(t *testing.T) {
+	server := newFakeServer(t)
+	if err := server.handler.RegisterTool(sumTool{}); err != nil {
+		t.Fatalf("Failed to register tool: %v", err)
+	}
+	c := New(server.dial, testOptions())
+	defer c.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := c.Connect(ctx); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+
+	out, err := CallToolTyped[sumArgs, sumResult](ctx, c, "sum", sumArgs{Values: []float64{1, 2, 3.5}})
+	if err != nil {
+		t.Fatalf("CallToolTyped failed: %v", err)
+	}
+	if out.Sum != 6.5 || out.Count != 3 {
+		t.Errorf("Expected sum 6.5 of 3 values, got %+v", out)
+	}
+
+	_, err = CallToolTyped[map[string]interface{}, sumResult](ctx, c, "sum", nil)
+	var toolErr *ToolError
+	if !errors.As(err, &toolErr) || toolErr.Error() != "tool sum failed: values is required" {
+		t.Errorf("Expected a ToolError, got %v", err)
+	}
+
+	if _, err := CallToolTyped[[]int, sumResult](ctx, c, "sum", []int{1}); err == nil {
+		t.Error("Expected non-object arguments to be rejected")
+	}
+
+	if _, err := CallToolTyped[sumArgs, sumResult](ctx, c, "echo", sumArgs{}); !errors.Is(err, ErrNoStructuredContent) {
+		t.Errorf("Expected ErrNoStructuredContent, got %v", err)
+	}
+}
+
+func TestDecodeStructured_JSONTextFallback(t *testing.T) {
+	result := &mcp.CallToolResult{Content: []mcp.Content{
+		mcp.NewTextContent("done"),
+		{Type: mcp.ContentTypeText, Text: `{"sum": 3, "count": 2}`, MimeType: "application/json"},
+	}}
+
+	var out sumResult
+	if err := DecodeStructured(result, &out); err != nil {
+		t.Fatalf("DecodeStructured failed: %v", err)
+	}
+	if out.Sum != 3 || out.Count != 2 {
+		t.Errorf("Expected the JSON text block to be decoded, got %+v", out)
+	}
+}
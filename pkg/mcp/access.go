@@ -0,0 +1,154 @@
+package mcp
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+// AnyClient is the principal of the access rule for clients without a rule
+// of their own
+const AnyClient = "*"
+
+// AccessRule lists the tools and resources one client may see and use.
+// Entries are tool names or resource URIs in which * matches any run of
+// characters, so "*" alone allows everything.
+type AccessRule struct {
+	Tools     []string
+	Resources []string
+}
+
+// accessRules holds the access rules by principal
+type accessRules struct {
+	mu    sync.RWMutex
+	rules map[string]*AccessRule
+}
+
+// SetAccessRules restricts each client to the tools and resources of the
+// rule for its principal, as recorded by Session.SetPrincipal. Clients
+// without a rule of their own use the AnyClient rule, and are unrestricted
+// if there is none. Tools and resources a client may not use are left out
+// of its listings and reported as not found when it asks for them. Nil
+// rules lift all restrictions.
+func (h *BaseHandler) SetAccessRules(rules map[string]AccessRule) {
+	copied := make(map[string]*AccessRule, len(rules))
+	for principal, rule := range rules {
+		rule := rule
+		copied[principal] = &rule
+	}
+
+	h.access.mu.Lock()
+	h.access.rules = copied
+	h.access.mu.Unlock()
+}
+
+// accessRule returns the rule for the client in ctx, or nil if the client
+// is unrestricted
+func (h *BaseHandler) accessRule(ctx context.Context) *AccessRule {
+	h.access.mu.RLock()
+	defer h.access.mu.RUnlock()
+	if len(h.access.rules) == 0 {
+		return nil
+	}
+
+	if session, ok := SessionFromContext(ctx); ok {
+		if rule, ok := h.access.rules[session.Principal()]; ok {
+			return rule
+		}
+	}
+	return h.access.rules[AnyClient]
+}
+
+// allowsTool reports whether the rule allows a tool; a nil rule allows all
+func (r *AccessRule) allowsTool(name string) bool {
+	return r == nil || matchesAny(r.Tools, name)
+}
+
+// allowsResource reports whether the rule allows a resource URI or URI
+// template; a nil rule allows all
+func (r *AccessRule) allowsResource(uri string) bool {
+	return r == nil || matchesAny(r.Resources, uri)
+}
+
+// accessibleTools drops the tools the client in ctx may not use, and the
+// aliases of those tools
+func (h *BaseHandler) accessibleTools(ctx context.Context, tools []*Tool) []*Tool {
+	rule := h.accessRule(ctx)
+	if rule == nil {
+		return tools
+	}
+
+	allowed := make([]*Tool, 0, len(tools))
+	for _, tool := range tools {
+		if rule.allowsTool(h.toolTarget(tool.Name)) {
+			allowed = append(allowed, tool)
+		}
+	}
+	return allowed
+}
+
+// accessibleResources drops the resources the client in ctx may not read
+func (h *BaseHandler) accessibleResources(ctx context.Context, resources []*Resource) []*Resource {
+	rule := h.accessRule(ctx)
+	if rule == nil {
+		return resources
+	}
+
+	allowed := make([]*Resource, 0, len(resources))
+	for _, resource := range resources {
+		if rule.allowsResource(resource.URI) {
+			allowed = append(allowed, resource)
+		}
+	}
+	return allowed
+}
+
+// accessibleTemplates drops the resource templates the client in ctx may
+// not read
+func (h *BaseHandler) accessibleTemplates(ctx context.Context, templates []*ResourceTemplate) []*ResourceTemplate {
+	rule := h.accessRule(ctx)
+	if rule == nil {
+		return templates
+	}
+
+	allowed := make([]*ResourceTemplate, 0, len(templates))
+	for _, template := range templates {
+		if rule.allowsResource(template.URITemplate) {
+			allowed = append(allowed, template)
+		}
+	}
+	return allowed
+}
+
+// matchesAny reports whether value matches one of the patterns
+func matchesAny(patterns []string, value string) bool {
+	for _, pattern := range patterns {
+		if wildcardMatch(pattern, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// wildcardMatch matches value against a pattern in which * stands for any
+// run of characters, including none
+func wildcardMatch(pattern, value string) bool {
+	parts := strings.Split(pattern, "*")
+	if len(parts) == 1 {
+		return pattern == value
+	}
+
+	if !strings.HasPrefix(value, parts[0]) {
+		return false
+	}
+	value = value[len(parts[0]):]
+	last := parts[len(parts)-1]
+	for _, part := range parts[1 : len(parts)-1] {
+		index := strings.Index(value, part)
+		if index < 0 {
+			return false
+		}
+		value = value[index+len(part):]
+	}
+	return len(value) >= len(last) && strings.HasSuffix(value, last)
+}
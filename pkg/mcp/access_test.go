@@ -0,0 +1,128 @@
+package mcp
+
+import (
+	"context"
+	"sort"
+	"testing"
+)
+
+func TestBaseHandler_AccessRules(t *testing.T) {
+	h := NewBaseHandler(ServerInfo{Name: "test", Version: "1.0.0"}, ServerCapabilities{
+		Resources: &ResourcesCapability{Subscribe: true},
+	})
+	h.RegisterTool(namedTool("web_search"))
+	h.RegisterTool(namedTool("github.search"))
+	h.RegisterTool(namedTool("deploy"))
+	h.RegisterResource(pdfResource{})
+	h.SetAccessRules(map[string]AccessRule{
+		"sub:reporting": {Tools: []string{"web_search", "github.*"}, Resources: []string{"file:///*.pdf"}},
+		AnyClient:       {Tools: []string{"web_search"}},
+	})
+
+	contextFor := func(principal string) context.Context {
+		session := NewSession(principal)
+		session.SetPrincipal(principal)
+		ctx := WithSession(context.Background(), session)
+		h.HandleMessage(ctx, NewNotification("initialized", nil))
+		return ctx
+	}
+	listedTools := func(ctx context.Context) []string {
+		response, _ := h.HandleMessage(ctx, NewRequest(1, "tools/list", nil))
+		var names []string
+		for _, tool := range response.Result.(map[string]interface{})["tools"].([]*Tool) {
+			names = append(names, tool.Name)
+		}
+		return names
+	}
+
+	reporting := contextFor("sub:reporting")
+	if names := listedTools(reporting); len(names) != 2 || names[0] != "github.search" || names[1] != "web_search" {
+		t.Errorf("Expected the reporting client to see its two tools, got %v", names)
+	}
+	if _, err := h.CallTool(reporting, &CallToolParams{Name: "github.search"}); err != nil {
+		t.Errorf("Expected the reporting client to call github.search, got %v", err)
+	}
+	if _, err := h.ReadResource(reporting, &ReadResourceParams{URI: "file:///report.pdf"}); err != nil {
+		t.Errorf("Expected the reporting client to read the report, got %v", err)
+	}
+
+	other := contextFor("key:0123456789abcdef")
+	if names := listedTools(other); len(names) != 1 || names[0] != "web_search" {
+		t.Errorf("Expected other clients to get the default rule, got %v", names)
+	}
+	_, err := h.CallTool(other, &CallToolParams{Name: "deploy"})
+	if info, ok := AsErrorInfo(err); !ok || info.Code != ToolNotFound {
+		t.Errorf("Expected a call to a forbidden tool to fail as not found, got %v", err)
+	}
+	response, _ := h.HandleMessage(other, NewRequest(2, "resources/list", nil))
+	if resources := response.Result.(map[string]interface{})["resources"].([]*Resource); len(resources) != 0 {
+		t.Errorf("Expected no resources for other clients, got %v", resources)
+	}
+	_, err = h.ReadResource(other, &ReadResourceParams{URI: "file:///report.pdf"})
+	if info, ok := AsErrorInfo(err); !ok || info.Code != ResourceNotFound {
+		t.Errorf("Expected a forbidden read to fail as not found, got %v", err)
+	}
+	if err := h.setSubscription(other, &SubscribeParams{URI: "file:///report.pdf"}, true); err == nil {
+		t.Error("Expected a forbidden subscription to fail")
+	}
+
+	h.SetAccessRules(nil)
+	if names := listedTools(other); len(names) != 3 {
+		t.Errorf("Expected all tools once the rules are lifted, got %v", names)
+	}
+}
+
+func TestWildcardMatch(t *testing.T) {
+	tests := []struct {
+		pattern, value string
+		want           bool
+	}{
+		{"web_search", "web_search", true},
+		{"web_search", "web_search2", false},
+		{"*", "anything", true},
+		{"github.*", "github.search", true},
+		{"github.*", "gitlab.search", false},
+		{"file:///docs/*", "file:///docs/a/b.md", true},
+		{"*.pdf", "file:///report.pdf", true},
+		{"a*b*c", "abc", true},
+		{"a*b*c", "acb", false},
+		{"ab*ba", "aba", false},
+	}
+
+	for _, tt := range tests {
+		if got := wildcardMatch(tt.pattern, tt.value); got != tt.want {
+			t.Errorf("wildcardMatch(%q, %q) = %v, want %v", tt.pattern, tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestBaseHandler_AccessRulesFollowAliases(t *testing.T) {
+	h := NewBaseHandler(ServerInfo{Name: "test", Version: "1.0.0"}, ServerCapabilities{})
+	h.RegisterTool(namedTool("web_search"))
+	h.RegisterTool(namedTool("deploy"))
+	h.AliasTool("search", "web_search")
+	h.AliasTool("ship", "deploy")
+	h.SetAccessRules(map[string]AccessRule{
+		AnyClient: {Tools: []string{"web_search", "ship"}},
+	})
+	ctx := context.Background()
+	h.HandleMessage(ctx, NewNotification("initialized", nil))
+
+	response, _ := h.HandleMessage(ctx, NewRequest(1, "tools/list", nil))
+	var names []string
+	for _, tool := range response.Result.(map[string]interface{})["tools"].([]*Tool) {
+		names = append(names, tool.Name)
+	}
+	sort.Strings(names)
+	if len(names) != 2 || names[0] != "search" || names[1] != "web_search" {
+		t.Errorf("Expected only the allowed tool and its alias listed, got %v", names)
+	}
+
+	if _, err := h.CallTool(ctx, &CallToolParams{Name: "search"}); err != nil {
+		t.Errorf("Expected the alias of an allowed tool to be callable, got %v", err)
+	}
+	_, err := h.CallTool(ctx, &CallToolParams{Name: "ship"})
+	if info, ok := AsErrorInfo(err); !ok || info.Code != ToolNotFound {
+		t.Errorf("Expected an alias not to bypass its tool's rule, got %v", err)
+	}
+}
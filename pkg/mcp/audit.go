@@ -0,0 +1,125 @@
+package mcp
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// Redacted replaces the values of redacted arguments in audit records
+const Redacted = "[REDACTED]"
+
+// AuditRecord describes one tool call for the audit log
+type AuditRecord struct {
+	Time time.Time `json:"time"`
+	// Client is the principal of the caller's credentials, or its session
+	// when it did not authenticate
+	Client     string                 `json:"client,omitempty"`
+	ClientName string                 `json:"client_name,omitempty"`
+	SessionID  string                 `json:"session_id,omitempty"`
+	RequestID  RequestID              `json:"request_id,omitempty"`
+	Tool       string                 `json:"tool"`
+	Arguments  map[string]interface{} `json:"arguments,omitempty"`
+	DurationMs int64                  `json:"duration_ms"`
+	Success    bool                   `json:"success"`
+	Error      string                 `json:"error,omitempty"`
+}
+
+// AuditSink stores audit records
+type AuditSink interface {
+	WriteAudit(record *AuditRecord) error
+}
+
+// AuditTools records every tool call in sink: when and by whom it was made,
+// its arguments, how long it took and whether it succeeded. Arguments whose
+// keys are in redact, at any depth and ignoring case, are replaced with
+// Redacted. Records that cannot be written are logged and the call is not
+// affected. Add it first, so it sees the outcome of every other middleware.
+func AuditTools(sink AuditSink, redact []string) Middleware {
+	redacted := make(map[string]bool, len(redact))
+	for _, key := range redact {
+		redacted[strings.ToLower(key)] = true
+	}
+
+	return func(next ToolHandler) ToolHandler {
+		name := next.Definition().Name
+		return WrapTool(next, func(ctx context.Context, params map[string]interface{}) (*CallToolResult, error) {
+			start := time.Now()
+			result, err := next.Execute(ctx, params)
+
+			call := CallContextFromContext(ctx)
+			record := &AuditRecord{
+				Time:       start.UTC(),
+				ClientName: call.ClientInfo().Name,
+				SessionID:  call.SessionID(),
+				RequestID:  call.RequestID,
+				Tool:       name,
+				Arguments:  redactArguments(params, redacted),
+				DurationMs: time.Since(start).Milliseconds(),
+				Success:    err == nil && result != nil && !result.IsError,
+				Error:      callError(result, err),
+			}
+			if call.Session != nil {
+				record.Client = call.Session.Principal()
+				if record.Client == "" {
+					record.Client = "session:" + call.SessionID()
+				}
+			}
+			if writeErr := sink.WriteAudit(record); writeErr != nil {
+				call.Logger.WithError(writeErr).Error("Failed to write audit record")
+			}
+			return result, err
+		})
+	}
+}
+
+// callError describes why a call failed, or is "" when it succeeded
+func callError(result *CallToolResult, err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	if result == nil || !result.IsError {
+		return ""
+	}
+	structured, _ := result.StructuredContent.(map[string]interface{})
+	if info, ok := structured["error"].(*ErrorInfo); ok {
+		return info.Message
+	}
+	for _, content := range result.Content {
+		if content.Type == "text" && content.Text != "" {
+			return content.Text
+		}
+	}
+	return "error result"
+}
+
+// redactArguments returns a copy of params with redacted keys masked
+func redactArguments(params map[string]interface{}, redacted map[string]bool) map[string]interface{} {
+	if params == nil {
+		return nil
+	}
+	return redactValue(params, redacted).(map[string]interface{})
+}
+
+// redactValue masks redacted keys in maps within value
+func redactValue(value interface{}, redacted map[string]bool) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		masked := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			if redacted[strings.ToLower(key)] {
+				masked[key] = Redacted
+			} else {
+				masked[key] = redactValue(item, redacted)
+			}
+		}
+		return masked
+	case []interface{}:
+		masked := make([]interface{}, len(v))
+		for i, item := range v {
+			masked[i] = redactValue(item, redacted)
+		}
+		return masked
+	}
+	return value
+}
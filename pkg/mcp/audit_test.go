@@ -0,0 +1,64 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// memoryAuditSink keeps audit records in memory
+type memoryAuditSink struct {
+	records []*AuditRecord
+}
+
+func (s *memoryAuditSink) WriteAudit(record *AuditRecord) error {
+	s.records = append(s.records, record)
+	return nil
+}
+
+func TestAuditTools(t *testing.T) {
+	sink := &memoryAuditSink{}
+	h := NewBaseHandler(ServerInfo{Name: "test", Version: "1.0.0"}, ServerCapabilities{})
+	h.Use(AuditTools(sink, []string{"password", "API_KEY"}))
+	h.RegisterTool(namedTool("deploy"))
+	h.RegisterTool(&flakyTool{name: "flaky", failures: 1, fail: func() (*CallToolResult, error) {
+		return nil, errors.New("connection reset")
+	}})
+
+	session := NewSession("s1")
+	session.SetPrincipal("key:abc")
+	ctx := WithSession(context.Background(), session)
+	h.HandleMessage(ctx, NewNotification("initialized", nil))
+
+	args := map[string]interface{}{
+		"target":   "prod",
+		"password": "hunter2",
+		"options":  map[string]interface{}{"api_key": "k", "force": true},
+	}
+	h.HandleMessage(ctx, NewRequest(1, "tools/call", map[string]interface{}{"name": "deploy", "arguments": args}))
+	h.HandleMessage(ctx, NewRequest(2, "tools/call", map[string]interface{}{"name": "flaky"}))
+
+	if len(sink.records) != 2 {
+		t.Fatalf("Expected 2 audit records, got %d", len(sink.records))
+	}
+
+	deploy := sink.records[0]
+	if deploy.Tool != "deploy" || !deploy.Success || deploy.Client != "key:abc" || deploy.SessionID != "s1" {
+		t.Errorf("Unexpected record %+v", deploy)
+	}
+	if deploy.Arguments["target"] != "prod" || deploy.Arguments["password"] != Redacted {
+		t.Errorf("Expected the password to be redacted, got %v", deploy.Arguments)
+	}
+	options := deploy.Arguments["options"].(map[string]interface{})
+	if options["api_key"] != Redacted || options["force"] != true {
+		t.Errorf("Expected nested keys to be redacted, got %v", options)
+	}
+	if args["password"] != "hunter2" {
+		t.Error("Expected the call's own arguments to stay intact")
+	}
+
+	flaky := sink.records[1]
+	if flaky.Success || flaky.Error != "connection reset" {
+		t.Errorf("Expected the failure to be recorded, got %+v", flaky)
+	}
+}
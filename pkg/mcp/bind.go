@@ -0,0 +1,216 @@
+package mcp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// BindArguments decodes tool arguments into the struct target points to,
+// saving tools the type assertions on each parameter. Fields are matched
+// by their json tag, as with encoding/json. Arguments are coerced to the
+// field types where the meaning is clear: whole numbers to integers and
+// numeric or boolean strings to numbers and booleans. The struct tags used
+// by NewToolFromFunc are honoured:
+//
+//   - default is used for a missing argument
+//   - enum restricts a string to the listed values, matched ignoring case
+//     and stored as listed
+//   - minimum and maximum bound a number
+//
+// Fields without omitempty that are not pointers and have no default are
+// required. A missing, malformed or out-of-range argument is reported as
+// an InvalidParams *ErrorInfo naming it.
+func BindArguments(params map[string]interface{}, target interface{}) error {
+	value := reflect.ValueOf(target)
+	if value.Kind() != reflect.Ptr || value.IsNil() || value.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("BindArguments needs a pointer to a struct, got %T", target)
+	}
+
+	bound := make(map[string]interface{}, len(params))
+	for name, argument := range params {
+		bound[name] = argument
+	}
+	if err := bindFields(value.Elem().Type(), bound); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(bound)
+	if err != nil {
+		return fmt.Errorf("failed to encode arguments: %w", err)
+	}
+	if err := json.Unmarshal(data, target); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) && typeErr.Field != "" {
+			return InvalidParamsError(typeErr.Field, "must be "+jsonKind(typeErr.Type))
+		}
+		return InvalidParamsError("arguments", err.Error())
+	}
+	return nil
+}
+
+// bindFields fills in defaults, coerces and checks the arguments for the
+// fields of t, including those of embedded structs
+func bindFields(t reflect.Type, bound map[string]interface{}) error {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, omitEmpty, skip := jsonFieldName(field)
+		if skip {
+			continue
+		}
+		if field.Anonymous && name == "" && derefType(field.Type).Kind() == reflect.Struct {
+			if err := bindFields(derefType(field.Type), bound); err != nil {
+				return err
+			}
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		argument, exists := bound[name]
+		if !exists || argument == nil {
+			if value, ok := field.Tag.Lookup("default"); ok {
+				parsed, err := defaultValue(derefType(field.Type), value)
+				if err != nil {
+					return fmt.Errorf("field %s: invalid default %q: %w", field.Name, value, err)
+				}
+				bound[name] = parsed
+				continue
+			}
+			if !omitEmpty && field.Type.Kind() != reflect.Ptr {
+				return InvalidParamsError(name, "required parameter is missing")
+			}
+			continue
+		}
+
+		coerced, err := coerceArgument(name, argument, field)
+		if err != nil {
+			return err
+		}
+		bound[name] = coerced
+	}
+	return nil
+}
+
+// coerceArgument converts an argument to the kind of its field and checks
+// the field's enum, minimum and maximum tags
+func coerceArgument(name string, argument interface{}, field reflect.StructField) (interface{}, error) {
+	switch derefType(field.Type).Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		number, ok := numberArgument(argument)
+		if !ok || number != math.Trunc(number) {
+			return nil, InvalidParamsError(name, "must be an integer")
+		}
+		return number, checkRange(name, number, field)
+
+	case reflect.Float32, reflect.Float64:
+		number, ok := numberArgument(argument)
+		if !ok {
+			return nil, InvalidParamsError(name, "must be a number")
+		}
+		return number, checkRange(name, number, field)
+
+	case reflect.Bool:
+		if text, ok := argument.(string); ok {
+			parsed, err := strconv.ParseBool(text)
+			if err != nil {
+				return nil, InvalidParamsError(name, "must be a boolean")
+			}
+			return parsed, nil
+		}
+
+	case reflect.String:
+		enum, ok := field.Tag.Lookup("enum")
+		text, isString := argument.(string)
+		if !ok || !isString {
+			break
+		}
+		values := strings.Split(enum, ",")
+		for i, value := range values {
+			values[i] = strings.TrimSpace(value)
+			if strings.EqualFold(values[i], text) {
+				return values[i], nil
+			}
+		}
+		return nil, InvalidParamsError(name, fmt.Sprintf("must be one of [%s], got '%s'", strings.Join(values, ", "), text))
+	}
+	return argument, nil
+}
+
+// numberArgument reads a number sent as a JSON number or a numeric string
+func numberArgument(argument interface{}) (float64, bool) {
+	switch v := argument.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case json.Number:
+		number, err := v.Float64()
+		return number, err == nil
+	case string:
+		number, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return number, err == nil
+	}
+	return 0, false
+}
+
+// checkRange checks a number against its field's minimum and maximum tags
+func checkRange(name string, number float64, field reflect.StructField) error {
+	if value, ok := field.Tag.Lookup("minimum"); ok {
+		if minimum, err := strconv.ParseFloat(value, 64); err == nil && number < minimum {
+			return InvalidParamsError(name, fmt.Sprintf("must be >= %g, got %g", minimum, number))
+		}
+	}
+	if value, ok := field.Tag.Lookup("maximum"); ok {
+		if maximum, err := strconv.ParseFloat(value, 64); err == nil && number > maximum {
+			return InvalidParamsError(name, fmt.Sprintf("must be <= %g, got %g", maximum, number))
+		}
+	}
+	return nil
+}
+
+// defaultValue parses a default tag for a field of type t: strings are
+// taken literally and anything else as JSON
+func defaultValue(t reflect.Type, value string) (interface{}, error) {
+	if t.Kind() == reflect.String {
+		return value, nil
+	}
+	var parsed interface{}
+	if err := json.Unmarshal([]byte(value), &parsed); err != nil {
+		return nil, err
+	}
+	return parsed, nil
+}
+
+// jsonKind names the JSON kind expected for a Go type in error messages
+func jsonKind(t reflect.Type) string {
+	switch derefType(t).Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "an integer"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	}
+	return "an object"
+}
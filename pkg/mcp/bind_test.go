@@ -0,0 +1,86 @@
+package mcp
+
+import (
+	"strings"
+	"testing"
+)
+
+type searchArgs struct {
+	Query      string  `json:"query"`
+	MaxResults int     `json:"max_results" default:"10" minimum:"1" maximum:"50"`
+	Engine     string  `json:"engine" enum:"duckduckgo,searxng,auto" default:"auto"`
+	SafeSearch bool    `json:"safe_search" default:"true"`
+	Boost      float64 `json:"boost,omitempty"`
+	Region     *string `json:"region"`
+}
+
+func TestBindArguments(t *testing.T) {
+	var args searchArgs
+	err := BindArguments(map[string]interface{}{
+		"query":       "golang",
+		"max_results": 5.0,
+		"engine":      "SearXNG",
+		"safe_search": "false",
+		"boost":       "1.5",
+	}, &args)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := searchArgs{Query: "golang", MaxResults: 5, Engine: "searxng", SafeSearch: false, Boost: 1.5}
+	if args != want {
+		t.Errorf("Expected %+v, got %+v", want, args)
+	}
+}
+
+func TestBindArguments_Defaults(t *testing.T) {
+	var args searchArgs
+	if err := BindArguments(map[string]interface{}{"query": "golang"}, &args); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if args.MaxResults != 10 || args.Engine != "auto" || !args.SafeSearch || args.Region != nil {
+		t.Errorf("Expected the defaults, got %+v", args)
+	}
+}
+
+func TestBindArguments_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		params map[string]interface{}
+		field  string
+		reason string
+	}{
+		{"missing required", map[string]interface{}{}, "query", "missing"},
+		{"wrong type", map[string]interface{}{"query": 123}, "query", "must be a string"},
+		{"fractional integer", map[string]interface{}{"query": "q", "max_results": 2.5}, "max_results", "integer"},
+		{"non-numeric string", map[string]interface{}{"query": "q", "max_results": "many"}, "max_results", "integer"},
+		{"below minimum", map[string]interface{}{"query": "q", "max_results": 0}, "max_results", ">= 1"},
+		{"above maximum", map[string]interface{}{"query": "q", "max_results": 51}, "max_results", "<= 50"},
+		{"not in enum", map[string]interface{}{"query": "q", "engine": "bing"}, "engine", "must be one of"},
+		{"bad boolean", map[string]interface{}{"query": "q", "safe_search": "maybe"}, "safe_search", "boolean"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var args searchArgs
+			err := BindArguments(tt.params, &args)
+			info, ok := AsErrorInfo(err)
+			if !ok || info.Code != InvalidParams {
+				t.Fatalf("Expected an InvalidParams error, got %v", err)
+			}
+			data := info.Data.(map[string]interface{})
+			if data["field"] != tt.field || !strings.Contains(data["reason"].(string), tt.reason) {
+				t.Errorf("Expected field %s with reason containing %q, got %v", tt.field, tt.reason, data)
+			}
+		})
+	}
+}
+
+func TestBindArguments_RejectsNonStruct(t *testing.T) {
+	var query string
+	if err := BindArguments(map[string]interface{}{}, &query); err == nil {
+		t.Error("Expected an error for a non-struct target")
+	}
+	if err := BindArguments(map[string]interface{}{}, searchArgs{}); err == nil {
+		t.Error("Expected an error for a non-pointer target")
+	}
+}
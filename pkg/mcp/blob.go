@@ -0,0 +1,101 @@
+package mcp
+
+import (
+	"encoding/base64"
+	"fmt"
+)
+
+// NewTextResourceContents returns the contents of a text resource
+func NewTextResourceContents(uri, mimeType, text string) ResourceContents {
+	return ResourceContents{URI: uri, MimeType: mimeType, Text: text}
+}
+
+// NewBlobResourceContents returns the contents of a binary resource, such
+// as a PDF or PNG, encoded as base64
+func NewBlobResourceContents(uri, mimeType string, data []byte) ResourceContents {
+	return ResourceContents{
+		URI:      uri,
+		MimeType: mimeType,
+		Blob:     base64.StdEncoding.EncodeToString(data),
+	}
+}
+
+// IsBlob reports whether the contents are binary
+func (c ResourceContents) IsBlob() bool {
+	return c.Blob != ""
+}
+
+// Bytes returns the resource's data, decoding blobs from base64
+func (c ResourceContents) Bytes() ([]byte, error) {
+	if !c.IsBlob() {
+		return []byte(c.Text), nil
+	}
+	data, err := base64.StdEncoding.DecodeString(c.Blob)
+	if err != nil {
+		return nil, fmt.Errorf("invalid blob for %s: %w", c.URI, err)
+	}
+	return data, nil
+}
+
+// NewEmbeddedResource returns tool content carrying a resource's contents,
+// letting tools return files such as PDFs inline
+func NewEmbeddedResource(contents ResourceContents) Content {
+	return Content{Type: ContentTypeResource, Resource: &contents}
+}
+
+// SetMaxBlobBytes caps the decoded size of the binary data in a single tool
+// result or resource read; 0 or less means unlimited
+func (h *BaseHandler) SetMaxBlobBytes(limit int64) {
+	h.toolsMu.Lock()
+	defer h.toolsMu.Unlock()
+	h.maxBlobBytes = limit
+}
+
+// blobLimit returns the configured blob size limit
+func (h *BaseHandler) blobLimit() int64 {
+	h.toolsMu.RLock()
+	defer h.toolsMu.RUnlock()
+	return h.maxBlobBytes
+}
+
+// blobSize returns the decoded size of base64 data
+func blobSize(encoded string) int64 {
+	return int64(base64.StdEncoding.DecodedLen(len(encoded)))
+}
+
+// checkContentBlobs returns an error when the binary data in contents
+// exceeds limit
+func checkContentBlobs(limit int64, contents []Content) error {
+	if limit <= 0 {
+		return nil
+	}
+
+	var total int64
+	for _, content := range contents {
+		total += blobSize(content.Data)
+		if content.Resource != nil {
+			total += blobSize(content.Resource.Blob)
+		}
+	}
+	if total > limit {
+		return LimitExceededError("max_blob_bytes", int(limit))
+	}
+	return nil
+}
+
+// checkResourceBlobs returns an error when the blobs read from a resource
+// exceed limit
+func checkResourceBlobs(limit int64, contents []ResourceContents) error {
+	if limit <= 0 {
+		return nil
+	}
+
+	var total int64
+	for _, content := range contents {
+		total += blobSize(content.Blob)
+	}
+	if total > limit {
+		return LimitExceededError("max_blob_bytes", int(limit))
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+// pdfResource serves a binary document
+type pdfResource struct {
+	data []byte
+}
+
+func (r pdfResource) Definition() *Resource {
+	return &Resource{URI: "file:///report.pdf", Name: "Report", MimeType: "application/pdf"}
+}
+
+func (r pdfResource) Read(ctx context.Context, uri string) (*ReadResourceResult, error) {
+	return &ReadResourceResult{Contents: []ResourceContents{NewBlobResourceContents(uri, "application/pdf", r.data)}}, nil
+}
+
+func TestBlobResourceContents_RoundTrip(t *testing.T) {
+	data := []byte{0x25, 0x50, 0x44, 0x46, 0x00, 0xff}
+	contents := NewBlobResourceContents("file:///report.pdf", "application/pdf", data)
+	if !contents.IsBlob() {
+		t.Fatalf("Expected blob contents")
+	}
+
+	decoded, err := contents.Bytes()
+	if err != nil {
+		t.Fatalf("Bytes failed: %v", err)
+	}
+	if !bytes.Equal(decoded, data) {
+		t.Errorf("Expected %v, got %v", data, decoded)
+	}
+
+	text := NewTextResourceContents("file:///notes.txt", "text/plain", "hello")
+	if decoded, _ := text.Bytes(); string(decoded) != "hello" {
+		t.Errorf("Expected text contents as bytes, got %q", decoded)
+	}
+}
+
+func TestBaseHandler_BlobSizeLimit(t *testing.T) {
+	h := NewBaseHandler(ServerInfo{Name: "test", Version: "1.0.0"}, ServerCapabilities{})
+	if err := h.RegisterResource(pdfResource{data: make([]byte, 2048)}); err != nil {
+		t.Fatalf("Failed to register resource: %v", err)
+	}
+
+	ctx := WithSession(context.Background(), NewSession("blobs"))
+	h.HandleMessage(ctx, NewRequest(1, "initialize", InitializeParams{ProtocolVersion: MCPVersion}))
+	h.HandleMessage(ctx, NewNotification("initialized", nil))
+
+	read := NewRequest(2, "resources/read", ReadResourceParams{URI: "file:///report.pdf"})
+	if response, _ := h.HandleMessage(ctx, read); response.Error != nil {
+		t.Fatalf("Expected the read to succeed without a limit, got %v", response.Error)
+	}
+
+	h.SetMaxBlobBytes(1024)
+	response, _ := h.HandleMessage(ctx, read)
+	if response.Error == nil || response.Error.Code != LimitExceeded {
+		t.Errorf("Expected a limit error, got %+v", response.Error)
+	}
+}
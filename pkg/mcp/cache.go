@@ -0,0 +1,201 @@
+package mcp
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// ResultCacheOptions configures CacheResults
+type ResultCacheOptions struct {
+	// Tools lists the tools whose results are cached; others are not
+	Tools []string
+	// Global lists the cached tools whose results are shared by all
+	// clients. Only tools whose results depend on their arguments alone,
+	// such as calculator or a search of public sources, are safe to list;
+	// the results of others are cached for each client separately.
+	Global []string
+	// TTL is how long a result is served from the cache
+	TTL time.Duration
+	// MaxEntries bounds the cached results of all tools together; the least
+	// recently used are evicted first. 0 means no bound.
+	MaxEntries int
+	// Shared, when set, keeps results in a store seen by every replica
+	// instead of in memory, so a result cached by one replica serves
+	// calls to all of them. MaxEntries does not apply to it.
+	Shared SharedStore
+}
+
+// CacheResults serves repeated calls to the tools named in options from a
+// cache, keyed by tool name, arguments and the calling client, so identical
+// searches or analyses return at once. A client is the principal of its
+// credentials, or else its session; tools in options.Global leave the
+// client out of the key. Arguments are compared as JSON, so key order
+// does not matter. Only successful results are cached.
+func CacheResults(options ResultCacheOptions) Middleware {
+	cache := &resultCache{
+		shared:     options.Shared,
+		ttl:        options.TTL,
+		maxEntries: options.MaxEntries,
+		order:      list.New(),
+		entries:    make(map[string]*list.Element),
+	}
+	cached := make(map[string]bool, len(options.Tools))
+	for _, name := range options.Tools {
+		cached[name] = true
+	}
+	global := make(map[string]bool, len(options.Global))
+	for _, name := range options.Global {
+		global[name] = true
+	}
+
+	return func(next ToolHandler) ToolHandler {
+		name := next.Definition().Name
+		if !cached[name] {
+			return next
+		}
+		return WrapTool(next, func(ctx context.Context, params map[string]interface{}) (*CallToolResult, error) {
+			arguments, err := json.Marshal(params)
+			if err != nil {
+				return next.Execute(ctx, params)
+			}
+			key := name + "\x00" + string(arguments)
+			if !global[name] {
+				key += "\x00" + cacheClient(ctx)
+			}
+
+			if result, hit := cache.get(ctx, key); hit {
+				return result, nil
+			}
+			result, err := next.Execute(ctx, params)
+			if err == nil && result != nil && !result.IsError {
+				cache.put(ctx, key, result)
+			}
+			return result, err
+		})
+	}
+}
+
+// cacheClient identifies the client in ctx for the cache key. Calls without
+// a session share the "" client.
+func cacheClient(ctx context.Context) string {
+	session, ok := SessionFromContext(ctx)
+	if !ok {
+		return ""
+	}
+	if principal := session.Principal(); principal != "" {
+		return "principal:" + principal
+	}
+	return "session:" + session.ID()
+}
+
+// resultCache is an LRU cache of tool results with expiry, or with a
+// shared store the store's keys
+type resultCache struct {
+	shared     SharedStore
+	ttl        time.Duration
+	maxEntries int
+
+	mu      sync.Mutex
+	order   *list.List // most recently used first
+	entries map[string]*list.Element
+}
+
+// cacheEntry is a cached tool result
+type cacheEntry struct {
+	key     string
+	result  CallToolResult
+	expires time.Time
+}
+
+// get returns a copy of the cached result for key, if it has not expired
+func (c *resultCache) get(ctx context.Context, key string) (*CallToolResult, bool) {
+	if c.shared != nil {
+		return c.getShared(ctx, key)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, exists := c.entries[key]
+	if !exists {
+		return nil, false
+	}
+	entry := element.Value.(*cacheEntry)
+	if time.Now().After(entry.expires) {
+		c.order.Remove(element)
+		delete(c.entries, key)
+		return nil, false
+	}
+	c.order.MoveToFront(element)
+
+	result := entry.result
+	return &result, true
+}
+
+// put caches a copy of result under key, evicting the least recently used
+// entry when the cache is full
+func (c *resultCache) put(ctx context.Context, key string, result *CallToolResult) {
+	if c.shared != nil {
+		c.putShared(ctx, key, result)
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := &cacheEntry{key: key, result: *result, expires: time.Now().Add(c.ttl)}
+	if element, exists := c.entries[key]; exists {
+		element.Value = entry
+		c.order.MoveToFront(element)
+		return
+	}
+	c.entries[key] = c.order.PushFront(entry)
+
+	if c.maxEntries > 0 && c.order.Len() > c.maxEntries {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+// sharedKey returns the shared store key of a cache key, hashed to bound
+// its length however large the arguments are
+func sharedKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return sharedCachePrefix + hex.EncodeToString(sum[:])
+}
+
+// getShared returns the result cached for key in the shared store; results
+// that cannot be read are treated as misses
+func (c *resultCache) getShared(ctx context.Context, key string) (*CallToolResult, bool) {
+	ctx, cancel := context.WithTimeout(ctx, sharedTimeout)
+	defer cancel()
+
+	data, err := c.shared.Get(ctx, sharedKey(key))
+	if err != nil {
+		return nil, false
+	}
+	var result CallToolResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, false
+	}
+	return &result, true
+}
+
+// putShared caches result under key in the shared store; a failure only
+// means the next call runs the tool again
+func (c *resultCache) putShared(ctx context.Context, key string, result *CallToolResult) {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, sharedTimeout)
+	defer cancel()
+	c.shared.Set(ctx, sharedKey(key), data, c.ttl)
+}
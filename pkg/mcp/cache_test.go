@@ -0,0 +1,207 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// countingTool reports how often it ran
+type countingTool struct {
+	name  string
+	calls int
+}
+
+func (t *countingTool) Definition() *Tool {
+	return &Tool{Name: t.name, InputSchema: ToolSchema{Type: "object"}}
+}
+
+func (t *countingTool) Execute(ctx context.Context, params map[string]interface{}) (*CallToolResult, error) {
+	t.calls++
+	if params["fail"] == true {
+		return NewErrorResult(InvalidParamsError("fail", "asked to fail")), nil
+	}
+	return &CallToolResult{Content: []Content{NewTextContent(fmt.Sprintf("call %d", t.calls))}}, nil
+}
+
+func newCacheHandler(options ResultCacheOptions, tools ...ToolHandler) *BaseHandler {
+	h := NewBaseHandler(ServerInfo{Name: "test", Version: "1.0.0"}, ServerCapabilities{})
+	h.Use(CacheResults(options))
+	for _, tool := range tools {
+		h.RegisterTool(tool)
+	}
+	h.HandleMessage(context.Background(), NewNotification("initialized", nil))
+	return h
+}
+
+func TestCacheResults(t *testing.T) {
+	search := &countingTool{name: "web_search"}
+	other := &countingTool{name: "calculator"}
+	h := newCacheHandler(ResultCacheOptions{Tools: []string{"web_search"}, TTL: time.Minute}, search, other)
+
+	first, _ := h.CallTool(context.Background(), &CallToolParams{Name: "web_search", Arguments: map[string]interface{}{"query": "go", "limit": 5}})
+	second, _ := h.CallTool(context.Background(), &CallToolParams{Name: "web_search", Arguments: map[string]interface{}{"limit": 5, "query": "go"}})
+	if search.calls != 1 {
+		t.Fatalf("Expected the repeated call to be served from the cache, tool ran %d times", search.calls)
+	}
+	if second.Content[0].Text != first.Content[0].Text {
+		t.Errorf("Expected the cached result, got %q", second.Content[0].Text)
+	}
+
+	h.CallTool(context.Background(), &CallToolParams{Name: "web_search", Arguments: map[string]interface{}{"query": "rust"}})
+	if search.calls != 2 {
+		t.Errorf("Expected other arguments to miss the cache, tool ran %d times", search.calls)
+	}
+
+	// Error results are not cached
+	for i := 0; i < 2; i++ {
+		h.CallTool(context.Background(), &CallToolParams{Name: "web_search", Arguments: map[string]interface{}{"fail": true}})
+	}
+	if search.calls != 4 {
+		t.Errorf("Expected error results not to be cached, tool ran %d times", search.calls)
+	}
+
+	// Tools not opted in are not cached
+	for i := 0; i < 2; i++ {
+		h.CallTool(context.Background(), &CallToolParams{Name: "calculator"})
+	}
+	if other.calls != 2 {
+		t.Errorf("Expected an uncached tool to run every time, ran %d times", other.calls)
+	}
+}
+
+func TestCacheResults_Expiry(t *testing.T) {
+	search := &countingTool{name: "web_search"}
+	h := newCacheHandler(ResultCacheOptions{Tools: []string{"web_search"}, TTL: 20 * time.Millisecond}, search)
+
+	h.CallTool(context.Background(), &CallToolParams{Name: "web_search"})
+	h.CallTool(context.Background(), &CallToolParams{Name: "web_search"})
+	time.Sleep(40 * time.Millisecond)
+	h.CallTool(context.Background(), &CallToolParams{Name: "web_search"})
+	if search.calls != 2 {
+		t.Errorf("Expected the result to expire after the TTL, tool ran %d times", search.calls)
+	}
+}
+
+func TestCacheResults_MaxEntries(t *testing.T) {
+	search := &countingTool{name: "web_search"}
+	h := newCacheHandler(ResultCacheOptions{Tools: []string{"web_search"}, TTL: time.Minute, MaxEntries: 2}, search)
+
+	call := func(query string) {
+		h.CallTool(context.Background(), &CallToolParams{Name: "web_search", Arguments: map[string]interface{}{"query": query}})
+	}
+	call("a")
+	call("b")
+	call("a") // a is now the most recently used
+	call("c") // evicts b
+	if search.calls != 3 {
+		t.Fatalf("Expected 3 executions, got %d", search.calls)
+	}
+
+	call("a")
+	if search.calls != 3 {
+		t.Errorf("Expected a to stay cached, tool ran %d times", search.calls)
+	}
+	call("b")
+	if search.calls != 4 {
+		t.Errorf("Expected b to be evicted, tool ran %d times", search.calls)
+	}
+}
+
+// memoryShared is a SharedStore standing in for a backend shared by replicas
+type memoryShared struct {
+	mu     sync.Mutex
+	values map[string][]byte
+	counts map[string]int64
+}
+
+func newMemoryShared() *memoryShared {
+	return &memoryShared{values: make(map[string][]byte), counts: make(map[string]int64)}
+}
+
+func (m *memoryShared) Get(ctx context.Context, key string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	value, exists := m.values[key]
+	if !exists {
+		return nil, errors.New("not found")
+	}
+	return value, nil
+}
+
+func (m *memoryShared) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.values[key] = value
+	return nil
+}
+
+func (m *memoryShared) Incr(ctx context.Context, key string, window time.Duration) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.counts[key]++
+	return m.counts[key], nil
+}
+
+func TestCacheResults_Shared(t *testing.T) {
+	shared := newMemoryShared()
+	options := ResultCacheOptions{Tools: []string{"web_search"}, TTL: time.Minute, Shared: shared}
+	first := &countingTool{name: "web_search"}
+	second := &countingTool{name: "web_search"}
+	replicas := []*BaseHandler{newCacheHandler(options, first), newCacheHandler(options, second)}
+
+	params := &CallToolParams{Name: "web_search", Arguments: map[string]interface{}{"query": "go"}}
+	cached, _ := replicas[0].CallTool(context.Background(), params)
+	result, _ := replicas[1].CallTool(context.Background(), params)
+	if first.calls != 1 || second.calls != 0 {
+		t.Fatalf("Expected one replica's result to serve the other, tools ran %d and %d times", first.calls, second.calls)
+	}
+	if result.Content[0].Text != cached.Content[0].Text {
+		t.Errorf("Expected the shared result, got %q", result.Content[0].Text)
+	}
+}
+
+func TestCacheResults_PerClient(t *testing.T) {
+	search := &countingTool{name: "web_search"}
+	calculator := &countingTool{name: "calculator"}
+	h := newCacheHandler(ResultCacheOptions{
+		Tools: []string{"web_search", "calculator"}, Global: []string{"calculator"}, TTL: time.Minute,
+	}, search, calculator)
+	client := func(id, principal string) context.Context {
+		session := NewSession(id)
+		session.SetPrincipal(principal)
+		ctx := WithSession(context.Background(), session)
+		h.HandleMessage(ctx, NewRequest(1, "initialize", InitializeParams{ProtocolVersion: MCPVersion}))
+		h.HandleMessage(ctx, NewNotification("initialized", nil))
+		return ctx
+	}
+	alice, bob := client("alice", ""), client("bob", "")
+
+	params := &CallToolParams{Name: "web_search", Arguments: map[string]interface{}{"query": "go"}}
+	h.CallTool(alice, params)
+	h.CallTool(bob, params)
+	if search.calls != 2 {
+		t.Errorf("Expected one client's result not to answer another, tool ran %d times", search.calls)
+	}
+	h.CallTool(alice, params)
+	if search.calls != 2 {
+		t.Errorf("Expected the client's own result from the cache, tool ran %d times", search.calls)
+	}
+
+	// Sessions of the same principal share results
+	h.CallTool(client("first", "agent"), params)
+	h.CallTool(client("second", "agent"), params)
+	if search.calls != 3 {
+		t.Errorf("Expected the principal's result in a new session, tool ran %d times", search.calls)
+	}
+
+	sum := &CallToolParams{Name: "calculator", Arguments: map[string]interface{}{"expression": "1+1"}}
+	h.CallTool(alice, sum)
+	h.CallTool(bob, sum)
+	if calculator.calls != 1 {
+		t.Errorf("Expected a global tool's result to answer every client, tool ran %d times", calculator.calls)
+	}
+}
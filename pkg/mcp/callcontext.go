@@ -0,0 +1,93 @@
+package mcp
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/chongliujia/mcp-go-template/pkg/utils"
+)
+
+// CallContext describes the tool call being handled, so tools can behave
+// per client or request without globals
+type CallContext struct {
+	// RequestID is the JSON-RPC ID of the tools/call request, nil for
+	// direct CallTool calls
+	RequestID RequestID
+	// Tool is the name of the tool being called
+	Tool string
+	// Session is the calling client's session, nil outside one
+	Session *Session
+	// Progress reports progress to the client; it is never nil
+	Progress ProgressReporter
+	// Logger is the handler's logger tagged with the tool, request, session
+	// and correlation ID
+	Logger utils.Logger
+}
+
+// SessionID returns the calling client's session ID, or "" outside a
+// session
+func (c *CallContext) SessionID() string {
+	if c.Session == nil {
+		return ""
+	}
+	return c.Session.ID()
+}
+
+// ClientInfo returns what the calling client sent in initialize
+func (c *CallContext) ClientInfo() ClientInfo {
+	if c.Session == nil {
+		return ClientInfo{}
+	}
+	return c.Session.ClientInfo()
+}
+
+// callContextKey is the context key for the current tool call
+type callContextKey struct{}
+
+// requestIDKey is the context key for the ID of the request being handled
+type requestIDKey struct{}
+
+// withRequestID returns a context carrying the ID of the request it handles
+func withRequestID(ctx context.Context, id RequestID) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// withCallContext returns a context carrying the CallContext of a call to
+// tool, logging through logger. The progress reporter must already be in
+// ctx.
+func withCallContext(ctx context.Context, logger utils.Logger, tool string) context.Context {
+	call := &CallContext{
+		RequestID: ctx.Value(requestIDKey{}),
+		Tool:      tool,
+		Progress:  ProgressFromContext(ctx),
+	}
+
+	fields := logrus.Fields{"tool": tool}
+	if call.RequestID != nil {
+		fields["request_id"] = call.RequestID
+	}
+	if session, ok := SessionFromContext(ctx); ok {
+		call.Session = session
+		fields["session_id"] = session.ID()
+		if name := session.ClientInfo().Name; name != "" {
+			fields["client"] = name
+		}
+	}
+	call.Logger = utils.CorrelatedLogger(ctx, logger).WithFields(fields)
+
+	return context.WithValue(ctx, callContextKey{}, call)
+}
+
+// CallContextFromContext returns the CallContext of the tool call handled
+// in ctx. It is never nil: outside a tool call its fields are empty, its
+// progress reports are discarded and its logger is the global one.
+func CallContextFromContext(ctx context.Context) *CallContext {
+	if call, ok := ctx.Value(callContextKey{}).(*CallContext); ok {
+		return call
+	}
+	return &CallContext{
+		Progress: ProgressFromContext(ctx),
+		Logger:   utils.LoggerFromContext(ctx),
+	}
+}
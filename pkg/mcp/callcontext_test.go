@@ -0,0 +1,80 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sirupsen/logrus/hooks/test"
+
+	"github.com/chongliujia/mcp-go-template/pkg/utils"
+)
+
+// contextTool records the CallContext it was called with
+type contextTool struct {
+	calls []*CallContext
+}
+
+func (t *contextTool) Definition() *Tool {
+	return &Tool{Name: "context", InputSchema: ToolSchema{Type: "object"}}
+}
+
+func (t *contextTool) Execute(ctx context.Context, params map[string]interface{}) (*CallToolResult, error) {
+	call := CallContextFromContext(ctx)
+	call.Progress(1, 1, "done")
+	t.calls = append(t.calls, call)
+	return &CallToolResult{}, nil
+}
+
+func TestBaseHandler_ProvidesCallContext(t *testing.T) {
+	h := NewBaseHandler(ServerInfo{Name: "test", Version: "1.0.0"}, ServerCapabilities{})
+	logger, logs := test.NewNullLogger()
+	h.SetLogger(utils.NewLogger(logger))
+	tool := &contextTool{}
+	if err := h.RegisterTool(tool); err != nil {
+		t.Fatalf("Failed to register tool: %v", err)
+	}
+
+	var progress int
+	session := NewSession("session-1")
+	session.SetNotifier(func(notification *Message) { progress++ })
+	ctx := WithSession(context.Background(), session)
+	h.HandleMessage(ctx, NewRequest(1, "initialize", InitializeParams{
+		ProtocolVersion: MCPVersion,
+		ClientInfo:      ClientInfo{Name: "inspector", Version: "0.1"},
+	}))
+	h.HandleMessage(ctx, NewNotification("initialized", nil))
+
+	h.HandleMessage(ctx, NewRequest("call-7", "tools/call", CallToolParams{
+		Name: "context",
+		Meta: &RequestMeta{ProgressToken: "p"},
+	}))
+	if len(tool.calls) != 1 {
+		t.Fatalf("Expected one call, got %d", len(tool.calls))
+	}
+
+	call := tool.calls[0]
+	if call.RequestID != "call-7" || call.Tool != "context" {
+		t.Errorf("Expected request call-7 for the context tool, got %v and %q", call.RequestID, call.Tool)
+	}
+	if call.SessionID() != "session-1" || call.ClientInfo().Name != "inspector" {
+		t.Errorf("Expected the caller's session and client info, got %q and %+v", call.SessionID(), call.ClientInfo())
+	}
+	if progress != 1 {
+		t.Errorf("Expected the progress report to reach the client, got %d notifications", progress)
+	}
+	call.Logger.Info("called")
+	entry := logs.LastEntry()
+	if entry == nil || entry.Data["tool"] != "context" || entry.Data["session_id"] != "session-1" || entry.Data["client"] != "inspector" {
+		t.Errorf("Expected the handler's logger tagged with the call, got %v", entry)
+	}
+}
+
+func TestCallContextFromContext_OutsideCall(t *testing.T) {
+	call := CallContextFromContext(context.Background())
+	if call.RequestID != nil || call.Session != nil || call.SessionID() != "" {
+		t.Errorf("Expected an empty call context, got %+v", call)
+	}
+	// Progress and Logger are usable without checks
+	call.Progress(1, 2, "ignored")
+	call.Logger.Debug("ignored")
+}
@@ -0,0 +1,56 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// requestKey identifies a request ID in a session's in-flight requests,
+// keeping the string "1" and the number 1 apart
+func requestKey(id RequestID) string {
+	key, err := json.Marshal(id)
+	if err != nil {
+		return ""
+	}
+	return string(key)
+}
+
+// trackRequest returns a context that notifications/cancelled for id can
+// cancel, and a function to call once the request has been answered.
+// Requests outside a session cannot be cancelled.
+func trackRequest(ctx context.Context, id RequestID) (context.Context, func()) {
+	session, ok := SessionFromContext(ctx)
+	key := requestKey(id)
+	if !ok || key == "" {
+		return ctx, func() {}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	entry := &cancel
+	session.mu.Lock()
+	session.inflight[key] = entry
+	session.mu.Unlock()
+
+	return ctx, func() {
+		session.mu.Lock()
+		// A client reusing the ID may have replaced this request's entry
+		if session.inflight[key] == entry {
+			delete(session.inflight, key)
+		}
+		session.mu.Unlock()
+		cancel()
+	}
+}
+
+// cancelRequest cancels the context of the client's request with id,
+// reporting whether it was still running
+func (s *Session) cancelRequest(id RequestID) bool {
+	s.mu.Lock()
+	cancel, exists := s.inflight[requestKey(id)]
+	s.mu.Unlock()
+
+	if exists {
+		(*cancel)()
+	}
+	return exists
+}
@@ -0,0 +1,60 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+)
+
+// Request sends a request to this client, such as sampling/createMessage,
+// and waits for its response until ctx is done. An error response is
+// returned as an *ErrorInfo. When ctx ends first, the client is told to
+// cancel the request.
+func (s *Session) Request(ctx context.Context, method string, params interface{}) (*Message, error) {
+	reply := make(chan *Message, 1)
+
+	s.mu.Lock()
+	s.nextRequest++
+	id := fmt.Sprintf("server-%d", s.nextRequest)
+	key := requestKey(id)
+	s.pending[key] = reply
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.pending, key)
+		s.mu.Unlock()
+	}()
+
+	if !s.Notify(NewRequest(id, method, params)) {
+		return nil, fmt.Errorf("session %s cannot send requests to its client", s.id)
+	}
+
+	select {
+	case response := <-reply:
+		if response.Error != nil {
+			return nil, response.Error
+		}
+		return response, nil
+	case <-ctx.Done():
+		s.Notify(NewNotification("notifications/cancelled", CancelledParams{
+			RequestID: id,
+			Reason:    ctx.Err().Error(),
+		}))
+		return nil, ctx.Err()
+	}
+}
+
+// HandleResponse passes a response from the client to the Request waiting
+// for it, reporting false if no request is waiting. Transports call it for
+// every response they receive.
+func (s *Session) HandleResponse(response *Message) bool {
+	s.mu.Lock()
+	reply, exists := s.pending[requestKey(response.ID)]
+	delete(s.pending, requestKey(response.ID))
+	s.mu.Unlock()
+
+	if exists {
+		reply <- response
+	}
+	return exists
+}
@@ -0,0 +1,177 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// maxCompletionValues caps the suggestions in one completion/complete result
+const maxCompletionValues = 100
+
+// Completion reference types
+const (
+	RefPrompt   = "ref/prompt"
+	RefResource = "ref/resource"
+	// RefTool completes tool arguments; it is an extension to the protocol
+	RefTool = "ref/tool"
+)
+
+// CompletionReference names the prompt, resource or tool being completed
+type CompletionReference struct {
+	Type string `json:"type"`
+	Name string `json:"name,omitempty"`
+	URI  string `json:"uri,omitempty"`
+}
+
+// CompletionArgument is the argument being completed and its partial value
+type CompletionArgument struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// CompletionContext holds arguments the client has already filled in
+type CompletionContext struct {
+	Arguments map[string]string `json:"arguments,omitempty"`
+}
+
+// CompleteParams represents completion/complete parameters
+type CompleteParams struct {
+	Ref      CompletionReference `json:"ref"`
+	Argument CompletionArgument  `json:"argument"`
+	Context  *CompletionContext  `json:"context,omitempty"`
+}
+
+// Completion holds suggested values for an argument
+type Completion struct {
+	Values  []string `json:"values"`
+	Total   int      `json:"total,omitempty"`
+	HasMore bool     `json:"hasMore,omitempty"`
+}
+
+// CompleteResult represents the result of completion/complete
+type CompleteResult struct {
+	Completion Completion `json:"completion"`
+}
+
+// CompletionProvider is implemented by tool, prompt and resource handlers
+// that suggest values for their arguments. Tools without one get the enum
+// values from their input schema, and prompts the values declared on their
+// arguments.
+type CompletionProvider interface {
+	Complete(ctx context.Context, params *CompleteParams) (*Completion, error)
+}
+
+// CompleteValues returns the candidates starting with prefix, ignoring
+// case, for providers that pick from a fixed list
+func CompleteValues(candidates []string, prefix string) *Completion {
+	prefix = strings.ToLower(prefix)
+	values := make([]string, 0, len(candidates))
+	for _, candidate := range candidates {
+		if strings.HasPrefix(strings.ToLower(candidate), prefix) {
+			values = append(values, candidate)
+		}
+	}
+	return &Completion{Values: values, Total: len(values)}
+}
+
+// complete answers completion/complete by asking the referenced handler,
+// falling back to schema enums for tools and declared values for prompts
+func (h *BaseHandler) complete(ctx context.Context, params *CompleteParams) (*CompleteResult, error) {
+	if !h.isInitialized(ctx) {
+		return nil, NotInitializedError()
+	}
+	if h.capabilities.Completions == nil {
+		return nil, NewError(MethodNotFound, "completions are not supported", nil)
+	}
+	if params.Argument.Name == "" {
+		return nil, InvalidParamsError("argument.name", "is required")
+	}
+
+	var target interface{}
+	var fallback func() *Completion
+	switch params.Ref.Type {
+	case RefPrompt:
+		handler, err := h.prompts.Get(params.Ref.Name)
+		if err != nil {
+			return nil, PromptNotFoundError(params.Ref.Name)
+		}
+		target = handler
+		fallback = func() *Completion {
+			return CompleteValues(PromptArgumentValues(handler.Definition(), params.Argument.Name), params.Argument.Value)
+		}
+	case RefResource:
+		// The reference names a resource template or a concrete resource
+		if handler, exists := h.templateHandler(params.Ref.URI); exists {
+			target = handler
+			break
+		}
+		handler, exists := h.lookupResource(params.Ref.URI)
+		if !exists {
+			return nil, ResourceNotFoundError(params.Ref.URI)
+		}
+		target = handler
+	case RefTool:
+		handler, err := h.enabledTool(params.Ref.Name)
+		if err != nil {
+			return nil, err
+		}
+		target = handler
+		fallback = func() *Completion {
+			return CompleteValues(schemaEnum(handler.Definition().InputSchema, params.Argument.Name), params.Argument.Value)
+		}
+	default:
+		return nil, InvalidParamsError("ref.type", fmt.Sprintf("unknown reference type %q", params.Ref.Type))
+	}
+
+	completion := &Completion{}
+	if provider, ok := target.(CompletionProvider); ok {
+		var err error
+		if completion, err = provider.Complete(ctx, params); err != nil {
+			return nil, err
+		}
+	} else if fallback != nil {
+		completion = fallback()
+	}
+
+	if completion.Values == nil {
+		completion.Values = []string{}
+	}
+	if len(completion.Values) > maxCompletionValues {
+		completion.Values = completion.Values[:maxCompletionValues]
+		completion.HasMore = true
+	}
+	return &CompleteResult{Completion: *completion}, nil
+}
+
+// PromptArgumentValues returns the values declared for an argument of
+// prompt
+func PromptArgumentValues(prompt *Prompt, name string) []string {
+	for _, arg := range prompt.Arguments {
+		if arg.Name == name {
+			return arg.Values
+		}
+	}
+	return nil
+}
+
+// schemaEnum returns the string enum values of a property in schema
+func schemaEnum(schema ToolSchema, property string) []string {
+	prop, ok := schema.Properties[property].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	var values []string
+	switch enum := prop["enum"].(type) {
+	case []string:
+		values = enum
+	case []interface{}:
+		for _, value := range enum {
+			if s, ok := value.(string); ok {
+				values = append(values, s)
+			}
+		}
+	}
+	return values
+}
@@ -0,0 +1,113 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// enumTool has an enum argument for completion
+type enumTool struct{}
+
+func (enumTool) Definition() *Tool {
+	return &Tool{Name: "enum", InputSchema: ToolSchema{
+		Type: "object",
+		Properties: map[string]interface{}{
+			"engine": map[string]interface{}{
+				"type": "string",
+				"enum": []string{"brave", "duckduckgo", "bing"},
+			},
+		},
+	}}
+}
+
+func (enumTool) Execute(ctx context.Context, params map[string]interface{}) (*CallToolResult, error) {
+	return &CallToolResult{}, nil
+}
+
+func TestBaseHandler_CompletesToolEnums(t *testing.T) {
+	h := NewBaseHandler(ServerInfo{Name: "test", Version: "1.0.0"}, ServerCapabilities{
+		Completions: &CompletionsCapability{},
+	})
+	h.RegisterTool(enumTool{})
+
+	ctx := WithSession(context.Background(), NewSession("completing"))
+	h.HandleMessage(ctx, NewRequest(1, "initialize", InitializeParams{ProtocolVersion: MCPVersion}))
+	h.HandleMessage(ctx, NewNotification("initialized", nil))
+
+	response, _ := h.HandleMessage(ctx, NewRequest(2, "completion/complete", CompleteParams{
+		Ref:      CompletionReference{Type: RefTool, Name: "enum"},
+		Argument: CompletionArgument{Name: "engine", Value: "B"},
+	}))
+	if response.Error != nil {
+		t.Fatalf("completion/complete failed: %v", response.Error.Message)
+	}
+
+	values := response.Result.(*CompleteResult).Completion.Values
+	if fmt.Sprint(values) != "[brave bing]" {
+		t.Errorf("Expected [brave bing], got %v", values)
+	}
+}
+
+// depthPrompt declares the values of its depth argument
+type depthPrompt struct{}
+
+func (depthPrompt) Definition() *Prompt {
+	return &Prompt{Name: "depth", Arguments: []PromptArgument{
+		{Name: "depth", Values: []string{"brief", "standard", "detailed"}},
+		{Name: "topic"},
+	}}
+}
+
+func (depthPrompt) Generate(ctx context.Context, params map[string]interface{}) (*GetPromptResult, error) {
+	return NewPromptResult("Depth"), nil
+}
+
+func TestBaseHandler_CompletesDeclaredPromptValues(t *testing.T) {
+	h := NewBaseHandler(ServerInfo{Name: "test", Version: "1.0.0"}, ServerCapabilities{
+		Prompts:     &PromptsCapability{},
+		Completions: &CompletionsCapability{},
+	})
+	h.RegisterPrompt(depthPrompt{})
+
+	ctx := WithSession(context.Background(), NewSession("completing"))
+	h.HandleMessage(ctx, NewRequest(1, "initialize", InitializeParams{ProtocolVersion: MCPVersion}))
+	h.HandleMessage(ctx, NewNotification("initialized", nil))
+
+	tests := map[string]string{"depth": "[brief]", "topic": "[]"}
+	for argument, expected := range tests {
+		response, _ := h.HandleMessage(ctx, NewRequest(2, "completion/complete", CompleteParams{
+			Ref:      CompletionReference{Type: RefPrompt, Name: "depth"},
+			Argument: CompletionArgument{Name: argument, Value: "b"},
+		}))
+		if response.Error != nil {
+			t.Fatalf("completion/complete failed: %v", response.Error.Message)
+		}
+		if values := response.Result.(*CompleteResult).Completion.Values; fmt.Sprint(values) != expected {
+			t.Errorf("%s: expected %s, got %v", argument, expected, values)
+		}
+	}
+
+	if data, _ := json.Marshal(depthPrompt{}.Definition()); strings.Contains(string(data), "brief") {
+		t.Errorf("Expected declared values not to be sent to clients, got %s", data)
+	}
+}
+
+func TestBaseHandler_CompletionRequiresCapability(t *testing.T) {
+	h := NewBaseHandler(ServerInfo{Name: "test", Version: "1.0.0"}, ServerCapabilities{})
+	h.RegisterTool(enumTool{})
+
+	ctx := WithSession(context.Background(), NewSession("completing"))
+	h.HandleMessage(ctx, NewRequest(1, "initialize", InitializeParams{ProtocolVersion: MCPVersion}))
+	h.HandleMessage(ctx, NewNotification("initialized", nil))
+
+	response, _ := h.HandleMessage(ctx, NewRequest(2, "completion/complete", CompleteParams{
+		Ref:      CompletionReference{Type: RefTool, Name: "enum"},
+		Argument: CompletionArgument{Name: "engine"},
+	}))
+	if response.Error == nil || response.Error.Code != MethodNotFound {
+		t.Errorf("Expected method not found without the completions capability, got %+v", response.Error)
+	}
+}
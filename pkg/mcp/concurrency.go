@@ -0,0 +1,64 @@
+package mcp
+
+import (
+	"context"
+	"time"
+)
+
+// ToolConcurrencyLimit bounds how many calls to a tool run at once
+type ToolConcurrencyLimit struct {
+	MaxConcurrent int
+	// QueueTimeout is how long an excess call waits for a running one to
+	// finish; 0 rejects it at once
+	QueueTimeout time.Duration
+}
+
+// LimitToolConcurrency caps the calls running at once for the tools named
+// in limits, such as memory-heavy ones. Excess calls wait up to the
+// queue timeout, then fail with a LimitExceeded error result whose data
+// carries the tool.
+func LimitToolConcurrency(limits map[string]ToolConcurrencyLimit) Middleware {
+	slots := make(map[string]chan struct{}, len(limits))
+	for name, limit := range limits {
+		slots[name] = make(chan struct{}, limit.MaxConcurrent)
+	}
+
+	return func(next ToolHandler) ToolHandler {
+		name := next.Definition().Name
+		sem, limited := slots[name]
+		if !limited {
+			return next
+		}
+		timeout := limits[name].QueueTimeout
+
+		return WrapTool(next, func(ctx context.Context, params map[string]interface{}) (*CallToolResult, error) {
+			select {
+			case sem <- struct{}{}:
+			default:
+				if timeout <= 0 {
+					return busyResult(name, cap(sem)), nil
+				}
+				timer := time.NewTimer(timeout)
+				defer timer.Stop()
+				select {
+				case sem <- struct{}{}:
+				case <-timer.C:
+					return busyResult(name, cap(sem)), nil
+				case <-ctx.Done():
+					return nil, ctx.Err()
+				}
+			}
+			defer func() { <-sem }()
+
+			return next.Execute(ctx, params)
+		})
+	}
+}
+
+// busyResult reports a call refused by LimitToolConcurrency
+func busyResult(tool string, max int) *CallToolResult {
+	info := LimitExceededError("max_concurrent_calls", max)
+	info.Message = "tool " + tool + " is busy: " + info.Message
+	info.Data.(map[string]interface{})["tool"] = tool
+	return NewErrorResult(info)
+}
@@ -0,0 +1,80 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// gatedTool runs until its release channel is closed
+type gatedTool struct {
+	name    string
+	started chan struct{}
+	release chan struct{}
+}
+
+func newGatedTool(name string) *gatedTool {
+	return &gatedTool{name: name, started: make(chan struct{}, 2), release: make(chan struct{})}
+}
+
+func (t *gatedTool) Definition() *Tool {
+	return &Tool{Name: t.name, InputSchema: ToolSchema{Type: "object"}}
+}
+
+func (t *gatedTool) Execute(ctx context.Context, params map[string]interface{}) (*CallToolResult, error) {
+	t.started <- struct{}{}
+	<-t.release
+	return &CallToolResult{Content: []Content{NewTextContent("done")}}, nil
+}
+
+func TestLimitToolConcurrency(t *testing.T) {
+	rejecting := newGatedTool("rejecting")
+	queueing := newGatedTool("queueing")
+	h := NewBaseHandler(ServerInfo{Name: "test", Version: "1.0.0"}, ServerCapabilities{})
+	h.RegisterTool(rejecting)
+	h.RegisterTool(queueing)
+	h.Use(LimitToolConcurrency(map[string]ToolConcurrencyLimit{
+		"rejecting": {MaxConcurrent: 1},
+		"queueing":  {MaxConcurrent: 1, QueueTimeout: 5 * time.Second},
+	}))
+	h.HandleMessage(context.Background(), NewNotification("initialized", nil))
+
+	call := func(name string) chan *CallToolResult {
+		results := make(chan *CallToolResult, 1)
+		go func() {
+			result, _ := h.CallTool(context.Background(), &CallToolParams{Name: name})
+			results <- result
+		}()
+		return results
+	}
+
+	// Without a queue timeout, excess calls are rejected at once
+	running := call("rejecting")
+	<-rejecting.started
+	result, err := h.CallTool(context.Background(), &CallToolParams{Name: "rejecting"})
+	if err != nil || !result.IsError {
+		t.Fatalf("Expected a busy error result, got %+v, %v", result, err)
+	}
+	structured, _ := result.StructuredContent.(map[string]interface{})
+	if info, _ := structured["error"].(*ErrorInfo); info == nil || info.Code != LimitExceeded {
+		t.Errorf("Expected a LimitExceeded error, got %#v", result.StructuredContent)
+	}
+	close(rejecting.release)
+	<-running
+
+	// With one, they wait for the running call to finish
+	first := call("queueing")
+	<-queueing.started
+	second := call("queueing")
+	select {
+	case <-queueing.started:
+		t.Fatal("Expected the second call to wait")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(queueing.release)
+	for _, results := range []chan *CallToolResult{first, second} {
+		if result := <-results; result.IsError {
+			t.Errorf("Expected both calls to succeed, got %+v", result)
+		}
+	}
+}
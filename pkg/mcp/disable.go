@@ -0,0 +1,109 @@
+package mcp
+
+import "sort"
+
+// ToolSwitch is implemented by handlers whose tools can be disabled and
+// re-enabled at runtime
+type ToolSwitch interface {
+	DisableTool(name string) error
+	EnableTool(name string) error
+	DisabledTools() []string
+}
+
+// DisableTool hides a registered tool from tools/list and rejects calls to
+// it as if it did not exist, until EnableTool is called. Clients are told
+// the tool list changed.
+func (h *BaseHandler) DisableTool(name string) error {
+	if _, err := h.tools.Get(name); err != nil {
+		return ToolNotFoundError(name)
+	}
+
+	h.toolsMu.Lock()
+	if h.disabled[name] {
+		h.toolsMu.Unlock()
+		return nil
+	}
+	if h.disabled == nil {
+		h.disabled = make(map[string]bool)
+	}
+	h.disabled[name] = true
+	h.toolsMu.Unlock()
+
+	h.notifyToolsChanged()
+	return nil
+}
+
+// EnableTool makes a tool disabled by DisableTool available again
+func (h *BaseHandler) EnableTool(name string) error {
+	h.toolsMu.Lock()
+	disabled := h.disabled[name]
+	delete(h.disabled, name)
+	h.toolsMu.Unlock()
+
+	if !disabled {
+		if _, err := h.tools.Get(name); err != nil {
+			return ToolNotFoundError(name)
+		}
+		return nil
+	}
+
+	h.notifyToolsChanged()
+	return nil
+}
+
+// IsToolEnabled reports whether a tool is registered and not disabled
+func (h *BaseHandler) IsToolEnabled(name string) bool {
+	_, err := h.enabledTool(name)
+	return err == nil
+}
+
+// DisabledTools returns the names of the disabled tools, sorted
+func (h *BaseHandler) DisabledTools() []string {
+	h.toolsMu.RLock()
+	defer h.toolsMu.RUnlock()
+
+	names := make([]string, 0, len(h.disabled))
+	for name := range h.disabled {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// enabledTool looks up a tool that may be called, by name or alias
+func (h *BaseHandler) enabledTool(name string) (ToolHandler, error) {
+	handler, err := h.tools.Get(name)
+
+	h.toolsMu.RLock()
+	resolved := name
+	if target, aliased := h.aliasTarget(name); err != nil && aliased {
+		// Aliases resolve one level, so they cannot loop
+		resolved = target
+		handler, err = h.tools.Get(target)
+	}
+	disabled := h.disabled[resolved]
+	h.toolsMu.RUnlock()
+
+	if err != nil || disabled {
+		return nil, ToolNotFoundError(name)
+	}
+	return handler, nil
+}
+
+// enabledTools drops disabled tools from a tool list and adds the aliases
+// of the remaining ones
+func (h *BaseHandler) enabledTools(tools []*Tool) []*Tool {
+	h.toolsMu.RLock()
+	defer h.toolsMu.RUnlock()
+	if len(h.disabled) == 0 {
+		return h.withAliases(tools)
+	}
+
+	enabled := make([]*Tool, 0, len(tools))
+	for _, tool := range tools {
+		if !h.disabled[tool.Name] {
+			enabled = append(enabled, tool)
+		}
+	}
+	return h.withAliases(enabled)
+}
@@ -0,0 +1,63 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestBaseHandler_DisableTool(t *testing.T) {
+	h := NewBaseHandler(ServerInfo{Name: "test", Version: "1.0.0"}, ServerCapabilities{
+		Tools: &ToolsCapability{ListChanged: true},
+	})
+	h.RegisterTool(namedTool("search"))
+	h.RegisterTool(namedTool("summarize"))
+	h.HandleMessage(context.Background(), NewNotification("initialized", nil))
+
+	changes := 0
+	h.SetNotifier(func(notification *Message) {
+		if notification.Method == "notifications/tools/list_changed" {
+			changes++
+		}
+	})
+
+	if err := h.DisableTool("search"); err != nil {
+		t.Fatalf("DisableTool failed: %v", err)
+	}
+	// Disabling twice changes nothing
+	h.DisableTool("search")
+	if changes != 1 {
+		t.Errorf("Expected one list_changed notification, got %d", changes)
+	}
+
+	listed, _ := h.ListTools()
+	if len(listed) != 1 || listed[0].Name != "summarize" {
+		t.Errorf("Expected only summarize to be listed, got %v", listed)
+	}
+	if h.IsToolEnabled("search") || len(h.DisabledTools()) != 1 {
+		t.Errorf("Expected search to be disabled, got %v", h.DisabledTools())
+	}
+
+	_, err := h.CallTool(context.Background(), &CallToolParams{Name: "search"})
+	var info *ErrorInfo
+	if !errors.As(err, &info) || info.Code != ToolNotFound {
+		t.Errorf("Expected calls to a disabled tool to fail as not found, got %v", err)
+	}
+
+	if err := h.EnableTool("search"); err != nil {
+		t.Fatalf("EnableTool failed: %v", err)
+	}
+	if listed, _ := h.ListTools(); len(listed) != 2 || changes != 2 {
+		t.Errorf("Expected both tools after re-enabling and two notifications, got %d and %d", len(listed), changes)
+	}
+	if _, err := h.CallTool(context.Background(), &CallToolParams{Name: "search"}); err != nil {
+		t.Errorf("Expected the re-enabled tool to be callable, got %v", err)
+	}
+
+	if err := h.DisableTool("missing"); err == nil {
+		t.Error("Expected disabling an unknown tool to fail")
+	}
+	if err := h.EnableTool("missing"); err == nil {
+		t.Error("Expected enabling an unknown tool to fail")
+	}
+}
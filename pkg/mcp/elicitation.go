@@ -0,0 +1,74 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrElicitationUnsupported is returned by Elicit when the client did not
+// advertise the elicitation capability
+var ErrElicitationUnsupported = errors.New("client does not support elicitation")
+
+// ElicitAction is the user's response to an elicitation request
+type ElicitAction string
+
+// Elicit actions
+const (
+	// ElicitAccept means the user submitted the requested content
+	ElicitAccept ElicitAction = "accept"
+	// ElicitDecline means the user explicitly refused to answer
+	ElicitDecline ElicitAction = "decline"
+	// ElicitCancel means the user dismissed the request without choosing
+	ElicitCancel ElicitAction = "cancel"
+)
+
+// ElicitParams represents elicitation/create parameters. The requested
+// schema is a flat object whose properties are strings, numbers, booleans
+// or enums.
+type ElicitParams struct {
+	Message         string     `json:"message"`
+	RequestedSchema ToolSchema `json:"requestedSchema"`
+}
+
+// ElicitResult represents the client's answer to elicitation/create
+type ElicitResult struct {
+	Action  ElicitAction           `json:"action"`
+	Content map[string]interface{} `json:"content,omitempty"`
+}
+
+// Accepted reports whether the user submitted content
+func (r *ElicitResult) Accepted() bool {
+	return r.Action == ElicitAccept
+}
+
+// Elicit asks the user of the client making the current request for input
+// matching params.RequestedSchema, pausing the tool until they answer or
+// ctx is done. Never use it to ask for passwords, API keys or other secrets.
+func Elicit(ctx context.Context, params *ElicitParams) (*ElicitResult, error) {
+	session, ok := SessionFromContext(ctx)
+	if !ok {
+		return nil, fmt.Errorf("elicitation requires a session")
+	}
+	if session.ClientCapabilities().Elicitation == nil {
+		return nil, ErrElicitationUnsupported
+	}
+
+	response, err := session.Request(ctx, "elicitation/create", params)
+	if err != nil {
+		return nil, err
+	}
+
+	var result ElicitResult
+	if err := response.UnmarshalResult(&result); err != nil {
+		return nil, fmt.Errorf("invalid elicitation result: %w", err)
+	}
+	return &result, nil
+}
+
+// ClientSupportsElicitation reports whether the client making the current
+// request advertised the elicitation capability
+func ClientSupportsElicitation(ctx context.Context) bool {
+	session, ok := SessionFromContext(ctx)
+	return ok && session.ClientCapabilities().Elicitation != nil
+}
@@ -0,0 +1,52 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestElicit_ReturnsUserAnswer(t *testing.T) {
+	session := NewSession("elicitation")
+	ctx := WithSession(context.Background(), session)
+	session.SetNotifier(func(*Message) {
+		t.Errorf("Expected no request without the elicitation capability")
+	})
+
+	if _, err := Elicit(ctx, &ElicitParams{Message: "Which engine?"}); !errors.Is(err, ErrElicitationUnsupported) {
+		t.Fatalf("Expected ErrElicitationUnsupported, got %v", err)
+	}
+
+	session.setClient(&InitializeParams{
+		Capabilities: ClientCapabilities{Elicitation: map[string]interface{}{}},
+	}, MCPVersion)
+
+	var params ElicitParams
+	session.SetNotifier(func(request *Message) {
+		if request.Method != "elicitation/create" {
+			t.Errorf("Expected elicitation/create, got %s", request.Method)
+		}
+		params = *request.Params.(*ElicitParams)
+		session.HandleResponse(NewSuccessResponse(request.ID, ElicitResult{
+			Action:  ElicitAccept,
+			Content: map[string]interface{}{"engine": "searxng"},
+		}))
+	})
+
+	result, err := Elicit(ctx, &ElicitParams{
+		Message: "Which engine?",
+		RequestedSchema: ToolSchema{
+			Type:       "object",
+			Properties: map[string]interface{}{"engine": map[string]interface{}{"type": "string"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Elicit failed: %v", err)
+	}
+	if params.Message != "Which engine?" {
+		t.Errorf("Expected the message to reach the client, got %q", params.Message)
+	}
+	if !result.Accepted() || result.Content["engine"] != "searxng" {
+		t.Errorf("Unexpected result %+v", result)
+	}
+}
@@ -0,0 +1,105 @@
+package mcp
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// NewError creates an error carrying an MCP error code
+func NewError(code int, message string, data interface{}) *ErrorInfo {
+	return &ErrorInfo{
+		Code:    code,
+		Message: message,
+		Data:    data,
+	}
+}
+
+// ToolNotFoundError reports a call to an unregistered tool
+func ToolNotFoundError(name string) *ErrorInfo {
+	return NewError(ToolNotFound, fmt.Sprintf("tool '%s' not found", name), map[string]interface{}{
+		"tool": name,
+	})
+}
+
+// ResourceNotFoundError reports a read of an unregistered resource
+func ResourceNotFoundError(uri string) *ErrorInfo {
+	return NewError(ResourceNotFound, fmt.Sprintf("resource '%s' not found", uri), map[string]interface{}{
+		"uri": uri,
+	})
+}
+
+// PromptNotFoundError reports a request for an unregistered prompt
+func PromptNotFoundError(name string) *ErrorInfo {
+	return NewError(PromptNotFound, fmt.Sprintf("prompt '%s' not found", name), map[string]interface{}{
+		"prompt": name,
+	})
+}
+
+// InvalidParamsError reports an invalid or missing request parameter
+func InvalidParamsError(field, reason string) *ErrorInfo {
+	return NewError(InvalidParams, fmt.Sprintf("invalid parameter '%s': %s", field, reason), map[string]interface{}{
+		"field":  field,
+		"reason": reason,
+	})
+}
+
+// MethodNotFoundError reports a request for an unknown method
+func MethodNotFoundError(method string) *ErrorInfo {
+	return NewError(MethodNotFound, fmt.Sprintf("method '%s' not found", method), map[string]interface{}{
+		"method": method,
+	})
+}
+
+// UnsupportedVersionError reports an initialize request with an unsupported protocol version
+func UnsupportedVersionError(requested string, supported ...string) *ErrorInfo {
+	return NewError(InvalidMCPVersion, fmt.Sprintf("unsupported protocol version: %s", requested), map[string]interface{}{
+		"requested": requested,
+		"supported": supported,
+	})
+}
+
+// LimitExceededError reports a request refused because a server limit was reached
+func LimitExceededError(limit string, max int) *ErrorInfo {
+	return NewError(LimitExceeded, fmt.Sprintf("%s limit of %d exceeded", limit, max), map[string]interface{}{
+		"limit": limit,
+		"max":   max,
+	})
+}
+
+// UnauthorizedError reports a request from a client that has not authenticated
+func UnauthorizedError(reason string) *ErrorInfo {
+	return NewError(Unauthorized, "unauthorized: "+reason, nil)
+}
+
+// RateLimitedError reports a request refused because the client exceeded
+// its rate limit; retryAfter is how long until a retry can succeed
+func RateLimitedError(retryAfter time.Duration) *ErrorInfo {
+	return NewError(RateLimited, fmt.Sprintf("rate limit exceeded, retry after %s", retryAfter.Round(time.Millisecond)), map[string]interface{}{
+		"retryAfterMs": retryAfter.Milliseconds(),
+	})
+}
+
+// NotInitializedError reports a request made before initialization completed
+func NotInitializedError() *ErrorInfo {
+	return NewError(InvalidRequest, "handler not initialized", nil)
+}
+
+// AsErrorInfo returns the *ErrorInfo in err's chain, if any
+func AsErrorInfo(err error) (*ErrorInfo, bool) {
+	var info *ErrorInfo
+	if errors.As(err, &info) {
+		return info, true
+	}
+	return nil, false
+}
+
+// NewErrorResponseFromError creates an error response for err. Typed MCP
+// errors keep their code, message, and data; any other error is reported
+// with the fallback code and message, with the error text as data.
+func NewErrorResponseFromError(id RequestID, err error, fallbackCode int, fallbackMessage string) *Message {
+	if info, ok := AsErrorInfo(err); ok {
+		return NewErrorResponse(id, info.Code, info.Message, info.Data)
+	}
+	return NewErrorResponse(id, fallbackCode, fallbackMessage, err.Error())
+}
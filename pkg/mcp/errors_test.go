@@ -0,0 +1,63 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+func TestNewErrorResponseFromError(t *testing.T) {
+	tests := []struct {
+		name         string
+		err          error
+		expectedCode int
+	}{
+		{"typed error", ToolNotFoundError("missing"), ToolNotFound},
+		{"wrapped typed error", fmt.Errorf("lookup: %w", PromptNotFoundError("missing")), PromptNotFound},
+		{"plain error", fmt.Errorf("boom"), InternalError},
+		{"limit error", LimitExceededError("max_connections", 10), LimitExceeded},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := NewErrorResponseFromError(1, tt.err, InternalError, "failed")
+			if response.Error.Code != tt.expectedCode {
+				t.Errorf("Code = %d, want %d", response.Error.Code, tt.expectedCode)
+			}
+		})
+	}
+}
+
+func TestBaseHandler_TypedErrors(t *testing.T) {
+	h := NewBaseHandler(ServerInfo{Name: "test", Version: "1.0.0"}, ServerCapabilities{})
+	ctx := context.Background()
+
+	h.HandleMessage(ctx, NewNotification("initialized", nil))
+
+	tests := []struct {
+		method       string
+		params       interface{}
+		expectedCode int
+	}{
+		{"tools/call", map[string]interface{}{"name": "missing"}, ToolNotFound},
+		{"resources/read", map[string]interface{}{"uri": "file:///missing"}, ResourceNotFound},
+		{"prompts/get", map[string]interface{}{"name": "missing"}, PromptNotFound},
+		{"initialize", map[string]interface{}{"protocolVersion": "1999-01-01"}, InvalidMCPVersion},
+		{"unknown/method", nil, MethodNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			response, _ := h.HandleMessage(ctx, NewRequest(1, tt.method, tt.params))
+			if response.Error == nil {
+				t.Fatal("Expected error response")
+			}
+			if response.Error.Code != tt.expectedCode {
+				t.Errorf("Code = %d, want %d", response.Error.Code, tt.expectedCode)
+			}
+			if tt.expectedCode != MethodNotFound && response.Error.Data == nil {
+				t.Error("Expected structured error data")
+			}
+		})
+	}
+}
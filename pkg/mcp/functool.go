@@ -0,0 +1,333 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// FuncTool is a tool backed by a plain Go function, built by NewToolFromFunc
+// or RegisterToolFunc
+type FuncTool struct {
+	definition *Tool
+	// validate is set when the derived schema can be checked with
+	// ValidateToolParameters
+	validate bool
+	execute  func(ctx context.Context, params map[string]interface{}) (*CallToolResult, error)
+}
+
+// NewToolFromFunc returns a tool that calls fn with its arguments decoded
+// into Args. The input schema is derived from the fields of Args: the json
+// tag names a property, fields without omitempty that are not pointers are
+// required, and the description, enum (comma separated), minimum, maximum
+// and default tags fill in the matching schema keywords.
+//
+// A Result of *CallToolResult is returned as is and a string becomes a text
+// result. Any other Result is returned as structured content, with an
+// output schema derived from it when it is a struct.
+func NewToolFromFunc[Args, Result any](name, description string, fn func(ctx context.Context, args Args) (Result, error)) (*FuncTool, error) {
+	argsType := reflect.TypeOf((*Args)(nil)).Elem()
+	if derefType(argsType).Kind() != reflect.Struct {
+		return nil, fmt.Errorf("tool %s: arguments must be a struct, got %s", name, argsType)
+	}
+
+	inputSchema, err := schemaForStruct(derefType(argsType))
+	if err != nil {
+		return nil, fmt.Errorf("tool %s: %w", name, err)
+	}
+
+	tool := &FuncTool{
+		definition: &Tool{
+			Name:        name,
+			Description: description,
+			InputSchema: inputSchema,
+		},
+		validate: ValidateToolSchema(inputSchema) == nil,
+	}
+
+	resultType := reflect.TypeOf((*Result)(nil)).Elem()
+	switch {
+	case resultType == reflect.TypeOf((*CallToolResult)(nil)), resultType.Kind() == reflect.String:
+	case derefType(resultType).Kind() == reflect.Struct && derefType(resultType) != reflect.TypeOf(time.Time{}):
+		outputSchema, err := schemaForStruct(derefType(resultType))
+		if err != nil {
+			return nil, fmt.Errorf("tool %s: %w", name, err)
+		}
+		tool.definition.OutputSchema = &outputSchema
+	}
+
+	tool.execute = func(ctx context.Context, params map[string]interface{}) (*CallToolResult, error) {
+		var args Args
+		if err := decodeArguments(params, &args); err != nil {
+			return nil, err
+		}
+
+		result, err := fn(ctx, args)
+		if err != nil {
+			return nil, err
+		}
+		return funcResult(result)
+	}
+	return tool, nil
+}
+
+// Definition implements ToolHandler
+func (t *FuncTool) Definition() *Tool {
+	return t.definition
+}
+
+// Execute implements ToolHandler, validating the arguments against the
+// derived schema before calling the function
+func (t *FuncTool) Execute(ctx context.Context, params map[string]interface{}) (*CallToolResult, error) {
+	if t.validate {
+		if err := ValidateToolParameters(params, t.definition.InputSchema); err != nil {
+			return nil, err
+		}
+	}
+	return t.execute(ctx, params)
+}
+
+// RegisterToolFunc registers a tool described by def that runs fn, for
+// one-off tools not worth a type implementing ToolHandler. Unlike
+// NewToolFromFunc, fn receives the raw arguments and def is used as is.
+func (h *BaseHandler) RegisterToolFunc(def *Tool, fn ExecuteFunc) error {
+	if def == nil || def.Name == "" {
+		return fmt.Errorf("tool definition must have a name")
+	}
+	if fn == nil {
+		return fmt.Errorf("tool %s: function cannot be nil", def.Name)
+	}
+	return h.RegisterTool(&FuncTool{definition: def, execute: fn})
+}
+
+// decodeArguments fills args from tool parameters through JSON, so the
+// Args struct sees values exactly as a client sent them
+func decodeArguments(params map[string]interface{}, args interface{}) error {
+	if params == nil {
+		params = map[string]interface{}{}
+	}
+	data, err := json.Marshal(params)
+	if err != nil {
+		return fmt.Errorf("failed to encode arguments: %w", err)
+	}
+	if err := json.Unmarshal(data, args); err != nil {
+		return fmt.Errorf("invalid arguments: %w", err)
+	}
+	return nil
+}
+
+// funcResult converts a function's return value into a tool result
+func funcResult(result interface{}) (*CallToolResult, error) {
+	switch r := result.(type) {
+	case *CallToolResult:
+		if r == nil {
+			return &CallToolResult{Content: []Content{}}, nil
+		}
+		return r, nil
+	case string:
+		return &CallToolResult{Content: []Content{NewTextContent(r)}}, nil
+	}
+
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode result: %w", err)
+	}
+	var structured interface{} = result
+	if kind := reflect.TypeOf(result); kind == nil || derefType(kind).Kind() != reflect.Struct && derefType(kind).Kind() != reflect.Map {
+		// Structured content must be an object, so other values travel
+		// as text only
+		structured = nil
+	}
+	return NewStructuredResult(string(data), structured), nil
+}
+
+// schemaForStruct derives an object schema from a struct's exported fields
+func schemaForStruct(t reflect.Type) (ToolSchema, error) {
+	properties, required, err := structProperties(t, map[reflect.Type]bool{t: true})
+	return ToolSchema{Type: "object", Properties: properties, Required: required}, err
+}
+
+// structProperties derives the properties of a struct and which of them are
+// required, flattening embedded structs as encoding/json does. seen holds
+// the structs being expanded, to reject recursive types.
+func structProperties(t reflect.Type, seen map[reflect.Type]bool) (map[string]interface{}, []string, error) {
+	properties := map[string]interface{}{}
+	var required []string
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, omitEmpty, skip := jsonFieldName(field)
+		if skip {
+			continue
+		}
+
+		if field.Anonymous && name == "" && derefType(field.Type).Kind() == reflect.Struct {
+			embedded, embeddedRequired, err := structProperties(derefType(field.Type), seen)
+			if err != nil {
+				return nil, nil, err
+			}
+			for embeddedName, property := range embedded {
+				properties[embeddedName] = property
+			}
+			required = append(required, embeddedRequired...)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		property, err := schemaForType(field.Type, seen)
+		if err != nil {
+			return nil, nil, fmt.Errorf("field %s: %w", field.Name, err)
+		}
+		if err := applyFieldTags(property, field); err != nil {
+			return nil, nil, fmt.Errorf("field %s: %w", field.Name, err)
+		}
+		properties[name] = property
+
+		if !omitEmpty && field.Type.Kind() != reflect.Ptr {
+			required = append(required, name)
+		}
+	}
+	return properties, required, nil
+}
+
+// jsonFieldName reads a field's json tag
+func jsonFieldName(field reflect.StructField) (name string, omitEmpty, skip bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false, true
+	}
+	name, options, _ := strings.Cut(tag, ",")
+	for _, option := range strings.Split(options, ",") {
+		if option == "omitempty" {
+			omitEmpty = true
+		}
+	}
+	return name, omitEmpty, false
+}
+
+// schemaForType derives the schema of a single value. seen guards against
+// recursive types.
+func schemaForType(t reflect.Type, seen map[reflect.Type]bool) (map[string]interface{}, error) {
+	t = derefType(t)
+	if t == reflect.TypeOf(time.Time{}) {
+		return map[string]interface{}{"type": "string", "format": "date-time"}, nil
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}, nil
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}, nil
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}, nil
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			// encoding/json sends byte slices as base64 strings
+			return map[string]interface{}{"type": "string", "contentEncoding": "base64"}, nil
+		}
+		items, err := schemaForType(t.Elem(), seen)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"type": "array", "items": items}, nil
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("map keys must be strings, got %s", t.Key())
+		}
+		values, err := schemaForType(t.Elem(), seen)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"type": "object", "additionalProperties": values}, nil
+	case reflect.Struct:
+		if seen[t] {
+			return nil, fmt.Errorf("recursive type %s is not supported", t)
+		}
+		seen[t] = true
+		properties, required, err := structProperties(t, seen)
+		delete(seen, t)
+		if err != nil {
+			return nil, err
+		}
+
+		property := map[string]interface{}{"type": "object", "properties": properties}
+		if len(required) > 0 {
+			property["required"] = required
+		}
+		return property, nil
+	case reflect.Interface:
+		// Any JSON value
+		return map[string]interface{}{}, nil
+	}
+	return nil, fmt.Errorf("unsupported type %s", t)
+}
+
+// applyFieldTags copies the schema keywords given as struct tags
+func applyFieldTags(property map[string]interface{}, field reflect.StructField) error {
+	if description, ok := field.Tag.Lookup("description"); ok {
+		property["description"] = description
+	}
+
+	if enum, ok := field.Tag.Lookup("enum"); ok {
+		var values []interface{}
+		for _, value := range strings.Split(enum, ",") {
+			parsed, err := tagValue(property, strings.TrimSpace(value))
+			if err != nil {
+				return fmt.Errorf("invalid enum value %q: %w", value, err)
+			}
+			values = append(values, parsed)
+		}
+		property["enum"] = values
+	}
+
+	for _, keyword := range []string{"minimum", "maximum"} {
+		if value, ok := field.Tag.Lookup(keyword); ok {
+			var number float64
+			if _, err := fmt.Sscan(value, &number); err != nil {
+				return fmt.Errorf("invalid %s %q", keyword, value)
+			}
+			property[keyword] = number
+		}
+	}
+
+	if value, ok := field.Tag.Lookup("default"); ok {
+		parsed, err := tagValue(property, value)
+		if err != nil {
+			return fmt.Errorf("invalid default %q: %w", value, err)
+		}
+		property["default"] = parsed
+	}
+	return nil
+}
+
+// tagValue parses a tag value as the property's type: strings are taken
+// literally and anything else as JSON
+func tagValue(property map[string]interface{}, value string) (interface{}, error) {
+	if property["type"] == "string" {
+		return value, nil
+	}
+	var parsed interface{}
+	if err := json.Unmarshal([]byte(value), &parsed); err != nil {
+		return nil, err
+	}
+	return parsed, nil
+}
+
+// derefType strips pointer types
+func derefType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
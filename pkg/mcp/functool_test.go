@@ -0,0 +1,167 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type convertArgs struct {
+	Value float64 `json:"value" description:"The value to convert"`
+	From  string  `json:"from" enum:"celsius,fahrenheit"`
+	// Precision is optional because of omitempty
+	Precision int `json:"precision,omitempty" minimum:"0" maximum:"6" default:"2"`
+	// Note is optional because it is a pointer
+	Note     *string `json:"note"`
+	internal string
+}
+
+type convertResult struct {
+	Value float64 `json:"value"`
+	Unit  string  `json:"unit"`
+}
+
+func convert(ctx context.Context, args convertArgs) (convertResult, error) {
+	if args.From == "celsius" {
+		return convertResult{Value: args.Value*9/5 + 32, Unit: "fahrenheit"}, nil
+	}
+	return convertResult{Value: (args.Value - 32) * 5 / 9, Unit: "celsius"}, nil
+}
+
+func TestNewToolFromFunc_DerivesSchema(t *testing.T) {
+	tool, err := NewToolFromFunc("convert_temperature", "Converts temperatures", convert)
+	if err != nil {
+		t.Fatalf("NewToolFromFunc failed: %v", err)
+	}
+
+	definition := tool.Definition()
+	if err := ValidateToolSchema(definition.InputSchema); err != nil {
+		t.Fatalf("Derived schema is invalid: %v", err)
+	}
+	if !reflect.DeepEqual(definition.InputSchema.Required, []string{"value", "from"}) {
+		t.Errorf("Expected value and from to be required, got %v", definition.InputSchema.Required)
+	}
+
+	expected := map[string]interface{}{
+		"value":     map[string]interface{}{"type": "number", "description": "The value to convert"},
+		"from":      map[string]interface{}{"type": "string", "enum": []interface{}{"celsius", "fahrenheit"}},
+		"precision": map[string]interface{}{"type": "integer", "minimum": 0.0, "maximum": 6.0, "default": 2.0},
+		"note":      map[string]interface{}{"type": "string"},
+	}
+	if !reflect.DeepEqual(definition.InputSchema.Properties, expected) {
+		t.Errorf("Unexpected properties:\n got %#v\nwant %#v", definition.InputSchema.Properties, expected)
+	}
+
+	if definition.OutputSchema == nil || len(definition.OutputSchema.Properties) != 2 {
+		t.Errorf("Expected an output schema derived from the result, got %+v", definition.OutputSchema)
+	}
+}
+
+func TestNewToolFromFunc_Execute(t *testing.T) {
+	tool, err := NewToolFromFunc("convert_temperature", "Converts temperatures", convert)
+	if err != nil {
+		t.Fatalf("NewToolFromFunc failed: %v", err)
+	}
+
+	result, err := tool.Execute(context.Background(), map[string]interface{}{"value": 100.0, "from": "celsius"})
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if structured, ok := result.StructuredContent.(convertResult); !ok || structured.Value != 212 {
+		t.Errorf("Expected 212 fahrenheit as structured content, got %#v", result.StructuredContent)
+	}
+	if !strings.Contains(result.Content[0].Text, "212") {
+		t.Errorf("Expected the result as text too, got %q", result.Content[0].Text)
+	}
+
+	// Arguments are validated against the derived schema
+	_, err = tool.Execute(context.Background(), map[string]interface{}{"value": 1.0, "from": "kelvin"})
+	if err == nil || !strings.Contains(err.Error(), "must be one of") {
+		t.Errorf("Expected an enum error, got %v", err)
+	}
+	_, err = tool.Execute(context.Background(), map[string]interface{}{"from": "celsius"})
+	var info *ErrorInfo
+	if !errors.As(err, &info) || info.Code != InvalidParams {
+		t.Errorf("Expected a missing parameter error, got %v", err)
+	}
+}
+
+func TestNewToolFromFunc_Results(t *testing.T) {
+	type noArgs struct{}
+
+	text, err := NewToolFromFunc("hello", "Says hello", func(ctx context.Context, args noArgs) (string, error) {
+		return "hello", nil
+	})
+	if err != nil {
+		t.Fatalf("NewToolFromFunc failed: %v", err)
+	}
+	if text.Definition().OutputSchema != nil {
+		t.Error("Expected no output schema for a text result")
+	}
+	result, err := text.Execute(context.Background(), nil)
+	if err != nil || result.Content[0].Text != "hello" || result.StructuredContent != nil {
+		t.Errorf("Expected a plain text result, got %+v, %v", result, err)
+	}
+
+	failing, _ := NewToolFromFunc("fail", "Fails", func(ctx context.Context, args noArgs) (*CallToolResult, error) {
+		return nil, errors.New("boom")
+	})
+	if _, err := failing.Execute(context.Background(), nil); err == nil || err.Error() != "boom" {
+		t.Errorf("Expected the function's error, got %v", err)
+	}
+}
+
+func TestNewToolFromFunc_RejectsUnsupportedArgs(t *testing.T) {
+	if _, err := NewToolFromFunc("bad", "", func(ctx context.Context, args string) (string, error) { return args, nil }); err == nil {
+		t.Error("Expected non-struct arguments to be rejected")
+	}
+
+	type channelArgs struct {
+		Events chan string `json:"events"`
+	}
+	if _, err := NewToolFromFunc("bad", "", func(ctx context.Context, args channelArgs) (string, error) { return "", nil }); err == nil {
+		t.Error("Expected a channel field to be rejected")
+	}
+
+	type node struct {
+		Children []node `json:"children"`
+	}
+	if _, err := NewToolFromFunc("bad", "", func(ctx context.Context, args node) (string, error) { return "", nil }); err == nil {
+		t.Error("Expected a recursive type to be rejected")
+	}
+}
+
+func TestBaseHandler_RegisterToolFunc(t *testing.T) {
+	h := NewBaseHandler(ServerInfo{Name: "test", Version: "1.0.0"}, ServerCapabilities{})
+	err := h.RegisterToolFunc(&Tool{
+		Name:        "greet",
+		Description: "Greets someone",
+		InputSchema: ToolSchema{Type: "object", Properties: map[string]interface{}{"name": map[string]interface{}{"type": "string"}}},
+	}, func(ctx context.Context, params map[string]interface{}) (*CallToolResult, error) {
+		return &CallToolResult{Content: []Content{NewTextContent("Hello, " + params["name"].(string))}}, nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to register tool: %v", err)
+	}
+	h.HandleMessage(context.Background(), NewNotification("initialized", nil))
+
+	tools, _ := h.ListTools()
+	if len(tools) != 1 || tools[0].Description != "Greets someone" {
+		t.Fatalf("Expected the definition to be listed as given, got %+v", tools)
+	}
+	result, err := h.CallTool(context.Background(), &CallToolParams{Name: "greet", Arguments: map[string]interface{}{"name": "Ada"}})
+	if err != nil || result.Content[0].Text != "Hello, Ada" {
+		t.Errorf("Unexpected result %+v, %v", result, err)
+	}
+
+	if err := h.RegisterToolFunc(&Tool{Name: "broken"}, nil); err == nil {
+		t.Error("Expected a nil function to be rejected")
+	}
+	if err := h.RegisterToolFunc(nil, func(ctx context.Context, params map[string]interface{}) (*CallToolResult, error) {
+		return nil, nil
+	}); err == nil {
+		t.Error("Expected a missing definition to be rejected")
+	}
+}
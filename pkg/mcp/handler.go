@@ -3,6 +3,9 @@ package mcp
 import (
 	"context"
 	"fmt"
+	"sync"
+
+	"github.com/chongliujia/mcp-go-template/pkg/utils"
 )
 
 // Handler defines the interface for MCP request handlers
@@ -10,23 +13,40 @@ type Handler interface {
 	HandleMessage(ctx context.Context, message *Message) (*Message, error)
 	Initialize(params *InitializeParams) (*InitializeResult, error)
 	ListTools() ([]*Tool, error)
-	CallTool(params *CallToolParams) (*CallToolResult, error)
+	CallTool(ctx context.Context, params *CallToolParams) (*CallToolResult, error)
 	ListResources() ([]*Resource, error)
-	ReadResource(params *ReadResourceParams) (*ReadResourceResult, error)
+	ReadResource(ctx context.Context, params *ReadResourceParams) (*ReadResourceResult, error)
 	ListPrompts() ([]*Prompt, error)
-	GetPrompt(params *GetPromptParams) (*GetPromptResult, error)
+	GetPrompt(ctx context.Context, params *GetPromptParams) (*GetPromptResult, error)
 }
 
 // BaseHandler provides a base implementation of the Handler interface
 type BaseHandler struct {
 	serverInfo   ServerInfo
 	capabilities ServerCapabilities
-	tools        map[string]ToolHandler
-	resources    map[string]ResourceHandler
-	prompts      map[string]PromptHandler
+	// toolsMu guards the fields below that are not registries, which lock
+	// themselves, so registration can go on while requests are served
+	toolsMu      sync.RWMutex
+	tools        ToolRegistry
+	disabled     map[string]bool   // tools turned off by DisableTool
+	aliases      map[string]string // alias -> tool, set by AliasTool
+	resources    ResourceRegistry
+	templates    []*registeredTemplate
+	conversions  []ResourceConversion
+	prompts      PromptRegistry
 	initialized  bool
+	hooks        hookSet
+	access       accessRules
+	middleware   []Middleware
+	notifier     Notifier
+	logger       utils.Logger
+	pageSize     int
+	maxBlobBytes int64
 }
 
+// Notifier delivers a server-initiated notification to connected clients
+type Notifier func(notification *Message)
+
 // ToolHandler defines the interface for tool implementations
 type ToolHandler interface {
 	Definition() *Tool
@@ -45,57 +65,160 @@ type PromptHandler interface {
 	Generate(ctx context.Context, params map[string]interface{}) (*GetPromptResult, error)
 }
 
+// PromptArgumentValidator is implemented by prompts that check their own
+// arguments, such as one serving variants that declare different
+// arguments. GetPrompt passes their arguments to Generate as given.
+type PromptArgumentValidator interface {
+	ValidatesArguments() bool
+}
+
 // NewBaseHandler creates a new BaseHandler with the given server info and capabilities
 func NewBaseHandler(serverInfo ServerInfo, capabilities ServerCapabilities) *BaseHandler {
-	return &BaseHandler{
+	return NewBaseHandlerWithRegistries(serverInfo, capabilities, Registries{})
+}
+
+// NewBaseHandlerWithRegistries creates a BaseHandler that serves the tools,
+// resources and prompts held in registries. Changes made through the
+// registries directly are announced to clients like those made through the
+// handler.
+func NewBaseHandlerWithRegistries(serverInfo ServerInfo, capabilities ServerCapabilities, registries Registries) *BaseHandler {
+	if registries.Tools == nil {
+		registries.Tools = NewToolRegistry()
+	}
+	if registries.Resources == nil {
+		registries.Resources = NewResourceRegistry()
+	}
+	if registries.Prompts == nil {
+		registries.Prompts = NewPromptRegistry()
+	}
+
+	h := &BaseHandler{
 		serverInfo:   serverInfo,
 		capabilities: capabilities,
-		tools:        make(map[string]ToolHandler),
-		resources:    make(map[string]ResourceHandler),
-		prompts:      make(map[string]PromptHandler),
+		tools:        registries.Tools,
+		resources:    registries.Resources,
+		prompts:      registries.Prompts,
+		logger:       utils.DefaultLogger(),
 		initialized:  false,
 	}
+	h.tools.OnChange(h.notifyToolsChanged)
+	h.resources.OnChange(h.notifyResourcesChanged)
+	h.prompts.OnChange(h.notifyPromptsChanged)
+	return h
 }
 
 // RegisterTool registers a tool handler
 func (h *BaseHandler) RegisterTool(handler ToolHandler) error {
-	tool := handler.Definition()
-	if tool == nil {
-		return fmt.Errorf("tool definition cannot be nil")
+	return h.tools.Register(handler)
+}
+
+// UnregisterTool removes a registered tool handler
+func (h *BaseHandler) UnregisterTool(name string) error {
+	if _, err := h.tools.Get(name); err != nil {
+		return ToolNotFoundError(name)
+	}
+
+	h.toolsMu.Lock()
+	delete(h.disabled, name)
+	h.toolsMu.Unlock()
+	return h.tools.Unregister(name)
+}
+
+// SetNotifier sets where notifications about handler changes, such as
+// notifications/tools/list_changed, are sent
+func (h *BaseHandler) SetNotifier(notifier Notifier) {
+	h.toolsMu.Lock()
+	defer h.toolsMu.Unlock()
+	h.notifier = notifier
+}
+
+// SetLogger sets the logger tool calls log through, in place of the
+// global one; tools find it in CallContext.Logger
+func (h *BaseHandler) SetLogger(logger utils.Logger) {
+	h.toolsMu.Lock()
+	defer h.toolsMu.Unlock()
+	h.logger = logger
+}
+
+// notifyToolsChanged tells clients the tool list changed when the tools
+// capability advertises listChanged
+func (h *BaseHandler) notifyToolsChanged() {
+	if h.capabilities.Tools != nil && h.capabilities.Tools.ListChanged {
+		h.notify(NewNotification("notifications/tools/list_changed", nil))
 	}
-	if tool.Name == "" {
-		return fmt.Errorf("tool name cannot be empty")
+}
+
+// notifyResourcesChanged tells clients the resource list changed when the
+// resources capability advertises listChanged
+func (h *BaseHandler) notifyResourcesChanged() {
+	if h.capabilities.Resources != nil && h.capabilities.Resources.ListChanged {
+		h.notify(NewNotification("notifications/resources/list_changed", nil))
+	}
+}
+
+// notifyPromptsChanged tells clients the prompt list changed when the
+// prompts capability advertises listChanged
+func (h *BaseHandler) notifyPromptsChanged() {
+	if h.capabilities.Prompts != nil && h.capabilities.Prompts.ListChanged {
+		h.notify(NewNotification("notifications/prompts/list_changed", nil))
+	}
+}
+
+// notify sends a notification through the notifier, if one is set
+func (h *BaseHandler) notify(notification *Message) {
+	h.toolsMu.RLock()
+	notifier := h.notifier
+	h.toolsMu.RUnlock()
+
+	if notifier != nil {
+		notifier(notification)
 	}
-	h.tools[tool.Name] = handler
-	return nil
 }
 
 // RegisterResource registers a resource handler
 func (h *BaseHandler) RegisterResource(handler ResourceHandler) error {
-	resource := handler.Definition()
-	if resource == nil {
-		return fmt.Errorf("resource definition cannot be nil")
+	if err := h.resources.Register(handler); err != nil {
+		return err
 	}
-	if resource.URI == "" {
-		return fmt.Errorf("resource URI cannot be empty")
+
+	if updater, ok := handler.(ResourceUpdater); ok {
+		updater.OnUpdate(h.NotifyResourceUpdated)
 	}
-	h.resources[resource.URI] = handler
 	return nil
 }
 
-// RegisterPrompt registers a prompt handler
-func (h *BaseHandler) RegisterPrompt(handler PromptHandler) error {
-	prompt := handler.Definition()
-	if prompt == nil {
-		return fmt.Errorf("prompt definition cannot be nil")
+// UnregisterResource removes a registered resource handler. A resource
+// that reports its own updates is told to stop sending them here.
+func (h *BaseHandler) UnregisterResource(uri string) error {
+	handler, err := h.resources.Get(uri)
+	if err != nil {
+		return ResourceNotFoundError(uri)
 	}
-	if prompt.Name == "" {
-		return fmt.Errorf("prompt name cannot be empty")
+	if err := h.resources.Unregister(uri); err != nil {
+		return err
+	}
+
+	if updater, ok := handler.(ResourceUpdater); ok {
+		updater.OnUpdate(func(string) {})
 	}
-	h.prompts[prompt.Name] = handler
 	return nil
 }
 
+// RegisterPrompt registers a prompt handler
+func (h *BaseHandler) RegisterPrompt(handler PromptHandler) error {
+	return h.prompts.Register(handler)
+}
+
+// UnregisterPrompt removes a registered prompt handler. Versioned prompts
+// are registered under their base name, so removing research_prompt removes
+// all of its versions; a name such as research_prompt:v1 is not found.
+func (h *BaseHandler) UnregisterPrompt(name string) error {
+	if _, err := h.prompts.Get(name); err != nil {
+		return PromptNotFoundError(name)
+	}
+	return h.prompts.Unregister(name)
+}
+
 // HandleMessage handles an incoming MCP message
 func (h *BaseHandler) HandleMessage(ctx context.Context, message *Message) (*Message, error) {
 	if message == nil {
@@ -103,7 +226,14 @@ func (h *BaseHandler) HandleMessage(ctx context.Context, message *Message) (*Mes
 	}
 
 	if message.IsRequest() {
-		return h.handleRequest(ctx, message)
+		ctx, done := trackRequest(ctx, message.ID)
+		defer done()
+		ctx = withRequestID(ctx, message.ID)
+
+		ctx, span := startRequestSpan(ctx, message)
+		response, err := h.dispatchRequest(ctx, message)
+		endRequestSpan(span, response, err)
+		return response, err
 	}
 
 	if message.IsNotification() {
@@ -124,21 +254,34 @@ func (h *BaseHandler) handleRequest(ctx context.Context, message *Message) (*Mes
 		
 		result, err := h.Initialize(&params)
 		if err != nil {
-			return NewErrorResponse(message.ID, InternalError, "initialization failed", err.Error()), nil
+			return NewErrorResponseFromError(message.ID, err, InternalError, "initialization failed"), nil
+		}
+		if session, ok := SessionFromContext(ctx); ok {
+			session.setClient(&params, result.ProtocolVersion)
 		}
+		h.runInitializeHooks(ctx, &params, result)
 		
 		return NewSuccessResponse(message.ID, result), nil
 
 	case "tools/list":
+		var params ListToolsParams
+		if message.Params != nil {
+			if err := message.UnmarshalParams(&params); err != nil {
+				return NewErrorResponse(message.ID, InvalidParams, "invalid tools list params", err.Error()), nil
+			}
+		}
+
 		tools, err := h.ListTools()
 		if err != nil {
-			return NewErrorResponse(message.ID, InternalError, "failed to list tools", err.Error()), nil
+			return NewErrorResponseFromError(message.ID, err, InternalError, "failed to list tools"), nil
 		}
-		
-		result := map[string]interface{}{
-			"tools": tools,
+
+		tools = h.accessibleTools(ctx, tools)
+		page, next, err := paginate(FilterTools(tools, params.Category, params.Tags), toolName, params.Cursor, h.pageSizeLimit())
+		if err != nil {
+			return NewErrorResponseFromError(message.ID, err, InvalidParams, "invalid tools list params"), nil
 		}
-		return NewSuccessResponse(message.ID, result), nil
+		return NewSuccessResponse(message.ID, listResult("tools", page, next)), nil
 
 	case "tools/call":
 		var params CallToolParams
@@ -146,23 +289,51 @@ func (h *BaseHandler) handleRequest(ctx context.Context, message *Message) (*Mes
 			return NewErrorResponse(message.ID, InvalidParams, "invalid tool call params", err.Error()), nil
 		}
 		
-		result, err := h.CallTool(&params)
+		result, err := h.CallTool(ctx, &params)
 		if err != nil {
-			return NewErrorResponse(message.ID, InternalError, "tool call failed", err.Error()), nil
+			return NewErrorResponseFromError(message.ID, err, InternalError, "tool call failed"), nil
 		}
 		
 		return NewSuccessResponse(message.ID, result), nil
 
 	case "resources/list":
+		var params PaginatedParams
+		if message.Params != nil {
+			if err := message.UnmarshalParams(&params); err != nil {
+				return NewErrorResponse(message.ID, InvalidParams, "invalid resources list params", err.Error()), nil
+			}
+		}
+
 		resources, err := h.ListResources()
 		if err != nil {
-			return NewErrorResponse(message.ID, InternalError, "failed to list resources", err.Error()), nil
+			return NewErrorResponseFromError(message.ID, err, InternalError, "failed to list resources"), nil
 		}
-		
-		result := map[string]interface{}{
-			"resources": resources,
+		resources = append(resources, h.templateResources(ctx)...)
+
+		page, next, err := paginate(h.accessibleResources(ctx, resources), resourceURI, params.Cursor, h.pageSizeLimit())
+		if err != nil {
+			return NewErrorResponseFromError(message.ID, err, InvalidParams, "invalid resources list params"), nil
+		}
+		return NewSuccessResponse(message.ID, listResult("resources", page, next)), nil
+
+	case "resources/templates/list":
+		var params PaginatedParams
+		if message.Params != nil {
+			if err := message.UnmarshalParams(&params); err != nil {
+				return NewErrorResponse(message.ID, InvalidParams, "invalid resource templates list params", err.Error()), nil
+			}
+		}
+
+		templates, err := h.ListResourceTemplates()
+		if err != nil {
+			return NewErrorResponseFromError(message.ID, err, InternalError, "failed to list resource templates"), nil
 		}
-		return NewSuccessResponse(message.ID, result), nil
+
+		page, next, err := paginate(h.accessibleTemplates(ctx, templates), resourceTemplateURI, params.Cursor, h.pageSizeLimit())
+		if err != nil {
+			return NewErrorResponseFromError(message.ID, err, InvalidParams, "invalid resource templates list params"), nil
+		}
+		return NewSuccessResponse(message.ID, listResult("resourceTemplates", page, next)), nil
 
 	case "resources/read":
 		var params ReadResourceParams
@@ -170,23 +341,72 @@ func (h *BaseHandler) handleRequest(ctx context.Context, message *Message) (*Mes
 			return NewErrorResponse(message.ID, InvalidParams, "invalid resource read params", err.Error()), nil
 		}
 		
-		result, err := h.ReadResource(&params)
+		result, err := h.ReadResource(ctx, &params)
 		if err != nil {
-			return NewErrorResponse(message.ID, InternalError, "resource read failed", err.Error()), nil
+			return NewErrorResponseFromError(message.ID, err, InternalError, "resource read failed"), nil
 		}
 		
 		return NewSuccessResponse(message.ID, result), nil
 
+	case "resources/subscribe", "resources/unsubscribe":
+		var params SubscribeParams
+		if err := message.UnmarshalParams(&params); err != nil {
+			return NewErrorResponse(message.ID, InvalidParams, "invalid resource subscription params", err.Error()), nil
+		}
+
+		if err := h.setSubscription(ctx, &params, message.Method == "resources/subscribe"); err != nil {
+			return NewErrorResponseFromError(message.ID, err, InternalError, "resource subscription failed"), nil
+		}
+
+		return NewSuccessResponse(message.ID, map[string]interface{}{}), nil
+
+	case "completion/complete":
+		var params CompleteParams
+		if err := message.UnmarshalParams(&params); err != nil {
+			return NewErrorResponse(message.ID, InvalidParams, "invalid completion params", err.Error()), nil
+		}
+
+		result, err := h.complete(ctx, &params)
+		if err != nil {
+			return NewErrorResponseFromError(message.ID, err, InternalError, "completion failed"), nil
+		}
+
+		return NewSuccessResponse(message.ID, result), nil
+
+	case "ping":
+		// Liveness check, answered in any state
+		return NewSuccessResponse(message.ID, map[string]interface{}{}), nil
+
+	case "logging/setLevel":
+		var params SetLevelParams
+		if err := message.UnmarshalParams(&params); err != nil {
+			return NewErrorResponse(message.ID, InvalidParams, "invalid logging/setLevel params", err.Error()), nil
+		}
+
+		if err := h.setLogLevel(ctx, params.Level); err != nil {
+			return NewErrorResponseFromError(message.ID, err, InternalError, "setting log level failed"), nil
+		}
+
+		return NewSuccessResponse(message.ID, map[string]interface{}{}), nil
+
 	case "prompts/list":
+		var params PaginatedParams
+		if message.Params != nil {
+			if err := message.UnmarshalParams(&params); err != nil {
+				return NewErrorResponse(message.ID, InvalidParams, "invalid prompts list params", err.Error()), nil
+			}
+		}
+
 		prompts, err := h.ListPrompts()
 		if err != nil {
-			return NewErrorResponse(message.ID, InternalError, "failed to list prompts", err.Error()), nil
+			return NewErrorResponseFromError(message.ID, err, InternalError, "failed to list prompts"), nil
 		}
-		
-		result := map[string]interface{}{
-			"prompts": prompts,
+
+		page, next, err := paginate(prompts, promptName, params.Cursor, h.pageSizeLimit())
+		if err != nil {
+			return NewErrorResponseFromError(message.ID, err, InvalidParams, "invalid prompts list params"), nil
 		}
-		return NewSuccessResponse(message.ID, result), nil
+		return NewSuccessResponse(message.ID, listResult("prompts", page, next)), nil
 
 	case "prompts/get":
 		var params GetPromptParams
@@ -194,15 +414,16 @@ func (h *BaseHandler) handleRequest(ctx context.Context, message *Message) (*Mes
 			return NewErrorResponse(message.ID, InvalidParams, "invalid prompt get params", err.Error()), nil
 		}
 		
-		result, err := h.GetPrompt(&params)
+		result, err := h.GetPrompt(ctx, &params)
 		if err != nil {
-			return NewErrorResponse(message.ID, InternalError, "prompt get failed", err.Error()), nil
+			return NewErrorResponseFromError(message.ID, err, InternalError, "prompt get failed"), nil
 		}
 		
 		return NewSuccessResponse(message.ID, result), nil
 
 	default:
-		return NewErrorResponse(message.ID, MethodNotFound, fmt.Sprintf("method '%s' not found", message.Method), nil), nil
+		info := MethodNotFoundError(message.Method)
+		return NewErrorResponse(message.ID, info.Code, info.Message, info.Data), nil
 	}
 }
 
@@ -211,11 +432,23 @@ func (h *BaseHandler) handleNotification(ctx context.Context, message *Message)
 	switch message.Method {
 	case "initialized":
 		// Client has completed initialization
-		h.initialized = true
+		if session, ok := SessionFromContext(ctx); ok {
+			session.markInitialized()
+		} else {
+			h.toolsMu.Lock()
+			h.initialized = true
+			h.toolsMu.Unlock()
+		}
 		return nil, nil
 		
 	case "notifications/cancelled":
-		// Handle request cancellation
+		var params CancelledParams
+		if err := message.UnmarshalParams(&params); err != nil || params.RequestID == nil {
+			return nil, nil
+		}
+		if session, ok := SessionFromContext(ctx); ok {
+			session.cancelRequest(params.RequestID)
+		}
 		return nil, nil
 		
 	default:
@@ -226,41 +459,56 @@ func (h *BaseHandler) handleNotification(ctx context.Context, message *Message)
 
 // Initialize handles the initialize request
 func (h *BaseHandler) Initialize(params *InitializeParams) (*InitializeResult, error) {
-	if params.ProtocolVersion != MCPVersion {
-		return nil, fmt.Errorf("unsupported protocol version: %s", params.ProtocolVersion)
+	version, ok := NegotiateProtocolVersion(params.ProtocolVersion)
+	if !ok {
+		return nil, UnsupportedVersionError(params.ProtocolVersion, SupportedProtocolVersions...)
 	}
 
 	result := &InitializeResult{
-		ProtocolVersion: MCPVersion,
-		Capabilities:    h.capabilities,
+		ProtocolVersion: version,
+		Capabilities:    h.advertisedCapabilities(),
 		ServerInfo:      h.serverInfo,
 	}
 
 	return result, nil
 }
 
-// ListTools returns all registered tools
+// ListTools returns all registered tools that are not disabled
 func (h *BaseHandler) ListTools() ([]*Tool, error) {
-	tools := make([]*Tool, 0, len(h.tools))
-	for _, handler := range h.tools {
-		tools = append(tools, handler.Definition())
-	}
-	return tools, nil
+	return h.enabledTools(h.tools.List()), nil
 }
 
-// CallTool executes a tool with the given parameters
-func (h *BaseHandler) CallTool(params *CallToolParams) (*CallToolResult, error) {
-	if !h.initialized {
-		return nil, fmt.Errorf("handler not initialized")
+// CallTool executes a tool on behalf of the session in ctx, if any. The
+// tool runs with ctx, so cancelling it or its deadline reaches the tool.
+func (h *BaseHandler) CallTool(ctx context.Context, params *CallToolParams) (*CallToolResult, error) {
+	if !h.isInitialized(ctx) {
+		return nil, NotInitializedError()
 	}
-
-	handler, exists := h.tools[params.Name]
-	if !exists {
-		return nil, fmt.Errorf("tool '%s' not found", params.Name)
+	// Aliases are allowed exactly when the tool they name is
+	if !h.accessRule(ctx).allowsTool(h.toolTarget(params.Name)) {
+		return nil, ToolNotFoundError(params.Name)
 	}
 
-	ctx := context.Background()
-	result, err := handler.Execute(ctx, params.Arguments)
+	handler, err := h.enabledTool(params.Name)
+	if err != nil {
+		return nil, err
+	}
+	handler = h.withMiddleware(handler)
+
+	ctx = withProgress(ctx, params.Meta)
+	h.toolsMu.RLock()
+	logger := h.logger
+	h.toolsMu.RUnlock()
+	ctx = withCallContext(ctx, logger, params.Name)
+	if deprecation := handler.Definition().Deprecated; deprecation != nil {
+		CallContextFromContext(ctx).Logger.WithField("replacement", deprecation.Replacement).Warn("Deprecated tool called")
+	}
+	ctx, span := startToolSpan(ctx, params.Name)
+	result, err, rejected := h.executeWithHooks(ctx, handler, params.Name, params.Arguments)
+	endToolSpan(span, result, err)
+	if rejected != nil {
+		return nil, rejected
+	}
 	if err != nil {
 		return &CallToolResult{
 			Content: []Content{{
@@ -271,62 +519,111 @@ func (h *BaseHandler) CallTool(params *CallToolParams) (*CallToolResult, error)
 		}, nil
 	}
 
-	return result, nil
+	if result != nil {
+		if err := validateContents(result.Content); err != nil {
+			return &CallToolResult{
+				Content: []Content{{
+					Type: "text",
+					Text: fmt.Sprintf("Tool returned invalid content: %v", err),
+				}},
+				IsError: true,
+			}, nil
+		}
+		if err := checkContentBlobs(h.blobLimit(), result.Content); err != nil {
+			return &CallToolResult{
+				Content: []Content{{
+					Type: "text",
+					Text: fmt.Sprintf("Tool result too large: %v", err),
+				}},
+				IsError: true,
+			}, nil
+		}
+	}
+
+	return structuredForClient(ctx, result), nil
 }
 
 // ListResources returns all registered resources
 func (h *BaseHandler) ListResources() ([]*Resource, error) {
-	resources := make([]*Resource, 0, len(h.resources))
-	for _, handler := range h.resources {
-		resources = append(resources, handler.Definition())
-	}
-	return resources, nil
+	return h.resources.List(), nil
 }
 
-// ReadResource reads a resource with the given URI
-func (h *BaseHandler) ReadResource(params *ReadResourceParams) (*ReadResourceResult, error) {
-	if !h.initialized {
-		return nil, fmt.Errorf("handler not initialized")
+// ReadResource reads a resource on behalf of the session in ctx, if any
+func (h *BaseHandler) ReadResource(ctx context.Context, params *ReadResourceParams) (*ReadResourceResult, error) {
+	if !h.isInitialized(ctx) {
+		return nil, NotInitializedError()
 	}
 
-	handler, exists := h.resources[params.URI]
-	if !exists {
-		return nil, fmt.Errorf("resource '%s' not found", params.URI)
+	handler, exists := h.lookupResource(params.URI)
+	if !exists || !h.accessRule(ctx).allowsResource(params.URI) {
+		return nil, ResourceNotFoundError(params.URI)
 	}
 
-	ctx := context.Background()
-	return handler.Read(ctx, params.URI)
+	result, err := handler.Read(ctx, params.URI)
+	if err != nil || result == nil {
+		return result, err
+	}
+	if params.Accept != "" {
+		contents, err := h.negotiateContents(ctx, params.URI, params.Accept, result.Contents)
+		if err != nil {
+			return nil, err
+		}
+		negotiated := *result
+		negotiated.Contents = contents
+		result = &negotiated
+	}
+	if err := checkResourceBlobs(h.blobLimit(), result.Contents); err != nil {
+		return nil, err
+	}
+	return result, nil
 }
 
 // ListPrompts returns all registered prompts
 func (h *BaseHandler) ListPrompts() ([]*Prompt, error) {
-	prompts := make([]*Prompt, 0, len(h.prompts))
-	for _, handler := range h.prompts {
-		prompts = append(prompts, handler.Definition())
-	}
-	return prompts, nil
+	return h.prompts.List(), nil
 }
 
-// GetPrompt generates a prompt with the given parameters
-func (h *BaseHandler) GetPrompt(params *GetPromptParams) (*GetPromptResult, error) {
-	if !h.initialized {
-		return nil, fmt.Errorf("handler not initialized")
+// GetPrompt generates a prompt on behalf of the session in ctx, if any
+func (h *BaseHandler) GetPrompt(ctx context.Context, params *GetPromptParams) (*GetPromptResult, error) {
+	if !h.isInitialized(ctx) {
+		return nil, NotInitializedError()
 	}
 
-	handler, exists := h.prompts[params.Name]
-	if !exists {
-		return nil, fmt.Errorf("prompt '%s' not found", params.Name)
+	handler, err := h.prompts.Get(params.Name)
+	if err != nil {
+		return nil, PromptNotFoundError(params.Name)
 	}
 
-	ctx := context.Background()
-	return handler.Generate(ctx, params.Arguments)
+	arguments := params.Arguments
+	if validator, ok := handler.(PromptArgumentValidator); !ok || !validator.ValidatesArguments() {
+		arguments, err = ValidatePromptArguments(params.Arguments, handler.Definition().Arguments)
+		if err != nil {
+			return nil, err
+		}
+	}
+	result, err := handler.Generate(ctx, arguments)
+	if err != nil || result == nil {
+		return result, err
+	}
+	return h.resolvePromptMessages(ctx, result)
 }
 
 // IsInitialized returns whether the handler has been initialized
 func (h *BaseHandler) IsInitialized() bool {
+	h.toolsMu.RLock()
+	defer h.toolsMu.RUnlock()
 	return h.initialized
 }
 
+// isInitialized reports whether the session in ctx has completed
+// initialization; requests without a session use the handler-wide flag
+func (h *BaseHandler) isInitialized(ctx context.Context) bool {
+	if session, ok := SessionFromContext(ctx); ok {
+		return session.IsInitialized()
+	}
+	return h.IsInitialized()
+}
+
 // GetServerInfo returns the server information
 func (h *BaseHandler) GetServerInfo() ServerInfo {
 	return h.serverInfo
@@ -0,0 +1,432 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus/hooks/test"
+
+	"github.com/chongliujia/mcp-go-template/pkg/utils"
+)
+
+func TestBaseHandler_NotifiesToolListChanged(t *testing.T) {
+	h := NewBaseHandler(ServerInfo{Name: "test", Version: "1.0.0"}, ServerCapabilities{
+		Tools: &ToolsCapability{ListChanged: true},
+	})
+
+	var methods []string
+	h.SetNotifier(func(notification *Message) {
+		methods = append(methods, notification.Method)
+	})
+
+	if err := h.RegisterTool(counterTool{}); err != nil {
+		t.Fatalf("Failed to register tool: %v", err)
+	}
+	if err := h.UnregisterTool("counter"); err != nil {
+		t.Fatalf("Failed to unregister tool: %v", err)
+	}
+	h.UnregisterTool("counter")
+
+	if len(methods) != 2 {
+		t.Fatalf("Expected 2 notifications, got %d", len(methods))
+	}
+	for _, method := range methods {
+		if method != "notifications/tools/list_changed" {
+			t.Errorf("Unexpected notification %q", method)
+		}
+	}
+}
+
+func TestBaseHandler_UnregistersResourcesAndPrompts(t *testing.T) {
+	h := NewBaseHandler(ServerInfo{Name: "test", Version: "1.0.0"}, ServerCapabilities{
+		Resources: &ResourcesCapability{Subscribe: true, ListChanged: true},
+		Prompts:   &PromptsCapability{ListChanged: true},
+	})
+
+	var methods []string
+	h.SetNotifier(func(notification *Message) {
+		methods = append(methods, notification.Method)
+	})
+
+	resource := &changingResource{}
+	h.RegisterResource(resource)
+	h.RegisterPrompt(staticPrompt("summary"))
+	methods = nil
+
+	if err := h.UnregisterResource("test://changing"); err != nil {
+		t.Fatalf("Failed to unregister resource: %v", err)
+	}
+	if err := h.UnregisterPrompt("summary"); err != nil {
+		t.Fatalf("Failed to unregister prompt: %v", err)
+	}
+	want := []string{"notifications/resources/list_changed", "notifications/prompts/list_changed"}
+	if len(methods) != 2 || methods[0] != want[0] || methods[1] != want[1] {
+		t.Errorf("Expected %v, got %v", want, methods)
+	}
+
+	if resources, _ := h.ListResources(); len(resources) != 0 {
+		t.Errorf("Expected no resources, got %d", len(resources))
+	}
+	if prompts, _ := h.ListPrompts(); len(prompts) != 0 {
+		t.Errorf("Expected no prompts, got %d", len(prompts))
+	}
+
+	// Updates from the removed resource no longer reach clients
+	methods = nil
+	resource.update("test://changing")
+	if len(methods) != 0 {
+		t.Errorf("Expected no notification from an unregistered resource, got %v", methods)
+	}
+
+	if err := h.UnregisterResource("test://changing"); err == nil {
+		t.Error("Expected an error for an unknown resource")
+	}
+	if info, ok := AsErrorInfo(h.UnregisterPrompt("summary")); !ok || info.Code != PromptNotFound {
+		t.Error("Expected a not found error for an unknown prompt")
+	}
+}
+
+func TestBaseHandler_NoToolListChangedWithoutCapability(t *testing.T) {
+	h := NewBaseHandler(ServerInfo{Name: "test", Version: "1.0.0"}, ServerCapabilities{
+		Tools: &ToolsCapability{},
+	})
+
+	notified := false
+	h.SetNotifier(func(*Message) { notified = true })
+	h.RegisterTool(counterTool{})
+
+	if notified {
+		t.Error("Expected no notification when listChanged is not advertised")
+	}
+}
+
+// changingResource is a resource that reports its own updates
+type changingResource struct {
+	update func(uri string)
+}
+
+func (r *changingResource) Definition() *Resource {
+	return &Resource{URI: "test://changing", Name: "changing"}
+}
+
+func (r *changingResource) Read(ctx context.Context, uri string) (*ReadResourceResult, error) {
+	return &ReadResourceResult{}, nil
+}
+
+func (r *changingResource) OnUpdate(update func(uri string)) {
+	r.update = update
+}
+
+func TestBaseHandler_ResourceSubscriptions(t *testing.T) {
+	h := NewBaseHandler(ServerInfo{Name: "test", Version: "1.0.0"}, ServerCapabilities{
+		Resources: &ResourcesCapability{Subscribe: true},
+	})
+	resource := &changingResource{}
+	if err := h.RegisterResource(resource); err != nil {
+		t.Fatalf("Failed to register resource: %v", err)
+	}
+
+	var updated []string
+	h.SetNotifier(func(notification *Message) {
+		var params SubscribeParams
+		notification.UnmarshalParams(&params)
+		updated = append(updated, params.URI)
+	})
+
+	session := NewSession("subscriber")
+	ctx := WithSession(context.Background(), session)
+	h.HandleMessage(ctx, NewRequest(1, "initialize", InitializeParams{ProtocolVersion: MCPVersion}))
+	h.HandleMessage(ctx, NewNotification("initialized", nil))
+
+	response, _ := h.HandleMessage(ctx, NewRequest(2, "resources/subscribe", SubscribeParams{URI: "test://missing"}))
+	if response.Error == nil || response.Error.Code != ResourceNotFound {
+		t.Errorf("Expected resource not found for an unknown URI, got %+v", response.Error)
+	}
+
+	response, _ = h.HandleMessage(ctx, NewRequest(3, "resources/subscribe", SubscribeParams{URI: "test://changing"}))
+	if response.Error != nil {
+		t.Fatalf("Subscribe failed: %v", response.Error.Message)
+	}
+	if !session.IsSubscribed("test://changing") {
+		t.Error("Expected session to be subscribed")
+	}
+
+	resource.update("test://changing")
+	if len(updated) != 1 || updated[0] != "test://changing" {
+		t.Errorf("Expected one update for test://changing, got %v", updated)
+	}
+
+	h.HandleMessage(ctx, NewRequest(4, "resources/unsubscribe", SubscribeParams{URI: "test://changing"}))
+	if session.IsSubscribed("test://changing") {
+		t.Error("Expected session to be unsubscribed")
+	}
+}
+
+func TestBaseHandler_SetLogLevel(t *testing.T) {
+	h := NewBaseHandler(ServerInfo{Name: "test", Version: "1.0.0"}, ServerCapabilities{
+		Logging: &LoggingCapability{},
+	})
+
+	session := NewSession("logging")
+	ctx := WithSession(context.Background(), session)
+	h.HandleMessage(ctx, NewRequest(1, "initialize", InitializeParams{ProtocolVersion: MCPVersion}))
+	h.HandleMessage(ctx, NewNotification("initialized", nil))
+
+	response, _ := h.HandleMessage(ctx, NewRequest(2, "logging/setLevel", SetLevelParams{Level: "verbose"}))
+	if response.Error == nil || response.Error.Code != InvalidParams {
+		t.Errorf("Expected invalid params for an unknown level, got %+v", response.Error)
+	}
+
+	response, _ = h.HandleMessage(ctx, NewRequest(3, "logging/setLevel", SetLevelParams{Level: LoggingLevelWarning}))
+	if response.Error != nil {
+		t.Fatalf("logging/setLevel failed: %v", response.Error.Message)
+	}
+
+	level := session.LogLevel()
+	if level.Includes(LoggingLevelInfo) {
+		t.Error("Expected warning level to exclude info messages")
+	}
+	if !level.Includes(LoggingLevelError) {
+		t.Error("Expected warning level to include error messages")
+	}
+}
+
+func TestBaseHandler_PingBeforeInitialize(t *testing.T) {
+	h := NewBaseHandler(ServerInfo{Name: "test", Version: "1.0.0"}, ServerCapabilities{})
+	ctx := WithSession(context.Background(), NewSession("pinger"))
+
+	response, err := h.HandleMessage(ctx, NewRequest(1, "ping", nil))
+	if err != nil {
+		t.Fatalf("ping failed: %v", err)
+	}
+	if response.Error != nil {
+		t.Errorf("Expected ping to succeed before initialization, got %v", response.Error.Message)
+	}
+}
+
+// progressTool reports two steps of progress
+type progressTool struct{}
+
+func (progressTool) Definition() *Tool {
+	return &Tool{Name: "progress", InputSchema: ToolSchema{Type: "object"}}
+}
+
+func (progressTool) Execute(ctx context.Context, params map[string]interface{}) (*CallToolResult, error) {
+	report := ProgressFromContext(ctx)
+	report(1, 2, "halfway")
+	report(2, 2, "")
+	return &CallToolResult{}, nil
+}
+
+func TestBaseHandler_ReportsProgress(t *testing.T) {
+	h := NewBaseHandler(ServerInfo{Name: "test", Version: "1.0.0"}, ServerCapabilities{})
+	if err := h.RegisterTool(progressTool{}); err != nil {
+		t.Fatalf("Failed to register tool: %v", err)
+	}
+
+	var reports []ProgressParams
+	session := NewSession("progress")
+	session.SetNotifier(func(notification *Message) {
+		reports = append(reports, notification.Params.(ProgressParams))
+	})
+	ctx := WithSession(context.Background(), session)
+	h.HandleMessage(ctx, NewRequest(1, "initialize", InitializeParams{ProtocolVersion: MCPVersion}))
+	h.HandleMessage(ctx, NewNotification("initialized", nil))
+
+	// Without a progress token nothing is reported
+	h.HandleMessage(ctx, NewRequest(2, "tools/call", CallToolParams{Name: "progress"}))
+	if len(reports) != 0 {
+		t.Fatalf("Expected no progress without a token, got %d reports", len(reports))
+	}
+
+	h.HandleMessage(ctx, NewRequest(3, "tools/call", CallToolParams{
+		Name: "progress",
+		Meta: &RequestMeta{ProgressToken: "call-3"},
+	}))
+	if len(reports) != 2 {
+		t.Fatalf("Expected 2 progress reports, got %d", len(reports))
+	}
+	if reports[0].ProgressToken != "call-3" || reports[0].Progress != 1 || reports[0].Message != "halfway" {
+		t.Errorf("Unexpected first report %+v", reports[0])
+	}
+}
+
+// blockingTool runs until its context is cancelled
+type blockingTool struct {
+	started chan struct{}
+}
+
+func (blockingTool) Definition() *Tool {
+	return &Tool{Name: "blocking", InputSchema: ToolSchema{Type: "object"}}
+}
+
+func (b blockingTool) Execute(ctx context.Context, params map[string]interface{}) (*CallToolResult, error) {
+	close(b.started)
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestBaseHandler_CancelsRequest(t *testing.T) {
+	h := NewBaseHandler(ServerInfo{Name: "test", Version: "1.0.0"}, ServerCapabilities{})
+	tool := blockingTool{started: make(chan struct{})}
+	if err := h.RegisterTool(tool); err != nil {
+		t.Fatalf("Failed to register tool: %v", err)
+	}
+
+	ctx := WithSession(context.Background(), NewSession("cancelling"))
+	h.HandleMessage(ctx, NewRequest(1, "initialize", InitializeParams{ProtocolVersion: MCPVersion}))
+	h.HandleMessage(ctx, NewNotification("initialized", nil))
+
+	responses := make(chan *Message, 1)
+	go func() {
+		response, _ := h.HandleMessage(ctx, NewRequest("call-1", "tools/call", CallToolParams{Name: "blocking"}))
+		responses <- response
+	}()
+
+	<-tool.started
+	h.HandleMessage(ctx, NewNotification("notifications/cancelled", CancelledParams{RequestID: "call-1", Reason: "user aborted"}))
+
+	select {
+	case response := <-responses:
+		result, ok := response.Result.(*CallToolResult)
+		if !ok || !result.IsError {
+			t.Errorf("Expected an error result for the cancelled call, got %+v", response.Result)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Cancelled tool call did not return")
+	}
+}
+
+func TestBaseHandler_CallToolUsesCallerContext(t *testing.T) {
+	h := NewBaseHandler(ServerInfo{Name: "test", Version: "1.0.0"}, ServerCapabilities{})
+	tool := blockingTool{started: make(chan struct{})}
+	h.RegisterTool(tool)
+	h.HandleMessage(context.Background(), NewNotification("initialized", nil))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	result, err := h.CallTool(ctx, &CallToolParams{Name: "blocking"})
+	if err != nil || result == nil || !result.IsError {
+		t.Errorf("Expected the deadline to end the call with an error result, got %+v, %v", result, err)
+	}
+}
+
+// deprecatedTool is a tool replaced by web_search
+type deprecatedTool struct{ namedTool }
+
+func (t deprecatedTool) Definition() *Tool {
+	tool := t.namedTool.Definition()
+	tool.Deprecated = &ToolDeprecation{Message: "scraping was unreliable", Replacement: "web_search"}
+	return tool
+}
+
+func TestBaseHandler_DeprecatedTool(t *testing.T) {
+	logger, logs := test.NewNullLogger()
+	h := NewBaseHandler(ServerInfo{Name: "test", Version: "1.0.0"}, ServerCapabilities{})
+	h.SetLogger(utils.NewLogger(logger))
+	h.RegisterTool(deprecatedTool{namedTool("scrape")})
+	h.HandleMessage(context.Background(), NewNotification("initialized", nil))
+
+	tools, _ := h.ListTools()
+	if len(tools) != 1 || tools[0].Deprecated == nil || tools[0].Deprecated.Replacement != "web_search" {
+		t.Fatalf("Expected the deprecation to be listed, got %+v", tools)
+	}
+	if got := tools[0].Deprecated.String(); got != "Deprecated: scraping was unreliable; use web_search instead" {
+		t.Errorf("Unexpected notice %q", got)
+	}
+
+	result, err := h.CallTool(context.Background(), &CallToolParams{Name: "scrape"})
+	if err != nil || result.IsError {
+		t.Fatalf("Expected the deprecated tool to still work, got %+v, %v", result, err)
+	}
+	if entry := logs.LastEntry(); entry == nil || entry.Message != "Deprecated tool called" || entry.Data["replacement"] != "web_search" {
+		t.Errorf("Expected a warning to be logged, got %v", entry)
+	}
+}
+
+func TestBaseHandler_ConcurrentRegistrationAndDispatch(t *testing.T) {
+	h := NewBaseHandler(ServerInfo{Name: "test", Version: "1.0.0"}, ServerCapabilities{})
+	h.RegisterTool(namedTool("stable"))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				name := fmt.Sprintf("tool-%d-%d", i, j)
+				h.RegisterTool(namedTool(name))
+				h.RegisterPrompt(staticPrompt(name))
+				h.RegisterResourceTemplate(analysisTemplate{})
+				h.DisableTool(name)
+				h.UnregisterTool(name)
+			}
+		}(i)
+		go func(i int) {
+			defer wg.Done()
+			session := NewSession(fmt.Sprintf("s%d", i))
+			ctx := WithSession(context.Background(), session)
+			h.HandleMessage(ctx, NewNotification("initialized", nil))
+			h.HandleMessage(context.Background(), NewNotification("initialized", nil))
+			for j := 0; j < 50; j++ {
+				h.HandleMessage(ctx, NewRequest(j, "tools/list", nil))
+				h.HandleMessage(ctx, NewRequest(j, "tools/call", map[string]interface{}{"name": "stable"}))
+				h.HandleMessage(ctx, NewRequest(j, "resources/templates/list", nil))
+				h.HandleMessage(ctx, NewRequest(j, "resources/read", map[string]interface{}{"uri": "doc://analysis/1"}))
+				h.HandleMessage(ctx, NewRequest(j, "prompts/list", nil))
+				h.IsInitialized()
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if listed, _ := h.ListTools(); len(listed) != 1 || listed[0].Name != "stable" {
+		t.Errorf("Expected only the stable tool to remain, got %v", listed)
+	}
+}
+
+// echoPrompt declares a required topic and a depth defaulting to brief,
+// and renders the arguments it receives
+type echoPrompt struct{}
+
+func (echoPrompt) Definition() *Prompt {
+	return &Prompt{Name: "echo", Arguments: []PromptArgument{
+		{Name: "topic", Required: true},
+		{Name: "depth", Default: "brief"},
+		{Name: "audience"},
+	}}
+}
+
+func (echoPrompt) Generate(ctx context.Context, params map[string]interface{}) (*GetPromptResult, error) {
+	return &GetPromptResult{Description: fmt.Sprint(params)}, nil
+}
+
+func TestBaseHandler_GetPromptValidatesArguments(t *testing.T) {
+	h := NewBaseHandler(ServerInfo{Name: "test", Version: "1.0.0"}, ServerCapabilities{Prompts: &PromptsCapability{}})
+	h.RegisterPrompt(echoPrompt{})
+	ctx := context.Background()
+	h.HandleMessage(ctx, NewNotification("initialized", nil))
+
+	result, err := h.GetPrompt(ctx, &GetPromptParams{Name: "echo", Arguments: map[string]interface{}{"topic": "go"}})
+	if err != nil {
+		t.Fatalf("GetPrompt failed: %v", err)
+	}
+	if result.Description != "map[depth:brief topic:go]" {
+		t.Errorf("Expected the depth default and no audience, got %s", result.Description)
+	}
+
+	tests := []map[string]interface{}{
+		nil,
+		{"depth": "detailed"},
+		{"topic": "go", "tone": "formal"},
+	}
+	for _, arguments := range tests {
+		_, err := h.GetPrompt(ctx, &GetPromptParams{Name: "echo", Arguments: arguments})
+		if info, ok := AsErrorInfo(err); !ok || info.Code != InvalidParams {
+			t.Errorf("Expected %v to be rejected with invalid params, got %v", arguments, err)
+		}
+	}
+}
@@ -0,0 +1,278 @@
+package mcp
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// CallInfo describes a single request as seen by lifecycle hooks
+type CallInfo struct {
+	ID       RequestID
+	Method   string
+	Params   interface{}
+	Result   interface{}
+	Error    *ErrorInfo
+	Duration time.Duration
+}
+
+// BeforeCallHook runs before a request is dispatched. Returning a non-nil
+// result short-circuits dispatch and sends that result to the client, which
+// lets hooks serve cached responses. Returning an error rejects the request;
+// an *ErrorInfo keeps its code, any other error is reported as InternalError.
+type BeforeCallHook func(ctx context.Context, call *CallInfo) (interface{}, error)
+
+// AfterCallHook runs after every request, successful or not, with the
+// result or error and the time spent handling it
+type AfterCallHook func(ctx context.Context, call *CallInfo)
+
+// ErrorHook runs when a request produces a JSON-RPC error response
+type ErrorHook func(ctx context.Context, call *CallInfo, err *ErrorInfo)
+
+// InitializeHook runs after a client completed the initialize request,
+// with what it sent and what the server answered
+type InitializeHook func(ctx context.Context, params *InitializeParams, result *InitializeResult)
+
+// ToolCallInfo describes a tool call as seen by tool call hooks. Result,
+// Error and Duration are set for ToolCallEndHook.
+type ToolCallInfo struct {
+	Name      string
+	Arguments map[string]interface{}
+	Result    *CallToolResult
+	Error     error
+	Duration  time.Duration
+}
+
+// ToolCallStartHook runs before a tool executes. Returning an error rejects
+// the call with a JSON-RPC error without running the tool; an *ErrorInfo
+// keeps its code, anything else is reported as an invalid request.
+type ToolCallStartHook func(ctx context.Context, call *ToolCallInfo) error
+
+// ToolCallEndHook runs after a tool executed, or was rejected by a start
+// hook
+type ToolCallEndHook func(ctx context.Context, call *ToolCallInfo)
+
+// ShutdownHook runs when the handler is shut down, to flush or release
+// what integrations hold
+type ShutdownHook func(ctx context.Context)
+
+// SessionCloseHook runs when a client's session ends, to release what was
+// kept for it
+type SessionCloseHook func(session *Session)
+
+// SessionCloser is implemented by handlers that want to know when a
+// transport ends a session
+type SessionCloser interface {
+	CloseSession(session *Session)
+}
+
+// hookSet holds the registered lifecycle hooks
+type hookSet struct {
+	mu         sync.RWMutex
+	before     []BeforeCallHook
+	after      []AfterCallHook
+	onError    []ErrorHook
+	initialize []InitializeHook
+	toolStart  []ToolCallStartHook
+	toolEnd    []ToolCallEndHook
+	shutdown   []ShutdownHook
+	closed     []SessionCloseHook
+}
+
+// OnBeforeCall registers a hook that runs before each request
+func (h *BaseHandler) OnBeforeCall(hook BeforeCallHook) {
+	h.hooks.mu.Lock()
+	defer h.hooks.mu.Unlock()
+	h.hooks.before = append(h.hooks.before, hook)
+}
+
+// OnAfterCall registers a hook that runs after each request
+func (h *BaseHandler) OnAfterCall(hook AfterCallHook) {
+	h.hooks.mu.Lock()
+	defer h.hooks.mu.Unlock()
+	h.hooks.after = append(h.hooks.after, hook)
+}
+
+// OnError registers a hook that runs when a request fails
+func (h *BaseHandler) OnError(hook ErrorHook) {
+	h.hooks.mu.Lock()
+	defer h.hooks.mu.Unlock()
+	h.hooks.onError = append(h.hooks.onError, hook)
+}
+
+// OnInitialize registers a hook that runs after each successful initialize
+func (h *BaseHandler) OnInitialize(hook InitializeHook) {
+	h.hooks.mu.Lock()
+	defer h.hooks.mu.Unlock()
+	h.hooks.initialize = append(h.hooks.initialize, hook)
+}
+
+// OnToolCallStart registers a hook that runs before each tool execution
+func (h *BaseHandler) OnToolCallStart(hook ToolCallStartHook) {
+	h.hooks.mu.Lock()
+	defer h.hooks.mu.Unlock()
+	h.hooks.toolStart = append(h.hooks.toolStart, hook)
+}
+
+// OnToolCallEnd registers a hook that runs after each tool execution
+func (h *BaseHandler) OnToolCallEnd(hook ToolCallEndHook) {
+	h.hooks.mu.Lock()
+	defer h.hooks.mu.Unlock()
+	h.hooks.toolEnd = append(h.hooks.toolEnd, hook)
+}
+
+// OnShutdown registers a hook that runs when Shutdown is called
+func (h *BaseHandler) OnShutdown(hook ShutdownHook) {
+	h.hooks.mu.Lock()
+	defer h.hooks.mu.Unlock()
+	h.hooks.shutdown = append(h.hooks.shutdown, hook)
+}
+
+// OnSessionClose registers a hook that runs when a session ends
+func (h *BaseHandler) OnSessionClose(hook SessionCloseHook) {
+	h.hooks.mu.Lock()
+	defer h.hooks.mu.Unlock()
+	h.hooks.closed = append(h.hooks.closed, hook)
+}
+
+// CloseSession runs the session close hooks. Transports call it once a
+// client disconnected or its session expired or was deleted.
+func (h *BaseHandler) CloseSession(session *Session) {
+	h.hooks.mu.RLock()
+	hooks := h.hooks.closed
+	h.hooks.mu.RUnlock()
+
+	for _, hook := range hooks {
+		hook(session)
+	}
+}
+
+// Shutdown runs the shutdown hooks in the order they were registered. The
+// server calls it once it stopped serving requests.
+func (h *BaseHandler) Shutdown(ctx context.Context) {
+	h.hooks.mu.RLock()
+	hooks := h.hooks.shutdown
+	h.hooks.mu.RUnlock()
+
+	for _, hook := range hooks {
+		hook(ctx)
+	}
+}
+
+// runInitializeHooks runs the initialize hooks
+func (h *BaseHandler) runInitializeHooks(ctx context.Context, params *InitializeParams, result *InitializeResult) {
+	h.hooks.mu.RLock()
+	hooks := h.hooks.initialize
+	h.hooks.mu.RUnlock()
+
+	for _, hook := range hooks {
+		hook(ctx, params, result)
+	}
+}
+
+// executeWithHooks runs a tool between the tool call hooks. rejected is set
+// when a start hook refused the call.
+func (h *BaseHandler) executeWithHooks(ctx context.Context, handler ToolHandler, name string, arguments map[string]interface{}) (result *CallToolResult, err error, rejected *ErrorInfo) {
+	h.hooks.mu.RLock()
+	start, end := h.hooks.toolStart, h.hooks.toolEnd
+	h.hooks.mu.RUnlock()
+	if len(start) == 0 && len(end) == 0 {
+		result, err = handler.Execute(ctx, arguments)
+		return result, err, nil
+	}
+
+	call := &ToolCallInfo{Name: name, Arguments: arguments}
+	began := time.Now()
+	for _, hook := range start {
+		if call.Error = hook(ctx, call); call.Error != nil {
+			rejected = hookRejection(call.Error)
+			break
+		}
+	}
+	if rejected == nil {
+		call.Result, call.Error = handler.Execute(ctx, arguments)
+	}
+	call.Duration = time.Since(began)
+
+	for _, hook := range end {
+		hook(ctx, call)
+	}
+	return call.Result, call.Error, rejected
+}
+
+// snapshot returns the currently registered hooks
+func (s *hookSet) snapshot() ([]BeforeCallHook, []AfterCallHook, []ErrorHook) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.before, s.after, s.onError
+}
+
+// dispatchRequest handles a request, running the lifecycle hooks around it
+func (h *BaseHandler) dispatchRequest(ctx context.Context, message *Message) (*Message, error) {
+	before, after, onError := h.hooks.snapshot()
+	if len(before) == 0 && len(after) == 0 && len(onError) == 0 {
+		return h.handleRequest(ctx, message)
+	}
+
+	call := &CallInfo{
+		ID:     message.ID,
+		Method: message.Method,
+		Params: message.Params,
+	}
+	start := time.Now()
+
+	var response *Message
+	for _, hook := range before {
+		result, err := hook(ctx, call)
+		if err != nil {
+			response = hookErrorResponse(message.ID, err)
+			break
+		}
+		if result != nil {
+			response = NewSuccessResponse(message.ID, result)
+			break
+		}
+	}
+
+	var err error
+	if response == nil {
+		response, err = h.handleRequest(ctx, message)
+	}
+	call.Duration = time.Since(start)
+
+	switch {
+	case err != nil:
+		call.Error = &ErrorInfo{Code: InternalError, Message: err.Error()}
+	case response != nil && response.Error != nil:
+		call.Error = response.Error
+	case response != nil:
+		call.Result = response.Result
+	}
+
+	if call.Error != nil {
+		for _, hook := range onError {
+			hook(ctx, call, call.Error)
+		}
+	}
+	for _, hook := range after {
+		hook(ctx, call)
+	}
+
+	return response, err
+}
+
+// hookRejection converts the error a hook rejected a call with into the
+// error reported to the client. Errors other than an ErrorInfo become an
+// InvalidRequest error, whether a request or a tool call hook returned them.
+func hookRejection(err error) *ErrorInfo {
+	if info, ok := AsErrorInfo(err); ok {
+		return info
+	}
+	return NewError(InvalidRequest, "request rejected: "+err.Error(), nil)
+}
+
+// hookErrorResponse converts a before-hook rejection into an error response
+func hookErrorResponse(id RequestID, err error) *Message {
+	info := hookRejection(err)
+	return NewErrorResponse(id, info.Code, info.Message, info.Data)
+}
@@ -0,0 +1,149 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestBaseHandler_Hooks(t *testing.T) {
+	h := NewBaseHandler(ServerInfo{Name: "test", Version: "1.0.0"}, ServerCapabilities{})
+
+	var before, after, failed []string
+	h.OnBeforeCall(func(ctx context.Context, call *CallInfo) (interface{}, error) {
+		before = append(before, call.Method)
+		return nil, nil
+	})
+	h.OnAfterCall(func(ctx context.Context, call *CallInfo) {
+		after = append(after, call.Method)
+	})
+	h.OnError(func(ctx context.Context, call *CallInfo, err *ErrorInfo) {
+		failed = append(failed, call.Method)
+		if err.Code != MethodNotFound {
+			t.Errorf("Expected MethodNotFound, got %d", err.Code)
+		}
+	})
+
+	ctx := context.Background()
+	if _, err := h.HandleMessage(ctx, NewRequest(1, "tools/list", nil)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := h.HandleMessage(ctx, NewRequest(2, "unknown/method", nil)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(before) != 2 || len(after) != 2 {
+		t.Errorf("Expected 2 before/after calls, got %d/%d", len(before), len(after))
+	}
+	if len(failed) != 1 || failed[0] != "unknown/method" {
+		t.Errorf("Expected one error hook call for unknown/method, got %v", failed)
+	}
+}
+
+func TestBaseHandler_BeforeCallShortCircuit(t *testing.T) {
+	h := NewBaseHandler(ServerInfo{Name: "test", Version: "1.0.0"}, ServerCapabilities{})
+
+	h.OnBeforeCall(func(ctx context.Context, call *CallInfo) (interface{}, error) {
+		if call.Method == "tools/list" {
+			return map[string]interface{}{"tools": []string{"cached"}}, nil
+		}
+		return nil, &ErrorInfo{Code: InvalidRequest, Message: "quota exceeded"}
+	})
+
+	ctx := context.Background()
+	response, _ := h.HandleMessage(ctx, NewRequest(1, "tools/list", nil))
+	if response.Error != nil {
+		t.Fatalf("Expected cached result, got error: %v", response.Error)
+	}
+	if result, ok := response.Result.(map[string]interface{}); !ok || result["tools"] == nil {
+		t.Errorf("Expected cached tools result, got %v", response.Result)
+	}
+
+	response, _ = h.HandleMessage(ctx, NewRequest(2, "prompts/list", nil))
+	if response.Error == nil || response.Error.Code != InvalidRequest {
+		t.Errorf("Expected InvalidRequest rejection, got %v", response.Error)
+	}
+}
+
+func TestBaseHandler_HookRejectionsMatch(t *testing.T) {
+	h := NewBaseHandler(ServerInfo{Name: "test", Version: "1.0.0"}, ServerCapabilities{})
+	h.RegisterTool(namedTool("blocked"))
+	h.OnBeforeCall(func(ctx context.Context, call *CallInfo) (interface{}, error) {
+		if call.Method == "prompts/list" {
+			return nil, errors.New("over quota")
+		}
+		return nil, nil
+	})
+	h.OnToolCallStart(func(ctx context.Context, call *ToolCallInfo) error {
+		return errors.New("over quota")
+	})
+
+	ctx := context.Background()
+	h.HandleMessage(ctx, NewNotification("initialized", nil))
+	request, _ := h.HandleMessage(ctx, NewRequest(1, "prompts/list", nil))
+	call, _ := h.HandleMessage(ctx, NewRequest(2, "tools/call", map[string]interface{}{"name": "blocked"}))
+	for _, response := range []*Message{request, call} {
+		if response.Error == nil || response.Error.Code != InvalidRequest || response.Error.Message != "request rejected: over quota" {
+			t.Errorf("Expected a plain error to be rejected as InvalidRequest, got %+v", response.Error)
+		}
+	}
+}
+
+func TestBaseHandler_LifecycleHooks(t *testing.T) {
+	h := NewBaseHandler(ServerInfo{Name: "test", Version: "1.0.0"}, ServerCapabilities{})
+	h.RegisterTool(namedTool("allowed"))
+	h.RegisterTool(namedTool("blocked"))
+
+	var clientName string
+	var ended []*ToolCallInfo
+	shutdowns := 0
+	h.OnInitialize(func(ctx context.Context, params *InitializeParams, result *InitializeResult) {
+		clientName = params.ClientInfo.Name
+	})
+	h.OnToolCallStart(func(ctx context.Context, call *ToolCallInfo) error {
+		if call.Name == "blocked" {
+			return errors.New("not allowed by policy")
+		}
+		return nil
+	})
+	h.OnToolCallEnd(func(ctx context.Context, call *ToolCallInfo) {
+		ended = append(ended, call)
+	})
+	h.OnShutdown(func(ctx context.Context) {
+		shutdowns++
+	})
+
+	ctx := context.Background()
+	h.HandleMessage(ctx, NewRequest(1, "initialize", map[string]interface{}{
+		"protocolVersion": MCPVersion,
+		"clientInfo":      map[string]interface{}{"name": "inspector", "version": "1.0"},
+	}))
+	h.HandleMessage(ctx, NewNotification("initialized", nil))
+	if clientName != "inspector" {
+		t.Errorf("Expected the initialize hook to see the client, got %q", clientName)
+	}
+
+	response, _ := h.HandleMessage(ctx, NewRequest(2, "tools/call", map[string]interface{}{"name": "allowed"}))
+	if response.Error != nil {
+		t.Fatalf("Unexpected error: %v", response.Error)
+	}
+	response, _ = h.HandleMessage(ctx, NewRequest(3, "tools/call", map[string]interface{}{"name": "blocked"}))
+	if response.Error == nil || response.Error.Code != InvalidRequest {
+		t.Errorf("Expected the start hook to reject the call, got %+v", response)
+	}
+
+	if len(ended) != 2 {
+		t.Fatalf("Expected 2 end hook calls, got %d", len(ended))
+	}
+	if ended[0].Name != "allowed" || ended[0].Result == nil || ended[0].Error != nil {
+		t.Errorf("Unexpected end of the allowed call: %+v", ended[0])
+	}
+	if ended[1].Name != "blocked" || ended[1].Result != nil || ended[1].Error == nil {
+		t.Errorf("Unexpected end of the blocked call: %+v", ended[1])
+	}
+
+	h.Shutdown(ctx)
+	if shutdowns != 1 {
+		t.Errorf("Expected the shutdown hook to run once, got %d", shutdowns)
+	}
+}
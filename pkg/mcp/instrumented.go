@@ -0,0 +1,227 @@
+package mcp
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Observation is a single measured request
+type Observation struct {
+	Method    string
+	Target    string // tool or prompt name, or resource URI, when applicable
+	Duration  time.Duration
+	ErrorCode int // 0 when the request succeeded
+}
+
+// MetricsSink receives an observation for every request handled by an
+// instrumented handler. Implementations must be safe for concurrent use.
+type MetricsSink interface {
+	Observe(obs Observation)
+}
+
+// instrumentedHandler decorates a Handler with metrics reporting
+type instrumentedHandler struct {
+	inner   Handler
+	metrics MetricsSink
+}
+
+// InstrumentedHandler wraps inner so every request's method, duration, and
+// error code is reported to metrics
+func InstrumentedHandler(inner Handler, metrics MetricsSink) Handler {
+	return &instrumentedHandler{
+		inner:   inner,
+		metrics: metrics,
+	}
+}
+
+// HandleMessage handles an incoming MCP message and records the outcome
+func (h *instrumentedHandler) HandleMessage(ctx context.Context, message *Message) (*Message, error) {
+	if message == nil || !message.IsRequest() {
+		return h.inner.HandleMessage(ctx, message)
+	}
+
+	start := time.Now()
+	response, err := h.inner.HandleMessage(ctx, message)
+
+	obs := Observation{
+		Method:   message.Method,
+		Target:   MessageTarget(message),
+		Duration: time.Since(start),
+	}
+	switch {
+	case err != nil:
+		obs.ErrorCode = errorCode(err)
+	case response != nil && response.Error != nil:
+		obs.ErrorCode = response.Error.Code
+	}
+	h.metrics.Observe(obs)
+
+	return response, err
+}
+
+// Initialize handles the initialize request
+func (h *instrumentedHandler) Initialize(params *InitializeParams) (*InitializeResult, error) {
+	start := time.Now()
+	result, err := h.inner.Initialize(params)
+	h.observe("initialize", "", start, err)
+	return result, err
+}
+
+// ListTools returns all registered tools
+func (h *instrumentedHandler) ListTools() ([]*Tool, error) {
+	start := time.Now()
+	tools, err := h.inner.ListTools()
+	h.observe("tools/list", "", start, err)
+	return tools, err
+}
+
+// CallTool executes a tool with the given parameters
+func (h *instrumentedHandler) CallTool(ctx context.Context, params *CallToolParams) (*CallToolResult, error) {
+	start := time.Now()
+	result, err := h.inner.CallTool(ctx, params)
+	h.observe("tools/call", params.Name, start, err)
+	return result, err
+}
+
+// ListResources returns all registered resources
+func (h *instrumentedHandler) ListResources() ([]*Resource, error) {
+	start := time.Now()
+	resources, err := h.inner.ListResources()
+	h.observe("resources/list", "", start, err)
+	return resources, err
+}
+
+// ReadResource reads a resource with the given URI
+func (h *instrumentedHandler) ReadResource(ctx context.Context, params *ReadResourceParams) (*ReadResourceResult, error) {
+	start := time.Now()
+	result, err := h.inner.ReadResource(ctx, params)
+	h.observe("resources/read", params.URI, start, err)
+	return result, err
+}
+
+// ListPrompts returns all registered prompts
+func (h *instrumentedHandler) ListPrompts() ([]*Prompt, error) {
+	start := time.Now()
+	prompts, err := h.inner.ListPrompts()
+	h.observe("prompts/list", "", start, err)
+	return prompts, err
+}
+
+// GetPrompt generates a prompt with the given parameters
+func (h *instrumentedHandler) GetPrompt(ctx context.Context, params *GetPromptParams) (*GetPromptResult, error) {
+	start := time.Now()
+	result, err := h.inner.GetPrompt(ctx, params)
+	h.observe("prompts/get", params.Name, start, err)
+	return result, err
+}
+
+// observe reports a direct method call to the sink
+func (h *instrumentedHandler) observe(method, target string, start time.Time, err error) {
+	obs := Observation{
+		Method:   method,
+		Target:   target,
+		Duration: time.Since(start),
+	}
+	if err != nil {
+		obs.ErrorCode = errorCode(err)
+	}
+	h.metrics.Observe(obs)
+}
+
+// errorCode maps an error to its JSON-RPC code, defaulting to InternalError
+func errorCode(err error) int {
+	if info, ok := AsErrorInfo(err); ok {
+		return info.Code
+	}
+	return InternalError
+}
+
+// MessageTarget extracts the tool or prompt name or resource URI from a request
+func MessageTarget(message *Message) string {
+	params, ok := message.Params.(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	switch message.Method {
+	case "tools/call", "prompts/get":
+		name, _ := params["name"].(string)
+		return name
+	case "resources/read":
+		uri, _ := params["uri"].(string)
+		return uri
+	}
+	return ""
+}
+
+// MethodStats aggregates observations for a single method
+type MethodStats struct {
+	Method        string        `json:"method"`
+	Count         int64         `json:"count"`
+	Errors        int64         `json:"errors"`
+	TotalDuration time.Duration `json:"totalDuration"`
+	MaxDuration   time.Duration `json:"maxDuration"`
+	ErrorCodes    map[int]int64 `json:"errorCodes,omitempty"`
+}
+
+// MemoryMetrics is an in-memory MetricsSink that aggregates per-method stats
+type MemoryMetrics struct {
+	mu    sync.Mutex
+	stats map[string]*MethodStats
+}
+
+// NewMemoryMetrics creates an empty in-memory metrics sink
+func NewMemoryMetrics() *MemoryMetrics {
+	return &MemoryMetrics{
+		stats: make(map[string]*MethodStats),
+	}
+}
+
+// Observe records an observation
+func (m *MemoryMetrics) Observe(obs Observation) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats, exists := m.stats[obs.Method]
+	if !exists {
+		stats = &MethodStats{Method: obs.Method}
+		m.stats[obs.Method] = stats
+	}
+
+	stats.Count++
+	stats.TotalDuration += obs.Duration
+	if obs.Duration > stats.MaxDuration {
+		stats.MaxDuration = obs.Duration
+	}
+	if obs.ErrorCode != 0 {
+		stats.Errors++
+		if stats.ErrorCodes == nil {
+			stats.ErrorCodes = make(map[int]int64)
+		}
+		stats.ErrorCodes[obs.ErrorCode]++
+	}
+}
+
+// Snapshot returns a copy of the per-method stats sorted by method name
+func (m *MemoryMetrics) Snapshot() []MethodStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	snapshot := make([]MethodStats, 0, len(m.stats))
+	for _, stats := range m.stats {
+		copied := *stats
+		if stats.ErrorCodes != nil {
+			copied.ErrorCodes = make(map[int]int64, len(stats.ErrorCodes))
+			for code, count := range stats.ErrorCodes {
+				copied.ErrorCodes[code] = count
+			}
+		}
+		snapshot = append(snapshot, copied)
+	}
+
+	sort.Slice(snapshot, func(i, j int) bool {
+		return snapshot[i].Method < snapshot[j].Method
+	})
+	return snapshot
+}
@@ -0,0 +1,47 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+)
+
+func TestInstrumentedHandler_RecordsMethods(t *testing.T) {
+	inner := NewBaseHandler(ServerInfo{Name: "test", Version: "1.0.0"}, ServerCapabilities{})
+	metrics := NewMemoryMetrics()
+	h := InstrumentedHandler(inner, metrics)
+
+	ctx := context.Background()
+	h.HandleMessage(ctx, NewRequest(1, "tools/list", nil))
+	h.HandleMessage(ctx, NewRequest(2, "tools/list", nil))
+	h.HandleMessage(ctx, NewRequest(3, "missing/method", nil))
+	h.HandleMessage(ctx, NewNotification("initialized", nil))
+
+	snapshot := metrics.Snapshot()
+	if len(snapshot) != 2 {
+		t.Fatalf("Expected stats for 2 methods, got %d", len(snapshot))
+	}
+
+	missing, list := snapshot[0], snapshot[1]
+	if list.Method != "tools/list" || list.Count != 2 || list.Errors != 0 {
+		t.Errorf("Unexpected tools/list stats: %+v", list)
+	}
+	if missing.Method != "missing/method" || missing.Errors != 1 || missing.ErrorCodes[MethodNotFound] != 1 {
+		t.Errorf("Unexpected missing/method stats: %+v", missing)
+	}
+}
+
+func TestInstrumentedHandler_DirectCalls(t *testing.T) {
+	inner := NewBaseHandler(ServerInfo{Name: "test", Version: "1.0.0"}, ServerCapabilities{})
+	metrics := NewMemoryMetrics()
+	h := InstrumentedHandler(inner, metrics)
+
+	// Not initialized, so the call fails
+	if _, err := h.CallTool(context.Background(), &CallToolParams{Name: "calculator"}); err == nil {
+		t.Fatal("Expected error calling tool before initialization")
+	}
+
+	snapshot := metrics.Snapshot()
+	if len(snapshot) != 1 || snapshot[0].Method != "tools/call" || snapshot[0].ErrorCodes[InvalidRequest] != 1 {
+		t.Errorf("Unexpected stats: %+v", snapshot)
+	}
+}
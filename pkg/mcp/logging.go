@@ -0,0 +1,54 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+)
+
+// loggingSeverity orders the logging levels from least to most severe
+var loggingSeverity = map[LoggingLevel]int{
+	LoggingLevelDebug:     0,
+	LoggingLevelInfo:      1,
+	LoggingLevelNotice:    2,
+	LoggingLevelWarning:   3,
+	LoggingLevelError:     4,
+	LoggingLevelCritical:  5,
+	LoggingLevelAlert:     6,
+	LoggingLevelEmergency: 7,
+}
+
+// Valid reports whether l is one of the levels defined by the protocol
+func (l LoggingLevel) Valid() bool {
+	_, ok := loggingSeverity[l]
+	return ok
+}
+
+// Includes reports whether a client that set level l wants messages at
+// level; an unset level includes nothing
+func (l LoggingLevel) Includes(level LoggingLevel) bool {
+	if !l.Valid() || !level.Valid() {
+		return false
+	}
+	return loggingSeverity[level] >= loggingSeverity[l]
+}
+
+// setLogLevel records the level of log messages the session in ctx wants
+// to receive as notifications/message
+func (h *BaseHandler) setLogLevel(ctx context.Context, level LoggingLevel) error {
+	if !h.isInitialized(ctx) {
+		return NotInitializedError()
+	}
+	if h.capabilities.Logging == nil {
+		return NewError(MethodNotFound, "logging is not supported", nil)
+	}
+	if !level.Valid() {
+		return InvalidParamsError("level", fmt.Sprintf("unknown logging level %q", level))
+	}
+
+	session, ok := SessionFromContext(ctx)
+	if !ok {
+		return NewError(InvalidRequest, "logging requires a session", nil)
+	}
+	session.setLogLevel(level)
+	return nil
+}
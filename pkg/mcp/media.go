@@ -0,0 +1,89 @@
+package mcp
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Content types a tool result can hold
+const (
+	ContentTypeText  = "text"
+	ContentTypeImage = "image"
+	ContentTypeAudio = "audio"
+	// ContentTypeResource embeds a resource's contents
+	ContentTypeResource = "resource"
+)
+
+// NewTextContent returns text content
+func NewTextContent(text string) Content {
+	return Content{Type: ContentTypeText, Text: text}
+}
+
+// NewImageContent returns image content, such as a chart or screenshot,
+// encoded as base64. An empty mimeType is detected from the data.
+func NewImageContent(data []byte, mimeType string) Content {
+	return newMediaContent(ContentTypeImage, data, mimeType)
+}
+
+// NewAudioContent returns audio content, such as speech from a TTS tool,
+// encoded as base64. An empty mimeType is detected from the data.
+func NewAudioContent(data []byte, mimeType string) Content {
+	return newMediaContent(ContentTypeAudio, data, mimeType)
+}
+
+// newMediaContent encodes data as content of the given type
+func newMediaContent(contentType string, data []byte, mimeType string) Content {
+	if mimeType == "" {
+		mimeType = http.DetectContentType(data)
+	}
+	return Content{
+		Type:     contentType,
+		Data:     base64.StdEncoding.EncodeToString(data),
+		MimeType: mimeType,
+	}
+}
+
+// Validate reports whether the content is well formed for its type: media
+// needs base64 data with a matching MIME type, and embedded resources need
+// a URI
+func (c Content) Validate() error {
+	switch c.Type {
+	case ContentTypeText:
+		return nil
+	case ContentTypeImage, ContentTypeAudio:
+		if c.Data == "" {
+			return fmt.Errorf("%s content requires data", c.Type)
+		}
+		if !strings.HasPrefix(c.MimeType, c.Type+"/") {
+			return fmt.Errorf("%s content has MIME type %q, expected %s/*", c.Type, c.MimeType, c.Type)
+		}
+		if _, err := base64.StdEncoding.DecodeString(c.Data); err != nil {
+			return fmt.Errorf("%s content data is not valid base64: %w", c.Type, err)
+		}
+		return nil
+	case ContentTypeResource:
+		if c.Resource == nil || c.Resource.URI == "" {
+			return fmt.Errorf("resource content requires a resource with a URI")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown content type %q", c.Type)
+	}
+}
+
+// validateContents returns the first content that is not well formed
+func validateContents(contents []Content) error {
+	for i, content := range contents {
+		if err := content.Validate(); err != nil {
+			return fmt.Errorf("content %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// Bytes returns the decoded data of image or audio content
+func (c Content) Bytes() ([]byte, error) {
+	return base64.StdEncoding.DecodeString(c.Data)
+}
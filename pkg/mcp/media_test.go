@@ -0,0 +1,75 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+// pngHeader is enough of a PNG file for MIME type detection
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\x0dIHDR")
+
+// mediaTool returns the content it was built with
+type mediaTool struct {
+	content Content
+}
+
+func (mediaTool) Definition() *Tool {
+	return &Tool{Name: "media", InputSchema: ToolSchema{Type: "object"}}
+}
+
+func (t mediaTool) Execute(ctx context.Context, params map[string]interface{}) (*CallToolResult, error) {
+	return &CallToolResult{Content: []Content{NewTextContent("Rendered chart"), t.content}}, nil
+}
+
+func TestMediaContent(t *testing.T) {
+	image := NewImageContent(pngHeader, "")
+	if image.Type != ContentTypeImage || image.MimeType != "image/png" {
+		t.Errorf("Expected detected image/png content, got %s %s", image.Type, image.MimeType)
+	}
+	if err := image.Validate(); err != nil {
+		t.Errorf("Expected valid image content, got %v", err)
+	}
+	if data, err := image.Bytes(); err != nil || !bytes.Equal(data, pngHeader) {
+		t.Errorf("Expected the image data back, got %v, %v", data, err)
+	}
+
+	audio := NewAudioContent([]byte("RIFF"), "audio/wav")
+	if err := audio.Validate(); err != nil {
+		t.Errorf("Expected valid audio content, got %v", err)
+	}
+
+	invalid := []Content{
+		{Type: ContentTypeImage, MimeType: "image/png"},
+		{Type: ContentTypeImage, Data: "aGVsbG8=", MimeType: "text/plain"},
+		{Type: ContentTypeAudio, Data: "not base64!", MimeType: "audio/mpeg"},
+		{Type: ContentTypeResource},
+		{Type: "video"},
+	}
+	for _, content := range invalid {
+		if err := content.Validate(); err == nil {
+			t.Errorf("Expected %+v to be invalid", content)
+		}
+	}
+}
+
+func TestBaseHandler_RejectsInvalidToolContent(t *testing.T) {
+	ctx := WithSession(context.Background(), NewSession("media"))
+	call := func(content Content) *CallToolResult {
+		h := NewBaseHandler(ServerInfo{Name: "test", Version: "1.0.0"}, ServerCapabilities{})
+		if err := h.RegisterTool(mediaTool{content: content}); err != nil {
+			t.Fatalf("Failed to register tool: %v", err)
+		}
+		h.HandleMessage(ctx, NewRequest(1, "initialize", InitializeParams{ProtocolVersion: MCPVersion}))
+		h.HandleMessage(ctx, NewNotification("initialized", nil))
+		response, _ := h.HandleMessage(ctx, NewRequest(2, "tools/call", CallToolParams{Name: "media"}))
+		return response.Result.(*CallToolResult)
+	}
+
+	if result := call(NewImageContent(pngHeader, "image/png")); result.IsError || len(result.Content) != 2 {
+		t.Errorf("Expected the image to be returned, got %+v", result)
+	}
+	if result := call(Content{Type: ContentTypeImage, MimeType: "image/png"}); !result.IsError {
+		t.Errorf("Expected image content without data to be rejected")
+	}
+}
@@ -0,0 +1,87 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+)
+
+// Middleware wraps a tool to add behaviour around its Execute calls, such as
+// logging, metrics, validation, auth or caching
+type Middleware func(next ToolHandler) ToolHandler
+
+// ExecuteFunc is the signature of ToolHandler.Execute
+type ExecuteFunc func(ctx context.Context, params map[string]interface{}) (*CallToolResult, error)
+
+// WrapTool returns a tool with next's definition that runs execute instead
+// of next.Execute. Middleware use it to avoid restating Definition.
+func WrapTool(next ToolHandler, execute ExecuteFunc) ToolHandler {
+	return &wrappedTool{ToolHandler: next, execute: execute}
+}
+
+// wrappedTool replaces the Execute method of a tool
+type wrappedTool struct {
+	ToolHandler
+	execute ExecuteFunc
+}
+
+// Execute implements ToolHandler
+func (t *wrappedTool) Execute(ctx context.Context, params map[string]interface{}) (*CallToolResult, error) {
+	return t.execute(ctx, params)
+}
+
+// Use adds middleware around every tool call, including tools registered
+// later. The first middleware added is the outermost.
+func (h *BaseHandler) Use(middleware ...Middleware) {
+	h.toolsMu.Lock()
+	defer h.toolsMu.Unlock()
+	h.middleware = append(h.middleware, middleware...)
+}
+
+// withMiddleware wraps a tool in the registered middleware
+func (h *BaseHandler) withMiddleware(handler ToolHandler) ToolHandler {
+	h.toolsMu.RLock()
+	middleware := h.middleware
+	h.toolsMu.RUnlock()
+
+	for i := len(middleware) - 1; i >= 0; i-- {
+		handler = middleware[i](handler)
+	}
+	return handler
+}
+
+// RecoverPanics turns a panic in a tool into an error, so one faulty tool
+// cannot take the server down
+func RecoverPanics(report func(tool string, recovered interface{}, stack []byte)) Middleware {
+	return func(next ToolHandler) ToolHandler {
+		return WrapTool(next, func(ctx context.Context, params map[string]interface{}) (result *CallToolResult, err error) {
+			defer func() {
+				if recovered := recover(); recovered != nil {
+					if report != nil {
+						report(next.Definition().Name, recovered, debug.Stack())
+					}
+					result, err = nil, fmt.Errorf("tool panicked: %v", recovered)
+				}
+			}()
+			return next.Execute(ctx, params)
+		})
+	}
+}
+
+// ValidateArguments checks arguments against each tool's input schema
+// before the tool runs; invalid calls fail without reaching the tool. Tools
+// whose schema has no properties are not checked.
+func ValidateArguments() Middleware {
+	return func(next ToolHandler) ToolHandler {
+		schema := next.Definition().InputSchema
+		if len(schema.Properties) == 0 {
+			return next
+		}
+		return WrapTool(next, func(ctx context.Context, params map[string]interface{}) (*CallToolResult, error) {
+			if err := ValidateToolParameters(params, schema); err != nil {
+				return nil, err
+			}
+			return next.Execute(ctx, params)
+		})
+	}
+}
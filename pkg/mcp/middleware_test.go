@@ -0,0 +1,129 @@
+package mcp
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// panicTool panics on every call
+type panicTool struct{}
+
+func (panicTool) Definition() *Tool {
+	return &Tool{Name: "panic", InputSchema: ToolSchema{Type: "object"}}
+}
+
+func (panicTool) Execute(ctx context.Context, params map[string]interface{}) (*CallToolResult, error) {
+	panic("something broke")
+}
+
+// modeTool requires a mode argument of fast or thorough
+type modeTool struct{}
+
+func (modeTool) Definition() *Tool {
+	return &Tool{Name: "mode", InputSchema: ToolSchema{
+		Type: "object",
+		Properties: map[string]interface{}{
+			"mode": map[string]interface{}{"type": "string", "enum": []interface{}{"fast", "thorough"}},
+		},
+		Required: []string{"mode"},
+	}}
+}
+
+func (modeTool) Execute(ctx context.Context, params map[string]interface{}) (*CallToolResult, error) {
+	return &CallToolResult{Content: []Content{NewTextContent(params["mode"].(string))}}, nil
+}
+
+// newMiddlewareHandler returns an initialized handler for direct calls
+func newMiddlewareHandler() *BaseHandler {
+	h := NewBaseHandler(ServerInfo{Name: "test", Version: "1.0.0"}, ServerCapabilities{})
+	h.HandleMessage(context.Background(), NewNotification("initialized", nil))
+	return h
+}
+
+func TestBaseHandler_UseOrdersMiddleware(t *testing.T) {
+	handler := newMiddlewareHandler()
+
+	var calls []string
+	trace := func(name string) Middleware {
+		return func(next ToolHandler) ToolHandler {
+			return WrapTool(next, func(ctx context.Context, params map[string]interface{}) (*CallToolResult, error) {
+				calls = append(calls, name+":"+next.Definition().Name)
+				return next.Execute(ctx, params)
+			})
+		}
+	}
+	handler.Use(trace("outer"), trace("inner"))
+
+	// Middleware also wraps tools registered after Use
+	if err := handler.RegisterTool(namedTool("late")); err != nil {
+		t.Fatalf("Failed to register tool: %v", err)
+	}
+	result, err := handler.CallTool(context.Background(), &CallToolParams{Name: "late"})
+	if err != nil || result.IsError {
+		t.Fatalf("Expected the call to succeed, got %+v, %v", result, err)
+	}
+
+	expected := []string{"outer:late", "inner:late"}
+	if strings.Join(calls, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected %v, got %v", expected, calls)
+	}
+}
+
+func TestMiddleware_ShortCircuit(t *testing.T) {
+	handler := newMiddlewareHandler()
+	handler.RegisterTool(namedTool("guarded"))
+	handler.Use(func(next ToolHandler) ToolHandler {
+		return WrapTool(next, func(ctx context.Context, params map[string]interface{}) (*CallToolResult, error) {
+			return NewStructuredResult("cached", map[string]interface{}{"cached": true}), nil
+		})
+	})
+
+	result, err := handler.CallTool(context.Background(), &CallToolParams{Name: "guarded"})
+	if err != nil || result.Content[0].Text != "cached" {
+		t.Errorf("Expected the middleware's result, got %+v, %v", result, err)
+	}
+}
+
+func TestRecoverPanics(t *testing.T) {
+	handler := newMiddlewareHandler()
+	handler.RegisterTool(panicTool{})
+
+	var reported string
+	handler.Use(RecoverPanics(func(tool string, recovered interface{}, stack []byte) {
+		reported = tool
+	}))
+
+	result, err := handler.CallTool(context.Background(), &CallToolParams{Name: "panic"})
+	if err != nil {
+		t.Fatalf("Expected a tool error result, got %v", err)
+	}
+	if !result.IsError || !strings.Contains(result.Content[0].Text, "something broke") {
+		t.Errorf("Expected the panic as an error result, got %+v", result)
+	}
+	if reported != "panic" {
+		t.Errorf("Expected the panic to be reported for the tool, got %q", reported)
+	}
+}
+
+func TestValidateArguments(t *testing.T) {
+	handler := newMiddlewareHandler()
+	handler.RegisterTool(modeTool{})
+	handler.Use(ValidateArguments())
+
+	// Without validation the tool would panic on the missing argument
+	result, err := handler.CallTool(context.Background(), &CallToolParams{Name: "mode"})
+	if err != nil || !result.IsError || !strings.Contains(result.Content[0].Text, "required parameter is missing") {
+		t.Errorf("Expected a missing parameter error result, got %+v, %v", result, err)
+	}
+
+	result, err = handler.CallTool(context.Background(), &CallToolParams{Name: "mode", Arguments: map[string]interface{}{"mode": "bogus"}})
+	if err != nil || !result.IsError || !strings.Contains(result.Content[0].Text, "must be one of") {
+		t.Errorf("Expected an enum error result, got %+v, %v", result, err)
+	}
+
+	result, err = handler.CallTool(context.Background(), &CallToolParams{Name: "mode", Arguments: map[string]interface{}{"mode": "fast"}})
+	if err != nil || result.IsError || result.Content[0].Text != "fast" {
+		t.Errorf("Expected a valid call to reach the tool, got %+v, %v", result, err)
+	}
+}
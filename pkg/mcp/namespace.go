@@ -0,0 +1,135 @@
+package mcp
+
+import "fmt"
+
+// NamespaceSeparator joins a namespace and a tool name, as in
+// research/web_search
+const NamespaceSeparator = "/"
+
+// NamespacedName returns name within namespace, or name itself when the
+// namespace is empty
+func NamespacedName(namespace, name string) string {
+	if namespace == "" {
+		return name
+	}
+	return namespace + NamespaceSeparator + name
+}
+
+// Namespaced returns handler served under namespace, so tool packs with
+// overlapping names can be registered side by side. Calls reach handler
+// unchanged.
+func Namespaced(namespace string, handler ToolHandler) ToolHandler {
+	if namespace == "" {
+		return handler
+	}
+	return &renamedTool{
+		ToolHandler: handler,
+		name:        NamespacedName(namespace, handler.Definition().Name),
+	}
+}
+
+// renamedTool serves a tool under another name
+type renamedTool struct {
+	ToolHandler
+	name string
+}
+
+// Definition implements ToolHandler
+func (t *renamedTool) Definition() *Tool {
+	return renamedDefinition(t.ToolHandler.Definition(), t.name)
+}
+
+// renamedDefinition returns a copy of tool with another name
+func renamedDefinition(tool *Tool, name string) *Tool {
+	renamed := *tool
+	renamed.Name = name
+	return &renamed
+}
+
+// AliasTool makes a registered tool available under another name too. The
+// alias is listed with the tool's definition and follows it: it disappears
+// while the tool is disabled or unregistered. It fails when the alias names
+// an existing tool or alias, or the target does not exist. A tool
+// registered later under the alias's name takes precedence over it.
+func (h *BaseHandler) AliasTool(alias, target string) error {
+	if alias == "" {
+		return fmt.Errorf("alias cannot be empty")
+	}
+	if _, err := h.tools.Get(target); err != nil {
+		return ToolNotFoundError(target)
+	}
+	if _, err := h.tools.Get(alias); err == nil {
+		return fmt.Errorf("alias '%s' collides with a registered tool", alias)
+	}
+
+	h.toolsMu.Lock()
+	if existing, exists := h.aliases[alias]; exists {
+		h.toolsMu.Unlock()
+		return fmt.Errorf("alias '%s' is already defined for tool '%s'", alias, existing)
+	}
+	if h.aliases == nil {
+		h.aliases = make(map[string]string)
+	}
+	h.aliases[alias] = target
+	h.toolsMu.Unlock()
+
+	h.notifyToolsChanged()
+	return nil
+}
+
+// Aliases returns the defined aliases and the tools they stand for
+func (h *BaseHandler) Aliases() map[string]string {
+	h.toolsMu.RLock()
+	defer h.toolsMu.RUnlock()
+
+	aliases := make(map[string]string, len(h.aliases))
+	for alias, target := range h.aliases {
+		aliases[alias] = target
+	}
+	return aliases
+}
+
+// aliasTarget returns the tool an alias stands for; the caller must hold
+// toolsMu
+func (h *BaseHandler) aliasTarget(name string) (string, bool) {
+	target, exists := h.aliases[name]
+	return target, exists
+}
+
+// toolTarget returns the tool a call to name runs: the target of an alias,
+// unless a tool is registered under the alias's name, or else name itself
+func (h *BaseHandler) toolTarget(name string) string {
+	if _, err := h.tools.Get(name); err == nil {
+		return name
+	}
+
+	h.toolsMu.RLock()
+	defer h.toolsMu.RUnlock()
+	if target, aliased := h.aliasTarget(name); aliased {
+		return target
+	}
+	return name
+}
+
+// withAliases adds an entry for each alias whose target is in tools,
+// unless a tool of that name is listed already; the caller must hold
+// toolsMu
+func (h *BaseHandler) withAliases(tools []*Tool) []*Tool {
+	if len(h.aliases) == 0 {
+		return tools
+	}
+
+	byName := make(map[string]*Tool, len(tools))
+	for _, tool := range tools {
+		byName[tool.Name] = tool
+	}
+	for alias, target := range h.aliases {
+		if _, taken := byName[alias]; taken {
+			continue
+		}
+		if tool, exists := byName[target]; exists {
+			tools = append(tools, renamedDefinition(tool, alias))
+		}
+	}
+	return tools
+}
@@ -0,0 +1,81 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestNamespaced(t *testing.T) {
+	h := NewBaseHandler(ServerInfo{Name: "test", Version: "1.0.0"}, ServerCapabilities{})
+	h.HandleMessage(context.Background(), NewNotification("initialized", nil))
+
+	// Two packs with the same tool name coexist under their namespaces
+	if err := h.RegisterTool(Namespaced("research", namedTool("search"))); err != nil {
+		t.Fatalf("Failed to register tool: %v", err)
+	}
+	h.RegisterTool(Namespaced("code", namedTool("search")))
+	h.RegisterTool(Namespaced("", namedTool("search")))
+
+	listed, _ := h.ListTools()
+	names := map[string]bool{}
+	for _, tool := range listed {
+		names[tool.Name] = true
+	}
+	if len(names) != 3 || !names["research/search"] || !names["code/search"] || !names["search"] {
+		t.Errorf("Expected namespaced and plain tools, got %v", names)
+	}
+
+	if _, err := h.CallTool(context.Background(), &CallToolParams{Name: "research/search"}); err != nil {
+		t.Errorf("Expected the namespaced tool to be callable, got %v", err)
+	}
+}
+
+func TestBaseHandler_AliasTool(t *testing.T) {
+	h := NewBaseHandler(ServerInfo{Name: "test", Version: "1.0.0"}, ServerCapabilities{
+		Tools: &ToolsCapability{ListChanged: true},
+	})
+	h.RegisterTool(namedTool("web_search"))
+	h.RegisterTool(namedTool("calculator"))
+	h.HandleMessage(context.Background(), NewNotification("initialized", nil))
+
+	changes := 0
+	h.SetNotifier(func(notification *Message) { changes++ })
+
+	if err := h.AliasTool("search", "web_search"); err != nil {
+		t.Fatalf("AliasTool failed: %v", err)
+	}
+	if changes != 1 {
+		t.Errorf("Expected a list_changed notification, got %d", changes)
+	}
+
+	listed, _ := h.ListTools()
+	if len(listed) != 3 {
+		t.Errorf("Expected the alias to be listed, got %d tools", len(listed))
+	}
+	if _, err := h.CallTool(context.Background(), &CallToolParams{Name: "search"}); err != nil {
+		t.Errorf("Expected the alias to be callable, got %v", err)
+	}
+
+	// Collisions are rejected
+	if err := h.AliasTool("calculator", "web_search"); err == nil {
+		t.Error("Expected an alias named like a tool to be rejected")
+	}
+	if err := h.AliasTool("search", "calculator"); err == nil {
+		t.Error("Expected a duplicate alias to be rejected")
+	}
+	if err := h.AliasTool("find", "missing"); err == nil {
+		t.Error("Expected an alias of an unknown tool to be rejected")
+	}
+
+	// The alias follows its target
+	h.DisableTool("web_search")
+	if listed, _ := h.ListTools(); len(listed) != 1 {
+		t.Errorf("Expected the alias to be hidden with its target, got %d tools", len(listed))
+	}
+	_, err := h.CallTool(context.Background(), &CallToolParams{Name: "search"})
+	var info *ErrorInfo
+	if !errors.As(err, &info) || info.Code != ToolNotFound {
+		t.Errorf("Expected the alias of a disabled tool to be unavailable, got %v", err)
+	}
+}
@@ -0,0 +1,173 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ResourceConversion turns resource contents of one MIME type into
+// another, such as HTML into Markdown. Clients ask for a conversion with
+// the experimental accept parameter of resources/read.
+type ResourceConversion struct {
+	From    string
+	To      string
+	Convert func(ctx context.Context, contents ResourceContents) (ResourceContents, error)
+}
+
+// RegisterResourceConversion adds a conversion; a later conversion between
+// the same types replaces an earlier one. The conversions are advertised
+// under the experimental resourceAccept capability.
+func (h *BaseHandler) RegisterResourceConversion(conversion ResourceConversion) {
+	conversion.From = baseMediaType(conversion.From)
+	conversion.To = baseMediaType(conversion.To)
+
+	h.toolsMu.Lock()
+	defer h.toolsMu.Unlock()
+
+	for i, existing := range h.conversions {
+		if existing.From == conversion.From && existing.To == conversion.To {
+			conversions := append([]ResourceConversion(nil), h.conversions...)
+			conversions[i] = conversion
+			h.conversions = conversions
+			return
+		}
+	}
+	h.conversions = append(h.conversions, conversion)
+}
+
+// resourceConversions returns the registered conversions; the slice is
+// never modified in place
+func (h *BaseHandler) resourceConversions() []ResourceConversion {
+	h.toolsMu.RLock()
+	defer h.toolsMu.RUnlock()
+	return h.conversions
+}
+
+// advertisedCapabilities returns the server capabilities, adding the
+// resource conversions to the experimental capabilities
+func (h *BaseHandler) advertisedCapabilities() ServerCapabilities {
+	capabilities := h.capabilities
+	conversions := h.resourceConversions()
+	if len(conversions) == 0 || capabilities.Resources == nil {
+		return capabilities
+	}
+
+	experimental := make(map[string]interface{}, len(capabilities.Experimental)+1)
+	for name, value := range capabilities.Experimental {
+		experimental[name] = value
+	}
+	advertised := make([]map[string]string, len(conversions))
+	for i, conversion := range conversions {
+		advertised[i] = map[string]string{"from": conversion.From, "to": conversion.To}
+	}
+	experimental["resourceAccept"] = map[string]interface{}{"conversions": advertised}
+	capabilities.Experimental = experimental
+	return capabilities
+}
+
+// negotiateContents returns contents in a type accept allows, converting
+// those that are not. accept is a comma separated list of MIME types as
+// in an HTTP Accept header; type/* and */* are wildcards and q values
+// order the choices.
+func (h *BaseHandler) negotiateContents(ctx context.Context, uri, accept string, contents []ResourceContents) ([]ResourceContents, error) {
+	accepted := parseAccept(accept)
+	if len(accepted) == 0 {
+		return contents, nil
+	}
+	conversions := h.resourceConversions()
+
+	negotiated := make([]ResourceContents, len(contents))
+	for i, item := range contents {
+		converted, ok, err := convertContents(ctx, conversions, accepted, item)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert %s to %s: %w", uri, accept, err)
+		}
+		if !ok {
+			return nil, NewError(InvalidParams, fmt.Sprintf("resource %s is not available as %s", uri, accept), map[string]interface{}{
+				"mimeType": item.MimeType,
+			})
+		}
+		negotiated[i] = converted
+	}
+	return negotiated, nil
+}
+
+// convertContents returns item in the most preferred accepted type it is
+// in or can be converted to
+func convertContents(ctx context.Context, conversions []ResourceConversion, accepted []string, item ResourceContents) (ResourceContents, bool, error) {
+	for _, pattern := range accepted {
+		if mimeMatches(pattern, item.MimeType) {
+			return item, true, nil
+		}
+		for _, conversion := range conversions {
+			if conversion.From != baseMediaType(item.MimeType) || !mimeMatches(pattern, conversion.To) {
+				continue
+			}
+			converted, err := conversion.Convert(ctx, item)
+			if err != nil {
+				return ResourceContents{}, false, err
+			}
+			converted.URI = item.URI
+			converted.MimeType = conversion.To
+			return converted, true, nil
+		}
+	}
+	return ResourceContents{}, false, nil
+}
+
+// parseAccept returns the media types in accept, most preferred first.
+// Types with q=0 are dropped.
+func parseAccept(accept string) []string {
+	type choice struct {
+		mediaType string
+		q         float64
+	}
+	var choices []choice
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if value, ok := params["q"]; ok {
+			if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+				q = parsed
+			}
+		}
+		if q > 0 {
+			choices = append(choices, choice{mediaType: mediaType, q: q})
+		}
+	}
+	sort.SliceStable(choices, func(i, j int) bool { return choices[i].q > choices[j].q })
+
+	types := make([]string, len(choices))
+	for i, c := range choices {
+		types[i] = c.mediaType
+	}
+	return types
+}
+
+// mimeMatches reports whether mimeType matches pattern, which may be
+// type/* or */*
+func mimeMatches(pattern, mimeType string) bool {
+	mimeType = baseMediaType(mimeType)
+	switch {
+	case pattern == "*/*":
+		return true
+	case strings.HasSuffix(pattern, "/*"):
+		return strings.HasPrefix(mimeType, strings.TrimSuffix(pattern, "*"))
+	}
+	return pattern == mimeType
+}
+
+// baseMediaType drops the parameters of a MIME type and lower-cases it
+func baseMediaType(mimeType string) string {
+	if i := strings.IndexByte(mimeType, ';'); i >= 0 {
+		mimeType = mimeType[:i]
+	}
+	return strings.ToLower(strings.TrimSpace(mimeType))
+}
@@ -0,0 +1,68 @@
+package mcp
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// htmlResource serves a small web page
+type htmlResource struct{}
+
+func (htmlResource) Definition() *Resource {
+	return &Resource{URI: "https://example.com/", Name: "Page", MimeType: "text/html"}
+}
+
+func (htmlResource) Read(ctx context.Context, uri string) (*ReadResourceResult, error) {
+	return &ReadResourceResult{Contents: []ResourceContents{NewTextResourceContents(uri, "text/html; charset=utf-8", "<h1>Title</h1>")}}, nil
+}
+
+func TestBaseHandler_ResourceAccept(t *testing.T) {
+	h := NewBaseHandler(ServerInfo{Name: "test", Version: "1.0.0"}, ServerCapabilities{Resources: &ResourcesCapability{}})
+	h.RegisterResource(htmlResource{})
+	h.RegisterResource(pdfResource{data: []byte("%PDF")})
+	h.RegisterResourceConversion(ResourceConversion{
+		From: "text/html",
+		To:   "text/markdown",
+		Convert: func(ctx context.Context, contents ResourceContents) (ResourceContents, error) {
+			return ResourceContents{Text: "# " + strings.TrimSuffix(strings.TrimPrefix(contents.Text, "<h1>"), "</h1>")}, nil
+		},
+	})
+	ctx := context.Background()
+	h.HandleMessage(ctx, NewNotification("initialized", nil))
+
+	tests := []struct {
+		accept   string
+		mimeType string
+		text     string
+	}{
+		{"", "text/html; charset=utf-8", "<h1>Title</h1>"},
+		{"text/markdown", "text/markdown", "# Title"},
+		{"text/html, text/markdown", "text/html; charset=utf-8", "<h1>Title</h1>"},
+		{"text/html;q=0.5, text/markdown", "text/markdown", "# Title"},
+		{"text/*", "text/html; charset=utf-8", "<h1>Title</h1>"},
+		{"text/markdown, */*;q=0.1", "text/markdown", "# Title"},
+	}
+	for _, tt := range tests {
+		result, err := h.ReadResource(ctx, &ReadResourceParams{URI: "https://example.com/", Accept: tt.accept})
+		if err != nil {
+			t.Errorf("accept %q: unexpected error %v", tt.accept, err)
+			continue
+		}
+		contents := result.Contents[0]
+		if contents.MimeType != tt.mimeType || contents.Text != tt.text || contents.URI != "https://example.com/" {
+			t.Errorf("accept %q: got %+v", tt.accept, contents)
+		}
+	}
+
+	_, err := h.ReadResource(ctx, &ReadResourceParams{URI: "file:///report.pdf", Accept: "text/plain"})
+	if info, ok := AsErrorInfo(err); !ok || info.Code != InvalidParams {
+		t.Errorf("Expected an unconvertible read to fail with invalid params, got %v", err)
+	}
+
+	response, _ := h.HandleMessage(ctx, NewRequest(1, "initialize", map[string]interface{}{"protocolVersion": MCPVersion}))
+	capabilities := response.Result.(*InitializeResult).Capabilities
+	if _, ok := capabilities.Experimental["resourceAccept"]; !ok {
+		t.Errorf("Expected the conversions to be advertised, got %+v", capabilities.Experimental)
+	}
+}
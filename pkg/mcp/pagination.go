@@ -0,0 +1,68 @@
+package mcp
+
+import (
+	"encoding/base64"
+	"sort"
+)
+
+// PaginatedParams represents the cursor sent with list requests
+type PaginatedParams struct {
+	Cursor string `json:"cursor,omitempty"`
+}
+
+// SetPageSize sets how many items each list response holds before the
+// client must follow nextCursor; 0 or less returns everything at once
+func (h *BaseHandler) SetPageSize(size int) {
+	h.toolsMu.Lock()
+	defer h.toolsMu.Unlock()
+	h.pageSize = size
+}
+
+// paginate returns the page of items following cursor and the cursor of
+// the next page, which is empty on the last page. Items are ordered by key,
+// and the cursor names the last key already returned, so adding or
+// removing items between requests neither repeats nor skips the rest.
+func paginate[T any](items []T, key func(T) string, cursor string, size int) ([]T, string, error) {
+	sort.Slice(items, func(i, j int) bool { return key(items[i]) < key(items[j]) })
+
+	start := 0
+	if cursor != "" {
+		after, err := base64.RawURLEncoding.DecodeString(cursor)
+		if err != nil {
+			return nil, "", InvalidParamsError("cursor", "invalid cursor")
+		}
+		start = sort.Search(len(items), func(i int) bool { return key(items[i]) > string(after) })
+	}
+
+	if size <= 0 || start+size >= len(items) {
+		return items[start:], "", nil
+	}
+	page := items[start : start+size]
+	return page, base64.RawURLEncoding.EncodeToString([]byte(key(page[len(page)-1]))), nil
+}
+
+// listResult builds a list response holding items under name, adding
+// nextCursor when more pages follow
+func listResult(name string, items interface{}, next string) map[string]interface{} {
+	result := map[string]interface{}{
+		name: items,
+	}
+	if next != "" {
+		result["nextCursor"] = next
+	}
+	return result
+}
+
+// toolName, resourceURI, resourceTemplateURI and promptName are the keys
+// list pages are ordered by
+func toolName(tool *Tool) string                            { return tool.Name }
+func resourceURI(resource *Resource) string                 { return resource.URI }
+func resourceTemplateURI(template *ResourceTemplate) string { return template.URITemplate }
+func promptName(prompt *Prompt) string                      { return prompt.Name }
+
+// pageSizeLimit returns the configured page size
+func (h *BaseHandler) pageSizeLimit() int {
+	h.toolsMu.RLock()
+	defer h.toolsMu.RUnlock()
+	return h.pageSize
+}
@@ -0,0 +1,62 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+// namedTool is a tool that only has a name
+type namedTool string
+
+func (n namedTool) Definition() *Tool {
+	return &Tool{Name: string(n), InputSchema: ToolSchema{Type: "object"}}
+}
+
+func (namedTool) Execute(ctx context.Context, params map[string]interface{}) (*CallToolResult, error) {
+	return &CallToolResult{}, nil
+}
+
+func TestBaseHandler_PaginatesToolsList(t *testing.T) {
+	h := NewBaseHandler(ServerInfo{Name: "test", Version: "1.0.0"}, ServerCapabilities{})
+	h.SetPageSize(2)
+	for i := 0; i < 5; i++ {
+		h.RegisterTool(namedTool(fmt.Sprintf("tool-%d", i)))
+	}
+
+	var names []string
+	cursor := ""
+	for pages := 1; ; pages++ {
+		response, _ := h.HandleMessage(context.Background(), NewRequest(pages, "tools/list", ListToolsParams{Cursor: cursor}))
+		if response.Error != nil {
+			t.Fatalf("tools/list failed: %v", response.Error.Message)
+		}
+		result := response.Result.(map[string]interface{})
+		for _, tool := range result["tools"].([]*Tool) {
+			names = append(names, tool.Name)
+		}
+
+		next, _ := result["nextCursor"].(string)
+		if next == "" {
+			if pages != 3 {
+				t.Errorf("Expected 3 pages, got %d", pages)
+			}
+			break
+		}
+		cursor = next
+	}
+
+	expected := []string{"tool-0", "tool-1", "tool-2", "tool-3", "tool-4"}
+	if fmt.Sprint(names) != fmt.Sprint(expected) {
+		t.Errorf("Expected %v, got %v", expected, names)
+	}
+}
+
+func TestBaseHandler_RejectsInvalidCursor(t *testing.T) {
+	h := NewBaseHandler(ServerInfo{Name: "test", Version: "1.0.0"}, ServerCapabilities{})
+
+	response, _ := h.HandleMessage(context.Background(), NewRequest(1, "prompts/list", PaginatedParams{Cursor: "not base64!"}))
+	if response.Error == nil || response.Error.Code != InvalidParams {
+		t.Errorf("Expected invalid params for a malformed cursor, got %+v", response.Error)
+	}
+}
@@ -0,0 +1,44 @@
+package mcp
+
+import "context"
+
+// ProgressReporter reports how far a tool call has got. total is 0 when
+// unknown and message may be empty.
+type ProgressReporter func(progress, total float64, message string)
+
+// progressKey is the context key for the current request's progress reporter
+type progressKey struct{}
+
+// withProgress returns a context whose progress reporter sends
+// notifications/progress to the client that made the request, if it asked
+// for them with a progress token
+func withProgress(ctx context.Context, meta *RequestMeta) context.Context {
+	if meta == nil || meta.ProgressToken == nil {
+		return ctx
+	}
+	session, ok := SessionFromContext(ctx)
+	if !ok {
+		return ctx
+	}
+
+	token := meta.ProgressToken
+	var reporter ProgressReporter = func(progress, total float64, message string) {
+		session.Notify(NewNotification("notifications/progress", ProgressParams{
+			ProgressToken: token,
+			Progress:      progress,
+			Total:         total,
+			Message:       message,
+		}))
+	}
+	return context.WithValue(ctx, progressKey{}, reporter)
+}
+
+// ProgressFromContext returns the reporter a tool uses to send progress
+// about the current call. It is never nil: when the client did not ask for
+// progress, reports are discarded.
+func ProgressFromContext(ctx context.Context) ProgressReporter {
+	if reporter, ok := ctx.Value(progressKey{}).(ProgressReporter); ok {
+		return reporter
+	}
+	return func(float64, float64, string) {}
+}
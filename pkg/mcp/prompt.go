@@ -0,0 +1,79 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+)
+
+// Roles a prompt message can have
+const (
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+)
+
+// NewPromptResult returns a prompt result holding messages
+func NewPromptResult(description string, messages ...PromptMessage) *GetPromptResult {
+	return &GetPromptResult{Description: description, Messages: messages}
+}
+
+// NewPromptMessage returns a message with the given role and content, such
+// as text, images or embedded resources
+func NewPromptMessage(role string, content ...Content) PromptMessage {
+	return PromptMessage{Role: role, Content: content}
+}
+
+// NewUserMessage returns a user message holding text
+func NewUserMessage(text string) PromptMessage {
+	return NewPromptMessage(RoleUser, NewTextContent(text))
+}
+
+// NewResourceReference returns content that GetPrompt replaces with the
+// contents of the registered resource uri. A non-empty mimeType reads the
+// resource in that type, converting it if needed.
+func NewResourceReference(uri, mimeType string) Content {
+	return Content{Type: ContentTypeResource, Resource: &ResourceContents{URI: uri, MimeType: mimeType}}
+}
+
+// isResourceReference reports whether content names a resource without
+// carrying its contents
+func isResourceReference(content Content) bool {
+	return content.Type == ContentTypeResource && content.Resource != nil &&
+		content.Resource.Text == "" && content.Resource.Blob == ""
+}
+
+// resolvePromptMessages embeds the contents of referenced resources and
+// checks that each message is well formed
+func (h *BaseHandler) resolvePromptMessages(ctx context.Context, result *GetPromptResult) (*GetPromptResult, error) {
+	resolved := *result
+	resolved.Messages = make([]PromptMessage, len(result.Messages))
+
+	for i, message := range result.Messages {
+		if message.Role != RoleUser && message.Role != RoleAssistant {
+			return nil, fmt.Errorf("prompt message %d has role %q, expected user or assistant", i, message.Role)
+		}
+
+		content := make([]Content, 0, len(message.Content))
+		for _, item := range message.Content {
+			if !isResourceReference(item) {
+				content = append(content, item)
+				continue
+			}
+			read, err := h.ReadResource(ctx, &ReadResourceParams{URI: item.Resource.URI, Accept: item.Resource.MimeType})
+			if err != nil {
+				return nil, err
+			}
+			for _, contents := range read.Contents {
+				content = append(content, NewEmbeddedResource(contents))
+			}
+		}
+		if err := validateContents(content); err != nil {
+			return nil, fmt.Errorf("prompt message %d: %w", i, err)
+		}
+		if err := checkContentBlobs(h.blobLimit(), content); err != nil {
+			return nil, err
+		}
+
+		resolved.Messages[i] = PromptMessage{Role: message.Role, Content: content}
+	}
+	return &resolved, nil
+}
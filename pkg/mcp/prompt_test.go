@@ -0,0 +1,75 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+)
+
+// contentPrompt returns fixed messages
+type contentPrompt []PromptMessage
+
+func (contentPrompt) Definition() *Prompt {
+	return &Prompt{Name: "content"}
+}
+
+func (p contentPrompt) Generate(ctx context.Context, params map[string]interface{}) (*GetPromptResult, error) {
+	return NewPromptResult("Fixed messages", p...), nil
+}
+
+func TestBaseHandler_GetPromptEmbedsResources(t *testing.T) {
+	h := NewBaseHandler(ServerInfo{Name: "test", Version: "1.0.0"}, ServerCapabilities{
+		Resources: &ResourcesCapability{},
+		Prompts:   &PromptsCapability{},
+	})
+	h.RegisterResource(pdfResource{data: []byte("%PDF-1.4")})
+	h.RegisterResource(htmlResource{})
+	h.RegisterResourceConversion(ResourceConversion{
+		From: "text/html",
+		To:   "text/markdown",
+		Convert: func(ctx context.Context, contents ResourceContents) (ResourceContents, error) {
+			return ResourceContents{Text: "# Title"}, nil
+		},
+	})
+	ctx := context.Background()
+	h.HandleMessage(ctx, NewNotification("initialized", nil))
+
+	png := []byte("\x89PNG\r\n\x1a\n")
+	h.RegisterPrompt(contentPrompt{
+		NewPromptMessage(RoleUser,
+			NewTextContent("Compare these"),
+			NewResourceReference("file:///report.pdf", ""),
+			NewResourceReference("https://example.com/", "text/markdown"),
+			NewImageContent(png, ""),
+		),
+	})
+
+	result, err := h.GetPrompt(ctx, &GetPromptParams{Name: "content"})
+	if err != nil {
+		t.Fatalf("GetPrompt failed: %v", err)
+	}
+	content := result.Messages[0].Content
+	if len(content) != 4 {
+		t.Fatalf("Expected 4 content items, got %+v", content)
+	}
+	if pdf := content[1].Resource; pdf.MimeType != "application/pdf" || pdf.Blob == "" {
+		t.Errorf("Expected the PDF to be embedded, got %+v", pdf)
+	}
+	if page := content[2].Resource; page.MimeType != "text/markdown" || page.Text != "# Title" {
+		t.Errorf("Expected the page as Markdown, got %+v", page)
+	}
+	if content[3].Type != ContentTypeImage || content[3].MimeType != "image/png" {
+		t.Errorf("Expected the image to be kept, got %+v", content[3])
+	}
+
+	tests := map[string]contentPrompt{
+		"unknown resource": {NewPromptMessage(RoleUser, NewResourceReference("file:///missing.txt", ""))},
+		"invalid image":    {NewPromptMessage(RoleUser, Content{Type: ContentTypeImage, Data: "aGk=", MimeType: "text/plain"})},
+		"invalid role":     {NewPromptMessage("system", NewTextContent("Hi"))},
+	}
+	for name, prompt := range tests {
+		h.RegisterPrompt(prompt)
+		if _, err := h.GetPrompt(ctx, &GetPromptParams{Name: "content"}); err == nil {
+			t.Errorf("%s: expected GetPrompt to fail", name)
+		}
+	}
+}
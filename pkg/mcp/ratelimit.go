@@ -0,0 +1,129 @@
+package mcp
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// ToolRateLimit is the pace of calls a tool accepts from all clients
+// together
+type ToolRateLimit struct {
+	RequestsPerSecond float64
+	Burst             int
+}
+
+// RateLimitTools limits calls to the tools named in limits, shared across
+// all clients, for example to stay within an upstream API's quota. Calls
+// over the limit do not reach the tool and fail with a RateLimited error
+// result whose data carries the tool and retryAfterMs. Use a
+// ToolRateLimiter to change the limits while the server runs.
+func RateLimitTools(limits map[string]ToolRateLimit) Middleware {
+	return NewToolRateLimiter(limits).Middleware()
+}
+
+// ToolRateLimiter limits tool calls as RateLimitTools describes, with
+// limits that can be changed while the server runs
+type ToolRateLimiter struct {
+	mu       sync.RWMutex
+	limiters map[string]*rate.Limiter
+	shared   SharedStore
+}
+
+// NewToolRateLimiter creates a limiter enforcing limits
+func NewToolRateLimiter(limits map[string]ToolRateLimit) *ToolRateLimiter {
+	l := &ToolRateLimiter{}
+	l.SetLimits(limits)
+	return l
+}
+
+// SetLimits replaces the limits. Tools whose limit is unchanged keep the
+// tokens they have left.
+func (l *ToolRateLimiter) SetLimits(limits map[string]ToolRateLimit) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	limiters := make(map[string]*rate.Limiter, len(limits))
+	for name, limit := range limits {
+		if limiter, exists := l.limiters[name]; exists &&
+			limiter.Limit() == rate.Limit(limit.RequestsPerSecond) && limiter.Burst() == limit.Burst {
+			limiters[name] = limiter
+			continue
+		}
+		limiters[name] = rate.NewLimiter(rate.Limit(limit.RequestsPerSecond), limit.Burst)
+	}
+	l.limiters = limiters
+}
+
+// SetShared counts calls in store, so the limits apply to all replicas
+// together rather than to each. Calls fall back to the local limits while
+// the store fails. It must be called before the server starts.
+func (l *ToolRateLimiter) SetShared(store SharedStore) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.shared = store
+}
+
+// Middleware returns middleware applying the limits current at each call
+func (l *ToolRateLimiter) Middleware() Middleware {
+	return func(next ToolHandler) ToolHandler {
+		name := next.Definition().Name
+		return WrapTool(next, func(ctx context.Context, params map[string]interface{}) (*CallToolResult, error) {
+			l.mu.RLock()
+			limiter, limited := l.limiters[name]
+			shared := l.shared
+			l.mu.RUnlock()
+
+			if limited {
+				if wait := reserveSharedCall(ctx, shared, name, limiter); wait > 0 {
+					return rateLimitedResult(name, wait), nil
+				}
+			}
+			return next.Execute(ctx, params)
+		})
+	}
+}
+
+// reserveSharedCall counts a call to tool in shared, or when there is no
+// shared store or it fails takes a token from limiter, and returns how long
+// until a call would be allowed
+func reserveSharedCall(ctx context.Context, shared SharedStore, tool string, limiter *rate.Limiter) time.Duration {
+	if shared != nil {
+		window := SharedWindow{
+			Store: shared,
+			Key:   "tool:" + tool,
+			Rate:  float64(limiter.Limit()),
+			Burst: limiter.Burst(),
+		}
+		if wait, err := window.Reserve(ctx, time.Now()); err == nil {
+			return wait
+		}
+	}
+	return reserveCall(limiter)
+}
+
+// reserveCall takes a token from limiter and returns zero, or, when it is
+// empty, takes nothing and returns how long until a call would be allowed
+func reserveCall(limiter *rate.Limiter) time.Duration {
+	now := time.Now()
+	reservation := limiter.ReserveN(now, 1)
+	wait := reservation.DelayFrom(now)
+	if wait > 0 {
+		// Rejected calls do not use up the limit
+		reservation.CancelAt(now)
+	}
+	return wait
+}
+
+// rateLimitedResult reports a call refused by a ToolRateLimiter
+func rateLimitedResult(tool string, retryAfter time.Duration) *CallToolResult {
+	info := RateLimitedError(retryAfter)
+	info.Message = "tool " + tool + ": " + info.Message
+	info.Data = map[string]interface{}{
+		"tool":         tool,
+		"retryAfterMs": retryAfter.Milliseconds(),
+	}
+	return NewErrorResult(info)
+}
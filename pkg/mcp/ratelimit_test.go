@@ -0,0 +1,106 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+)
+
+func TestRateLimitTools(t *testing.T) {
+	h := NewBaseHandler(ServerInfo{Name: "test", Version: "1.0.0"}, ServerCapabilities{})
+	h.RegisterTool(namedTool("web_search"))
+	h.RegisterTool(namedTool("calculator"))
+	h.Use(RateLimitTools(map[string]ToolRateLimit{
+		"web_search": {RequestsPerSecond: 0.1, Burst: 2},
+	}))
+	h.HandleMessage(context.Background(), NewNotification("initialized", nil))
+
+	for i := 0; i < 2; i++ {
+		result, err := h.CallTool(context.Background(), &CallToolParams{Name: "web_search"})
+		if err != nil || result.IsError {
+			t.Fatalf("Call %d: expected the burst to be allowed, got %+v, %v", i, result, err)
+		}
+	}
+
+	result, err := h.CallTool(context.Background(), &CallToolParams{Name: "web_search"})
+	if err != nil || !result.IsError {
+		t.Fatalf("Expected a rate limited error result, got %+v, %v", result, err)
+	}
+	structured, _ := result.StructuredContent.(map[string]interface{})
+	info, _ := structured["error"].(*ErrorInfo)
+	if info == nil || info.Code != RateLimited {
+		t.Fatalf("Expected a RateLimited error in the structured content, got %#v", result.StructuredContent)
+	}
+	data := info.Data.(map[string]interface{})
+	if data["tool"] != "web_search" || data["retryAfterMs"].(int64) <= 0 {
+		t.Errorf("Expected the tool and a retry delay, got %v", data)
+	}
+
+	// Other tools are not limited
+	for i := 0; i < 5; i++ {
+		if result, _ := h.CallTool(context.Background(), &CallToolParams{Name: "calculator"}); result.IsError {
+			t.Fatal("Expected calls to an unlimited tool to succeed")
+		}
+	}
+}
+
+func TestToolRateLimiter_SetLimits(t *testing.T) {
+	h := NewBaseHandler(ServerInfo{Name: "test", Version: "1.0.0"}, ServerCapabilities{})
+	h.RegisterTool(namedTool("web_search"))
+	limiter := NewToolRateLimiter(nil)
+	h.Use(limiter.Middleware())
+	ctx := context.Background()
+	h.HandleMessage(ctx, NewNotification("initialized", nil))
+
+	calls := func() int {
+		allowed := 0
+		for i := 0; i < 5; i++ {
+			if result, _ := h.CallTool(ctx, &CallToolParams{Name: "web_search"}); !result.IsError {
+				allowed++
+			}
+		}
+		return allowed
+	}
+
+	if allowed := calls(); allowed != 5 {
+		t.Fatalf("Expected calls without limits to succeed, got %d of 5", allowed)
+	}
+	limiter.SetLimits(map[string]ToolRateLimit{"web_search": {RequestsPerSecond: 0.1, Burst: 2}})
+	if allowed := calls(); allowed != 2 {
+		t.Fatalf("Expected the new limit to allow 2 calls, got %d", allowed)
+	}
+
+	// An unchanged limit keeps its empty bucket; a changed one starts over
+	limiter.SetLimits(map[string]ToolRateLimit{"web_search": {RequestsPerSecond: 0.1, Burst: 2}})
+	if allowed := calls(); allowed != 0 {
+		t.Errorf("Expected an unchanged limit to keep its state, got %d calls", allowed)
+	}
+	limiter.SetLimits(map[string]ToolRateLimit{"web_search": {RequestsPerSecond: 0.1, Burst: 3}})
+	if allowed := calls(); allowed != 3 {
+		t.Errorf("Expected a changed limit to allow 3 calls, got %d", allowed)
+	}
+}
+
+func TestToolRateLimiter_Shared(t *testing.T) {
+	shared := newMemoryShared()
+	limits := map[string]ToolRateLimit{"web_search": {RequestsPerSecond: 0.1, Burst: 2}}
+	ctx := context.Background()
+
+	allowed := 0
+	for i := 0; i < 2; i++ {
+		h := NewBaseHandler(ServerInfo{Name: "test", Version: "1.0.0"}, ServerCapabilities{})
+		h.RegisterTool(namedTool("web_search"))
+		limiter := NewToolRateLimiter(limits)
+		limiter.SetShared(shared)
+		h.Use(limiter.Middleware())
+		h.HandleMessage(ctx, NewNotification("initialized", nil))
+
+		for j := 0; j < 2; j++ {
+			if result, _ := h.CallTool(ctx, &CallToolParams{Name: "web_search"}); !result.IsError {
+				allowed++
+			}
+		}
+	}
+	if allowed != 2 {
+		t.Errorf("Expected the burst to be shared by both replicas, got %d calls", allowed)
+	}
+}
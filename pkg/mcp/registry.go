@@ -0,0 +1,270 @@
+package mcp
+
+import (
+	"fmt"
+	"sync"
+)
+
+// ToolRegistry stores the tools a BaseHandler serves. Register adds a
+// handler under its tool name, replacing any tool already registered with
+// that name. Implementations must be safe for concurrent use and call the
+// OnChange function after every change, without holding locks the
+// function could need.
+type ToolRegistry interface {
+	Register(handler ToolHandler) error
+	Unregister(name string) error
+	Get(name string) (ToolHandler, error)
+	List() []*Tool
+	Count() int
+	OnChange(fn func())
+}
+
+// ResourceRegistry stores the resources a BaseHandler serves, keyed by URI,
+// with the same rules as ToolRegistry
+type ResourceRegistry interface {
+	Register(handler ResourceHandler) error
+	Unregister(uri string) error
+	Get(uri string) (ResourceHandler, error)
+	List() []*Resource
+	Count() int
+	OnChange(fn func())
+}
+
+// PromptRegistry stores the prompts a BaseHandler serves, with the same
+// rules as ToolRegistry
+type PromptRegistry interface {
+	Register(handler PromptHandler) error
+	Unregister(name string) error
+	Get(name string) (PromptHandler, error)
+	List() []*Prompt
+	Count() int
+	OnChange(fn func())
+}
+
+// Registries selects the stores of a BaseHandler; nil fields get in-memory
+// registries
+type Registries struct {
+	Tools     ToolRegistry
+	Resources ResourceRegistry
+	Prompts   PromptRegistry
+}
+
+// handlerMap is the in-memory store behind the default registries
+type handlerMap[H any] struct {
+	mu       sync.RWMutex
+	handlers map[string]H
+	onChange func()
+}
+
+func newHandlerMap[H any]() handlerMap[H] {
+	return handlerMap[H]{handlers: make(map[string]H)}
+}
+
+// put stores handler under key and reports the change
+func (m *handlerMap[H]) put(key string, handler H) {
+	m.mu.Lock()
+	m.handlers[key] = handler
+	onChange := m.onChange
+	m.mu.Unlock()
+
+	if onChange != nil {
+		onChange()
+	}
+}
+
+// remove deletes key, reporting the change and whether it existed
+func (m *handlerMap[H]) remove(key string) bool {
+	m.mu.Lock()
+	_, exists := m.handlers[key]
+	delete(m.handlers, key)
+	onChange := m.onChange
+	m.mu.Unlock()
+
+	if exists && onChange != nil {
+		onChange()
+	}
+	return exists
+}
+
+// get returns the handler stored under key
+func (m *handlerMap[H]) get(key string) (H, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	handler, exists := m.handlers[key]
+	return handler, exists
+}
+
+// all returns every stored handler
+func (m *handlerMap[H]) all() []H {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	handlers := make([]H, 0, len(m.handlers))
+	for _, handler := range m.handlers {
+		handlers = append(handlers, handler)
+	}
+	return handlers
+}
+
+// Count returns the number of stored handlers
+func (m *handlerMap[H]) Count() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.handlers)
+}
+
+// OnChange sets the function called after a handler is added or removed
+func (m *handlerMap[H]) OnChange(fn func()) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onChange = fn
+}
+
+// memoryToolRegistry is the default ToolRegistry
+type memoryToolRegistry struct {
+	handlerMap[ToolHandler]
+}
+
+// NewToolRegistry returns an empty in-memory ToolRegistry
+func NewToolRegistry() ToolRegistry {
+	return &memoryToolRegistry{newHandlerMap[ToolHandler]()}
+}
+
+// Register implements ToolRegistry
+func (r *memoryToolRegistry) Register(handler ToolHandler) error {
+	tool := handler.Definition()
+	if tool == nil {
+		return fmt.Errorf("tool definition cannot be nil")
+	}
+	if tool.Name == "" {
+		return fmt.Errorf("tool name cannot be empty")
+	}
+	r.put(tool.Name, handler)
+	return nil
+}
+
+// Unregister implements ToolRegistry
+func (r *memoryToolRegistry) Unregister(name string) error {
+	if !r.remove(name) {
+		return ToolNotFoundError(name)
+	}
+	return nil
+}
+
+// Get implements ToolRegistry
+func (r *memoryToolRegistry) Get(name string) (ToolHandler, error) {
+	handler, exists := r.get(name)
+	if !exists {
+		return nil, ToolNotFoundError(name)
+	}
+	return handler, nil
+}
+
+// List implements ToolRegistry
+func (r *memoryToolRegistry) List() []*Tool {
+	handlers := r.all()
+	tools := make([]*Tool, 0, len(handlers))
+	for _, handler := range handlers {
+		tools = append(tools, handler.Definition())
+	}
+	return tools
+}
+
+// memoryResourceRegistry is the default ResourceRegistry
+type memoryResourceRegistry struct {
+	handlerMap[ResourceHandler]
+}
+
+// NewResourceRegistry returns an empty in-memory ResourceRegistry
+func NewResourceRegistry() ResourceRegistry {
+	return &memoryResourceRegistry{newHandlerMap[ResourceHandler]()}
+}
+
+// Register implements ResourceRegistry
+func (r *memoryResourceRegistry) Register(handler ResourceHandler) error {
+	resource := handler.Definition()
+	if resource == nil {
+		return fmt.Errorf("resource definition cannot be nil")
+	}
+	if resource.URI == "" {
+		return fmt.Errorf("resource URI cannot be empty")
+	}
+	r.put(resource.URI, handler)
+	return nil
+}
+
+// Unregister implements ResourceRegistry
+func (r *memoryResourceRegistry) Unregister(uri string) error {
+	if !r.remove(uri) {
+		return ResourceNotFoundError(uri)
+	}
+	return nil
+}
+
+// Get implements ResourceRegistry
+func (r *memoryResourceRegistry) Get(uri string) (ResourceHandler, error) {
+	handler, exists := r.get(uri)
+	if !exists {
+		return nil, ResourceNotFoundError(uri)
+	}
+	return handler, nil
+}
+
+// List implements ResourceRegistry
+func (r *memoryResourceRegistry) List() []*Resource {
+	handlers := r.all()
+	resources := make([]*Resource, 0, len(handlers))
+	for _, handler := range handlers {
+		resources = append(resources, handler.Definition())
+	}
+	return resources
+}
+
+// memoryPromptRegistry is the default PromptRegistry
+type memoryPromptRegistry struct {
+	handlerMap[PromptHandler]
+}
+
+// NewPromptRegistry returns an empty in-memory PromptRegistry
+func NewPromptRegistry() PromptRegistry {
+	return &memoryPromptRegistry{newHandlerMap[PromptHandler]()}
+}
+
+// Register implements PromptRegistry
+func (r *memoryPromptRegistry) Register(handler PromptHandler) error {
+	prompt := handler.Definition()
+	if prompt == nil {
+		return fmt.Errorf("prompt definition cannot be nil")
+	}
+	if prompt.Name == "" {
+		return fmt.Errorf("prompt name cannot be empty")
+	}
+	r.put(prompt.Name, handler)
+	return nil
+}
+
+// Unregister implements PromptRegistry
+func (r *memoryPromptRegistry) Unregister(name string) error {
+	if !r.remove(name) {
+		return PromptNotFoundError(name)
+	}
+	return nil
+}
+
+// Get implements PromptRegistry
+func (r *memoryPromptRegistry) Get(name string) (PromptHandler, error) {
+	handler, exists := r.get(name)
+	if !exists {
+		return nil, PromptNotFoundError(name)
+	}
+	return handler, nil
+}
+
+// List implements PromptRegistry
+func (r *memoryPromptRegistry) List() []*Prompt {
+	handlers := r.all()
+	prompts := make([]*Prompt, 0, len(handlers))
+	for _, handler := range handlers {
+		prompts = append(prompts, handler.Definition())
+	}
+	return prompts
+}
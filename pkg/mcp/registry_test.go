@@ -0,0 +1,89 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+)
+
+// staticPrompt is a prompt with an empty result
+type staticPrompt string
+
+func (p staticPrompt) Definition() *Prompt {
+	return &Prompt{Name: string(p)}
+}
+
+func (staticPrompt) Generate(ctx context.Context, params map[string]interface{}) (*GetPromptResult, error) {
+	return &GetPromptResult{}, nil
+}
+
+func TestBaseHandler_ServesSharedRegistries(t *testing.T) {
+	tools := NewToolRegistry()
+	prompts := NewPromptRegistry()
+	h := NewBaseHandlerWithRegistries(ServerInfo{Name: "test", Version: "1.0.0"}, ServerCapabilities{
+		Tools:   &ToolsCapability{ListChanged: true},
+		Prompts: &PromptsCapability{ListChanged: true},
+	}, Registries{Tools: tools, Prompts: prompts})
+
+	var notifications []string
+	h.SetNotifier(func(notification *Message) {
+		notifications = append(notifications, notification.Method)
+	})
+
+	// Tools registered in the registry are served and announced
+	if err := tools.Register(namedTool("direct")); err != nil {
+		t.Fatalf("Failed to register tool: %v", err)
+	}
+	if err := h.RegisterTool(namedTool("via-handler")); err != nil {
+		t.Fatalf("Failed to register tool: %v", err)
+	}
+	listed, _ := h.ListTools()
+	if len(listed) != 2 || tools.Count() != 2 {
+		t.Errorf("Expected both tools in the handler and the registry, got %d and %d", len(listed), tools.Count())
+	}
+
+	if err := prompts.Register(staticPrompt("summary")); err != nil {
+		t.Fatalf("Failed to register prompt: %v", err)
+	}
+	if listed, _ := h.ListPrompts(); len(listed) != 1 {
+		t.Errorf("Expected the registry's prompt, got %d", len(listed))
+	}
+
+	expected := []string{"notifications/tools/list_changed", "notifications/tools/list_changed", "notifications/prompts/list_changed"}
+	if len(notifications) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, notifications)
+	}
+	for i := range expected {
+		if notifications[i] != expected[i] {
+			t.Errorf("Notification %d: expected %s, got %s", i, expected[i], notifications[i])
+		}
+	}
+
+	// Resources have no listChanged capability here, so nothing is sent
+	h.RegisterResource(pdfResource{})
+	if len(notifications) != len(expected) {
+		t.Errorf("Expected no resources/list_changed without the capability, got %v", notifications)
+	}
+}
+
+func TestToolRegistry_ReplacesAndUnregisters(t *testing.T) {
+	registry := NewToolRegistry()
+	changes := 0
+	registry.OnChange(func() { changes++ })
+
+	registry.Register(namedTool("tool"))
+	registry.Register(namedTool("tool"))
+	if registry.Count() != 1 || changes != 2 {
+		t.Errorf("Expected one replaced tool and two changes, got %d and %d", registry.Count(), changes)
+	}
+
+	if err := registry.Unregister("tool"); err != nil {
+		t.Errorf("Unregister failed: %v", err)
+	}
+	err := registry.Unregister("tool")
+	if info, ok := AsErrorInfo(err); !ok || info.Code != ToolNotFound {
+		t.Errorf("Expected a tool not found error, got %v", err)
+	}
+	if changes != 3 {
+		t.Errorf("Expected no change for a missing tool, got %d changes", changes)
+	}
+}
@@ -0,0 +1,100 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy says how failed calls to a tool are retried
+type RetryPolicy struct {
+	// MaxAttempts counts the first call too; below 2 disables retries
+	MaxAttempts int
+	// Backoff is the wait before the first retry; it doubles for each
+	// further retry, up to MaxBackoff when that is set
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// Retryable reports whether a failed call may succeed when repeated;
+	// nil uses RetryOnErrors
+	Retryable func(result *CallToolResult, err error) bool
+}
+
+// RetryOnErrors classifies plain Go errors as transient, such as a failed
+// HTTP request, and error results carrying an InternalError or RateLimited
+// code. Typed MCP errors, cancellation and other error results are final.
+func RetryOnErrors(result *CallToolResult, err error) bool {
+	if err != nil {
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return false
+		}
+		_, typed := AsErrorInfo(err)
+		return !typed
+	}
+	if result == nil || !result.IsError {
+		return false
+	}
+	structured, _ := result.StructuredContent.(map[string]interface{})
+	info, _ := structured["error"].(*ErrorInfo)
+	return info != nil && (info.Code == InternalError || info.Code == RateLimited)
+}
+
+// RetryOnErrorResults classifies every failure but cancellation as
+// transient, for tools that report upstream failures as plain error results
+func RetryOnErrorResults(result *CallToolResult, err error) bool {
+	if err != nil {
+		return RetryOnErrors(result, err)
+	}
+	return result != nil && result.IsError
+}
+
+// RetryTools repeats failed calls to the tools named in policies, so a
+// flaky upstream does not surface every transient failure to the client.
+// Retries wait with exponential backoff and jitter, and stop when the
+// call's context is done. The last attempt's result is returned.
+func RetryTools(policies map[string]RetryPolicy) Middleware {
+	return func(next ToolHandler) ToolHandler {
+		name := next.Definition().Name
+		policy, exists := policies[name]
+		if !exists || policy.MaxAttempts < 2 {
+			return next
+		}
+		retryable := policy.Retryable
+		if retryable == nil {
+			retryable = RetryOnErrors
+		}
+
+		return WrapTool(next, func(ctx context.Context, params map[string]interface{}) (*CallToolResult, error) {
+			backoff := policy.Backoff
+			for attempt := 1; ; attempt++ {
+				result, err := next.Execute(ctx, params)
+				if attempt == policy.MaxAttempts || !retryable(result, err) {
+					return result, err
+				}
+
+				timer := time.NewTimer(jitter(backoff))
+				select {
+				case <-timer.C:
+				case <-ctx.Done():
+					timer.Stop()
+					return result, err
+				}
+
+				backoff *= 2
+				if policy.MaxBackoff > 0 && backoff > policy.MaxBackoff {
+					backoff = policy.MaxBackoff
+				}
+			}
+		})
+	}
+}
+
+// jitter returns a random wait between half of backoff and backoff, so
+// clients failing together do not retry together
+func jitter(backoff time.Duration) time.Duration {
+	if backoff <= 1 {
+		return backoff
+	}
+	half := backoff / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
@@ -0,0 +1,125 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// flakyTool fails its first failures calls in the given way
+type flakyTool struct {
+	name     string
+	failures int
+	fail     func() (*CallToolResult, error)
+	calls    int
+}
+
+func (t *flakyTool) Definition() *Tool {
+	return &Tool{Name: t.name, InputSchema: ToolSchema{Type: "object"}}
+}
+
+func (t *flakyTool) Execute(ctx context.Context, params map[string]interface{}) (*CallToolResult, error) {
+	t.calls++
+	if t.calls <= t.failures {
+		return t.fail()
+	}
+	return &CallToolResult{Content: []Content{NewTextContent("ok")}}, nil
+}
+
+func failWithError() (*CallToolResult, error) {
+	return nil, errors.New("connection reset")
+}
+
+func failWithResult() (*CallToolResult, error) {
+	return &CallToolResult{Content: []Content{NewTextContent("upstream failed")}, IsError: true}, nil
+}
+
+func TestRetryTools(t *testing.T) {
+	tests := []struct {
+		name      string
+		tool      *flakyTool
+		policy    RetryPolicy
+		wantCalls int
+		wantError bool
+	}{
+		{
+			name:      "transient errors are retried",
+			tool:      &flakyTool{failures: 2, fail: failWithError},
+			policy:    RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond},
+			wantCalls: 3,
+		},
+		{
+			name:      "attempts are bounded",
+			tool:      &flakyTool{failures: 5, fail: failWithError},
+			policy:    RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond},
+			wantCalls: 3,
+			wantError: true,
+		},
+		{
+			name: "typed errors are final",
+			tool: &flakyTool{failures: 1, fail: func() (*CallToolResult, error) {
+				return nil, InvalidParamsError("query", "is required")
+			}},
+			policy:    RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond},
+			wantCalls: 1,
+			wantError: true,
+		},
+		{
+			name:      "plain error results are final by default",
+			tool:      &flakyTool{failures: 1, fail: failWithResult},
+			policy:    RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond},
+			wantCalls: 1,
+			wantError: true,
+		},
+		{
+			name: "internal error results are retried",
+			tool: &flakyTool{failures: 1, fail: func() (*CallToolResult, error) {
+				return NewErrorResult(NewError(InternalError, "upstream unavailable", nil)), nil
+			}},
+			policy:    RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond},
+			wantCalls: 2,
+		},
+		{
+			name:      "error results are retried when classified so",
+			tool:      &flakyTool{failures: 1, fail: failWithResult},
+			policy:    RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond, Retryable: RetryOnErrorResults},
+			wantCalls: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.tool.name = "flaky"
+			h := NewBaseHandler(ServerInfo{Name: "test", Version: "1.0.0"}, ServerCapabilities{})
+			h.Use(RetryTools(map[string]RetryPolicy{"flaky": tt.policy}))
+			h.RegisterTool(tt.tool)
+			h.HandleMessage(context.Background(), NewNotification("initialized", nil))
+
+			result, err := h.CallTool(context.Background(), &CallToolParams{Name: "flaky"})
+			if tt.tool.calls != tt.wantCalls {
+				t.Errorf("Expected %d calls, got %d", tt.wantCalls, tt.tool.calls)
+			}
+			failed := err != nil || result.IsError
+			if failed != tt.wantError {
+				t.Errorf("Expected failure %v, got %+v, %v", tt.wantError, result, err)
+			}
+		})
+	}
+}
+
+func TestRetryTools_StopsWhenCancelled(t *testing.T) {
+	tool := &flakyTool{name: "flaky", failures: 5, fail: failWithError}
+	wrapped := RetryTools(map[string]RetryPolicy{
+		"flaky": {MaxAttempts: 5, Backoff: time.Hour},
+	})(tool)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := wrapped.Execute(ctx, nil); err == nil {
+		t.Fatal("Expected the last error")
+	}
+	if tool.calls != 1 {
+		t.Errorf("Expected no retry after cancellation, got %d calls", tool.calls)
+	}
+}
@@ -0,0 +1,83 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrSamplingUnsupported is returned by CreateMessage when the client did
+// not advertise the sampling capability
+var ErrSamplingUnsupported = errors.New("client does not support sampling")
+
+// SamplingMessage is a message in a sampling/createMessage conversation
+type SamplingMessage struct {
+	Role    string  `json:"role"`
+	Content Content `json:"content"`
+}
+
+// ModelHint suggests a model by name, e.g. "claude" or "gpt-4o"
+type ModelHint struct {
+	Name string `json:"name,omitempty"`
+}
+
+// ModelPreferences guide the client's choice of model; priorities range
+// from 0 to 1
+type ModelPreferences struct {
+	Hints                []ModelHint `json:"hints,omitempty"`
+	CostPriority         float64     `json:"costPriority,omitempty"`
+	SpeedPriority        float64     `json:"speedPriority,omitempty"`
+	IntelligencePriority float64     `json:"intelligencePriority,omitempty"`
+}
+
+// CreateMessageParams represents sampling/createMessage parameters
+type CreateMessageParams struct {
+	Messages         []SamplingMessage      `json:"messages"`
+	ModelPreferences *ModelPreferences      `json:"modelPreferences,omitempty"`
+	SystemPrompt     string                 `json:"systemPrompt,omitempty"`
+	IncludeContext   string                 `json:"includeContext,omitempty"`
+	Temperature      *float64               `json:"temperature,omitempty"`
+	MaxTokens        int                    `json:"maxTokens"`
+	StopSequences    []string               `json:"stopSequences,omitempty"`
+	Metadata         map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// CreateMessageResult represents the client's answer to sampling/createMessage
+type CreateMessageResult struct {
+	Role       string  `json:"role"`
+	Content    Content `json:"content"`
+	Model      string  `json:"model"`
+	StopReason string  `json:"stopReason,omitempty"`
+}
+
+// CreateMessage asks the client making the current request to sample its
+// LLM, letting tools use a model without holding an API key. It waits for
+// the client's answer until ctx is done; clients usually ask the user to
+// approve each request.
+func CreateMessage(ctx context.Context, params *CreateMessageParams) (*CreateMessageResult, error) {
+	session, ok := SessionFromContext(ctx)
+	if !ok {
+		return nil, fmt.Errorf("sampling requires a session")
+	}
+	if session.ClientCapabilities().Sampling == nil {
+		return nil, ErrSamplingUnsupported
+	}
+
+	response, err := session.Request(ctx, "sampling/createMessage", params)
+	if err != nil {
+		return nil, err
+	}
+
+	var result CreateMessageResult
+	if err := response.UnmarshalResult(&result); err != nil {
+		return nil, fmt.Errorf("invalid sampling result: %w", err)
+	}
+	return &result, nil
+}
+
+// ClientSupportsSampling reports whether the client making the current
+// request advertised the sampling capability
+func ClientSupportsSampling(ctx context.Context) bool {
+	session, ok := SessionFromContext(ctx)
+	return ok && session.ClientCapabilities().Sampling != nil
+}
@@ -0,0 +1,69 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestCreateMessage_RoundTripsThroughClient(t *testing.T) {
+	session := NewSession("sampling")
+	session.setClient(&InitializeParams{
+		Capabilities: ClientCapabilities{Sampling: map[string]interface{}{}},
+	}, MCPVersion)
+
+	var sent *Message
+	session.SetNotifier(func(request *Message) {
+		sent = request
+		session.HandleResponse(NewSuccessResponse(request.ID, CreateMessageResult{
+			Role:    "assistant",
+			Content: Content{Type: "text", Text: "A short summary."},
+			Model:   "test-model",
+		}))
+	})
+	ctx := WithSession(context.Background(), session)
+
+	result, err := CreateMessage(ctx, &CreateMessageParams{
+		Messages:  []SamplingMessage{{Role: "user", Content: Content{Type: "text", Text: "Summarize"}}},
+		MaxTokens: 100,
+	})
+	if err != nil {
+		t.Fatalf("CreateMessage failed: %v", err)
+	}
+	if sent == nil || sent.Method != "sampling/createMessage" || sent.ID == nil {
+		t.Fatalf("Expected a sampling/createMessage request, got %+v", sent)
+	}
+	if result.Content.Text != "A short summary." || result.Model != "test-model" {
+		t.Errorf("Unexpected result %+v", result)
+	}
+
+	// A response nobody is waiting for is not claimed
+	if session.HandleResponse(NewSuccessResponse(sent.ID, nil)) {
+		t.Errorf("Expected a late response to be ignored")
+	}
+}
+
+func TestCreateMessage_ErrorsAndUnsupported(t *testing.T) {
+	session := NewSession("no-sampling")
+	session.SetNotifier(func(*Message) {
+		t.Errorf("Expected no request without the sampling capability")
+	})
+	ctx := WithSession(context.Background(), session)
+
+	if _, err := CreateMessage(ctx, &CreateMessageParams{MaxTokens: 10}); !errors.Is(err, ErrSamplingUnsupported) {
+		t.Fatalf("Expected ErrSamplingUnsupported, got %v", err)
+	}
+
+	session.setClient(&InitializeParams{
+		Capabilities: ClientCapabilities{Sampling: map[string]interface{}{}},
+	}, MCPVersion)
+	session.SetNotifier(func(request *Message) {
+		session.HandleResponse(NewErrorResponse(request.ID, -1, "User rejected sampling request", nil))
+	})
+
+	_, err := CreateMessage(ctx, &CreateMessageParams{MaxTokens: 10})
+	var info *ErrorInfo
+	if !errors.As(err, &info) || info.Message != "User rejected sampling request" {
+		t.Errorf("Expected the client's error, got %v", err)
+	}
+}
@@ -0,0 +1,299 @@
+package mcp
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+// Session holds the state of one client connection: its initialization
+// progress, what it reported about itself, and arbitrary values tools can
+// keep between calls. Transports create a session per connection and attach
+// it to the context passed to HandleMessage with WithSession.
+type Session struct {
+	id string
+
+	mu           sync.RWMutex
+	initialized  bool
+	clientInfo   ClientInfo
+	capabilities ClientCapabilities
+	// protocolVersion is the version negotiated in initialize
+	protocolVersion string
+	claims          Claims
+	principal       string
+	values          map[string]interface{}
+	// subscriptions are the resource URIs the client subscribed to
+	subscriptions map[string]struct{}
+	// logLevel is the minimum level of log messages the client asked for
+	logLevel LoggingLevel
+	// notifier sends notifications and requests to this client alone
+	notifier Notifier
+	// inflight cancels the client's running requests, keyed by request ID
+	inflight map[string]*context.CancelFunc
+	// pending holds the server's requests awaiting the client's response
+	pending     map[string]chan *Message
+	nextRequest uint64
+}
+
+// Claims are the verified token claims of an authenticated client, such as
+// "sub" and "scope"
+type Claims map[string]interface{}
+
+// sessionKey is the context key for the current session
+type sessionKey struct{}
+
+// NewSession creates an uninitialized session
+func NewSession(id string) *Session {
+	return &Session{
+		id:            id,
+		values:        make(map[string]interface{}),
+		subscriptions: make(map[string]struct{}),
+		inflight:      make(map[string]*context.CancelFunc),
+		pending:       make(map[string]chan *Message),
+	}
+}
+
+// WithSession returns a context carrying session
+func WithSession(ctx context.Context, session *Session) context.Context {
+	return context.WithValue(ctx, sessionKey{}, session)
+}
+
+// SessionFromContext returns the session handling the current request, if any
+func SessionFromContext(ctx context.Context) (*Session, bool) {
+	session, ok := ctx.Value(sessionKey{}).(*Session)
+	return session, ok && session != nil
+}
+
+// ID returns the session identifier
+func (s *Session) ID() string {
+	return s.id
+}
+
+// IsInitialized returns whether the client has completed initialization
+func (s *Session) IsInitialized() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.initialized
+}
+
+// ClientInfo returns what the client reported in its initialize request
+func (s *Session) ClientInfo() ClientInfo {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.clientInfo
+}
+
+// ClientCapabilities returns the capabilities the client advertised
+func (s *Session) ClientCapabilities() ClientCapabilities {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.capabilities
+}
+
+// Claims returns the client's verified token claims, or nil if the client
+// did not authenticate with a token
+func (s *Session) Claims() Claims {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.claims
+}
+
+// SetClaims records the client's verified token claims
+func (s *Session) SetClaims(claims Claims) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.claims = claims
+}
+
+// Principal identifies the credentials the client authenticated with, or
+// is "" for an unauthenticated client
+func (s *Session) Principal() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.principal
+}
+
+// SetPrincipal records the identity of the client's credentials
+func (s *Session) SetPrincipal(principal string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.principal = principal
+}
+
+// ClaimsFromContext returns the token claims of the client making the
+// current request, letting tools make per-user decisions
+func ClaimsFromContext(ctx context.Context) (Claims, bool) {
+	session, ok := SessionFromContext(ctx)
+	if !ok {
+		return nil, false
+	}
+	claims := session.Claims()
+	return claims, claims != nil
+}
+
+// Get returns a value stored in the session
+func (s *Session) Get(key string) (interface{}, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	value, ok := s.values[key]
+	return value, ok
+}
+
+// Set stores a value in the session
+func (s *Session) Set(key string, value interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values[key] = value
+}
+
+// Delete removes a value from the session
+func (s *Session) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.values, key)
+}
+
+// IsSubscribed reports whether the client subscribed to updates of uri
+func (s *Session) IsSubscribed(uri string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, exists := s.subscriptions[uri]
+	return exists
+}
+
+// Subscriptions returns the resource URIs the client subscribed to
+func (s *Session) Subscriptions() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	uris := make([]string, 0, len(s.subscriptions))
+	for uri := range s.subscriptions {
+		uris = append(uris, uri)
+	}
+	sort.Strings(uris)
+	return uris
+}
+
+// subscribe records a subscription to uri
+func (s *Session) subscribe(uri string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.subscriptions[uri] = struct{}{}
+}
+
+// unsubscribe removes a subscription to uri
+func (s *Session) unsubscribe(uri string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.subscriptions, uri)
+}
+
+// LogLevel returns the minimum level of log messages the client asked for
+// with logging/setLevel, or "" if it did not ask for any
+func (s *Session) LogLevel() LoggingLevel {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.logLevel
+}
+
+// setLogLevel records the client's requested log level
+func (s *Session) setLogLevel(level LoggingLevel) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.logLevel = level
+}
+
+// SetNotifier sets how notifications and requests meant only for this
+// client, such as progress updates, are delivered. Transports call it when
+// they create the session.
+func (s *Session) SetNotifier(notifier Notifier) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.notifier = notifier
+}
+
+// Notify sends a notification to this client, reporting false if the
+// transport did not set a notifier
+func (s *Session) Notify(notification *Message) bool {
+	s.mu.RLock()
+	notifier := s.notifier
+	s.mu.RUnlock()
+
+	if notifier == nil {
+		return false
+	}
+	notifier(notification)
+	return true
+}
+
+// ProtocolVersion returns the protocol version negotiated in initialize,
+// or "" before initialize
+func (s *Session) ProtocolVersion() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.protocolVersion
+}
+
+// setClient records the client's initialize parameters and the protocol
+// version negotiated with it
+func (s *Session) setClient(params *InitializeParams, version string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.clientInfo = params.ClientInfo
+	s.capabilities = params.Capabilities
+	s.protocolVersion = version
+}
+
+// markInitialized records that the client sent the initialized notification
+func (s *Session) markInitialized() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.initialized = true
+}
+
+// SessionState is the part of a session another server can restore, such
+// as a replica behind a load balancer. Values stored with Set, running
+// requests and the notifier stay with the server that holds them.
+type SessionState struct {
+	Initialized     bool               `json:"initialized"`
+	ClientInfo      ClientInfo         `json:"clientInfo"`
+	Capabilities    ClientCapabilities `json:"capabilities"`
+	ProtocolVersion string             `json:"protocolVersion,omitempty"`
+	Claims          Claims             `json:"claims,omitempty"`
+	Principal       string             `json:"principal,omitempty"`
+	Subscriptions   []string           `json:"subscriptions,omitempty"`
+	LogLevel        LoggingLevel       `json:"logLevel,omitempty"`
+}
+
+// State returns what RestoreSession needs to continue the session
+func (s *Session) State() SessionState {
+	subscriptions := s.Subscriptions()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return SessionState{
+		Initialized:     s.initialized,
+		ClientInfo:      s.clientInfo,
+		Capabilities:    s.capabilities,
+		ProtocolVersion: s.protocolVersion,
+		Claims:          s.claims,
+		Principal:       s.principal,
+		Subscriptions:   subscriptions,
+		LogLevel:        s.logLevel,
+	}
+}
+
+// RestoreSession recreates a session from the state another server saved
+func RestoreSession(id string, state SessionState) *Session {
+	s := NewSession(id)
+	s.initialized = state.Initialized
+	s.clientInfo = state.ClientInfo
+	s.capabilities = state.Capabilities
+	s.protocolVersion = state.ProtocolVersion
+	s.claims = state.Claims
+	s.principal = state.Principal
+	s.logLevel = state.LogLevel
+	for _, uri := range state.Subscriptions {
+		s.subscriptions[uri] = struct{}{}
+	}
+	return s
+}
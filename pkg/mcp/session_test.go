@@ -0,0 +1,75 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+// counterTool counts its calls in the caller's session
+type counterTool struct{}
+
+func (counterTool) Definition() *Tool {
+	return &Tool{Name: "counter", InputSchema: ToolSchema{Type: "object"}}
+}
+
+func (counterTool) Execute(ctx context.Context, params map[string]interface{}) (*CallToolResult, error) {
+	session, ok := SessionFromContext(ctx)
+	if !ok {
+		return nil, fmt.Errorf("no session")
+	}
+	count, _ := session.Get("count")
+	n, _ := count.(int)
+	session.Set("count", n+1)
+	return &CallToolResult{Content: []Content{{Type: "text", Text: fmt.Sprint(n + 1)}}}, nil
+}
+
+func TestBaseHandler_SessionIsolation(t *testing.T) {
+	h := NewBaseHandler(ServerInfo{Name: "test", Version: "1.0.0"}, ServerCapabilities{})
+	if err := h.RegisterTool(counterTool{}); err != nil {
+		t.Fatalf("Failed to register tool: %v", err)
+	}
+
+	first := NewSession("first")
+	second := NewSession("second")
+	firstCtx := WithSession(context.Background(), first)
+	secondCtx := WithSession(context.Background(), second)
+
+	initialize := NewRequest(1, "initialize", InitializeParams{
+		ProtocolVersion: MCPVersion,
+		ClientInfo:      ClientInfo{Name: "first-client", Version: "1.0.0"},
+	})
+	if response, _ := h.HandleMessage(firstCtx, initialize); response.Error != nil {
+		t.Fatalf("Initialize failed: %v", response.Error)
+	}
+	h.HandleMessage(firstCtx, NewNotification("initialized", nil))
+
+	if !first.IsInitialized() || second.IsInitialized() {
+		t.Fatalf("Expected only the first session to be initialized")
+	}
+	if h.IsInitialized() {
+		t.Errorf("Session initialization should not mark the handler initialized")
+	}
+	if got := first.ClientInfo().Name; got != "first-client" {
+		t.Errorf("Expected client name first-client, got %q", got)
+	}
+
+	call := NewRequest(2, "tools/call", CallToolParams{Name: "counter"})
+	response, _ := h.HandleMessage(secondCtx, call)
+	if response.Error == nil || response.Error.Code != InvalidRequest {
+		t.Errorf("Expected uninitialized session to be rejected, got %+v", response.Error)
+	}
+
+	for i := 1; i <= 2; i++ {
+		response, _ := h.HandleMessage(firstCtx, call)
+		if response.Error != nil {
+			t.Fatalf("Tool call failed: %v", response.Error)
+		}
+	}
+	if count, _ := first.Get("count"); count != 2 {
+		t.Errorf("Expected session count 2, got %v", count)
+	}
+	if _, ok := second.Get("count"); ok {
+		t.Errorf("Expected second session to have no stored count")
+	}
+}
@@ -0,0 +1,64 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// SharedStore is a key-value store seen by every replica of a server, such
+// as the state backend, letting replicas share cached results and rate
+// limits. Get fails for missing keys; any error is treated like one.
+type SharedStore interface {
+	Get(ctx context.Context, key string) ([]byte, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Incr adds one to the counter at key, starting a counter that expires
+	// after window when there is none, and returns the new count
+	Incr(ctx context.Context, key string, window time.Duration) (int64, error)
+}
+
+// sharedTimeout bounds each call to a SharedStore, after which callers fall
+// back to their local state
+const sharedTimeout = 2 * time.Second
+
+// Key prefixes of the state kept in a SharedStore
+const (
+	sharedCachePrefix     = "cache:"
+	sharedRateLimitPrefix = "ratelimit:"
+)
+
+// SharedWindow counts calls in fixed windows of a SharedStore, the shared
+// equivalent of a token bucket: up to burst calls are allowed in each
+// window of burst/rps, so the average rate matches the bucket's
+type SharedWindow struct {
+	Store SharedStore
+	// Key names the counter; the window's start is appended to it
+	Key   string
+	Rate  float64
+	Burst int
+}
+
+// Reserve counts a call at now and returns zero, or, when the window's
+// calls are used up, how long until the next window starts. Rejected
+// calls are counted too, so a client retrying early is not let through.
+func (w SharedWindow) Reserve(ctx context.Context, now time.Time) (time.Duration, error) {
+	if w.Rate <= 0 || w.Burst <= 0 {
+		return 0, nil
+	}
+	window := time.Duration(float64(w.Burst) / w.Rate * float64(time.Second))
+	if window <= 0 {
+		window = time.Millisecond
+	}
+	start := now.Truncate(window)
+
+	ctx, cancel := context.WithTimeout(ctx, sharedTimeout)
+	defer cancel()
+	count, err := w.Store.Incr(ctx, fmt.Sprintf("%s%s:%d", sharedRateLimitPrefix, w.Key, start.UnixMilli()), window)
+	if err != nil {
+		return 0, err
+	}
+	if count <= int64(w.Burst) {
+		return 0, nil
+	}
+	return start.Add(window).Sub(now), nil
+}
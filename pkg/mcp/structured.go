@@ -0,0 +1,51 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// NewStructuredResult returns a successful tool result with a readable
+// summary and a machine-readable payload as structured content. The payload
+// should match the tool's OutputSchema when it declares one.
+func NewStructuredResult(text string, structured interface{}) *CallToolResult {
+	return &CallToolResult{
+		Content: []Content{{
+			Type: "text",
+			Text: text,
+		}},
+		StructuredContent: structured,
+	}
+}
+
+// NewErrorResult returns a failed tool result describing info as text and,
+// for clients that react to specific failures such as rate limits, as an
+// "error" object in the structured content
+func NewErrorResult(info *ErrorInfo) *CallToolResult {
+	result := NewStructuredResult(info.Message, map[string]interface{}{"error": info})
+	result.IsError = true
+	return result
+}
+
+// structuredForClient returns result as the client in ctx understands it:
+// clients that negotiated a revision without structured output get the
+// payload as a JSON text block instead
+func structuredForClient(ctx context.Context, result *CallToolResult) *CallToolResult {
+	if result == nil || result.StructuredContent == nil || SupportsProtocol(ctx, ProtocolVersionStructuredOutput) {
+		return result
+	}
+
+	data, err := json.MarshalIndent(result.StructuredContent, "", "  ")
+	if err != nil {
+		return result
+	}
+
+	downgraded := *result
+	downgraded.StructuredContent = nil
+	downgraded.Content = append(append([]Content(nil), result.Content...), Content{
+		Type:     "text",
+		Text:     string(data),
+		MimeType: "application/json",
+	})
+	return &downgraded
+}
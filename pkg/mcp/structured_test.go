@@ -0,0 +1,50 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+)
+
+// structuredTool returns its payload as structured content
+type structuredTool struct{}
+
+func (structuredTool) Definition() *Tool {
+	return &Tool{
+		Name:         "structured",
+		InputSchema:  ToolSchema{Type: "object"},
+		OutputSchema: &ToolSchema{Type: "object", Properties: map[string]interface{}{"answer": map[string]interface{}{"type": "integer"}}},
+	}
+}
+
+func (structuredTool) Execute(ctx context.Context, params map[string]interface{}) (*CallToolResult, error) {
+	return NewStructuredResult("The answer is 42", map[string]interface{}{"answer": 42}), nil
+}
+
+func TestBaseHandler_StructuredContentFollowsProtocolVersion(t *testing.T) {
+	h := NewBaseHandler(ServerInfo{Name: "test", Version: "1.0.0"}, ServerCapabilities{})
+	if err := h.RegisterTool(structuredTool{}); err != nil {
+		t.Fatalf("Failed to register tool: %v", err)
+	}
+
+	call := func(version string) *CallToolResult {
+		ctx := WithSession(context.Background(), NewSession(version))
+		h.HandleMessage(ctx, NewRequest(1, "initialize", InitializeParams{ProtocolVersion: version}))
+		h.HandleMessage(ctx, NewNotification("initialized", nil))
+		response, _ := h.HandleMessage(ctx, NewRequest(2, "tools/call", CallToolParams{Name: "structured"}))
+		return response.Result.(*CallToolResult)
+	}
+
+	current := call(ProtocolVersionStructuredOutput)
+	if current.StructuredContent == nil || len(current.Content) != 1 {
+		t.Errorf("Expected structured content and a text summary, got %+v", current)
+	}
+
+	// Older clients get the payload as a JSON text block instead
+	legacy := call("2025-03-26")
+	if legacy.StructuredContent != nil {
+		t.Errorf("Expected no structured content for an older client")
+	}
+	if len(legacy.Content) != 2 || legacy.Content[1].MimeType != "application/json" || legacy.Content[1].Text == "" {
+		t.Errorf("Expected a JSON text block, got %+v", legacy.Content)
+	}
+}
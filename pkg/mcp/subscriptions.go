@@ -0,0 +1,58 @@
+package mcp
+
+import "context"
+
+// ResourceUpdater is implemented by resource handlers whose content changes.
+// RegisterResource passes the handler a function to call with the URI of
+// each changed resource, which notifies the clients subscribed to it.
+type ResourceUpdater interface {
+	OnUpdate(update func(uri string))
+}
+
+// NotifyResourceUpdated sends notifications/resources/updated for uri. The
+// notifier delivers it only to clients subscribed to the resource.
+func (h *BaseHandler) NotifyResourceUpdated(uri string) {
+	if h.capabilities.Resources == nil || !h.capabilities.Resources.Subscribe {
+		return
+	}
+
+	h.toolsMu.RLock()
+	notifier := h.notifier
+	h.toolsMu.RUnlock()
+
+	if notifier != nil {
+		notifier(NewNotification("notifications/resources/updated", map[string]interface{}{
+			"uri": uri,
+		}))
+	}
+}
+
+// setSubscription subscribes or unsubscribes the session in ctx from
+// updates to a resource
+func (h *BaseHandler) setSubscription(ctx context.Context, params *SubscribeParams, subscribe bool) error {
+	if !h.isInitialized(ctx) {
+		return NotInitializedError()
+	}
+	if h.capabilities.Resources == nil || !h.capabilities.Resources.Subscribe {
+		return NewError(MethodNotFound, "resource subscriptions are not supported", nil)
+	}
+	if params.URI == "" {
+		return InvalidParamsError("uri", "is required")
+	}
+
+	session, ok := SessionFromContext(ctx)
+	if !ok {
+		return NewError(InvalidRequest, "resource subscriptions require a session", nil)
+	}
+
+	if !subscribe {
+		session.unsubscribe(params.URI)
+		return nil
+	}
+
+	if _, exists := h.lookupResource(params.URI); !exists || !h.accessRule(ctx).allowsResource(params.URI) {
+		return ResourceNotFoundError(params.URI)
+	}
+	session.subscribe(params.URI)
+	return nil
+}
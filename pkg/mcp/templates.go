@@ -0,0 +1,224 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// ResourceTemplate describes a family of resources whose URIs follow an
+// RFC 6570 URI template, such as "doc://analysis/{id}"
+type ResourceTemplate struct {
+	URITemplate string `json:"uriTemplate"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mimeType,omitempty"`
+}
+
+// ResourceTemplateHandler defines the interface for parameterized resources.
+// Read receives the requested URI and the values of the template's
+// variables.
+type ResourceTemplateHandler interface {
+	Definition() *ResourceTemplate
+	Read(ctx context.Context, uri string, vars map[string]string) (*ReadResourceResult, error)
+}
+
+// ResourceTemplateLister is implemented by template handlers whose
+// resources depend on the client, such as its own notes. resources/list
+// includes the resources they list for the client in ctx.
+type ResourceTemplateLister interface {
+	ListResources(ctx context.Context) []*Resource
+}
+
+// templateVarRegex matches the expressions of a URI template
+var templateVarRegex = regexp.MustCompile(`\{([^{}]*)\}`)
+
+// templateVarNameRegex matches valid template variable names
+var templateVarNameRegex = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+// uriTemplate is a parsed URI template that matches concrete URIs
+type uriTemplate struct {
+	pattern *regexp.Regexp
+	vars    []string
+	// reserved marks {+var} expressions, whose values may contain "/"
+	reserved []bool
+}
+
+// parseURITemplate compiles a URI template with simple {var} expressions,
+// which match a single path segment, and reserved {+var} expressions,
+// which may span several
+func parseURITemplate(template string) (*uriTemplate, error) {
+	parsed := &uriTemplate{}
+	var pattern strings.Builder
+	pattern.WriteString("^")
+
+	last := 0
+	for _, loc := range templateVarRegex.FindAllStringSubmatchIndex(template, -1) {
+		pattern.WriteString(regexp.QuoteMeta(template[last:loc[0]]))
+		last = loc[1]
+
+		name := template[loc[2]:loc[3]]
+		reserved := strings.HasPrefix(name, "+")
+		name = strings.TrimPrefix(name, "+")
+		if !templateVarNameRegex.MatchString(name) {
+			return nil, fmt.Errorf("unsupported expression {%s} in URI template %q", template[loc[2]:loc[3]], template)
+		}
+
+		if reserved {
+			pattern.WriteString("(.+)")
+		} else {
+			pattern.WriteString("([^/?#]+)")
+		}
+		parsed.vars = append(parsed.vars, name)
+		parsed.reserved = append(parsed.reserved, reserved)
+	}
+	if len(parsed.vars) == 0 {
+		return nil, fmt.Errorf("URI template %q has no variables", template)
+	}
+	pattern.WriteString(regexp.QuoteMeta(template[last:]))
+	pattern.WriteString("$")
+
+	compiled, err := regexp.Compile(pattern.String())
+	if err != nil {
+		return nil, fmt.Errorf("invalid URI template %q: %w", template, err)
+	}
+	parsed.pattern = compiled
+	return parsed, nil
+}
+
+// match returns the variables of uri, unescaping simple expressions, or
+// false if uri does not follow the template
+func (t *uriTemplate) match(uri string) (map[string]string, bool) {
+	matches := t.pattern.FindStringSubmatch(uri)
+	if matches == nil {
+		return nil, false
+	}
+
+	vars := make(map[string]string, len(t.vars))
+	for i, name := range t.vars {
+		value := matches[i+1]
+		if !t.reserved[i] {
+			unescaped, err := url.PathUnescape(value)
+			if err != nil {
+				return nil, false
+			}
+			value = unescaped
+		}
+		vars[name] = value
+	}
+	return vars, true
+}
+
+// registeredTemplate is a template handler with its parsed URI template
+type registeredTemplate struct {
+	handler  ResourceTemplateHandler
+	template *uriTemplate
+}
+
+// RegisterResourceTemplate registers a handler for every URI matching its
+// template. When several templates match a URI, the first registered wins;
+// resources registered with RegisterResource take precedence over all.
+func (h *BaseHandler) RegisterResourceTemplate(handler ResourceTemplateHandler) error {
+	definition := handler.Definition()
+	if definition == nil {
+		return fmt.Errorf("resource template definition cannot be nil")
+	}
+	parsed, err := parseURITemplate(definition.URITemplate)
+	if err != nil {
+		return err
+	}
+
+	registered := &registeredTemplate{handler: handler, template: parsed}
+	h.toolsMu.Lock()
+	defer h.toolsMu.Unlock()
+	for i, existing := range h.templates {
+		if existing.handler.Definition().URITemplate == definition.URITemplate {
+			// Copy rather than overwrite, since readers may hold the slice
+			templates := append([]*registeredTemplate(nil), h.templates...)
+			templates[i] = registered
+			h.templates = templates
+			return nil
+		}
+	}
+	h.templates = append(h.templates, registered)
+	return nil
+}
+
+// ListResourceTemplates returns all registered resource templates
+func (h *BaseHandler) ListResourceTemplates() ([]*ResourceTemplate, error) {
+	registeredTemplates := h.registeredTemplates()
+	templates := make([]*ResourceTemplate, 0, len(registeredTemplates))
+	for _, registered := range registeredTemplates {
+		templates = append(templates, registered.handler.Definition())
+	}
+	return templates, nil
+}
+
+// registeredTemplates returns the registered templates in registration
+// order
+func (h *BaseHandler) registeredTemplates() []*registeredTemplate {
+	h.toolsMu.RLock()
+	defer h.toolsMu.RUnlock()
+	return h.templates
+}
+
+// templateResources returns the resources the template handlers that
+// implement ResourceTemplateLister list for the client in ctx
+func (h *BaseHandler) templateResources(ctx context.Context) []*Resource {
+	var resources []*Resource
+	for _, registered := range h.registeredTemplates() {
+		if lister, ok := registered.handler.(ResourceTemplateLister); ok {
+			resources = append(resources, lister.ListResources(ctx)...)
+		}
+	}
+	return resources
+}
+
+// lookupResource returns the handler for uri: a registered resource, or a
+// template whose URI template uri matches
+func (h *BaseHandler) lookupResource(uri string) (ResourceHandler, bool) {
+	if handler, err := h.resources.Get(uri); err == nil {
+		return handler, true
+	}
+	for _, registered := range h.registeredTemplates() {
+		if vars, ok := registered.template.match(uri); ok {
+			return &templatedResource{handler: registered.handler, uri: uri, vars: vars}, true
+		}
+	}
+	return nil, false
+}
+
+// templateHandler returns the template handler registered for template
+func (h *BaseHandler) templateHandler(template string) (ResourceTemplateHandler, bool) {
+	for _, registered := range h.registeredTemplates() {
+		if registered.handler.Definition().URITemplate == template {
+			return registered.handler, true
+		}
+	}
+	return nil, false
+}
+
+// templatedResource is a concrete resource served by a template handler
+type templatedResource struct {
+	handler ResourceTemplateHandler
+	uri     string
+	vars    map[string]string
+}
+
+// Definition describes the concrete resource using its template's details
+func (r *templatedResource) Definition() *Resource {
+	template := r.handler.Definition()
+	return &Resource{
+		URI:         r.uri,
+		Name:        template.Name,
+		Description: template.Description,
+		MimeType:    template.MimeType,
+	}
+}
+
+// Read reads the resource through its template handler
+func (r *templatedResource) Read(ctx context.Context, uri string) (*ReadResourceResult, error) {
+	return r.handler.Read(ctx, uri, r.vars)
+}
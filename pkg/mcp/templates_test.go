@@ -0,0 +1,88 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+)
+
+// analysisTemplate serves doc://analysis/{id} resources
+type analysisTemplate struct{}
+
+func (analysisTemplate) Definition() *ResourceTemplate {
+	return &ResourceTemplate{URITemplate: "doc://analysis/{id}", Name: "Analysis", MimeType: "application/json"}
+}
+
+func (analysisTemplate) Read(ctx context.Context, uri string, vars map[string]string) (*ReadResourceResult, error) {
+	return &ReadResourceResult{Contents: []ResourceContents{{URI: uri, Text: "analysis " + vars["id"]}}}, nil
+}
+
+func TestParseURITemplate(t *testing.T) {
+	tests := []struct {
+		template string
+		uri      string
+		want     map[string]string
+	}{
+		{"doc://analysis/{id}", "doc://analysis/42", map[string]string{"id": "42"}},
+		{"doc://analysis/{id}", "doc://analysis/a%20b", map[string]string{"id": "a b"}},
+		{"doc://analysis/{id}", "doc://analysis/4/2", nil},
+		{"file:///{+path}", "file:///home/user/notes.txt", map[string]string{"path": "home/user/notes.txt"}},
+		{"db://{table}/{id}", "db://users/7", map[string]string{"table": "users", "id": "7"}},
+		{"db://{table}/{id}", "other://users/7", nil},
+	}
+
+	for _, test := range tests {
+		parsed, err := parseURITemplate(test.template)
+		if err != nil {
+			t.Fatalf("parseURITemplate(%q) failed: %v", test.template, err)
+		}
+		vars, ok := parsed.match(test.uri)
+		if ok != (test.want != nil) {
+			t.Errorf("%q matching %q: expected match %v", test.template, test.uri, test.want != nil)
+			continue
+		}
+		for name, value := range test.want {
+			if vars[name] != value {
+				t.Errorf("%q matching %q: expected %s=%q, got %q", test.template, test.uri, name, value, vars[name])
+			}
+		}
+	}
+
+	for _, invalid := range []string{"doc://static", "doc://{?query}", "doc://{}"} {
+		if _, err := parseURITemplate(invalid); err == nil {
+			t.Errorf("Expected %q to be rejected", invalid)
+		}
+	}
+}
+
+func TestBaseHandler_ResourceTemplates(t *testing.T) {
+	h := NewBaseHandler(ServerInfo{Name: "test", Version: "1.0.0"}, ServerCapabilities{Resources: &ResourcesCapability{}})
+	if err := h.RegisterResourceTemplate(analysisTemplate{}); err != nil {
+		t.Fatalf("Failed to register template: %v", err)
+	}
+
+	ctx := WithSession(context.Background(), NewSession("templates"))
+	h.HandleMessage(ctx, NewRequest(1, "initialize", InitializeParams{ProtocolVersion: MCPVersion}))
+	h.HandleMessage(ctx, NewNotification("initialized", nil))
+
+	response, _ := h.HandleMessage(ctx, NewRequest(2, "resources/templates/list", nil))
+	if response.Error != nil {
+		t.Fatalf("resources/templates/list failed: %v", response.Error)
+	}
+	templates := response.Result.(map[string]interface{})["resourceTemplates"].([]*ResourceTemplate)
+	if len(templates) != 1 || templates[0].URITemplate != "doc://analysis/{id}" {
+		t.Errorf("Unexpected templates %+v", templates)
+	}
+
+	response, _ = h.HandleMessage(ctx, NewRequest(3, "resources/read", ReadResourceParams{URI: "doc://analysis/42"}))
+	if response.Error != nil {
+		t.Fatalf("resources/read failed: %v", response.Error)
+	}
+	if text := response.Result.(*ReadResourceResult).Contents[0].Text; text != "analysis 42" {
+		t.Errorf("Expected the template to read id 42, got %q", text)
+	}
+
+	response, _ = h.HandleMessage(ctx, NewRequest(4, "resources/read", ReadResourceParams{URI: "doc://other/42"}))
+	if response.Error == nil {
+		t.Errorf("Expected an error for a URI no template matches")
+	}
+}
@@ -0,0 +1,74 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName identifies spans created by this package
+const tracerName = "github.com/chongliujia/mcp-go-template/pkg/mcp"
+
+// tracer returns the tracer from the global provider, which is a no-op
+// until the application installs one
+func tracer() trace.Tracer {
+	return otel.Tracer(tracerName)
+}
+
+// startRequestSpan starts a span for an incoming request
+func startRequestSpan(ctx context.Context, message *Message) (context.Context, trace.Span) {
+	attrs := []attribute.KeyValue{
+		attribute.String("rpc.system", "jsonrpc"),
+		attribute.String("rpc.method", message.Method),
+	}
+	if message.ID != nil {
+		attrs = append(attrs, attribute.String("rpc.jsonrpc.request_id", fmt.Sprint(message.ID)))
+	}
+	if target := MessageTarget(message); target != "" {
+		attrs = append(attrs, attribute.String("mcp.target", target))
+	}
+	if session, ok := SessionFromContext(ctx); ok {
+		attrs = append(attrs, attribute.String("mcp.session_id", session.ID()))
+	}
+
+	return tracer().Start(ctx, message.Method,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(attrs...),
+	)
+}
+
+// endRequestSpan records the response's outcome and ends the span
+func endRequestSpan(span trace.Span, response *Message, err error) {
+	switch {
+	case err != nil:
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	case response != nil && response.Error != nil:
+		span.SetAttributes(attribute.Int("rpc.jsonrpc.error_code", response.Error.Code))
+		span.SetStatus(codes.Error, response.Error.Message)
+	}
+	span.End()
+}
+
+// startToolSpan starts a span around a tool's execution
+func startToolSpan(ctx context.Context, name string) (context.Context, trace.Span) {
+	return tracer().Start(ctx, "tool "+name,
+		trace.WithAttributes(attribute.String("mcp.tool.name", name)),
+	)
+}
+
+// endToolSpan records the tool's outcome and ends the span
+func endToolSpan(span trace.Span, result *CallToolResult, err error) {
+	switch {
+	case err != nil:
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	case result != nil && result.IsError:
+		span.SetStatus(codes.Error, "tool returned an error result")
+	}
+	span.End()
+}
@@ -0,0 +1,47 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestBaseHandler_TracesRequestsAndTools(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	defer otel.SetTracerProvider(previous)
+
+	h := NewBaseHandler(ServerInfo{Name: "test", Version: "1.0.0"}, ServerCapabilities{})
+	if err := h.RegisterTool(counterTool{}); err != nil {
+		t.Fatalf("Failed to register tool: %v", err)
+	}
+
+	ctx := WithSession(context.Background(), NewSession("traced"))
+	h.HandleMessage(ctx, NewRequest(1, "initialize", InitializeParams{ProtocolVersion: MCPVersion}))
+	h.HandleMessage(ctx, NewNotification("initialized", nil))
+	exporter.Reset()
+
+	h.HandleMessage(ctx, NewRequest(2, "tools/call", CallToolParams{Name: "counter"}))
+
+	spans := exporter.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("Expected a request span and a tool span, got %d spans", len(spans))
+	}
+
+	// The tool span ends first and is a child of the request span
+	tool, request := spans[0], spans[1]
+	if request.Name != "tools/call" {
+		t.Errorf("Expected request span tools/call, got %q", request.Name)
+	}
+	if tool.Name != "tool counter" {
+		t.Errorf("Expected tool span 'tool counter', got %q", tool.Name)
+	}
+	if tool.Parent.SpanID() != request.SpanContext.SpanID() {
+		t.Errorf("Expected tool span to be a child of the request span")
+	}
+}
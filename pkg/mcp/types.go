@@ -5,8 +5,8 @@ import (
 	"fmt"
 )
 
-// MCP Protocol Version
-const MCPVersion = "2024-11-05"
+// MCPVersion is the newest protocol version the server speaks
+const MCPVersion = "2025-06-18"
 
 // RequestID represents a unique identifier for MCP requests
 type RequestID interface{}
@@ -43,6 +43,9 @@ const (
 	ResourceNotFound  = -32002
 	ToolNotFound      = -32003
 	PromptNotFound    = -32004
+	LimitExceeded     = -32005
+	Unauthorized      = -32006
+	RateLimited       = -32007
 )
 
 // InitializeParams represents the parameters for the initialize request
@@ -65,6 +68,7 @@ type InitializeResult struct {
 type ClientCapabilities struct {
 	Experimental map[string]interface{} `json:"experimental,omitempty"`
 	Sampling     map[string]interface{} `json:"sampling,omitempty"`
+	Elicitation  map[string]interface{} `json:"elicitation,omitempty"`
 }
 
 // ServerCapabilities represents what the server can do
@@ -73,12 +77,16 @@ type ServerCapabilities struct {
 	Prompts      *PromptsCapability     `json:"prompts,omitempty"`
 	Resources    *ResourcesCapability   `json:"resources,omitempty"`
 	Tools        *ToolsCapability       `json:"tools,omitempty"`
+	Completions  *CompletionsCapability `json:"completions,omitempty"`
 	Experimental map[string]interface{} `json:"experimental,omitempty"`
 }
 
 // LoggingCapability represents logging capabilities
 type LoggingCapability struct{}
 
+// CompletionsCapability represents argument completion capabilities
+type CompletionsCapability struct{}
+
 // PromptsCapability represents prompt capabilities
 type PromptsCapability struct {
 	ListChanged bool `json:"listChanged,omitempty"`
@@ -109,9 +117,52 @@ type ServerInfo struct {
 
 // Tool represents an MCP tool definition
 type Tool struct {
-	Name        string      `json:"name"`
-	Description string      `json:"description,omitempty"`
-	InputSchema ToolSchema  `json:"inputSchema"`
+	Name         string      `json:"name"`
+	Description  string      `json:"description,omitempty"`
+	InputSchema  ToolSchema  `json:"inputSchema"`
+	OutputSchema *ToolSchema `json:"outputSchema,omitempty"`
+	Category     string      `json:"category,omitempty"`
+	Tags         []string    `json:"tags,omitempty"`
+	// Version is the tool's semantic version, when it has several
+	Version string `json:"version,omitempty"`
+	// Deprecated marks a tool that still works but that clients should
+	// stop using
+	Deprecated *ToolDeprecation `json:"deprecated,omitempty"`
+}
+
+// ToolDeprecation says why a tool is deprecated and what replaces it
+type ToolDeprecation struct {
+	Message string `json:"message,omitempty"`
+	// Replacement names the tool to use instead, if any
+	Replacement string `json:"replacement,omitempty"`
+}
+
+// String describes the deprecation for humans
+func (d *ToolDeprecation) String() string {
+	notice := "Deprecated"
+	if d.Message != "" {
+		notice += ": " + d.Message
+	}
+	if d.Replacement != "" {
+		notice += "; use " + d.Replacement + " instead"
+	}
+	return notice
+}
+
+// Tool categories used by the built-in tools
+const (
+	ToolCategoryResearch   = "research"
+	ToolCategoryMath       = "math"
+	ToolCategoryFilesystem = "filesystem"
+	ToolCategoryNetwork    = "network"
+	ToolCategoryDatabase   = "database"
+)
+
+// ListToolsParams represents the optional filters for tools/list
+type ListToolsParams struct {
+	Category string   `json:"category,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+	Cursor   string   `json:"cursor,omitempty"`
 }
 
 // ToolSchema represents the JSON schema for tool input
@@ -125,12 +176,31 @@ type ToolSchema struct {
 type CallToolParams struct {
 	Name      string                 `json:"name"`
 	Arguments map[string]interface{} `json:"arguments,omitempty"`
+	Meta      *RequestMeta           `json:"_meta,omitempty"`
+}
+
+// RequestMeta represents the _meta field clients may send with a request
+type RequestMeta struct {
+	// ProgressToken asks for notifications/progress about the request; it
+	// is a string or a number chosen by the client
+	ProgressToken interface{} `json:"progressToken,omitempty"`
+}
+
+// ProgressParams represents notifications/progress parameters
+type ProgressParams struct {
+	ProgressToken interface{} `json:"progressToken"`
+	Progress      float64     `json:"progress"`
+	Total         float64     `json:"total,omitempty"`
+	Message       string      `json:"message,omitempty"`
 }
 
 // CallToolResult represents the result of calling a tool
 type CallToolResult struct {
 	Content []Content `json:"content"`
-	IsError bool      `json:"isError,omitempty"`
+	// StructuredContent is a JSON object for clients to parse, described by
+	// the tool's OutputSchema
+	StructuredContent interface{} `json:"structuredContent,omitempty"`
+	IsError           bool        `json:"isError,omitempty"`
 }
 
 // Content represents different types of content in MCP
@@ -140,6 +210,8 @@ type Content struct {
 	Data     string      `json:"data,omitempty"`
 	MimeType string      `json:"mimeType,omitempty"`
 	Blob     interface{} `json:"blob,omitempty"`
+	// Resource holds the contents of an embedded resource
+	Resource *ResourceContents `json:"resource,omitempty"`
 }
 
 // Resource represents an MCP resource
@@ -153,6 +225,16 @@ type Resource struct {
 // ReadResourceParams represents parameters for reading a resource
 type ReadResourceParams struct {
 	URI string `json:"uri"`
+	// Accept is experimental: the MIME types the client wants, as in an
+	// HTTP Accept header. Contents of other types are converted with the
+	// registered resource conversions.
+	Accept string `json:"accept,omitempty"`
+}
+
+// SubscribeParams represents the parameters for resources/subscribe and
+// resources/unsubscribe
+type SubscribeParams struct {
+	URI string `json:"uri"`
 }
 
 // ReadResourceResult represents the result of reading a resource
@@ -180,6 +262,12 @@ type PromptArgument struct {
 	Name        string `json:"name"`
 	Description string `json:"description,omitempty"`
 	Required    bool   `json:"required,omitempty"`
+	// Default is used by GetPrompt when the argument is omitted; it is not
+	// sent to clients
+	Default string `json:"-"`
+	// Values are offered by completion/complete; they are not sent to
+	// clients
+	Values []string `json:"-"`
 }
 
 // GetPromptParams represents parameters for getting a prompt
@@ -214,6 +302,17 @@ const (
 	LoggingLevelEmergency LoggingLevel = "emergency"
 )
 
+// CancelledParams represents notifications/cancelled parameters
+type CancelledParams struct {
+	RequestID RequestID `json:"requestId"`
+	Reason    string    `json:"reason,omitempty"`
+}
+
+// SetLevelParams represents logging/setLevel parameters
+type SetLevelParams struct {
+	Level LoggingLevel `json:"level"`
+}
+
 // LoggingMessage represents a logging message
 type LoggingMessage struct {
 	Level  LoggingLevel `json:"level"`
@@ -316,4 +415,43 @@ func (m *Message) UnmarshalResult(v interface{}) error {
 	}
 	
 	return json.Unmarshal(data, v)
-}
\ No newline at end of file
+}
+
+// HasTag checks if the tool is tagged with tag
+func (t *Tool) HasTag(tag string) bool {
+	for _, existing := range t.Tags {
+		if existing == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// Matches checks if the tool is in category and carries every tag. An empty
+// category or tag list matches any tool.
+func (t *Tool) Matches(category string, tags []string) bool {
+	if category != "" && t.Category != category {
+		return false
+	}
+	for _, tag := range tags {
+		if !t.HasTag(tag) {
+			return false
+		}
+	}
+	return true
+}
+
+// FilterTools returns the tools matching category and tags
+func FilterTools(tools []*Tool, category string, tags []string) []*Tool {
+	if category == "" && len(tags) == 0 {
+		return tools
+	}
+
+	filtered := make([]*Tool, 0, len(tools))
+	for _, tool := range tools {
+		if tool.Matches(category, tags) {
+			filtered = append(filtered, tool)
+		}
+	}
+	return filtered
+}
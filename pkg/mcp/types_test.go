@@ -217,4 +217,33 @@ func TestJSONSerialization(t *testing.T) {
 	if unmarshaled.ID != msg.ID {
 		t.Errorf("ID mismatch after serialization")
 	}
-}
\ No newline at end of file
+}
+
+func TestFilterTools(t *testing.T) {
+	tools := []*Tool{
+		{Name: "calculator", Category: ToolCategoryMath},
+		{Name: "web_search", Category: ToolCategoryNetwork, Tags: []string{"research", "search"}},
+		{Name: "document_analyzer", Category: ToolCategoryResearch, Tags: []string{"nlp"}},
+	}
+
+	tests := []struct {
+		name     string
+		category string
+		tags     []string
+		expected int
+	}{
+		{"no filter", "", nil, 3},
+		{"by category", ToolCategoryMath, nil, 1},
+		{"by tag", "", []string{"research"}, 1},
+		{"by category and tag", ToolCategoryNetwork, []string{"search", "research"}, 1},
+		{"no match", ToolCategoryResearch, []string{"search"}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FilterTools(tools, tt.category, tt.tags); len(got) != tt.expected {
+				t.Errorf("FilterTools() returned %d tools, want %d", len(got), tt.expected)
+			}
+		})
+	}
+}
@@ -267,7 +267,7 @@ func ValidateToolParameters(params map[string]interface{}, schema ToolSchema) er
 	// Check required parameters
 	for _, required := range schema.Required {
 		if _, exists := params[required]; !exists {
-			return fmt.Errorf("required parameter '%s' is missing", required)
+			return InvalidParamsError(required, "required parameter is missing")
 		}
 	}
 	
@@ -275,7 +275,7 @@ func ValidateToolParameters(params map[string]interface{}, schema ToolSchema) er
 	for paramName, paramValue := range params {
 		propDef, exists := schema.Properties[paramName]
 		if !exists {
-			return fmt.Errorf("unknown parameter '%s'", paramName)
+			return InvalidParamsError(paramName, "unknown parameter")
 		}
 		
 		if err := validateParameterValue(paramName, paramValue, propDef); err != nil {
@@ -491,4 +491,35 @@ func sanitizeValue(value interface{}) interface{} {
 		// Return other types as-is
 		return value
 	}
-}
\ No newline at end of file
+}
+// ValidatePromptArguments checks arguments against a prompt's declared
+// arguments and returns a copy with the defaults of omitted ones filled in
+func ValidatePromptArguments(arguments map[string]interface{}, declared []PromptArgument) (map[string]interface{}, error) {
+	validated := make(map[string]interface{}, len(declared))
+	for name, value := range arguments {
+		validated[name] = value
+	}
+
+	known := make(map[string]bool, len(declared))
+	for _, arg := range declared {
+		known[arg.Name] = true
+		if value, exists := validated[arg.Name]; exists && value != nil {
+			continue
+		}
+		if arg.Required {
+			return nil, InvalidParamsError(arg.Name, "required argument is missing")
+		}
+		if arg.Default != "" {
+			validated[arg.Name] = arg.Default
+		} else {
+			delete(validated, arg.Name)
+		}
+	}
+
+	for name := range validated {
+		if !known[name] {
+			return nil, InvalidParamsError(name, "unknown argument")
+		}
+	}
+	return validated, nil
+}
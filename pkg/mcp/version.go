@@ -0,0 +1,57 @@
+package mcp
+
+import "context"
+
+// SupportedProtocolVersions lists the protocol revisions the server speaks,
+// newest first
+var SupportedProtocolVersions = []string{"2025-06-18", "2025-03-26", "2024-11-05"}
+
+// Protocol revisions that introduced features gated by the negotiated version
+const (
+	// ProtocolVersionStreamableHTTP introduced the Streamable HTTP transport
+	ProtocolVersionStreamableHTTP = "2025-03-26"
+	// ProtocolVersionStructuredOutput introduced structured tool output
+	ProtocolVersionStructuredOutput = "2025-06-18"
+)
+
+// NegotiateProtocolVersion picks the version to answer an initialize
+// request with: the requested one if supported, otherwise the newest
+// supported version older than it. A client asking for a revision newer
+// than any the server knows gets the server's newest and may disconnect if
+// it cannot speak it. ok is false when the request predates every
+// supported version.
+func NegotiateProtocolVersion(requested string) (version string, ok bool) {
+	// Revisions are dates, so they order as strings
+	for _, supported := range SupportedProtocolVersions {
+		if supported <= requested {
+			return supported, true
+		}
+	}
+	return "", false
+}
+
+// IsSupportedProtocolVersion reports whether the server speaks version
+func IsSupportedProtocolVersion(version string) bool {
+	for _, supported := range SupportedProtocolVersions {
+		if supported == version {
+			return true
+		}
+	}
+	return false
+}
+
+// ProtocolAtLeast reports whether version is minimum or a later revision
+func ProtocolAtLeast(version, minimum string) bool {
+	return version >= minimum
+}
+
+// SupportsProtocol reports whether the client making the current request
+// negotiated minimum or a later revision. Requests outside a session are
+// assumed to speak the newest version.
+func SupportsProtocol(ctx context.Context, minimum string) bool {
+	session, ok := SessionFromContext(ctx)
+	if !ok {
+		return true
+	}
+	return ProtocolAtLeast(session.ProtocolVersion(), minimum)
+}
@@ -0,0 +1,46 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+)
+
+func TestNegotiateProtocolVersion(t *testing.T) {
+	tests := []struct {
+		requested string
+		expected  string
+		ok        bool
+	}{
+		{"2024-11-05", "2024-11-05", true},
+		{"2025-03-26", "2025-03-26", true},
+		{"2025-06-18", "2025-06-18", true},
+		{"2099-01-01", "2025-06-18", true},
+		{"2025-01-01", "2024-11-05", true},
+		{"1999-01-01", "", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		version, ok := NegotiateProtocolVersion(tt.requested)
+		if version != tt.expected || ok != tt.ok {
+			t.Errorf("NegotiateProtocolVersion(%q) = %q, %v; expected %q, %v", tt.requested, version, ok, tt.expected, tt.ok)
+		}
+	}
+}
+
+func TestBaseHandler_RecordsNegotiatedVersion(t *testing.T) {
+	h := NewBaseHandler(ServerInfo{Name: "test", Version: "1.0.0"}, ServerCapabilities{})
+	session := NewSession("versioned")
+	ctx := WithSession(context.Background(), session)
+
+	response, _ := h.HandleMessage(ctx, NewRequest(1, "initialize", InitializeParams{ProtocolVersion: "2024-11-05"}))
+	if response.Error != nil {
+		t.Fatalf("initialize failed: %v", response.Error.Message)
+	}
+	if result := response.Result.(*InitializeResult); result.ProtocolVersion != "2024-11-05" {
+		t.Errorf("Expected the requested version to be accepted, got %q", result.ProtocolVersion)
+	}
+	if SupportsProtocol(ctx, ProtocolVersionStructuredOutput) {
+		t.Error("Expected a 2024-11-05 client not to support structured output")
+	}
+}
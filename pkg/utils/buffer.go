@@ -0,0 +1,47 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"sync"
+)
+
+// maxPooledBufferSize is the largest buffer capacity returned to the pool.
+// Larger buffers are dropped so a single huge response does not pin memory.
+const maxPooledBufferSize = 4 * 1024 * 1024
+
+var bufferPool = sync.Pool{
+	New: func() interface{} {
+		return new(bytes.Buffer)
+	},
+}
+
+// GetBuffer returns an empty buffer from the shared pool
+func GetBuffer() *bytes.Buffer {
+	buf := bufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	return buf
+}
+
+// PutBuffer returns a buffer to the shared pool
+func PutBuffer(buf *bytes.Buffer) {
+	if buf == nil || buf.Cap() > maxPooledBufferSize {
+		return
+	}
+	bufferPool.Put(buf)
+}
+
+// MarshalIndent encodes v as indented JSON using a pooled buffer
+func MarshalIndent(v interface{}) (string, error) {
+	buf := GetBuffer()
+	defer PutBuffer(buf)
+
+	enc := json.NewEncoder(buf)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return "", err
+	}
+
+	// Encoder terminates each value with a newline; MarshalIndent does not
+	return string(bytes.TrimSuffix(buf.Bytes(), []byte("\n"))), nil
+}
@@ -0,0 +1,45 @@
+package utils
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// correlationKey is the context key for the current request's correlation ID
+type correlationKey struct{}
+
+// NewCorrelationID returns a random identifier for one request
+func NewCorrelationID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
+
+// WithCorrelationID returns a context carrying id
+func WithCorrelationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, correlationKey{}, id)
+}
+
+// CorrelationID returns the correlation ID of the request handled in ctx,
+// or "" outside a request
+func CorrelationID(ctx context.Context) string {
+	id, _ := ctx.Value(correlationKey{}).(string)
+	return id
+}
+
+// LoggerFromContext returns the global logger tagged with the correlation
+// ID in ctx, so tool logs can be matched to the request that caused them
+func LoggerFromContext(ctx context.Context) Logger {
+	return CorrelatedLogger(ctx, DefaultLogger())
+}
+
+// CorrelatedLogger returns logger tagged with the correlation ID in ctx
+func CorrelatedLogger(ctx context.Context, logger Logger) Logger {
+	if id := CorrelationID(ctx); id != "" {
+		return logger.WithField("correlation_id", id)
+	}
+	return logger
+}
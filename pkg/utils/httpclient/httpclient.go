@@ -0,0 +1,172 @@
+// Package httpclient provides a shared, configurable factory for outbound
+// HTTP clients used by tools that fetch remote content.
+package httpclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+// DefaultUserAgent is sent when a request does not set its own User-Agent
+const DefaultUserAgent = "Mozilla/5.0 (compatible; MCP-Go-Template/1.0)"
+
+// Options configures the shared transport and the clients built from it
+type Options struct {
+	Timeout             time.Duration
+	ToolTimeouts        map[string]time.Duration
+	MaxIdleConns        int
+	MaxIdleConnsPerHost int
+	IdleConnTimeout     time.Duration
+	ProxyURL            string
+	UserAgent           string
+	InsecureSkipVerify  bool
+	CAFile              string
+}
+
+// DefaultOptions returns the options used when nothing is configured
+func DefaultOptions() Options {
+	return Options{
+		Timeout:             30 * time.Second,
+		ToolTimeouts:        make(map[string]time.Duration),
+		MaxIdleConns:        100,
+		MaxIdleConnsPerHost: 10,
+		IdleConnTimeout:     90 * time.Second,
+		UserAgent:           DefaultUserAgent,
+	}
+}
+
+// Factory builds HTTP clients that share a single connection pool
+type Factory struct {
+	opts      Options
+	transport http.RoundTripper
+}
+
+// NewFactory creates a factory with its own shared transport
+func NewFactory(opts Options) (*Factory, error) {
+	base := &http.Transport{
+		Proxy:               http.ProxyFromEnvironment,
+		MaxIdleConns:        opts.MaxIdleConns,
+		MaxIdleConnsPerHost: opts.MaxIdleConnsPerHost,
+		IdleConnTimeout:     opts.IdleConnTimeout,
+		TLSHandshakeTimeout: 10 * time.Second,
+		ForceAttemptHTTP2:   true,
+	}
+
+	if opts.ProxyURL != "" {
+		proxyURL, err := url.Parse(opts.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy URL: %w", err)
+		}
+		base.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	if opts.InsecureSkipVerify || opts.CAFile != "" {
+		tlsConfig := &tls.Config{
+			InsecureSkipVerify: opts.InsecureSkipVerify,
+		}
+		if opts.CAFile != "" {
+			pem, err := os.ReadFile(opts.CAFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read CA file: %w", err)
+			}
+			pool, err := x509.SystemCertPool()
+			if err != nil || pool == nil {
+				pool = x509.NewCertPool()
+			}
+			if !pool.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("no certificates found in CA file %s", opts.CAFile)
+			}
+			tlsConfig.RootCAs = pool
+		}
+		base.TLSClientConfig = tlsConfig
+	}
+
+	// Client spans use the global tracer provider, so they are only
+	// exported once tracing is set up and nest under the calling tool's span
+	return &Factory{
+		opts: opts,
+		transport: otelhttp.NewTransport(&userAgentTransport{
+			base:      base,
+			userAgent: opts.UserAgent,
+		}),
+	}, nil
+}
+
+// Client returns an HTTP client for the named tool. All clients share the
+// factory's transport; only the timeout differs per tool.
+func (f *Factory) Client(name string) *http.Client {
+	timeout := f.opts.Timeout
+	if override, exists := f.opts.ToolTimeouts[name]; exists && override > 0 {
+		timeout = override
+	}
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: f.transport,
+	}
+}
+
+// userAgentTransport sets a default User-Agent on outgoing requests
+type userAgentTransport struct {
+	base      http.RoundTripper
+	userAgent string
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *userAgentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.userAgent != "" && req.Header.Get("User-Agent") == "" {
+		req = req.Clone(req.Context())
+		req.Header.Set("User-Agent", t.userAgent)
+	}
+	return t.base.RoundTrip(req)
+}
+
+var (
+	defaultFactory *Factory
+	defaultMutex   sync.RWMutex
+)
+
+// Configure replaces the process-wide default factory
+func Configure(opts Options) error {
+	factory, err := NewFactory(opts)
+	if err != nil {
+		return err
+	}
+
+	defaultMutex.Lock()
+	defaultFactory = factory
+	defaultMutex.Unlock()
+	return nil
+}
+
+// Default returns the process-wide factory, creating it with default
+// options if Configure has not been called
+func Default() *Factory {
+	defaultMutex.RLock()
+	factory := defaultFactory
+	defaultMutex.RUnlock()
+	if factory != nil {
+		return factory
+	}
+
+	defaultMutex.Lock()
+	defer defaultMutex.Unlock()
+	if defaultFactory == nil {
+		// Default options never fail to build
+		defaultFactory, _ = NewFactory(DefaultOptions())
+	}
+	return defaultFactory
+}
+
+// New returns a client for the named tool from the default factory
+func New(name string) *http.Client {
+	return Default().Client(name)
+}
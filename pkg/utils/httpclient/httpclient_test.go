@@ -0,0 +1,62 @@
+package httpclient
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestFactory_ClientTimeouts(t *testing.T) {
+	opts := DefaultOptions()
+	opts.Timeout = 5 * time.Second
+	opts.ToolTimeouts["slow_tool"] = 60 * time.Second
+
+	factory, err := NewFactory(opts)
+	if err != nil {
+		t.Fatalf("NewFactory failed: %v", err)
+	}
+
+	if got := factory.Client("web_search").Timeout; got != 5*time.Second {
+		t.Errorf("Expected default timeout 5s, got %v", got)
+	}
+	if got := factory.Client("slow_tool").Timeout; got != 60*time.Second {
+		t.Errorf("Expected override timeout 60s, got %v", got)
+	}
+	if factory.Client("a").Transport != factory.Client("b").Transport {
+		t.Error("Expected clients to share a transport")
+	}
+}
+
+func TestFactory_UserAgent(t *testing.T) {
+	var received string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Get("User-Agent")
+	}))
+	defer srv.Close()
+
+	opts := DefaultOptions()
+	opts.UserAgent = "test-agent/1.0"
+	factory, err := NewFactory(opts)
+	if err != nil {
+		t.Fatalf("NewFactory failed: %v", err)
+	}
+
+	resp, err := factory.Client("test").Get(srv.URL)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if received != "test-agent/1.0" {
+		t.Errorf("Expected User-Agent 'test-agent/1.0', got '%s'", received)
+	}
+}
+
+func TestNewFactory_InvalidProxy(t *testing.T) {
+	opts := DefaultOptions()
+	opts.ProxyURL = "://bad"
+	if _, err := NewFactory(opts); err == nil {
+		t.Error("Expected error for invalid proxy URL")
+	}
+}
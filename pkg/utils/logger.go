@@ -6,8 +6,74 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
-// Logger is a global logger instance
-var Logger *logrus.Logger
+// Logger is what the server, handlers, registries and tools log through.
+// NewLogger adapts a logrus logger to it; implement it to log through
+// another library.
+type Logger interface {
+	WithField(key string, value interface{}) Logger
+	WithFields(fields map[string]interface{}) Logger
+	WithError(err error) Logger
+	Debug(args ...interface{})
+	Debugf(format string, args ...interface{})
+	Info(args ...interface{})
+	Infof(format string, args ...interface{})
+	Warn(args ...interface{})
+	Warnf(format string, args ...interface{})
+	Error(args ...interface{})
+	Errorf(format string, args ...interface{})
+}
+
+// std is the global logger, the default of components not given a Logger
+var std *logrus.Logger
+
+// logrusLogger adapts a logrus logger or entry to Logger
+type logrusLogger struct {
+	logrus.FieldLogger
+}
+
+// NewLogger returns a Logger writing to logger, a *logrus.Logger or
+// *logrus.Entry
+func NewLogger(logger logrus.FieldLogger) Logger {
+	return logrusLogger{logger}
+}
+
+// DefaultLogger returns the global logger as a Logger
+func DefaultLogger() Logger {
+	return NewLogger(std)
+}
+
+// WithField returns a logger adding key to each entry
+func (l logrusLogger) WithField(key string, value interface{}) Logger {
+	return logrusLogger{l.FieldLogger.WithField(key, value)}
+}
+
+// WithFields returns a logger adding fields to each entry
+func (l logrusLogger) WithFields(fields map[string]interface{}) Logger {
+	return logrusLogger{l.FieldLogger.WithFields(fields)}
+}
+
+// WithError returns a logger adding err to each entry
+func (l logrusLogger) WithError(err error) Logger {
+	return logrusLogger{l.FieldLogger.WithError(err)}
+}
+
+// AddHook adds hook to the logrus logger behind logger and reports whether
+// logger has one, i.e. whether it was made by NewLogger
+func AddHook(logger Logger, hook logrus.Hook) bool {
+	adapted, ok := logger.(logrusLogger)
+	if !ok {
+		return false
+	}
+	switch target := adapted.FieldLogger.(type) {
+	case *logrus.Logger:
+		target.AddHook(hook)
+	case *logrus.Entry:
+		target.Logger.AddHook(hook)
+	default:
+		return false
+	}
+	return true
+}
 
 // LogLevel represents the logging level
 type LogLevel string
@@ -21,96 +87,97 @@ const (
 
 // init initializes the global logger
 func init() {
-	Logger = logrus.New()
-	Logger.SetOutput(os.Stdout)
-	Logger.SetFormatter(&logrus.JSONFormatter{
+	std = logrus.New()
+	std.SetOutput(os.Stdout)
+	std.SetFormatter(samplingFormatter{&logrus.JSONFormatter{
 		TimestampFormat: "2006-01-02T15:04:05.000Z",
-	})
-	Logger.SetLevel(logrus.InfoLevel)
+	}})
+	std.SetLevel(logrus.InfoLevel)
 }
 
 // SetLogLevel sets the logging level
 func SetLogLevel(level LogLevel) {
 	switch level {
 	case DebugLevel:
-		Logger.SetLevel(logrus.DebugLevel)
+		std.SetLevel(logrus.DebugLevel)
 	case InfoLevel:
-		Logger.SetLevel(logrus.InfoLevel)
+		std.SetLevel(logrus.InfoLevel)
 	case WarnLevel:
-		Logger.SetLevel(logrus.WarnLevel)
+		std.SetLevel(logrus.WarnLevel)
 	case ErrorLevel:
-		Logger.SetLevel(logrus.ErrorLevel)
+		std.SetLevel(logrus.ErrorLevel)
 	default:
-		Logger.SetLevel(logrus.InfoLevel)
+		std.SetLevel(logrus.InfoLevel)
 	}
 }
 
-// SetFormatter sets the log formatter
+// SetFormatter sets the log formatter, which is not given the entries
+// left out by sampling
 func SetFormatter(formatter logrus.Formatter) {
-	Logger.SetFormatter(formatter)
+	std.SetFormatter(samplingFormatter{formatter})
 }
 
-// GetLogger returns the global logger instance
+// GetLogger returns the global logger, to configure its output
 func GetLogger() *logrus.Logger {
-	return Logger
+	return std
 }
 
 // Debug logs a debug message
 func Debug(args ...interface{}) {
-	Logger.Debug(args...)
+	std.Debug(args...)
 }
 
 // Debugf logs a formatted debug message
 func Debugf(format string, args ...interface{}) {
-	Logger.Debugf(format, args...)
+	std.Debugf(format, args...)
 }
 
 // Info logs an info message
 func Info(args ...interface{}) {
-	Logger.Info(args...)
+	std.Info(args...)
 }
 
 // Infof logs a formatted info message
 func Infof(format string, args ...interface{}) {
-	Logger.Infof(format, args...)
+	std.Infof(format, args...)
 }
 
 // Warn logs a warning message
 func Warn(args ...interface{}) {
-	Logger.Warn(args...)
+	std.Warn(args...)
 }
 
 // Warnf logs a formatted warning message
 func Warnf(format string, args ...interface{}) {
-	Logger.Warnf(format, args...)
+	std.Warnf(format, args...)
 }
 
 // Error logs an error message
 func Error(args ...interface{}) {
-	Logger.Error(args...)
+	std.Error(args...)
 }
 
 // Errorf logs a formatted error message
 func Errorf(format string, args ...interface{}) {
-	Logger.Errorf(format, args...)
+	std.Errorf(format, args...)
 }
 
 // Fatal logs a fatal message and exits
 func Fatal(args ...interface{}) {
-	Logger.Fatal(args...)
+	std.Fatal(args...)
 }
 
 // Fatalf logs a formatted fatal message and exits
 func Fatalf(format string, args ...interface{}) {
-	Logger.Fatalf(format, args...)
+	std.Fatalf(format, args...)
 }
 
 // WithField creates a logger with a single field
 func WithField(key string, value interface{}) *logrus.Entry {
-	return Logger.WithField(key, value)
+	return std.WithField(key, value)
 }
 
 // WithFields creates a logger with multiple fields
 func WithFields(fields logrus.Fields) *logrus.Entry {
-	return Logger.WithFields(fields)
+	return std.WithFields(fields)
 }
\ No newline at end of file
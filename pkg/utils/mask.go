@@ -0,0 +1,71 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// maskedSecret replaces secrets in log entries
+const maskedSecret = "********"
+
+// secretHook masks secrets in the messages and fields of log entries
+type secretHook struct {
+	mu       sync.RWMutex
+	replacer *strings.Replacer
+}
+
+var (
+	secretsHook     = &secretHook{}
+	secretsHookOnce sync.Once
+)
+
+// MaskSecrets makes the logger write ******** in place of each of secrets,
+// in messages and in field values, replacing the secrets of earlier calls
+func MaskSecrets(secrets []string) {
+	secretsHookOnce.Do(func() {
+		std.AddHook(secretsHook)
+	})
+
+	pairs := make([]string, 0, 2*len(secrets))
+	for _, secret := range secrets {
+		if secret != "" {
+			pairs = append(pairs, secret, maskedSecret)
+		}
+	}
+
+	secretsHook.mu.Lock()
+	defer secretsHook.mu.Unlock()
+	secretsHook.replacer = nil
+	if len(pairs) > 0 {
+		secretsHook.replacer = strings.NewReplacer(pairs...)
+	}
+}
+
+// Levels returns every level, so that no entry leaks a secret
+func (h *secretHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire masks the secrets in entry before it is formatted
+func (h *secretHook) Fire(entry *logrus.Entry) error {
+	h.mu.RLock()
+	replacer := h.replacer
+	h.mu.RUnlock()
+	if replacer == nil {
+		return nil
+	}
+
+	entry.Message = replacer.Replace(entry.Message)
+	for key, value := range entry.Data {
+		switch value := value.(type) {
+		case string:
+			entry.Data[key] = replacer.Replace(value)
+		case error, fmt.Stringer:
+			entry.Data[key] = replacer.Replace(fmt.Sprint(value))
+		}
+	}
+	return nil
+}
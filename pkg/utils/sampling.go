@@ -0,0 +1,139 @@
+package utils
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// suppressedField reports, on the first entry of a new interval, how many
+// entries with the same message were left out in the previous one
+const suppressedField = "suppressed"
+
+// SamplingOptions configures SetSampling
+type SamplingOptions struct {
+	// Burst is how many entries with the same level and message are
+	// logged per Interval; 0 disables sampling
+	Burst    int
+	Interval time.Duration
+}
+
+// suppressedKey marks, in an entry's context, an entry left out by sampling
+type suppressedKey struct{}
+
+// samplingKey identifies the entries counted together
+type samplingKey struct {
+	level   logrus.Level
+	message string
+}
+
+// samplingWindow counts the entries of a key in its current interval
+type samplingWindow struct {
+	start   time.Time
+	logged  int
+	dropped int
+}
+
+// samplingHook limits how often the same message is logged
+type samplingHook struct {
+	mu        sync.Mutex
+	opts      SamplingOptions
+	windows   map[samplingKey]*samplingWindow
+	lastPrune time.Time
+}
+
+var (
+	logSampler     = &samplingHook{windows: make(map[samplingKey]*samplingWindow)}
+	logSamplerOnce sync.Once
+)
+
+// SetSampling makes the global logger log at most opts.Burst entries with
+// the same level and message per opts.Interval, such as the warnings of a
+// retried request, and leave the rest out of its output and hooks. The
+// first entry logged after suppressed ones has a "suppressed" field with
+// their number. Fatal and panic entries are always logged. It replaces the
+// options of earlier calls.
+func SetSampling(opts SamplingOptions) {
+	logSamplerOnce.Do(func() {
+		std.AddHook(logSampler)
+	})
+
+	logSampler.mu.Lock()
+	defer logSampler.mu.Unlock()
+	logSampler.opts = opts
+	logSampler.windows = make(map[samplingKey]*samplingWindow)
+}
+
+// Suppressed reports whether sampling left entry out. Hooks added after
+// SetSampling was first called should skip such entries.
+func Suppressed(entry *logrus.Entry) bool {
+	return entry.Context != nil && entry.Context.Value(suppressedKey{}) != nil
+}
+
+// Levels implements logrus.Hook
+func (h *samplingHook) Levels() []logrus.Level {
+	return []logrus.Level{logrus.ErrorLevel, logrus.WarnLevel, logrus.InfoLevel, logrus.DebugLevel, logrus.TraceLevel}
+}
+
+// Fire implements logrus.Hook, marking entry as suppressed once its key
+// is over the burst of the current interval
+func (h *samplingHook) Fire(entry *logrus.Entry) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.opts.Burst <= 0 {
+		return nil
+	}
+
+	now := time.Now()
+	h.prune(now)
+	key := samplingKey{level: entry.Level, message: entry.Message}
+	window, ok := h.windows[key]
+	if !ok || now.Sub(window.start) >= h.opts.Interval {
+		if ok && window.dropped > 0 {
+			entry.Data[suppressedField] = window.dropped
+		}
+		window = &samplingWindow{start: now}
+		h.windows[key] = window
+	}
+
+	window.logged++
+	if window.logged <= h.opts.Burst {
+		return nil
+	}
+	window.dropped++
+	ctx := entry.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	entry.Context = context.WithValue(ctx, suppressedKey{}, true)
+	return nil
+}
+
+// prune forgets, once per interval, the keys whose interval ended with no
+// entries suppressed; h.mu must be held
+func (h *samplingHook) prune(now time.Time) {
+	if now.Sub(h.lastPrune) < h.opts.Interval {
+		return
+	}
+	h.lastPrune = now
+	for key, window := range h.windows {
+		if window.dropped == 0 && now.Sub(window.start) >= h.opts.Interval {
+			delete(h.windows, key)
+		}
+	}
+}
+
+// samplingFormatter writes nothing for entries left out by sampling
+type samplingFormatter struct {
+	logrus.Formatter
+}
+
+// Format implements logrus.Formatter
+func (f samplingFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	if Suppressed(entry) {
+		return nil, nil
+	}
+	return f.Formatter.Format(entry)
+}
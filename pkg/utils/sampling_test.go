@@ -0,0 +1,45 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestSamplingHook_DropsEntriesOverBurst(t *testing.T) {
+	hook := &samplingHook{
+		opts:    SamplingOptions{Burst: 2, Interval: 50 * time.Millisecond},
+		windows: make(map[samplingKey]*samplingWindow),
+	}
+	formatter := samplingFormatter{&logrus.TextFormatter{}}
+	fire := func(message string) *logrus.Entry {
+		entry := logrus.NewEntry(logrus.New())
+		entry.Level = logrus.WarnLevel
+		entry.Message = message
+		hook.Fire(entry)
+		return entry
+	}
+
+	for i := 0; i < 5; i++ {
+		entry := fire("retrying request")
+		if Suppressed(entry) != (i >= 2) {
+			t.Errorf("Entry %d: expected suppressed=%v", i, i >= 2)
+		}
+		if output, _ := formatter.Format(entry); (len(output) == 0) != (i >= 2) {
+			t.Errorf("Entry %d: expected output only within the burst, got %q", i, output)
+		}
+	}
+	if Suppressed(fire("another message")) {
+		t.Error("Expected other messages to be counted separately")
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	entry := fire("retrying request")
+	if Suppressed(entry) {
+		t.Error("Expected the next interval to log the message again")
+	}
+	if got := entry.Data[suppressedField]; got != 3 {
+		t.Errorf("Expected the next interval to report 3 suppressed entries, got %v", got)
+	}
+}
@@ -0,0 +1,142 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// journalSocket receives entries in journald's native protocol
+const journalSocket = "/run/systemd/journal/socket"
+
+// SyslogOptions configures the syslog output added by AddSyslog
+type SyslogOptions struct {
+	// Network and Address reach a remote syslog server, e.g. "udp" and
+	// "logs.internal:514"; both empty use the local daemon
+	Network string
+	Address string
+	// Tag names the program in messages; empty uses the executable's name
+	Tag string
+	// Facility is a facility name such as "daemon" or "local0"; empty
+	// means "daemon"
+	Facility string
+}
+
+// journaldHook sends log entries to the systemd journal
+type journaldHook struct {
+	conn       net.Conn
+	identifier string
+}
+
+// AddJournald makes the global logger also send its entries to the systemd
+// journal, with their fields as journal fields. identifier is the entries'
+// SYSLOG_IDENTIFIER; empty uses the executable's name.
+func AddJournald(identifier string) error {
+	conn, err := net.Dial("unixgram", journalSocket)
+	if err != nil {
+		return fmt.Errorf("failed to connect to journald: %w", err)
+	}
+	if identifier == "" {
+		identifier = filepath.Base(os.Args[0])
+	}
+	std.AddHook(&journaldHook{conn: conn, identifier: identifier})
+	return nil
+}
+
+// Levels implements logrus.Hook
+func (h *journaldHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire implements logrus.Hook, sending entry as one datagram
+func (h *journaldHook) Fire(entry *logrus.Entry) error {
+	if Suppressed(entry) {
+		return nil
+	}
+
+	var message bytes.Buffer
+	writeJournalField(&message, "MESSAGE", entry.Message)
+	writeJournalField(&message, "PRIORITY", strconv.Itoa(journalPriority(entry.Level)))
+	writeJournalField(&message, "SYSLOG_IDENTIFIER", h.identifier)
+
+	keys := make([]string, 0, len(entry.Data))
+	for key := range entry.Data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		name := journalFieldName(key)
+		if name == "" {
+			continue
+		}
+		value := entry.Data[key]
+		if err, ok := value.(error); ok {
+			value = err.Error()
+		}
+		writeJournalField(&message, name, fmt.Sprint(value))
+	}
+
+	_, err := h.conn.Write(message.Bytes())
+	return err
+}
+
+// journalPriority maps a logrus level to a syslog priority
+func journalPriority(level logrus.Level) int {
+	switch level {
+	case logrus.PanicLevel, logrus.FatalLevel:
+		return 2
+	case logrus.ErrorLevel:
+		return 3
+	case logrus.WarnLevel:
+		return 4
+	case logrus.InfoLevel:
+		return 6
+	default:
+		return 7
+	}
+}
+
+// journalFieldName turns a log field name into a journal field name:
+// uppercase letters, digits and underscores, not starting with an
+// underscore or digit, which journald reserves or rejects. Names the hook
+// sets itself get a FIELD_ prefix. It returns "" for names with nothing
+// left.
+func journalFieldName(key string) string {
+	name := []byte(strings.ToUpper(key))
+	for i, c := range name {
+		if (c < 'A' || c > 'Z') && (c < '0' || c > '9') {
+			name[i] = '_'
+		}
+	}
+	trimmed := strings.TrimLeft(string(name), "_0123456789")
+	switch trimmed {
+	case "MESSAGE", "PRIORITY", "SYSLOG_IDENTIFIER":
+		trimmed = "FIELD_" + trimmed
+	}
+	if len(trimmed) > 64 {
+		trimmed = trimmed[:64]
+	}
+	return trimmed
+}
+
+// writeJournalField appends a field in the native protocol: NAME=value, or
+// the name, the value's length and the value for values spanning lines
+func writeJournalField(message *bytes.Buffer, name, value string) {
+	if !strings.Contains(value, "\n") {
+		fmt.Fprintf(message, "%s=%s\n", name, value)
+		return
+	}
+	message.WriteString(name)
+	message.WriteByte('\n')
+	binary.Write(message, binary.LittleEndian, uint64(len(value)))
+	message.WriteString(value)
+	message.WriteByte('\n')
+}
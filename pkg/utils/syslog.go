@@ -0,0 +1,77 @@
+//go:build !windows && !plan9
+
+package utils
+
+import (
+	"fmt"
+	"log/syslog"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// syslogFacilities maps facility names to their priorities
+var syslogFacilities = map[string]syslog.Priority{
+	"kern": syslog.LOG_KERN, "user": syslog.LOG_USER, "mail": syslog.LOG_MAIL,
+	"daemon": syslog.LOG_DAEMON, "auth": syslog.LOG_AUTH, "syslog": syslog.LOG_SYSLOG,
+	"lpr": syslog.LOG_LPR, "news": syslog.LOG_NEWS, "uucp": syslog.LOG_UUCP,
+	"cron": syslog.LOG_CRON, "authpriv": syslog.LOG_AUTHPRIV, "ftp": syslog.LOG_FTP,
+	"local0": syslog.LOG_LOCAL0, "local1": syslog.LOG_LOCAL1, "local2": syslog.LOG_LOCAL2,
+	"local3": syslog.LOG_LOCAL3, "local4": syslog.LOG_LOCAL4, "local5": syslog.LOG_LOCAL5,
+	"local6": syslog.LOG_LOCAL6, "local7": syslog.LOG_LOCAL7,
+}
+
+// syslogHook sends log entries to syslog
+type syslogHook struct {
+	writer *syslog.Writer
+}
+
+// AddSyslog makes the global logger also send its entries to syslog,
+// formatted as on its output
+func AddSyslog(opts SyslogOptions) error {
+	if opts.Facility == "" {
+		opts.Facility = "daemon"
+	}
+	facility, ok := syslogFacilities[opts.Facility]
+	if !ok {
+		return fmt.Errorf("unknown syslog facility: %s", opts.Facility)
+	}
+
+	writer, err := syslog.Dial(opts.Network, opts.Address, facility|syslog.LOG_INFO, opts.Tag)
+	if err != nil {
+		return fmt.Errorf("failed to connect to syslog: %w", err)
+	}
+	std.AddHook(&syslogHook{writer: writer})
+	return nil
+}
+
+// Levels implements logrus.Hook
+func (h *syslogHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire implements logrus.Hook, sending entry at the matching severity
+func (h *syslogHook) Fire(entry *logrus.Entry) error {
+	if Suppressed(entry) {
+		return nil
+	}
+
+	line, err := entry.String()
+	if err != nil {
+		return err
+	}
+	line = strings.TrimSuffix(line, "\n")
+
+	switch entry.Level {
+	case logrus.PanicLevel, logrus.FatalLevel:
+		return h.writer.Crit(line)
+	case logrus.ErrorLevel:
+		return h.writer.Err(line)
+	case logrus.WarnLevel:
+		return h.writer.Warning(line)
+	case logrus.InfoLevel:
+		return h.writer.Info(line)
+	default:
+		return h.writer.Debug(line)
+	}
+}
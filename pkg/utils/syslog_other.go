@@ -0,0 +1,13 @@
+//go:build windows || plan9
+
+package utils
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// AddSyslog fails, as log/syslog is not available on this platform
+func AddSyslog(opts SyslogOptions) error {
+	return fmt.Errorf("syslog is not supported on %s", runtime.GOOS)
+}